@@ -0,0 +1,194 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/backfill"
+	"tx-aggregator/benchmark"
+	"tx-aggregator/config"
+	"tx-aggregator/health"
+	"tx-aggregator/middleware"
+	"tx-aggregator/regression"
+	"tx-aggregator/replay"
+	"tx-aggregator/shadow"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// AdminHandler handles operational and introspection endpoints under /admin.
+type AdminHandler struct {
+	benchmark  *benchmark.Runner
+	regression *regression.Monitor
+	backfill   *backfill.Backfiller
+	replay     *replay.Replayer
+	shadow     *shadow.Mirror // nil when shadowing is disabled
+	health     *health.Prober
+}
+
+// NewAdminHandler initializes a new AdminHandler.
+func NewAdminHandler(bench *benchmark.Runner, monitor *regression.Monitor, backfiller *backfill.Backfiller, replayer *replay.Replayer, mirror *shadow.Mirror, prober *health.Prober) *AdminHandler {
+	return &AdminHandler{benchmark: bench, regression: monitor, backfill: backfiller, replay: replayer, shadow: mirror, health: prober}
+}
+
+// WhoAmI handles GET /admin/whoami and reports the API key name and roles
+// used for the request, so operators can verify what a key is allowed to do.
+func (h *AdminHandler) WhoAmI(ctx *fiber.Ctx) error {
+	result := types.WhoAmIResult{AuthEnabled: config.Current().Auth.Enabled}
+	if key, ok := middleware.APIKeyFromCtx(ctx); ok {
+		result.Name = key.Name
+		result.Roles = key.Roles
+	}
+
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  result,
+	})
+}
+
+// EffectiveConfig handles GET /admin/config/effective and returns the merged
+// runtime configuration (secrets redacted) with the layers it came from.
+func (h *AdminHandler) EffectiveConfig(ctx *fiber.Ctx) error {
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  config.Effective(),
+	})
+}
+
+// ProviderBenchmark handles GET /admin/provider-benchmark and returns the
+// latest provider ranking per chain. It answers CodeNotFound until the first
+// benchmark run has finished.
+func (h *AdminHandler) ProviderBenchmark(ctx *fiber.Ctx) error {
+	report, ok := h.benchmark.Report()
+	if !ok {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeNotFound,
+			Message: types.GetMessageByCode(types.CodeNotFound),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  report,
+	})
+}
+
+// ReportRegression handles POST /admin/regressions. External detectors such
+// as the integration tool post a count drop here so that it is exported as a
+// metric and alerted like the ones found by the server-side monitor.
+func (h *AdminHandler) ReportRegression(ctx *fiber.Ctx) error {
+	var f types.RegressionFinding
+	if err := ctx.BodyParser(&f); err != nil || f.Address == "" || f.ChainName == "" || f.Actual >= f.Expected {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+	if f.Source == "" {
+		f.Source = types.RegressionSourceIntegration
+	}
+
+	h.regression.Report(f)
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+	})
+}
+
+// StartBackfill handles POST /admin/backfill?chainName=<chain>. It warms the
+// cache of a newly added chain for every address on the watch list in the
+// background and returns the job; poll GET /admin/backfill/:id for progress.
+func (h *AdminHandler) StartBackfill(ctx *fiber.Ctx) error {
+	chainName := utils.GetInsensitiveQuery(ctx, "chainName")
+	if _, err := utils.ChainIDByName(chainName); err != nil {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	job := h.backfill.Submit(chainName)
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  job,
+	})
+}
+
+// GetBackfill handles GET /admin/backfill/:id and returns the job progress.
+func (h *AdminHandler) GetBackfill(ctx *fiber.Ctx) error {
+	job, ok := h.backfill.Get(ctx.Params("id"))
+	if !ok {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeNotFound,
+			Message: types.GetMessageByCode(types.CodeNotFound),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  job,
+	})
+}
+
+// StartReplay handles POST /admin/replay?chainName=<chain>. It re-runs the
+// current normalization over every cached address of the chain in the
+// background and returns the job; poll GET /admin/replay/:id for progress.
+func (h *AdminHandler) StartReplay(ctx *fiber.Ctx) error {
+	chainName := utils.GetInsensitiveQuery(ctx, "chainName")
+	if _, err := utils.ChainIDByName(chainName); err != nil {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	job := h.replay.Submit(chainName)
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  job,
+	})
+}
+
+// GetReplay handles GET /admin/replay/:id and returns the job progress.
+func (h *AdminHandler) GetReplay(ctx *fiber.Ctx) error {
+	job, ok := h.replay.Get(ctx.Params("id"))
+	if !ok {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeNotFound,
+			Message: types.GetMessageByCode(types.CodeNotFound),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  job,
+	})
+}
+
+// ShadowDiffs handles GET /admin/shadow/diffs and returns the most recent
+// mirrored requests whose staging response differed from production's,
+// newest first. The list is empty when shadowing is disabled.
+func (h *AdminHandler) ShadowDiffs(ctx *fiber.Ctx) error {
+	diffs := h.shadow.Diffs()
+	if diffs == nil {
+		diffs = []types.ShadowDiff{}
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  diffs,
+	})
+}
+
+// ProviderStatus handles GET /providers/status and reports, for every
+// registered provider, whether it is currently routed to and the outcome of
+// its latest health probes.
+func (h *AdminHandler) ProviderStatus(ctx *fiber.Ctx) error {
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  h.health.Status(),
+	})
+}
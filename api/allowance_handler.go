@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+)
+
+// AllowanceHandler handles HTTP requests for live ERC-20 allowances.
+type AllowanceHandler struct {
+	service interfaces.AllowanceServiceInterface
+}
+
+// NewAllowanceHandler initializes a new AllowanceHandler with the given service.
+func NewAllowanceHandler(service interfaces.AllowanceServiceInterface) *AllowanceHandler {
+	return &AllowanceHandler{service: service}
+}
+
+// GetAllowance handles GET /allowance?owner=...&token=...&spender=...&chainName=...
+// It returns the allowance currently granted, complementing the historical
+// approvals in /transactions, e.g. for revoke tooling.
+func (h *AllowanceHandler) GetAllowance(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	params, err := parseAllowanceParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid allowance parameters")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	allowance, err := h.service.GetAllowance(params)
+	if err != nil {
+		log.Error().Err(err).Str("chain", params.ChainName).Str("token", params.Token).Msg("❌ Allowance lookup failed")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeProviderFailed,
+			Message: types.GetMessageByCode(types.CodeProviderFailed),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  allowance,
+	})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+)
+
+// CacheHandler handles HTTP requests that manage cached transactions.
+type CacheHandler struct {
+	invalidator interfaces.CacheInvalidatorInterface
+}
+
+// NewCacheHandler initializes a new CacheHandler with the given invalidator.
+func NewCacheHandler(invalidator interfaces.CacheInvalidatorInterface) *CacheHandler {
+	return &CacheHandler{invalidator: invalidator}
+}
+
+// InvalidateCache handles POST /cache/invalidate?address=...&chainName=...
+// It drops every cached entry of the address on the given chains (all chains
+// of the address format when chainName is omitted), so an integrator that has
+// just broadcast a transaction sees it on the next poll.
+func (h *CacheHandler) InvalidateCache(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	params, err := parseTransactionQueryParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid cache invalidation parameters")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	result, err := h.invalidator.InvalidateTransactions(params)
+	if err != nil {
+		log.Error().Err(err).Str("address", params.Address).Msg("❌ Cache invalidation failed")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInternalError,
+			Message: types.GetMessageByCode(types.CodeInternalError),
+		})
+	}
+
+	key, _ := middleware.APIKeyFromCtx(ctx)
+	log.Info().
+		Str("key", key.Name).
+		Str("address", params.Address).
+		Int64("keys_removed", result.KeysRemoved).
+		Msg("✅ Cache invalidated on request")
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  result,
+	})
+}
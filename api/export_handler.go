@@ -0,0 +1,58 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/taxlot"
+	"tx-aggregator/types"
+)
+
+// ExportHandler handles HTTP requests for asynchronous export jobs.
+type ExportHandler struct {
+	exporter *taxlot.Exporter
+}
+
+// NewExportHandler initializes a new ExportHandler with the given exporter.
+func NewExportHandler(exporter *taxlot.Exporter) *ExportHandler {
+	return &ExportHandler{exporter: exporter}
+}
+
+// CreateTaxLotExport handles POST /exports/taxlots.
+// It validates the parameters, queues the job and returns its ID immediately.
+func (h *ExportHandler) CreateTaxLotExport(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	params, err := parseTaxLotExportParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid tax lot export parameters")
+		return ctx.JSON(&types.ExportJobResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	job := h.exporter.Submit(params)
+	return ctx.JSON(&types.ExportJobResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  &job,
+	})
+}
+
+// GetTaxLotExport handles GET /exports/taxlots/:id and returns the job state,
+// including the report once the job is done.
+func (h *ExportHandler) GetTaxLotExport(ctx *fiber.Ctx) error {
+	job, ok := h.exporter.Get(ctx.Params("id"))
+	if !ok {
+		return ctx.JSON(&types.ExportJobResponse{
+			Code:    types.CodeNotFound,
+			Message: types.GetMessageByCode(types.CodeNotFound),
+		})
+	}
+
+	return ctx.JSON(&types.ExportJobResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  &job,
+	})
+}
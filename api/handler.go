@@ -5,9 +5,13 @@ import (
 	"errors"
 	"github.com/gofiber/fiber/v2"
 	"time"
+	"tx-aggregator/freshness"
 	"tx-aggregator/interfaces"
 	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
 	"tx-aggregator/types"
+	"tx-aggregator/usecase"
+	"tx-aggregator/utils"
 )
 
 // TransactionHandler handles HTTP requests related to transaction queries.
@@ -24,29 +28,30 @@ func NewTransactionHandler(service interfaces.TransactionServiceInterface) *Tran
 // It parses query parameters, delegates processing to the usecase, and always returns HTTP 200,
 // with the actual status represented by a custom code in the JSON body.
 func (h *TransactionHandler) GetTransactions(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
 	start := time.Now()
-	logger.Log.Info().Msg("📥 Received /transactions request")
 
 	// Parse and validate query parameters
 	params, err := parseTransactionQueryParams(ctx)
+	if err == nil {
+		params.GroupByChain, err = parseGroupByChain(ctx)
+	}
+	if err == nil {
+		params.MinValue, err = parseMinValue(ctx)
+		params.HideZeroValue = parseBoolQuery(ctx, "hideZeroValue")
+	}
 	if err != nil {
-		logger.Log.Warn().Err(err).Msg("❌ Invalid query parameters")
+		log.Warn().Err(err).Msg("❌ Invalid query parameters")
 		return ctx.JSON(&types.TransactionResponse{
 			Code:    types.CodeInvalidParam,
 			Message: types.GetMessageByCode(types.CodeInvalidParam),
 		})
 	}
 
-	logger.Log.Info().
-		Str("address", params.Address).
-		Str("token_address", params.TokenAddress).
-		Interface("chain_names", params.ChainNames).
-		Msg("✅ Parsed transaction request parameters")
-
 	// Call the usecase/service layer
 	resp, err := h.service.GetTransactions(params)
 	if err != nil {
-		logger.Log.Error().
+		log.Error().
 			Err(err).
 			Dur("cost", time.Since(start)).
 			Msg("❌ Error while processing transaction request")
@@ -71,12 +76,31 @@ func (h *TransactionHandler) GetTransactions(ctx *fiber.Ctx) error {
 		return ctx.JSON(resp)
 	}
 
-	// Log and return successful response
-	logger.Log.Info().
+	if parseDebugFlag(ctx) {
+		resp.Meta = &types.ResponseMeta{Hash: utils.HashTransactions(resp.Result.Transactions)}
+	}
+	if lags := freshness.Lags(params.ChainNames); lags != nil {
+		if resp.Meta == nil {
+			resp.Meta = &types.ResponseMeta{}
+		}
+		resp.Meta.IndexerLagBlocks = lags
+	}
+
+	// The access log reports the request itself
+	if resp.CacheHit {
+		middleware.MarkCacheHit(ctx)
+	}
+	log.Debug().
 		Int("tx_count", len(resp.Result.Transactions)).
 		Int("code", resp.Code).
 		Dur("cost", time.Since(start)).
 		Msg("✅ Successfully retrieved transaction data")
 
+	if params.GroupByChain {
+		return ctx.JSON(usecase.GroupTransactionsByChain(resp, params.ChainNames))
+	}
+	if parseTokenDictFlag(ctx) {
+		return ctx.JSON(usecase.CompactTransactions(resp))
+	}
 	return ctx.JSON(resp)
 }
@@ -7,7 +7,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"tx-aggregator/config"
+	"tx-aggregator/freshness"
 	"tx-aggregator/types"
+	"tx-aggregator/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -220,3 +223,106 @@ func TestGetTransactions_SuccessWithTransactions(t *testing.T) {
 	assert.Equal(t, "0xabc123", body.Result.Transactions[0].Hash)
 	assert.Equal(t, "0xdef456", body.Result.Transactions[1].Hash)
 }
+
+// TestGetTransactions_DebugMeta tests that debug=true adds the response hash.
+func TestGetTransactions_DebugMeta(t *testing.T) {
+	mockService := new(MockService)
+	app := setupTestApp(mockService)
+
+	newResp := func() *types.TransactionResponse {
+		r := &types.TransactionResponse{Code: types.CodeSuccess}
+		r.Result.Transactions = []types.Transaction{{Hash: "0xabc123", ChainID: 1}}
+		return r
+	}
+	mockService.On("GetTransactions", mock.Anything).Return(newResp(), nil).Once()
+	mockService.On("GetTransactions", mock.Anything).Return(newResp(), nil).Once()
+
+	for _, query := range []string{"", "&debug=true"} {
+		req := httptest.NewRequest("GET", "/transactions?address="+validAddr+query, nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+
+		var body types.TransactionResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		resp.Body.Close()
+
+		if query == "" {
+			assert.Nil(t, body.Meta)
+			continue
+		}
+		if assert.NotNil(t, body.Meta) {
+			assert.Equal(t, utils.HashTransactions(newResp().Result.Transactions), body.Meta.Hash)
+		}
+	}
+}
+
+// TestGetTransactions_IndexerLag tests that a known indexer lag is reported
+// in meta without debug=true.
+func TestGetTransactions_IndexerLag(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"LAGGY": 424242}
+	config.SetCurrentConfig(cfg)
+
+	mockService := new(MockService)
+	app := setupTestApp(mockService)
+	mockService.On("GetTransactions", mock.Anything).Return(&types.TransactionResponse{Code: types.CodeSuccess}, nil).Once()
+
+	freshness.Record(424242, 1000, 996)
+
+	req := httptest.NewRequest("GET", "/transactions?address="+validAddr+"&chainName=laggy", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body types.TransactionResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	if assert.NotNil(t, body.Meta) {
+		assert.Empty(t, body.Meta.Hash)
+		assert.Equal(t, map[string]int64{"LAGGY": 4}, body.Meta.IndexerLagBlocks)
+	}
+}
+
+// TestGetTransactions_GroupByChain tests the groupBy=chain response.
+func TestGetTransactions_GroupByChain(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	config.SetCurrentConfig(cfg)
+
+	mockService := new(MockService)
+	app := setupTestApp(mockService)
+	out := &types.TransactionResponse{Code: types.CodeSuccess}
+	out.Result.Transactions = []types.Transaction{
+		{Hash: "0x1", ServerChainName: "ETH"},
+		{Hash: "0x2", ServerChainName: "ETH"},
+	}
+	mockService.On("GetTransactions", mock.MatchedBy(func(p *types.TransactionQueryParams) bool {
+		return p.GroupByChain
+	})).Return(out, nil).Once()
+
+	req := httptest.NewRequest("GET", "/transactions?address="+validAddr+"&chainName=eth,bsc&groupBy=chain", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body types.GroupedTransactionResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, types.CodeSuccess, body.Code)
+	assert.Len(t, body.Result.Chains["ETH"], 2)
+	assert.NotNil(t, body.Result.Chains["BSC"])
+	assert.Empty(t, body.Result.Chains["BSC"])
+	mockService.AssertExpectations(t)
+
+	for _, query := range []string{"&groupBy=token", "&groupBy=chain&tokenDict=true"} {
+		req := httptest.NewRequest("GET", "/transactions?address="+validAddr+query, nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		var body types.TransactionResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		resp.Body.Close()
+		assert.Equal(t, types.CodeInvalidParam, body.Code, query)
+	}
+}
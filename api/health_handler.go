@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/config"
+	"tx-aggregator/health"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+)
+
+// HealthHandler serves GET /health, the endpoint checked by Consul and load
+// balancers.
+type HealthHandler struct {
+	checker *health.DeepChecker
+}
+
+// NewHealthHandler initializes a new HealthHandler.
+func NewHealthHandler(checker *health.DeepChecker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// Health handles GET /health. It answers "ok" unless health.deep.enabled is
+// set; it then checks Redis, Consul and the critical providers and returns
+// the component report. The status is HTTP 503, taking the instance out of
+// service, only when Redis or every provider of a chain is down; other
+// failures are reported as degraded with HTTP 200.
+func (h *HealthHandler) Health(ctx *fiber.Ctx) error {
+	if !config.Current().Health.Deep.Enabled {
+		return ctx.SendString("ok")
+	}
+
+	report := h.checker.Check(ctx.Context())
+	switch report.Status {
+	case types.ComponentFailed:
+		logger.ForRequest(middleware.RequestIDFromCtx(ctx)).Warn().
+			Interface("components", report.Components).
+			Strs("down_chains", report.DownChains).
+			Msg("❌ Deep health check failed")
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(report)
+	case types.ComponentDegraded:
+		logger.ForRequest(middleware.RequestIDFromCtx(ctx)).Warn().
+			Interface("components", report.Components).
+			Msg("Deep health check degraded")
+	}
+	return ctx.JSON(report)
+}
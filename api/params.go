@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"tx-aggregator/config"
 	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
 	"tx-aggregator/types"
 	"tx-aggregator/utils"
 )
@@ -17,32 +20,55 @@ func parseTransactionQueryParams(ctx *fiber.Ctx) (*types.TransactionQueryParams,
 	address := utils.GetInsensitiveQuery(ctx, "address")
 	if address == "" {
 		return nil, fmt.Errorf("address parameter is required")
-	} else if !utils.IsValidEthereumAddress(address) {
-		return nil, fmt.Errorf("invalid address: %s", address)
 	}
-
 	// Parse and validate chain names
 	rawChainNames := utils.GetInsensitiveQuery(ctx, "chainName")
 	validChainNames, err := parseAndValidateChainNames(rawChainNames)
 	if err != nil {
 		return nil, err
 	}
+	// Only the address families of the requested chains are tried, so an
+	// address that reads as EVM (a 40-digit felt) still reaches Starknet
+	// when the client asks for it with chainName.
+	kind, normalized, ok := utils.DetectAddressFamily(address, utils.ChainAddressFamilies(validChainNames))
+	if !ok {
+		// Not an address of the requested chains: detect it among every
+		// configured chain to report which chains do not support it. EVM is
+		// the default family, tried even with no chain configured.
+		families := utils.ChainAddressFamilies(allChainNames())
+		families[types.AddressFamilyEVM] = true
+		kind, normalized, ok = utils.DetectAddressFamily(address, families)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	address = normalized
+	validChainNames, err = filterChainsByAddressKind(validChainNames, kind, rawChainNames != "")
+	if err != nil {
+		return nil, err
+	}
 
-	// Parse token address
-	tokenAddress := strings.ToLower(utils.GetInsensitiveQuery(ctx, "tokenAddress"))
-	if tokenAddress != "" &&
-		!utils.IsValidEthereumAddress(tokenAddress) &&
-		tokenAddress != types.NativeTokenName {
-		return nil, fmt.Errorf("invalid token address: %s", tokenAddress)
+	tokenAddress, err := parseTokenAddress(utils.GetInsensitiveQuery(ctx, "tokenAddress"), kind)
+	if err != nil {
+		return nil, err
+	}
+
+	startBlock, endBlock, err := parseBlockRange(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	params := &types.TransactionQueryParams{
-		Address:      strings.ToLower(address),
+		Address:      address,
 		TokenAddress: tokenAddress,
 		ChainNames:   validChainNames,
+		StartBlock:   startBlock,
+		EndBlock:     endBlock,
+		RequestID:    middleware.RequestIDFromCtx(ctx),
+		Timings:      middleware.TimingsFromCtx(ctx),
 	}
 
-	logger.Log.Debug().
+	logger.ForRequest(params.RequestID).Debug().
 		Str("address", params.Address).
 		Str("token_address", params.TokenAddress).
 		Interface("chain_names", params.ChainNames).
@@ -51,15 +77,89 @@ func parseTransactionQueryParams(ctx *fiber.Ctx) (*types.TransactionQueryParams,
 	return params, nil
 }
 
+// parseBlockRange reads the optional startBlock / endBlock heights. Both are
+// inclusive; an absent bound is returned as 0 (open).
+func parseBlockRange(ctx *fiber.Ctx) (start, end int64, err error) {
+	parse := func(name string) (int64, error) {
+		raw := utils.GetInsensitiveQuery(ctx, name)
+		if raw == "" {
+			return 0, nil
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid %s: %s", name, raw)
+		}
+		return v, nil
+	}
+	if start, err = parse("startBlock"); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parse("endBlock"); err != nil {
+		return 0, 0, err
+	}
+	if end > 0 && start > end {
+		return 0, 0, fmt.Errorf("startBlock %d is after endBlock %d", start, end)
+	}
+	return start, end, nil
+}
+
+// parseDebugFlag reports whether the request asked for debug meta (debug=true or debug=1).
+func parseDebugFlag(ctx *fiber.Ctx) bool {
+	return parseBoolQuery(ctx, "debug")
+}
+
+// parseTokenDictFlag reports whether the request asked for the compact
+// response with a tokens dictionary (tokenDict=true or tokenDict=1).
+func parseTokenDictFlag(ctx *fiber.Ctx) bool {
+	return parseBoolQuery(ctx, "tokenDict")
+}
+
+// parseGroupByChain reports whether the request asked for transactions
+// grouped by chain (groupBy=chain). Chain is the only grouping.
+func parseGroupByChain(ctx *fiber.Ctx) (bool, error) {
+	switch groupBy := strings.ToLower(utils.GetInsensitiveQuery(ctx, "groupBy")); groupBy {
+	case "":
+		return false, nil
+	case "chain":
+		if parseTokenDictFlag(ctx) {
+			return false, fmt.Errorf("groupBy=chain cannot be combined with tokenDict")
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid groupBy: %s", groupBy)
+	}
+}
+
+// parseMinValue reads the optional minValue, a non-negative decimal amount
+// such as 0.001, returned as given ("" when absent).
+func parseMinValue(ctx *fiber.Ctx) (string, error) {
+	raw := strings.TrimSpace(utils.GetInsensitiveQuery(ctx, "minValue"))
+	if raw == "" {
+		return "", nil
+	}
+	intPart, fracPart, _ := strings.Cut(raw, ".")
+	isDigits := func(s string) bool {
+		return strings.Trim(s, "0123456789") == ""
+	}
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) || strings.HasSuffix(raw, ".") {
+		return "", fmt.Errorf("invalid minValue: %s", raw)
+	}
+	return raw, nil
+}
+
+// parseBoolQuery reports whether the query parameter name is "true" or "1".
+func parseBoolQuery(ctx *fiber.Ctx, name string) bool {
+	v := strings.ToLower(utils.GetInsensitiveQuery(ctx, name))
+	return v == "true" || v == "1"
+}
+
 // parseAndValidateChainNames validates and normalizes chain names from the input string.
 func parseAndValidateChainNames(rawChainNames string) ([]string, error) {
 	var validChainNames []string
 
 	if rawChainNames == "" {
 		// No input provided, return all available chain names
-		for name := range config.Current().ChainNames {
-			validChainNames = append(validChainNames, name)
-		}
+		validChainNames = allChainNames()
 	} else {
 		logger.Log.Debug().Str("chain_names", rawChainNames).Msg("Validating specified chain names")
 		inputChainNames := strings.Split(rawChainNames, ",")
@@ -83,3 +183,220 @@ func parseAndValidateChainNames(rawChainNames string) ([]string, error) {
 	sort.Strings(validChainNames)
 	return validChainNames, nil
 }
+
+// allChainNames returns the configured chain names.
+func allChainNames() []string {
+	names := make([]string, 0, len(config.Current().ChainNames))
+	for name := range config.Current().ChainNames {
+		names = append(names, name)
+	}
+	return names
+}
+
+// parseTokenAddress validates the tokenAddress filter for the given address
+// kind. EVM token addresses are lowercased, TRC-20 contracts keep their
+// base58 case, jetton masters are converted to raw form, Starknet tokens and
+// the address part of Aptos assets are padded to 64 digits and UTXO chains
+// have no tokens at all.
+func parseTokenAddress(raw, kind string) (string, error) {
+	if raw == "" || strings.EqualFold(raw, types.NativeTokenName) {
+		return strings.ToLower(raw), nil
+	}
+	if kind == types.AddressFamilyTON {
+		if rawAddr, ok := utils.TonToRaw(raw); ok {
+			return rawAddr, nil
+		}
+		return "", fmt.Errorf("invalid token address: %s", raw)
+	}
+	if kind == types.AddressFamilyStarknet {
+		if canonical, ok := utils.StarknetToCanonical(raw); ok {
+			return canonical, nil
+		}
+		return "", fmt.Errorf("invalid token address: %s", raw)
+	}
+	if kind == types.AddressFamilyAptos {
+		if canonical, ok := utils.AptosAssetToCanonical(raw); ok {
+			return canonical, nil
+		}
+		return "", fmt.Errorf("invalid token address: %s", raw)
+	}
+	if kind == types.AddressFamilyTron {
+		if utils.IsValidTronAddress(raw) {
+			return raw, nil
+		}
+		return "", fmt.Errorf("invalid token address: %s", raw)
+	}
+	lower := strings.ToLower(raw)
+	if kind != types.AddressFamilyEVM || !utils.IsValidEthereumAddress(lower) {
+		return "", fmt.Errorf("invalid token address: %s", lower)
+	}
+	return lower, nil
+}
+
+// filterChainsByAddressKind keeps the chains whose address format matches the
+// queried address. Chains the client asked for explicitly must all match.
+func filterChainsByAddressKind(chainNames []string, kind string, explicit bool) ([]string, error) {
+	var kept, mismatched []string
+	for _, name := range chainNames {
+		if utils.ChainAddressFamily(name) == kind {
+			kept = append(kept, name)
+		} else {
+			mismatched = append(mismatched, name)
+		}
+	}
+
+	if explicit && len(mismatched) > 0 {
+		return nil, fmt.Errorf("address format not supported on chains: %s", strings.Join(mismatched, ", "))
+	}
+	if len(kept) == 0 && len(chainNames) > 0 {
+		return nil, fmt.Errorf("no chains support this address format")
+	}
+	return kept, nil
+}
+
+// parseTaxLotExportParams parses the query parameters of a tax lot export request.
+// It reuses the address / chainName / tokenAddress rules of /transactions and
+// adds the accounting method (fifo by default).
+func parseTaxLotExportParams(ctx *fiber.Ctx) (*types.TaxLotExportParams, error) {
+	txParams, err := parseTransactionQueryParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	method := strings.ToLower(utils.GetInsensitiveQuery(ctx, "method"))
+	if method == "" {
+		method = types.TaxLotMethodFIFO
+	}
+	if method != types.TaxLotMethodFIFO && method != types.TaxLotMethodLIFO {
+		return nil, fmt.Errorf("invalid method: %s", method)
+	}
+
+	return &types.TaxLotExportParams{
+		Address:      txParams.Address,
+		TokenAddress: txParams.TokenAddress,
+		ChainNames:   txParams.ChainNames,
+		Method:       method,
+	}, nil
+}
+
+// parseWatchParams parses the address and chains of a new watch, plus the
+// optional webhookUrl and ttlSeconds (0 when absent).
+func parseWatchParams(ctx *fiber.Ctx) (*types.TransactionQueryParams, string, time.Duration, error) {
+	params, err := parseTransactionQueryParams(ctx)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if params.TokenAddress != "" || params.StartBlock > 0 || params.EndBlock > 0 {
+		return nil, "", 0, fmt.Errorf("watches cover whole addresses, without token or block range")
+	}
+
+	var ttl time.Duration
+	if raw := utils.GetInsensitiveQuery(ctx, "ttlSeconds"); raw != "" {
+		secs, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || secs <= 0 {
+			return nil, "", 0, fmt.Errorf("invalid ttlSeconds: %s", raw)
+		}
+		ttl = time.Duration(secs) * time.Second
+	}
+	return params, utils.GetInsensitiveQuery(ctx, "webhookUrl"), ttl, nil
+}
+
+// parseTokensParams parses the address and chains of a tokens request.
+func parseTokensParams(ctx *fiber.Ctx) (*types.TransactionQueryParams, error) {
+	params, err := parseTransactionQueryParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if params.TokenAddress != "" || params.StartBlock > 0 || params.EndBlock > 0 {
+		return nil, fmt.Errorf("token lists cover whole addresses, without token or block range")
+	}
+	return params, nil
+}
+
+// defaultPortfolioRecent is how many recent transactions a portfolio lists
+// per chain when the request does not say.
+const defaultPortfolioRecent = 10
+
+// parsePortfolioParams parses the address and chains of a portfolio request,
+// plus the optional number of recent transactions per chain (recent), at
+// most response.max.
+func parsePortfolioParams(ctx *fiber.Ctx) (*types.TransactionQueryParams, int, error) {
+	params, err := parseTransactionQueryParams(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if params.TokenAddress != "" || params.StartBlock > 0 || params.EndBlock > 0 {
+		return nil, 0, fmt.Errorf("portfolios cover whole addresses, without token or block range")
+	}
+
+	recent := defaultPortfolioRecent
+	if raw := utils.GetInsensitiveQuery(ctx, "recent"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || int64(n) > config.Current().Response.Max {
+			return nil, 0, fmt.Errorf("invalid recent: %s", raw)
+		}
+		recent = n
+	}
+	return params, recent, nil
+}
+
+// parseAllowanceParams parses a live allowance lookup: the owner, token and
+// spender EVM addresses (lowercased) and exactly one EVM chain with an RPC
+// endpoint.
+func parseAllowanceParams(ctx *fiber.Ctx) (*types.AllowanceParams, error) {
+	params := &types.AllowanceParams{}
+	for _, p := range []struct {
+		name string
+		dst  *string
+	}{{"owner", &params.Owner}, {"token", &params.Token}, {"spender", &params.Spender}} {
+		raw := utils.GetInsensitiveQuery(ctx, p.name)
+		if raw == "" {
+			return nil, fmt.Errorf("%s parameter is required", p.name)
+		}
+		if !utils.IsValidEthereumAddress(raw) {
+			return nil, fmt.Errorf("invalid %s: %s", p.name, raw)
+		}
+		*p.dst = strings.ToLower(raw)
+	}
+
+	chainName := strings.TrimSpace(utils.GetInsensitiveQuery(ctx, "chainName"))
+	if chainName == "" || strings.Contains(chainName, ",") {
+		return nil, fmt.Errorf("exactly one chainName is required")
+	}
+	chainName, err := parseRPCChain(chainName)
+	if err != nil {
+		return nil, err
+	}
+	params.ChainName = chainName
+	return params, nil
+}
+
+// parseRPCChain validates a chain for live JSON-RPC reads: a known EVM chain
+// with an RPC endpoint (see utils.ChainRPCURL). It returns the name
+// uppercased.
+func parseRPCChain(raw string) (string, error) {
+	chainName := strings.ToUpper(raw)
+	if _, err := utils.ChainIDByName(chainName); err != nil {
+		return "", fmt.Errorf("unknown chain names: %s", chainName)
+	}
+	if utils.ChainAddressFamily(chainName) != types.AddressFamilyEVM {
+		return "", fmt.Errorf("JSON-RPC reads are only supported on EVM chains: %s", chainName)
+	}
+	if _, ok := utils.ChainRPCURL(chainName); !ok {
+		return "", fmt.Errorf("no RPC URL configured for chain %s", chainName)
+	}
+	return chainName, nil
+}
+
+// parseReceiptParams parses the :chain and :hash route parameters of a
+// receipt request. The hash is lowercased.
+func parseReceiptParams(ctx *fiber.Ctx) (chainName, hash string, err error) {
+	if chainName, err = parseRPCChain(ctx.Params("chain")); err != nil {
+		return "", "", err
+	}
+	hash = strings.ToLower(ctx.Params("hash"))
+	if !utils.IsValidTxHash(hash) {
+		return "", "", fmt.Errorf("invalid transaction hash: %s", hash)
+	}
+	return chainName, hash, nil
+}
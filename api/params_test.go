@@ -3,7 +3,9 @@ package api
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -105,6 +107,26 @@ func TestParseTransactionQueryParams(t *testing.T) {
 				ChainNames:   []string{"BSC", "ETH"}, // sorted
 			},
 		},
+		{
+			name:  "block range",
+			query: "?address=0x0123456789abcdef0123456789abcdef01234567&chainName=eth&startBlock=100&endBlock=200",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "0x0123456789abcdef0123456789abcdef01234567",
+				ChainNames: []string{"ETH"},
+				StartBlock: 100,
+				EndBlock:   200,
+			},
+		},
+		{
+			name:          "invalid startBlock",
+			query:         "?address=0x0123456789abcdef0123456789abcdef01234567&startBlock=-1",
+			expectedError: "invalid startBlock: -1",
+		},
+		{
+			name:          "startBlock after endBlock",
+			query:         "?address=0x0123456789abcdef0123456789abcdef01234567&startBlock=300&endBlock=200",
+			expectedError: "startBlock 300 is after endBlock 200",
+		},
 	}
 
 	for _, tt := range tests {
@@ -133,3 +155,626 @@ func TestParseTransactionQueryParams(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTransactionQueryParams_Bitcoin(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BTC": 8332}
+	cfg.Esplora = []types.EsploraConfig{{ChainName: "BTC"}}
+	config.SetCurrentConfig(cfg)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedError  string
+		expectedResult *types.TransactionQueryParams
+	}{
+		{
+			name:  "base58 address keeps case and only UTXO chains",
+			query: "?address=1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2",
+				ChainNames: []string{"BTC"},
+			},
+		},
+		{
+			name:  "bech32 address is lowercased",
+			query: "?address=BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4&chainName=btc",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+				ChainNames: []string{"BTC"},
+			},
+		},
+		{
+			name:  "EVM address skips UTXO chains",
+			query: "?address=0x0123456789abcdef0123456789abcdef01234567",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "0x0123456789abcdef0123456789abcdef01234567",
+				ChainNames: []string{"ETH"},
+			},
+		},
+		{
+			name:          "bitcoin address on EVM chain",
+			query:         "?address=1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2&chainName=eth,btc",
+			expectedError: "address format not supported on chains: ETH",
+		},
+		{
+			name:          "token filter on UTXO chain",
+			query:         "?address=1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2&tokenAddress=0x000000000000000000000000000000000000dead",
+			expectedError: "invalid token address: 0x000000000000000000000000000000000000dead",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var result *types.TransactionQueryParams
+			var handlerErr error
+
+			app.Get("/tx", func(c *fiber.Ctx) error {
+				result, handlerErr = parseTransactionQueryParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tx"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestParseTransactionQueryParams_Tron(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "TRON": 728126428}
+	cfg.Tron = []types.TronConfig{{ChainName: "TRON"}}
+	config.SetCurrentConfig(cfg)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedError  string
+		expectedResult *types.TransactionQueryParams
+	}{
+		{
+			name:  "tron address keeps case and only Tron chains",
+			query: "?address=TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+				ChainNames: []string{"TRON"},
+			},
+		},
+		{
+			name:  "TRC-20 token filter keeps case",
+			query: "?address=TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7&tokenAddress=TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+			expectedResult: &types.TransactionQueryParams{
+				Address:      "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+				TokenAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+				ChainNames:   []string{"TRON"},
+			},
+		},
+		{
+			name:          "EVM token filter on Tron chain",
+			query:         "?address=TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7&tokenAddress=0x000000000000000000000000000000000000dEaD",
+			expectedError: "invalid token address: 0x000000000000000000000000000000000000dEaD",
+		},
+		{
+			name:          "tron address on EVM chain",
+			query:         "?address=TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7&chainName=eth",
+			expectedError: "address format not supported on chains: ETH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var result *types.TransactionQueryParams
+			var handlerErr error
+
+			app.Get("/tx", func(c *fiber.Ctx) error {
+				result, handlerErr = parseTransactionQueryParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tx"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestParseTransactionQueryParams_Ton(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "TON": -239}
+	cfg.Ton = []types.TonConfig{{ChainName: "TON"}}
+	config.SetCurrentConfig(cfg)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedError  string
+		expectedResult *types.TransactionQueryParams
+	}{
+		{
+			name:  "user-friendly address becomes raw and only TON chains",
+			query: "?address=UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPuwA",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e",
+				ChainNames: []string{"TON"},
+			},
+		},
+		{
+			name:  "jetton master filter becomes raw",
+			query: "?address=0:CA6E321C7CCE9ECEDF0A8CA2492EC8592494AA5FB5CE0387DFF96EF6AF982A3E&tokenAddress=EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF",
+			expectedResult: &types.TransactionQueryParams{
+				Address:      "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e",
+				TokenAddress: "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e",
+				ChainNames:   []string{"TON"},
+			},
+		},
+		{
+			name:          "TON address on EVM chain",
+			query:         "?address=EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF&chainName=eth",
+			expectedError: "address format not supported on chains: ETH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var result *types.TransactionQueryParams
+			var handlerErr error
+
+			app.Get("/tx", func(c *fiber.Ctx) error {
+				result, handlerErr = parseTransactionQueryParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tx"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestParseTransactionQueryParams_Starknet(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "STARKNET": 23448594291968334}
+	cfg.Starknet = []types.StarknetConfig{{ChainName: "STARKNET"}}
+	config.SetCurrentConfig(cfg)
+
+	const account = "0x0" + "4a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f"
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedError  string
+		expectedResult *types.TransactionQueryParams
+	}{
+		{
+			name:  "unpadded felt is padded and only Starknet chains",
+			query: "?address=0x4A1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    account,
+				ChainNames: []string{"STARKNET"},
+			},
+		},
+		{
+			name:  "token filter is padded",
+			query: "?address=" + account + "&tokenAddress=0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7",
+			expectedResult: &types.TransactionQueryParams{
+				Address:      account,
+				TokenAddress: "0x049d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7",
+				ChainNames:   []string{"STARKNET"},
+			},
+		},
+		{
+			name:  "EVM address still reads as EVM",
+			query: "?address=0x0123456789abcdef0123456789abcdef01234567",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "0x0123456789abcdef0123456789abcdef01234567",
+				ChainNames: []string{"ETH"},
+			},
+		},
+		{
+			name:  "EVM-length felt reaches Starknet when asked for",
+			query: "?address=0x0123456789abcdef0123456789abcdef01234567&chainName=starknet",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    "0x" + "000000000000000000000000" + "0123456789abcdef0123456789abcdef01234567",
+				ChainNames: []string{"STARKNET"},
+			},
+		},
+		{
+			name:          "Starknet address on EVM chain",
+			query:         "?address=" + account + "&chainName=eth",
+			expectedError: "address format not supported on chains: ETH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var result *types.TransactionQueryParams
+			var handlerErr error
+
+			app.Get("/tx", func(c *fiber.Ctx) error {
+				result, handlerErr = parseTransactionQueryParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tx"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestParseTransactionQueryParams_Aptos(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "APTOS": 900001}
+	cfg.Aptos = []types.AptosConfig{{ChainName: "APTOS"}}
+	config.SetCurrentConfig(cfg)
+
+	const account = "0x000000000000000000000000000000000000000000000000000000000000cafe"
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedError  string
+		expectedResult *types.TransactionQueryParams
+	}{
+		{
+			name:  "short address is padded and only Aptos chains",
+			query: "?address=0xCAFE",
+			expectedResult: &types.TransactionQueryParams{
+				Address:    account,
+				ChainNames: []string{"APTOS"},
+			},
+		},
+		{
+			name:  "coin type filter keeps its module path",
+			query: "?address=" + account + "&tokenAddress=0x1::aptos_coin::AptosCoin",
+			expectedResult: &types.TransactionQueryParams{
+				Address:      account,
+				TokenAddress: "0x0000000000000000000000000000000000000000000000000000000000000001::aptos_coin::AptosCoin",
+				ChainNames:   []string{"APTOS"},
+			},
+		},
+		{
+			name:          "invalid coin type",
+			query:         "?address=" + account + "&tokenAddress=aptos_coin::AptosCoin",
+			expectedError: "invalid token address: aptos_coin::AptosCoin",
+		},
+		{
+			name:          "Aptos address on EVM chain",
+			query:         "?address=" + account + "&chainName=eth",
+			expectedError: "address format not supported on chains: ETH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var result *types.TransactionQueryParams
+			var handlerErr error
+
+			app.Get("/tx", func(c *fiber.Ctx) error {
+				result, handlerErr = parseTransactionQueryParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tx"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, result)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestParseWatchParams(t *testing.T) {
+	setupTestConfig()
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedError string
+		webhook       string
+		ttl           time.Duration
+	}{
+		{
+			name:    "webhook and ttl",
+			query:   "?address=0x0123456789abcdef0123456789abcdef01234567&chainName=eth&webhookUrl=https://hooks.example.com/tx&ttlSeconds=3600",
+			webhook: "https://hooks.example.com/tx",
+			ttl:     time.Hour,
+		},
+		{
+			name:  "defaults",
+			query: "?address=0x0123456789abcdef0123456789abcdef01234567",
+		},
+		{
+			name:          "invalid ttlSeconds",
+			query:         "?address=0x0123456789abcdef0123456789abcdef01234567&ttlSeconds=0",
+			expectedError: "invalid ttlSeconds: 0",
+		},
+		{
+			name:          "token filter",
+			query:         "?address=0x0123456789abcdef0123456789abcdef01234567&tokenAddress=0x000000000000000000000000000000000000dead",
+			expectedError: "watches cover whole addresses, without token or block range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var (
+				params     *types.TransactionQueryParams
+				webhook    string
+				ttl        time.Duration
+				handlerErr error
+			)
+			app.Post("/watches", func(c *fiber.Ctx) error {
+				params, webhook, ttl, handlerErr = parseWatchParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/watches"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, params)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, "0x0123456789abcdef0123456789abcdef01234567", params.Address)
+				assert.Equal(t, tt.webhook, webhook)
+				assert.Equal(t, tt.ttl, ttl)
+			}
+		})
+	}
+}
+
+func TestParsePortfolioParams(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+	setupTestConfig()
+	cfg := config.Current()
+	cfg.Response.Max = 50
+	config.SetCurrentConfig(cfg)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedError string
+		recent        int
+	}{
+		{name: "default recent", query: "", recent: defaultPortfolioRecent},
+		{name: "recent", query: "&recent=3", recent: 3},
+		{name: "invalid recent", query: "&recent=-1", expectedError: "invalid recent: -1"},
+		{name: "recent over response.max", query: "&recent=51", expectedError: "invalid recent: 51"},
+		{
+			name:          "block range",
+			query:         "&startBlock=10",
+			expectedError: "portfolios cover whole addresses, without token or block range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var (
+				params     *types.TransactionQueryParams
+				recent     int
+				handlerErr error
+			)
+			app.Get("/portfolio", func(c *fiber.Ctx) error {
+				params, recent, handlerErr = parsePortfolioParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/portfolio?address=0x0123456789abcdef0123456789abcdef01234567"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, params)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.recent, recent)
+			}
+		})
+	}
+}
+
+func TestParseMinValue(t *testing.T) {
+	tests := []struct {
+		query         string
+		want          string
+		expectedError string
+	}{
+		{query: "", want: ""},
+		{query: "?minValue=0.001", want: "0.001"},
+		{query: "?minvalue=25", want: "25"},
+		{query: "?minValue=-1", expectedError: "invalid minValue: -1"},
+		{query: "?minValue=1e-6", expectedError: "invalid minValue: 1e-6"},
+		{query: "?minValue=.5", expectedError: "invalid minValue: .5"},
+		{query: "?minValue=1.", expectedError: "invalid minValue: 1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			app := fiber.New()
+
+			var (
+				got        string
+				handlerErr error
+			)
+			app.Get("/tx", func(c *fiber.Ctx) error {
+				got, handlerErr = parseMinValue(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tx"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseAllowanceParams(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+	setupTestConfig()
+	cfg := config.Current()
+	cfg.RPCURLs = map[string]string{"eth": "https://eth.rpc.example"}
+	config.SetCurrentConfig(cfg)
+
+	const (
+		owner   = "0x1111111111111111111111111111111111111111"
+		token   = "0x2222222222222222222222222222222222222222"
+		spender = "0x3333333333333333333333333333333333333333"
+	)
+	tests := []struct {
+		name          string
+		query         string
+		expectedError string
+	}{
+		{name: "valid", query: "?owner=" + owner + "&token=" + token + "&spender=" + spender + "&chainName=eth"},
+		{name: "missing spender", query: "?owner=" + owner + "&token=" + token + "&chainName=eth", expectedError: "spender parameter is required"},
+		{name: "invalid token", query: "?owner=" + owner + "&token=0x22&spender=" + spender + "&chainName=eth", expectedError: "invalid token: 0x22"},
+		{name: "several chains", query: "?owner=" + owner + "&token=" + token + "&spender=" + spender + "&chainName=eth,bsc", expectedError: "exactly one chainName is required"},
+		{name: "no RPC URL", query: "?owner=" + owner + "&token=" + token + "&spender=" + spender + "&chainName=bsc", expectedError: "no RPC URL configured for chain BSC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var (
+				params     *types.AllowanceParams
+				handlerErr error
+			)
+			app.Get("/allowance", func(c *fiber.Ctx) error {
+				params, handlerErr = parseAllowanceParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/allowance"+tt.query, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.Nil(t, params)
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, &types.AllowanceParams{ChainName: "ETH", Token: token, Owner: owner, Spender: spender}, params)
+			}
+		})
+	}
+}
+
+func TestParseReceiptParams(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+	setupTestConfig()
+	cfg := config.Current()
+	cfg.RPCURLs = map[string]string{"eth": "https://eth.rpc.example"}
+	config.SetCurrentConfig(cfg)
+
+	const hash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	tests := []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{name: "valid", path: "/receipt/eth/0x" + strings.ToUpper(hash[2:])},
+		{name: "invalid hash", path: "/receipt/eth/0x1234", expectedError: "invalid transaction hash: 0x1234"},
+		{name: "unknown chain", path: "/receipt/xxx/" + hash, expectedError: "unknown chain names: XXX"},
+		{name: "no RPC URL", path: "/receipt/bsc/" + hash, expectedError: "no RPC URL configured for chain BSC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+
+			var (
+				chain, got string
+				handlerErr error
+			)
+			app.Get("/receipt/:chain/:hash", func(c *fiber.Ctx) error {
+				chain, got, handlerErr = parseReceiptParams(c)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			_, _ = app.Test(req)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, handlerErr, tt.expectedError)
+			} else {
+				assert.NoError(t, handlerErr)
+				assert.Equal(t, "ETH", chain)
+				assert.Equal(t, hash, got)
+			}
+		})
+	}
+}
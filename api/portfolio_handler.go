@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+)
+
+// PortfolioHandler handles HTTP requests for address portfolios.
+type PortfolioHandler struct {
+	service interfaces.PortfolioServiceInterface
+}
+
+// NewPortfolioHandler initializes a new PortfolioHandler with the given service.
+func NewPortfolioHandler(service interfaces.PortfolioServiceInterface) *PortfolioHandler {
+	return &PortfolioHandler{service: service}
+}
+
+// GetPortfolio handles GET /portfolio?address=...&chainName=...&recent=...
+// It returns the tokens, balances and recent transactions of the address on
+// every requested chain, saving clients one request per chain and kind.
+func (h *PortfolioHandler) GetPortfolio(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	params, recent, err := parsePortfolioParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid portfolio parameters")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	portfolio, err := h.service.GetPortfolio(params, recent)
+	if err != nil {
+		log.Error().Err(err).Str("address", params.Address).Msg("❌ Failed to build portfolio")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeProviderFailed,
+			Message: types.GetMessageByCode(types.CodeProviderFailed),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  portfolio,
+	})
+}
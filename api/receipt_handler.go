@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+	"tx-aggregator/usecase"
+)
+
+// ReceiptHandler handles HTTP requests for transaction receipts.
+type ReceiptHandler struct {
+	service interfaces.ReceiptServiceInterface
+}
+
+// NewReceiptHandler initializes a new ReceiptHandler with the given service.
+func NewReceiptHandler(service interfaces.ReceiptServiceInterface) *ReceiptHandler {
+	return &ReceiptHandler{service: service}
+}
+
+// GetReceipt handles GET /receipt/:chain/:hash.
+// It returns the normalized receipt of the transaction, so that detail views
+// need no direct node access.
+func (h *ReceiptHandler) GetReceipt(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	chainName, hash, err := parseReceiptParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid receipt parameters")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	receipt, err := h.service.GetReceipt(chainName, hash)
+	if errors.Is(err, usecase.ErrReceiptNotFound) {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeNotFound,
+			Message: types.GetMessageByCode(types.CodeNotFound),
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("chain", chainName).Str("hash", hash).Msg("❌ Receipt lookup failed")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeProviderFailed,
+			Message: types.GetMessageByCode(types.CodeProviderFailed),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  receipt,
+	})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+)
+
+// TokensHandler handles HTTP requests for the tokens seen for an address.
+type TokensHandler struct {
+	service interfaces.TokenServiceInterface
+}
+
+// NewTokensHandler initializes a new TokensHandler with the given service.
+func NewTokensHandler(service interfaces.TokenServiceInterface) *TokensHandler {
+	return &TokensHandler{service: service}
+}
+
+// GetTokens handles GET /tokens?address=...&chainName=...
+// It returns the token contracts the address has transfers of, with their
+// symbol and decimals, so wallets can discover holdings without a token list.
+func (h *TokensHandler) GetTokens(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	params, err := parseTokensParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid tokens parameters")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	tokens, err := h.service.GetTokens(params)
+	if err != nil {
+		log.Error().Err(err).Str("address", params.Address).Msg("❌ Failed to list tokens")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeProviderFailed,
+			Message: types.GetMessageByCode(types.CodeProviderFailed),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  tokens,
+	})
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"tx-aggregator/logger"
+	"tx-aggregator/middleware"
+	"tx-aggregator/types"
+	"tx-aggregator/watch"
+)
+
+// anonymousOwner owns the watches created while authentication is disabled.
+const anonymousOwner = "anonymous"
+
+// WatchHandler handles HTTP requests that manage address watches. Every
+// watch belongs to the API key that created it.
+type WatchHandler struct {
+	watcher *watch.Watcher
+}
+
+// NewWatchHandler initializes a new WatchHandler with the given watcher.
+func NewWatchHandler(watcher *watch.Watcher) *WatchHandler {
+	return &WatchHandler{watcher: watcher}
+}
+
+// CreateWatch handles POST /watches?address=...&chainName=...&webhookUrl=...&ttlSeconds=...
+// and returns the new watch.
+func (h *WatchHandler) CreateWatch(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	params, webhookURL, ttl, err := parseWatchParams(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("❌ Invalid watch parameters")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInvalidParam,
+			Message: types.GetMessageByCode(types.CodeInvalidParam),
+		})
+	}
+
+	w, err := h.watcher.Create(watchOwner(ctx), params, webhookURL, ttl)
+	if err != nil {
+		code := types.CodeInternalError
+		if errors.Is(err, watch.ErrLimitReached) {
+			code = types.CodeInvalidParam
+		}
+		log.Warn().Err(err).Str("address", params.Address).Msg("❌ Failed to create watch")
+		return ctx.JSON(&types.APIResponse{
+			Code:    code,
+			Message: types.GetMessageByCode(code),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  &w,
+	})
+}
+
+// ListWatches handles GET /watches and returns the live watches of the
+// calling API key.
+func (h *WatchHandler) ListWatches(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	watches, err := h.watcher.List(watchOwner(ctx))
+	if err != nil {
+		log.Error().Err(err).Msg("❌ Failed to list watches")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInternalError,
+			Message: types.GetMessageByCode(types.CodeInternalError),
+		})
+	}
+	if watches == nil {
+		watches = []types.Watch{}
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+		Result:  watches,
+	})
+}
+
+// DeleteWatch handles DELETE /watches/:id. Watches of other API keys are
+// reported as not found.
+func (h *WatchHandler) DeleteWatch(ctx *fiber.Ctx) error {
+	log := logger.ForRequest(middleware.RequestIDFromCtx(ctx))
+	ok, err := h.watcher.Delete(watchOwner(ctx), ctx.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("❌ Failed to delete watch")
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeInternalError,
+			Message: types.GetMessageByCode(types.CodeInternalError),
+		})
+	}
+	if !ok {
+		return ctx.JSON(&types.APIResponse{
+			Code:    types.CodeNotFound,
+			Message: types.GetMessageByCode(types.CodeNotFound),
+		})
+	}
+	return ctx.JSON(&types.APIResponse{
+		Code:    types.CodeSuccess,
+		Message: types.GetMessageByCode(types.CodeSuccess),
+	})
+}
+
+// watchOwner is the name of the API key of the request.
+func watchOwner(ctx *fiber.Ctx) string {
+	if key, ok := middleware.APIKeyFromCtx(ctx); ok && key.Name != "" {
+		return key.Name
+	}
+	return anonymousOwner
+}
@@ -0,0 +1,232 @@
+// Package archive keeps gzip-compressed copies of raw provider responses in
+// S3-compatible object storage, keyed by date and request hash:
+//
+//	<prefix>/<yyyy-mm-dd>/<label>/<sha256 of method, URL and body>.json.gz
+//
+// so that historical data can be re-normalized after classifier changes
+// without querying providers again. Payloads are captured through the
+// utils.PayloadRecorder hook and uploaded in the background; whole days older
+// than the retention period are deleted once a day.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultRegion        = "us-east-1"
+	defaultPrefix        = "raw"
+	defaultRetentionDays = 90
+	defaultQueueSize     = 1000
+	sweepInterval        = 24 * time.Hour
+	dayLayout            = "2006-01-02"
+)
+
+// skippedLabels are request label prefixes that are not provider payloads.
+var skippedLabels = []string{"regression."}
+
+// entry is one captured response waiting for upload.
+type entry struct {
+	label, method, url string
+	reqBody, respBody  []byte
+	fetchedAt          time.Time
+}
+
+// Archiver uploads captured provider responses and applies the retention
+// policy.
+type Archiver struct {
+	cfg   types.ArchiveConfig
+	store objectStore
+	queue chan entry
+	now   func() time.Time
+}
+
+// NewArchiver builds an Archiver from cfg, filling in defaults. It returns
+// nil when archiving is disabled.
+func NewArchiver(cfg types.ArchiveConfig) *Archiver {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	if cfg.RetentionDays == 0 {
+		cfg.RetentionDays = defaultRetentionDays
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	logger.Log.Info().
+		Str("endpoint", cfg.Endpoint).
+		Str("bucket", cfg.Bucket).
+		Str("prefix", cfg.Prefix).
+		Int("retention_days", cfg.RetentionDays).
+		Msg("Initializing payload archive")
+
+	return newArchiver(cfg, newS3Store(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey))
+}
+
+func newArchiver(cfg types.ArchiveConfig, store objectStore) *Archiver {
+	return &Archiver{
+		cfg:   cfg,
+		store: store,
+		queue: make(chan entry, cfg.QueueSize),
+		now:   time.Now,
+	}
+}
+
+// Record queues one response for upload. It never blocks: when the queue is
+// full the payload is dropped and counted. Non-JSON bodies and internal
+// requests (alerts) are ignored. Record has the utils.PayloadRecorder
+// signature.
+func (a *Archiver) Record(label, method, rawURL string, reqBody, respBody []byte) {
+	for _, p := range skippedLabels {
+		if strings.HasPrefix(label, p) {
+			return
+		}
+	}
+	if !json.Valid(respBody) {
+		return
+	}
+	select {
+	case a.queue <- entry{label, method, rawURL, reqBody, respBody, a.now()}:
+	default:
+		metrics.ArchiveFailures.WithLabelValues("queue_full").Inc()
+	}
+}
+
+// Start runs the uploader and the daily retention sweep in the background.
+func (a *Archiver) Start() {
+	go func() {
+		for e := range a.queue {
+			a.upload(e)
+		}
+	}()
+
+	if a.cfg.RetentionDays < 0 {
+		return // keep forever
+	}
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := a.Sweep(); err != nil {
+				logger.Log.Warn().Err(err).Int("deleted", n).Msg("Archive retention sweep failed")
+			} else {
+				logger.Log.Info().Int("deleted", n).Msg("Archive retention sweep finished")
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// upload encodes and stores one entry.
+func (a *Archiver) upload(e entry) {
+	body, err := encode(types.ArchivedPayload{
+		Label:       e.label,
+		Method:      e.method,
+		URL:         utils.RedactURL(e.url),
+		RequestBody: validJSON(e.reqBody),
+		Response:    e.respBody,
+		FetchedAt:   e.fetchedAt.Unix(),
+	})
+	if err != nil {
+		metrics.ArchiveFailures.WithLabelValues("encode").Inc()
+		logger.Log.Warn().Err(err).Str("label", e.label).Msg("Failed to encode archived payload")
+		return
+	}
+
+	key := a.key(e)
+	if err := a.store.Put(key, body); err != nil {
+		metrics.ArchiveFailures.WithLabelValues("upload").Inc()
+		logger.Log.Warn().Err(err).Str("key", key).Msg("Failed to archive provider payload")
+		return
+	}
+	metrics.ArchivedPayloads.Inc()
+	logger.Log.Debug().Str("key", key).Int("size", len(body)).Msg("Provider payload archived")
+}
+
+// key returns the object key of an entry. The hash covers the unredacted
+// request, so the same request made twice on one day overwrites itself.
+func (a *Archiver) key(e entry) string {
+	h := sha256.New()
+	h.Write([]byte(e.method + "\n" + e.url + "\n"))
+	h.Write(e.reqBody)
+	return path.Join(
+		a.cfg.Prefix,
+		e.fetchedAt.UTC().Format(dayLayout),
+		e.label,
+		hex.EncodeToString(h.Sum(nil))+".json.gz",
+	)
+}
+
+// Sweep deletes every day older than the retention period and returns the
+// number of objects removed.
+func (a *Archiver) Sweep() (int, error) {
+	cutoff := a.now().UTC().AddDate(0, 0, -a.cfg.RetentionDays).Format(dayLayout)
+
+	_, days, err := a.store.List(a.cfg.Prefix+"/", "/")
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, dayPrefix := range days {
+		day := path.Base(strings.TrimSuffix(dayPrefix, "/"))
+		if _, err := time.Parse(dayLayout, day); err != nil || day >= cutoff {
+			continue
+		}
+		keys, _, err := a.store.List(dayPrefix, "")
+		if err != nil {
+			return deleted, err
+		}
+		for _, k := range keys {
+			if err := a.store.Delete(k); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// encode returns p as gzip-compressed JSON.
+func encode(p types.ArchivedPayload) ([]byte, error) {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// validJSON returns b when it is a JSON document, else nil.
+func validJSON(b []byte) json.RawMessage {
+	if len(b) == 0 || !json.Valid(b) {
+		return nil
+	}
+	return b
+}
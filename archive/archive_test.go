@@ -0,0 +1,122 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+// memStore is an in-memory objectStore.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemStore() *memStore { return &memStore{objects: make(map[string][]byte)} }
+
+func (m *memStore) Put(key string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = body
+	return nil
+}
+
+func (m *memStore) List(prefix, delimiter string) (keys, prefixes []string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := map[string]bool{}
+	for k := range m.objects {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(k, prefix)
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			p := prefix + rest[:i+1]
+			if !seen[p] {
+				seen[p] = true
+				prefixes = append(prefixes, p)
+			}
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sort.Strings(prefixes)
+	return keys, prefixes, nil
+}
+
+func (m *memStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func testArchiver(store objectStore, now time.Time) *Archiver {
+	a := newArchiver(types.ArchiveConfig{Prefix: "raw", RetentionDays: 30, QueueSize: 1}, store)
+	a.now = func() time.Time { return now }
+	return a
+}
+
+func TestArchiver_Upload(t *testing.T) {
+	store := newMemStore()
+	now := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	a := testArchiver(store, now)
+
+	a.Record("blockscan.txlist", "GET", "https://api.example.com/api?module=account&apikey=SECRET", nil, []byte(`{"result":[]}`))
+	a.upload(<-a.queue)
+
+	keys, _, _ := store.List("raw/2025-03-04/blockscan.txlist/", "")
+	assert.Len(t, keys, 1)
+	assert.True(t, strings.HasSuffix(keys[0], ".json.gz"))
+
+	zr, err := gzip.NewReader(bytes.NewReader(store.objects[keys[0]]))
+	assert.NoError(t, err)
+	raw, _ := io.ReadAll(zr)
+
+	var p types.ArchivedPayload
+	assert.NoError(t, json.Unmarshal(raw, &p))
+	assert.Equal(t, "blockscan.txlist", p.Label)
+	assert.JSONEq(t, `{"result":[]}`, string(p.Response))
+	assert.NotContains(t, p.URL, "SECRET")
+	assert.Contains(t, p.URL, "module=account")
+	assert.Equal(t, now.Unix(), p.FetchedAt)
+}
+
+func TestArchiver_RecordSkips(t *testing.T) {
+	a := testArchiver(newMemStore(), time.Now())
+
+	a.Record("regression.webhook", "POST", "https://hooks.example.com", nil, []byte(`{}`))
+	a.Record("p.page", "GET", "https://example.com", nil, []byte(`not json`))
+	assert.Len(t, a.queue, 0)
+
+	a.Record("p.page", "GET", "https://example.com", nil, []byte(`{}`))
+	a.Record("p.page", "GET", "https://example.com/2", nil, []byte(`{}`)) // queue full, dropped
+	assert.Len(t, a.queue, 1)
+}
+
+func TestArchiver_Sweep(t *testing.T) {
+	store := newMemStore()
+	a := testArchiver(store, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC))
+
+	_ = store.Put("raw/2025-02-28/x/a.json.gz", nil) // older than 30 days
+	_ = store.Put("raw/2025-02-28/y/b.json.gz", nil)
+	_ = store.Put("raw/2025-03-01/x/c.json.gz", nil) // exactly at the cutoff, kept
+	_ = store.Put("raw/2025-03-30/x/d.json.gz", nil)
+	_ = store.Put("other/2020-01-01/x/e.json.gz", nil) // outside the prefix
+
+	n, err := a.Sweep()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, store.objects, 3)
+	assert.Contains(t, store.objects, "raw/2025-03-01/x/c.json.gz")
+}
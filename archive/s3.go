@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tx-aggregator/utils"
+)
+
+// objectStore is the subset of S3 used by the archive.
+type objectStore interface {
+	Put(key string, body []byte) error
+	// List returns the keys under prefix and, when delimiter is set, the
+	// common prefixes rolled up at the delimiter.
+	List(prefix, delimiter string) (keys, prefixes []string, err error)
+	Delete(key string) error
+}
+
+// s3Store talks to an S3-compatible endpoint with path-style addressing and
+// SigV4 request signing.
+type s3Store struct {
+	endpoint string // scheme://host[:port], no trailing slash
+	bucket   string
+	auth     *utils.SigV4Auth
+	client   *http.Client
+}
+
+func newS3Store(endpoint, region, bucket, accessKey, secretKey string) *s3Store {
+	return &s3Store{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		bucket:   bucket,
+		auth: &utils.SigV4Auth{
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+			Region:    region,
+			Service:   "s3",
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Put uploads one gzip-compressed JSON object.
+func (s *s3Store) Put(key string, body []byte) error {
+	_, err := s.do("PUT", key, nil, body, map[string]string{
+		"Content-Type":     "application/json",
+		"Content-Encoding": "gzip",
+	})
+	return err
+}
+
+// listBucketResult is the ListObjectsV2 reply.
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2.
+func (s *s3Store) List(prefix, delimiter string) (keys, prefixes []string, err error) {
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if delimiter != "" {
+			q.Set("delimiter", delimiter)
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		body, err := s.do("GET", "", q, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		var res listBucketResult
+		if err := xml.Unmarshal(body, &res); err != nil {
+			return nil, nil, fmt.Errorf("decode list result: %w", err)
+		}
+		for _, c := range res.Contents {
+			keys = append(keys, c.Key)
+		}
+		for _, p := range res.CommonPrefixes {
+			prefixes = append(prefixes, p.Prefix)
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			return keys, prefixes, nil
+		}
+		token = res.NextContinuationToken
+	}
+}
+
+// Delete removes one object.
+func (s *s3Store) Delete(key string) error {
+	_, err := s.do("DELETE", key, nil, nil, nil)
+	return err
+}
+
+// do sends one signed request against the bucket (or an object in it when
+// key is set) and returns the response body.
+func (s *s3Store) do(method, key string, query url.Values, body []byte, headers map[string]string) ([]byte, error) {
+	u := s.endpoint + "/" + url.PathEscape(s.bucket)
+	if key != "" {
+		segments := strings.Split(key, "/")
+		for i, seg := range segments {
+			segments[i] = url.PathEscape(seg)
+		}
+		u += "/" + strings.Join(segments, "/")
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create %s request failed: %w", method, err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if err := s.auth.Sign(req, body); err != nil {
+		return nil, fmt.Errorf("sign %s request failed: %w", method, err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send %s %s failed: %w", method, key, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s response failed: %w", method, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, key, resp.StatusCode, truncate(respBody, 256))
+	}
+	return respBody, nil
+}
+
+// truncate returns at most n bytes of b as a string.
+func truncate(b []byte, n int) string {
+	if len(b) > n {
+		b = b[:n]
+	}
+	return string(b)
+}
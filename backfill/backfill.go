@@ -0,0 +1,146 @@
+// Package backfill warms the cache of a newly added chain for every address
+// on the watch list, so launch day does not turn into a cache-miss storm.
+// Addresses are fetched one at a time, rate-limited, with deep pagination.
+package backfill
+
+import (
+	"strings"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/jobs"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultRequestsPerMinute = 30
+	defaultMaxPages          = 100
+	maxJobs                  = 50 // finished jobs kept in memory before eviction
+)
+
+// Backfiller runs backfill jobs, at most one per chain at a time.
+type Backfiller struct {
+	warmer interfaces.TransactionWarmerInterface
+	jobs   *jobs.Registry[types.BackfillJob] // keyed by chain name while running
+}
+
+// NewBackfiller creates a Backfiller that caches through warmer (normally
+// the usecase Service).
+func NewBackfiller(warmer interfaces.TransactionWarmerInterface) *Backfiller {
+	return &Backfiller{
+		warmer: warmer,
+		jobs: jobs.NewRegistry(maxJobs, func(job *types.BackfillJob, now int64) {
+			job.UpdatedTime = now
+		}),
+	}
+}
+
+// Submit starts a backfill of chainName over the watch list in the
+// background. If one is already running for the chain, that job is returned
+// instead of starting another.
+func (b *Backfiller) Submit(chainName string) types.BackfillJob {
+	chainName = strings.ToUpper(chainName)
+	addresses := config.Current().Backfill.WatchList
+
+	now := time.Now().Unix()
+	job, added := b.jobs.Add(chainName, func(id string) *types.BackfillJob {
+		return &types.BackfillJob{
+			ID:          id,
+			ChainName:   chainName,
+			Status:      types.ExportStatusPending,
+			Addresses:   len(addresses),
+			CreatedTime: now,
+			UpdatedTime: now,
+		}
+	})
+	if !added {
+		return job
+	}
+
+	logger.Log.Info().
+		Str("job_id", job.ID).
+		Str("chain", chainName).
+		Int("addresses", len(addresses)).
+		Msg("Backfill submitted")
+
+	b.jobs.Go(job.ID, func() { b.run(job.ID, chainName, addresses) }, func(job *types.BackfillJob, _ error) {
+		job.Status = types.ExportStatusFailed
+	})
+	return job
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (b *Backfiller) Get(id string) (types.BackfillJob, bool) {
+	return b.jobs.Get(id)
+}
+
+// run warms every address on chainName, spacing requests by the configured
+// rate. A failing address is counted and skipped; the job only fails when
+// every address failed.
+func (b *Backfiller) run(id, chainName string, addresses []string) {
+	cfg := config.Current().Backfill
+	rate := cfg.RequestsPerMinute
+	if rate <= 0 {
+		rate = defaultRequestsPerMinute
+	}
+	maxPages := cfg.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	b.jobs.Update(id, func(job *types.BackfillJob) { job.Status = types.ExportStatusRunning })
+	start := time.Now()
+
+	ticker := time.NewTicker(time.Minute / time.Duration(rate))
+	defer ticker.Stop()
+
+	for i, address := range addresses {
+		if i > 0 {
+			<-ticker.C
+		}
+		count, err := b.warmer.WarmTransactions(&types.TransactionQueryParams{
+			Address:    normalizeAddress(chainName, address),
+			ChainNames: []string{chainName},
+			MaxPages:   maxPages,
+		})
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("job_id", id).Str("address", address).Str("chain", chainName).Msg("Backfill address failed")
+			b.jobs.Update(id, func(job *types.BackfillJob) { job.Failed++ })
+			continue
+		}
+		b.jobs.Update(id, func(job *types.BackfillJob) {
+			job.Done++
+			job.Transactions += count
+		})
+	}
+
+	b.jobs.Release(chainName)
+
+	b.jobs.Update(id, func(job *types.BackfillJob) {
+		job.Status = types.ExportStatusDone
+		if job.Failed > 0 && job.Done == 0 {
+			job.Status = types.ExportStatusFailed
+		}
+		logger.Log.Info().
+			Str("job_id", id).
+			Str("chain", chainName).
+			Int("done", job.Done).
+			Int("failed", job.Failed).
+			Int("transactions", job.Transactions).
+			Dur("cost", time.Since(start)).
+			Msg("Backfill finished")
+	})
+}
+
+// normalizeAddress converts address to the canonical spelling of the
+// address family of chainName, like the API does. Addresses the family does
+// not accept are kept as is and fail upstream.
+func normalizeAddress(chainName, address string) string {
+	if normalized, ok := utils.NormalizeAddress(utils.ChainAddressFamily(chainName), address); ok {
+		return normalized
+	}
+	return address
+}
@@ -0,0 +1,94 @@
+package backfill
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// stubWarmer records the warmed params and fails for addresses in fail.
+type stubWarmer struct {
+	mu     sync.Mutex
+	params []types.TransactionQueryParams
+	fail   map[string]bool
+}
+
+func (s *stubWarmer) WarmTransactions(params *types.TransactionQueryParams) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = append(s.params, *params)
+	if s.fail[params.Address] {
+		return 0, errors.New("boom")
+	}
+	return 3, nil
+}
+
+func waitForJob(t *testing.T, b *Backfiller, id string) types.BackfillJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := b.Get(id)
+		assert.True(t, ok)
+		if job.Status == types.ExportStatusDone || job.Status == types.ExportStatusFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return types.BackfillJob{}
+}
+
+func TestBackfiller_WarmsWatchList(t *testing.T) {
+	defer config.SetCurrentConfig(types.Config{})
+	config.SetCurrentConfig(types.Config{Backfill: types.BackfillConfig{
+		WatchList: []string{
+			"0xAbCdEf0123456789aBcDeF0123456789AbCdEf01",
+			"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2",
+			"0x0000000000000000000000000000000000000bad",
+		},
+		RequestsPerMinute: 60000,
+		MaxPages:          50,
+	}})
+
+	warmer := &stubWarmer{fail: map[string]bool{"0x0000000000000000000000000000000000000bad": true}}
+	b := NewBackfiller(warmer)
+	job := b.Submit("eth")
+	assert.Equal(t, "ETH", job.ChainName)
+	assert.Equal(t, 3, job.Addresses)
+
+	done := waitForJob(t, b, job.ID)
+	assert.Equal(t, types.ExportStatusDone, done.Status)
+	assert.Equal(t, 2, done.Done)
+	assert.Equal(t, 1, done.Failed)
+	assert.Equal(t, 6, done.Transactions)
+
+	assert.Len(t, warmer.params, 3)
+	assert.Equal(t, "0xabcdef0123456789abcdef0123456789abcdef01", warmer.params[0].Address)
+	assert.Equal(t, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", warmer.params[1].Address)
+	assert.Equal(t, []string{"ETH"}, warmer.params[0].ChainNames)
+	assert.Equal(t, int64(50), warmer.params[0].MaxPages)
+}
+
+func TestBackfiller_OneJobPerChain(t *testing.T) {
+	defer config.SetCurrentConfig(types.Config{})
+	config.SetCurrentConfig(types.Config{Backfill: types.BackfillConfig{
+		WatchList:         []string{"0x0000000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"},
+		RequestsPerMinute: 600, // 100ms between addresses keeps the job running
+	}})
+
+	b := NewBackfiller(&stubWarmer{})
+	first := b.Submit("ETH")
+	second := b.Submit("ETH")
+	assert.Equal(t, first.ID, second.ID)
+
+	done := waitForJob(t, b, first.ID)
+	assert.Equal(t, types.ExportStatusDone, done.Status)
+	third := b.Submit("ETH")
+	assert.NotEqual(t, first.ID, third.ID)
+	waitForJob(t, b, third.ID)
+}
@@ -0,0 +1,275 @@
+// Package benchmark periodically measures every provider able to serve a
+// chain against a sample of addresses and ranks them by completeness (share
+// of the union of all providers' transactions) and latency. The latest
+// ranking is exposed at /admin/provider-benchmark and, with benchmark.route,
+// orders the providers a request tries (see provider.MultiProvider.SetRanking).
+package benchmark
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+const defaultIntervalMinutes = 60
+
+// Runner executes benchmark runs and keeps the latest report.
+type Runner struct {
+	registry provider.Registry
+
+	mu     sync.RWMutex
+	report *types.BenchmarkReport
+}
+
+// NewRunner creates a Runner over the provider registry.
+func NewRunner(registry provider.Registry) *Runner {
+	return &Runner{registry: registry}
+}
+
+// Start runs a benchmark immediately and then every interval_minutes in the
+// background. It does nothing unless benchmark.enabled is set.
+func (r *Runner) Start() {
+	cfg := config.Current().Benchmark
+	if !cfg.Enabled {
+		return
+	}
+	interval := cfg.IntervalMinutes
+	if interval <= 0 {
+		interval = defaultIntervalMinutes
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			r.Run()
+			<-ticker.C
+		}
+	}()
+	logger.Log.Info().Int("interval_minutes", interval).Msg("Provider benchmark scheduled")
+}
+
+// Report returns the latest report, if a run has finished.
+func (r *Runner) Report() (*types.BenchmarkReport, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.report, r.report != nil
+}
+
+// Ranking returns the provider keys of chain ordered best first, or nil when
+// the chain has not been benchmarked.
+func (r *Runner) Ranking(chain string) []string {
+	report, ok := r.Report()
+	if !ok {
+		return nil
+	}
+	for _, c := range report.Chains {
+		if strings.EqualFold(c.ChainName, chain) {
+			keys := make([]string, len(c.Providers))
+			for i, p := range c.Providers {
+				keys[i] = p.Provider
+			}
+			return keys
+		}
+	}
+	return nil
+}
+
+// Run benchmarks every configured chain once and stores the report.
+func (r *Runner) Run() *types.BenchmarkReport {
+	cfg := config.Current()
+	start := time.Now()
+
+	chains := make([]string, 0, len(cfg.Benchmark.Addresses))
+	for chain := range cfg.Benchmark.Addresses {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+
+	report := &types.BenchmarkReport{GeneratedTime: start.Unix()}
+	for _, chain := range chains {
+		candidates := r.candidates(chain, &cfg)
+		if len(candidates) == 0 {
+			logger.Log.Warn().Str("chain", chain).Msg("No benchmark candidates for chain")
+			continue
+		}
+		report.Chains = append(report.Chains, r.benchmarkChain(chain, cfg.Benchmark.Addresses[chain], candidates))
+	}
+
+	r.mu.Lock()
+	r.report = report
+	r.mu.Unlock()
+
+	logger.Log.Info().
+		Int("chains", len(report.Chains)).
+		Dur("cost", time.Since(start)).
+		Msg("Provider benchmark finished")
+	return report
+}
+
+// candidates returns the registered provider keys to benchmark for chain.
+func (r *Runner) candidates(chain string, cfg *types.Config) map[string]provider.Provider {
+	chain = strings.ToLower(chain)
+	registry := r.registry.Providers()
+	out := make(map[string]provider.Provider)
+
+	if keys, ok := cfg.Benchmark.Candidates[chain]; ok {
+		for _, key := range keys {
+			if p, ok := registry[key]; ok {
+				out[key] = p
+			}
+		}
+		return out
+	}
+
+	for _, key := range cfg.Providers.ChainProviders[chain] {
+		if p, ok := registry[key]; ok {
+			out[key] = p
+		}
+	}
+	for key, p := range registry {
+		if strings.HasSuffix(key, "_"+chain) {
+			out[key] = p
+		}
+	}
+	return out
+}
+
+// sample is one provider's answer for one address.
+type sample struct {
+	keys    map[string]struct{}
+	latency time.Duration
+	err     error
+}
+
+// benchmarkChain queries every candidate for each address concurrently and
+// aggregates the samples into a ranked ChainBenchmark.
+func (r *Runner) benchmarkChain(chain string, addresses []string, candidates map[string]provider.Provider) types.ChainBenchmark {
+	type totals struct {
+		completeness float64
+		latency      time.Duration
+		maxLatency   time.Duration
+		calls        int
+		errors       int
+	}
+	agg := make(map[string]*totals, len(candidates))
+	for key := range candidates {
+		agg[key] = &totals{}
+	}
+
+	for _, address := range addresses {
+		samples := make(map[string]*sample, len(candidates))
+		var (
+			wg sync.WaitGroup
+			mu sync.Mutex
+		)
+		for key, p := range candidates {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s := measure(p, chain, address)
+				mu.Lock()
+				samples[key] = s
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		union := make(map[string]struct{})
+		for _, s := range samples {
+			for k := range s.keys {
+				union[k] = struct{}{}
+			}
+		}
+
+		for key, s := range samples {
+			t := agg[key]
+			t.calls++
+			t.latency += s.latency
+			if s.latency > t.maxLatency {
+				t.maxLatency = s.latency
+			}
+			switch {
+			case s.err != nil:
+				t.errors++
+				logger.Log.Warn().Err(s.err).Str("provider", key).Str("address", address).Msg("Benchmark call failed")
+			case len(union) == 0:
+				t.completeness++
+			default:
+				t.completeness += float64(len(s.keys)) / float64(len(union))
+			}
+		}
+	}
+
+	result := types.ChainBenchmark{ChainName: chain, Addresses: len(addresses)}
+	for key, t := range agg {
+		pb := types.ProviderBenchmark{Provider: key, Errors: t.errors}
+		if t.calls > 0 {
+			pb.Completeness = t.completeness / float64(t.calls)
+			pb.AvgLatencyMs = (t.latency / time.Duration(t.calls)).Milliseconds()
+			pb.MaxLatencyMs = t.maxLatency.Milliseconds()
+		}
+		result.Providers = append(result.Providers, pb)
+	}
+	rank(result.Providers)
+	return result
+}
+
+// rank orders providers by completeness (desc), then average latency (asc),
+// then key, and assigns 1-based ranks.
+func rank(providers []types.ProviderBenchmark) {
+	sort.Slice(providers, func(i, j int) bool {
+		a, b := providers[i], providers[j]
+		if a.Completeness != b.Completeness {
+			return a.Completeness > b.Completeness
+		}
+		if a.AvgLatencyMs != b.AvgLatencyMs {
+			return a.AvgLatencyMs < b.AvgLatencyMs
+		}
+		return a.Provider < b.Provider
+	})
+	for i := range providers {
+		providers[i].Rank = i + 1
+	}
+}
+
+// measure calls one provider for one address and returns the set of
+// transaction identities it reported.
+func measure(p provider.Provider, chain, address string) *sample {
+	start := time.Now()
+	resp, err := p.GetTransactions(&types.TransactionQueryParams{
+		Address:    address,
+		ChainNames: []string{chain},
+	})
+	s := &sample{latency: time.Since(start), err: err}
+	if err != nil {
+		return s
+	}
+
+	s.keys = make(map[string]struct{}, len(resp.Result.Transactions))
+	for _, tx := range resp.Result.Transactions {
+		s.keys[identity(tx)] = struct{}{}
+	}
+	return s
+}
+
+// identity distinguishes the movements of one transaction across providers.
+func identity(tx types.Transaction) string {
+	return fmt.Sprintf("%d|%s|%d|%s|%d|%s|%s",
+		tx.ChainID,
+		strings.ToLower(tx.Hash),
+		tx.CoinType,
+		strings.ToLower(tx.TokenAddress),
+		tx.TokenID,
+		strings.ToLower(tx.FromAddress),
+		strings.ToLower(tx.ToAddress),
+	)
+}
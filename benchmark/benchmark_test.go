@@ -0,0 +1,87 @@
+package benchmark
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+// stubProvider returns a fixed set of hashes after an optional delay.
+type stubProvider struct {
+	hashes []string
+	delay  time.Duration
+	err    error
+}
+
+func (s *stubProvider) GetTransactions(*types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	time.Sleep(s.delay)
+	if s.err != nil {
+		return nil, s.err
+	}
+	resp := &types.TransactionResponse{}
+	for _, h := range s.hashes {
+		resp.Result.Transactions = append(resp.Result.Transactions, types.Transaction{ChainID: 1, Hash: h})
+	}
+	return resp, nil
+}
+
+func TestRun_RanksByCompletenessThenLatency(t *testing.T) {
+	cfg := types.Config{
+		Providers: types.ProvidersConfig{ChainProviders: map[string][]string{"eth": {"ankr"}}},
+		Benchmark: types.BenchmarkConfig{Addresses: map[string][]string{"eth": {"0xabc"}}},
+	}
+	config.SetCurrentConfig(cfg)
+	defer config.SetCurrentConfig(types.Config{})
+
+	r := NewRunner(provider.StaticRegistry{
+		"ankr":           &stubProvider{hashes: []string{"0x1", "0x2"}},
+		"alchemy_eth":    &stubProvider{hashes: []string{"0x1", "0x2", "0x3", "0x4"}, delay: 20 * time.Millisecond},
+		"blockscout_eth": &stubProvider{hashes: []string{"0x1", "0x2", "0x3", "0x4"}},
+		"oklink_eth":     &stubProvider{err: errors.New("boom")},
+		"alchemy_bsc":    &stubProvider{hashes: []string{"0x9"}},
+	})
+
+	_, ok := r.Report()
+	assert.False(t, ok)
+
+	report := r.Run()
+	assert.Len(t, report.Chains, 1)
+	providers := report.Chains[0].Providers
+	assert.Len(t, providers, 4)
+
+	assert.Equal(t, "blockscout_eth", providers[0].Provider)
+	assert.Equal(t, 1.0, providers[0].Completeness)
+	assert.Equal(t, "alchemy_eth", providers[1].Provider)
+	assert.Equal(t, "ankr", providers[2].Provider)
+	assert.Equal(t, 0.5, providers[2].Completeness)
+	assert.Equal(t, "oklink_eth", providers[3].Provider)
+	assert.Equal(t, 1, providers[3].Errors)
+	assert.Equal(t, 4, providers[3].Rank)
+
+	assert.Equal(t, []string{"blockscout_eth", "alchemy_eth", "ankr", "oklink_eth"}, r.Ranking("ETH"))
+	assert.Nil(t, r.Ranking("bsc"))
+}
+
+func TestRun_ExplicitCandidates(t *testing.T) {
+	cfg := types.Config{
+		Benchmark: types.BenchmarkConfig{
+			Addresses:  map[string][]string{"eth": {"0xabc"}},
+			Candidates: map[string][]string{"eth": {"alchemy_eth", "missing"}},
+		},
+	}
+	config.SetCurrentConfig(cfg)
+	defer config.SetCurrentConfig(types.Config{})
+
+	r := NewRunner(provider.StaticRegistry{
+		"alchemy_eth":    &stubProvider{hashes: []string{"0x1"}},
+		"blockscout_eth": &stubProvider{hashes: []string{"0x1"}},
+	})
+	report := r.Run()
+	assert.Len(t, report.Chains[0].Providers, 1)
+	assert.Equal(t, "alchemy_eth", report.Chains[0].Providers[0].Provider)
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+	"tx-aggregator/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultBloomBits   = 1 << 24 // 2 MiB per chain; ~1% false positives at 1.7M addresses with 7 hashes
+	defaultBloomHashes = 7
+	defaultEmptyTTL    = 10 * time.Minute
+)
+
+// formatBloomKey is the bitmap of the Bloom filter of addresses with
+// history on chainName.
+func formatBloomKey(chainName string) string {
+	return keyPrefix() + "bloom:" + strings.ToLower(chainName)
+}
+
+// formatDiscoveryKey counts the fetches of unknown addresses in the minute
+// starting at t.
+func formatDiscoveryKey(t time.Time) string {
+	return keyPrefix() + "bloom-discovery:" + strconv.FormatInt(t.Unix()/60, 10)
+}
+
+// formatEmptyKey marks address as fetched from chainName's providers with
+// no transactions.
+func formatEmptyKey(chainName, address string) string {
+	return keyPrefix() + "bloom-empty:" + strings.ToLower(chainName) + ":" + strings.ToLower(address)
+}
+
+// bloomOffsets returns the bit positions of address in a filter of bits
+// bits with hashes hash functions, by double hashing one SHA-256 digest.
+func bloomOffsets(address string, bits uint64, hashes int) []int64 {
+	sum := sha256.Sum256([]byte(strings.ToLower(address)))
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	offsets := make([]int64, hashes)
+	for i := range offsets {
+		offsets[i] = int64((h1 + uint64(i)*h2) % bits)
+	}
+	return offsets
+}
+
+// bloomParams returns the configured filter size and number of hashes.
+func bloomParams() (uint64, int) {
+	cfg := config.Current().Redis.Bloom
+	bits, hashes := cfg.Bits, cfg.Hashes
+	if bits == 0 {
+		bits = defaultBloomBits
+	}
+	if hashes <= 0 {
+		hashes = defaultBloomHashes
+	}
+	return bits, hashes
+}
+
+// BloomAdd records that address has history on chainName.
+func (r *RedisCache) BloomAdd(chainName, address string) error {
+	bits, hashes := bloomParams()
+	key := formatBloomKey(chainName)
+	pipe := r.conn().Pipeline()
+	for _, off := range bloomOffsets(address, bits, hashes) {
+		pipe.SetBit(r.ctx, key, off, 1)
+	}
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// BloomMayContain reports whether address may have history on chainName.
+// false is definite: the address was never added.
+func (r *RedisCache) BloomMayContain(chainName, address string) (bool, error) {
+	bits, hashes := bloomParams()
+	key := formatBloomKey(chainName)
+	pipe := r.conn().Pipeline()
+	cmds := make([]*redis.IntCmd, hashes)
+	for i, off := range bloomOffsets(address, bits, hashes) {
+		cmds[i] = pipe.GetBit(r.ctx, key, off)
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return false, err
+	}
+	for _, c := range cmds {
+		if c.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// TakeDiscoveryToken reports whether another fetch of an address unknown to
+// the Bloom filters may go to the providers this minute, counting it. The
+// budget of perMinute is shared by every instance.
+func (r *RedisCache) TakeDiscoveryToken(perMinute int) (bool, error) {
+	return r.takeMinuteToken(formatDiscoveryKey(time.Now()), perMinute)
+}
+
+// MarkEmpty records that a fetch of address on chainName found no
+// transactions, for redis.bloom.empty_ttl_seconds (default 10 minutes).
+func (r *RedisCache) MarkEmpty(chainName, address string) error {
+	ttl := time.Duration(config.Current().Redis.Bloom.EmptyTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultEmptyTTL
+	}
+	return r.conn().Set(r.ctx, formatEmptyKey(chainName, address), 1, ttl).Err()
+}
+
+// IsKnownEmpty reports whether address was recently fetched on chainName
+// and found to have no transactions.
+func (r *RedisCache) IsKnownEmpty(chainName, address string) (bool, error) {
+	n, err := r.conn().Exists(r.ctx, formatEmptyKey(chainName, address)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// withBloom sets redis.bloom for the duration of a test.
+func withBloom(t *testing.T, b types.BloomConfig) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.Bloom = b
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestBloom(t *testing.T) {
+	rc := newTestRedisCache(t)
+	withBloom(t, types.BloomConfig{Enabled: true, Bits: 1 << 16, Hashes: 5})
+
+	ok, err := rc.BloomMayContain("ETH", "0xabc")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, rc.BloomAdd("ETH", "0xABC"))
+	ok, err = rc.BloomMayContain("eth", "0xabc")
+	assert.NoError(t, err)
+	assert.True(t, ok, "case-insensitive")
+
+	ok, err = rc.BloomMayContain("BSC", "0xabc")
+	assert.NoError(t, err)
+	assert.False(t, ok, "filters are per chain")
+
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, rc.BloomAdd("ETH", fmt.Sprintf("0x%040d", i)))
+	}
+	for i := 1000; i < 2000; i++ {
+		if ok, _ := rc.BloomMayContain("ETH", fmt.Sprintf("0x%040d", i)); ok {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 20)
+}
+
+func TestBloomOffsets(t *testing.T) {
+	offs := bloomOffsets("0xabc", 100, 7)
+	assert.Len(t, offs, 7)
+	for _, o := range offs {
+		assert.GreaterOrEqual(t, o, int64(0))
+		assert.Less(t, o, int64(100))
+	}
+	assert.Equal(t, offs, bloomOffsets("0xABC", 100, 7))
+}
+
+func TestTakeDiscoveryToken(t *testing.T) {
+	rc := newTestRedisCache(t)
+
+	for i := 0; i < 3; i++ {
+		ok, err := rc.TakeDiscoveryToken(3)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, err := rc.TakeDiscoveryToken(3)
+	assert.NoError(t, err)
+	assert.False(t, ok, "budget spent for this minute")
+}
+
+func TestKnownEmpty(t *testing.T) {
+	rc := newTestRedisCache(t)
+	withBloom(t, types.BloomConfig{Enabled: true, EmptyTTLSeconds: 60})
+
+	ok, err := rc.IsKnownEmpty("ETH", "0xabc")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, rc.MarkEmpty("ETH", "0xABC"))
+	ok, err = rc.IsKnownEmpty("eth", "0xabc")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = rc.IsKnownEmpty("BSC", "0xabc")
+	assert.NoError(t, err)
+	assert.False(t, ok, "per chain")
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"tx-aggregator/config"
+)
+
+const (
+	codecNone = "none"
+	codecGzip = "gzip"
+
+	defaultCompressMinBytes = 1024
+)
+
+// gzipMagic starts every gzip stream. JSON never starts with these bytes,
+// so compressed and plain values can be told apart on read.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// compress encodes a serialized value according to redis.compression.
+// Values below min_bytes, or that would not shrink, are returned as is.
+func compress(data []byte) ([]byte, error) {
+	cfg := config.Current().Redis.Compression
+	codec := strings.ToLower(cfg.Codec)
+	if codec == "" || codec == codecNone {
+		return data, nil
+	}
+	if codec != codecGzip {
+		return nil, fmt.Errorf("unknown redis.compression.codec %q", cfg.Codec)
+	}
+	minBytes := cfg.MinBytes
+	if minBytes <= 0 {
+		minBytes = defaultCompressMinBytes
+	}
+	if len(data) < minBytes {
+		return data, nil
+	}
+
+	level := cfg.Level
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(data) {
+		return data, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress reverses compress; plain values pass through unchanged.
+func decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
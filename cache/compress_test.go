@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// withCompression sets redis.compression for the duration of a test.
+func withCompression(t *testing.T, c types.CacheCompressionConfig) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.Compression = c
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestCompress_Thresholds(t *testing.T) {
+	large := []byte(`[` + strings.Repeat(`{"hash":"0xabc","state":1},`, 100) + `{}]`)
+
+	withCompression(t, types.CacheCompressionConfig{})
+	out, err := compress(large)
+	assert.NoError(t, err)
+	assert.Equal(t, large, out, "off by default")
+
+	withCompression(t, types.CacheCompressionConfig{Codec: "gzip"})
+	out, err = compress([]byte(`[]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`[]`), out, "below min_bytes")
+
+	out, err = compress(large)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out, gzipMagic))
+	assert.Less(t, len(out), len(large))
+
+	back, err := decompress(out)
+	assert.NoError(t, err)
+	assert.Equal(t, large, back)
+
+	withCompression(t, types.CacheCompressionConfig{Codec: "snappy"})
+	_, err = compress(large)
+	assert.Error(t, err)
+}
+
+func TestCompress_RedisRoundTrip(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+
+	txs := make([]types.Transaction, 50)
+	for i := range txs {
+		txs[i] = types.Transaction{Hash: "0xabc", Balance: "1000000000000000000"}
+	}
+
+	// Written plain, then compressed: both decode
+	withCompression(t, types.CacheCompressionConfig{})
+	assert.NoError(t, rc.SetJSONPipeline("plain", txs, 0))
+	withCompression(t, types.CacheCompressionConfig{Codec: "gzip", MinBytes: 100})
+	assert.NoError(t, rc.SetJSONPipeline("packed", txs, 0))
+
+	plainRaw, _ := s.Get("plain")
+	packedRaw, _ := s.Get("packed")
+	assert.Less(t, len(packedRaw), len(plainRaw))
+
+	plain, err := rc.Get("plain")
+	assert.NoError(t, err)
+	packed, err := rc.Get("packed")
+	assert.NoError(t, err)
+	assert.Equal(t, plain, packed)
+
+	// Compression happens before encryption
+	c, err := NewCipher("k1", map[string][]byte{"k1": testKey('a')})
+	assert.NoError(t, err)
+	rc.SetCipher(c)
+	assert.NoError(t, rc.SetJSONPipeline("sealed", txs, 0))
+	sealed, err := rc.Get("sealed")
+	assert.NoError(t, err)
+	assert.Equal(t, plain, sealed)
+}
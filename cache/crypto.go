@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks a cache value written by Cipher.Seal. The full layout
+// is "enc:<keyID>:<base64(nonce||ciphertext)>".
+const encryptedPrefix = "enc:"
+
+// memberIDPrefix marks a row ID written by Cipher.MemberID. The full layout
+// is "h:<keyID>:<hex(HMAC-SHA256)>".
+const memberIDPrefix = "h:"
+
+// Cipher encrypts cache values with AES-GCM. Values carry the ID of the key
+// that sealed them, so rotating the active key keeps older entries readable
+// as long as the previous key stays in the keyring.
+type Cipher struct {
+	activeID string
+	aeads    map[string]cipher.AEAD
+	macKeys  map[string][]byte
+}
+
+// NewCipher builds a Cipher from raw AES keys (16, 24 or 32 bytes) indexed by
+// key ID. activeID selects the key used for new values.
+func NewCipher(activeID string, keys map[string][]byte) (*Cipher, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("active key %q not found in keyring", activeID)
+	}
+	c := &Cipher{
+		activeID: activeID,
+		aeads:    make(map[string]cipher.AEAD, len(keys)),
+		macKeys:  make(map[string][]byte, len(keys)),
+	}
+	for id, key := range keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key id %q must not contain ':'", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		c.aeads[id] = aead
+		c.macKeys[id] = mac(key, []byte("cache member id"))
+	}
+	return c, nil
+}
+
+// NewCipherFromBase64 is NewCipher for base64-encoded keys, as stored in Vault.
+func NewCipherFromBase64(activeID string, encoded map[string]string) (*Cipher, error) {
+	keys := make(map[string][]byte, len(encoded))
+	for id, v := range encoded {
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return NewCipher(activeID, keys)
+}
+
+// Seal encrypts plaintext with the active key.
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	aead := c.aeads[c.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(c.activeID))
+
+	out := make([]byte, 0, len(encryptedPrefix)+len(c.activeID)+1+base64.StdEncoding.EncodedLen(len(sealed)))
+	out = append(out, encryptedPrefix...)
+	out = append(out, c.activeID...)
+	out = append(out, ':')
+	return base64.StdEncoding.AppendEncode(out, sealed), nil
+}
+
+// Open decrypts a value produced by Seal. Values without the encrypted
+// prefix are returned unchanged, so entries written before encryption was
+// enabled stay readable until they expire.
+func (c *Cipher) Open(value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, []byte(encryptedPrefix)) {
+		return value, nil
+	}
+	rest := value[len(encryptedPrefix):]
+	sep := bytes.IndexByte(rest, ':')
+	if sep < 0 {
+		return nil, fmt.Errorf("malformed encrypted cache value")
+	}
+	keyID := string(rest[:sep])
+	aead, ok := c.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown cache key id %q", keyID)
+	}
+
+	sealed, err := base64.StdEncoding.AppendDecode(nil, rest[sep+1:])
+	if err != nil {
+		return nil, fmt.Errorf("decode encrypted cache value: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted cache value too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(keyID))
+}
+
+// MemberID returns an opaque, stable ID for name under the active key, so
+// sorted-set members and hash fields do not reveal the row they index.
+func (c *Cipher) MemberID(name string) string {
+	return c.memberID(c.activeID, name)
+}
+
+// RetiredMemberIDs returns the IDs name had under the other keys of the
+// keyring and before encryption was enabled, so rewritten rows can drop
+// their previous copies.
+func (c *Cipher) RetiredMemberIDs(name string) []string {
+	ids := []string{name}
+	for id := range c.macKeys {
+		if id != c.activeID {
+			ids = append(ids, c.memberID(id, name))
+		}
+	}
+	return ids
+}
+
+func (c *Cipher) memberID(keyID, name string) string {
+	return memberIDPrefix + keyID + ":" + hex.EncodeToString(mac(c.macKeys[keyID], []byte(name)))
+}
+
+// mac is HMAC-SHA256 of msg under key.
+func mac(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(b byte) []byte {
+	return []byte(strings.Repeat(string(b), 32))
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := NewCipher("k1", map[string][]byte{"k1": testKey('a')})
+	assert.NoError(t, err)
+
+	sealed, err := c.Seal([]byte(`[{"hash":"0x1"}]`))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(sealed), "enc:k1:"))
+
+	plain, err := c.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"hash":"0x1"}]`, string(plain))
+}
+
+func TestCipher_Rotation(t *testing.T) {
+	old, err := NewCipher("k1", map[string][]byte{"k1": testKey('a')})
+	assert.NoError(t, err)
+	sealed, err := old.Seal([]byte("history"))
+	assert.NoError(t, err)
+
+	rotated, err := NewCipher("k2", map[string][]byte{"k1": testKey('a'), "k2": testKey('b')})
+	assert.NoError(t, err)
+	plain, err := rotated.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "history", string(plain))
+
+	dropped, err := NewCipher("k2", map[string][]byte{"k2": testKey('b')})
+	assert.NoError(t, err)
+	_, err = dropped.Open(sealed)
+	assert.Error(t, err)
+}
+
+func TestCipher_PlaintextPassthrough(t *testing.T) {
+	c, err := NewCipher("k1", map[string][]byte{"k1": testKey('a')})
+	assert.NoError(t, err)
+
+	plain, err := c.Open([]byte(`[]`))
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(plain))
+}
+
+func TestNewCipher_InvalidConfig(t *testing.T) {
+	_, err := NewCipher("missing", map[string][]byte{"k1": testKey('a')})
+	assert.Error(t, err)
+
+	_, err = NewCipher("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+
+	_, err = NewCipherFromBase64("k1", map[string]string{"k1": "not base64!"})
+	assert.Error(t, err)
+}
+
+func TestRedisCache_EncryptedValues(t *testing.T) {
+	cache := newTestRedisCache(t)
+	c, err := NewCipher("k1", map[string][]byte{"k1": testKey('a')})
+	assert.NoError(t, err)
+	cache.SetCipher(c)
+
+	assert.NoError(t, cache.SetJSONPipeline("addr-eth", []string{"0x1"}, time.Minute))
+
+	raw, err := cache.client.Get(cache.ctx, "addr-eth").Result()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "enc:k1:"), "value must be encrypted at rest")
+
+	val, err := cache.Get("addr-eth")
+	assert.NoError(t, err)
+	assert.Equal(t, `["0x1"]`, val)
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// formatCursorKey is the key holding the last block indexed on chainName.
+func formatCursorKey(chainName string) string {
+	return keyPrefix() + "indexer:cursor:" + strings.ToUpper(chainName)
+}
+
+// IndexCursor returns the last block the indexer finished on chainName, or 0
+// when it has not run there yet.
+func (r *RedisCache) IndexCursor(chainName string) (int64, error) {
+	val, err := r.conn().Get(r.ctx, formatCursorKey(chainName)).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// SetIndexCursor records block as the last block indexed on chainName. The
+// cursor never expires.
+func (r *RedisCache) SetIndexCursor(chainName string, block int64) error {
+	return r.conn().Set(r.ctx, formatCursorKey(chainName), block, 0).Err()
+}
@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexCursor(t *testing.T) {
+	rc := newTestRedisCache(t)
+
+	block, err := rc.IndexCursor("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), block, "no cursor yet")
+
+	assert.NoError(t, rc.SetIndexCursor("eth", 19000000))
+	block, err = rc.IndexCursor("ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(19000000), block, "chain names are case-insensitive")
+
+	block, _ = rc.IndexCursor("bsc")
+	assert.Equal(t, int64(0), block, "cursors are per chain")
+}
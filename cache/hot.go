@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"tx-aggregator/types"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// hotMinScore is the decayed score below which an address leaves the
+// ranking of hot addresses.
+const hotMinScore = 0.5
+
+// formatHotKey is the sorted set ranking queried address / chain pairs.
+func formatHotKey() string {
+	return keyPrefix() + "hot"
+}
+
+// hotMember is the member of the hot ranking for an address on a chain.
+func hotMember(address, chainName string) string {
+	return strings.ToLower(address) + "|" + strings.ToUpper(chainName)
+}
+
+// TrackHot counts one query of address on each of chainNames (ZINCRBY).
+func (r *RedisCache) TrackHot(address string, chainNames []string) error {
+	if len(chainNames) == 0 {
+		return nil
+	}
+	key := formatHotKey()
+	pipe := r.conn().Pipeline()
+	for _, chain := range chainNames {
+		pipe.ZIncrBy(r.ctx, key, 1, hotMember(address, chain))
+	}
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// HotAddresses returns the n most queried address / chain pairs, hottest
+// first.
+func (r *RedisCache) HotAddresses(n int) ([]types.HotAddress, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	zs, err := r.conn().ZRevRangeWithScores(r.ctx, formatHotKey(), 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.HotAddress, 0, len(zs))
+	for _, z := range zs {
+		member, _ := z.Member.(string)
+		address, chain, ok := strings.Cut(member, "|")
+		if !ok {
+			continue
+		}
+		out = append(out, types.HotAddress{Address: address, ChainName: chain, Score: z.Score})
+	}
+	return out, nil
+}
+
+// DecayHotAddresses multiplies every score of the hot ranking by factor and
+// drops the pairs that fall below hotMinScore, so the ranking follows recent
+// traffic.
+func (r *RedisCache) DecayHotAddresses(factor float64) error {
+	key := formatHotKey()
+	pipe := r.conn().Pipeline()
+	pipe.ZUnionStore(r.ctx, key, &redis.ZStore{Keys: []string{key}, Weights: []float64{factor}})
+	pipe.ZRemRangeByScore(r.ctx, key, "-inf", "("+strconv.FormatFloat(hotMinScore, 'f', -1, 64))
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// EntryTTL returns how long the cached chain-level entry of address on
+// chainName has left: 0 when nothing is cached, negative when the entry does
+// not expire.
+func (r *RedisCache) EntryTTL(address, chainName string) (time.Duration, error) {
+	key := formatChainKey(address, chainName)
+	if zsetLayout() {
+		key = zsetIndexKey(key)
+	}
+	ttl, err := r.conn().PTTL(r.ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// go-redis reports the PTTL replies -2 (no key) and -1 (no expiry) as is.
+	switch ttl {
+	case -2:
+		return 0, nil
+	case -1:
+		return -1, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestHotAddresses(t *testing.T) {
+	rc := newTestRedisCache(t)
+
+	assert.NoError(t, rc.TrackHot("0xAAA", []string{"eth", "BSC"}))
+	assert.NoError(t, rc.TrackHot("0xaaa", []string{"ETH"}))
+	assert.NoError(t, rc.TrackHot("0xbbb", []string{"ETH"}))
+
+	hot, err := rc.HotAddresses(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.HotAddress{
+		{Address: "0xaaa", ChainName: "ETH", Score: 2},
+		{Address: "0xbbb", ChainName: "ETH", Score: 1},
+	}, hot, "hottest first")
+
+	assert.NoError(t, rc.DecayHotAddresses(0.5))
+	assert.NoError(t, rc.DecayHotAddresses(0.5))
+	hot, err = rc.HotAddresses(10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.HotAddress{{Address: "0xaaa", ChainName: "ETH", Score: 0.5}}, hot,
+		"scores halve and pairs below the minimum drop out")
+}
+
+func TestEntryTTL(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.TTLSeconds = 60
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	ttl, err := rc.EntryTTL("0xuser", "ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), ttl, "nothing cached")
+
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = []types.Transaction{{ChainID: 1, Hash: "0x1", CoinType: types.CoinTypeNative}}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(resp, "0xuser"))
+	ttl, err = rc.EntryTTL("0xuser", "ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, 60*time.Second, ttl)
+
+	s.Set(formatChainKey("0xother", "ETH"), "[]")
+	ttl, err = rc.EntryTTL("0xother", "ETH")
+	assert.NoError(t, err)
+	assert.Less(t, ttl, time.Duration(0), "no expiry")
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// addressKeys returns every key that may hold cached transactions of address
+// on chain: the token set and the chain-level, native and per-token entries
+// in both layouts.
+func (r *RedisCache) addressKeys(address, chain string) ([]string, error) {
+	setKey := formatTokenSetKey(address, chain)
+	tokens, err := r.conn().SMembers(r.ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []string{formatChainKey(address, chain), formatNativeKey(address, chain)}
+	for _, token := range tokens {
+		entries = append(entries, formatTokenKey(address, chain, token))
+	}
+	keys := []string{setKey}
+	for _, k := range entries {
+		keys = append(keys, k, zsetIndexKey(k), zsetRowsKey(k))
+	}
+	return keys, nil
+}
+
+// InvalidateAddress deletes every cache entry of address on chainNames: the
+// chain-level, native and per-token entries (in both layouts) and the token
+// set. It returns the number of keys removed.
+func (r *RedisCache) InvalidateAddress(address string, chainNames []string) (int64, error) {
+	var removed int64
+	for _, chain := range chainNames {
+		keys, err := r.addressKeys(address, chain)
+		if err != nil {
+			return removed, err
+		}
+
+		// One DEL per key: a multi-key DEL is a cross-slot error in cluster mode.
+		pipe := r.conn().Pipeline()
+		cmds := make([]*redis.IntCmd, len(keys))
+		for i, k := range keys {
+			cmds[i] = pipe.Del(r.ctx, k)
+		}
+		if _, err := pipe.Exec(r.ctx); err != nil {
+			return removed, err
+		}
+		for _, c := range cmds {
+			removed += c.Val()
+		}
+	}
+	return removed, nil
+}
+
+// RetainAddress extends the lifetime of every cache entry of address on
+// chainNames to ttl. Entries that expire later are left alone, and missing
+// keys are skipped.
+func (r *RedisCache) RetainAddress(address string, chainNames []string, ttl time.Duration) error {
+	for _, chain := range chainNames {
+		keys, err := r.addressKeys(address, chain)
+		if err != nil {
+			return err
+		}
+		pipe := r.conn().Pipeline()
+		for _, k := range keys {
+			pipe.ExpireGT(r.ctx, k, ttl)
+		}
+		if _, err := pipe.Exec(r.ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestInvalidateAddress(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.TTLSeconds = 100
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = []types.Transaction{
+		{ChainID: 1, Hash: "0x1", CoinType: types.CoinTypeNative},
+		{ChainID: 1, Hash: "0x2", CoinType: types.CoinTypeToken, TokenAddress: "0xtoken"},
+		{ChainID: 56, Hash: "0x3", CoinType: types.CoinTypeNative},
+	}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(resp, "0xuser"))
+	assert.NoError(t, rc.ParseTxAndSaveToCache(resp, "0xother"))
+	before := len(s.Keys())
+
+	removed, err := rc.InvalidateAddress("0xUSER", []string{"ETH"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), removed, "chain, native and token entries plus the token set")
+	assert.Len(t, s.Keys(), before-4)
+
+	out, err := rc.QueryTxFromCache(&types.TransactionQueryParams{Address: "0xuser", ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	assert.Empty(t, out.Result.Transactions)
+
+	out, err = rc.QueryTxFromCache(&types.TransactionQueryParams{Address: "0xuser", ChainNames: []string{"BSC"}})
+	assert.NoError(t, err)
+	assert.Len(t, out.Result.Transactions, 1, "other chains are kept")
+}
+
+func TestRetainAddress(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.TTLSeconds = 100
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = []types.Transaction{
+		{ChainID: 1, Hash: "0x1", CoinType: types.CoinTypeNative},
+		{ChainID: 1, Hash: "0x2", CoinType: types.CoinTypeToken, TokenAddress: "0xtoken"},
+	}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(resp, "0xuser"))
+
+	assert.NoError(t, rc.RetainAddress("0xuser", []string{"ETH"}, time.Hour))
+	for _, k := range s.Keys() {
+		assert.Equal(t, time.Hour, s.TTL(k), k)
+	}
+
+	assert.NoError(t, rc.RetainAddress("0xuser", []string{"ETH"}, time.Minute))
+	for _, k := range s.Keys() {
+		assert.Equal(t, time.Hour, s.TTL(k), "a shorter ttl is ignored: "+k)
+	}
+}
@@ -11,9 +11,9 @@ func TestFormatChainKey(t *testing.T) {
 		chainName string
 		expected  string
 	}{
-		{"0xABCDEF", "ETH", "0xabcdef-eth"},
-		{"0x123456", "bsc", "0x123456-bsc"},
-		{"0xABCDEF", "Polygon", "0xabcdef-polygon"},
+		{"0xABCDEF", "ETH", keyPrefix() + "0xabcdef-eth"},
+		{"0x123456", "bsc", keyPrefix() + "0x123456-bsc"},
+		{"0xABCDEF", "Polygon", keyPrefix() + "0xabcdef-polygon"},
 	}
 
 	for _, tt := range tests {
@@ -30,8 +30,8 @@ func TestFormatNativeKey(t *testing.T) {
 		chainName string
 		expected  string
 	}{
-		{"0xABCDEF", "ETH", "0xabcdef-eth-" + types.NativeTokenName},
-		{"0x123456", "bsc", "0x123456-bsc-" + types.NativeTokenName},
+		{"0xABCDEF", "ETH", keyPrefix() + "0xabcdef-eth-" + types.NativeTokenName},
+		{"0x123456", "bsc", keyPrefix() + "0x123456-bsc-" + types.NativeTokenName},
 	}
 
 	for _, tt := range tests {
@@ -49,8 +49,8 @@ func TestFormatTokenKey(t *testing.T) {
 		tokenAddr string
 		expected  string
 	}{
-		{"0xABCDEF", "ETH", "0xToken1", "0xabcdef-eth-0xtoken1"},
-		{"0x123456", "bsc", "0xdeadbeef", "0x123456-bsc-0xdeadbeef"},
+		{"0xABCDEF", "ETH", "0xToken1", keyPrefix() + "0xabcdef-eth-0xtoken1"},
+		{"0x123456", "bsc", "0xdeadbeef", keyPrefix() + "0x123456-bsc-0xdeadbeef"},
 	}
 
 	for _, tt := range tests {
@@ -67,8 +67,8 @@ func TestFormatTokenSetKey(t *testing.T) {
 		chainName string
 		expected  string
 	}{
-		{"0xABCDEF", "ETH", "0xabcdef-eth-tokens"},
-		{"0x123456", "bsc", "0x123456-bsc-tokens"},
+		{"0xABCDEF", "ETH", keyPrefix() + "0xabcdef-eth-tokens"},
+		{"0x123456", "bsc", keyPrefix() + "0x123456-bsc-tokens"},
 	}
 
 	for _, tt := range tests {
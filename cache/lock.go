@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// unlockScript deletes a lock only while it still holds the caller's token,
+// so a lock that expired and was taken by another instance is left alone.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// formatLockKey is the key of the distributed lock called name.
+func formatLockKey(name string) string {
+	return keyPrefix() + "lock:" + name
+}
+
+// TryLock takes the distributed lock name for at most ttl (SET NX PX). It
+// returns the token to pass to Unlock and whether the lock was taken; false
+// means another holder has it.
+func (r *RedisCache) TryLock(name string, ttl time.Duration) (string, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(buf)
+	ok, err := r.conn().SetNX(r.ctx, formatLockKey(name), token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// Unlock releases the lock name if it is still held with token.
+func (r *RedisCache) Unlock(name, token string) error {
+	return unlockScript.Run(r.ctx, r.conn(), []string{formatLockKey(name)}, token).Err()
+}
+
+// Locked reports whether anyone holds the lock name.
+func (r *RedisCache) Locked(name string) (bool, error) {
+	n, err := r.conn().Exists(r.ctx, formatLockKey(name)).Result()
+	return n > 0, err
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	rc := newTestRedisCache(t)
+
+	token, ok, err := rc.TryLock("0xabc|eth", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, ok, err = rc.TryLock("0xabc|eth", time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok, "held by the first caller")
+
+	locked, err := rc.Locked("0xabc|eth")
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	assert.NoError(t, rc.Unlock("0xabc|eth", "someone-else"))
+	locked, _ = rc.Locked("0xabc|eth")
+	assert.True(t, locked, "a foreign token does not release the lock")
+
+	assert.NoError(t, rc.Unlock("0xabc|eth", token))
+	locked, _ = rc.Locked("0xabc|eth")
+	assert.False(t, locked)
+
+	_, ok, err = rc.TryLock("0xabc|eth", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok, "free again after unlock")
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"sort"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const defaultMaxCachedTxs = 2000
+
+// MaxCachedTxs is the number of rows kept per cache entry, newest first.
+func MaxCachedTxs() int {
+	if n := config.Current().Redis.MaxCachedTxs; n > 0 {
+		return n
+	}
+	return defaultMaxCachedTxs
+}
+
+// mergeTransactions adds fresh rows to the cached ones. A fresh row replaces
+// a cached row with the same utils.TransactionKey, so state changes such as a pending
+// transaction being mined are picked up. The result is ordered newest first
+// and holds at most limit rows.
+func mergeTransactions(cached, fresh []types.Transaction, limit int) []types.Transaction {
+	merged := make([]types.Transaction, 0, len(cached)+len(fresh))
+	index := make(map[string]int, len(cached)+len(fresh))
+	for _, txs := range [][]types.Transaction{cached, fresh} {
+		for _, tx := range txs {
+			k := utils.TransactionKey(tx)
+			if i, ok := index[k]; ok {
+				merged[i] = tx
+				continue
+			}
+			index[k] = len(merged)
+			merged = append(merged, tx)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Height != merged[j].Height {
+			return merged[i].Height > merged[j].Height
+		}
+		return merged[i].TxIndex > merged[j].TxIndex
+	})
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
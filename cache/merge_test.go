@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestMergeTransactions(t *testing.T) {
+	cached := []types.Transaction{
+		{ChainID: 1, Hash: "0xold", Height: 10, CoinType: types.CoinTypeNative, Amount: "1"},
+		{ChainID: 1, Hash: "0xmid", Height: 20, CoinType: types.CoinTypeNative, Amount: "2", State: 0},
+	}
+	fresh := []types.Transaction{
+		{ChainID: 1, Hash: "0xMID", Height: 20, CoinType: types.CoinTypeNative, Amount: "2", State: 1},
+		{ChainID: 1, Hash: "0xmid", Height: 20, CoinType: types.CoinTypeToken, TokenAddress: "0xt", Amount: "5"},
+		{ChainID: 1, Hash: "0xnew", Height: 30, CoinType: types.CoinTypeNative, Amount: "3"},
+	}
+
+	merged := mergeTransactions(cached, fresh, 0)
+	if assert.Len(t, merged, 4) {
+		assert.Equal(t, "0xnew", merged[0].Hash, "newest first")
+		assert.Equal(t, 1, merged[1].State, "fresh row replaces the cached one")
+		assert.Equal(t, types.CoinTypeToken, merged[2].CoinType, "token transfer of the same tx is its own row")
+		assert.Equal(t, "0xold", merged[3].Hash, "older cached rows are kept")
+	}
+
+	merged = mergeTransactions(cached, fresh, 2)
+	if assert.Len(t, merged, 2) {
+		assert.Equal(t, int64(30), merged[0].Height)
+		assert.Equal(t, int64(20), merged[1].Height)
+	}
+}
+
+func TestParseTxAndSaveToCache_MergesWithCachedRows(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.TTLSeconds = 100
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	save := func(write func(*types.TransactionResponse, string) error, hashes ...string) {
+		resp := &types.TransactionResponse{}
+		for i, h := range hashes {
+			resp.Result.Transactions = append(resp.Result.Transactions,
+				types.Transaction{ChainID: 1, Hash: h, Height: int64(100 + i), CoinType: types.CoinTypeNative})
+		}
+		assert.NoError(t, write(resp, "0xUser"))
+	}
+	query := func() []types.Transaction {
+		resp, err := rc.QueryTxFromCache(&types.TransactionQueryParams{Address: "0xUser", ChainNames: []string{"ETH"}})
+		assert.NoError(t, err)
+		return resp.Result.Transactions
+	}
+
+	save(rc.ParseTxAndSaveToCache, "0xa", "0xb")
+	save(rc.ParseTxAndSaveToCache, "0xb", "0xc")
+	assert.Len(t, query(), 3, "rows of the first fetch survive the second")
+
+	save(rc.ReplaceTxInCache, "0xd")
+	txs := query()
+	if assert.Len(t, txs, 1) {
+		assert.Equal(t, "0xd", txs[0].Hash)
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"tx-aggregator/types"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// formatReceiptKey is the key holding the normalized receipt of a
+// transaction on a chain.
+func formatReceiptKey(chainName, hash string) string {
+	return keyPrefix() + "receipt:" + strings.ToLower(chainName) + ":" + strings.ToLower(hash)
+}
+
+// CachedReceipt returns the cached receipt of hash on chainName; false
+// means it is not cached.
+func (r *RedisCache) CachedReceipt(chainName, hash string) (*types.Receipt, bool, error) {
+	val, err := r.Get(formatReceiptKey(chainName, hash))
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var receipt types.Receipt
+	if err := json.Unmarshal([]byte(val), &receipt); err != nil {
+		return nil, false, nil // unreadable, as good as absent
+	}
+	return &receipt, true, nil
+}
+
+// CacheReceipt stores the receipt of a mined transaction for ttl.
+func (r *RedisCache) CacheReceipt(receipt *types.Receipt, ttl time.Duration) error {
+	return r.SetJSONPipeline(formatReceiptKey(receipt.ServerChainName, receipt.Hash), receipt, ttl)
+}
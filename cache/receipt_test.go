@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/types"
+)
+
+func TestCachedReceipt(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+
+	_, ok, err := rc.CachedReceipt("ETH", "0xabc")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	receipt := &types.Receipt{ServerChainName: "ETH", Hash: "0xABC", Height: 10, State: types.TxStateSuccess}
+	assert.NoError(t, rc.CacheReceipt(receipt, time.Minute))
+
+	got, ok, err := rc.CachedReceipt("eth", "0xabc")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, receipt, got)
+	assert.Equal(t, time.Minute, s.TTL(formatReceiptKey("ETH", "0xabc")))
+}
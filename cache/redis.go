@@ -4,54 +4,184 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+// Connection pool settings, tuned for high concurrency.
+const (
+	poolSize    = 40 // adjust to your workload (≈ 10 × CPU cores)
+	minIdleConn = 8
 )
 
 // RedisCache is a thin wrapper around a go‑redis client.  It works for both
 // single‑instance and cluster deployments.
 type RedisCache struct {
+	mu     sync.RWMutex    // guards client and mode, replaced by Reconnect
 	client redis.Cmdable   // *redis.Client or *redis.ClusterClient
 	ctx    context.Context // shared context for all calls
 	mode   string          // "single" or "cluster" (for debugging only)
+	cipher *Cipher         // optional value encryption; nil stores plaintext
 }
 
+// reconnectGrace is how long a client replaced by Reconnect stays open for
+// the commands still running on it.
+const reconnectGrace = 30 * time.Second
+
 // NewRedisCache detects whether the target is a single node or a cluster
-// from the number of addresses provided and initialises the appropriate
-// client.  Pool settings are tuned for high concurrency.
-func NewRedisCache(addrs []string, password string) *RedisCache {
-	const (
-		poolSize    = 40 // adjust to your workload (≈ 10 × CPU cores)
-		minIdleConn = 8
-	)
+// from the number of addresses configured and initialises the appropriate
+// client, authenticating as cfg.Username when set and connecting over TLS
+// when cfg.TLS is enabled.  Pool settings are tuned for high concurrency.
+func NewRedisCache(cfg types.RedisConfig) (*RedisCache, error) {
+	client, mode, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisCache{client: client, ctx: context.Background(), mode: mode}, nil
+}
+
+// newClient builds the client for cfg and checks that it answers.
+func newClient(cfg types.RedisConfig) (redis.Cmdable, string, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, "", errors.New("no redis addresses configured")
+	}
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, "", err
+	}
 	ctx := context.Background()
 
 	// --- cluster mode --------------------------------------------------------
-	if len(addrs) > 1 {
+	if len(cfg.Addrs) > 1 {
 		cl := redis.NewClusterClient(&redis.ClusterOptions{
-			Addrs:        addrs,
-			Password:     password,
+			Addrs:        cfg.Addrs,
+			Username:     cfg.Username,
+			Password:     cfg.Password,
+			TLSConfig:    tlsConfig,
 			PoolSize:     poolSize,
 			MinIdleConns: minIdleConn,
 		})
 		pingRedis(ctx, cl)
-		return &RedisCache{client: cl, ctx: ctx, mode: "cluster"}
+		return cl, "cluster", nil
 	}
 
 	// --- single‑instance mode -------------------------------------------------
 	single := redis.NewClient(&redis.Options{
-		Addr:         addrs[0],
-		Password:     password,
+		Addr:         cfg.Addrs[0],
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		TLSConfig:    tlsConfig,
 		DB:           0,
 		PoolSize:     poolSize,
 		MinIdleConns: minIdleConn,
 	})
 	pingRedis(ctx, single)
-	return &RedisCache{client: single, ctx: ctx, mode: "single"}
+	return single, "single", nil
+}
+
+// ConnectionChanged reports whether b differs from a in a setting the client
+// is built from, which only Reconnect applies.
+func ConnectionChanged(a, b types.RedisConfig) bool {
+	return !slices.Equal(a.Addrs, b.Addrs) || a.Username != b.Username ||
+		a.Password != b.Password || a.TLS != b.TLS
+}
+
+// Reconnect replaces the client with one built from cfg, after its
+// connection settings changed in a hot reload. Commands already running
+// finish on the previous client, which is closed after reconnectGrace.
+func (r *RedisCache) Reconnect(cfg types.RedisConfig) error {
+	client, mode, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	old := r.client
+	r.client, r.mode = client, mode
+	r.mu.Unlock()
+
+	if c, ok := old.(io.Closer); ok {
+		time.AfterFunc(reconnectGrace, func() { _ = c.Close() })
+	}
+	logger.Log.Info().Str("mode", mode).Strs("addrs", cfg.Addrs).Msg("redis client reconnected")
+	return nil
+}
+
+// conn returns the client in use. It must be fetched again for each command
+// rather than kept, as Reconnect replaces it.
+func (r *RedisCache) conn() redis.Cmdable {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.client
+}
+
+// newTLSConfig builds the client TLS settings from cfg. It returns nil when
+// TLS is disabled.
+func newTLSConfig(cfg types.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tc := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("redis CA file %s contains no PEM certificates", cfg.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load redis client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+// SetCipher enables transparent AES-GCM encryption of cached values.
+// Passing nil turns encryption off for new writes.
+func (r *RedisCache) SetCipher(c *Cipher) {
+	r.cipher = c
+}
+
+// PoolSize returns the configured maximum number of connections per node.
+func (r *RedisCache) PoolSize() int {
+	return poolSize
+}
+
+// PoolStats returns the connection pool statistics of the underlying client,
+// aggregated over all nodes in cluster mode. It returns nil for clients that
+// do not expose pool statistics.
+func (r *RedisCache) PoolStats() *redis.PoolStats {
+	if ps, ok := r.conn().(interface{ PoolStats() *redis.PoolStats }); ok {
+		return ps.PoolStats()
+	}
+	return nil
+}
+
+// Ping checks that Redis answers, for health checks.
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.conn().Ping(ctx).Err()
 }
 
 // pingRedis logs whether the connection is alive.
@@ -67,15 +197,16 @@ func pingRedis(ctx context.Context, c redis.Cmdable) {
 // Primitive helpers
 // ---------------------------------------------------------------------------
 
-// SetJSONPipeline stores a value (marshalled to JSON) and its TTL in a
-// single round‑trip using a pipeline.
+// SetJSONPipeline stores a value (marshalled to JSON, then compressed and
+// encrypted when configured) and its TTL in a single round‑trip using a
+// pipeline.
 func (r *RedisCache) SetJSONPipeline(key string, value any, ttl time.Duration) error {
-	data, err := json.Marshal(value)
+	data, err := r.encodeJSON(value)
 	if err != nil {
-		return fmt.Errorf("json marshal: %w", err)
+		return err
 	}
 
-	pipe := r.client.Pipeline()
+	pipe := r.conn().Pipeline()
 	pipe.Set(r.ctx, key, data, ttl) // SET already accepts TTL, but we add EXPIRE
 	if ttl > 0 {
 		pipe.Expire(r.ctx, key, ttl)
@@ -97,7 +228,7 @@ func (r *RedisCache) AddToSetBulk(setKey string, members []string, ttl time.Dura
 		args[i] = m
 	}
 
-	pipe := r.client.Pipeline()
+	pipe := r.conn().Pipeline()
 	pipe.SAdd(r.ctx, setKey, args...)
 	if ttl > 0 {
 		pipe.Expire(r.ctx, setKey, ttl)
@@ -106,8 +237,53 @@ func (r *RedisCache) AddToSetBulk(setKey string, members []string, ttl time.Dura
 	return err
 }
 
-// Get returns the raw string value stored under key.  It is used by the
-// QueryTxFromCache path.
+// Get returns the value stored under key, decrypting it when it was written
+// with a Cipher and decompressing it when it was compressed.  It is used by
+// the QueryTxFromCache path.
 func (r *RedisCache) Get(key string) (string, error) {
-	return r.client.Get(r.ctx, key).Result()
+	val, err := r.conn().Get(r.ctx, key).Result()
+	if err != nil {
+		return val, err
+	}
+	data, err := r.decode(key, []byte(val))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// encodeJSON marshals value to JSON, then compresses and encrypts it when
+// configured, giving the bytes stored in Redis.
+func (r *RedisCache) encodeJSON(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal: %w", err)
+	}
+	if data, err = compress(data); err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	if r.cipher != nil {
+		if data, err = r.cipher.Seal(data); err != nil {
+			return nil, fmt.Errorf("encrypt: %w", err)
+		}
+	}
+	return data, nil
+}
+
+// decode reverses encodeJSON up to the JSON bytes. key is only used in
+// error messages. A value that cannot be decrypted or decompressed is
+// corrupt (or sealed with a key no longer configured) and logged as an error.
+func (r *RedisCache) decode(key string, data []byte) ([]byte, error) {
+	var err error
+	if r.cipher != nil {
+		if data, err = r.cipher.Open(data); err != nil {
+			logger.Log.Error().Err(err).Str("key", key).Msg("Corrupt cache value: decrypt failed")
+			return nil, fmt.Errorf("decrypt %s: %w", key, err)
+		}
+	}
+	if data, err = decompress(data); err != nil {
+		logger.Log.Error().Err(err).Str("key", key).Msg("Corrupt cache value: decompress failed")
+		return nil, fmt.Errorf("decompress %s: %w", key, err)
+	}
+	return data, nil
 }
@@ -2,8 +2,13 @@ package cache
 
 import (
 	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+	"tx-aggregator/types"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
@@ -75,3 +80,97 @@ func TestGet_NotFound(t *testing.T) {
 	_, err := cache.Get("nonexistent")
 	assert.Error(t, err)
 }
+
+func TestPing(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	cache := &RedisCache{client: redis.NewClient(&redis.Options{Addr: s.Addr()}), ctx: context.Background()}
+
+	assert.NoError(t, cache.Ping(context.Background()))
+	s.Close()
+	assert.Error(t, cache.Ping(context.Background()))
+}
+
+func TestNewRedisCache_ACLUser(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	t.Cleanup(s.Close)
+	s.RequireUserAuth("reader", "secret")
+
+	rc, err := NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}, Username: "reader", Password: "secret"})
+	assert.NoError(t, err)
+	assert.NoError(t, rc.client.Set(rc.ctx, "k", "v", 0).Err())
+
+	rc, err = NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}, Username: "reader", Password: "wrong"})
+	assert.NoError(t, err)
+	assert.Error(t, rc.client.Set(rc.ctx, "k", "v", 0).Err())
+}
+
+func TestReconnect(t *testing.T) {
+	first, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	t.Cleanup(first.Close)
+	second, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start miniredis: %v", err)
+	}
+	t.Cleanup(second.Close)
+
+	cfg := types.RedisConfig{Addrs: []string{first.Addr()}, TTLSeconds: 60}
+	rc, err := NewRedisCache(cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, rc.SetJSONPipeline("k", "v", time.Minute))
+	assert.True(t, first.Exists("k"))
+
+	moved := cfg
+	moved.Addrs = []string{second.Addr()}
+	assert.False(t, ConnectionChanged(cfg, types.RedisConfig{Addrs: []string{first.Addr()}, TTLSeconds: 120}), "the TTL is read per write")
+	assert.True(t, ConnectionChanged(cfg, moved))
+
+	assert.NoError(t, rc.Reconnect(moved))
+	assert.NoError(t, rc.SetJSONPipeline("k2", "v", time.Minute))
+	assert.True(t, second.Exists("k2"), "writes go to the new address")
+	assert.False(t, first.Exists("k2"))
+
+	assert.Error(t, rc.Reconnect(types.RedisConfig{}))
+	assert.NoError(t, rc.Ping(context.Background()), "a failed reconnect keeps the client")
+}
+
+func TestNewRedisCache_NoAddrs(t *testing.T) {
+	_, err := NewRedisCache(types.RedisConfig{})
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	tc, err := newTLSConfig(types.RedisTLSConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, tc, "disabled TLS yields no config")
+
+	srv := httptest.NewTLSServer(nil)
+	t.Cleanup(srv.Close)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	tc, err = newTLSConfig(types.RedisTLSConfig{Enabled: true, CAFile: caFile, ServerName: "redis.internal"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, tc) {
+		assert.NotNil(t, tc.RootCAs)
+		assert.Equal(t, "redis.internal", tc.ServerName)
+		assert.False(t, tc.InsecureSkipVerify)
+	}
+
+	badFile := filepath.Join(t.TempDir(), "bad.pem")
+	assert.NoError(t, os.WriteFile(badFile, []byte("not a certificate"), 0o600))
+	_, err = newTLSConfig(types.RedisTLSConfig{Enabled: true, CAFile: badFile})
+	assert.Error(t, err)
+
+	_, err = newTLSConfig(types.RedisTLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+}
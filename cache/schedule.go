@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// claimScript returns up to ARGV[2] members of KEYS[1] scored at or below
+// ARGV[1] and moves them to score ARGV[3], so concurrent claimers never get
+// the same member twice.
+var claimScript = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, id in ipairs(ids) do
+	redis.call("ZADD", KEYS[1], ARGV[3], id)
+end
+return ids`)
+
+// formatWatchScheduleKey is the sorted set of watch ids scored by the Unix
+// time of their next refresh.
+func formatWatchScheduleKey() string {
+	return keyPrefix() + "watch:schedule"
+}
+
+// formatRateKey counts the requests made under name in the minute starting
+// at t.
+func formatRateKey(name string, t time.Time) string {
+	return keyPrefix() + "ratelimit:" + name + ":" + strconv.FormatInt(t.Unix()/60, 10)
+}
+
+// ScheduleWatch sets the next refresh of watch id to at.
+func (r *RedisCache) ScheduleWatch(id string, at time.Time) error {
+	return r.conn().ZAdd(r.ctx, formatWatchScheduleKey(), redis.Z{Score: float64(at.Unix()), Member: id}).Err()
+}
+
+// UnscheduleWatch removes watch id from the refresh schedule.
+func (r *RedisCache) UnscheduleWatch(id string) error {
+	return r.conn().ZRem(r.ctx, formatWatchScheduleKey(), id).Err()
+}
+
+// ClaimDueWatches returns up to n watch ids whose refresh is due and pushes
+// their next refresh lease into the future, so no other instance claims them
+// meanwhile. A claimer that dies before rescheduling a watch leaves it to be
+// claimed again once the lease runs out.
+func (r *RedisCache) ClaimDueWatches(n int, lease time.Duration) ([]string, error) {
+	now := time.Now()
+	return claimScript.Run(r.ctx, r.conn(), []string{formatWatchScheduleKey()},
+		now.Unix(), n, now.Add(lease).Unix()).StringSlice()
+}
+
+// TakeRateToken reports whether another request may be made under name this
+// minute, counting it. The budget of perMinute is shared by every instance.
+func (r *RedisCache) TakeRateToken(name string, perMinute int) (bool, error) {
+	return r.takeMinuteToken(formatRateKey(name, time.Now()), perMinute)
+}
+
+// takeMinuteToken increments the per-minute counter key and reports whether
+// it is still within perMinute.
+func (r *RedisCache) takeMinuteToken(key string, perMinute int) (bool, error) {
+	pipe := r.conn().Pipeline()
+	n := pipe.Incr(r.ctx, key)
+	pipe.Expire(r.ctx, key, 2*time.Minute)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return false, err
+	}
+	return n.Val() <= int64(perMinute), nil
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimDueWatches(t *testing.T) {
+	rc := newTestRedisCache(t)
+	now := time.Now()
+
+	assert.NoError(t, rc.ScheduleWatch("a", now.Add(-time.Minute)))
+	assert.NoError(t, rc.ScheduleWatch("b", now))
+	assert.NoError(t, rc.ScheduleWatch("c", now.Add(time.Hour)))
+
+	ids, err := rc.ClaimDueWatches(10, time.Minute)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, ids)
+
+	ids, err = rc.ClaimDueWatches(10, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, ids, "claimed watches are leased")
+
+	assert.NoError(t, rc.ScheduleWatch("a", now.Add(-time.Second)))
+	assert.NoError(t, rc.ScheduleWatch("b", now.Add(-time.Second)))
+	ids, _ = rc.ClaimDueWatches(1, time.Minute)
+	assert.Len(t, ids, 1, "at most n")
+
+	assert.NoError(t, rc.UnscheduleWatch("a"))
+	assert.NoError(t, rc.UnscheduleWatch("b"))
+	ids, _ = rc.ClaimDueWatches(10, time.Minute)
+	assert.Empty(t, ids)
+}
+
+func TestTakeRateToken(t *testing.T) {
+	rc := newTestRedisCache(t)
+
+	for i := 0; i < 2; i++ {
+		ok, err := rc.TakeRateToken("watch:ankr", 2)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, err := rc.TakeRateToken("watch:ankr", 2)
+	assert.NoError(t, err)
+	assert.False(t, ok, "budget spent")
+
+	ok, _ = rc.TakeRateToken("watch:blockscan_eth", 2)
+	assert.True(t, ok, "budgets are per name")
+}
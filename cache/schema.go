@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"tx-aggregator/types"
+)
+
+// schemaRevision is bumped by hand when the meaning of cached rows changes
+// without their Go type changing (e.g. a field is filled differently).
+const schemaRevision = 1
+
+// SchemaVersion identifies the layout of cached transactions and prefixes
+// every cache key. It is derived from the fields of types.Transaction, so a
+// change to the struct moves the cache to new keys: blobs written by older
+// builds are never read again, which makes them plain cache misses, and
+// they expire with their TTL.
+var SchemaVersion = schemaVersion(reflect.TypeOf(types.Transaction{}), schemaRevision)
+
+// schemaVersion hashes the JSON names and types of t's fields, recursively,
+// together with revision.
+func schemaVersion(t reflect.Type, revision int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "rev=%d;", revision)
+	describeType(&b, t)
+	sum := sha256.Sum256([]byte(b.String()))
+	return "s" + hex.EncodeToString(sum[:4])
+}
+
+// describeType writes a canonical description of t to b.
+func describeType(b *strings.Builder, t reflect.Type) {
+	switch t.Kind() {
+	case reflect.Struct:
+		b.WriteString("{")
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fmt.Fprintf(b, "%s:%s=", f.Name, f.Tag.Get("json"))
+			describeType(b, f.Type)
+			b.WriteString(";")
+		}
+		b.WriteString("}")
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		b.WriteString(t.Kind().String() + "(")
+		describeType(b, t.Elem())
+		b.WriteString(")")
+	case reflect.Map:
+		b.WriteString("map(")
+		describeType(b, t.Key())
+		b.WriteString(",")
+		describeType(b, t.Elem())
+		b.WriteString(")")
+	default:
+		b.WriteString(t.Kind().String())
+	}
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	assert.Regexp(t, regexp.MustCompile(`^s[0-9a-f]{8}$`), SchemaVersion)
+
+	type v1 struct {
+		Hash  string `json:"hash"`
+		State int    `json:"state"`
+	}
+	type renamed struct {
+		Hash  string `json:"hash"`
+		State int    `json:"status"`
+	}
+	type retyped struct {
+		Hash  string `json:"hash"`
+		State string `json:"state"`
+	}
+	base := schemaVersion(reflect.TypeOf(v1{}), 1)
+
+	assert.Equal(t, base, schemaVersion(reflect.TypeOf(v1{}), 1), "stable for the same layout")
+	assert.NotEqual(t, base, schemaVersion(reflect.TypeOf(renamed{}), 1), "JSON name changed")
+	assert.NotEqual(t, base, schemaVersion(reflect.TypeOf(retyped{}), 1), "field type changed")
+	assert.NotEqual(t, base, schemaVersion(reflect.TypeOf(v1{}), 2), "revision bumped")
+}
@@ -2,8 +2,10 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/redis/go-redis/v9"
+	"strings"
 	"sync"
 	"time"
 	"tx-aggregator/utils"
@@ -14,10 +16,32 @@ import (
 )
 
 // ParseTxAndSaveToCache groups a batch of transactions and writes them to
-// Redis using pipelines / bulk commands for maximum throughput.
+// Redis using pipelines / bulk commands for maximum throughput. The batch is
+// merged into what is already cached, so older rows that a page-limited
+// provider fetch no longer returns are kept, up to redis.max_cached_txs per
+// entry.
 func (r *RedisCache) ParseTxAndSaveToCache(
 	resp *types.TransactionResponse,
 	address string,
+) error {
+	return r.saveTransactions(resp, address, true)
+}
+
+// ReplaceTxInCache is ParseTxAndSaveToCache without the merge: the entries
+// it writes hold exactly the rows of resp. It is used when the rows are a
+// rewritten copy of the cached ones.
+func (r *RedisCache) ReplaceTxInCache(
+	resp *types.TransactionResponse,
+	address string,
+) error {
+	return r.saveTransactions(resp, address, false)
+}
+
+// saveTransactions implements ParseTxAndSaveToCache and ReplaceTxInCache.
+func (r *RedisCache) saveTransactions(
+	resp *types.TransactionResponse,
+	address string,
+	merge bool,
 ) error {
 	if resp == nil || len(resp.Result.Transactions) == 0 {
 		logger.Log.Info().Msg("no transactions to cache")
@@ -52,7 +76,7 @@ func (r *RedisCache) ParseTxAndSaveToCache(
 			nativeTxMap[key] = append(nativeTxMap[key], tx)
 		}
 
-		if tx.CoinType == types.CoinTypeToken && tx.TokenAddress != "" {
+		if types.IsTokenCoinType(tx.CoinType) && tx.TokenAddress != "" {
 			key := formatTokenKey(address, chainName, tx.TokenAddress)
 			tokenTxMap[key] = append(tokenTxMap[key], tx)
 
@@ -70,10 +94,26 @@ func (r *RedisCache) ParseTxAndSaveToCache(
 	errCh := make(chan error, 8)
 
 	// Helper to schedule JSON‑encoded pipelines.
+	limit := MaxCachedTxs()
+	zset := zsetLayout()
 	scheduleJSON := func(key string, txs []types.Transaction, label string) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if zset {
+				if err := r.saveZSet(key, txs, ttl, merge, limit); err != nil {
+					logger.Log.Error().Err(err).Str("key", key).Msg("cache " + label + " failed")
+					errCh <- err
+					return
+				}
+				logger.Log.Debug().Str("key", key).Int("txs", len(txs)).Msg("cached " + label)
+				return
+			}
+			var cached []types.Transaction
+			if merge {
+				cached = r.cachedTransactions(key)
+			}
+			txs = mergeTransactions(cached, txs, limit)
 			if err := r.SetJSONPipeline(key, txs, ttl); err != nil {
 				logger.Log.Error().Err(err).Str("key", key).Msg("cache " + label + " failed")
 				errCh <- err
@@ -89,6 +129,16 @@ func (r *RedisCache) ParseTxAndSaveToCache(
 		scheduleJSON(formatChainKey(address, chainName), txs, "chainTx")
 	}
 
+	// Bloom filters of addresses with history (see BloomConfig).
+	if config.Current().Redis.Bloom.Enabled {
+		for chainID := range chainTxMap {
+			chainName, _ := utils.ChainNameByID(chainID)
+			if err := r.BloomAdd(chainName, address); err != nil {
+				logger.Log.Warn().Err(err).Str("chain", chainName).Msg("Failed to add address to Bloom filter")
+			}
+		}
+	}
+
 	// Separate maps.
 	for k, v := range nativeTxMap {
 		scheduleJSON(k, v, "nativeTx")
@@ -133,6 +183,24 @@ func (r *RedisCache) ParseTxAndSaveToCache(
 	return nil
 }
 
+// cachedTransactions returns the rows stored under key. Missing or
+// unreadable entries yield none.
+func (r *RedisCache) cachedTransactions(key string) []types.Transaction {
+	val, err := r.Get(key)
+	if err != nil {
+		if err != redis.Nil {
+			logger.Log.Warn().Err(err).Str("key", key).Msg("Failed to read cache entry for merge, overwriting")
+		}
+		return nil
+	}
+	var txs []types.Transaction
+	if err := json.Unmarshal([]byte(val), &txs); err != nil {
+		logger.Log.Debug().Err(err).Str("key", key).Msg("Unreadable cache entry, overwriting")
+		return nil
+	}
+	return txs
+}
+
 // QueryTxFromCache (unchanged except for minor style tweaks).
 func (r *RedisCache) QueryTxFromCache(
 	req *types.TransactionQueryParams,
@@ -148,6 +216,7 @@ func (r *RedisCache) QueryTxFromCache(
 		logger.Log.Warn().Msg("no chain names given; skipping cache lookup")
 		return out, nil
 	}
+	zset := zsetLayout()
 
 	for _, chainName := range req.ChainNames {
 		wg.Add(1)
@@ -161,16 +230,26 @@ func (r *RedisCache) QueryTxFromCache(
 				key = formatTokenKey(req.Address, chain, req.TokenAddress)
 			}
 
-			val, err := r.Get(key)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
 			var txs []types.Transaction
-			if uErr := json.Unmarshal([]byte(val), &txs); uErr != nil {
-				errChan <- uErr
-				return
+			if zset {
+				rows, err := r.rangeZSet(key, req.StartBlock, req.EndBlock)
+				if err != nil {
+					errChan <- err
+					return
+				}
+				txs = rows
+			} else {
+				val, err := r.Get(key)
+				if err != nil {
+					errChan <- err
+					return
+				}
+				if uErr := json.Unmarshal([]byte(val), &txs); uErr != nil {
+					// A blob this build cannot read is as good as absent.
+					logger.Log.Debug().Err(uErr).Str("key", key).Msg("Unreadable cache entry, treating as miss")
+					return
+				}
+				txs = filterHeightRange(txs, req.StartBlock, req.EndBlock)
 			}
 
 			mu.Lock()
@@ -189,3 +268,111 @@ func (r *RedisCache) QueryTxFromCache(
 	}
 	return out, nil
 }
+
+// HasEntry reports whether anything is cached for the address, chains and
+// token of req, whatever its block range. It tells an entry with no rows in
+// the range apart from a cache miss.
+func (r *RedisCache) HasEntry(req *types.TransactionQueryParams) (bool, error) {
+	if len(req.ChainNames) == 0 {
+		return false, nil
+	}
+	keys := make([]string, len(req.ChainNames))
+	for i, chain := range req.ChainNames {
+		if req.TokenAddress == "" {
+			keys[i] = formatChainKey(req.Address, chain)
+		} else {
+			keys[i] = formatTokenKey(req.Address, chain, req.TokenAddress)
+		}
+		if zsetLayout() {
+			keys[i] = zsetIndexKey(keys[i])
+		}
+	}
+	// EXISTS with several keys is a cross-slot error in cluster mode.
+	pipe := r.conn().Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, k := range keys {
+		cmds[i] = pipe.Exists(r.ctx, k)
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return false, err
+	}
+	for _, c := range cmds {
+		if c.Val() > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// filterHeightRange keeps the rows whose height lies within [start, end].
+// Zero bounds are open.
+func filterHeightRange(txs []types.Transaction, start, end int64) []types.Transaction {
+	if start <= 0 && end <= 0 {
+		return txs
+	}
+	kept := txs[:0]
+	for _, tx := range txs {
+		if (start > 0 && tx.Height < start) || (end > 0 && tx.Height > end) {
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	return kept
+}
+
+// TokenSet returns the token contracts the address has transfers of on
+// chainName, as recorded when its transactions were cached. A missing set
+// yields none.
+func (r *RedisCache) TokenSet(address, chainName string) ([]string, error) {
+	return r.conn().SMembers(r.ctx, formatTokenSetKey(address, chainName)).Result()
+}
+
+// ScanChainAddresses returns every address with a cached chain-level entry
+// for chainName, in no particular order. In cluster mode every master is
+// scanned.
+func (r *RedisCache) ScanChainAddresses(chainName string) ([]string, error) {
+	suffix := "-" + strings.ToLower(chainName)
+	pattern := formatChainKey("*", chainName)
+	if zsetLayout() {
+		suffix = zsetIndexKey(suffix)
+		pattern = zsetIndexKey(pattern)
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]struct{})
+	)
+	scan := func(ctx context.Context, c redis.Cmdable) error {
+		iter := c.Scan(ctx, 0, pattern, 500).Iterator()
+		for iter.Next(ctx) {
+			// Addresses never contain "-"; a longer prefix means another
+			// chain whose name ends with this one.
+			addr := strings.TrimSuffix(strings.TrimPrefix(iter.Val(), keyPrefix()), suffix)
+			if addr == "" || strings.Contains(addr, "-") {
+				continue
+			}
+			mu.Lock()
+			seen[addr] = struct{}{}
+			mu.Unlock()
+		}
+		return iter.Err()
+	}
+
+	var err error
+	if cl, ok := r.conn().(*redis.ClusterClient); ok {
+		err = cl.ForEachMaster(r.ctx, func(ctx context.Context, c *redis.Client) error {
+			return scan(ctx, c)
+		})
+	} else {
+		err = scan(r.ctx, r.conn())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(seen))
+	for addr := range seen {
+		addresses = append(addresses, addr)
+	}
+	return addresses, nil
+}
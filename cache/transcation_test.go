@@ -97,6 +97,25 @@ func TestQueryTxFromCache_Integration(t *testing.T) {
 	assert.Equal(t, "0xabc", resp.Result.Transactions[0].Hash)
 }
 
+func TestQueryTxFromCache_UnreadableEntryIsMiss(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+
+	rc := newRedisCacheWithServer(t, s)
+	s.Set(formatChainKey("0xuser", "eth"), `{"not":"a list"}`)
+	s.Set(formatChainKey("0xuser", "bsc"), `[{"hash":"0xdef"}]`)
+
+	resp, err := rc.QueryTxFromCache(&types.TransactionQueryParams{
+		Address:    "0xUser",
+		ChainNames: []string{"ETH", "BSC"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Result.Transactions, 1) {
+		assert.Equal(t, "0xdef", resp.Result.Transactions[0].Hash)
+	}
+}
+
 func TestQueryTxFromCache_EmptyChains(t *testing.T) {
 	rc := &RedisCache{
 		client: nil,
@@ -112,3 +131,41 @@ func TestQueryTxFromCache_EmptyChains(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, resp.Result.Transactions)
 }
+
+func TestScanChainAddresses(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+
+	rc := newRedisCacheWithServer(t, s)
+
+	s.Set(formatChainKey("0xaa", "eth"), "[]")
+	s.Set(formatNativeKey("0xaa", "eth"), "[]")
+	s.Set(formatTokenSetKey("0xaa", "eth"), "x")
+	s.Set(formatChainKey("0xbb", "eth"), "[]")
+	s.Set(formatChainKey("0xcc", "bsc"), "[]")
+	s.Set(formatChainKey("0xdd", "arb-eth"), "[]") // chain "ARB-ETH", not "ETH"
+	s.Set("0xee-eth", "[]")                         // written before schema versioning
+
+	addrs, err := rc.ScanChainAddresses("ETH")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0xaa", "0xbb"}, addrs)
+}
+
+func TestTokenSet(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+
+	rc := newRedisCacheWithServer(t, s)
+	_, err = s.SAdd(formatTokenSetKey("0xaa", "eth"), "0xusdt", "0xdai")
+	assert.NoError(t, err)
+
+	tokens, err := rc.TokenSet("0xAA", "ETH")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0xusdt", "0xdai"}, tokens)
+
+	tokens, err = rc.TokenSet("0xbb", "ETH")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+	"tx-aggregator/types"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// formatWatchKey is the key holding the watch id as JSON. It expires with
+// the watch.
+func formatWatchKey(id string) string {
+	return keyPrefix() + "watch:" + id
+}
+
+// formatWatchIndexKey is the sorted set of every watch id, scored by expiry.
+func formatWatchIndexKey() string {
+	return keyPrefix() + "watches"
+}
+
+// formatOwnerWatchesKey is the sorted set of the watch ids of owner, scored
+// by expiry.
+func formatOwnerWatchesKey(owner string) string {
+	return keyPrefix() + "watches:owner:" + owner
+}
+
+// SaveWatch creates or updates w. The watch expires at w.ExpiresTime.
+func (r *RedisCache) SaveWatch(w types.Watch) error {
+	ttl := time.Until(time.Unix(w.ExpiresTime, 0))
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	member := redis.Z{Score: float64(w.ExpiresTime), Member: w.ID}
+	pipe := r.conn().Pipeline()
+	pipe.Set(r.ctx, formatWatchKey(w.ID), data, ttl)
+	pipe.ZAdd(r.ctx, formatWatchIndexKey(), member)
+	pipe.ZAdd(r.ctx, formatOwnerWatchesKey(w.Owner), member)
+	_, err = pipe.Exec(r.ctx)
+	return err
+}
+
+// GetWatch returns the watch id; false means it does not exist or expired.
+func (r *RedisCache) GetWatch(id string) (types.Watch, bool, error) {
+	val, err := r.conn().Get(r.ctx, formatWatchKey(id)).Result()
+	if err == redis.Nil {
+		return types.Watch{}, false, nil
+	}
+	if err != nil {
+		return types.Watch{}, false, err
+	}
+	var w types.Watch
+	if err := json.Unmarshal([]byte(val), &w); err != nil {
+		return types.Watch{}, false, err
+	}
+	return w, true, nil
+}
+
+// DeleteWatch removes w and its refresh schedule.
+func (r *RedisCache) DeleteWatch(w types.Watch) error {
+	pipe := r.conn().Pipeline()
+	pipe.Del(r.ctx, formatWatchKey(w.ID))
+	pipe.ZRem(r.ctx, formatWatchScheduleKey(), w.ID)
+	pipe.ZRem(r.ctx, formatWatchIndexKey(), w.ID)
+	pipe.ZRem(r.ctx, formatOwnerWatchesKey(w.Owner), w.ID)
+	_, err := pipe.Exec(r.ctx)
+	return err
+}
+
+// Watches returns the live watches of owner, or of every owner when owner is
+// empty, soonest to expire first. Expired ids are pruned on the way.
+func (r *RedisCache) Watches(owner string) ([]types.Watch, error) {
+	key := formatWatchIndexKey()
+	if owner != "" {
+		key = formatOwnerWatchesKey(owner)
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := r.conn().ZRemRangeByScore(r.ctx, key, "-inf", "("+now).Err(); err != nil {
+		return nil, err
+	}
+	ids, err := r.conn().ZRange(r.ctx, key, 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	// One GET per key: a multi-key MGET is a cross-slot error in cluster mode.
+	pipe := r.conn().Pipeline()
+	cmds := make([]*redis.StringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.Get(r.ctx, formatWatchKey(id))
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	watches := make([]types.Watch, 0, len(ids))
+	for _, c := range cmds {
+		var w types.Watch
+		if c.Err() != nil || json.Unmarshal([]byte(c.Val()), &w) != nil {
+			continue // deleted or expired between the two reads
+		}
+		watches = append(watches, w)
+	}
+	return watches, nil
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+func TestWatches(t *testing.T) {
+	rc := newTestRedisCache(t)
+	now := time.Now()
+
+	a := types.Watch{ID: "a", Owner: "wallet", Address: "0xabc", ExpiresTime: now.Add(time.Hour).Unix()}
+	b := types.Watch{ID: "b", Owner: "explorer", Address: "0xdef", ExpiresTime: now.Add(2 * time.Hour).Unix()}
+	expired := types.Watch{ID: "c", Owner: "wallet", Address: "0x123", ExpiresTime: now.Add(-time.Minute).Unix()}
+	for _, w := range []types.Watch{a, b, expired} {
+		assert.NoError(t, rc.SaveWatch(w))
+	}
+
+	got, ok, err := rc.GetWatch("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, a, got)
+	_, ok, _ = rc.GetWatch("c")
+	assert.False(t, ok, "expired watches are not stored")
+
+	all, err := rc.Watches("")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Watch{a, b}, all, "soonest to expire first")
+
+	own, err := rc.Watches("wallet")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Watch{a}, own)
+
+	assert.NoError(t, rc.DeleteWatch(a))
+	own, _ = rc.Watches("wallet")
+	assert.Empty(t, own)
+	all, _ = rc.Watches("")
+	assert.Equal(t, []types.Watch{b}, all)
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache layouts selectable with redis.layout.
+const (
+	// LayoutBlob stores each entry as one JSON array (the default).
+	LayoutBlob = "blob"
+	// LayoutZSet stores each entry as a sorted set of row keys scored by
+	// block height plus a hash from row key to the encoded row, so block
+	// ranges are read with ZRANGEBYSCORE instead of decoding the whole entry.
+	// With cache encryption on, row keys are replaced by opaque HMAC IDs.
+	LayoutZSet = "zset"
+)
+
+// zsetLayout reports whether redis.layout selects the sorted-set layout.
+func zsetLayout() bool {
+	return strings.EqualFold(config.Current().Redis.Layout, LayoutZSet)
+}
+
+// zsetIndexKey is the sorted set (row key scored by height) of entry key.
+func zsetIndexKey(key string) string {
+	return key + ":idx"
+}
+
+// zsetRowsKey is the hash (row key → encoded row) of entry key.
+func zsetRowsKey(key string) string {
+	return key + ":rows"
+}
+
+// zsetMemberID is the sorted-set member and hash field of tx. With cache
+// encryption on it is an HMAC of the row key, so the index does not expose
+// hashes and addresses that the sealed rows hide.
+func (r *RedisCache) zsetMemberID(tx types.Transaction) string {
+	k := utils.TransactionKey(tx)
+	if r.cipher == nil {
+		return k
+	}
+	return r.cipher.MemberID(k)
+}
+
+// saveZSet writes txs to the sorted-set entry key. With merge, rows already
+// stored are kept, and replaced when txs holds the same row; without, the
+// entry is rewritten. Either way the oldest rows beyond limit are removed.
+func (r *RedisCache) saveZSet(key string, txs []types.Transaction, ttl time.Duration, merge bool, limit int) error {
+	idxKey, rowsKey := zsetIndexKey(key), zsetRowsKey(key)
+	txs = mergeTransactions(nil, txs, limit)
+
+	pipe := r.conn().Pipeline()
+	if !merge {
+		pipe.Del(r.ctx, idxKey, rowsKey)
+	}
+	members := make([]redis.Z, 0, len(txs))
+	fields := make([]interface{}, 0, 2*len(txs))
+	var retired []string
+	for _, tx := range txs {
+		data, err := r.encodeJSON(tx)
+		if err != nil {
+			return err
+		}
+		k := r.zsetMemberID(tx)
+		members = append(members, redis.Z{Score: float64(tx.Height), Member: k})
+		fields = append(fields, k, data)
+		if merge && r.cipher != nil {
+			retired = append(retired, r.cipher.RetiredMemberIDs(utils.TransactionKey(tx))...)
+		}
+	}
+	if len(retired) > 0 {
+		// Drop copies of these rows stored in plaintext or under a rotated key.
+		pipe.ZRem(r.ctx, idxKey, toArgs(retired)...)
+		pipe.HDel(r.ctx, rowsKey, retired...)
+	}
+	if len(members) > 0 {
+		pipe.ZAdd(r.ctx, idxKey, members...)
+		pipe.HSet(r.ctx, rowsKey, fields...)
+	}
+	card := pipe.ZCard(r.ctx, idxKey)
+	if ttl > 0 {
+		pipe.Expire(r.ctx, idxKey, ttl)
+		pipe.Expire(r.ctx, rowsKey, ttl)
+	}
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return err
+	}
+
+	excess := card.Val() - int64(limit)
+	if limit <= 0 || excess <= 0 {
+		return nil
+	}
+	stale, err := r.conn().ZRange(r.ctx, idxKey, 0, excess-1).Result()
+	if err != nil || len(stale) == 0 {
+		return err
+	}
+	pipe = r.conn().Pipeline()
+	pipe.ZRem(r.ctx, idxKey, toArgs(stale)...)
+	pipe.HDel(r.ctx, rowsKey, stale...)
+	_, err = pipe.Exec(r.ctx)
+	return err
+}
+
+// rangeZSet returns the rows of the sorted-set entry key whose height lies
+// within [start, end], newest first. Zero bounds are open. It returns
+// redis.Nil when no row matches.
+func (r *RedisCache) rangeZSet(key string, start, end int64) ([]types.Transaction, error) {
+	opt := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if start > 0 {
+		opt.Min = strconv.FormatInt(start, 10)
+	}
+	if end > 0 {
+		opt.Max = strconv.FormatInt(end, 10)
+	}
+	ids, err := r.conn().ZRevRangeByScore(r.ctx, zsetIndexKey(key), opt).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, redis.Nil
+	}
+
+	rowsKey := zsetRowsKey(key)
+	vals, err := r.conn().HMGet(r.ctx, rowsKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]types.Transaction, 0, len(vals))
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			continue // expired or trimmed between the two reads
+		}
+		data, err := r.decode(rowsKey, []byte(s))
+		if err != nil {
+			return nil, err
+		}
+		var tx types.Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			logger.Log.Debug().Err(err).Str("key", rowsKey).Str("row", ids[i]).Msg("Unreadable cached row, skipping")
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// toArgs converts strings to the variadic arguments of go-redis commands.
+func toArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
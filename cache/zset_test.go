@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// withZSetLayout switches redis.layout to zset for the duration of a test.
+func withZSetLayout(t *testing.T, maxCachedTxs int) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Redis.Layout = LayoutZSet
+	cfg.Redis.TTLSeconds = 100
+	cfg.Redis.MaxCachedTxs = maxCachedTxs
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func heightsResponse(heights ...int64) *types.TransactionResponse {
+	resp := &types.TransactionResponse{}
+	for _, h := range heights {
+		resp.Result.Transactions = append(resp.Result.Transactions, types.Transaction{
+			ChainID:  1,
+			Hash:     "0x" + string(rune('a'+h%26)),
+			Height:   h,
+			CoinType: types.CoinTypeNative,
+		})
+	}
+	return resp
+}
+
+func TestZSetLayout_RangeQueries(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+	withZSetLayout(t, 0)
+
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(10, 20), "0xUser"))
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(20, 30), "0xUser"))
+	assert.True(t, s.Exists(zsetIndexKey(formatChainKey("0xuser", "ETH"))))
+
+	query := func(start, end int64) []int64 {
+		resp, err := rc.QueryTxFromCache(&types.TransactionQueryParams{
+			Address: "0xUser", ChainNames: []string{"ETH"}, StartBlock: start, EndBlock: end,
+		})
+		assert.NoError(t, err)
+		var heights []int64
+		for _, tx := range resp.Result.Transactions {
+			heights = append(heights, tx.Height)
+		}
+		return heights
+	}
+	assert.Equal(t, []int64{30, 20, 10}, query(0, 0), "merged, newest first")
+	assert.Equal(t, []int64{30, 20}, query(15, 0))
+	assert.Equal(t, []int64{20}, query(15, 25))
+	assert.Empty(t, query(31, 0))
+
+	ok, err := rc.HasEntry(&types.TransactionQueryParams{Address: "0xUser", ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	assert.True(t, ok, "an empty range is not a miss")
+
+	addrs, err := rc.ScanChainAddresses("ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xuser"}, addrs)
+
+	assert.NoError(t, rc.ReplaceTxInCache(heightsResponse(40), "0xUser"))
+	assert.Equal(t, []int64{40}, query(0, 0))
+}
+
+func TestZSetLayout_TrimsOldestRows(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+	withZSetLayout(t, 2)
+
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(10, 20), "0xUser"))
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(30), "0xUser"))
+
+	key := formatChainKey("0xuser", "ETH")
+	members, err := s.ZMembers(zsetIndexKey(key))
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+	fields, err := s.HKeys(zsetRowsKey(key))
+	assert.NoError(t, err)
+	assert.Len(t, fields, 2, "trimmed rows leave the hash too")
+}
+
+func TestZSetLayout_OpaqueMembersWhenEncrypted(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc := newRedisCacheWithServer(t, s)
+	withZSetLayout(t, 0)
+
+	// A row cached before encryption was enabled is replaced, not duplicated.
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(10), "0xUser"))
+	old, err := NewCipher("k1", map[string][]byte{"k1": testKey('a')})
+	assert.NoError(t, err)
+	rc.SetCipher(old)
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(10, 20), "0xUser"))
+
+	// After rotation the rows move to IDs under the new key.
+	rotated, err := NewCipher("k2", map[string][]byte{"k1": testKey('a'), "k2": testKey('b')})
+	assert.NoError(t, err)
+	rc.SetCipher(rotated)
+	assert.NoError(t, rc.ParseTxAndSaveToCache(heightsResponse(10, 20), "0xUser"))
+
+	key := formatChainKey("0xuser", "ETH")
+	members, err := s.ZMembers(zsetIndexKey(key))
+	assert.NoError(t, err)
+	fields, err := s.HKeys(zsetRowsKey(key))
+	assert.NoError(t, err)
+	assert.Len(t, members, 2)
+	assert.ElementsMatch(t, members, fields)
+	for _, m := range members {
+		assert.True(t, strings.HasPrefix(m, "h:k2:"), m)
+		assert.NotContains(t, m, "0x")
+	}
+
+	resp, err := rc.QueryTxFromCache(&types.TransactionQueryParams{Address: "0xUser", ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Result.Transactions, 2)
+}
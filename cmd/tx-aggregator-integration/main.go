@@ -22,6 +22,12 @@ import (
 // envFlag defines the environment to run tests against (local, local-docker, dev, test, prod, or all)
 var envFlag = flag.String("env", "local", "environment to run (value must exist in envHosts or 'all')")
 
+// reportFlag posts count drops to the service's /admin/regressions endpoint so they are alerted on
+var reportFlag = flag.Bool("report", false, "report count drops to POST /admin/regressions (needs an admin API key)")
+
+// apiKeyFlag is the API key sent with regression reports
+var apiKeyFlag = flag.String("api-key", os.Getenv("TX_AGGREGATOR_API_KEY"), "API key for -report (default $TX_AGGREGATOR_API_KEY)")
+
 // envHosts maps environment names to their corresponding base URLs
 var envHosts = map[string]string{
 	"local":        "http://127.0.0.1:8080",
@@ -56,11 +62,18 @@ func main() {
 
 	// Run test suites for each environment
 	exitCode := 0
+	hashesByEnv := make(map[string]map[string]string)
 	for _, env := range envs {
 		fmt.Printf("\n=== Environment: %s (%s) ===\n", env, envHosts[env])
-		if !runSuite(envHosts[env], paths) {
+		ok, hashes := runSuite(envHosts[env], paths)
+		if !ok {
 			exitCode = 1
 		}
+		hashesByEnv[env] = hashes
+	}
+
+	if len(envs) > 1 {
+		compareAcrossEnvs(envs, paths, hashesByEnv)
 	}
 
 	os.Exit(exitCode)
@@ -87,8 +100,9 @@ func resolveEnvs(flagValue string) ([]string, error) {
 
 // runSuite executes the test suite against the specified base URL with the given test paths.
 // It makes two requests to each endpoint, compares the responses, and checks transaction counts.
-// Returns true if all tests pass, false otherwise.
-func runSuite(baseURL string, paths []string) bool {
+// Requests ask for debug meta, so responses are compared by their canonical hash when available.
+// Returns true if all tests pass, false otherwise, plus the response hash of every test path.
+func runSuite(baseURL string, paths []string) (bool, map[string]string) {
 	passed := 0
 	base, _ := url.Parse(baseURL)
 	expectedCounts := loadExpectedCounts()
@@ -96,9 +110,10 @@ func runSuite(baseURL string, paths []string) bool {
 	for k, v := range expectedCounts {
 		updatedCounts[k] = v
 	}
+	hashes := make(map[string]string)
 
 	for idx, p := range paths {
-		fullURL := buildFullURL(base, p)
+		fullURL := withDebug(buildFullURL(base, p))
 		fmt.Printf("Test #%d: %s\n", idx+1, fullURL)
 
 		// Make first request
@@ -122,6 +137,7 @@ func runSuite(baseURL string, paths []string) bool {
 		count := extractCount(secondResp)
 		relURI := buildFullURL(base, p)[len(base.Scheme+"://"+base.Host):]
 		prevCount, exists := expectedCounts[relURI]
+		hashes[p] = extractHash(secondResp)
 
 		// Handle case when this is the first time testing this endpoint
 		if !exists {
@@ -134,11 +150,21 @@ func runSuite(baseURL string, paths []string) bool {
 		// Fail if transaction count has decreased
 		if count < prevCount {
 			fmt.Printf("❌ FAIL: item count dropped! current=%d, expected=%d\n", count, prevCount)
+			if *reportFlag {
+				reportRegression(base, relURI, prevCount, count)
+			}
 			continue
 		}
 
-		// Fail if responses don't match
-		if !assert.ObjectsAreEqual(firstResp, secondResp) {
+		// Fail if responses don't match (cheap hash check first, full compare as fallback)
+		firstHash, secondHash := extractHash(firstResp), extractHash(secondResp)
+		if firstHash != "" && secondHash != "" {
+			if firstHash != secondHash {
+				fmt.Printf("❌ FAIL: response hash mismatch (%s != %s)\n", firstHash, secondHash)
+				printResponseDiff(firstResp, secondResp)
+				continue
+			}
+		} else if !assert.ObjectsAreEqual(firstResp, secondResp) {
 			fmt.Println("❌ FAIL: response mismatch")
 			printResponseDiff(firstResp, secondResp)
 			continue
@@ -158,7 +184,99 @@ func runSuite(baseURL string, paths []string) bool {
 	}
 
 	fmt.Printf("Summary: %d / %d passed\n", passed, len(paths))
-	return passed == len(paths)
+	return passed == len(paths), hashes
+}
+
+// compareAcrossEnvs reports test paths whose response hash differs between
+// environments. It is informational only: environments may legitimately lag
+// behind each other.
+func compareAcrossEnvs(envs []string, paths []string, hashesByEnv map[string]map[string]string) {
+	fmt.Println("\n=== Cross-environment hash comparison ===")
+	mismatches := 0
+	for _, p := range paths {
+		var ref string
+		same := true
+		for _, env := range envs {
+			h := hashesByEnv[env][p]
+			if h == "" {
+				continue
+			}
+			if ref == "" {
+				ref = h
+			} else if h != ref {
+				same = false
+			}
+		}
+		if same {
+			continue
+		}
+		mismatches++
+		fmt.Printf("≠ %s\n", p)
+		for _, env := range envs {
+			fmt.Printf("    %-12s %s\n", env, hashesByEnv[env][p])
+		}
+	}
+	fmt.Printf("Environments agree on %d / %d paths\n", len(paths)-mismatches, len(paths))
+}
+
+// withDebug adds debug=true to the URL so the service returns meta.hash.
+func withDebug(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	q.Set("debug", "true")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// extractHash returns meta.hash from a response payload, or "" when absent.
+func extractHash(m map[string]interface{}) string {
+	meta, ok := m["meta"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	hash, _ := meta["hash"].(string)
+	return hash
+}
+
+// reportRegression posts a count drop of relURI to the service under test.
+func reportRegression(base *url.URL, relURI string, expected, actual int) {
+	u, err := url.Parse(relURI)
+	if err != nil {
+		fmt.Println("Failed to parse test path for report:", err)
+		return
+	}
+	q := u.Query()
+	finding := map[string]interface{}{
+		"source":       "integration",
+		"address":      q.Get("address"),
+		"chainName":    q.Get("chainName"),
+		"tokenAddress": q.Get("tokenAddress"),
+		"expected":     expected,
+		"actual":       actual,
+	}
+	if finding["chainName"] == "" {
+		finding["chainName"] = "ALL"
+	}
+	body, _ := json.Marshal(finding)
+
+	req, err := http.NewRequest(http.MethodPost, buildFullURL(base, "/admin/regressions"), strings.NewReader(string(body)))
+	if err != nil {
+		fmt.Println("Failed to build regression report:", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", *apiKeyFlag)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("Failed to report regression:", err)
+		return
+	}
+	defer resp.Body.Close()
+	fmt.Printf("   reported regression (status %d)\n", resp.StatusCode)
 }
 
 // loadTestCases reads test case URLs from the specified file.
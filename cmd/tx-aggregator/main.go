@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
+	"time"
 	"tx-aggregator/consul"
-	"tx-aggregator/provider/blockscan"
 	"tx-aggregator/types"
 	"tx-aggregator/usecase"
 
@@ -15,133 +14,218 @@ import (
 	consulapi "github.com/hashicorp/consul/api"
 
 	"tx-aggregator/api"
+	"tx-aggregator/archive"
+	"tx-aggregator/backfill"
+	"tx-aggregator/benchmark"
 	"tx-aggregator/cache"
 	"tx-aggregator/config"
+	"tx-aggregator/errorreport"
+	"tx-aggregator/health"
+	"tx-aggregator/indexer"
 	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
 	"tx-aggregator/provider"
-	"tx-aggregator/provider/ankr"
-	"tx-aggregator/provider/blockscout"
+	"tx-aggregator/provider/registry"
+	"tx-aggregator/regression"
+	"tx-aggregator/replay"
 	"tx-aggregator/router"
+	"tx-aggregator/server"
+	"tx-aggregator/shadow"
+	"tx-aggregator/storage"
+	"tx-aggregator/taxlot"
+	"tx-aggregator/tokenlist"
 	"tx-aggregator/utils"
+	"tx-aggregator/vault"
+	"tx-aggregator/warm"
+	"tx-aggregator/watch"
 )
 
 func main() {
 	logger.Log.Info().Msg("==== Starting tx-aggregator ====")
 
+	// CONFIG_FILE runs the service standalone, without Consul
+	standaloneFile := config.StandaloneFile()
+
 	// 1. Load bootstrap config (for Consul + service registration)
-	bootstrapFile := consul.BootstrapPath()
-	logger.Log.Info().Str("file", bootstrapFile).Msg("Loading bootstrap config")
+	bootstrapCfg := &types.BootstrapConfig{}
+	if standaloneFile == "" {
+		bootstrapFile := consul.BootstrapPath()
+		logger.Log.Info().Str("file", bootstrapFile).Msg("Loading bootstrap config")
 
-	bootstrapCfg, err := consul.LoadBootstrap(bootstrapFile)
-	if err != nil {
-		logger.Log.Fatal().Err(err).Str("file", bootstrapFile).Msg("Failed to load bootstrap config")
+		var err error
+		bootstrapCfg, err = consul.LoadBootstrap(bootstrapFile)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Str("file", bootstrapFile).Msg("Failed to load bootstrap config")
+		}
+		logger.Log.Info().
+			Str("consul.address", bootstrapCfg.Consul.Address).
+			Str("consul.scheme", bootstrapCfg.Consul.Scheme).
+			Str("consul.datacenter", bootstrapCfg.Consul.Datacenter).
+			Strs("etcd.endpoints", bootstrapCfg.Etcd.Endpoints).
+			Str("service.name", bootstrapCfg.Service.Name).
+			Str("service.ip", bootstrapCfg.Service.IP).
+			Int("service.port", bootstrapCfg.Service.Port).
+			Msg("Bootstrap config loaded")
+	}
+
+	// 2. Load runtime config from Consul KV or etcd, or only from the standalone file
+	if standaloneFile != "" {
+		config.InitFromFile(standaloneFile)
+	} else {
+		logger.Log.Info().Msg("Initializing runtime configuration from KV")
+		config.Init(bootstrapCfg)
 	}
-	logger.Log.Info().
-		Str("consul.address", bootstrapCfg.Consul.Address).
-		Str("consul.scheme", bootstrapCfg.Consul.Scheme).
-		Str("consul.datacenter", bootstrapCfg.Consul.Datacenter).
-		Str("service.name", bootstrapCfg.Service.Name).
-		Str("service.ip", bootstrapCfg.Service.IP).
-		Int("service.port", bootstrapCfg.Service.Port).
-		Msg("Bootstrap config loaded")
-
-	// 2. Load runtime config from Consul KV
-	logger.Log.Info().Msg("Initializing runtime configuration from Consul KV")
-	config.Init(bootstrapCfg)
 
 	// 3. Init logger (after config)
 	logger.Init(config.Current().Log.Level, config.Current().Log.Path, config.Current().Log.ConsoleFormat, config.Current().Log.FileFormat)
 
-	// 4. Setup Consul client
-	logger.Log.Info().Str("consul.address", bootstrapCfg.Consul.Address).Msg("Creating Consul API client")
-	consulCfg := consulapi.DefaultConfig()
-	consulCfg.Address = bootstrapCfg.Consul.Address
-	consulCfg.Scheme = bootstrapCfg.Consul.Scheme
-	consulCfg.Datacenter = bootstrapCfg.Consul.Datacenter
-	consulCfg.Token = bootstrapCfg.Consul.Token
+	// Report error-level events to Sentry while sentry.enabled is set
+	reporter := errorreport.NewReporter()
+	logger.AddSink(reporter)
 
-	consulClient, err := consulapi.NewClient(consulCfg)
-	if err != nil {
-		logger.Log.Fatal().Err(err).Msg("Failed to connect to Consul API")
+	// 4. Setup Consul client, unless standalone or configured from etcd
+	// without a Consul address
+	useConsul := standaloneFile == "" && (len(bootstrapCfg.Etcd.Endpoints) == 0 || bootstrapCfg.Consul.Address != "")
+	var consulClient *consulapi.Client
+	var consulPinger health.Pinger
+	if useConsul {
+		logger.Log.Info().Str("consul.address", bootstrapCfg.Consul.Address).Msg("Creating Consul API client")
+		consulCfg := consulapi.DefaultConfig()
+		consulCfg.Address = bootstrapCfg.Consul.Address
+		consulCfg.Scheme = bootstrapCfg.Consul.Scheme
+		consulCfg.Datacenter = bootstrapCfg.Consul.Datacenter
+		consulCfg.Token = bootstrapCfg.Consul.Token
+
+		var err error
+		consulClient, err = consulapi.NewClient(consulCfg)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to connect to Consul API")
+		}
+		consulPinger = consul.NewPinger(consulClient)
+		logger.Log.Info().Msg("Connected to Consul successfully")
 	}
-	logger.Log.Info().Msg("Connected to Consul successfully")
 
 	// 5. Setup Redis
 	logger.Log.Info().Strs("redis.addrs", config.Current().Redis.Addrs).Msg("Initializing Redis cache")
-	redisCache := cache.NewRedisCache(config.Current().Redis.Addrs, config.Current().Redis.Password)
-	if redisCache == nil {
-		logger.Log.Fatal().Msg("Failed to initialize Redis cache")
+	redisCache, err := cache.NewRedisCache(config.Current().Redis)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("Failed to initialize Redis cache")
 	}
-	logger.Log.Info().Msg("Redis cache initialized")
-
-	// 6. Setup providers
-	logger.Log.Info().Msg("Setting up providers")
-	registry := make(map[string]provider.Provider)
-	registry["ankr"] = ankr.NewAnkrProvider(config.Current().Ankr.APIKey, config.Current().Ankr.URL)
-	logger.Log.Info().Msg("Ankr provider registered")
-
-	// Register blockscout providers
-	for _, bs := range config.Current().Blockscout {
-		chainID, err := utils.ChainIDByName(bs.ChainName)
+	if enc := config.Current().Redis.Encryption; enc.Enabled {
+		keys, err := vault.ReadSecret(enc.Vault)
 		if err != nil {
-			logger.Log.Warn().Str("chain", bs.ChainName).Msg("Invalid chain name, skipping Blockscout")
-			continue
+			logger.Log.Fatal().Err(err).Str("path", enc.Vault.Path).Msg("Failed to load cache encryption keys from Vault")
 		}
-		key := fmt.Sprintf("blockscout_%s", strings.ToLower(bs.ChainName))
-		registry[key] = blockscout.NewBlockscoutProvider(chainID, bs)
-		logger.Log.Info().Str("provider", key).Str("url", bs.URL).Msg("Blockscout provider registered")
+		cipher, err := cache.NewCipherFromBase64(enc.ActiveKeyID, keys)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Invalid cache encryption keys")
+		}
+		redisCache.SetCipher(cipher)
+		logger.Log.Info().Str("active_key_id", enc.ActiveKeyID).Int("keys", len(keys)).Msg("Cache value encryption enabled")
+	}
+	if err := metrics.RegisterRedisPool("cache", redisCache.PoolSize(), redisCache.PoolStats); err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to register Redis pool metrics")
 	}
+	logger.Log.Info().Msg("Redis cache initialized")
 
-	// Register blockscan providers
-	for _, bs := range config.Current().Blockscan {
-		chainID, err := utils.ChainIDByName(bs.ChainName)
-		if err != nil {
-			logger.Log.Warn().Str("chain", bs.ChainName).Msg("Invalid chain name, skipping Blockscan")
-			continue
+	// Apply the settings read only at startup when a hot reload changes them
+	config.OnChange(func(old, new types.Config) {
+		if new.Log.Level != old.Log.Level {
+			logger.SetLevel(new.Log.Level)
+			logger.Log.Info().Int8("level", new.Log.Level).Msg("Log level changed")
 		}
-		key := fmt.Sprintf("blockscan_%s", strings.ToLower(bs.ChainName))
-		registry[key] = blockscan.NewBlockscanProvider(chainID, bs)
-		logger.Log.Info().Str("provider", key).Str("url", bs.URL).Msg("Blockscan provider registered")
+		if cache.ConnectionChanged(old.Redis, new.Redis) {
+			if err := redisCache.Reconnect(new.Redis); err != nil {
+				logger.Log.Error().Err(err).Msg("Failed to reconnect Redis with the new settings, keeping the previous connection")
+			}
+		}
+	})
+
+	// Archive raw provider payloads when enabled
+	if archiver := archive.NewArchiver(config.Current().Archive); archiver != nil {
+		utils.SetPayloadRecorder(archiver.Record)
+		archiver.Start()
+		logger.Log.Info().Msg("Payload archive started")
 	}
 
-	multiProvider := provider.NewMultiProvider(registry)
+	// 6. Setup providers
+	logger.Log.Info().Msg("Setting up providers")
+	multiProvider := provider.NewReloadingMultiProvider(registry.Build)
+	prober := health.NewProber(multiProvider)
+	multiProvider.SetHealth(prober)
+	prober.Start()
+	benchRunner := benchmark.NewRunner(multiProvider)
+	multiProvider.SetRanking(benchRunner)
+	benchRunner.Start()
+	regressionMonitor := regression.NewMonitor(multiProvider)
+	regressionMonitor.Start()
 
 	// 7. Setup Fiber app
 	logger.Log.Info().Msg("Setting up HTTP server and routes")
 	txService := usecase.NewService(redisCache, multiProvider)
+	if cfg := config.Current().Storage; cfg.Enabled {
+		store, err := storage.NewPostgresStore(cfg)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Failed to initialize transaction storage")
+		}
+		txService.SetStore(store)
+		logger.Log.Info().Msg("PostgreSQL transaction storage enabled")
+	}
 	txHandler := api.NewTransactionHandler(txService)
+	exporter := taxlot.NewExporter(txService, config.Current().Export.Workers, config.Current().Export.MaxJobs)
+	exportHandler := api.NewExportHandler(exporter)
+	backfiller := backfill.NewBackfiller(txService)
+	replayer := replay.NewReplayer(txService)
+	warm.NewWarmer(redisCache, txService).Start()
+	tokenlist.Start()
+	indexer.NewIndexer(redisCache, txService).Start()
+	watcher := watch.NewWatcher(redisCache, txService)
+	watcher.Start()
+	mirror := shadow.NewMirror(config.Current().Shadow)
+	if mirror != nil {
+		mirror.Start()
+	}
+	adminHandler := api.NewAdminHandler(benchRunner, regressionMonitor, backfiller, replayer, mirror, prober)
 
 	app := fiber.New()
-	router.SetupRoutes(app, txHandler)
+	healthHandler := api.NewHealthHandler(health.NewDeepChecker(multiProvider, redisCache, consulPinger))
+	router.SetupRoutes(app, txHandler, api.NewPortfolioHandler(txService), api.NewTokensHandler(txService), api.NewAllowanceHandler(txService), api.NewReceiptHandler(txService), exportHandler, adminHandler, api.NewCacheHandler(txService), api.NewWatchHandler(watcher), healthHandler, mirror)
 
-	// 8. Register service in Consul
+	// 8. Register service in Consul (when a Consul client was set up)
 	port := bootstrapCfg.Service.Port
 	if port == 0 {
 		port = config.Current().Server.Port
 	}
-	serviceIP := bootstrapCfg.Service.IP
-	if serviceIP == "" {
-		serviceIP, _ = utils.GetLocalIPv4()
-	}
+	var deregister func() error
+	if consulClient != nil {
+		serviceIP := bootstrapCfg.Service.IP
+		if serviceIP == "" {
+			serviceIP, _ = utils.GetLocalIPv4()
+		}
 
-	logger.Log.Info().
-		Str("service.name", bootstrapCfg.Service.Name).
-		Str("service.ip", serviceIP).
-		Int("service.port", port).
-		Msg("Registering service in Consul")
-
-	deregister, err := consul.Register(consulClient, types.Options{
-		Name:       bootstrapCfg.Service.Name,
-		ID:         fmt.Sprintf("%s-%s-%d", bootstrapCfg.Service.Name, serviceIP, port),
-		Address:    serviceIP,
-		Port:       port,
-		HealthPath: "/health",
-		Meta:       map[string]string{"env": os.Getenv("APP_ENV")},
-	})
-	if err != nil {
-		logger.Log.Fatal().Err(err).Msg("Consul service registration failed")
+		logger.Log.Info().
+			Str("service.name", bootstrapCfg.Service.Name).
+			Str("service.ip", serviceIP).
+			Int("service.port", port).
+			Msg("Registering service in Consul")
+
+		var err error
+		deregister, err = consul.Register(consulClient, types.Options{
+			Name:       bootstrapCfg.Service.Name,
+			ID:         fmt.Sprintf("%s-%s-%d", bootstrapCfg.Service.Name, serviceIP, port),
+			Address:    serviceIP,
+			Port:       port,
+			HealthPath: "/health",
+			TLS:        config.Current().Server.TLS.Enabled,
+			Meta:       map[string]string{"env": os.Getenv("APP_ENV")},
+		})
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msg("Consul service registration failed")
+		}
+		logger.Log.Info().Msg("Service registered successfully in Consul")
+	} else {
+		logger.Log.Info().Str("standalone_file", standaloneFile).Msg("Consul not used, not registering the service")
 	}
-	logger.Log.Info().Msg("Service registered successfully in Consul")
 
 	// 9. Graceful shutdown
 	go func() {
@@ -150,17 +234,39 @@ func main() {
 		sig := <-sigCh
 		logger.Log.Warn().Str("signal", sig.String()).Msg("Received shutdown signal")
 
-		if err := deregister(); err != nil {
-			logger.Log.Error().Err(err).Msg("Failed to deregister from Consul")
-		} else {
-			logger.Log.Info().Msg("Deregistered from Consul successfully")
+		if consulClient != nil {
+			if err := deregister(); err != nil {
+				logger.Log.Error().Err(err).Msg("Failed to deregister from Consul")
+			} else {
+				logger.Log.Info().Msg("Deregistered from Consul successfully")
+			}
 		}
+		reporter.Flush(2 * time.Second)
 		os.Exit(0)
 	}()
 
-	// 10. Start HTTP server
-	logger.Log.Info().Int("port", port).Msg("Starting Fiber HTTP server")
-	if err := app.Listen(fmt.Sprintf(":%d", port)); err != nil {
+	// 10. Re-read the configuration on SIGHUP
+	go func() {
+		hupCh := make(chan os.Signal, 1)
+		signal.Notify(hupCh, syscall.SIGHUP)
+		for range hupCh {
+			logger.Log.Info().Msg("Received SIGHUP, reloading configuration")
+			if err := config.Reload(); err != nil {
+				logger.Log.Error().Err(err).Msg("Configuration reload failed, keeping the previous configuration")
+				continue
+			}
+			logger.Log.Info().Uint64("generation", config.Generation()).Msg("Configuration reloaded")
+		}
+	}()
+
+	// 11. Start HTTP server
+	serverCfg := config.Current().Server
+	logger.Log.Info().
+		Str("host", serverCfg.Host).
+		Int("port", port).
+		Bool("tls", serverCfg.TLS.Enabled).
+		Msg("Starting Fiber HTTP server")
+	if err := server.Listen(app, serverCfg, port); err != nil {
 		logger.Log.Fatal().Err(err).Msg("Fiber server terminated unexpectedly")
 	}
 }
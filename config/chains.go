@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+// chainRegistry holds the last valid *types.ChainRegistry loaded from KV.
+// It stays empty when the registry key does not exist, in which case the chain
+// settings embedded in the service config are used unchanged.
+var chainRegistry atomic.Value
+
+// publishMu serialises the two refreshers (service config and chain registry)
+// so neither overwrites the other's latest snapshot.
+var publishMu sync.Mutex
+
+// CurrentChainRegistry returns the active chain registry, or nil when the
+// chain settings come from the service config.
+func CurrentChainRegistry() *types.ChainRegistry {
+	reg, _ := chainRegistry.Load().(*types.ChainRegistry)
+	return reg
+}
+
+// chainRegistryKey returns the KV path of the chain registry blob, taken
+// from the bootstrap section of the store in use.
+func chainRegistryKey(bootstrap *types.BootstrapConfig, src remoteSource, env string) string {
+	key := bootstrap.Consul.ChainRegistryKey
+	if src.provider == providerEtcd {
+		key = bootstrap.Etcd.ChainRegistryKey
+	}
+	if key != "" {
+		return key
+	}
+	return fmt.Sprintf("config/chains/%s", env)
+}
+
+// publish overlays the active chain registry on cfg, validates the result
+// and stores it, notifying the OnChange callbacks. It reports whether the
+// published snapshot changed; an invalid configuration is not stored and its
+// problems are returned.
+func publish(cfg types.Config) (bool, error) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	if reg := CurrentChainRegistry(); reg != nil {
+		cfg = ApplyChainRegistry(cfg, reg)
+	}
+	if err := cfg.Validate(); err != nil {
+		return false, err
+	}
+	old := Current()
+	if reflect.DeepEqual(old, cfg) {
+		return false, nil
+	}
+	runtimeCfg.Store(cfg)
+	generation.Add(1)
+	notify(old, cfg)
+	return true, nil
+}
+
+// ApplyChainRegistry returns cfg with its chain settings replaced by reg.
+func ApplyChainRegistry(cfg types.Config, reg *types.ChainRegistry) types.Config {
+	cfg.ChainNames = reg.ChainNames
+	cfg.NativeTokens = reg.NativeTokens
+	cfg.NativeDecimals = reg.NativeDecimals
+	cfg.Ankr.ChainIDs = reg.AnkrChainIDs
+	cfg.ExplorerURLs = reg.ExplorerURLs
+	return cfg
+}
+
+// maxNativeDecimals bounds native_decimals; no chain uses more than 18.
+const maxNativeDecimals = 36
+
+// ValidateChainRegistry rejects registries that would leave the service with
+// dangling references: every native token and decimals entry, Ankr chain and
+// explorer URL must point at a chain listed in chain_names, and chain IDs
+// must be unique.
+func ValidateChainRegistry(reg *types.ChainRegistry) error {
+	if len(reg.ChainNames) == 0 {
+		return fmt.Errorf("chain_names must not be empty")
+	}
+
+	known := make(map[int64]string, len(reg.ChainNames))
+	for name, id := range reg.ChainNames {
+		if id <= 0 {
+			return fmt.Errorf("chain %s has invalid chain ID %d", name, id)
+		}
+		if other, dup := known[id]; dup {
+			return fmt.Errorf("chain ID %d is used by both %s and %s", id, other, name)
+		}
+		known[id] = name
+	}
+
+	for idStr, symbol := range reg.NativeTokens {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("native_tokens key %q is not a chain ID", idStr)
+		}
+		if _, ok := known[id]; !ok {
+			return fmt.Errorf("native token %s references unknown chain ID %d", symbol, id)
+		}
+	}
+	for idStr, decimals := range reg.NativeDecimals {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("native_decimals key %q is not a chain ID", idStr)
+		}
+		if _, ok := known[id]; !ok {
+			return fmt.Errorf("native decimals reference unknown chain ID %d", id)
+		}
+		if decimals < 0 || decimals > maxNativeDecimals {
+			return fmt.Errorf("native decimals of chain ID %d out of range: %d", id, decimals)
+		}
+	}
+	for name, id := range reg.AnkrChainIDs {
+		if _, ok := known[id]; !ok {
+			return fmt.Errorf("ankr chain %s references unknown chain ID %d", name, id)
+		}
+	}
+	for name, raw := range reg.ExplorerURLs {
+		if _, ok := reg.ChainNames[name]; !ok {
+			return fmt.Errorf("explorer URL for unknown chain %s", name)
+		}
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("explorer URL for %s is not an absolute URL: %q", name, raw)
+		}
+	}
+	return nil
+}
+
+// loadChainRegistry reads and validates the registry blob at key.
+func loadChainRegistry(src remoteSource, key string) (*types.ChainRegistry, error) {
+	remote := viper.New()
+	remote.SetConfigType("yaml")
+	if err := remote.AddRemoteProvider(src.provider, src.endpoint, key); err != nil {
+		return nil, fmt.Errorf("%s provider init: %w", src.provider, err)
+	}
+	if err := remote.ReadRemoteConfig(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", key, err)
+	}
+
+	var reg types.ChainRegistry
+	if err := remote.Unmarshal(&reg); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", key, err)
+	}
+	if err := ValidateChainRegistry(&reg); err != nil {
+		return nil, fmt.Errorf("invalid chain registry %s: %w", key, err)
+	}
+	return &reg, nil
+}
+
+// initChainRegistry loads the registry once, before the first snapshot is
+// published. A missing or invalid blob leaves the chain settings embedded in
+// the service config in place.
+func initChainRegistry(src remoteSource, key string) {
+	reg, err := loadChainRegistry(src, key)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("key", key).Msg("chain registry not loaded – using chain settings from service config")
+		return
+	}
+	chainRegistry.Store(reg)
+	logger.Log.Info().Str("key", key).Int("chains", len(reg.ChainNames)).Msg("chain registry loaded")
+}
+
+// watchChainRegistry reloads the registry whenever its key changes (see
+// remoteSource.watch), independently of the service config.
+func watchChainRegistry(src remoteSource, key string) {
+	src.watch(key, func() {
+		if err := reloadChainRegistry(src, key); err != nil {
+			logger.Log.Error().Err(err).Str("key", key).Msg("chain registry refresh failed, keeping the previous registry")
+		}
+	})
+}
+
+// reloadChainRegistry reads the registry at key and publishes it when it
+// changed. A missing or invalid blob, or one that would make the
+// configuration invalid, keeps the previous registry in place.
+func reloadChainRegistry(src remoteSource, key string) error {
+	reg, err := loadChainRegistry(src, key)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(CurrentChainRegistry(), reg) {
+		return nil
+	}
+	prev := CurrentChainRegistry()
+	chainRegistry.Store(reg)
+	if _, err := publish(Current()); err != nil {
+		chainRegistry.Store(prev)
+		return fmt.Errorf("chain registry %s rejected: %w", key, err)
+	}
+	logger.Log.Info().Str("key", key).Int("chains", len(reg.ChainNames)).Msgf("chain registry hot‑reloaded from %s", src)
+	return nil
+}
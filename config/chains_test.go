@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+func validRegistry() *types.ChainRegistry {
+	return &types.ChainRegistry{
+		ChainNames:     map[string]int64{"ETH": 1, "BSC": 56},
+		NativeTokens:   map[string]string{"1": "ETH", "56": "BNB"},
+		NativeDecimals: map[string]int64{"56": 18},
+		AnkrChainIDs:   map[string]int64{"eth": 1, "bsc": 56},
+		ExplorerURLs:   map[string]string{"ETH": "https://etherscan.io"},
+	}
+}
+
+func TestValidateChainRegistry(t *testing.T) {
+	assert.NoError(t, ValidateChainRegistry(validRegistry()))
+
+	tests := []struct {
+		name   string
+		mutate func(r *types.ChainRegistry)
+	}{
+		{"empty chain names", func(r *types.ChainRegistry) { r.ChainNames = nil }},
+		{"duplicate chain ID", func(r *types.ChainRegistry) { r.ChainNames["ETH2"] = 1 }},
+		{"native token for unknown chain", func(r *types.ChainRegistry) { r.NativeTokens["137"] = "POL" }},
+		{"non-numeric native token key", func(r *types.ChainRegistry) { r.NativeTokens["eth"] = "ETH" }},
+		{"native decimals for unknown chain", func(r *types.ChainRegistry) { r.NativeDecimals["728126428"] = 6 }},
+		{"negative native decimals", func(r *types.ChainRegistry) { r.NativeDecimals["56"] = -1 }},
+		{"ankr chain for unknown chain", func(r *types.ChainRegistry) { r.AnkrChainIDs["polygon"] = 137 }},
+		{"explorer for unknown chain", func(r *types.ChainRegistry) { r.ExplorerURLs["POL"] = "https://polygonscan.com" }},
+		{"relative explorer URL", func(r *types.ChainRegistry) { r.ExplorerURLs["ETH"] = "etherscan.io" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := validRegistry()
+			tt.mutate(reg)
+			assert.Error(t, ValidateChainRegistry(reg))
+		})
+	}
+}
+
+func TestPublish_OverlaysChainRegistry(t *testing.T) {
+	defer chainRegistry.Store((*types.ChainRegistry)(nil))
+	defer SetCurrentConfig(types.Config{})
+
+	chainRegistry.Store(validRegistry())
+	cfg := validConfig()
+	cfg.ChainNames = map[string]int64{"OLD": 999}
+	_, err := publish(cfg)
+	assert.NoError(t, err)
+
+	cfg = Current()
+	assert.Equal(t, int64(56), cfg.ChainNames["BSC"])
+	assert.NotContains(t, cfg.ChainNames, "OLD")
+	assert.Equal(t, int64(1), cfg.Ankr.ChainIDs["eth"])
+	assert.Equal(t, int64(18), cfg.NativeDecimals["56"])
+	assert.Equal(t, int64(50), cfg.Response.Max, "service settings must be kept")
+}
@@ -1,15 +1,14 @@
 // Package config handles loading and hot‑reloading runtime configuration
-// from Consul KV (with an optional local‑file override).
+// from Consul KV or etcd (above an optional local file), or from a single
+// local file when running standalone.
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"reflect"
-	"strings"
 	"sync/atomic"
-	"time"
 
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper/remote" // enables Consul/etcd remote KV support
@@ -22,6 +21,16 @@ import (
 // atomic.Value gives us cheap, lock‑free, thread‑safe reads.
 var runtimeCfg atomic.Value // stores types.Config
 
+// generation counts the snapshots stored in runtimeCfg, so that consumers
+// holding derived state can cheaply tell whether it is out of date.
+var generation atomic.Uint64
+
+// Generation returns a number that changes every time a new configuration
+// snapshot is published.
+func Generation() uint64 {
+	return generation.Load()
+}
+
 // Current returns a read‑only snapshot of the latest configuration.
 func Current() types.Config {
 	v := runtimeCfg.Load()
@@ -31,8 +40,10 @@ func Current() types.Config {
 	return v.(types.Config)
 }
 
-// Init loads configuration from Consul KV (plus optional local overrides)
-// and starts a background goroutine that refreshes the settings every 10 s.
+// Init loads configuration from Consul KV, or etcd when the bootstrap file
+// lists etcd endpoints (above an optional local file), and starts the
+// background watchers applying changes as they are written (see Reload for
+// on-demand re-reads).
 func Init(bootstrap *types.BootstrapConfig) {
 	/* ────────────────────────────────────────────────────────────────
 	   1. Resolve environment, Consul address & token
@@ -54,10 +65,12 @@ func Init(bootstrap *types.BootstrapConfig) {
 	if consulToken != "" {
 		_ = os.Setenv("CONSUL_HTTP_TOKEN", consulToken) // for the Consul client
 	}
+	src := remoteSourceFor(bootstrap, consulAddr)
 
 	logger.Log.Info().
 		Str("env", env).
-		Str("consul.address", consulAddr).
+		Str("config.backend", src.String()).
+		Str("config.endpoint", src.endpoint).
 		Str("consul.token", maskToken(consulToken)).
 		Msg("initialising configuration")
 
@@ -65,31 +78,34 @@ func Init(bootstrap *types.BootstrapConfig) {
 	   2. Default values (safest, lowest precedence)
 	---------------------------------------------------------------- */
 	viper.SetDefault("server.port", 8080)
-	_ = viper.BindEnv("server.port", "APP_PORT")
+	bindEnv(viper.GetViper()) // TXAGG_* variables win over every layer
 
 	/* ────────────────────────────────────────────────────────────────
-	   3. Optional local override  (medium precedence)
+	   3. Optional local file  (below the KV layers, so Consul edits
+	      and hot reloads win over it)
 	---------------------------------------------------------------- */
-	overrideFile := fmt.Sprintf("config.%s.yaml", env)
-	viper.SetConfigName(strings.TrimSuffix(overrideFile, ".yaml"))
-	viper.AddConfigPath(filepath.Join(".", types.ConfigFolderPath)) // e.g. ./config
-	viper.AddConfigPath(".")                                        // project root
-
-	_ = viper.MergeInConfig() // ignore 'file not found'; merge if present
+	var applied []string
+	file, err := mergeOverride(viper.GetViper(), env)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("cannot read local configuration file")
+	}
+	if file != "" {
+		applied = append(applied, file)
+	}
 
 	/* ────────────────────────────────────────────────────────────────
-	   4. Load Consul KV  (highest precedence)
+	   4. Load KV layers  (higher precedence)
+	      base → <env> → <env>/<region>, later layers win
 	---------------------------------------------------------------- */
-	key := fmt.Sprintf("config/%s/%s", bootstrap.Service.Name, env) // KV path containing the YAML blob
-	if consulAddr != "" {
-		if err := viper.AddRemoteProvider("consul", consulAddr, key); err != nil {
-			logger.Log.Fatal().Err(err).Msg("cannot register Consul remote provider")
-		}
-		viper.SetConfigType("yaml")
-
-		if err := viper.ReadRemoteConfig(); err != nil {
-			logger.Log.Fatal().Err(err).Msg("cannot read configuration from Consul KV")
+	region := firstNonEmpty(os.Getenv("APP_REGION"), bootstrap.Service.Region)
+	layers := configLayers(bootstrap.Service.Name, env, region)
+	key := layers[1] // the environment layer is the only mandatory one
+	if src.enabled() {
+		kv, err := mergeLayers(viper.GetViper(), src, layers, key)
+		if err != nil {
+			logger.Log.Fatal().Err(err).Msgf("cannot read configuration from %s", src)
 		}
+		applied = append(applied, kv...)
 	} else {
 		logger.Log.Warn().Msg("CONSUL_ADDR missing – falling back to local defaults only")
 	}
@@ -98,49 +114,196 @@ func Init(bootstrap *types.BootstrapConfig) {
 	   5. Unmarshal first snapshot & publish it
 	---------------------------------------------------------------- */
 	var cfg types.Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := unmarshal(viper.GetViper(), &cfg); err != nil {
 		logger.Log.Fatal().Err(err).Msg("cannot unmarshal initial configuration")
 	}
-	runtimeCfg.Store(cfg) // first snapshot
+
+	// Chain metadata lives under its own KV path with its own refresher. It
+	// is loaded first so that the first snapshot is validated with it.
+	registryKey := chainRegistryKey(bootstrap, src, env)
+	if src.enabled() {
+		initChainRegistry(src, registryKey)
+	}
+
+	storeEffective(viper.AllSettings(), applied)
+	if _, err := publish(cfg); err != nil { // first snapshot
+		logger.Log.Fatal().Err(err).Strs("layers", applied).Msg("refusing to start with an invalid configuration")
+	}
 
 	logger.Log.Info().
 		Int("server.port", cfg.Server.Port).
+		Strs("layers", applied).
 		Msg("configuration loaded")
 
 	/* ────────────────────────────────────────────────────────────────
-	   6. Background refresher – poll Consul every 10 s
+	   6. Background refreshers – watch the KV layers and the chain
+	      registry for changes; Reload re-reads everything on demand
 	---------------------------------------------------------------- */
-	go func() {
-		ticker := time.NewTicker(10 * time.Second)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			/* 1. create a clean viper instance and pull the KV blob */
-			remote := viper.New()
-			remote.SetConfigType("yaml")
-			if err := remote.AddRemoteProvider("consul", consulAddr, key); err != nil {
-				logger.Log.Error().Err(err).Msg("consul provider init failed")
-				continue
-			}
-			if err := remote.ReadRemoteConfig(); err != nil {
-				logger.Log.Error().Err(err).Msg("cannot fetch remote config")
-				continue
+	reload := func() error {
+		remote := newViper()
+		remote.SetConfigType("yaml")
+		var applied []string
+		file, err := mergeOverride(remote, env)
+		if err != nil {
+			return err
+		}
+		if file != "" {
+			applied = append(applied, file)
+		}
+		if src.enabled() {
+			kv, err := mergeLayers(remote, src, layers, key)
+			if err != nil {
+				return fmt.Errorf("cannot fetch remote config: %w", err)
 			}
+			applied = append(applied, kv...)
+		}
+		return apply(remote, applied)
+	}
+	reloader.Store(func() error {
+		err := reload()
+		if src.enabled() {
+			err = errors.Join(err, reloadChainRegistry(src, registryKey))
+		}
+		return err
+	})
 
-			/* 2. unmarshal into a concrete struct */
-			var updated types.Config
-			if err := remote.Unmarshal(&updated); err != nil {
-				logger.Log.Error().Err(err).Msg("unmarshal failed")
-				continue
-			}
+	if !src.enabled() {
+		return
+	}
+	go watchChainRegistry(src, registryKey)
+	go src.watch(layerPrefix(bootstrap.Service.Name), func() {
+		if err := reload(); err != nil {
+			logger.Log.Error().Err(err).Msg("configuration reload failed, keeping the previous configuration")
+		}
+	})
+}
 
-			/* 3. swap in only when something actually changed */
-			if !reflect.DeepEqual(Current(), updated) {
-				runtimeCfg.Store(updated)
-				logger.Log.Info().Msg("configuration hot‑reloaded from Consul KV")
-			}
+// reloader re-reads every configuration source; it is set by Init and
+// InitFromFile.
+var reloader atomic.Value // stores func() error
+
+// Reload re-reads every configuration source now – the local file, the KV
+// layers and the chain registry, or the standalone file – and
+// publishes the result when it is valid and changed. It lets operators apply
+// a change on demand (see SIGHUP in main) rather than wait for the watchers.
+func Reload() error {
+	fn, _ := reloader.Load().(func() error)
+	if fn == nil {
+		return errors.New("configuration is not initialised")
+	}
+	return fn()
+}
+
+// apply unmarshals the settings of v, read from the applied sources, and
+// publishes them when they are valid and changed.
+func apply(v *viper.Viper, applied []string) error {
+	var updated types.Config
+	if err := unmarshal(v, &updated); err != nil {
+		return fmt.Errorf("unmarshal failed: %w", err)
+	}
+	changed, err := publish(updated)
+	if err != nil {
+		return err
+	}
+	if changed {
+		storeEffective(v.AllSettings(), applied)
+		logger.Log.Info().Strs("layers", applied).Msg("configuration hot‑reloaded")
+	}
+	return nil
+}
+
+// StandaloneFileEnv names the environment variable selecting file-only mode.
+const StandaloneFileEnv = "CONFIG_FILE"
+
+// StandaloneFile returns the configuration file given in CONFIG_FILE. When it
+// is set the service runs without Consul: configuration is loaded with
+// InitFromFile and the service is not registered.
+func StandaloneFile() string {
+	return os.Getenv(StandaloneFileEnv)
+}
+
+// InitFromFile loads configuration exclusively from the YAML file at path,
+// for running locally or in CI without a Consul agent. Nothing is read from
+// Consul KV, no chain registry is used and the file is only re-read by
+// Reload. TXAGG_* environment variables (and APP_PORT) still override
+// the file.
+func InitFromFile(path string) {
+	logger.Log.Info().Str("file", path).Msg("initialising configuration from file only, Consul disabled")
+
+	cfg, settings, err := loadFile(path)
+	if err != nil {
+		logger.Log.Fatal().Err(err).Msg("cannot load configuration file")
+	}
+	storeEffective(settings, []string{path})
+	if _, err := publish(cfg); err != nil {
+		logger.Log.Fatal().Err(err).Str("file", path).Msg("refusing to start with an invalid configuration")
+	}
+
+	logger.Log.Info().
+		Int("server.port", cfg.Server.Port).
+		Str("file", path).
+		Msg("configuration loaded")
+
+	reloader.Store(func() error {
+		v, err := readFile(path)
+		if err != nil {
+			return err
 		}
-	}()
+		return apply(v, []string{path})
+	})
+}
+
+// loadFile reads the configuration file at path with the same defaults as
+// Init and returns it with its raw settings.
+func loadFile(path string) (types.Config, map[string]interface{}, error) {
+	v, err := readFile(path)
+	if err != nil {
+		return types.Config{}, nil, err
+	}
+
+	var cfg types.Config
+	if err := unmarshal(v, &cfg); err != nil {
+		return types.Config{}, nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	return cfg, v.AllSettings(), nil
+}
+
+// readFile reads the configuration file at path into a new viper instance.
+func readFile(path string) (*viper.Viper, error) {
+	v := newViper()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// newViper returns a viper instance with the defaults and environment
+// bindings every configuration source is read with.
+func newViper() *viper.Viper {
+	v := viper.New()
+	v.SetDefault("server.port", 8080)
+	bindEnv(v)
+	return v
+}
+
+// mergeOverride merges the optional local file for env,
+// configfiles/config.<env>.yaml or ./config.<env>.yaml, into v. It returns
+// the file used, or "" when there is none; a file that cannot be read or
+// parsed is an error.
+func mergeOverride(v *viper.Viper, env string) (string, error) {
+	v.SetConfigName(fmt.Sprintf("config.%s", env))
+	v.AddConfigPath(filepath.Join(".", types.ConfigFolderPath)) // e.g. ./configfiles
+	v.AddConfigPath(".")                                        // project root
+
+	if err := v.MergeInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("merge %s: %w", v.ConfigFileUsed(), err)
+	}
+	return v.ConfigFileUsed(), nil
 }
 
 /* ──────────────────────────────────────────────────────────────────
@@ -165,7 +328,13 @@ func firstNonEmpty(candidates ...string) string {
 	return ""
 }
 
-// SetCurrentConfig is for testing purposes only.
+// SetCurrentConfig is for testing purposes only. It bypasses validation but,
+// like a hot reload, notifies the OnChange callbacks.
 func SetCurrentConfig(cfg types.Config) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	old := Current()
 	runtimeCfg.Store(cfg)
+	generation.Add(1)
+	notify(old, cfg)
 }
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -9,10 +11,51 @@ import (
 	"tx-aggregator/types"
 )
 
+// validConfig returns a minimal configuration that passes Validate.
+func validConfig() types.Config {
+	return types.Config{
+		Server:     types.ServerConfig{Port: 8080},
+		Redis:      types.RedisConfig{Addrs: []string{"127.0.0.1:6379"}, TTLSeconds: 60},
+		Response:   types.ResponseConfig{Max: 50},
+		ChainNames: map[string]int64{"eth": 1, "bsc": 56},
+		Ankr:       types.AnkrConfig{URL: "https://rpc.ankr.com/multichain"},
+		Blockscout: []types.BlockscoutConfig{{URL: "https://eth.blockscout.com/api/v2", ChainName: "ETH"}},
+		Providers: types.ProvidersConfig{ChainProviders: map[string][]string{
+			"eth": {"ankr", "blockscout_eth"},
+			"bsc": {"ankr"},
+		}},
+	}
+}
+
 func TestInit_WithLocalFileOnly(t *testing.T) {
 	// Clean state
 	viper.Reset()
-	SetCurrentConfig(types.Config{})
+	orig := Current()
+	t.Cleanup(func() {
+		viper.Reset()
+		SetCurrentConfig(orig)
+	})
+
+	// The local override is looked up in the working directory.
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.test.yaml"), []byte(`
+redis:
+  addrs: [127.0.0.1:6379]
+  ttl: 60
+response:
+  max: 50
+chain_names:
+  ETH: 1
+ankr:
+  url: https://rpc.ankr.com/multichain
+providers:
+  chain_providers:
+    ETH: ankr
+`), 0o600))
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
 
 	// Set env variables to simulate local only config
 	_ = os.Setenv("APP_ENV", "test")
@@ -45,12 +88,204 @@ func TestInit_WithLocalFileOnly(t *testing.T) {
 	// ✅ Assert that server.port picked from env APP_PORT
 	assert.Equal(t, 9090, cfg.Server.Port, "server.port should match APP_PORT env")
 
-	// ✅ Assert Redis TTL fallback to zero if not present
-	assert.Zero(t, cfg.Redis.TTLSeconds, "Redis.TTLSeconds should be zero if not configured")
+	// ✅ Assert the local override was applied
+	assert.Equal(t, 60, cfg.Redis.TTLSeconds)
+	assert.Equal(t, int64(50), cfg.Response.Max)
 
 	// ✅ Assert log level fallback to zero if not present
 	assert.Zero(t, cfg.Log.Level, "Log.Level should be zero if not configured")
+}
+
+func TestMergeOverride(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	file, err := mergeOverride(viper.New(), "test")
+	assert.NoError(t, err)
+	assert.Empty(t, file, "a missing file is skipped")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.test.yaml"), []byte("redis:\n  ttl: 60\nresponse:\n  max: 50\n"), 0o600))
+	v := viper.New()
+	file, err = mergeOverride(v, "test")
+	assert.NoError(t, err)
+	assert.Contains(t, file, "config.test.yaml")
+	// KV layers are merged after the file and win over it.
+	assert.NoError(t, v.MergeConfigMap(map[string]interface{}{"redis": map[string]interface{}{"ttl": 120}}))
+	assert.Equal(t, 120, v.GetInt("redis.ttl"))
+	assert.Equal(t, 50, v.GetInt("response.max"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.test.yaml"), []byte("redis: [unclosed\n"), 0o600))
+	_, err = mergeOverride(viper.New(), "test")
+	assert.Error(t, err, "a file that does not parse is not ignored")
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, validConfig().Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(c *types.Config)
+		want   string
+	}{
+		{"no redis address", func(c *types.Config) { c.Redis.Addrs = nil }, "redis.addrs"},
+		{"zero TTL", func(c *types.Config) { c.Redis.TTLSeconds = 0 }, "redis.ttl"},
+		{"zero response max", func(c *types.Config) { c.Response.Max = 0 }, "response.max"},
+		{"invalid port", func(c *types.Config) { c.Server.Port = 70000 }, "server.port"},
+		{"unknown chain in chain_providers", func(c *types.Config) { c.Providers.ChainProviders["pol"] = []string{"ankr"} }, "chain_providers.pol: chain is not in chain_names"},
+		{"unconfigured provider", func(c *types.Config) { c.Providers.ChainProviders["bsc"] = []string{"blockscan_bsc"} }, "provider blockscan_bsc is not configured"},
+		{"missing ankr URL", func(c *types.Config) { c.Ankr.URL = "" }, "ankr.url"},
+		{"provider without URL", func(c *types.Config) { c.Blockscout[0].URL = "" }, "blockscout_eth: url is required"},
+		{"relative provider URL", func(c *types.Config) { c.Blockscout[0].URL = "eth.blockscout.com" }, "not an absolute URL"},
+		{"provider for unknown chain", func(c *types.Config) {
+			c.Tron = []types.TronConfig{{ChainName: "TRON"}}
+		}, "tron_tron: chain TRON is not in chain_names"},
+		{"unknown address family", func(c *types.Config) {
+			c.AddressFamilies = map[string]string{"bsc": "cosmos"}
+		}, `address_families.bsc: unknown address family "cosmos"`},
+		{"address family for unknown chain", func(c *types.Config) {
+			c.AddressFamilies = map[string]string{"sui": types.AddressFamilyAptos}
+		}, "address_families.sui: chain is not in chain_names"},
+		{"relative RPC URL", func(c *types.Config) {
+			c.RPCURLs = map[string]string{"eth": "localhost:8545"}
+		}, `rpc_urls.eth: url "localhost:8545" is not an absolute URL`},
+		{"invalid wrapped-native address", func(c *types.Config) {
+			c.WrappedNative = map[string]string{"eth": "weth"}
+		}, `wrapped_native.eth: "weth" is not a contract address`},
+		{"invalid swap topic", func(c *types.Config) {
+			c.SwapEvents = []types.SwapEventConfig{{Protocol: "curve", Topic: "0x1234"}}
+		}, `swap_events[0]: topic "0x1234" is not an event signature hash`},
+		{"bridge to unknown chain", func(c *types.Config) {
+			c.Bridges = []types.BridgeConfig{{Name: "optimism", ChainName: "eth", CounterpartChain: "op",
+				Contracts: []string{"0x99c9fc46f92e8a1c0dec1b1747d010903e884be1"}}}
+		}, `bridges.optimism: chain "op" is not in chain_names`},
+		{"relative token list URL", func(c *types.Config) {
+			c.TokenLists.Sources = []string{"https://"}
+		}, `token_lists.sources: "https://" is not an absolute URL`},
+		{"token both allowed and denied", func(c *types.Config) {
+			c.TokenFilter = types.TokenFilterConfig{
+				Allow: []string{"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+				Deny:  []string{"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+			}
+		}, `token_filter: 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48 is both allowed and denied`},
+		{"dust threshold in scientific notation", func(c *types.Config) {
+			c.DustThresholds = []types.DustThresholdConfig{{ChainName: "eth", Token: types.NativeTokenName, Min: "1e-6"}}
+		}, `dust_thresholds.eth: min "1e-6" is not a decimal amount`},
+		{"dust threshold token of another address family", func(c *types.Config) {
+			c.ChainNames["tron"] = 728126428
+			c.AddressFamilies = map[string]string{"tron": types.AddressFamilyTron}
+			c.DustThresholds = []types.DustThresholdConfig{
+				{ChainName: "tron", Token: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Min: "0.01"},
+				{ChainName: "tron", Token: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Min: "0.01"},
+			}
+		}, `dust_thresholds.tron: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" is not a tron token address`},
+		{"unknown trace method", func(c *types.Config) {
+			c.InternalTraces = []types.InternalTraceConfig{{ChainName: "ETH", Method: "trace_transaction"}}
+		}, `internal_traces.ETH: unknown method "trace_transaction"`},
+		{"trace of unknown chain", func(c *types.Config) {
+			c.InternalTraces = []types.InternalTraceConfig{{ChainName: "SUI"}}
+		}, `internal_traces: chain "SUI" is not in chain_names`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(&cfg)
+			err := cfg.Validate()
+			var cfgErr *types.ConfigError
+			if assert.ErrorAs(t, err, &cfgErr) {
+				assert.Len(t, cfgErr.Problems, 1)
+				assert.Contains(t, err.Error(), tt.want)
+			}
+		})
+	}
+
+	// Every problem is reported at once.
+	err := types.Config{}.Validate()
+	var cfgErr *types.ConfigError
+	if assert.ErrorAs(t, err, &cfgErr) {
+		assert.Len(t, cfgErr.Problems, 5)
+	}
+}
+
+func TestPublish_RejectsInvalidConfig(t *testing.T) {
+	orig := Current()
+	t.Cleanup(func() { SetCurrentConfig(orig) })
+
+	changed, err := publish(validConfig())
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	gen := Generation()
+
+	bad := validConfig()
+	bad.Response.Max = 0
+	changed, err = publish(bad)
+	assert.Error(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, gen, Generation(), "a rejected reload must not be published")
+	assert.Equal(t, int64(50), Current().Response.Max, "the previous configuration stays in place")
+}
+
+func TestInitFromFile(t *testing.T) {
+	orig := Current()
+	t.Cleanup(func() { SetCurrentConfig(orig) })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+redis:
+  addrs: [127.0.0.1:6379]
+  password: s3cret
+  ttl: 60
+response:
+  max: 50
+chain_names:
+  ETH: 1
+`), 0o600))
+
+	InitFromFile(path)
+	cfg := Current()
+	assert.Equal(t, 8080, cfg.Server.Port, "server.port defaults as with Consul")
+	assert.Equal(t, []string{"127.0.0.1:6379"}, cfg.Redis.Addrs)
+	assert.Equal(t, 60, cfg.Redis.TTLSeconds)
+	assert.Equal(t, int64(1), cfg.ChainNames["eth"])
+
+	eff := Effective()
+	assert.Equal(t, []string{path}, eff.Layers)
+	assert.Equal(t, redactedValue, eff.Settings["redis"].(map[string]interface{})["password"])
+
+	_, _, err := loadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestReload_StandaloneFile(t *testing.T) {
+	orig := Current()
+	t.Cleanup(func() { SetCurrentConfig(orig) })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(ttl, max int) {
+		assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`
+redis:
+  addrs: [127.0.0.1:6379]
+  ttl: %d
+response:
+  max: %d
+chain_names:
+  ETH: 1
+`, ttl, max)), 0o600))
+	}
+	write(60, 50)
+	InitFromFile(path)
+
+	write(90, 50)
+	assert.NoError(t, Reload())
+	assert.Equal(t, 90, Current().Redis.TTLSeconds, "the file is re-read on demand")
+
+	gen := Generation()
+	assert.NoError(t, Reload())
+	assert.Equal(t, gen, Generation(), "an unchanged file is not republished")
 
-	// ✅ Assert response max fallback to zero if not present
-	assert.Zero(t, cfg.Response.Max, "Response.Max should be zero if not configured")
+	write(120, 0)
+	assert.Error(t, Reload())
+	assert.Equal(t, 90, Current().Redis.TTLSeconds, "an invalid file keeps the previous configuration")
 }
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"tx-aggregator/types"
+)
+
+// EnvPrefix prefixes the environment variables overriding configuration
+// keys: TXAGG_REDIS_TTL overrides redis.ttl and TXAGG_SERVER_TLS_CERT_FILE
+// overrides server.tls.cert_file. Environment variables win over every
+// file and KV layer.
+const EnvPrefix = "TXAGG"
+
+// legacyEnv lists environment variables supported before EnvPrefix, which
+// are still honoured after the prefixed ones.
+var legacyEnv = map[string][]string{
+	"server.port": {"APP_PORT"},
+}
+
+// envKeys lists every configuration key that can be overridden, from the
+// mapstructure tags of types.Config.
+var envKeys = configKeys(reflect.TypeOf(types.Config{}), "")
+
+// bindEnv binds every configuration key of v to its environment variable.
+func bindEnv(v *viper.Viper) {
+	for _, key := range envKeys {
+		_ = v.BindEnv(append([]string{key, EnvName(key)}, legacyEnv[key]...)...)
+	}
+}
+
+// EnvName returns the environment variable overriding key.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// configKeys returns the dotted keys of the fields of t, descending into
+// nested structs. Maps and lists are keys of their own: their variables hold
+// the whole value as JSON or YAML (see envValueHook).
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if f.Type.Kind() == reflect.Struct {
+			keys = append(keys, configKeys(f.Type, prefix+name+".")...)
+			continue
+		}
+		keys = append(keys, prefix+name)
+	}
+	return keys
+}
+
+// unmarshal decodes the settings of v into cfg, accepting JSON or YAML
+// strings from environment variables for maps and lists.
+func unmarshal(v *viper.Viper, cfg *types.Config) error {
+	return v.Unmarshal(cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		envValueHook,
+		// viper's default hooks
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+}
+
+// envValueHook parses a string holding a JSON or YAML map or list when the
+// target is a map or a slice, such as TXAGG_CHAIN_NAMES='{"ETH": 1}'. Map
+// keys are lowercased, as viper does for keys read from files. Other strings
+// are left to the following hooks, so TXAGG_REDIS_ADDRS=a:6379,b:6379 still
+// works.
+func envValueHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || (to.Kind() != reflect.Map && to.Kind() != reflect.Slice) {
+		return data, nil
+	}
+	s := strings.TrimSpace(data.(string))
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return data, nil
+	}
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(s), &parsed); err != nil {
+		return nil, err
+	}
+	return lowerKeys(parsed), nil
+}
+
+// lowerKeys lowercases the keys of every map in v.
+func lowerKeys(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[strings.ToLower(k)] = lowerKeys(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range val {
+			val[i] = lowerKeys(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "TXAGG_REDIS_TTL", EnvName("redis.ttl"))
+	assert.Equal(t, "TXAGG_SERVER_TLS_CERT_FILE", EnvName("server.tls.cert_file"))
+	assert.Contains(t, envKeys, "providers.retry.max_retries")
+	assert.Contains(t, envKeys, "chain_names")
+	assert.NotContains(t, envKeys, "providers", "structs are descended into")
+}
+
+func TestLoadFile_EnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+server:
+  port: 8080
+redis:
+  addrs: [127.0.0.1:6379]
+  ttl: 60
+response:
+  max: 50
+chain_names:
+  ETH: 1
+providers:
+  chain_providers:
+    ETH: ankr
+`), 0o600))
+
+	t.Setenv("TXAGG_REDIS_TTL", "120")
+	t.Setenv("TXAGG_REDIS_ADDRS", "redis-a:6379,redis-b:6379")
+	t.Setenv("TXAGG_SERVER_TLS_ENABLED", "true")
+	t.Setenv("TXAGG_SERVER_PORT", "9443")
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("TXAGG_CHAIN_NAMES", `{"ETH": 1, "BSC": 56}`)
+	t.Setenv("TXAGG_PROVIDERS_CHAIN_PROVIDERS", `{"ETH": ["ankr", "blockscout_eth"], "BSC": "ankr"}`)
+	t.Setenv("TXAGG_BLOCKSCOUT", `[{"url": "https://eth.blockscout.com/api/v2", "chain_name": "ETH"}]`)
+
+	cfg, settings, err := loadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 120, cfg.Redis.TTLSeconds)
+	assert.Equal(t, []string{"redis-a:6379", "redis-b:6379"}, cfg.Redis.Addrs)
+	assert.True(t, cfg.Server.TLS.Enabled)
+	assert.Equal(t, 9443, cfg.Server.Port, "TXAGG_SERVER_PORT wins over APP_PORT")
+	assert.Equal(t, map[string]int64{"eth": 1, "bsc": 56}, cfg.ChainNames)
+	assert.Equal(t, map[string][]string{"eth": {"ankr", "blockscout_eth"}, "bsc": {"ankr"}}, cfg.Providers.ChainProviders)
+	if assert.Len(t, cfg.Blockscout, 1) {
+		assert.Equal(t, "ETH", cfg.Blockscout[0].ChainName)
+	}
+	assert.Equal(t, int64(50), cfg.Response.Max, "keys without a variable keep the file value")
+	assert.Equal(t, "120", settings["redis"].(map[string]interface{})["ttl"])
+
+	t.Setenv("TXAGG_SERVER_PORT", "")
+	cfg, _, err = loadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 9090, cfg.Server.Port, "APP_PORT is still honoured")
+
+	t.Setenv("TXAGG_CHAIN_NAMES", `{"ETH": 1`)
+	_, _, err = loadFile(path)
+	assert.Error(t, err, "a malformed value is reported")
+}
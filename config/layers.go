@@ -0,0 +1,249 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/spf13/viper"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+// effective holds the merged settings and the layers that produced them,
+// as served by /admin/config/effective.
+var effective atomic.Value // stores types.EffectiveConfig
+
+// configLayers returns the KV keys merged into the runtime config,
+// lowest precedence first:
+//
+//	config/<service>/base             shared by every environment (optional)
+//	config/<service>/<env>            environment overlay (required)
+//	config/<service>/<env>/<region>   region overlay (optional, only when region is set)
+func configLayers(service, env, region string) []string {
+	base := fmt.Sprintf("config/%s", service)
+	layers := []string{base + "/base", fmt.Sprintf("%s/%s", base, env)}
+	if region != "" {
+		layers = append(layers, fmt.Sprintf("%s/%s/%s", base, env, region))
+	}
+	return layers
+}
+
+// layerPrefix is the KV prefix holding every layer of service, watched for
+// changes. It also covers the layers of other environments, whose changes
+// cause a harmless re-read.
+func layerPrefix(service string) string {
+	return fmt.Sprintf("config/%s/", service)
+}
+
+// mergeLayers reads each layer from src and deep-merges it into v in
+// order, so later layers win key by key while lists are replaced wholesale.
+// Only the required layer must exist; missing optional layers are skipped.
+// It returns the keys that were actually applied.
+func mergeLayers(v *viper.Viper, src remoteSource, layers []string, required string) ([]string, error) {
+	var applied []string
+	for _, key := range layers {
+		layer := viper.New()
+		layer.SetConfigType("yaml")
+		if err := layer.AddRemoteProvider(src.provider, src.endpoint, key); err != nil {
+			return nil, fmt.Errorf("%s provider init for %s: %w", src.provider, key, err)
+		}
+		if err := layer.ReadRemoteConfig(); err != nil {
+			if key == required {
+				return nil, fmt.Errorf("read %s: %w", key, err)
+			}
+			logger.Log.Debug().Err(err).Str("key", key).Msg("optional config layer not found, skipping")
+			continue
+		}
+		if err := v.MergeConfigMap(layer.AllSettings()); err != nil {
+			return nil, fmt.Errorf("merge %s: %w", key, err)
+		}
+		applied = append(applied, key)
+	}
+	return applied, nil
+}
+
+// storeEffective records the settings behind the published config.
+func storeEffective(settings map[string]interface{}, layers []string) {
+	effective.Store(types.EffectiveConfig{Layers: layers, Settings: settings})
+}
+
+// Effective returns the merged settings with secrets redacted. When a chain
+// registry is active its values replace the chain settings, as in Current().
+func Effective() types.EffectiveConfig {
+	eff, _ := effective.Load().(types.EffectiveConfig)
+	out := types.EffectiveConfig{
+		Layers:   append([]string(nil), eff.Layers...),
+		Settings: redact(eff.Settings).(map[string]interface{}),
+	}
+
+	if reg := CurrentChainRegistry(); reg != nil {
+		out.Settings["chain_names"] = reg.ChainNames
+		out.Settings["native_tokens"] = reg.NativeTokens
+		out.Settings["explorer_urls"] = reg.ExplorerURLs
+		if ankr, ok := out.Settings["ankr"].(map[string]interface{}); ok {
+			ankr["chain_ids"] = reg.AnkrChainIDs
+		}
+		out.Layers = append(out.Layers, "chain registry")
+	}
+	return out
+}
+
+// redactedValue replaces secret settings in the effective view.
+const redactedValue = "********"
+
+// redact returns a deep copy of v with secret-looking keys masked, and the
+// credentials inside URL settings masked (see RedactURL).
+func redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if IsSecretKey(k) {
+				if s, ok := val.(string); ok && s == "" {
+					out[k] = ""
+				} else {
+					out[k] = redactedValue
+				}
+				continue
+			}
+			if s, ok := val.(string); ok && isURLKey(k) && s != "" {
+				if r, err := RedactURL(s); err == nil {
+					out[k] = r
+				} else {
+					out[k] = redactedValue
+				}
+				continue
+			}
+			out[k] = redact(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = redact(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// nonSecretKeys are settings whose names match a secret pattern but which
+// hold limits or locations rather than credentials.
+var nonSecretKeys = map[string]bool{
+	"max_per_key":        true,
+	"chain_registry_key": true,
+}
+
+// IsSecretKey reports whether a settings key (or URL query parameter) holds
+// a credential. Webhook URLs count as credentials, since anyone who knows
+// one can post to it. camelCase and kebab-case keys, as in query strings
+// ("webhookUrl", "api-key"), are matched like their snake_case form.
+func IsSecretKey(key string) bool {
+	key = snakeCase(key)
+	switch key {
+	case "key", "password", "token", "secret", "apikey", "dsn", "webhook_url":
+		return true
+	}
+	if nonSecretKeys[key] {
+		return false
+	}
+	return strings.HasSuffix(key, "_key") ||
+		strings.HasSuffix(key, "_password") ||
+		strings.HasSuffix(key, "_token") ||
+		strings.HasSuffix(key, "_secret") ||
+		strings.HasSuffix(key, "_webhook_url")
+}
+
+// snakeCase lower-cases key and turns its camelCase word boundaries and
+// dashes into underscores: "webhookUrl" and "APIKey" become "webhook_url"
+// and "api_key".
+func snakeCase(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if r == '-' {
+			r = '_'
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// isURLKey reports whether a settings key holds a URL.
+func isURLKey(key string) bool {
+	key = strings.ToLower(key)
+	return key == "url" || strings.HasSuffix(key, "_url")
+}
+
+// RedactURL masks the credentials a URL may carry: the userinfo password,
+// secret query parameters (see IsSecretKey) and path segments that look like
+// API keys, as in Alchemy or Infura endpoints ("/v2/<key>").
+func RedactURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redactedValue)
+		} else {
+			u.User = url.User(redactedValue)
+		}
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if IsSecretKey(k) {
+				q.Set(k, redactedValue)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	segments := strings.Split(u.Path, "/")
+	masked := false
+	for i, seg := range segments {
+		if looksLikeAPIKey(seg) {
+			segments[i] = redactedValue
+			masked = true
+		}
+	}
+	if masked {
+		u.Path = strings.Join(segments, "/")
+		u.RawPath = ""
+	}
+	return u.String(), nil
+}
+
+// looksLikeAPIKey reports whether a URL path segment is an opaque token: at
+// least 16 characters of letters, digits, '-' and '_', mixing letters and
+// digits. Words like "block-explorer-api" or "v2" do not qualify.
+func looksLikeAPIKey(seg string) bool {
+	if len(seg) < 16 {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range seg {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letter = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r == '-' || r == '_':
+		default:
+			return false
+		}
+	}
+	return letter && digit
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"sync"
+
+	"tx-aggregator/types"
+)
+
+// subscriber is one callback registered with OnChange.
+type subscriber struct {
+	id int
+	fn func(old, new types.Config)
+}
+
+var (
+	subscribersMu sync.Mutex
+	subscribers   []subscriber
+	nextID        int
+)
+
+// OnChange registers fn to be called with the previous and the new snapshot
+// every time a changed configuration is published, so that subsystems built
+// from it at startup (the Redis pool, the logger) can rebuild what changed.
+// Callbacks run in registration order on the goroutine publishing the
+// snapshot, one snapshot at a time, and must not block for long nor publish
+// themselves. Calling the returned function unregisters fn.
+func OnChange(fn func(old, new types.Config)) (cancel func()) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	nextID++
+	id := nextID
+	subscribers = append(subscribers, subscriber{id: id, fn: fn})
+
+	return func() {
+		subscribersMu.Lock()
+		defer subscribersMu.Unlock()
+		for i, s := range subscribers {
+			if s.id == id {
+				subscribers = append(subscribers[:i:i], subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify calls the registered callbacks. The caller holds publishMu, which
+// keeps notifications in publishing order.
+func notify(old, new types.Config) {
+	subscribersMu.Lock()
+	subs := append([]subscriber(nil), subscribers...)
+	subscribersMu.Unlock()
+
+	for _, s := range subs {
+		s.fn(old, new)
+	}
+}
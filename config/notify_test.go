@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/types"
+)
+
+func TestOnChange(t *testing.T) {
+	orig := Current()
+	t.Cleanup(func() { SetCurrentConfig(orig) })
+	SetCurrentConfig(validConfig())
+
+	type change struct{ old, new int64 }
+	var changes []change
+	cancel := OnChange(func(old, new types.Config) {
+		changes = append(changes, change{old.Response.Max, new.Response.Max})
+	})
+
+	updated := validConfig()
+	updated.Response.Max = 100
+	_, err := publish(updated)
+	assert.NoError(t, err)
+	assert.Equal(t, []change{{50, 100}}, changes)
+
+	_, err = publish(updated)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1, "an unchanged snapshot is not notified")
+
+	updated.Response.Max = 0
+	_, err = publish(updated)
+	assert.Error(t, err)
+	assert.Len(t, changes, 1, "a rejected snapshot is not notified")
+
+	cancel()
+	SetCurrentConfig(validConfig())
+	assert.Len(t, changes, 1, "cancelled callbacks are not called")
+}
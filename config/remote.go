@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// Viper remote providers for the supported KV stores.
+const (
+	providerConsul = "consul"
+	providerEtcd   = "etcd3"
+)
+
+// remoteSource is the KV store the runtime configuration and the chain
+// registry are read from.
+type remoteSource struct {
+	provider string // viper remote provider: providerConsul or providerEtcd
+	endpoint string // store address; etcd endpoints are joined by ";"
+}
+
+// remoteSourceFor selects etcd when the bootstrap file lists etcd
+// endpoints, and Consul KV at consulAddr otherwise.
+func remoteSourceFor(bootstrap *types.BootstrapConfig, consulAddr string) remoteSource {
+	if len(bootstrap.Etcd.Endpoints) > 0 {
+		return remoteSource{provider: providerEtcd, endpoint: strings.Join(bootstrap.Etcd.Endpoints, ";")}
+	}
+	return remoteSource{provider: providerConsul, endpoint: consulAddr}
+}
+
+// enabled reports whether there is a store to read from.
+func (s remoteSource) enabled() bool {
+	return s.endpoint != ""
+}
+
+// String names the store in logs.
+func (s remoteSource) String() string {
+	if s.provider == providerEtcd {
+		return "etcd"
+	}
+	return "Consul KV"
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+func TestRemoteSourceFor(t *testing.T) {
+	bootstrap := &types.BootstrapConfig{Consul: types.ConsulBootstrap{ChainRegistryKey: "consul/chains"}}
+
+	src := remoteSourceFor(bootstrap, "127.0.0.1:8500")
+	assert.Equal(t, remoteSource{provider: providerConsul, endpoint: "127.0.0.1:8500"}, src)
+	assert.Equal(t, "Consul KV", src.String())
+	assert.True(t, src.enabled())
+	assert.Equal(t, "consul/chains", chainRegistryKey(bootstrap, src, "prod"))
+
+	assert.False(t, remoteSourceFor(bootstrap, "").enabled(), "no Consul address and no etcd endpoints")
+
+	bootstrap.Etcd.Endpoints = []string{"http://10.0.0.1:2379", "http://10.0.0.2:2379"}
+	src = remoteSourceFor(bootstrap, "127.0.0.1:8500")
+	assert.Equal(t, remoteSource{provider: providerEtcd, endpoint: "http://10.0.0.1:2379;http://10.0.0.2:2379"}, src, "etcd wins over Consul")
+	assert.Equal(t, "etcd", src.String())
+	assert.Equal(t, "config/chains/prod", chainRegistryKey(bootstrap, src, "prod"), "the Consul key does not apply to etcd")
+
+	bootstrap.Etcd.ChainRegistryKey = "etcd/chains"
+	assert.Equal(t, "etcd/chains", chainRegistryKey(bootstrap, src, "prod"))
+}
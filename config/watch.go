@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"tx-aggregator/logger"
+)
+
+const (
+	// pollInterval paces refreshes from stores without blocking queries (etcd).
+	pollInterval = 10 * time.Second
+	// blockingWait is how long Consul holds a blocking query open when
+	// nothing changes.
+	blockingWait = 5 * time.Minute
+	// watchRetryDelay spaces blocking queries after a failed one.
+	watchRetryDelay = 5 * time.Second
+)
+
+// watch calls refresh whenever the keys under prefix may have changed and
+// never returns. Consul is watched with blocking queries, so changes apply
+// as soon as they are written and an idle service costs Consul one open
+// request per watch; etcd is polled every pollInterval.
+func (s remoteSource) watch(prefix string, refresh func()) {
+	if s.provider == providerConsul {
+		w, err := newConsulWatcher(s.endpoint)
+		if err == nil {
+			w.watch(context.Background(), prefix, refresh)
+			return
+		}
+		logger.Log.Warn().Err(err).Str("prefix", prefix).Msg("cannot create Consul watch client – polling instead")
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		refresh()
+	}
+}
+
+// consulWatcher runs Consul KV blocking queries.
+type consulWatcher struct {
+	kv         *api.KV
+	retryDelay time.Duration
+}
+
+// newConsulWatcher creates a watcher for the agent at addr. The ACL token is
+// taken from CONSUL_HTTP_TOKEN, as for the config reads.
+func newConsulWatcher(addr string) (*consulWatcher, error) {
+	conf := api.DefaultConfig()
+	conf.Address = addr
+	client, err := api.NewClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &consulWatcher{kv: client.KV(), retryDelay: watchRetryDelay}, nil
+}
+
+// watch blocks on the KV index of prefix and calls onChange every time it
+// moves, including once for the first answer so that a change made between
+// the initial load and the first query is not missed. It returns when ctx is
+// done.
+func (w *consulWatcher) watch(ctx context.Context, prefix string, onChange func()) {
+	var index uint64
+	for ctx.Err() == nil {
+		opts := (&api.QueryOptions{WaitIndex: index, WaitTime: blockingWait}).WithContext(ctx)
+		_, meta, err := w.kv.Keys(prefix, "", opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Log.Error().Err(err).Str("prefix", prefix).Msg("Consul blocking query failed")
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.retryDelay):
+			}
+			continue
+		}
+
+		next := nextIndex(meta.LastIndex)
+		if next == index {
+			continue // the wait elapsed without a change
+		}
+		index = next
+		onChange()
+	}
+}
+
+// nextIndex returns the index to wait on after a blocking query that
+// returned last, following Consul's guidance: an index of 0, which would not
+// block, is treated as 1. An index that goes backwards (e.g. after a snapshot
+// restore) is waited on as is, after a refresh like any other change.
+func nextIndex(last uint64) uint64 {
+	if last == 0 {
+		return 1
+	}
+	return last
+}
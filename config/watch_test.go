@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBlockingKV answers Consul KV keys queries like a blocking query: a
+// request for the current index waits until the index moves or a short wait
+// elapses.
+type fakeBlockingKV struct {
+	index    atomic.Uint64
+	failNext atomic.Bool
+	changed  chan struct{}
+}
+
+func (f *fakeBlockingKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.failNext.Swap(false) {
+		http.Error(w, "no cluster leader", http.StatusInternalServerError)
+		return
+	}
+	waitIndex, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	if waitIndex != 0 && waitIndex == f.index.Load() {
+		select {
+		case <-f.changed:
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index.Load(), 10))
+	_, _ = w.Write([]byte(`["config/tx-aggregator/base"]`))
+}
+
+func (f *fakeBlockingKV) set(index uint64) {
+	f.index.Store(index)
+	select {
+	case f.changed <- struct{}{}:
+	default:
+	}
+}
+
+func TestConsulWatcher(t *testing.T) {
+	fake := &fakeBlockingKV{changed: make(chan struct{}, 1)}
+	fake.index.Store(10)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	w, err := newConsulWatcher(server.Listener.Addr().String())
+	assert.NoError(t, err)
+	w.retryDelay = 10 * time.Millisecond
+
+	calls := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	done := make(chan struct{})
+	go func() {
+		w.watch(ctx, layerPrefix("tx-aggregator"), func() { calls <- struct{}{} })
+		close(done)
+	}()
+
+	expectCall := func(msg string) {
+		t.Helper()
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal(msg)
+		}
+	}
+	expectNoCall := func(msg string) {
+		t.Helper()
+		select {
+		case <-calls:
+			t.Fatal(msg)
+		case <-time.After(150 * time.Millisecond):
+		}
+	}
+
+	expectCall("the first answer triggers a refresh")
+	expectNoCall("an elapsed wait is not a change")
+
+	fake.set(11)
+	expectCall("a new index triggers a refresh")
+
+	fake.failNext.Store(true)
+	fake.set(12)
+	expectCall("a failed query is retried")
+
+	fake.set(3)
+	expectCall("an index going backwards triggers a refresh")
+	expectNoCall("the lower index is then waited on")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch did not return after cancel")
+	}
+}
+
+func TestNextIndex(t *testing.T) {
+	assert.Equal(t, uint64(12), nextIndex(12))
+	assert.Equal(t, uint64(1), nextIndex(0), "0 would not block")
+}
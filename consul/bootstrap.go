@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"tx-aggregator/types"
 
 	"github.com/spf13/viper"
@@ -55,6 +56,9 @@ func LoadBootstrap(path string) (*types.BootstrapConfig, error) {
 	if env := os.Getenv("CONSUL_TOKEN"); env != "" {
 		cfg.Consul.Token = env
 	}
+	if env := os.Getenv("ETCD_ENDPOINTS"); env != "" {
+		cfg.Etcd.Endpoints = strings.Split(env, ",")
+	}
 	if env := os.Getenv("SERVICE_IP"); env != "" {
 		cfg.Service.IP = env
 	}
@@ -77,3 +77,33 @@ func TestLoadBootstrap(t *testing.T) {
 		t.Errorf("Expected token 'default-token', got %s", cfg.Consul.Token)
 	}
 }
+
+func TestLoadBootstrap_Etcd(t *testing.T) {
+	filePath := writeTempBootstrapFile(t, types.BootstrapConfig{
+		Etcd: types.EtcdBootstrap{
+			Endpoints:        []string{"http://10.0.0.1:2379"},
+			ChainRegistryKey: "chains/prod",
+		},
+		Service: types.ServiceBootstrap{Name: "my-service"},
+	})
+
+	cfg, err := consul.LoadBootstrap(filePath)
+	if err != nil {
+		t.Fatalf("LoadBootstrap() failed: %v", err)
+	}
+	if len(cfg.Etcd.Endpoints) != 1 || cfg.Etcd.Endpoints[0] != "http://10.0.0.1:2379" {
+		t.Errorf("Expected etcd endpoints from file, got %v", cfg.Etcd.Endpoints)
+	}
+	if cfg.Etcd.ChainRegistryKey != "chains/prod" {
+		t.Errorf("Expected etcd chain registry key 'chains/prod', got %s", cfg.Etcd.ChainRegistryKey)
+	}
+
+	t.Setenv("ETCD_ENDPOINTS", "http://10.0.0.2:2379,http://10.0.0.3:2379")
+	cfg, err = consul.LoadBootstrap(filePath)
+	if err != nil {
+		t.Fatalf("LoadBootstrap() failed: %v", err)
+	}
+	if len(cfg.Etcd.Endpoints) != 2 || cfg.Etcd.Endpoints[1] != "http://10.0.0.3:2379" {
+		t.Errorf("Expected ETCD_ENDPOINTS override, got %v", cfg.Etcd.Endpoints)
+	}
+}
@@ -0,0 +1,32 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// Pinger checks that Consul can serve reads, for the deep health check.
+type Pinger struct {
+	client *api.Client
+}
+
+// NewPinger creates a Pinger using client.
+func NewPinger(client *api.Client) *Pinger {
+	return &Pinger{client: client}
+}
+
+// Ping asks the agent for the current cluster leader. It fails when the agent
+// is unreachable or the cluster has no leader, in which case KV reads fail too.
+func (p *Pinger) Ping(ctx context.Context) error {
+	leader, err := p.client.Status().LeaderWithQueryOptions((&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("consul status: %w", err)
+	}
+	if leader == "" {
+		return errors.New("consul cluster has no leader")
+	}
+	return nil
+}
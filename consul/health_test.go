@@ -0,0 +1,45 @@
+package consul_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tx-aggregator/consul"
+
+	"github.com/hashicorp/consul/api"
+)
+
+func TestPinger(t *testing.T) {
+	leader := `"10.0.0.1:8300"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/status/leader" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(leader))
+	}))
+	defer server.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = server.Listener.Addr().String()
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create Consul client: %v", err)
+	}
+	pinger := consul.NewPinger(client)
+
+	if err := pinger.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() with a leader failed: %v", err)
+	}
+
+	leader = `""`
+	if err := pinger.Ping(context.Background()); err == nil {
+		t.Error("Ping() without a leader should fail")
+	}
+
+	server.Close()
+	if err := pinger.Ping(context.Background()); err == nil {
+		t.Error("Ping() of an unreachable agent should fail")
+	}
+}
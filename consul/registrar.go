@@ -21,6 +21,11 @@ func Register(client *api.Client, opt types.Options) (func() error, error) {
 		opt.Deregister = 5 * time.Minute
 	}
 
+	scheme := "http"
+	if opt.TLS {
+		scheme = "https"
+	}
+
 	reg := &api.AgentServiceRegistration{
 		ID:      opt.ID,
 		Name:    opt.Name,
@@ -29,7 +34,9 @@ func Register(client *api.Client, opt types.Options) (func() error, error) {
 		Address: opt.Address,
 		Meta:    opt.Meta,
 		Check: &api.AgentServiceCheck{
-			HTTP:                           fmt.Sprintf("http://%s:%d%s", opt.Address, opt.Port, opt.HealthPath),
+			HTTP: fmt.Sprintf("%s://%s:%d%s", scheme, opt.Address, opt.Port, opt.HealthPath),
+			// The check dials the service IP, which the certificate does not name.
+			TLSSkipVerify:                  opt.TLS,
 			Interval:                       opt.Interval.String(),
 			Timeout:                        opt.Timeout.String(),
 			DeregisterCriticalServiceAfter: opt.Deregister.String(),
@@ -0,0 +1,177 @@
+// Package errorreport forwards error-level log events to Sentry. It is a
+// logger sink: every event logged at error level or above becomes a Sentry
+// event carrying the log fields, grouped by message, provider and chain.
+// Recovered panics reach it the same way (see middleware.Recover).
+package errorreport
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fatalFlushTimeout bounds how long a fatal event may delay the exit that
+// follows it.
+const fatalFlushTimeout = 2 * time.Second
+
+// tagFields are log fields promoted to Sentry tags, so that issues can be
+// searched by them. The first name found in an event wins per tag.
+var tagFields = map[string][]string{
+	"provider":   {"provider", "provider_key"},
+	"chain":      {"chain", "chain_name"},
+	"label":      {"label"},
+	"request_id": {logger.RequestIDField},
+	"path":       {"path"},
+}
+
+// omittedFields carry nothing Sentry does not already record.
+var omittedFields = map[string]bool{
+	zerolog.LevelFieldName:     true,
+	zerolog.MessageFieldName:   true,
+	zerolog.TimestampFieldName: true,
+}
+
+// Reporter is a zerolog.LevelWriter sending error-level events to Sentry.
+// It follows the sentry section of the live configuration: the client is
+// rebuilt whenever that section changes.
+type Reporter struct {
+	mu      sync.Mutex
+	applied types.SentryConfig
+	hub     *sentry.Hub // nil while disabled
+
+	transport sentry.Transport // nil uses the HTTP transport; replaced in tests
+}
+
+var _ zerolog.LevelWriter = (*Reporter)(nil)
+
+// NewReporter creates a Reporter. Install it with logger.AddSink.
+func NewReporter() *Reporter {
+	return &Reporter{}
+}
+
+// Write implements io.Writer; events without a level are not reported.
+func (r *Reporter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel reports p, a JSON log event, when level is error or above.
+// It never fails, so that logging is not affected by Sentry.
+func (r *Reporter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.ErrorLevel || level == zerolog.NoLevel || level == zerolog.Disabled {
+		return len(p), nil
+	}
+	hub := r.currentHub()
+	if hub == nil {
+		return len(p), nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return len(p), nil
+	}
+	hub.CaptureEvent(buildEvent(level, fields))
+	if level >= zerolog.FatalLevel {
+		// Fatal exits the process right after logging.
+		hub.Flush(fatalFlushTimeout)
+	}
+	return len(p), nil
+}
+
+// Flush waits up to timeout for queued events to be sent.
+func (r *Reporter) Flush(timeout time.Duration) {
+	if hub := r.currentHub(); hub != nil {
+		hub.Flush(timeout)
+	}
+}
+
+// currentHub returns the hub for the live configuration, rebuilding it when
+// the sentry section changed. It must not log at error level, which would
+// re-enter the Reporter.
+func (r *Reporter) currentHub() *sentry.Hub {
+	cfg := config.Current().Sentry
+	if !cfg.Enabled || cfg.DSN == "" {
+		cfg = types.SentryConfig{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cfg == r.applied {
+		return r.hub
+	}
+	if r.hub != nil {
+		r.hub.Flush(time.Second)
+	}
+	r.applied, r.hub = cfg, nil
+	if cfg.DSN == "" {
+		return nil
+	}
+
+	environment := cfg.Environment
+	if environment == "" {
+		environment = os.Getenv("APP_ENV")
+	}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: environment,
+		Release:     cfg.Release,
+		SampleRate:  cfg.SampleRate,
+		Transport:   r.transport,
+	})
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Invalid Sentry configuration, error reporting disabled")
+		return nil
+	}
+	r.hub = sentry.NewHub(client, sentry.NewScope())
+	logger.Log.Info().Str("environment", environment).Msg("Sentry error reporting enabled")
+	return r.hub
+}
+
+// buildEvent turns the fields of a log event into a Sentry event. Events are
+// fingerprinted by message, provider and chain, so that one failing provider
+// on one chain is one issue whatever its error text.
+func buildEvent(level zerolog.Level, fields map[string]interface{}) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	if level >= zerolog.FatalLevel {
+		event.Level = sentry.LevelFatal
+	}
+	event.Logger = "zerolog"
+
+	msg, _ := fields[zerolog.MessageFieldName].(string)
+	event.Message = msg
+	if errText, ok := fields[zerolog.ErrorFieldName].(string); ok && errText != "" {
+		event.Message = msg + ": " + errText
+	}
+
+	for tag, names := range tagFields {
+		for _, name := range names {
+			if v, ok := fields[name].(string); ok && v != "" {
+				event.Tags[tag] = v
+				break
+			}
+		}
+	}
+	event.Fingerprint = []string{msg, event.Tags["provider"], event.Tags["chain"]}
+
+	for k, v := range fields {
+		if omittedFields[k] {
+			continue
+		}
+		if k == "url" {
+			if s, ok := v.(string); ok {
+				v = utils.RedactURL(s)
+			}
+		}
+		event.Extra[k] = v
+	}
+	return event
+}
@@ -0,0 +1,83 @@
+package errorreport
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// recordingTransport keeps the events it is asked to send.
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (t *recordingTransport) Close()                                {}
+func (t *recordingTransport) SendEvent(e *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, e)
+}
+
+func (t *recordingTransport) sent() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+func setupSentryConfig(t *testing.T, cfg types.SentryConfig) {
+	orig := config.Current()
+	c := orig
+	c.Sentry = cfg
+	config.SetCurrentConfig(c)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestReporter(t *testing.T) {
+	transport := &recordingTransport{}
+	r := NewReporter()
+	r.transport = transport
+	log := zerolog.New(zerolog.MultiLevelWriter(r))
+
+	log.Error().Str("provider", "blockscan_eth").Msg("disabled")
+	assert.Empty(t, transport.sent(), "nothing is sent while disabled")
+
+	setupSentryConfig(t, types.SentryConfig{Enabled: true, DSN: "https://public@sentry.example.com/1", Environment: "test"})
+
+	log.Warn().Msg("below error level")
+	log.Error().
+		Str("provider", "blockscan_eth").
+		Str("chain_name", "ETH").
+		Str("url", "https://api.example.com/api?apikey=s3cret").
+		Str("error", "non-200 response for blockscan.normalTx: 503").
+		Msg("Non-200 HTTP status")
+
+	sent := transport.sent()
+	if assert.Len(t, sent, 1) {
+		e := sent[0]
+		assert.Equal(t, sentry.LevelError, e.Level)
+		assert.Equal(t, "Non-200 HTTP status: non-200 response for blockscan.normalTx: 503", e.Message)
+		assert.Equal(t, []string{"Non-200 HTTP status", "blockscan_eth", "ETH"}, e.Fingerprint)
+		assert.Equal(t, "blockscan_eth", e.Tags["provider"])
+		assert.Equal(t, "ETH", e.Tags["chain"])
+		assert.Equal(t, "test", e.Environment)
+		assert.Equal(t, "https://api.example.com/api?apikey=%2A%2A%2A%2A%2A%2A%2A%2A", e.Extra["url"])
+		assert.NotContains(t, e.Extra, "message")
+	}
+
+	// Turning reporting off in the configuration takes effect immediately.
+	setupSentryConfig(t, types.SentryConfig{})
+	log.Error().Msg("disabled again")
+	assert.Len(t, transport.sent(), 1)
+}
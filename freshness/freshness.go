@@ -0,0 +1,92 @@
+// Package freshness records how far each chain's indexer trails the chain
+// head, as observed by the providers that can tell (Blockscout against its
+// RPC node, Ankr against its blockchain stats). The lag is returned to
+// clients so that very recent transactions missing from a response can be
+// explained.
+package freshness
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"tx-aggregator/utils"
+)
+
+const (
+	// probeInterval is the minimum time between two probes of one source.
+	probeInterval = 30 * time.Second
+	// maxAge is how long an observation is reported before it is considered
+	// stale and left out.
+	maxAge = 5 * time.Minute
+)
+
+type observation struct {
+	lag int64
+	at  time.Time
+}
+
+var (
+	mu     sync.Mutex
+	lags   = make(map[int64]observation) // chain ID → latest observation
+	probes = make(map[string]time.Time)  // source key → last probe
+	now    = time.Now
+)
+
+// Record stores the lag of chainID's indexer given the chain head and the
+// newest indexed block. A node behind the indexer counts as no lag. When
+// several sources serve one chain, the latest observation wins.
+func Record(chainID, headBlock, indexedBlock int64) {
+	lag := headBlock - indexedBlock
+	if lag < 0 {
+		lag = 0
+	}
+	mu.Lock()
+	lags[chainID] = observation{lag: lag, at: now()}
+	mu.Unlock()
+}
+
+// ShouldProbe reports whether the source identified by key has not been
+// probed within probeInterval, and if so marks it as probed now.
+func ShouldProbe(key string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	t := now()
+	if last, ok := probes[key]; ok && t.Sub(last) < probeInterval {
+		return false
+	}
+	probes[key] = t
+	return true
+}
+
+// Lags returns the recent indexer lag, in blocks, of every chain in
+// chainNames that has one, keyed by uppercase chain name. It returns nil when
+// none is known.
+func Lags(chainNames []string) map[string]int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	var out map[string]int64
+	for _, name := range chainNames {
+		id, err := utils.ChainIDByName(name)
+		if err != nil {
+			continue
+		}
+		o, ok := lags[id]
+		if !ok || now().Sub(o.at) > maxAge {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]int64)
+		}
+		out[strings.ToUpper(name)] = o.lag
+	}
+	return out
+}
+
+// reset clears all observations; used by tests.
+func reset() {
+	mu.Lock()
+	lags = make(map[int64]observation)
+	probes = make(map[string]time.Time)
+	mu.Unlock()
+}
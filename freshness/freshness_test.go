@@ -0,0 +1,46 @@
+package freshness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+)
+
+func TestLags(t *testing.T) {
+	orig := config.Current()
+	defer config.SetCurrentConfig(orig)
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56, "POL": 137}
+	config.SetCurrentConfig(cfg)
+
+	clock := time.Unix(1_700_000_000, 0)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+	reset()
+
+	assert.Nil(t, Lags([]string{"eth"}))
+
+	Record(1, 100, 97)
+	Record(56, 100, 105) // node behind the indexer
+	assert.Equal(t, map[string]int64{"ETH": 3, "BSC": 0}, Lags([]string{"eth", "bsc", "pol", "unknown"}))
+
+	clock = clock.Add(maxAge + time.Second)
+	Record(1, 120, 118) // latest observation wins, BSC is stale
+	assert.Equal(t, map[string]int64{"ETH": 2}, Lags([]string{"ETH", "BSC"}))
+}
+
+func TestShouldProbe(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+	reset()
+
+	assert.True(t, ShouldProbe("blockscout_ttx"))
+	assert.False(t, ShouldProbe("blockscout_ttx"))
+	assert.True(t, ShouldProbe("ankr"))
+
+	clock = clock.Add(probeInterval)
+	assert.True(t, ShouldProbe("blockscout_ttx"))
+}
@@ -0,0 +1,175 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+const (
+	// defaultDeepTimeoutMs stays below the 1s timeout of the Consul service check.
+	defaultDeepTimeoutMs = 800
+	defaultDeepCacheMs   = 5000
+)
+
+// Pinger is a dependency that can be checked cheaply, such as Redis or Consul.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// DeepChecker checks every dependency needed to serve requests: Redis,
+// Consul and the critical providers listed in health.deep.providers.
+type DeepChecker struct {
+	registry provider.Registry
+	redis    Pinger
+	consul   Pinger // nil when Consul is not used
+
+	mu     sync.Mutex // held during a check, so concurrent requests share it
+	last   types.HealthReport
+	lastAt time.Time
+}
+
+// NewDeepChecker creates a DeepChecker. consul may be nil.
+func NewDeepChecker(registry provider.Registry, redis, consul Pinger) *DeepChecker {
+	return &DeepChecker{registry: registry, redis: redis, consul: consul}
+}
+
+// Check reports the outcome of the component checks, reusing the last report
+// for health.deep.cache_ms so that frequent health requests do not each hit
+// Redis, Consul and the providers.
+func (d *DeepChecker) Check(ctx context.Context) types.HealthReport {
+	cacheMs := config.Current().Health.Deep.CacheMs
+	if cacheMs <= 0 {
+		cacheMs = defaultDeepCacheMs
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.lastAt.IsZero() && now().Sub(d.lastAt) < time.Duration(cacheMs)*time.Millisecond {
+		return d.last
+	}
+	d.last, d.lastAt = d.check(ctx), now()
+	return d.last
+}
+
+// check runs all component checks in parallel, each bounded by
+// health.deep.timeout_ms. Providers are checked directly, regardless of the
+// state kept by the background prober. See types.HealthReport for the
+// status.
+func (d *DeepChecker) check(ctx context.Context) types.HealthReport {
+	cfg := config.Current().Health.Deep
+	timeout := cfg.TimeoutMs
+	if timeout <= 0 {
+		timeout = defaultDeepTimeoutMs
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+
+	checks := map[string]func(context.Context) error{}
+	if d.redis != nil {
+		checks["redis"] = d.redis.Ping
+	}
+	if d.consul != nil {
+		checks["consul"] = d.consul.Ping
+	}
+	providers := d.registry.Providers()
+	var components []types.ComponentHealth
+	for _, key := range cfg.Providers {
+		name := "provider:" + key
+		prov, ok := providers[key]
+		if !ok {
+			components = append(components, types.ComponentHealth{Name: name, Status: types.ComponentFailed, Error: "provider not registered"})
+			continue
+		}
+		checker, ok := prov.(provider.HealthChecker)
+		if !ok {
+			components = append(components, types.ComponentHealth{Name: name, Status: types.ComponentSkipped})
+			continue
+		}
+		checks[name] = func(context.Context) error { return checker.HealthCheck() }
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := runCheck(ctx, name, check)
+			mu.Lock()
+			components = append(components, c)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	report := types.HealthReport{Status: types.ComponentOK, Components: components, CheckedAt: now().Unix()}
+	failed := make(map[string]bool)
+	for _, c := range components {
+		if c.Status == types.ComponentFailed {
+			failed[c.Name] = true
+			report.Status = types.ComponentDegraded
+		}
+	}
+	report.DownChains = downChains(failed)
+	if failed["redis"] || len(report.DownChains) > 0 {
+		report.Status = types.ComponentFailed
+	}
+	return report
+}
+
+// downChains returns the chains of providers.chain_providers all of whose
+// providers failed their check. A provider that was not checked counts as
+// up.
+func downChains(failed map[string]bool) []string {
+	var down []string
+	for chain, keys := range config.Current().Providers.ChainProviders {
+		if len(keys) == 0 {
+			continue
+		}
+		all := true
+		for _, key := range keys {
+			if !failed["provider:"+key] {
+				all = false
+				break
+			}
+		}
+		if all {
+			down = append(down, strings.ToUpper(chain))
+		}
+	}
+	sort.Strings(down)
+	return down
+}
+
+// runCheck runs one check, giving up when ctx is done. Provider health checks
+// do not take a context, so a check that outlives ctx finishes in the
+// background and its result is dropped.
+func runCheck(ctx context.Context, name string, check func(context.Context) error) types.ComponentHealth {
+	start := now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out: %w", ctx.Err())
+	}
+
+	c := types.ComponentHealth{Name: name, Status: types.ComponentOK, LatencyMs: now().Sub(start).Milliseconds()}
+	if err != nil {
+		c.Status = types.ComponentFailed
+		c.Error = err.Error()
+	}
+	return c
+}
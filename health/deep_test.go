@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+// pingFunc adapts a function to Pinger.
+type pingFunc func(ctx context.Context) error
+
+func (f pingFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+// slowProvider answers its health check after delay.
+type slowProvider struct {
+	plainProvider
+	delay time.Duration
+}
+
+func (s slowProvider) HealthCheck() error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func TestDeepChecker(t *testing.T) {
+	config.SetCurrentConfig(types.Config{Health: types.HealthConfig{Deep: types.DeepHealthConfig{
+		Enabled:   true,
+		Providers: []string{"ankr", "tron_tron", "missing"},
+		TimeoutMs: 50,
+	}}})
+	defer config.SetCurrentConfig(types.Config{})
+
+	ok := pingFunc(func(context.Context) error { return nil })
+	registry := provider.StaticRegistry{
+		"ankr":      &checkedProvider{},
+		"tron_tron": plainProvider{},
+	}
+
+	report := NewDeepChecker(registry, ok, ok).Check(context.Background())
+	assert.Equal(t, types.ComponentDegraded, report.Status, "an unregistered provider degrades the check")
+	for i := range report.Components {
+		report.Components[i].LatencyMs = 0
+	}
+	assert.Equal(t, []types.ComponentHealth{
+		{Name: "consul", Status: types.ComponentOK},
+		{Name: "provider:ankr", Status: types.ComponentOK},
+		{Name: "provider:missing", Status: types.ComponentFailed, Error: "provider not registered"},
+		{Name: "provider:tron_tron", Status: types.ComponentSkipped},
+		{Name: "redis", Status: types.ComponentOK},
+	}, report.Components)
+
+	config.SetCurrentConfig(types.Config{Health: types.HealthConfig{Deep: types.DeepHealthConfig{
+		Enabled:   true,
+		Providers: []string{"ankr"},
+		TimeoutMs: 50,
+	}}})
+	registry["ankr"] = slowProvider{delay: time.Second}
+	redisDown := pingFunc(func(context.Context) error { return errors.New("connection refused") })
+
+	report = NewDeepChecker(registry, redisDown, nil).Check(context.Background())
+	assert.Equal(t, types.ComponentFailed, report.Status, "redis down fails the check")
+	if assert.Len(t, report.Components, 2, "consul is not checked without a client") {
+		assert.Equal(t, "provider:ankr", report.Components[0].Name)
+		assert.Contains(t, report.Components[0].Error, "timed out")
+		assert.Equal(t, "connection refused", report.Components[1].Error)
+	}
+
+	registry["ankr"] = &checkedProvider{}
+	report = NewDeepChecker(registry, ok, ok).Check(context.Background())
+	assert.Equal(t, types.ComponentOK, report.Status)
+}
+
+func TestDeepChecker_DownChains(t *testing.T) {
+	config.SetCurrentConfig(types.Config{
+		Health: types.HealthConfig{Deep: types.DeepHealthConfig{Enabled: true, Providers: []string{"a", "b"}}},
+		Providers: types.ProvidersConfig{ChainProviders: map[string][]string{
+			"eth": {"a", "b"},
+			"bsc": {"a", "c"}, // c is not checked
+		}},
+	})
+	defer config.SetCurrentConfig(types.Config{})
+
+	ok := pingFunc(func(context.Context) error { return nil })
+	a := &checkedProvider{err: errors.New("down")}
+	registry := provider.StaticRegistry{"a": a, "b": &checkedProvider{}}
+	d := NewDeepChecker(registry, ok, nil)
+
+	report := d.Check(context.Background())
+	assert.Equal(t, types.ComponentDegraded, report.Status, "eth is still served by b")
+	assert.Empty(t, report.DownChains)
+
+	registry["b"] = &checkedProvider{err: errors.New("down")}
+	assert.Equal(t, types.ComponentDegraded, d.Check(context.Background()).Status, "the report is cached")
+
+	d = NewDeepChecker(registry, ok, nil)
+	report = d.Check(context.Background())
+	assert.Equal(t, types.ComponentFailed, report.Status)
+	assert.Equal(t, []string{"ETH"}, report.DownChains)
+}
@@ -0,0 +1,187 @@
+// Package health periodically probes every registered provider that offers
+// a cheap health check, counts consecutive failures and takes providers that
+// keep failing out of MultiProvider routing until they pass again. The
+// current state is exposed at /providers/status.
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+const (
+	defaultIntervalSeconds  = 30
+	defaultFailureThreshold = 3
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// state is what the prober knows about one provider.
+type state struct {
+	failures       int
+	lastCheck      time.Time
+	lastLatency    time.Duration
+	lastErr        string
+	unhealthySince time.Time // zero while healthy
+}
+
+// Prober runs the health checks and keeps the state of every provider.
+type Prober struct {
+	registry provider.Registry
+
+	mu     sync.RWMutex
+	states map[string]*state // providerKey -> state, probed providers only
+}
+
+var _ provider.HealthSource = (*Prober)(nil)
+
+// NewProber creates a Prober over the provider registry. Providers added by a
+// configuration reload are probed from the next round on.
+func NewProber(registry provider.Registry) *Prober {
+	return &Prober{
+		registry: registry,
+		states:   make(map[string]*state),
+	}
+}
+
+// Start probes every provider immediately and then every interval_seconds in
+// the background. It does nothing unless health.enabled is set.
+func (p *Prober) Start() {
+	cfg := config.Current().Health
+	if !cfg.Enabled {
+		return
+	}
+	interval := cfg.IntervalSeconds
+	if interval <= 0 {
+		interval = defaultIntervalSeconds
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			p.ProbeAll()
+			<-ticker.C
+		}
+	}()
+	logger.Log.Info().Int64("interval_seconds", interval).Msg("Provider health prober scheduled")
+}
+
+// ProbeAll runs the health check of every provider that has one, in
+// parallel, and waits for all of them.
+func (p *Prober) ProbeAll() {
+	var wg sync.WaitGroup
+	for key, prov := range p.registry.Providers() {
+		checker, ok := prov.(provider.HealthChecker)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := now()
+			err := checker.HealthCheck()
+			p.record(key, err, now().Sub(start))
+		}()
+	}
+	wg.Wait()
+}
+
+// record updates the state of key with the outcome of one probe.
+func (p *Prober) record(key string, err error, latency time.Duration) {
+	threshold := config.Current().Health.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, ok := p.states[key]
+	if !ok {
+		s = &state{}
+		p.states[key] = s
+	}
+	s.lastCheck = now()
+	s.lastLatency = latency
+
+	if err == nil {
+		if !s.unhealthySince.IsZero() {
+			logger.Log.Info().
+				Str("provider", key).
+				Dur("unhealthy_for", now().Sub(s.unhealthySince)).
+				Msg("Provider healthy again, back in routing")
+		}
+		s.failures = 0
+		s.lastErr = ""
+		s.unhealthySince = time.Time{}
+		metrics.ProviderHealthy.WithLabelValues(key).Set(1)
+		return
+	}
+
+	s.failures++
+	s.lastErr = err.Error()
+	logger.Log.Warn().
+		Err(err).
+		Str("provider", key).
+		Int("consecutive_failures", s.failures).
+		Msg("Provider health check failed")
+
+	if s.failures >= threshold && s.unhealthySince.IsZero() {
+		s.unhealthySince = now()
+		metrics.ProviderHealthy.WithLabelValues(key).Set(0)
+		logger.Log.Error().
+			Str("provider", key).
+			Int("consecutive_failures", s.failures).
+			Msg("Provider unhealthy, removed from routing")
+	}
+}
+
+// Healthy reports whether key may be routed to. Providers that have not
+// been probed, or cannot be, are healthy.
+func (p *Prober) Healthy(key string) bool {
+	if p == nil {
+		return true
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s, ok := p.states[key]
+	return !ok || s.unhealthySince.IsZero()
+}
+
+// Status returns the state of every registered provider, sorted by key.
+func (p *Prober) Status() []types.ProviderStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	providers := p.registry.Providers()
+	out := make([]types.ProviderStatus, 0, len(providers))
+	for key, prov := range providers {
+		st := types.ProviderStatus{Provider: key, Healthy: true}
+		if _, ok := prov.(provider.HealthChecker); ok {
+			st.Probed = true
+		}
+		if s, ok := p.states[key]; ok {
+			st.Healthy = s.unhealthySince.IsZero()
+			st.ConsecutiveFailures = s.failures
+			st.LastCheckTime = s.lastCheck.Unix()
+			st.LastLatencyMs = s.lastLatency.Milliseconds()
+			st.LastError = s.lastErr
+			if !st.Healthy {
+				st.UnhealthySince = s.unhealthySince.Unix()
+			}
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+// checkedProvider answers health checks with err.
+type checkedProvider struct {
+	err error
+}
+
+func (c *checkedProvider) GetTransactions(*types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	return &types.TransactionResponse{}, nil
+}
+
+func (c *checkedProvider) HealthCheck() error { return c.err }
+
+// plainProvider has no health check.
+type plainProvider struct{}
+
+func (plainProvider) GetTransactions(*types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	return &types.TransactionResponse{}, nil
+}
+
+func TestProbeAll_DisablesAfterThresholdAndRecovers(t *testing.T) {
+	config.SetCurrentConfig(types.Config{Health: types.HealthConfig{FailureThreshold: 2}})
+	defer config.SetCurrentConfig(types.Config{})
+
+	flaky := &checkedProvider{err: errors.New("503")}
+	p := NewProber(provider.StaticRegistry{
+		"ankr":           &checkedProvider{},
+		"blockscout_ttx": flaky,
+		"tron_tron":      plainProvider{},
+	})
+
+	p.ProbeAll()
+	assert.True(t, p.Healthy("blockscout_ttx"), "one failure is below the threshold")
+
+	p.ProbeAll()
+	assert.False(t, p.Healthy("blockscout_ttx"))
+	assert.True(t, p.Healthy("ankr"))
+	assert.True(t, p.Healthy("tron_tron"), "providers without a health check stay routable")
+
+	status := p.Status()
+	assert.Len(t, status, 3)
+	assert.Equal(t, "blockscout_ttx", status[1].Provider)
+	assert.False(t, status[1].Healthy)
+	assert.Equal(t, 2, status[1].ConsecutiveFailures)
+	assert.Equal(t, "503", status[1].LastError)
+	assert.NotZero(t, status[1].UnhealthySince)
+	assert.False(t, status[2].Probed)
+
+	flaky.err = nil
+	p.ProbeAll()
+	assert.True(t, p.Healthy("blockscout_ttx"))
+	assert.Zero(t, p.Status()[1].ConsecutiveFailures)
+}
+
+func TestHealthy_NilProber(t *testing.T) {
+	var p *Prober
+	assert.True(t, p.Healthy("ankr"))
+}
@@ -0,0 +1,285 @@
+// Package indexer follows the new blocks of configured chains over JSON-RPC
+// and pushes the transactions of watched addresses into storage and the
+// cache as they are mined, so watched wallets are fresh without waiting for
+// a query to miss the cache. Each chain keeps a cursor in Redis and is
+// indexed by a single instance at a time.
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/provider/rpcscan"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultPollSeconds = 5
+	defaultMaxBlocks   = 100
+	defaultLockSeconds = 300
+
+	// lockPrefix names the per-chain lock taken for each round.
+	lockPrefix = "job:index:"
+
+	// syncInterval is how often Start picks up chains added to or removed
+	// from the configuration.
+	syncInterval = 5 * time.Second
+)
+
+// errNotConfigured is returned by RunOnce for a chain not in indexer.chains.
+var errNotConfigured = errors.New("chain not configured")
+
+// Follower reads a chain block by block. Implemented by the rpcscan
+// provider.
+type Follower interface {
+	LatestBlock() (int64, error)
+	IndexRange(addresses []string, from, to int64) (map[string][]types.Transaction, error)
+}
+
+// Indexer follows every chain listed in indexer.chains.
+type Indexer struct {
+	cursors  interfaces.IndexCursorStoreInterface
+	ingester interfaces.TransactionIngesterInterface
+	build    bool // whether followers are built from the configuration
+
+	mu         sync.Mutex
+	generation uint64                              // config.Generation() followers were built from
+	followers  map[string]Follower                 // uppercase chain name → follower
+	built      map[string]types.IndexerChainConfig // uppercase chain name → settings its follower was built with
+	running    map[string]bool                     // chains followed by a Start goroutine
+}
+
+// NewIndexer creates an Indexer that keeps its cursors in cursors (normally
+// the Redis cache) and hands the transactions it finds to ingester (normally
+// the usecase Service). The chains follow configuration reloads.
+func NewIndexer(cursors interfaces.IndexCursorStoreInterface, ingester interfaces.TransactionIngesterInterface) *Indexer {
+	return &Indexer{cursors: cursors, ingester: ingester, build: true}
+}
+
+// Start follows every chain in the background, one round per poll_seconds,
+// while indexer.enabled is set. Chains added to indexer.chains by a reload
+// are followed within seconds; removed ones stop after their current round.
+func (ix *Indexer) Start() {
+	go func() {
+		for {
+			utils.Safely("indexer", ix.startChains)
+			time.Sleep(syncInterval)
+		}
+	}()
+	logger.Log.Info().Msg("Background indexer scheduled")
+}
+
+// startChains starts a goroutine for every configured chain not followed
+// yet.
+func (ix *Indexer) startChains() {
+	if !config.Current().Indexer.Enabled {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.syncLocked()
+	if ix.running == nil {
+		ix.running = make(map[string]bool)
+	}
+	for chainName := range ix.followers {
+		if ix.running[chainName] {
+			continue
+		}
+		ix.running[chainName] = true
+		logger.Log.Info().Str("chain", chainName).Msg("Indexer following chain")
+		go ix.follow(chainName)
+	}
+}
+
+// follow runs the rounds of chainName until it leaves the configuration or
+// the indexer is disabled.
+func (ix *Indexer) follow(chainName string) {
+	defer func() {
+		ix.mu.Lock()
+		delete(ix.running, chainName)
+		ix.mu.Unlock()
+	}()
+	for config.Current().Indexer.Enabled {
+		var err error
+		utils.Safely("indexer", func() { _, err = ix.RunOnce(chainName) })
+		if errors.Is(err, errNotConfigured) {
+			logger.Log.Info().Str("chain", chainName).Msg("Indexer chain removed, stopping")
+			return
+		} else if err != nil {
+			logger.Log.Warn().Err(err).Str("chain", chainName).Msg("Indexer round failed")
+		}
+		time.Sleep(pollInterval(chainConfig(chainName)))
+	}
+}
+
+// follower returns the follower of chainName, rebuilding the followers
+// first if the configuration changed.
+func (ix *Indexer) follower(chainName string) (Follower, bool) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.syncLocked()
+	f, ok := ix.followers[chainName]
+	return f, ok
+}
+
+// syncLocked rebuilds the followers from indexer.chains when the
+// configuration changed since they were built, keeping those whose settings
+// did not change. Without build, the followers are fixed (tests).
+func (ix *Indexer) syncLocked() {
+	gen := config.Generation()
+	if !ix.build || (ix.followers != nil && gen == ix.generation) {
+		return
+	}
+	followers := make(map[string]Follower)
+	built := make(map[string]types.IndexerChainConfig)
+	for _, c := range config.Current().Indexer.Chains {
+		name := strings.ToUpper(c.ChainName)
+		if f, ok := ix.followers[name]; ok && ix.built[name] == c {
+			followers[name], built[name] = f, c
+			continue
+		}
+		chainID, err := utils.ChainIDByName(c.ChainName)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("chain", c.ChainName).Msg("Skipping indexer chain")
+			continue
+		}
+		followers[name] = rpcscan.NewRPCScanProvider(chainID, types.RPCScanConfig{
+			ChainName: c.ChainName,
+			URL:       c.URL,
+			BatchSize: c.BatchSize,
+			LogRange:  c.LogRange,
+		})
+		built[name] = c
+	}
+	ix.generation, ix.followers, ix.built = gen, followers, built
+}
+
+// RunOnce indexes the blocks of chainName mined since the cursor, up to
+// max_blocks and confirmations behind the head, then moves the cursor past
+// the blocks whose transactions were all ingested. The first round only
+// places the cursor at the head: older history is fetched on demand. It
+// returns the number of blocks indexed; a round skipped because another
+// instance holds the chain is not an error.
+func (ix *Indexer) RunOnce(chainName string) (int64, error) {
+	chainName = strings.ToUpper(chainName)
+	follower, ok := ix.follower(chainName)
+	if !ok {
+		return 0, fmt.Errorf("indexer: %s: %w", chainName, errNotConfigured)
+	}
+	cfg := chainConfig(chainName)
+
+	// Only one instance indexes a chain at a time. The lock is held for the
+	// whole round and released at its end; lock_seconds only bounds how long
+	// a crashed instance keeps the chain.
+	lockName := lockPrefix + chainName
+	lockTTL := time.Duration(utils.IntOr(cfg.LockSeconds, defaultLockSeconds)) * time.Second
+	token, ok, err := ix.cursors.TryLock(lockName, max(lockTTL, pollInterval(cfg)))
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer func() {
+		if err := ix.cursors.Unlock(lockName, token); err != nil {
+			logger.Log.Warn().Err(err).Str("chain", chainName).Msg("Failed to release indexer lock")
+		}
+	}()
+
+	latest, err := follower.LatestBlock()
+	if err != nil {
+		return 0, err
+	}
+	head := latest - max(cfg.Confirmations, 0)
+	if head <= 0 {
+		return 0, nil
+	}
+	cursor, err := ix.cursors.IndexCursor(chainName)
+	if err != nil {
+		return 0, err
+	}
+	if cursor == 0 {
+		logger.Log.Info().Str("chain", chainName).Int64("block", head).Msg("Indexer cursor initialized at head")
+		return 0, ix.cursors.SetIndexCursor(chainName, head)
+	}
+
+	from := cursor + 1
+	to := min(head, from+utils.IntOr(cfg.MaxBlocks, defaultMaxBlocks)-1)
+	if from > to {
+		metrics.IndexerLag.WithLabelValues(chainName).Set(float64(latest - cursor))
+		return 0, nil
+	}
+
+	found, err := follower.IndexRange(watched(), from, to)
+	if err != nil {
+		return 0, err
+	}
+	ingested, done := 0, to
+	for address, txs := range found {
+		n, err := ix.ingester.IngestTransactions(address, txs)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("chain", chainName).Str("address", address).Msg("Failed to ingest indexed transactions")
+			// Stop the cursor before the first block of the address, so the
+			// next round indexes it again
+			for _, tx := range txs {
+				done = min(done, tx.Height-1)
+			}
+			continue
+		}
+		ingested += n
+	}
+	if done < from {
+		return 0, fmt.Errorf("indexer: %s: ingest of block %d failed", chainName, from)
+	}
+	if err := ix.cursors.SetIndexCursor(chainName, done); err != nil {
+		return 0, err
+	}
+	to = done
+
+	blocks := to - from + 1
+	metrics.IndexedBlocks.WithLabelValues(chainName).Add(float64(blocks))
+	metrics.IndexerLag.WithLabelValues(chainName).Set(float64(latest - to))
+	logger.Log.Debug().
+		Str("chain", chainName).
+		Int64("from_block", from).
+		Int64("to_block", to).
+		Int("addresses", len(found)).
+		Int("transactions", ingested).
+		Msg("Indexer round finished")
+	return blocks, nil
+}
+
+// chainConfig returns the live settings of chainName.
+func chainConfig(chainName string) types.IndexerChainConfig {
+	for _, c := range config.Current().Indexer.Chains {
+		if strings.EqualFold(c.ChainName, chainName) {
+			return c
+		}
+	}
+	return types.IndexerChainConfig{ChainName: chainName}
+}
+
+// watched returns the lowercase, deduplicated watch list.
+func watched() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, a := range config.Current().Indexer.WatchList {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		out = append(out, a)
+	}
+	return out
+}
+
+// pollInterval is the delay between two rounds on a chain.
+func pollInterval(cfg types.IndexerChainConfig) time.Duration {
+	return time.Duration(utils.IntOr(cfg.PollSeconds, defaultPollSeconds)) * time.Second
+}
@@ -0,0 +1,161 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// stubCursors keeps cursors in memory; the lock is always free and counts
+// its releases.
+type stubCursors struct {
+	cursors  map[string]int64
+	unlocked int
+}
+
+func (s *stubCursors) IndexCursor(chainName string) (int64, error) {
+	return s.cursors[chainName], nil
+}
+
+func (s *stubCursors) SetIndexCursor(chainName string, block int64) error {
+	s.cursors[chainName] = block
+	return nil
+}
+
+func (s *stubCursors) TryLock(string, time.Duration) (string, bool, error) {
+	return "token", true, nil
+}
+
+func (s *stubCursors) Unlock(string, string) error {
+	s.unlocked++
+	return nil
+}
+
+// stubFollower reports a fixed head and records the ranges indexed.
+type stubFollower struct {
+	head   int64
+	ranges [][2]int64
+	found  map[string][]types.Transaction
+	err    error
+}
+
+func (f *stubFollower) LatestBlock() (int64, error) { return f.head, nil }
+
+func (f *stubFollower) IndexRange(_ []string, from, to int64) (map[string][]types.Transaction, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.ranges = append(f.ranges, [2]int64{from, to})
+	return f.found, nil
+}
+
+// stubIngester records the ingested rows per address; addresses in fail
+// fail.
+type stubIngester struct {
+	got  map[string]int
+	fail map[string]bool
+}
+
+func (s *stubIngester) IngestTransactions(address string, txs []types.Transaction) (int, error) {
+	if s.fail[address] {
+		return 0, errors.New("store down")
+	}
+	s.got[address] += len(txs)
+	return len(txs), nil
+}
+
+func TestRunOnce(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Indexer = types.IndexerConfig{
+		Enabled:   true,
+		WatchList: []string{"0xABC"},
+		Chains:    []types.IndexerChainConfig{{ChainName: "ETH", Confirmations: 2, MaxBlocks: 10}},
+	}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	cursors := &stubCursors{cursors: map[string]int64{}}
+	ingester := &stubIngester{got: map[string]int{}}
+	follower := &stubFollower{head: 102, found: map[string][]types.Transaction{"0xabc": {{Hash: "0x1"}}}}
+	ix := &Indexer{cursors: cursors, ingester: ingester, followers: map[string]Follower{"ETH": follower}}
+
+	n, err := ix.RunOnce("eth")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n, "first round only places the cursor")
+	assert.Equal(t, int64(100), cursors.cursors["ETH"], "confirmations behind the head")
+
+	follower.head = 130
+	n, err = ix.RunOnce("ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), n, "capped by max_blocks")
+	assert.Equal(t, [][2]int64{{101, 110}}, follower.ranges)
+	assert.Equal(t, int64(110), cursors.cursors["ETH"])
+	assert.Equal(t, 1, ingester.got["0xabc"])
+
+	follower.err = errors.New("node down")
+	_, err = ix.RunOnce("ETH")
+	assert.Error(t, err)
+	assert.Equal(t, int64(110), cursors.cursors["ETH"], "a failed range is retried")
+	assert.Equal(t, 3, cursors.unlocked, "the lock is released after every round")
+
+	follower.err = nil
+	follower.found = map[string][]types.Transaction{
+		"0xabc": {{Hash: "0x2", Height: 112}},
+		"0xdef": {{Hash: "0x3", Height: 115}},
+	}
+	ingester.fail = map[string]bool{"0xdef": true}
+	n, err = ix.RunOnce("ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, int64(114), cursors.cursors["ETH"], "the cursor stops before a failed ingest")
+
+	follower.found = map[string][]types.Transaction{"0xdef": {{Hash: "0x3", Height: 115}}}
+	_, err = ix.RunOnce("ETH")
+	assert.Error(t, err)
+	assert.Equal(t, int64(114), cursors.cursors["ETH"])
+
+	_, err = ix.RunOnce("BSC")
+	assert.ErrorIs(t, err, errNotConfigured)
+}
+
+func TestFollowersFollowReloads(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	cfg.Indexer.Chains = []types.IndexerChainConfig{{ChainName: "ETH", URL: "http://eth"}}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	ix := NewIndexer(&stubCursors{cursors: map[string]int64{}}, &stubIngester{got: map[string]int{}})
+	eth, ok := ix.follower("ETH")
+	assert.True(t, ok)
+	_, ok = ix.follower("BSC")
+	assert.False(t, ok)
+
+	cfg.Indexer.Chains = append(cfg.Indexer.Chains, types.IndexerChainConfig{ChainName: "bsc", URL: "http://bsc"})
+	config.SetCurrentConfig(cfg)
+	_, ok = ix.follower("BSC")
+	assert.True(t, ok, "chain added by a reload")
+	same, _ := ix.follower("ETH")
+	assert.Same(t, eth, same, "unchanged chains keep their follower")
+
+	cfg.Indexer.Chains = cfg.Indexer.Chains[1:]
+	config.SetCurrentConfig(cfg)
+	_, err := ix.RunOnce("ETH")
+	assert.ErrorIs(t, err, errNotConfigured, "chain removed by a reload")
+}
+
+func TestWatched(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Indexer.WatchList = []string{"0xABC", " 0xabc ", "", "0xdef"}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	assert.Equal(t, []string{"0xabc", "0xdef"}, watched())
+}
@@ -1,8 +1,107 @@
 package interfaces
 
-import "tx-aggregator/types"
+import (
+	"time"
+	"tx-aggregator/types"
+)
 
 // TransactionServiceInterface defines the interface for transaction service
 type TransactionServiceInterface interface {
 	GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error)
 }
+
+// TransactionHistoryInterface exposes the history of an address without the
+// response limit (sorted ascending), used by batch consumers such as the tax
+// lot export. complete is false when the history may have been cut short.
+type TransactionHistoryInterface interface {
+	GetTransactionHistory(params *types.TransactionQueryParams) (txs []types.Transaction, complete bool, err error)
+}
+
+// PortfolioServiceInterface summarizes the holdings and recent activity of
+// an address on several chains.
+type PortfolioServiceInterface interface {
+	GetPortfolio(params *types.TransactionQueryParams, recent int) (*types.Portfolio, error)
+}
+
+// TokenServiceInterface lists the tokens an address has transfers of.
+type TokenServiceInterface interface {
+	GetTokens(params *types.TransactionQueryParams) ([]types.TokenMeta, error)
+}
+
+// AllowanceServiceInterface reads live ERC-20 allowances.
+type AllowanceServiceInterface interface {
+	GetAllowance(params *types.AllowanceParams) (*types.Allowance, error)
+}
+
+// ReceiptServiceInterface proxies transaction receipts from the chains' RPC
+// endpoints.
+type ReceiptServiceInterface interface {
+	GetReceipt(chainName, hash string) (*types.Receipt, error)
+}
+
+// TransactionWarmerInterface fetches an address from the providers, bypassing
+// the cache read, and caches the result. Used by the backfill job.
+type TransactionWarmerInterface interface {
+	WarmTransactions(params *types.TransactionQueryParams) (int, error)
+}
+
+// TransactionReplayerInterface re-runs the current normalization over cached
+// transactions. Used by the replay job.
+type TransactionReplayerInterface interface {
+	CachedAddresses(chainName string) ([]string, error)
+	ReplayTransactions(params *types.TransactionQueryParams) (kept, dropped int, err error)
+}
+
+// HotAddressStoreInterface ranks recently queried addresses and reports how
+// long their cache entries have left. Implemented by the Redis cache; used by
+// the cache warming job.
+type HotAddressStoreInterface interface {
+	HotAddresses(n int) ([]types.HotAddress, error)
+	DecayHotAddresses(factor float64) error
+	EntryTTL(address, chainName string) (time.Duration, error)
+	TryLock(name string, ttl time.Duration) (string, bool, error)
+}
+
+// CacheInvalidatorInterface drops the cached transactions of an address so
+// the next query goes to the providers.
+type CacheInvalidatorInterface interface {
+	InvalidateTransactions(params *types.TransactionQueryParams) (*types.CacheInvalidation, error)
+}
+
+// TransactionIngesterInterface stores transactions pushed by the indexer for
+// an address, as opposed to fetched on request.
+type TransactionIngesterInterface interface {
+	IngestTransactions(address string, txs []types.Transaction) (int, error)
+}
+
+// IndexCursorStoreInterface keeps the last block indexed per chain and the
+// lock that makes a single instance index each chain. Implemented by the
+// Redis cache; used by the indexer.
+type IndexCursorStoreInterface interface {
+	IndexCursor(chainName string) (int64, error)
+	SetIndexCursor(chainName string, block int64) error
+	TryLock(name string, ttl time.Duration) (string, bool, error)
+	Unlock(name, token string) error
+}
+
+// TransactionRefresherInterface fetches an address from the providers,
+// bypassing the cache read, caches the result and returns it. Used by the
+// watch refresh job.
+type TransactionRefresherInterface interface {
+	RefreshTransactions(params *types.TransactionQueryParams) ([]types.Transaction, error)
+}
+
+// WatchStoreInterface keeps address watches and their refresh schedule,
+// extends the cache retention of watched addresses and meters refreshes per
+// provider. Implemented by the Redis cache.
+type WatchStoreInterface interface {
+	SaveWatch(w types.Watch) error
+	GetWatch(id string) (types.Watch, bool, error)
+	DeleteWatch(w types.Watch) error
+	Watches(owner string) ([]types.Watch, error)
+	RetainAddress(address string, chainNames []string, ttl time.Duration) error
+	ScheduleWatch(id string, at time.Time) error
+	UnscheduleWatch(id string) error
+	ClaimDueWatches(n int, lease time.Duration) ([]string, error)
+	TakeRateToken(name string, perMinute int) (bool, error)
+}
@@ -0,0 +1,152 @@
+// Package jobs keeps the state of background admin jobs (tax lot exports,
+// backfills, replays) in memory, so their progress can be polled by ID.
+// Finished jobs are evicted oldest first once a registry holds too many.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"tx-aggregator/utils"
+)
+
+// Registry holds jobs of type T by ID. Jobs added under a key are exclusive:
+// while one runs, adding another under the same key returns the running one.
+// A job is never evicted before it is released, keyed or not.
+type Registry[T any] struct {
+	maxJobs int
+	touch   func(job *T, now int64) // records the update time of a job
+
+	mu      sync.RWMutex
+	jobs    map[string]*T
+	order   []string          // job IDs in submission order, used for eviction
+	running map[string]string // key → ID of its running job
+	active  map[string]bool   // IDs of jobs not yet released
+}
+
+// NewRegistry creates a Registry keeping at most maxJobs jobs. touch is
+// called with the current Unix time whenever a job is updated.
+func NewRegistry[T any](maxJobs int, touch func(job *T, now int64)) *Registry[T] {
+	return &Registry[T]{
+		maxJobs: maxJobs,
+		touch:   touch,
+		jobs:    make(map[string]*T),
+		running: make(map[string]string),
+		active:  make(map[string]bool),
+	}
+}
+
+// Add stores the job built by newJob under a fresh ID and returns a snapshot
+// of it. With a non-empty key, if a job is still running under key, that job
+// is returned instead and added is false; otherwise the new job holds key
+// until Release. Either way the new job is kept until it is released with
+// Release or Finish.
+func (r *Registry[T]) Add(key string, newJob func(id string) *T) (snapshot T, added bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.running[key]; ok && key != "" {
+		return *r.jobs[id], false
+	}
+	id := newID()
+	job := newJob(id)
+	r.jobs[id] = job
+	r.order = append(r.order, id)
+	r.active[id] = true
+	if key != "" {
+		r.running[key] = id
+	}
+	r.evictLocked()
+	return *job, true
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (r *Registry[T]) Get(id string) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return *job, true
+}
+
+// Update applies fn to the job under lock, if it has not been evicted.
+func (r *Registry[T]) Update(id string, fn func(job *T)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[id]; ok {
+		fn(job)
+		r.touch(job, time.Now().Unix())
+	}
+}
+
+// Release marks key as free for a new job and its job as finished.
+func (r *Registry[T]) Release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.running[key]; ok {
+		delete(r.active, id)
+		delete(r.running, key)
+	}
+	r.evictLocked()
+}
+
+// Finish marks the job with the given ID as finished, so it may be evicted.
+// Use it for jobs added without a key.
+func (r *Registry[T]) Finish(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.active, id)
+	for key, running := range r.running {
+		if running == id {
+			delete(r.running, key)
+		}
+	}
+	r.evictLocked()
+}
+
+// Go runs fn for the job with the given ID in a new goroutine. If fn
+// panics, the panic is logged, fail records it on the job and the job is
+// finished, so it neither stays running nor holds its key.
+func (r *Registry[T]) Go(id string, fn func(), fail func(job *T, err error)) {
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				err := utils.LogPanic("jobs", e)
+				r.Update(id, func(job *T) { fail(job, err) })
+				r.Finish(id)
+			}
+		}()
+		fn()
+	}()
+}
+
+// evictLocked drops the oldest finished jobs once more than maxJobs are
+// retained. Jobs not yet released are skipped, so the registry may exceed
+// maxJobs while they run. The caller must hold r.mu.
+func (r *Registry[T]) evictLocked() {
+	excess := len(r.order) - r.maxJobs
+	if excess <= 0 {
+		return
+	}
+	kept := r.order[:0]
+	for _, id := range r.order {
+		if excess > 0 && !r.active[id] {
+			delete(r.jobs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	r.order = kept
+}
+
+// newID returns a random 128-bit hex identifier.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
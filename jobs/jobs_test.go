@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testJob struct {
+	ID      string
+	Steps   int
+	Updated int64
+}
+
+func newTestRegistry(maxJobs int) *Registry[testJob] {
+	return NewRegistry(maxJobs, func(job *testJob, now int64) { job.Updated = now })
+}
+
+func TestRegistry_AddGetUpdate(t *testing.T) {
+	r := newTestRegistry(10)
+	job, added := r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+	assert.True(t, added)
+	assert.Len(t, job.ID, 32)
+
+	r.Update(job.ID, func(j *testJob) { j.Steps++ })
+	got, ok := r.Get(job.ID)
+	assert.True(t, ok)
+	assert.Equal(t, 1, got.Steps)
+	assert.NotZero(t, got.Updated)
+
+	_, ok = r.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestRegistry_OneRunningJobPerKey(t *testing.T) {
+	r := newTestRegistry(10)
+	first, _ := r.Add("ETH", func(id string) *testJob { return &testJob{ID: id} })
+	again, added := r.Add("ETH", func(id string) *testJob { return &testJob{ID: id} })
+	assert.False(t, added)
+	assert.Equal(t, first.ID, again.ID)
+
+	other, added := r.Add("BSC", func(id string) *testJob { return &testJob{ID: id} })
+	assert.True(t, added)
+	assert.NotEqual(t, first.ID, other.ID)
+
+	r.Release("ETH")
+	next, added := r.Add("ETH", func(id string) *testJob { return &testJob{ID: id} })
+	assert.True(t, added)
+	assert.NotEqual(t, first.ID, next.ID)
+}
+
+func TestRegistry_EvictsOldestFinishedJobs(t *testing.T) {
+	r := newTestRegistry(2)
+	running, _ := r.Add("ETH", func(id string) *testJob { return &testJob{ID: id} })
+	var finished []string
+	for i := 0; i < 3; i++ {
+		job, _ := r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+		r.Finish(job.ID)
+		finished = append(finished, job.ID)
+	}
+	_, ok := r.Get(running.ID)
+	assert.True(t, ok, "a job holding its key is kept")
+	_, ok = r.Get(finished[0])
+	assert.False(t, ok, "finished jobs behind a running one are still evicted")
+	assert.Len(t, r.order, 2)
+
+	r.Release("ETH")
+	job, _ := r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+	r.Finish(job.ID)
+	_, ok = r.Get(running.ID)
+	assert.False(t, ok)
+	assert.Len(t, r.order, 2)
+}
+
+func TestRegistry_SkipsUnfinishedJobsOnEviction(t *testing.T) {
+	r := newTestRegistry(2)
+	pending, _ := r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+	done, _ := r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+	r.Finish(done.ID)
+	r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+
+	_, ok := r.Get(pending.ID)
+	assert.True(t, ok, "an unfinished key-less job is kept")
+	_, ok = r.Get(done.ID)
+	assert.False(t, ok, "the finished job behind it is evicted")
+	assert.Len(t, r.order, 2)
+
+	r.Add("", func(id string) *testJob { return &testJob{ID: id} })
+	assert.Len(t, r.order, 3, "the registry grows while every job is unfinished")
+	r.Finish(pending.ID)
+	_, ok = r.Get(pending.ID)
+	assert.False(t, ok)
+	assert.Len(t, r.order, 2)
+}
+
+func TestRegistry_GoRecoversPanic(t *testing.T) {
+	r := newTestRegistry(10)
+	job, _ := r.Add("ETH", func(id string) *testJob { return &testJob{ID: id} })
+
+	r.Go(job.ID, func() { panic("boom") }, func(j *testJob, err error) {
+		assert.EqualError(t, err, "jobs panicked: boom")
+		j.Steps = -1
+	})
+
+	assert.Eventually(t, func() bool {
+		_, added := r.Add("ETH", func(id string) *testJob { return &testJob{ID: id} })
+		return added
+	}, time.Second, 10*time.Millisecond, "the key is released")
+	got, _ := r.Get(job.ID)
+	assert.Equal(t, -1, got.Steps)
+}
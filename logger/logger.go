@@ -18,6 +18,9 @@ import (
 //	logger.Log.Info().Msg("hello world")
 var Log zerolog.Logger
 
+// out is the writer behind Log, kept so that AddSink can extend it.
+var out io.Writer
+
 // -----------------------------------------------------------------------------
 // Fallback console logger (usable before Init is called).
 // -----------------------------------------------------------------------------
@@ -27,6 +30,7 @@ func init() {
 		TimeFormat: time.RFC3339, // ISO‑8601
 	}
 
+	out = console
 	Log = zerolog.New(console).
 		With().
 		Timestamp().
@@ -62,6 +66,7 @@ func Init(level int8, logDir, consoleFormat, fileFormat string) {
 	if err != nil {
 		// Cannot write to file ➜ degrade gracefully to console‑only logging.
 		fallback := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+		out = fallback
 		Log = zerolog.New(fallback).With().Timestamp().Caller().Logger()
 		Log.Error().Err(err).Msg("Failed to open log file (console‑only mode)")
 		return
@@ -75,6 +80,7 @@ func Init(level int8, logDir, consoleFormat, fileFormat string) {
 	multi := io.MultiWriter(consoleOut, fileOut)
 
 	// 5) Replace global logger.
+	out = multi
 	Log = zerolog.New(multi).
 		With().
 		Timestamp().
@@ -91,6 +97,12 @@ func Init(level int8, logDir, consoleFormat, fileFormat string) {
 		Msg("Logger initialized")
 }
 
+// SetLevel changes the minimum level logged, for a log.level changed by a
+// configuration reload.
+func SetLevel(level int8) {
+	zerolog.SetGlobalLevel(zerolog.Level(level))
+}
+
 // buildWriter returns an io.Writer that writes in either text (ConsoleWriter) or
 // raw JSON format. `forConsole == true` enables ANSI colors.
 func buildWriter(format string, target io.Writer, forConsole bool) io.Writer {
@@ -105,3 +117,10 @@ func buildWriter(format string, target io.Writer, forConsole bool) io.Writer {
 		}
 	}
 }
+
+// AddSink makes every later log entry also go to w, as raw JSON together
+// with its level. It must be called after Init, which replaces the writers.
+func AddSink(w zerolog.LevelWriter) {
+	out = zerolog.MultiLevelWriter(out, w)
+	Log = Log.Output(out)
+}
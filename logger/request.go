@@ -0,0 +1,20 @@
+package logger
+
+import "github.com/rs/zerolog"
+
+// RequestIDField is the log field carrying the ID of the API request an
+// event belongs to.
+const RequestIDField = "request_id"
+
+// ForRequest returns the global logger with every event tagged with
+// requestID, so the lines of one API request can be correlated. Without a
+// request ID (background jobs) it is the global logger itself.
+//
+//	logger.ForRequest(params.RequestID).Info().Msg("hello")
+func ForRequest(requestID string) *zerolog.Logger {
+	if requestID == "" {
+		return &Log
+	}
+	l := Log.With().Str(RequestIDField, requestID).Logger()
+	return &l
+}
@@ -0,0 +1,286 @@
+// Package metrics exposes Prometheus metrics describing how saturated the
+// service is: worker pools, semaphore waits, in-flight provider calls and the
+// Redis connection pool. Go runtime metrics (go_goroutines, …) come from the
+// default registry.
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "tx_aggregator"
+
+var (
+	// WorkerPoolSize is the configured number of workers per pool.
+	WorkerPoolSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_pool_size",
+		Help:      "Configured number of workers per pool.",
+	}, []string{"pool"})
+
+	// WorkerPoolBusy is the number of workers currently running a job.
+	WorkerPoolBusy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_pool_busy",
+		Help:      "Workers currently running a job, per pool.",
+	}, []string{"pool"})
+
+	// SemaphoreWait observes how long jobs wait for a free worker slot.
+	SemaphoreWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "semaphore_wait_seconds",
+		Help:      "Time spent waiting for a worker slot, per pool.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10), // 1ms … ~4.4min
+	}, []string{"pool"})
+
+	// ProviderInFlight is the number of provider calls currently running.
+	ProviderInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_inflight_calls",
+		Help:      "Provider calls currently in flight, per provider.",
+	}, []string{"provider"})
+
+	// ProviderFailovers counts chains that moved on to their next provider,
+	// by reason ("error" or "timeout").
+	ProviderFailovers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_failovers_total",
+		Help:      "Chains that failed over to their next provider, per chain and reason.",
+	}, []string{"chain", "reason"})
+
+	// ProviderDiscrepancies counts transaction fields on which providers
+	// returned different values, by field.
+	ProviderDiscrepancies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_discrepancies_total",
+		Help:      "Transaction fields on which providers disagreed, per field.",
+	}, []string{"field"})
+
+	// HTTPRetries counts outbound requests retried by the shared HTTP helper,
+	// by label and reason ("429", "5xx" or "timeout").
+	HTTPRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_retries_total",
+		Help:      "Outbound HTTP requests retried, per request label and reason.",
+	}, []string{"label", "reason"})
+
+	// ProviderRequestDuration observes every outbound request attempt,
+	// failed ones included, by provider and request label.
+	ProviderRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Duration of outbound request attempts, per provider and request label.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms … ~20s
+	}, []string{"provider", "label"})
+
+	// ProviderErrors counts failed outbound request attempts by provider,
+	// request label and class ("timeout", "network", "429", "5xx", "4xx",
+	// "status" or "decode").
+	ProviderErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_errors_total",
+		Help:      "Failed outbound request attempts, per provider, request label and error class.",
+	}, []string{"provider", "label", "class"})
+
+	// RateLimitWait observes how long outbound requests wait for their
+	// provider's rate limiter.
+	RateLimitWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_wait_seconds",
+		Help:      "Time outbound requests spent waiting for a rate limit token, per provider.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10), // 1ms … ~4.4min
+	}, []string{"provider"})
+
+	// ProviderHealthy is 1 while a provider passes its health probes and 0
+	// once it has been taken out of routing.
+	ProviderHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "provider_healthy",
+		Help:      "1 while a provider is healthy, 0 while it is excluded from routing.",
+	}, []string{"provider"})
+
+	// SkippedItems counts provider items dropped because they could not be
+	// decoded or normalized.
+	SkippedItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "skipped_items_total",
+		Help:      "Provider items skipped after a decode or normalize failure.",
+	}, []string{"kind", "stage"})
+
+	// PanicsRecovered counts panics recovered in background goroutines.
+	PanicsRecovered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "panics_recovered_total",
+		Help:      "Panics recovered in background goroutines, per component.",
+	}, []string{"component"})
+)
+
+var (
+	// RegressionsDetected counts expected-count regressions per source and chain.
+	RegressionsDetected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "regressions_detected_total",
+		Help:      "Transaction count drops detected, per source and chain.",
+	}, []string{"source", "chain"})
+
+	// RegressionActive counts, per chain, the addresses below their expected
+	// count. Addresses are left out of the labels to bound cardinality; they
+	// are in the logs and alerts.
+	RegressionActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "regression_active",
+		Help:      "Number of addresses currently below their expected transaction count on a chain.",
+	}, []string{"chain"})
+
+	// RegressionAlertsFailed counts alerts that could not be delivered, per sink.
+	RegressionAlertsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "regression_alerts_failed_total",
+		Help:      "Regression alerts that failed to deliver, per sink.",
+	}, []string{"sink"})
+)
+
+var (
+	// ArchivedPayloads counts raw provider responses stored in the archive.
+	ArchivedPayloads = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archived_payloads_total",
+		Help:      "Raw provider responses written to the archive.",
+	})
+
+	// ArchiveFailures counts payloads that were not archived, per reason.
+	ArchiveFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archive_failures_total",
+		Help:      "Raw provider responses not archived, per reason (queue_full, encode, upload).",
+	}, []string{"reason"})
+)
+
+var (
+	// BloomShortCircuits counts cache misses for addresses unknown to the
+	// Bloom filters that were not fetched: answered empty because recently
+	// fetched empty, or refused because the discovery budget is spent.
+	BloomShortCircuits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bloom_short_circuits_total",
+		Help:      "Requests for unknown addresses answered without querying providers.",
+	})
+
+	// CacheWarmRefreshes counts cache entries of hot addresses refreshed in
+	// the background, per result.
+	CacheWarmRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_warm_refreshes_total",
+		Help:      "Cache entries of hot addresses refreshed before expiry, per result (ok, error).",
+	}, []string{"result"})
+)
+
+var (
+	// WatchRefreshes counts background refreshes of watched addresses, per
+	// result.
+	WatchRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "watch_refreshes_total",
+		Help:      "Background refreshes of watched addresses, per result (ok, error, rate_limited).",
+	}, []string{"result"})
+
+	// WatchWebhooks counts new-activity notifications sent to watch
+	// webhooks, per result.
+	WatchWebhooks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "watch_webhooks_total",
+		Help:      "New-activity webhooks sent for watched addresses, per result (ok, error).",
+	}, []string{"result"})
+)
+
+var (
+	// IndexedBlocks counts blocks processed by the background indexer, per chain.
+	IndexedBlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "indexer_blocks_total",
+		Help:      "Blocks scanned by the background indexer, per chain.",
+	}, []string{"chain"})
+
+	// IndexerLag is how many blocks the indexer trails the node head by,
+	// per chain.
+	IndexerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexer_lag_blocks",
+		Help:      "Blocks between the node head and the last block indexed, per chain.",
+	}, []string{"chain"})
+)
+
+var (
+	// ShadowRequests counts requests mirrored to staging, per outcome.
+	ShadowRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shadow_requests_total",
+		Help:      "Requests mirrored to staging, per result (match, diff, error, dropped).",
+	}, []string{"result"})
+)
+
+// Handler serves the default Prometheus registry.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// RegisterRedisPool exports the connection pool statistics returned by stats
+// under the given client name. maxConns is the configured pool size.
+func RegisterRedisPool(name string, maxConns int, stats func() *redis.PoolStats) error {
+	return prometheus.Register(newRedisPoolCollector(name, maxConns, stats))
+}
+
+// redisPoolCollector reads go-redis pool statistics at scrape time.
+type redisPoolCollector struct {
+	maxConns int
+	stats    func() *redis.PoolStats
+
+	hits, misses, timeouts         *prometheus.Desc
+	totalConns, idleConns, maxDesc *prometheus.Desc
+	staleConns                     *prometheus.Desc
+}
+
+func newRedisPoolCollector(name string, maxConns int, stats func() *redis.PoolStats) *redisPoolCollector {
+	labels := prometheus.Labels{"client": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", metric), help, nil, labels)
+	}
+	return &redisPoolCollector{
+		maxConns:   maxConns,
+		stats:      stats,
+		hits:       desc("hits_total", "Times a free connection was found in the pool."),
+		misses:     desc("misses_total", "Times a free connection was not found in the pool."),
+		timeouts:   desc("timeouts_total", "Times a wait for a connection timed out."),
+		totalConns: desc("total_conns", "Connections currently in the pool."),
+		idleConns:  desc("idle_conns", "Idle connections in the pool."),
+		staleConns: desc("stale_conns_total", "Stale connections removed from the pool."),
+		maxDesc:    desc("max_conns", "Configured maximum number of connections per node."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.hits, c.misses, c.timeouts, c.totalConns, c.idleConns, c.staleConns, c.maxDesc} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	if s == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(s.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(s.StaleConns))
+	ch <- prometheus.MustNewConstMetric(c.maxDesc, prometheus.GaugeValue, float64(c.maxConns))
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisPoolCollector(t *testing.T) {
+	c := newRedisPoolCollector("test", 40, func() *redis.PoolStats {
+		return &redis.PoolStats{Hits: 7, Misses: 2, TotalConns: 5, IdleConns: 3}
+	})
+
+	expected := `
+# HELP tx_aggregator_redis_pool_idle_conns Idle connections in the pool.
+# TYPE tx_aggregator_redis_pool_idle_conns gauge
+tx_aggregator_redis_pool_idle_conns{client="test"} 3
+# HELP tx_aggregator_redis_pool_hits_total Times a free connection was found in the pool.
+# TYPE tx_aggregator_redis_pool_hits_total counter
+tx_aggregator_redis_pool_hits_total{client="test"} 7
+# HELP tx_aggregator_redis_pool_max_conns Configured maximum number of connections per node.
+# TYPE tx_aggregator_redis_pool_max_conns gauge
+tx_aggregator_redis_pool_max_conns{client="test"} 40
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"tx_aggregator_redis_pool_idle_conns",
+		"tx_aggregator_redis_pool_hits_total",
+		"tx_aggregator_redis_pool_max_conns",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, testutil.CollectAndCount(c))
+}
+
+func TestRedisPoolCollector_NilStats(t *testing.T) {
+	c := newRedisPoolCollector("test", 40, func() *redis.PoolStats { return nil })
+	assert.Equal(t, 0, testutil.CollectAndCount(c))
+}
+
+func TestProviderInFlight(t *testing.T) {
+	g := ProviderInFlight.WithLabelValues("test_provider")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	assert.Equal(t, float64(1), testutil.ToFloat64(g))
+	g.Dec()
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+// cacheHitLocal is the fiber.Ctx locals key set when a response was served
+// from the cache.
+const cacheHitLocal = "cacheHit"
+
+// timingsLocal is the fiber.Ctx locals key of the request's
+// *types.RequestTimings.
+const timingsLocal = "timings"
+
+// redactedQueryValue replaces secret query parameters in the access log.
+const redactedQueryValue = "********"
+
+// quietPaths are logged at debug level, so probes and scrapes do not drown
+// the access log.
+var quietPaths = map[string]bool{
+	"/health":  true,
+	"/metrics": true,
+}
+
+// AccessLog writes one structured line per request once it has been
+// handled: method, path, sanitized query, status, duration, response size,
+// cache hit flag and the name of the API key. It must run after RequestID
+// so the line carries the request ID.
+//
+// Requests taking at least log.slow_request_ms also log, at warn level, the
+// time spent in each phase recorded in the request's timings (see
+// TimingsFromCtx).
+func AccessLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		timings := &types.RequestTimings{}
+		c.Locals(timingsLocal, timings)
+		err := c.Next()
+		duration := time.Since(start)
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The error handler has not written the response yet.
+			status = fiber.StatusInternalServerError
+			if fe, ok := err.(*fiber.Error); ok {
+				status = fe.Code
+			}
+		}
+
+		log := logger.ForRequest(RequestIDFromCtx(c))
+		var event *zerolog.Event
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			event = log.Error()
+		case status >= fiber.StatusBadRequest:
+			event = log.Warn()
+		case quietPaths[c.Path()]:
+			event = log.Debug()
+		default:
+			event = log.Info()
+		}
+
+		event = event.
+			Str("method", c.Method()).
+			Str("path", c.Path()).
+			Str("query", sanitizeQuery(string(c.Request().URI().QueryString()))).
+			Int("status", status).
+			Dur("duration", duration).
+			Int("bytes", len(c.Response().Body())).
+			Bool("cache_hit", CacheHitFromCtx(c))
+		if key, ok := APIKeyFromCtx(c); ok {
+			event = event.Str("client_key", key.Name)
+		}
+		event.Msg("Request served")
+
+		if slow := config.Current().Log.SlowRequestMs; slow > 0 && duration >= time.Duration(slow)*time.Millisecond {
+			breakdown := zerolog.Dict()
+			for phase, d := range timings.Phases() {
+				breakdown = breakdown.Dur(phase, d)
+			}
+			log.Warn().
+				Str("method", c.Method()).
+				Str("path", c.Path()).
+				Int("status", status).
+				Dur("duration", duration).
+				Bool("cache_hit", CacheHitFromCtx(c)).
+				Dict("breakdown", breakdown).
+				Msg("Slow request")
+		}
+		return err
+	}
+}
+
+// TimingsFromCtx returns the timings of this request, nil when AccessLog
+// does not run.
+func TimingsFromCtx(c *fiber.Ctx) *types.RequestTimings {
+	t, _ := c.Locals(timingsLocal).(*types.RequestTimings)
+	return t
+}
+
+// MarkCacheHit records that the response of this request came from the
+// cache, for the access log.
+func MarkCacheHit(c *fiber.Ctx) {
+	c.Locals(cacheHitLocal, true)
+}
+
+// CacheHitFromCtx reports whether MarkCacheHit was called for this request.
+func CacheHitFromCtx(c *fiber.Ctx) bool {
+	hit, _ := c.Locals(cacheHitLocal).(bool)
+	return hit
+}
+
+// sanitizeQuery masks the values of credential parameters in a raw query
+// string. A query that cannot be parsed is dropped.
+func sanitizeQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		return ""
+	}
+	for k := range q {
+		if config.IsSecretKey(k) {
+			q.Set(k, redactedQueryValue)
+		}
+	}
+	return q.Encode()
+}
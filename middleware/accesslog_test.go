@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	orig := logger.Log
+	logger.Log = zerolog.New(&buf)
+	t.Cleanup(func() { logger.Log = orig })
+
+	setupAuthConfig(true)
+	defer setupAuthConfig(false)
+
+	app := fiber.New()
+	app.Use(RequestID(), AccessLog())
+	app.Get("/read", APIKeyAuth(), RequireRole(types.RoleRead), func(c *fiber.Ctx) error {
+		MarkCacheHit(c)
+		return c.SendString("cached")
+	})
+
+	req := httptest.NewRequest("GET", "/read?address=0xabc&api_key=s3cret&webhookUrl=https%3A%2F%2Fhooks.example%2Fx", nil)
+	req.Header.Set(types.DefaultAPIKeyHeader, "read-key")
+	req.Header.Set(types.RequestIDHeader, "req-1")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	line := lastLine(t, &buf)
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "req-1", line[logger.RequestIDField])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/read", line["path"])
+	assert.Equal(t, "address=0xabc&api_key=%2A%2A%2A%2A%2A%2A%2A%2A&webhookUrl=%2A%2A%2A%2A%2A%2A%2A%2A", line["query"])
+	assert.Equal(t, float64(200), line["status"])
+	assert.Equal(t, float64(len("cached")), line["bytes"])
+	assert.Equal(t, true, line["cache_hit"])
+	assert.Equal(t, "support", line["client_key"])
+
+	buf.Reset()
+	resp, err = app.Test(httptest.NewRequest("GET", "/read", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+	line = lastLine(t, &buf)
+	assert.Equal(t, "warn", line["level"])
+	assert.Equal(t, float64(401), line["status"])
+	assert.Equal(t, false, line["cache_hit"])
+	assert.NotContains(t, line, "client_key")
+}
+
+func TestAccessLogSlowRequest(t *testing.T) {
+	var buf bytes.Buffer
+	orig := logger.Log
+	logger.Log = zerolog.New(&buf)
+	t.Cleanup(func() { logger.Log = orig })
+
+	origCfg := config.Current()
+	cfg := origCfg
+	cfg.Log.SlowRequestMs = 20
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(origCfg) })
+
+	app := fiber.New()
+	app.Use(RequestID(), AccessLog())
+	app.Get("/fast", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		timings := TimingsFromCtx(c)
+		timings.Add(types.TimingCacheRead, 2*time.Millisecond)
+		timings.Add(types.TimingProviderPrefix+"ankr", 25*time.Millisecond)
+		time.Sleep(30 * time.Millisecond)
+		return c.SendString("ok")
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/fast", nil))
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "Slow request")
+
+	buf.Reset()
+	_, err = app.Test(httptest.NewRequest("GET", "/slow", nil))
+	assert.NoError(t, err)
+
+	line := lastLine(t, &buf)
+	assert.Equal(t, "warn", line["level"])
+	assert.Equal(t, "Slow request", line["message"])
+	assert.Equal(t, "/slow", line["path"])
+	assert.GreaterOrEqual(t, line["duration"], float64(30))
+	assert.Equal(t, map[string]interface{}{"cache_read": float64(2), "provider:ankr": float64(25)}, line["breakdown"])
+}
+
+// lastLine decodes the last JSON log line written to buf.
+func lastLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(lines[len(lines)-1], &line))
+	return line
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// setupAuthConfig enables auth with one key per role.
+func setupAuthConfig(enabled bool) {
+	cfg := config.Current()
+	cfg.Auth = types.AuthConfig{
+		Enabled: enabled,
+		Keys: []types.APIKeyConfig{
+			{Name: "support", Key: "read-key", Roles: []string{types.RoleRead}},
+			{Name: "tax", Key: "export-key", Roles: []string{types.RoleExport}},
+			{Name: "ops", Key: "admin-key", Roles: []string{types.RoleAdmin}},
+		},
+	}
+	config.SetCurrentConfig(cfg)
+}
+
+func setupApp() *fiber.App {
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Get("/read", APIKeyAuth(), RequireRole(types.RoleRead), ok)
+	app.Post("/admin", APIKeyAuth(), RequireRole(types.RoleAdmin), ok)
+	return app
+}
+
+func TestAuth(t *testing.T) {
+	setupAuthConfig(true)
+	defer setupAuthConfig(false)
+	app := setupApp()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{"missing key", "GET", "/read", "", fiber.StatusUnauthorized},
+		{"unknown key", "GET", "/read", "nope", fiber.StatusUnauthorized},
+		{"read key on read route", "GET", "/read", "read-key", fiber.StatusOK},
+		{"export key on read route", "GET", "/read", "export-key", fiber.StatusForbidden},
+		{"read key on admin route", "POST", "/admin", "read-key", fiber.StatusForbidden},
+		{"admin key on admin route", "POST", "/admin", "admin-key", fiber.StatusOK},
+		{"admin key implies read", "GET", "/read", "admin-key", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(types.DefaultAPIKeyHeader, tt.key)
+			}
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestAuth_Disabled(t *testing.T) {
+	setupAuthConfig(false)
+	app := setupApp()
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/admin", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+
+	"tx-aggregator/logger"
+)
+
+// Recover turns a panic in a later handler into an HTTP 500 and logs it at
+// error level with its stack, which also reports it to Sentry when enabled.
+// It must run after RequestID and AccessLog so the panic is correlated and
+// access-logged.
+func Recover() fiber.Handler {
+	return recover.New(recover.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c *fiber.Ctx, e interface{}) {
+			logger.ForRequest(RequestIDFromCtx(c)).Error().
+				Str("panic", fmt.Sprint(e)).
+				Str("path", c.Path()).
+				Str("stack", string(debug.Stack())).
+				Msg("💥 Panic while handling request")
+		},
+	})
+}
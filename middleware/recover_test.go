@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	orig := logger.Log
+	logger.Log = zerolog.New(&buf)
+	t.Cleanup(func() { logger.Log = orig })
+
+	app := fiber.New()
+	app.Use(RequestID(), Recover())
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		panic("provider table is nil")
+	})
+
+	req := httptest.NewRequest("GET", "/boom", nil)
+	req.Header.Set(types.RequestIDHeader, "req-panic")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	line := lastLine(t, &buf)
+	assert.Equal(t, "error", line["level"])
+	assert.Equal(t, "req-panic", line[logger.RequestIDField])
+	assert.Equal(t, "provider table is nil", line["panic"])
+	assert.Equal(t, "/boom", line["path"])
+	assert.Contains(t, line["stack"], "runtime/debug.Stack")
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tx-aggregator/types"
+)
+
+const (
+	// requestIDLocal is the fiber.Ctx locals key holding the request ID.
+	requestIDLocal = "requestId"
+	// maxRequestIDLength bounds client-supplied request IDs.
+	maxRequestIDLength = 128
+)
+
+// RequestID gives every request an ID: the client's X-Request-ID when it is
+// usable, a random one otherwise. The ID is stored in the request context,
+// echoed in the response header, tagged on the logs of the request and
+// forwarded to providers.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(types.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Locals(requestIDLocal, id)
+		c.Set(types.RequestIDHeader, id)
+		return c.Next()
+	}
+}
+
+// RequestIDFromCtx returns the ID of the request, or "" outside RequestID.
+func RequestIDFromCtx(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDLocal).(string)
+	return id
+}
+
+// validRequestID accepts non-empty IDs of up to maxRequestIDLength letters,
+// digits and "-_.:", so client input cannot break log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit hex identifier.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/types"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(RequestIDFromCtx(c)) })
+
+	tests := []struct {
+		name     string
+		header   string
+		accepted bool
+	}{
+		{"client id kept", "abc-123_x.y:z", true},
+		{"missing", "", false},
+		{"unsafe characters", "abc\" injected", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(types.RequestIDHeader, tt.header)
+			}
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+
+			id := resp.Header.Get(types.RequestIDHeader)
+			assert.Equal(t, id, string(body), "echoed and stored in the context")
+			if tt.accepted {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.Len(t, id, 32, "generated")
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package alchemy
+
+import (
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*AlchemyProvider)(nil)
+
+// AlchemyProvider serves one chain from Alchemy's alchemy_getAssetTransfers,
+// covering native, internal, ERC-20 and ERC-721 movements without an explorer.
+type AlchemyProvider struct {
+	chainID int64
+	cfg     types.AlchemyConfig
+	auth    utils.AuthStrategy // Optional request signing
+}
+
+// NewAlchemyProvider constructs a provider for one chain / one endpoint.
+func NewAlchemyProvider(chainID int64, cfg types.AlchemyConfig) *AlchemyProvider {
+	logger.Log.Info().
+		Str("chain", cfg.ChainName).
+		Msg("Initializing AlchemyProvider")
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+
+	auth, err := utils.NewAuthStrategy(cfg.Auth)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("chain", cfg.ChainName).Msg("Invalid Alchemy auth config, requests will be unsigned")
+	}
+
+	return &AlchemyProvider{
+		chainID: chainID,
+		cfg:     cfg,
+		auth:    auth,
+	}
+}
+
+// GetTransactions fetches transfers sent from and received by the address
+// concurrently (Alchemy cannot OR both filters in one query), removes the
+// duplicates of self transfers and returns a unified TransactionResponse.
+func (p *AlchemyProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := params.Address
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from Alchemy")
+
+	var outgoing, incoming []types.AlchemyTransfer
+
+	g := new(errgroup.Group)
+
+	// 1. Transfers sent by the address
+	g.Go(func() error {
+		transfers, err := p.fetchAssetTransfers(params.RequestID, types.AlchemyAssetTransfersParams{FromAddress: address}, maxPages)
+		if err != nil {
+			return err
+		}
+		outgoing = transfers
+		return nil
+	})
+
+	// 2. Transfers received by the address
+	g.Go(func() error {
+		transfers, err := p.fetchAssetTransfers(params.RequestID, types.AlchemyAssetTransfersParams{ToAddress: address}, maxPages)
+		if err != nil {
+			return err
+		}
+		incoming = transfers
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Msg("Alchemy fetch failed")
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(outgoing)+len(incoming))
+	var all []types.Transaction
+	for _, t := range append(outgoing, incoming...) {
+		if _, dup := seen[t.UniqueID]; dup {
+			continue
+		}
+		seen[t.UniqueID] = struct{}{}
+		all = append(all, p.transformTransfer(t, address))
+	}
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("outgoing", len(outgoing)).
+		Int("incoming", len(incoming)).
+		Int("total", len(all)).
+		Msg("Alchemy provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+func (p *AlchemyProvider) sendRequest(req interface{}, out interface{}, opts ...utils.RequestOption) error {
+	opts = append([]utils.RequestOption{
+		utils.WithAuth(p.auth),
+		utils.WithProviderKey(provider.Key("alchemy", p.cfg.ChainName)),
+	}, opts...)
+	return utils.DoHttpRequestWithLogging(
+		"POST", "alchemy.assetTransfers", p.cfg.URL, req,
+		map[string]string{"Content-Type": "application/json"},
+		out,
+		opts...,
+	)
+}
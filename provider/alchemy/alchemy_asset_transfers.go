@@ -0,0 +1,154 @@
+package alchemy
+
+import (
+	"fmt"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	// defaultPageSize is Alchemy's maximum maxCount (0x3e8).
+	defaultPageSize = 1000
+	// defaultMaxPages caps pageKey pagination when max_pages is unset.
+	defaultMaxPages = 10
+)
+
+// categories are the transfer kinds requested from Alchemy.
+var categories = []string{
+	types.AlchemyCategoryExternal,
+	types.AlchemyCategoryInternal,
+	types.AlchemyCategoryERC20,
+	types.AlchemyCategoryERC721,
+}
+
+// fetchAssetTransfers pages through alchemy_getAssetTransfers for one
+// direction (filter carries FromAddress or ToAddress) by following pageKey
+// until it is empty or maxPages is reached. A failure on a later page keeps
+// the transfers collected so far.
+func (p *AlchemyProvider) fetchAssetTransfers(requestID string, filter types.AlchemyAssetTransfersParams, maxPages int64) ([]types.AlchemyTransfer, error) {
+	pageSize := p.cfg.RequestPageSize
+	if pageSize <= 0 || pageSize > defaultPageSize {
+		pageSize = defaultPageSize
+	}
+
+	filter.FromBlock = "0x0"
+	filter.ToBlock = "latest"
+	filter.Category = categories
+	filter.Order = "desc"
+	filter.WithMetadata = true
+	filter.MaxCount = fmt.Sprintf("0x%x", pageSize)
+
+	var all []types.AlchemyTransfer
+	for page := int64(0); page < maxPages; page++ {
+		resp, err := p.fetchAssetTransfersPage(requestID, filter)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Int64("page", page+1).
+				Msg("Alchemy pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, resp.Result.Transfers...)
+
+		if resp.Result.PageKey == "" {
+			break
+		}
+		filter.PageKey = resp.Result.PageKey
+	}
+
+	logger.Log.Debug().
+		Str("chain", p.cfg.ChainName).
+		Int("transfers", len(all)).
+		Msg("Alchemy pagination finished")
+	return all, nil
+}
+
+// fetchAssetTransfersPage requests a single page of alchemy_getAssetTransfers.
+func (p *AlchemyProvider) fetchAssetTransfersPage(requestID string, filter types.AlchemyAssetTransfersParams) (*types.AlchemyAssetTransfersResponse, error) {
+	req := types.AlchemyRequest{
+		JSONRPC: "2.0",
+		Method:  "alchemy_getAssetTransfers",
+		Params:  []interface{}{filter},
+		ID:      1,
+	}
+
+	var resp types.AlchemyAssetTransfersResponse
+	if err := p.sendRequest(req, &resp, utils.WithRequestID(requestID)); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		logger.Log.Error().
+			Int("error_code", resp.Error.Code).
+			Str("error_message", resp.Error.Message).
+			Str("chain", p.cfg.ChainName).
+			Msg("Alchemy API returned an error in asset transfers response")
+		return nil, resp.Error
+	}
+	return &resp, nil
+}
+
+// transformTransfer converts one Alchemy transfer into a types.Transaction.
+// Alchemy only reports transfers of successful transactions, and does not
+// return gas or transaction index data.
+func (p *AlchemyProvider) transformTransfer(t types.AlchemyTransfer, address string) types.Transaction {
+	height := utils.ParseStringToInt64OrDefault(t.BlockNum, 0)
+	timestamp := utils.ParseBlockscoutTimestampToUnix(t.Metadata.BlockTimestamp)
+
+	tranType := types.TransTypeOut
+	if strings.EqualFold(t.To, address) {
+		tranType = types.TransTypeIn
+	}
+
+	tx := types.Transaction{
+		ChainID:      p.chainID,
+		State:        types.TxStateSuccess,
+		Height:       height,
+		Hash:         t.Hash,
+		FromAddress:  t.From,
+		ToAddress:    t.To,
+		Type:         types.TxTypeTransfer,
+		CreatedTime:  timestamp,
+		ModifiedTime: timestamp,
+		TranType:     tranType,
+	}
+
+	switch t.Category {
+	case types.AlchemyCategoryExternal, types.AlchemyCategoryInternal:
+		tx.CoinType = types.CoinTypeNative
+		tx.Decimals = utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+		tx.TokenDisplayName, _ = utils.NativeTokenByChainID(p.chainID)
+		if t.Category == types.AlchemyCategoryInternal {
+			tx.Type = types.TxTypeInternal
+			tx.CoinType = types.CoinTypeInternal
+		}
+	case types.AlchemyCategoryERC721:
+		tx.CoinType = types.CoinTypeERC721
+		tx.TokenAddress = t.RawContract.Address
+		tx.TokenDisplayName = t.Asset
+		if id, err := utils.NormalizeNumericString(t.ERC721TokenID); err == nil {
+			tx.TokenIDRaw = id
+			tx.TokenID = utils.TokenIDToInt64(id)
+		}
+		tx.Balance = "1"
+		tx.Amount = "1"
+		return tx
+	default: // erc20
+		tx.CoinType = types.CoinTypeToken
+		tx.TokenAddress = t.RawContract.Address
+		tx.TokenDisplayName = t.Asset
+		tx.Decimals = utils.ParseStringToInt64OrDefault(t.RawContract.Decimal, 0)
+	}
+
+	if raw, err := utils.NormalizeNumericString(t.RawContract.Value); err == nil {
+		tx.Balance = raw
+		tx.Amount = utils.DivideByDecimals(raw, int(tx.Decimals))
+	}
+	return tx
+}
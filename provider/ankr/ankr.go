@@ -3,6 +3,8 @@ package ankr
 import (
 	"fmt"
 	"strings"
+	"tx-aggregator/config"
+	"tx-aggregator/freshness"
 	"tx-aggregator/logger"
 	"tx-aggregator/provider"
 	"tx-aggregator/types"
@@ -46,6 +48,13 @@ func (a *AnkrProvider) GetTransactions(params *types.TransactionQueryParams) (*t
 		tokenTxs  []types.Transaction
 	)
 
+	// Refresh the indexer lag hint in the background, at most every probe interval
+	if freshness.ShouldProbe("ankr") {
+		if blockchains, err := utils.ResolveAnkrBlockchains(nil); err == nil {
+			go a.probeIndexerLag(blockchains)
+		}
+	}
+
 	// Use an errgroup to concurrently fetch and transform both types of transactions
 	g := new(errgroup.Group)
 
@@ -84,8 +93,10 @@ func (a *AnkrProvider) GetTransactions(params *types.TransactionQueryParams) (*t
 		return nil, err
 	}
 
-	// Patch token transfers using matching normal transactions
+	// Patch token transfers using matching normal transactions, then from
+	// the node for transactions the address did not send
 	tokenTxs = utils.PatchTokenTransactionsWithNormalTxInfo(tokenTxs, normalTxs)
+	fillTokenTxsFromRPC(params.RequestID, tokenTxs)
 
 	// Merge the final results
 	transactions := append(normalTxs, tokenTxs...)
@@ -106,10 +117,61 @@ func (a *AnkrProvider) GetTransactions(params *types.TransactionQueryParams) (*t
 	}, nil
 }
 
-func (p *AnkrProvider) sendRequest(requestBody interface{}, result interface{}, label string) error {
+// defaultMaxPages caps nextPageToken pagination when ankr.max_pages is unset.
+const defaultMaxPages = 10
+
+// fetchAllPages calls fetchPage, following nextPageToken, until no token is
+// returned, ankr.max_pages pages (or the params.MaxPages override) have been
+// read or ankr.max_transactions items have been collected. A failure on a later
+// page keeps the items collected so far.
+func fetchAllPages[T any](params *types.TransactionQueryParams, label string, fetchPage func(pageToken string) ([]T, string, error)) ([]T, error) {
+	cfg := config.Current().Ankr
+	maxPages := int(provider.MaxPages(params, int64(cfg.MaxPages), defaultMaxPages))
+
+	var (
+		all       []T
+		pageToken string
+		pages     int
+	)
+	for pages < maxPages {
+		items, next, err := fetchPage(pageToken)
+		if err != nil {
+			if pages == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("method", label).
+				Int("page", pages+1).
+				Msg("Ankr pagination aborted, keeping earlier pages")
+			break
+		}
+		pages++
+		all = append(all, items...)
+
+		if cfg.MaxTransactions > 0 && len(all) >= cfg.MaxTransactions {
+			all = all[:cfg.MaxTransactions]
+			break
+		}
+		if next == "" {
+			break
+		}
+		pageToken = next
+	}
+
+	logger.Log.Debug().
+		Str("method", label).
+		Int("pages", pages).
+		Int("items", len(all)).
+		Msg("Ankr pagination finished")
+	return all, nil
+}
+
+func (p *AnkrProvider) sendRequest(requestBody interface{}, result interface{}, label string, opts ...utils.RequestOption) error {
 	fullURL := fmt.Sprintf("%s/%s", p.url, p.apiKey)
+	opts = append([]utils.RequestOption{utils.WithProviderKey("ankr")}, opts...)
 	return utils.DoHttpRequestWithLogging("POST", "ankr."+label, fullURL, requestBody, map[string]string{
 		"Content-Type": "application/json",
 		"x-api-key":    p.apiKey,
-	}, result)
+	}, result, opts...)
 }
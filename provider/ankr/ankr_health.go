@@ -0,0 +1,27 @@
+package ankr
+
+import (
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+var _ provider.HealthChecker = (*AnkrProvider)(nil)
+
+// HealthCheck asks the Advanced API for the stats of one blockchain, a cheap
+// call that fails when the API or the key is unusable.
+func (p *AnkrProvider) HealthCheck() error {
+	requestBody := types.AnkrTransactionRequest{
+		JSONRPC: "2.0",
+		Method:  "ankr_getBlockchainStats",
+		Params:  map[string]interface{}{"blockchain": "eth"},
+		ID:      1,
+	}
+	var result types.AnkrBlockchainStatsResponse
+	if err := p.sendRequest(requestBody, &result, "healthCheck"); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
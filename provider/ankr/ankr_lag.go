@@ -0,0 +1,56 @@
+package ankr
+
+import (
+	"fmt"
+	"strings"
+	"tx-aggregator/freshness"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// probeIndexerLag compares the newest block indexed by the Advanced API
+// (ankr_getBlockchainStats) with the head of Ankr's node RPC for each of the
+// given blockchains and records the difference. Failures are only logged:
+// the lag is a hint, never a reason to fail a request.
+func (p *AnkrProvider) probeIndexerLag(blockchains []string) {
+	requestBody := types.AnkrTransactionRequest{
+		JSONRPC: "2.0",
+		Method:  "ankr_getBlockchainStats",
+		Params:  map[string]interface{}{"blockchain": blockchains},
+		ID:      1,
+	}
+	var result types.AnkrBlockchainStatsResponse
+	if err := p.sendRequest(requestBody, &result, "blockchainStats"); err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to probe Ankr indexed head")
+		return
+	}
+	if result.Error != nil {
+		logger.Log.Warn().Err(result.Error).Msg("Failed to probe Ankr indexed head")
+		return
+	}
+
+	for _, s := range result.Result.Stats {
+		chainID, err := utils.AnkrChainIDByName(s.Blockchain)
+		if err != nil {
+			continue
+		}
+		head, err := utils.RPCBlockNumber("ankr.rpcBlockNumber", p.nodeURL(s.Blockchain))
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("blockchain", s.Blockchain).Msg("Failed to probe Ankr node head")
+			continue
+		}
+		freshness.Record(chainID, head, s.LatestBlockNumber)
+		logger.Log.Debug().
+			Str("blockchain", s.Blockchain).
+			Int64("head", head).
+			Int64("indexed", s.LatestBlockNumber).
+			Msg("Recorded Ankr indexer lag")
+	}
+}
+
+// nodeURL returns the node RPC endpoint of an Ankr blockchain, which sits
+// next to the multichain endpoint: https://rpc.ankr.com/<blockchain>/<key>.
+func (p *AnkrProvider) nodeURL(blockchain string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(p.url, "/multichain"), blockchain, p.apiKey)
+}
@@ -33,21 +33,51 @@ func (p *AnkrProvider) GetTransactionsByAddress(params *types.TransactionQueryPa
 		Int("page_size", config.Current().Ankr.RequestPageSize).
 		Msg("Fetching normal transactions from Ankr")
 
+	txs, err := fetchAllPages(params, "ankr_getTransactionsByAddress", func(pageToken string) ([]types.AnkrTransaction, string, error) {
+		page, err := p.fetchTransactionsPage(params.RequestID, address, blockchains, pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Result.Transactions, page.Result.NextPageToken, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.AnkrTransactionResponse
+	result.JSONRPC = "2.0"
+	result.ID = 1
+	result.Result.Transactions = txs
+
+	logger.Log.Debug().
+		Str("address", address).
+		Int("tx_count", len(result.Result.Transactions)).
+		Msg("Successfully fetched normal transactions")
+	return &result, nil
+}
+
+// fetchTransactionsPage requests a single page of ankr_getTransactionsByAddress.
+// An empty pageToken requests the first page.
+func (p *AnkrProvider) fetchTransactionsPage(requestID, address string, blockchains []string, pageToken string) (*types.AnkrTransactionResponse, error) {
+	params := map[string]interface{}{
+		"blockchain":  blockchains,
+		"includeLogs": config.Current().Ankr.IncludeLogs,
+		"descOrder":   config.Current().Ankr.DescOrder,
+		"pageSize":    config.Current().Ankr.RequestPageSize,
+		"address":     address,
+	}
+	if pageToken != "" {
+		params["pageToken"] = pageToken
+	}
 	requestBody := types.AnkrTransactionRequest{
 		JSONRPC: "2.0",
 		Method:  "ankr_getTransactionsByAddress",
-		Params: map[string]interface{}{
-			"blockchain":  blockchains,
-			"includeLogs": config.Current().Ankr.IncludeLogs,
-			"descOrder":   config.Current().Ankr.DescOrder,
-			"pageSize":    config.Current().Ankr.RequestPageSize,
-			"address":     address,
-		},
-		ID: 1,
+		Params:  params,
+		ID:      1,
 	}
 
 	var result types.AnkrTransactionResponse
-	if err := p.sendRequest(requestBody, &result, "normalTx"); err != nil {
+	if err := p.sendRequest(requestBody, &result, "normalTx", utils.WithRequestID(requestID)); err != nil {
 		logger.Log.Error().
 			Err(err).
 			Str("address", address).
@@ -63,11 +93,6 @@ func (p *AnkrProvider) GetTransactionsByAddress(params *types.TransactionQueryPa
 			Msg("Ankr API returned an error in normal transactions response")
 		return nil, result.Error // OK now, since it implements error
 	}
-
-	logger.Log.Debug().
-		Str("address", address).
-		Int("tx_count", len(result.Result.Transactions)).
-		Msg("Successfully fetched normal transactions")
 	return &result, nil
 }
 
@@ -87,6 +112,7 @@ func (a *AnkrProvider) transformAnkrNormalTx(resp *types.AnkrTransactionResponse
 
 	for _, tx := range resp.Result.Transactions {
 		chainID, _ := utils.AnkrChainIDByName(tx.Blockchain)
+		nativeDecimals := utils.NativeDecimalsByChainID(chainID, types.NativeDefaultDecimals)
 		height := utils.ParseStringToInt64OrDefault(tx.BlockNumber, 0)
 		timestamp := utils.ParseStringToInt64OrDefault(tx.Timestamp, 0)
 		txIndex := utils.ParseStringToInt64OrDefault(tx.TransactionIndex, 0)
@@ -101,7 +127,7 @@ func (a *AnkrProvider) transformAnkrNormalTx(resp *types.AnkrTransactionResponse
 
 		// Normalize values
 		amountRaw, err := utils.NormalizeNumericString(tx.Value)
-		amount := utils.DivideByDecimals(amountRaw, types.NativeDefaultDecimals)
+		amount := utils.DivideByDecimals(amountRaw, int(nativeDecimals))
 		gasLimit, err := utils.NormalizeNumericString(tx.Gas)
 		gasUsed, err := utils.NormalizeNumericString(tx.GasUsed)
 		gasPrice, err := utils.NormalizeNumericString(tx.GasPrice)
@@ -119,6 +145,33 @@ func (a *AnkrProvider) transformAnkrNormalTx(resp *types.AnkrTransactionResponse
 		if txType == types.TxTypeApprove {
 			approveShow = approveValue
 		}
+		// Swapping, then wrapping or unwrapping the native coin, take
+		// precedence
+		var swap *types.SwapInfo
+		for _, l := range tx.Logs {
+			if protocol, ok := utils.DetectSwapEvent(l.Topics); ok {
+				txType, tokenAddr, approveShow = types.TxTypeSwap, "", ""
+				swap = &types.SwapInfo{Protocol: protocol}
+				break
+			}
+			if wrapType, ok := utils.DetectWrapEvent(chainID, l.Address, l.Topics); ok {
+				txType, tokenAddr, approveShow = wrapType, strings.ToLower(l.Address), ""
+			}
+		}
+		// A batch call is broken down into the events it emitted
+		var actions []types.SubAction
+		if utils.IsBatchCall(tx.Input) {
+			for _, l := range tx.Logs {
+				actions = append(actions, utils.LogSubActions(chainID, l.Address, l.Topics, l.Data)...)
+			}
+		}
+		// The last Safe execution event is that of the outermost Safe
+		var safe *types.SafeExecution
+		for _, l := range tx.Logs {
+			if exec, ok := utils.DetectSafeExecution(l.Address, l.Topics, l.Data); ok {
+				safe = exec
+			}
+		}
 
 		// Determine transaction direction
 		tranType := types.TransTypeOut
@@ -155,12 +208,18 @@ func (a *AnkrProvider) transformAnkrNormalTx(resp *types.AnkrTransactionResponse
 			Type:             txType,
 			CoinType:         types.CoinTypeNative,
 			TokenDisplayName: nativeTokenName,
-			Decimals:         types.NativeDefaultDecimals,
+			Decimals:         nativeDecimals,
 			CreatedTime:      timestamp,
 			ModifiedTime:     timestamp,
 			TranType:         tranType,
 			ApproveShow:      approveShow,
 			IconURL:          "",
+			Swap:             swap,
+			Actions:          actions,
+			Safe:             safe,
+		}
+		if config.Current().Ankr.IncludeLogs {
+			transaction.Enrichment |= types.EnrichmentLogsScanned
 		}
 
 		transactions = append(transactions, transaction)
@@ -33,20 +33,50 @@ func (p *AnkrProvider) GetTokenTransfers(params *types.TransactionQueryParams) (
 		Int("page_size", config.Current().Ankr.RequestPageSize).
 		Msg("Fetching token transfers from Ankr")
 
+	transfers, err := fetchAllPages(params, "ankr_getTokenTransfers", func(pageToken string) ([]types.TokenTransfer, string, error) {
+		page, err := p.fetchTokenTransfersPage(params.RequestID, address, blockchains, pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Result.Transfers, page.Result.NextPageToken, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result types.AnkrTokenTransferResponse
+	result.JSONRPC = "2.0"
+	result.ID = 1
+	result.Result.Transfers = transfers
+
+	logger.Log.Debug().
+		Str("address", address).
+		Int("transfer_count", len(result.Result.Transfers)).
+		Msg("Successfully fetched token transfers")
+	return &result, nil
+}
+
+// fetchTokenTransfersPage requests a single page of ankr_getTokenTransfers.
+// An empty pageToken requests the first page.
+func (p *AnkrProvider) fetchTokenTransfersPage(requestID, address string, blockchains []string, pageToken string) (*types.AnkrTokenTransferResponse, error) {
+	params := map[string]interface{}{
+		"blockchain": blockchains,
+		"descOrder":  config.Current().Ankr.DescOrder,
+		"pageSize":   config.Current().Ankr.RequestPageSize,
+		"address":    address,
+	}
+	if pageToken != "" {
+		params["pageToken"] = pageToken
+	}
 	requestBody := types.AnkrTransactionRequest{
 		JSONRPC: "2.0",
 		Method:  "ankr_getTokenTransfers",
-		Params: map[string]interface{}{
-			"blockchain": blockchains,
-			"descOrder":  config.Current().Ankr.DescOrder,
-			"pageSize":   config.Current().Ankr.RequestPageSize,
-			"address":    address,
-		},
-		ID: 1,
+		Params:  params,
+		ID:      1,
 	}
 
 	var result types.AnkrTokenTransferResponse
-	if err := p.sendRequest(requestBody, &result, "tokenTx"); err != nil {
+	if err := p.sendRequest(requestBody, &result, "tokenTx", utils.WithRequestID(requestID)); err != nil {
 		logger.Log.Error().
 			Err(err).
 			Str("address", address).
@@ -62,11 +92,6 @@ func (p *AnkrProvider) GetTokenTransfers(params *types.TransactionQueryParams) (
 			Msg("Ankr API returned an error in token transfer response")
 		return nil, result.Error // OK now, since it implements error
 	}
-
-	logger.Log.Debug().
-		Str("address", address).
-		Int("transfer_count", len(result.Result.Transfers)).
-		Msg("Successfully fetched token transfers")
 	return &result, nil
 }
 
@@ -114,10 +139,10 @@ func (a *AnkrProvider) transformAnkrTokenTransfers(
 		transaction := types.Transaction{
 			ChainID:          chainID,
 			TokenID:          0,
-			State:            types.TxStateSuccess, // always mark as success (API limitation)
+			State:            types.TxStateSuccess, // not provided, see fillTokenTxsFromRPC
 			Height:           tr.BlockHeight,
 			Hash:             tr.TransactionHash,
-			BlockHash:        "", // not available from API
+			BlockHash:        "", // not available from API, see fillTokenTxsFromRPC
 			FromAddress:      tr.FromAddress,
 			ToAddress:        tr.ToAddress,
 			TokenAddress:     tr.ContractAddress,
@@ -147,3 +172,70 @@ func (a *AnkrProvider) transformAnkrTokenTransfers(
 
 	return transactions
 }
+
+// fillTokenTxsFromRPC fills in the block hash, nonce, transaction index and
+// status of the token transfers that no normal transaction of the address
+// patched (their BlockHash is still empty), from the transactions and
+// receipts of the JSON-RPC endpoint of their chain. Ankr does not report the
+// status of token transfers: rows without a receipt stay successful, and so
+// do those of chains without an endpoint.
+func fillTokenTxsFromRPC(requestID string, tokenTxs []types.Transaction) {
+	unseen := make(map[int64][]string) // chain ID → hashes
+	seen := make(map[string]bool)
+	for _, tx := range tokenTxs {
+		if tx.BlockHash != "" || seen[tx.Hash] {
+			continue
+		}
+		seen[tx.Hash] = true
+		unseen[tx.ChainID] = append(unseen[tx.ChainID], tx.Hash)
+	}
+
+	for chainID, hashes := range unseen {
+		chainName, err := utils.ChainNameByID(chainID)
+		if err != nil {
+			continue
+		}
+		url, ok := utils.ChainRPCURL(chainName)
+		if !ok {
+			continue
+		}
+		rpcTxs, err := utils.RPCTransactionsByHash("ankr.rpcTxByHash", url, hashes, utils.WithRequestID(requestID))
+		if err != nil {
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", chainName).
+				Int("hashes", len(hashes)).
+				Msg("Failed to fetch token transfer transactions from RPC")
+		}
+
+		receipts, err := utils.RPCTransactionReceipts("ankr.rpcReceipts", url, hashes, utils.WithRequestID(requestID))
+		if err != nil {
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", chainName).
+				Int("hashes", len(hashes)).
+				Msg("Failed to fetch token transfer receipts from RPC")
+		}
+
+		used := make(map[string]bool)
+		for i, tx := range tokenTxs {
+			if tx.ChainID != chainID || tx.BlockHash != "" {
+				continue
+			}
+			if receipt, ok := receipts[tx.Hash]; ok && receipt.Status != "" {
+				tokenTxs[i].State = types.TxStateFail
+				if receipt.Status == "0x1" {
+					tokenTxs[i].State = types.TxStateSuccess
+				}
+				tokenTxs[i].LogIndex = utils.TransferLogIndex(tx, receipt.Logs, used)
+			}
+			if rpcTx, ok := rpcTxs[tx.Hash]; ok {
+				tokenTxs[i].BlockHash = rpcTx.BlockHash
+				tokenTxs[i].TxIndex = utils.ParseStringToInt64OrDefault(rpcTx.TransactionIndex, 0)
+				if nonce, err := utils.NormalizeNumericString(rpcTx.Nonce); err == nil {
+					tokenTxs[i].Nonce = nonce
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package aptos serves Aptos networks through the Aptos indexer GraphQL API.
+// The indexer reports per-account balance changes (withdrawals, deposits and
+// gas fees) rather than transfers, so transfers are rebuilt by pairing each
+// deposit with a withdrawal of the same asset in the same transaction, in
+// event order. Coins (v1) and fungible assets (v2) are handled alike; APT has
+// 8 decimals.
+//
+// Aptos has no position of a transaction within a block. Every transaction
+// has a global, strictly increasing version instead, which is reported as
+// both txIndex (so that rows sort as on chain) and hash (explorers accept
+// either).
+package aptos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*AptosProvider)(nil)
+
+const (
+	defaultURL = "https://api.mainnet.aptoslabs.com/v1/graphql"
+	// defaultPageSize is used when request_page_size is unset.
+	defaultPageSize = 100
+	// defaultMaxPages caps offset pagination when max_pages is unset.
+	defaultMaxPages = 10
+	// versionBatchSize bounds the versions looked up per counterparty query.
+	versionBatchSize = 50
+	// aptDecimals is the number of decimals of APT (1 APT = 1e8 octas).
+	aptDecimals = 8
+)
+
+// AptosProvider serves one Aptos network.
+type AptosProvider struct {
+	chainID int64
+	cfg     types.AptosConfig
+}
+
+// NewAptosProvider constructs a provider for one Aptos network.
+func NewAptosProvider(chainID int64, cfg types.AptosConfig) *AptosProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.RequestPageSize <= 0 {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Msg("Initializing AptosProvider")
+
+	return &AptosProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions reads the account's own balance changes, then every
+// balance change of the same transactions to find the counterparties, and
+// returns the transfers that involve the account.
+func (p *AptosProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := canonical(params.Address)
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from Aptos indexer")
+
+	// 1. The account's own withdrawals, deposits and gas fees
+	own, err := p.fetchAccountActivities(params.RequestID, address, maxPages)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Aptos indexer fetch failed")
+		return nil, err
+	}
+
+	// 2. Both sides of every transaction that moved the account's assets
+	related, err := p.fetchTransactionActivities(params.RequestID, transferVersions(own))
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Aptos indexer fetch failed")
+		return nil, err
+	}
+
+	all := p.transformActivities(own, related, address)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("activities", len(own)).
+		Int("total", len(all)).
+		Msg("Aptos provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchAllPages reads consecutive offset pages until a short page or
+// maxPages is reached. A failure on a later page keeps the items collected
+// so far.
+func fetchAllPages[T any](p *AptosProvider, maxPages int64, label string, fetchPage func(offset int64) ([]T, error)) ([]T, error) {
+	var all []T
+	for page := int64(0); page < maxPages; page++ {
+		items, err := fetchPage(page * p.cfg.RequestPageSize)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", label).
+				Int64("page", page+1).
+				Msg("Aptos indexer pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if int64(len(items)) < p.cfg.RequestPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// query runs one GraphQL query against the indexer and decodes its data.
+func (p *AptosProvider) query(label, query string, variables map[string]interface{}, out interface{}, opts ...utils.RequestOption) error {
+	req := types.GraphQLRequest{Query: query, Variables: variables}
+	headers := map[string]string{"Content-Type": "application/json"}
+	if p.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.cfg.APIKey
+	}
+
+	opts = append([]utils.RequestOption{utils.WithProviderKey(provider.Key("aptos", p.cfg.ChainName))}, opts...)
+	var resp types.GraphQLResponse
+	if err := utils.DoHttpRequestWithLogging("POST", label, p.cfg.URL, req, headers, &resp, opts...); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	if len(resp.Data) == 0 {
+		return errors.New("aptos indexer returned no data")
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("decode aptos indexer data: %w", err)
+	}
+	return nil
+}
+
+// canonical returns the long form of an account address, or the input
+// unchanged when it is not one.
+func canonical(addr string) string {
+	if c, ok := utils.AptosToCanonical(addr); ok {
+		return c
+	}
+	return addr
+}
@@ -0,0 +1,103 @@
+package aptos
+
+import (
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// activityFields is the selection shared by both activity queries.
+const activityFields = `
+    transaction_version
+    event_index
+    block_height
+    owner_address
+    amount
+    asset_type
+    type
+    is_gas_fee
+    is_transaction_success
+    transaction_timestamp
+    metadata { symbol decimals }`
+
+// accountActivitiesQuery lists one account's balance changes, newest first.
+const accountActivitiesQuery = `query AccountActivities($address: String!, $limit: Int!, $offset: Int!) {
+  fungible_asset_activities(
+    where: {owner_address: {_eq: $address}}
+    order_by: [{transaction_version: desc}, {event_index: desc}]
+    limit: $limit
+    offset: $offset
+  ) {` + activityFields + `
+  }
+}`
+
+// transactionActivitiesQuery lists every non-fee balance change of the given
+// transactions in event order.
+const transactionActivitiesQuery = `query TransactionActivities($versions: [bigint!]!, $limit: Int!, $offset: Int!) {
+  fungible_asset_activities(
+    where: {transaction_version: {_in: $versions}, is_gas_fee: {_eq: false}}
+    order_by: [{transaction_version: asc}, {event_index: asc}]
+    limit: $limit
+    offset: $offset
+  ) {` + activityFields + `
+  }
+}`
+
+// fetchAccountActivities reads all pages of the account's balance changes.
+func (p *AptosProvider) fetchAccountActivities(requestID, address string, maxPages int64) ([]types.AptosActivity, error) {
+	return fetchAllPages(p, maxPages, "account_activities", func(offset int64) ([]types.AptosActivity, error) {
+		var data types.AptosActivitiesData
+		vars := map[string]interface{}{
+			"address": address,
+			"limit":   p.cfg.RequestPageSize,
+			"offset":  offset,
+		}
+		if err := p.query("aptos.account_activities", accountActivitiesQuery, vars, &data, utils.WithRequestID(requestID)); err != nil {
+			return nil, err
+		}
+		return data.Activities, nil
+	})
+}
+
+// fetchTransactionActivities reads the balance changes of all accounts in
+// the given transactions, versionBatchSize versions per query. Each batch is
+// read until a short page, so a transaction is never cut in half.
+func (p *AptosProvider) fetchTransactionActivities(requestID string, versions []string) ([]types.AptosActivity, error) {
+	var all []types.AptosActivity
+	for start := 0; start < len(versions); start += versionBatchSize {
+		batch := versions[start:min(start+versionBatchSize, len(versions))]
+		for offset := int64(0); ; offset += p.cfg.RequestPageSize {
+			var data types.AptosActivitiesData
+			vars := map[string]interface{}{
+				"versions": batch,
+				"limit":    p.cfg.RequestPageSize,
+				"offset":   offset,
+			}
+			if err := p.query("aptos.transaction_activities", transactionActivitiesQuery, vars, &data, utils.WithRequestID(requestID)); err != nil {
+				return nil, err
+			}
+			all = append(all, data.Activities...)
+			if int64(len(data.Activities)) < p.cfg.RequestPageSize {
+				break
+			}
+		}
+	}
+	return all, nil
+}
+
+// transferVersions returns the distinct versions of the transactions in
+// which the account's assets moved, in first-seen order.
+func transferVersions(own []types.AptosActivity) []string {
+	seen := make(map[string]struct{}, len(own))
+	var versions []string
+	for _, a := range own {
+		v := a.TransactionVersion.String()
+		if a.IsGasFee || v == "" {
+			continue
+		}
+		if _, dup := seen[v]; !dup {
+			seen[v] = struct{}{}
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,218 @@
+package aptos
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// aptosTimestampLayout is the indexer's timestamp format, UTC without a zone.
+const aptosTimestampLayout = "2006-01-02T15:04:05.999999"
+
+// Long forms of the two identifiers of APT: the coin type and its paired
+// fungible asset metadata address.
+const (
+	aptCoinType = "0x0000000000000000000000000000000000000000000000000000000000000001::aptos_coin::AptosCoin"
+	aptFAAsset  = "0x000000000000000000000000000000000000000000000000000000000000000a"
+)
+
+// transfer is a withdrawal paired with a deposit. From is empty for a mint
+// and To is empty for a burn.
+type transfer struct {
+	from, to string
+	amount   string
+	asset    types.AptosActivity // The event that carries asset and tx data
+}
+
+// transformActivities rebuilds the transfers of every transaction in related
+// and converts those that involve address into rows. The gas fee, taken from
+// the account's own activities, is reported as gasUsed with gasPrice 1 on the
+// rows the account sent; transactions in which the account only paid gas get
+// a zero-value native row.
+func (p *AptosProvider) transformActivities(own, related []types.AptosActivity, address string) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	fees := make(map[string]types.AptosActivity)
+	for _, a := range own {
+		if a.IsGasFee {
+			fees[a.TransactionVersion.String()] = a
+		}
+	}
+
+	var txs []types.Transaction
+	sent := make(map[string]bool) // version → the account sent a row
+	for _, t := range pairTransfers(related) {
+		if t.from != address && t.to != address {
+			continue
+		}
+		tx, err := p.transferRow(t, address, nativeSymbol)
+		if err != nil {
+			softjson.SkipItem(t.asset, err)
+			continue
+		}
+		version := t.asset.TransactionVersion.String()
+		if t.from == address && !sent[version] {
+			sent[version] = true
+			if fee, ok := fees[version]; ok {
+				tx.GasUsed = fee.Amount.String()
+				tx.GasPrice = "1"
+			}
+		}
+		txs = append(txs, tx)
+	}
+
+	for version, fee := range fees {
+		if sent[version] {
+			continue
+		}
+		tx, err := p.transferRow(transfer{from: address, amount: "0", asset: fee}, address, nativeSymbol)
+		if err != nil {
+			softjson.SkipItem(fee, err)
+			continue
+		}
+		tx.Balance, tx.Amount = "0", "0"
+		tx.GasUsed = fee.Amount.String()
+		tx.GasPrice = "1"
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// pairTransfers walks each transaction's balance changes in event order and
+// pairs every deposit with the earliest pending withdrawal of the same asset,
+// preferring one of the same amount. Unpaired deposits are mints and
+// unpaired withdrawals are burns.
+func pairTransfers(activities []types.AptosActivity) []transfer {
+	var out []transfer
+	flush := func(pending map[string][]types.AptosActivity, order []string) {
+		for _, asset := range order {
+			for _, w := range pending[asset] {
+				out = append(out, transfer{from: canonical(w.OwnerAddress), amount: w.Amount.String(), asset: w})
+			}
+		}
+	}
+
+	var version string
+	pending := make(map[string][]types.AptosActivity)
+	var order []string
+	for _, a := range activities {
+		if v := a.TransactionVersion.String(); v != version {
+			flush(pending, order)
+			version, pending, order = v, make(map[string][]types.AptosActivity), nil
+		}
+		asset := strings.ToLower(a.AssetType)
+		switch {
+		case isWithdraw(a.Type):
+			if _, ok := pending[asset]; !ok {
+				order = append(order, asset)
+			}
+			pending[asset] = append(pending[asset], a)
+		case isDeposit(a.Type):
+			t := transfer{to: canonical(a.OwnerAddress), amount: a.Amount.String(), asset: a}
+			if queue := pending[asset]; len(queue) > 0 {
+				i := 0
+				for j, w := range queue {
+					if w.Amount == a.Amount {
+						i = j
+						break
+					}
+				}
+				t.from = canonical(queue[i].OwnerAddress)
+				pending[asset] = append(queue[:i:i], queue[i+1:]...)
+			}
+			out = append(out, t)
+		}
+	}
+	flush(pending, order)
+	return out
+}
+
+// transferRow converts one transfer into a row. APT is reported as the
+// native coin whichever of its two identifiers the event used.
+func (p *AptosProvider) transferRow(t transfer, address, nativeSymbol string) (types.Transaction, error) {
+	a := t.asset
+	raw, err := utils.NormalizeNumericString(t.amount)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	height, err := a.BlockHeight.Int64()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	version, err := a.TransactionVersion.Int64()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	ts, err := time.Parse(aptosTimestampLayout, a.TransactionTimestamp)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	asset, ok := utils.AptosAssetToCanonical(a.AssetType)
+	if !ok {
+		return types.Transaction{}, errors.New("invalid asset type")
+	}
+
+	state := types.TxStateSuccess
+	if !a.IsTransactionSuccess {
+		state = types.TxStateFail
+	}
+	tranType := types.TransTypeIn
+	if t.from == address {
+		tranType = types.TransTypeOut
+	}
+
+	tx := types.Transaction{
+		ChainID:      p.chainID,
+		State:        state,
+		Height:       height,
+		Hash:         a.TransactionVersion.String(),
+		TxIndex:      version,
+		FromAddress:  t.from,
+		ToAddress:    t.to,
+		Balance:      raw,
+		CreatedTime:  ts.Unix(),
+		ModifiedTime: ts.Unix(),
+		TranType:     tranType,
+	}
+	if asset == aptCoinType || asset == aptFAAsset {
+		tx.Decimals = utils.NativeDecimalsByChainID(p.chainID, aptDecimals)
+		tx.Amount = utils.DivideByDecimals(raw, int(tx.Decimals))
+		tx.Type = types.TxTypeUnknown // native transfer
+		tx.CoinType = types.CoinTypeNative
+		tx.TokenDisplayName = nativeSymbol
+		return tx, nil
+	}
+
+	decimals := int64(aptDecimals)
+	if a.Metadata != nil {
+		decimals = a.Metadata.Decimals
+		tx.TokenDisplayName = a.Metadata.Symbol
+	}
+	tx.TokenAddress = asset
+	tx.Amount = utils.DivideByDecimals(raw, int(decimals))
+	tx.Type = types.TxTypeTransfer
+	tx.CoinType = types.CoinTypeToken
+	tx.Decimals = decimals
+	return tx, nil
+}
+
+// isWithdraw matches 0x1::coin::WithdrawEvent and 0x1::fungible_asset::Withdraw.
+func isWithdraw(eventType string) bool {
+	return strings.HasSuffix(eventType, "::WithdrawEvent") || strings.HasSuffix(eventType, "::Withdraw")
+}
+
+// isDeposit matches 0x1::coin::DepositEvent and 0x1::fungible_asset::Deposit.
+func isDeposit(eventType string) bool {
+	return strings.HasSuffix(eventType, "::DepositEvent") || strings.HasSuffix(eventType, "::Deposit")
+}
@@ -1,6 +1,8 @@
 package blockscan
 
 import (
+	"strings"
+
 	"golang.org/x/sync/errgroup"
 	"tx-aggregator/logger"
 	"tx-aggregator/provider"
@@ -13,8 +15,10 @@ var _ provider.Provider = (*BlockscanProvider)(nil)
 
 // BlockscanProvider fetches data from a BscScan / Etherscan compatible REST API.
 type BlockscanProvider struct {
-	chainID int64
-	cfg     types.BlockscanConfig
+	chainID     int64
+	cfg         types.BlockscanConfig
+	auth        utils.AuthStrategy // Optional request signing
+	providerKey string             // Registry key, for per-provider rate limits and timeouts
 }
 
 // NewBlockscanProvider constructs a provider for one chain / one base-URL.
@@ -23,18 +27,34 @@ func NewBlockscanProvider(chainID int64, cfg types.BlockscanConfig) *BlockscanPr
 		Str("url", cfg.URL).
 		Str("chain", cfg.ChainName).
 		Msg("Initializing BlockscanProvider")
+
+	auth, err := utils.NewAuthStrategy(cfg.Auth)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("chain", cfg.ChainName).Msg("Invalid Blockscan auth config, requests will be unsigned")
+	}
+
 	return &BlockscanProvider{
-		chainID: chainID,
-		cfg:     cfg,
+		chainID:     chainID,
+		cfg:         cfg,
+		auth:        auth,
+		providerKey: provider.Key("blockscan", cfg.ChainName),
 	}
 }
 
+// SetProviderKey makes the provider's requests use the rate limit and
+// timeout settings of key instead of its own blockscan_<chain> key, for
+// wrappers registered under another name such as Routescan.
+func (p *BlockscanProvider) SetProviderKey(key string) {
+	p.providerKey = key
+}
+
 // -----------------------------------------------------------------------------
 // Public entry – fan-out, merge and return a single TransactionResponse
 // -----------------------------------------------------------------------------
 
 func (p *BlockscanProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
 	address := params.Address
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
 
 	logger.Log.Info().
 		Str("provider", p.cfg.ChainName).
@@ -51,7 +71,7 @@ func (p *BlockscanProvider) GetTransactions(params *types.TransactionQueryParams
 
 	// 1. Normal transactions (txlist)
 	g.Go(func() error {
-		resp, err := p.fetchNormalTx(address)
+		resp, err := p.fetchNormalTx(params.RequestID, address, maxPages)
 		if err != nil {
 			return err
 		}
@@ -61,7 +81,7 @@ func (p *BlockscanProvider) GetTransactions(params *types.TransactionQueryParams
 
 	// 2. Token transfers (tokentx)
 	g.Go(func() error {
-		resp, err := p.fetchTokenTx(address)
+		resp, err := p.fetchTokenTx(params.RequestID, address, maxPages)
 		if err != nil {
 			return err
 		}
@@ -72,7 +92,7 @@ func (p *BlockscanProvider) GetTransactions(params *types.TransactionQueryParams
 	// 3. Internal transactions (txlistinternal)
 	// TODO: temporarily disabled due to API issues
 	//g.Go(func() error {
-	//	resp, err := p.fetchInternalTx(address)
+	//	resp, err := p.fetchInternalTx(params.RequestID, address, maxPages)
 	//	if err != nil {
 	//		return err
 	//	}
@@ -106,3 +126,65 @@ func (p *BlockscanProvider) GetTransactions(params *types.TransactionQueryParams
 		}{Transactions: all},
 	}, nil
 }
+
+// -----------------------------------------------------------------------------
+// Pagination
+// -----------------------------------------------------------------------------
+
+const (
+	// defaultMaxPages bounds the pagination loop when max_pages is not configured.
+	defaultMaxPages = 10
+	// maxResultWindow is the Etherscan limit for page × offset.
+	maxResultWindow = 10000
+)
+
+// fetchAllPages calls fetchPage for consecutive pages, starting at cfg.Page,
+// until a page returns fewer than RequestPageSize items, maxPages pages
+// have been read, or the API result window is exhausted. A failure on a later
+// page keeps the items collected so far.
+func fetchAllPages[T any](p *BlockscanProvider, maxPages int64, label string, fetchPage func(page int64) ([]T, error)) ([]T, error) {
+	page := p.cfg.Page
+	if page <= 0 {
+		page = 1
+	}
+	var all []T
+	for fetched := int64(0); fetched < maxPages; fetched++ {
+		items, err := fetchPage(page)
+		if err != nil {
+			if fetched == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", label).
+				Int64("page", page).
+				Msg("Blockscan pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+
+		// Short page, unknown page size or end of the result window → done.
+		if p.cfg.RequestPageSize <= 0 ||
+			int64(len(items)) < p.cfg.RequestPageSize ||
+			(page+1)*p.cfg.RequestPageSize > maxResultWindow {
+			break
+		}
+		page++
+	}
+
+	logger.Log.Debug().
+		Str("chain", p.cfg.ChainName).
+		Str("endpoint", label).
+		Int64("last_page", page).
+		Int("items", len(all)).
+		Msg("Blockscan pagination finished")
+	return all, nil
+}
+
+// isEmptyResultMessage reports whether a status "0" response only means that
+// the requested page has no rows (e.g. "No transactions found").
+func isEmptyResultMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.HasPrefix(msg, "no ") && strings.Contains(msg, "found")
+}
@@ -0,0 +1,39 @@
+package blockscan
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"tx-aggregator/provider"
+	"tx-aggregator/utils"
+)
+
+// proxyResp is the answer of the proxy module: a JSON-RPC envelope on
+// success, the usual status/message/result triple on error.
+type proxyResp struct {
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
+var _ provider.HealthChecker = (*BlockscanProvider)(nil)
+
+// HealthCheck asks for the latest block through the proxy module, which
+// also validates the API key.
+func (p *BlockscanProvider) HealthCheck() error {
+	q := url.Values{
+		"module": {"proxy"},
+		"action": {"eth_blockNumber"},
+		"apikey": {p.cfg.APIKey},
+	}
+	u := fmt.Sprintf("%s?%s", p.cfg.URL, q.Encode())
+
+	var out proxyResp
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.healthCheck", u, nil, nil, &out, utils.WithAuth(p.auth), utils.WithProviderKey(p.providerKey)); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(out.Result, "0x") {
+		return fmt.Errorf("blockscan health check failed: %s %s", out.Message, out.Result)
+	}
+	return nil
+}
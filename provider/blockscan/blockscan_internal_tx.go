@@ -13,10 +13,25 @@ import (
 	"tx-aggregator/utils"
 )
 
-// fetchInternalTx retrieves internal transactions for a specific address from the Blockscan API.
+// fetchInternalTx retrieves all pages of internal transactions for a specific address from the Blockscan API.
+// Returns the merged API response containing internal transactions or an error if the first page fails.
+func (p *BlockscanProvider) fetchInternalTx(requestID, addr string, maxPages int64) (*types.BlockscanInternalTxResp, error) {
+	items, err := fetchAllPages(p, maxPages, "internalTx", func(page int64) ([]types.BlockscanInternalItem, error) {
+		resp, err := p.fetchInternalTxPage(requestID, addr, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.BlockscanInternalTxResp{Status: types.StatusOK, Message: "OK", Result: items}, nil
+}
+
+// fetchInternalTxPage retrieves a single page of internal transactions for a specific address.
 // It constructs a query with parameters like address, block range, pagination settings, and API key.
-// Returns the API response containing internal transactions or an error if the request fails.
-func (p *BlockscanProvider) fetchInternalTx(addr string) (*types.BlockscanInternalTxResp, error) {
+func (p *BlockscanProvider) fetchInternalTxPage(requestID, addr string, page int64) (*types.BlockscanInternalTxResp, error) {
 	// Construct query parameters for the Blockscan API request
 	q := url.Values{
 		"module":     {"account"},
@@ -24,7 +39,7 @@ func (p *BlockscanProvider) fetchInternalTx(addr string) (*types.BlockscanIntern
 		"address":    {addr},
 		"startblock": {strconv.FormatInt(p.cfg.Startblock, 10)},
 		"endblock":   {strconv.FormatInt(p.cfg.Endblock, 10)},
-		"page":       {strconv.FormatInt(p.cfg.Page, 10)},
+		"page":       {strconv.FormatInt(page, 10)},
 		"offset":     {fmt.Sprint(p.cfg.RequestPageSize)},
 		"sort":       {p.cfg.Sort},
 		"apikey":     {p.cfg.APIKey},
@@ -32,12 +47,16 @@ func (p *BlockscanProvider) fetchInternalTx(addr string) (*types.BlockscanIntern
 	var out types.BlockscanInternalTxResp
 	// Construct the full URL with query parameters and make the HTTP request
 	u := fmt.Sprintf("%s?%s", p.cfg.URL, q.Encode())
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.internalTx", u, nil, nil, &out); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.internalTx", u, nil, nil, &out, utils.WithAuth(p.auth), utils.WithProviderKey(p.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 
-	// Check if the API returned an error status and log the error
+	// Check if the API returned an error status and log the error ("no transactions" only means an empty page)
 	if out.Status == types.StatusError {
+		if isEmptyResultMessage(out.Message) {
+			out.Result = nil
+			return &out, nil
+		}
 		logger.Log.Warn().
 			Str("error_message", out.Message).
 			Str("address", addr).
@@ -59,6 +78,7 @@ func (p *BlockscanProvider) transformInternalTx(resp *types.BlockscanInternalTxR
 	}
 
 	var txs []types.Transaction
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
 	for _, it := range resp.Result {
 		// Parse block height and timestamp
 		height := utils.ParseStringToInt64OrDefault(it.BlockNumber, 0)
@@ -80,7 +100,7 @@ func (p *BlockscanProvider) transformInternalTx(resp *types.BlockscanInternalTxR
 
 		// Normalize numeric values (value, gas limit, gas used)
 		valueRaw, _ := utils.NormalizeNumericString(it.Value)
-		value := utils.DivideByDecimals(valueRaw, types.NativeDefaultDecimals)
+		value := utils.DivideByDecimals(valueRaw, int(nativeDecimals))
 		gasLimit, _ := utils.NormalizeNumericString(it.Gas)
 		gasUsed, _ := utils.NormalizeNumericString(it.GasUsed)
 
@@ -98,7 +118,7 @@ func (p *BlockscanProvider) transformInternalTx(resp *types.BlockscanInternalTxR
 			GasUsed:      gasUsed,
 			Type:         types.TxTypeInternal,
 			CoinType:     types.CoinTypeInternal,
-			Decimals:     types.NativeDefaultDecimals,
+			Decimals:     nativeDecimals,
 			CreatedTime:  unixTime,
 			ModifiedTime: unixTime,
 			TranType:     tranType,
@@ -13,16 +13,33 @@ import (
 // This file contains functions for fetching and transforming normal transactions from Blockscan API
 // (such as Etherscan, BscScan, etc.)
 
-// fetchNormalTx retrieves normal transactions for a given address from the Blockscan API.
-// It constructs the API request with parameters from the provider configuration.
+// fetchNormalTx retrieves all pages of normal transactions for a given address from the Blockscan API
+// and merges them into a single response.
 //
 // Parameters:
 //   - addr: The blockchain address to fetch transactions for
+//   - maxPages: The maximum number of pages to read
 //
 // Returns:
-//   - *types.BlockscanNormalTxResp: The API response containing transaction data
-//   - error: Any error encountered during the API request
-func (p *BlockscanProvider) fetchNormalTx(addr string) (*types.BlockscanNormalTxResp, error) {
+//   - *types.BlockscanNormalTxResp: The merged API response containing transaction data
+//   - error: Any error encountered while fetching the first page
+func (p *BlockscanProvider) fetchNormalTx(requestID, addr string, maxPages int64) (*types.BlockscanNormalTxResp, error) {
+	items, err := fetchAllPages(p, maxPages, "normalTx", func(page int64) ([]types.BlockscanTxItem, error) {
+		resp, err := p.fetchNormalTxPage(requestID, addr, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.BlockscanNormalTxResp{Status: types.StatusOK, Message: "OK", Result: items}, nil
+}
+
+// fetchNormalTxPage retrieves a single page of normal transactions for a given address.
+// It constructs the API request with parameters from the provider configuration.
+func (p *BlockscanProvider) fetchNormalTxPage(requestID, addr string, page int64) (*types.BlockscanNormalTxResp, error) {
 	// Construct query parameters for the Blockscan API request
 	q := url.Values{
 		"module":     {"account"},
@@ -30,7 +47,7 @@ func (p *BlockscanProvider) fetchNormalTx(addr string) (*types.BlockscanNormalTx
 		"address":    {addr},
 		"startblock": {strconv.FormatInt(p.cfg.Startblock, 10)},
 		"endblock":   {strconv.FormatInt(p.cfg.Endblock, 10)},
-		"page":       {strconv.FormatInt(p.cfg.Page, 10)},
+		"page":       {strconv.FormatInt(page, 10)},
 		"offset":     {fmt.Sprint(p.cfg.RequestPageSize)},
 		"sort":       {p.cfg.Sort},
 		"apikey":     {p.cfg.APIKey},
@@ -41,12 +58,16 @@ func (p *BlockscanProvider) fetchNormalTx(addr string) (*types.BlockscanNormalTx
 	u := fmt.Sprintf("%s?%s", p.cfg.URL, q.Encode())
 
 	// Execute the HTTP request with logging
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.normalTx", u, nil, nil, &out); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.normalTx", u, nil, nil, &out, utils.WithAuth(p.auth), utils.WithProviderKey(p.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 
-	// Check if the API returned an error status
+	// Check if the API returned an error status ("no transactions" only means an empty page)
 	if out.Status == types.StatusError {
+		if isEmptyResultMessage(out.Message) {
+			out.Result = nil
+			return &out, nil
+		}
 		logger.Log.Warn().
 			Str("error_message", out.Message).
 			Str("address", addr).
@@ -73,6 +94,7 @@ func (p *BlockscanProvider) transformNormalTx(resp *types.BlockscanNormalTxResp,
 	}
 
 	var txs []types.Transaction
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
 	// Process each transaction in the response
 	for _, it := range resp.Result {
 		// Parse numeric values from string representations
@@ -94,7 +116,7 @@ func (p *BlockscanProvider) transformNormalTx(resp *types.BlockscanNormalTxResp,
 
 		// Parse and normalize transaction values
 		amountRaw, _ := utils.NormalizeNumericString(it.Value)
-		amount := utils.DivideByDecimals(amountRaw, types.NativeDefaultDecimals)
+		amount := utils.DivideByDecimals(amountRaw, int(nativeDecimals))
 		gasLimit, _ := utils.NormalizeNumericString(it.Gas)
 		gasUsed, _ := utils.NormalizeNumericString(it.GasUsed)
 		gasPrice, _ := utils.NormalizeNumericString(it.GasPrice)
@@ -129,7 +151,7 @@ func (p *BlockscanProvider) transformNormalTx(resp *types.BlockscanNormalTxResp,
 			Type:             types.TxTypeUnknown, // native transfer
 			CoinType:         types.CoinTypeNative,
 			TokenDisplayName: nativeSymbol,
-			Decimals:         types.NativeDefaultDecimals,
+			Decimals:         nativeDecimals,
 			CreatedTime:      unixTime,
 			ModifiedTime:     unixTime,
 			TranType:         tranType,
@@ -12,22 +12,38 @@ import (
 	"tx-aggregator/utils"
 )
 
-// fetchTokenTx retrieves token transactions for a specific address from the Blockscan API.
-// It constructs the API request with appropriate parameters and handles error responses.
+// fetchTokenTx retrieves all pages of token transactions for a specific address from the Blockscan API.
 //
 // Parameters:
 //   - addr: The blockchain address to fetch token transactions for
+//   - maxPages: The maximum number of pages to read
 //
 // Returns:
-//   - *types.BlockscanTokenTxResp: The API response containing token transactions
-//   - error: Any error encountered during the API request
-func (p *BlockscanProvider) fetchTokenTx(addr string) (*types.BlockscanTokenTxResp, error) {
+//   - *types.BlockscanTokenTxResp: The merged API response containing token transactions
+//   - error: Any error encountered while fetching the first page
+func (p *BlockscanProvider) fetchTokenTx(requestID, addr string, maxPages int64) (*types.BlockscanTokenTxResp, error) {
+	items, err := fetchAllPages(p, maxPages, "tokenTx", func(page int64) ([]types.BlockscanTokenTxItem, error) {
+		resp, err := p.fetchTokenTxPage(requestID, addr, page)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &types.BlockscanTokenTxResp{Status: types.StatusOK, Message: "OK", Result: items}, nil
+}
+
+// fetchTokenTxPage retrieves a single page of token transactions for a specific address.
+// It constructs the API request with appropriate parameters and handles error responses.
+func (p *BlockscanProvider) fetchTokenTxPage(requestID, addr string, page int64) (*types.BlockscanTokenTxResp, error) {
 	// Prepare query parameters for the Blockscan API request
 	q := url.Values{
 		"module":  {"account"},             // Specify the module as account
 		"action":  {"tokentx"},             // Request token transactions
 		"address": {addr},                  // The address to query transactions for
-		"page":    {strconv.FormatInt(p.cfg.Page, 10)}, // Pagination parameter
+		"page":    {strconv.FormatInt(page, 10)},       // Pagination parameter
 		"offset":  {fmt.Sprint(p.cfg.RequestPageSize)},  // Number of results per page
 		"sort":    {p.cfg.Sort},            // Sorting order (asc/desc)
 		"apikey":  {p.cfg.APIKey},          // API key for authentication
@@ -39,12 +55,16 @@ func (p *BlockscanProvider) fetchTokenTx(addr string) (*types.BlockscanTokenTxRe
 	u := fmt.Sprintf("%s?%s", p.cfg.URL, q.Encode())
 
 	// Execute HTTP GET request with logging
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.tokenTx", u, nil, nil, &out); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscan.tokenTx", u, nil, nil, &out, utils.WithAuth(p.auth), utils.WithProviderKey(p.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 
-	// Check if the API returned an error status
+	// Check if the API returned an error status ("no transactions" only means an empty page)
 	if out.Status == types.StatusError {
+		if isEmptyResultMessage(out.Message) {
+			out.Result = nil
+			return &out, nil
+		}
 		// Log the error with relevant details
 		logger.Log.Warn().
 			Str("error_message", out.Message).
@@ -82,6 +102,10 @@ func (p *BlockscanProvider) transformTokenTx(resp *types.BlockscanTokenTxResp, a
 		height := utils.ParseStringToInt64OrDefault(tt.BlockNumber, 0)
 		unixTime := utils.ParseStringToInt64OrDefault(tt.TimeStamp, 0)
 		txIndex := utils.ParseStringToInt64OrDefault(tt.TransactionIndex, 0)
+		var logIndex int64
+		if tt.LogIndex != "" {
+			logIndex = utils.ParseStringToInt64OrDefault(tt.LogIndex, 0)
+		}
 		decimals := utils.ParseStringToInt64OrDefault(tt.TokenDecimal, types.NativeDefaultDecimals)
 
 		// Normalize and format token amount values
@@ -106,6 +130,7 @@ func (p *BlockscanProvider) transformTokenTx(resp *types.BlockscanTokenTxResp, a
 			Hash:             tt.Hash,
 			BlockHash:        tt.BlockHash,
 			TxIndex:          txIndex,
+			LogIndex:         logIndex,
 			FromAddress:      tt.From,
 			ToAddress:        tt.To,
 			TokenAddress:     tt.ContractAddress,
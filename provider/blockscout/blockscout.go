@@ -9,9 +9,10 @@ package blockscout
 import (
 	"fmt"
 	"io"
-	"net/http"
 	"time"
+	"tx-aggregator/freshness"
 	"tx-aggregator/logger"
+	"tx-aggregator/provider"
 	"tx-aggregator/types"
 	"tx-aggregator/utils"
 
@@ -21,8 +22,10 @@ import (
 // BlockscoutProvider implements the Provider interface for fetching transaction
 // data from a Blockscout‑compatible API.
 type BlockscoutProvider struct {
-	chainID int64 // Numeric chain ID
-	config  types.BlockscoutConfig
+	chainID     int64 // Numeric chain ID
+	config      types.BlockscoutConfig
+	auth        utils.AuthStrategy // Optional request signing for the REST API
+	providerKey string             // Registry key, for per-provider rate limits and timeouts
 }
 
 // NewBlockscoutProvider returns a new BlockscoutProvider.
@@ -31,9 +34,16 @@ func NewBlockscoutProvider(chainID int64, config types.BlockscoutConfig) *Blocks
 	logger.Log.Info().
 		Msg("Initializing BlockscoutProvider")
 
+	auth, err := utils.NewAuthStrategy(config.Auth)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("chain", config.ChainName).Msg("Invalid Blockscout auth config, requests will be unsigned")
+	}
+
 	return &BlockscoutProvider{
-		chainID: chainID,
-		config:  config,
+		chainID:     chainID,
+		config:      config,
+		auth:        auth,
+		providerKey: provider.Key("blockscout", config.ChainName),
 	}
 }
 
@@ -50,6 +60,7 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 
 	var (
 		normalTxs   []types.Transaction
+		batchCalls  map[string]bool
 		tokenTxs    []types.Transaction
 		internalTxs []types.Transaction
 
@@ -59,22 +70,28 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 		fetchErr error
 	)
 
+	// Refresh the indexer lag hint in the background, at most every probe interval.
+	if p.config.RPCURL != "" && freshness.ShouldProbe("blockscout_"+p.config.ChainName) {
+		go p.probeIndexerLag()
+	}
+
 	// Launch concurrent fetches.
 	g := new(errgroup.Group)
 
 	// 1. Normal transactions.
 	g.Go(func() error {
-		resp, err := p.fetchBlockscoutNormalTx(address)
+		resp, err := p.fetchBlockscoutNormalTx(params.RequestID, address)
 		if err != nil {
 			return err
 		}
 		normalTxs = p.transformBlockscoutNormalTx(resp, address, nil)
+		batchCalls = blockscoutBatchCalls(resp)
 		return nil
 	})
 
 	// 2. Token transfers.
 	g.Go(func() error {
-		resp, err := p.fetchBlockscoutTokenTransfers(address)
+		resp, err := p.fetchBlockscoutTokenTransfers(params.RequestID, address)
 		if err != nil {
 			return err
 		}
@@ -84,7 +101,7 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 
 	// 3. Internal transactions.
 	g.Go(func() error {
-		resp, err := p.fetchBlockscoutInternalTx(address)
+		resp, err := p.fetchBlockscoutInternalTx(params.RequestID, address)
 		if err != nil {
 			return err
 		}
@@ -94,7 +111,7 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 
 	// 4. Logs from Blockscout “/logs” endpoint.
 	g.Go(func() error {
-		resp, err := p.fetchBlockscoutLogs(address)
+		resp, err := p.fetchBlockscoutLogs(params.RequestID, address)
 		if err != nil {
 			return err
 		}
@@ -118,7 +135,7 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 			blocks[tx.Height] = struct{}{}
 		}
 
-		rpcLogs, fetchErr = p.fetchLogsByBlockFromRPC(blocks)
+		rpcLogs, fetchErr = p.fetchLogsByBlockFromRPC(params.RequestID, blocks)
 		if fetchErr != nil {
 			// Log the error and continue using only Blockscout logs.
 			logger.Log.Warn().Err(fetchErr).Msg("Failed to fetch RPC logs")
@@ -129,11 +146,15 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 
 	// Inject logs into normal transactions (approve detection, etc.).
 	if len(normalTxs) > 0 {
-		normalTxs = p.transformBlockscoutNormalTxWithLogs(normalTxs, allLogs, address)
+		normalTxs = p.transformBlockscoutNormalTxWithLogs(normalTxs, allLogs, batchCalls, address)
 	}
 
-	// Patch tokenTxs with gas info from normalTxs
+	// Patch tokenTxs with gas info from normalTxs, and internalTxs with
+	// their position in the block. Rows of transactions the address did not
+	// send get their position from the RPC node.
 	tokenTxs = utils.PatchTokenTransactionsWithNormalTxInfo(tokenTxs, normalTxs)
+	utils.PatchTxPositionFromNormalTxs(internalTxs, normalTxs)
+	p.fillTxPositionFromRPC(params.RequestID, normalTxs, tokenTxs, internalTxs)
 
 	// Aggregate and return all transactions.
 	allTxs := append(normalTxs, tokenTxs...)
@@ -158,7 +179,7 @@ func (p *BlockscoutProvider) GetTransactions(params *types.TransactionQueryParam
 // doLoggedHttpGet sends a GET request to the given URL, logs duration and errors, and returns the response body.
 func doLoggedHttpGet(label string, url string) ([]byte, error) {
 	start := time.Now()
-	resp, err := http.Get(url)
+	resp, err := utils.ProviderClient().Get(url)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -0,0 +1,26 @@
+package blockscout
+
+import (
+	"errors"
+	"fmt"
+
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+var _ provider.HealthChecker = (*BlockscoutProvider)(nil)
+
+// HealthCheck reads the latest indexed blocks, the cheapest REST call that
+// still goes through the indexer's database.
+func (p *BlockscoutProvider) HealthCheck() error {
+	var blocks []types.BlockscoutBlock
+	url := fmt.Sprintf("%s/main-page/blocks", p.config.URL)
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.healthCheck", url, nil, nil, &blocks, utils.WithAuth(p.auth), utils.WithProviderKey(p.providerKey)); err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return errors.New("blockscout returned no blocks")
+	}
+	return nil
+}
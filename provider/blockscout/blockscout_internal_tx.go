@@ -10,10 +10,10 @@ import (
 
 // fetchBlockscoutInternalTx retrieves internal transactions from Blockscout:
 // GET /addresses/{address}/internal-transactions
-func (t *BlockscoutProvider) fetchBlockscoutInternalTx(address string) (*types.BlockscoutInternalTxResponse, error) {
+func (t *BlockscoutProvider) fetchBlockscoutInternalTx(requestID, address string) (*types.BlockscoutInternalTxResponse, error) {
 	url := fmt.Sprintf("%s/addresses/%s/internal-transactions?limit=%d", t.config.URL, address, t.config.RequestPageSize)
 	var result types.BlockscoutInternalTxResponse
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.internalTx", url, nil, nil, &result); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.internalTx", url, nil, nil, &result, utils.WithAuth(t.auth), utils.WithProviderKey(t.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -31,6 +31,7 @@ func (t *BlockscoutProvider) transformBlockscoutInternalTx(
 	}
 
 	var transactions []types.Transaction
+	nativeDecimals := utils.NativeDecimalsByChainID(t.chainID, types.NativeDefaultDecimals)
 
 	for _, itx := range resp.Items {
 		// Determine transaction success state
@@ -61,7 +62,7 @@ func (t *BlockscoutProvider) transformBlockscoutInternalTx(
 		// Normalize gas limit (if provided)
 		gasLimit, err := utils.NormalizeNumericString(itx.GasLimit)
 		amountRaw, err := utils.NormalizeNumericString(itx.Value)
-		amount := utils.DivideByDecimals(amountRaw, types.NativeDefaultDecimals)
+		amount := utils.DivideByDecimals(amountRaw, int(nativeDecimals))
 		if err != nil {
 			logger.Log.Error().
 				Err(err).
@@ -89,7 +90,7 @@ func (t *BlockscoutProvider) transformBlockscoutInternalTx(
 			Type:             types.TxTypeInternal, // Internal call
 			CoinType:         types.CoinTypeNative, // Typically native token
 			TokenDisplayName: "",
-			Decimals:         types.NativeDefaultDecimals,
+			Decimals:         nativeDecimals,
 			CreatedTime:      unixTime,
 			ModifiedTime:     unixTime,
 			TranType:         tranType,
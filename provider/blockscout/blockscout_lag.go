@@ -0,0 +1,38 @@
+package blockscout
+
+import (
+	"fmt"
+
+	"tx-aggregator/freshness"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// probeIndexerLag compares the newest block indexed by Blockscout with the
+// head of the chain's RPC node and records the difference. Failures are only
+// logged: the lag is a hint, never a reason to fail a request.
+func (p *BlockscoutProvider) probeIndexerLag() {
+	var blocks []types.BlockscoutBlock
+	url := fmt.Sprintf("%s/main-page/blocks", p.config.URL)
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.latestBlocks", url, nil, nil, &blocks, utils.WithAuth(p.auth), utils.WithProviderKey(p.providerKey)); err != nil {
+		logger.Log.Warn().Err(err).Str("chain", p.config.ChainName).Msg("Failed to probe Blockscout indexed head")
+		return
+	}
+	if len(blocks) == 0 {
+		return
+	}
+
+	head, err := utils.RPCBlockNumber("blockscout.rpcBlockNumber", p.config.RPCURL)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("chain", p.config.ChainName).Msg("Failed to probe RPC head")
+		return
+	}
+
+	freshness.Record(p.chainID, head, blocks[0].Height)
+	logger.Log.Debug().
+		Str("chain", p.config.ChainName).
+		Int64("head", head).
+		Int64("indexed", blocks[0].Height).
+		Msg("Recorded Blockscout indexer lag")
+}
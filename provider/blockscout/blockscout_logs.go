@@ -14,10 +14,10 @@ import (
 
 // fetchBlockscoutLogs retrieves logs from Blockscout:
 // GET /addresses/{address}/logs
-func (t *BlockscoutProvider) fetchBlockscoutLogs(address string) (*types.BlockscoutLogResponse, error) {
+func (t *BlockscoutProvider) fetchBlockscoutLogs(requestID, address string) (*types.BlockscoutLogResponse, error) {
 	url := fmt.Sprintf("%s/addresses/%s/logs?limit=%d", t.config.URL, address, t.config.RequestPageSize)
 	var result types.BlockscoutLogResponse
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.logs", url, nil, nil, &result); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.logs", url, nil, nil, &result, utils.WithAuth(t.auth), utils.WithProviderKey(t.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -33,6 +33,7 @@ func (t *BlockscoutProvider) fetchBlockscoutLogs(address string) (*types.Blocksc
 // return.error  Non‑nil if any shard fails (partial results are discarded)
 // ───────────────────────────────────────────────────────────────────────────────
 func (p *BlockscoutProvider) fetchLogsByBlockFromRPC(
+	requestID string,
 	blocks map[int64]struct{},
 ) (map[string][]types.BlockscoutLog, error) {
 
@@ -104,6 +105,7 @@ func (p *BlockscoutProvider) fetchLogsByBlockFromRPC(
 				reqs,
 				map[string]string{"Content-Type": "application/json"},
 				&rpcResponses,
+				utils.WithRequestID(requestID),
 			); err != nil {
 				return err
 			}
@@ -1,20 +1,23 @@
 package blockscout
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
 	"tx-aggregator/types"
 	"tx-aggregator/utils"
 )
 
 // fetchBlockscoutNormalTx retrieves normal transactions from the Blockscout endpoint:
 // GET /addresses/{address}/transactions
-func (t *BlockscoutProvider) fetchBlockscoutNormalTx(address string) (*types.BlockscoutTransactionResponse, error) {
+func (t *BlockscoutProvider) fetchBlockscoutNormalTx(requestID, address string) (*types.BlockscoutTransactionResponse, error) {
 	url := fmt.Sprintf("%s/addresses/%s/transactions?limit=%d", t.config.URL, address, t.config.RequestPageSize)
 	var result types.BlockscoutTransactionResponse
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.normalTx", url, nil, nil, &result); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.normalTx", url, nil, nil, &result, utils.WithAuth(t.auth), utils.WithProviderKey(t.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -33,6 +36,7 @@ func (t *BlockscoutProvider) transformBlockscoutNormalTx(
 	}
 
 	var transactions []types.Transaction
+	nativeDecimals := utils.NativeDecimalsByChainID(t.chainID, types.NativeDefaultDecimals)
 
 	for _, tx := range resp.Items {
 		// Determine transaction status
@@ -50,9 +54,13 @@ func (t *BlockscoutProvider) transformBlockscoutNormalTx(
 		// Parse timestamp
 		unixTime := utils.ParseBlockscoutTimestampToUnix(tx.Timestamp)
 
-		// Normalize values
+		// Normalize values; a transaction without a usable value is skipped
 		amountRaw, err := utils.NormalizeNumericString(tx.Value)
-		amount := utils.DivideByDecimals(amountRaw, types.NativeDefaultDecimals)
+		if err != nil {
+			softjson.SkipItem(tx, err)
+			continue
+		}
+		amount := utils.DivideByDecimals(amountRaw, int(nativeDecimals))
 		gasUsed, err := utils.NormalizeNumericString(tx.GasUsed)
 		gasLimit, err := utils.NormalizeNumericString(tx.GasLimit)
 		gasPrice, err := utils.NormalizeNumericString(tx.GasPrice)
@@ -79,6 +87,7 @@ func (t *BlockscoutProvider) transformBlockscoutNormalTx(
 			State:            state,
 			Height:           tx.BlockNumber,
 			Hash:             tx.Hash,
+			TxIndex:          tx.Position,
 			BlockHash:        tx.BlockHash,
 			FromAddress:      tx.From.Hash,
 			ToAddress:        tx.To.Hash,
@@ -92,7 +101,7 @@ func (t *BlockscoutProvider) transformBlockscoutNormalTx(
 			Type:             types.TxTypeUnknown,  // Default type for native transfer
 			CoinType:         types.CoinTypeNative, // Native coin
 			TokenDisplayName: nativeTokenName,
-			Decimals:         types.NativeDefaultDecimals,
+			Decimals:         nativeDecimals,
 			CreatedTime:      unixTime,
 			ModifiedTime:     unixTime,
 			TranType:         tranType,
@@ -100,25 +109,65 @@ func (t *BlockscoutProvider) transformBlockscoutNormalTx(
 			IconURL:          "",
 		}
 
+		// Rollups also charge for L1 data
+		var gasUsedForL1 string
+		if tx.Arbitrum != nil {
+			gasUsedForL1 = tx.Arbitrum.GasUsedForL1
+		}
+		transaction.L1Fee, transaction.Fee, _ = utils.FeeBreakdown(gasUsed, gasPrice, tx.L1Fee, gasUsedForL1)
+
+		// A transaction without recipient deploys a contract
+		if tx.To.Hash == "" && tx.CreatedContract != nil && tx.CreatedContract.Hash != "" {
+			transaction.Type = types.TxTypeContractCreation
+			transaction.ContractAddress = strings.ToLower(tx.CreatedContract.Hash)
+		}
+
 		transactions = append(transactions, transaction)
 	}
 
 	return transactions
 }
 
+// blockscoutBatchCalls returns the hashes of the transactions whose method,
+// as decoded by Blockscout (a name, or the selector when unknown), is a
+// batch call.
+func blockscoutBatchCalls(resp *types.BlockscoutTransactionResponse) map[string]bool {
+	batches := make(map[string]bool)
+	if resp == nil {
+		return batches
+	}
+	for _, tx := range resp.Items {
+		if utils.IsBatchCall(tx.Method) {
+			batches[tx.Hash] = true
+		}
+	}
+	return batches
+}
+
 // transformBlockscoutNormalTxWithLogs re-processes the already converted normal transactions
 // to detect if any are "approve" type (or other ERC-20 events) by scanning the logs map.
-// `logsMap` is keyed by tx hash => slice of BlockscoutLog.
+// `logsMap` is keyed by tx hash => slice of BlockscoutLog. The logs of the
+// batch calls in `batchCalls` are also decoded into sub-actions.
 func (b *BlockscoutProvider) transformBlockscoutNormalTxWithLogs(
 	normalTxs []types.Transaction,
 	logsMap map[string][]types.BlockscoutLog,
+	batchCalls map[string]bool,
 	address string,
 ) []types.Transaction {
 
 	for i, tx := range normalTxs {
-		// Does this transaction have logs in the map?
+		// Logs for the address were fetched, so every row has been scanned
+		normalTxs[i].Enrichment |= types.EnrichmentLogsScanned
+
+		if batchCalls[tx.Hash] {
+			normalTxs[i].Actions = b.blockscoutSubActions(logsMap[tx.Hash])
+		}
+		normalTxs[i].Safe = blockscoutSafeExecution(logsMap[tx.Hash])
+
+		// Does this transaction have logs in the map? A contract creation
+		// keeps its type whatever its constructor emitted.
 		logsForTx, found := logsMap[tx.Hash]
-		if !found || len(logsForTx) == 0 {
+		if !found || len(logsForTx) == 0 || tx.Type == types.TxTypeContractCreation {
 			// No logs => nothing to detect
 			continue
 		}
@@ -126,8 +175,28 @@ func (b *BlockscoutProvider) transformBlockscoutNormalTxWithLogs(
 		// We’ll see if any log indicates a recognized ERC-20 event
 		var finalTxType int = types.TxTypeUnknown
 		var finalTokenAddr, finalApproveVal string
+		var swap *types.SwapInfo
 
 		for _, lg := range logsForTx {
+			// A DEX Swap event labels the transaction as a swap, even when
+			// it also wraps or unwraps the native coin
+			if protocol, ok := utils.DetectSwapEvent(lg.Topics); ok {
+				finalTxType = types.TxTypeSwap
+				finalTokenAddr, finalApproveVal = "", ""
+				swap = &types.SwapInfo{Protocol: protocol}
+				break
+			}
+			if finalTxType != types.TxTypeUnknown {
+				continue // the first recognized event is kept unless a swap follows
+			}
+			// A Deposit or Withdrawal of the chain's wrapped-native contract
+			// labels the transaction as a wrap or unwrap
+			if wrapType, ok := utils.DetectWrapEvent(b.chainID, lg.Address.Hash, lg.Topics); ok {
+				finalTxType = wrapType
+				finalTokenAddr = strings.ToLower(lg.Address.Hash)
+				continue
+			}
+
 			// Our generic detection function:
 			// DetectERC20Event(contractAddress, topics, data)
 			txType, tokenAddr, approveValue := utils.DetectERC20Event(
@@ -140,10 +209,6 @@ func (b *BlockscoutProvider) transformBlockscoutNormalTxWithLogs(
 				finalTxType = txType
 				finalTokenAddr = tokenAddr
 				finalApproveVal = approveValue
-
-				// If you only want to capture the first recognized event,
-				// you can break here.
-				break
 			}
 		}
 
@@ -156,8 +221,41 @@ func (b *BlockscoutProvider) transformBlockscoutNormalTxWithLogs(
 
 			normalTxs[i].Type = finalTxType
 			normalTxs[i].TokenAddress = finalTokenAddr
+			normalTxs[i].Swap = swap
 		}
 	}
 
 	return normalTxs
 }
+
+// blockscoutSafeExecution returns the Safe execution recorded by the last
+// Safe execution event of a transaction, that of the outermost Safe, or nil.
+func blockscoutSafeExecution(logs []types.BlockscoutLog) *types.SafeExecution {
+	var safe *types.SafeExecution
+	last := int64(-1)
+	for _, lg := range logs {
+		if exec, ok := utils.DetectSafeExecution(lg.Address.Hash, lg.Topics, lg.Data); ok && lg.Index > last {
+			safe, last = exec, lg.Index
+		}
+	}
+	return safe
+}
+
+// blockscoutSubActions decodes the logs of a batch call into sub-actions, in
+// log order. Logs reported by both Blockscout and the RPC node are decoded
+// once.
+func (b *BlockscoutProvider) blockscoutSubActions(logs []types.BlockscoutLog) []types.SubAction {
+	sorted := slices.Clone(logs)
+	slices.SortStableFunc(sorted, func(x, y types.BlockscoutLog) int { return cmp.Compare(x.Index, y.Index) })
+
+	var actions []types.SubAction
+	seen := make(map[int64]bool, len(sorted))
+	for _, lg := range sorted {
+		if seen[lg.Index] {
+			continue
+		}
+		seen[lg.Index] = true
+		actions = append(actions, utils.LogSubActions(b.chainID, lg.Address.Hash, lg.Topics, lg.Data)...)
+	}
+	return actions
+}
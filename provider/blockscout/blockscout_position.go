@@ -0,0 +1,56 @@
+package blockscout
+
+import (
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fillTxPositionFromRPC sets the transaction index, and the block hash when
+// missing, of token and internal rows whose transaction the address did not
+// send (incoming transfers), so they are not in its normal transactions.
+// It needs the chain's rpc_url; without it, or when the lookup fails, the
+// rows keep a zero index.
+func (p *BlockscoutProvider) fillTxPositionFromRPC(requestID string, normalTxs []types.Transaction, groups ...[]types.Transaction) {
+	if p.config.RPCURL == "" {
+		return
+	}
+	known := make(map[string]bool, len(normalTxs))
+	for _, tx := range normalTxs {
+		known[tx.Hash] = true
+	}
+	var hashes []string
+	for _, rows := range groups {
+		for _, row := range rows {
+			if row.TxIndex != 0 || known[row.Hash] {
+				continue
+			}
+			known[row.Hash] = true
+			hashes = append(hashes, row.Hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+
+	rpcTxs, err := utils.RPCTransactionsByHash("blockscout.rpcTxByHash", p.config.RPCURL, hashes, utils.WithRequestID(requestID))
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("chain", p.config.ChainName).
+			Int("hashes", len(hashes)).
+			Msg("Failed to fetch transaction positions from RPC")
+	}
+	for _, rows := range groups {
+		for i, row := range rows {
+			rpcTx, ok := rpcTxs[row.Hash]
+			if !ok || row.TxIndex != 0 {
+				continue
+			}
+			rows[i].TxIndex = utils.ParseStringToInt64OrDefault(rpcTx.TransactionIndex, 0)
+			if row.BlockHash == "" {
+				rows[i].BlockHash = rpcTx.BlockHash
+			}
+		}
+	}
+}
@@ -4,16 +4,17 @@ import (
 	"fmt"
 	"strings"
 	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
 	"tx-aggregator/types"
 	"tx-aggregator/utils"
 )
 
 // fetchBlockscoutTokenTransfers retrieves token transfers from Blockscout:
 // GET /addresses/{address}/token-transfers
-func (t *BlockscoutProvider) fetchBlockscoutTokenTransfers(address string) (*types.BlockscoutTokenTransferResponse, error) {
+func (t *BlockscoutProvider) fetchBlockscoutTokenTransfers(requestID, address string) (*types.BlockscoutTokenTransferResponse, error) {
 	url := fmt.Sprintf("%s/addresses/%s/token-transfers?limit=%d", t.config.URL, address, t.config.RequestPageSize)
 	var result types.BlockscoutTokenTransferResponse
-	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.tokenTransfers", url, nil, nil, &result); err != nil {
+	if err := utils.DoHttpRequestWithLogging("GET", "blockscout.tokenTransfers", url, nil, nil, &result, utils.WithAuth(t.auth), utils.WithProviderKey(t.providerKey), utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -43,6 +44,12 @@ func (t *BlockscoutProvider) transformBlockscoutTokenTransfers(
 		decimals := utils.ParseStringToInt64OrDefault(tt.Token.Decimals, types.NativeDefaultDecimals) // Default to 18 if missing
 		amountRaw, err := utils.NormalizeNumericString(tt.Total.Value)
 		if err != nil {
+			if tt.Total.Value != "" {
+				// A present but unparsable amount is skipped; NFT transfers
+				// legitimately carry no value.
+				softjson.SkipItem(tt, err)
+				continue
+			}
 			logger.Log.Error().
 				Err(err).
 				Str("address", address).
@@ -58,6 +65,7 @@ func (t *BlockscoutProvider) transformBlockscoutTokenTransfers(
 			Height:           tt.BlockNumber,
 			Hash:             tt.TransactionHash,
 			BlockHash:        tt.BlockHash,
+			LogIndex:         tt.LogIndex,
 			FromAddress:      tt.From.Hash,
 			ToAddress:        tt.To.Hash,
 			TokenAddress:     tt.Token.Address,
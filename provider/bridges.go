@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"strings"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// bridgeSide is one bridges entry with its chains resolved to IDs.
+type bridgeSide struct {
+	name                 string
+	chainID, counterpart int64
+}
+
+// labelBridges marks the transfers of address to or from a configured
+// bridge contract as bridge transfers towards or from the bridge's
+// counterpart chain. Approvals, swaps and wraps keep their type, and so do
+// rows between two other addresses.
+func labelBridges(address string, rows []types.Transaction) {
+	contracts := make(map[int64]map[string]bridgeSide)
+	for _, b := range config.Current().Bridges {
+		chainID, err := utils.ChainIDByName(b.ChainName)
+		if err != nil {
+			continue
+		}
+		counterpart, err := utils.ChainIDByName(b.CounterpartChain)
+		if err != nil {
+			continue
+		}
+		if contracts[chainID] == nil {
+			contracts[chainID] = make(map[string]bridgeSide)
+		}
+		for _, c := range b.Contracts {
+			contracts[chainID][strings.ToLower(c)] = bridgeSide{b.Name, chainID, counterpart}
+		}
+	}
+	if len(contracts) == 0 {
+		return
+	}
+
+	for i, tx := range rows {
+		if tx.Type != types.TxTypeTransfer && tx.Type != types.TxTypeInternal {
+			continue
+		}
+		byContract := contracts[tx.ChainID]
+		if side, ok := byContract[strings.ToLower(tx.ToAddress)]; ok && strings.EqualFold(tx.FromAddress, address) {
+			rows[i].Type = types.TxTypeBridge
+			rows[i].Bridge = &types.BridgeInfo{Name: side.name, FromChainID: side.chainID, ToChainID: side.counterpart}
+		} else if side, ok := byContract[strings.ToLower(tx.FromAddress)]; ok && strings.EqualFold(tx.ToAddress, address) {
+			rows[i].Type = types.TxTypeBridge
+			rows[i].Bridge = &types.BridgeInfo{Name: side.name, FromChainID: side.counterpart, ToChainID: side.chainID}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package esplora serves Bitcoin-style UTXO chains through an Esplora
+// compatible REST API (Blockstream Esplora, mempool.space). Each transaction
+// is reduced to a single from/to/amount row relative to the queried address.
+package esplora
+
+import (
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*EsploraProvider)(nil)
+
+// EsploraProvider serves one UTXO chain from one Esplora endpoint.
+type EsploraProvider struct {
+	chainID int64
+	cfg     types.EsploraConfig
+}
+
+// NewEsploraProvider constructs a provider for one chain / one base-URL.
+func NewEsploraProvider(chainID int64, cfg types.EsploraConfig) *EsploraProvider {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Msg("Initializing EsploraProvider")
+
+	return &EsploraProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions pages through the confirmed history of the address and
+// converts every transaction into one types.Transaction.
+func (p *EsploraProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := params.Address
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from Esplora")
+
+	raw, err := p.fetchAddressTxs(params.RequestID, address, provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages))
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Esplora fetch failed")
+		return nil, err
+	}
+
+	all := make([]types.Transaction, 0, len(raw))
+	for _, tx := range raw {
+		if t, ok := p.transformTx(tx, address); ok {
+			all = append(all, t)
+		}
+	}
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("total", len(all)).
+		Msg("Esplora provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
@@ -0,0 +1,18 @@
+package esplora
+
+import (
+	"fmt"
+
+	"tx-aggregator/provider"
+	"tx-aggregator/utils"
+)
+
+var _ provider.HealthChecker = (*EsploraProvider)(nil)
+
+// HealthCheck reads the height of the chain tip, a plain number.
+func (p *EsploraProvider) HealthCheck() error {
+	var height int64
+	url := fmt.Sprintf("%s/blocks/tip/height", p.cfg.URL)
+	return utils.DoHttpRequestWithLogging("GET", "esplora.healthCheck", url, nil, nil, &height,
+		utils.WithProviderKey(provider.Key("esplora", p.cfg.ChainName)))
+}
@@ -0,0 +1,140 @@
+package esplora
+
+import (
+	"fmt"
+	"strconv"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	// confirmedPageSize is the fixed number of confirmed txs Esplora returns per page.
+	confirmedPageSize = 25
+	// defaultMaxPages caps pagination when max_pages is unset.
+	defaultMaxPages = 10
+	// btcDecimals is the number of decimals of a bitcoin (1 BTC = 1e8 sat).
+	btcDecimals = 8
+)
+
+// fetchAddressTxs reads /address/:address/txs and then follows
+// /address/:address/txs/chain/:last_seen_txid until a short page or maxPages.
+// Unconfirmed transactions on the first page are dropped later on. A failure
+// on a later page keeps the transactions collected so far.
+func (p *EsploraProvider) fetchAddressTxs(requestID, address string, maxPages int64) ([]types.EsploraTx, error) {
+	url := fmt.Sprintf("%s/address/%s/txs", p.cfg.URL, address)
+	var all []types.EsploraTx
+	for page := int64(0); page < maxPages; page++ {
+		var txs []types.EsploraTx
+		if err := utils.DoHttpRequestWithLogging("GET", "esplora.addressTxs", url, nil, nil, &txs,
+			utils.WithProviderKey(provider.Key("esplora", p.cfg.ChainName)), utils.WithRequestID(requestID)); err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Int64("page", page+1).
+				Msg("Esplora pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, txs...)
+
+		var confirmed []types.EsploraTx
+		for _, tx := range txs {
+			if tx.Status.Confirmed {
+				confirmed = append(confirmed, tx)
+			}
+		}
+		if len(confirmed) < confirmedPageSize {
+			break
+		}
+		lastSeen := confirmed[len(confirmed)-1].TxID
+		url = fmt.Sprintf("%s/address/%s/txs/chain/%s", p.cfg.URL, address, lastSeen)
+	}
+	return all, nil
+}
+
+// transformTx maps a UTXO transaction onto from/to/amount semantics relative
+// to address:
+//   - address spent inputs → outgoing: amount is what left to other addresses
+//     (change excluded), to is the first such recipient.
+//   - otherwise → incoming: amount is the sum of outputs paying address, from
+//     is the address of the first input ("" for coinbase).
+//
+// The fee is reported as gasUsed (satoshis) with gasPrice 1, so that
+// gasUsed × gasPrice is the fee just like on EVM chains. Unconfirmed
+// transactions are skipped.
+func (p *EsploraProvider) transformTx(tx types.EsploraTx, address string) (types.Transaction, bool) {
+	if !tx.Status.Confirmed {
+		return types.Transaction{}, false
+	}
+
+	var spent, received, sentOut int64
+	for _, in := range tx.Vin {
+		if in.Prevout != nil && in.Prevout.ScriptPubKeyAddress == address {
+			spent += in.Prevout.Value
+		}
+	}
+	recipient := ""
+	for _, out := range tx.Vout {
+		if out.ScriptPubKeyAddress == address {
+			received += out.Value
+			continue
+		}
+		sentOut += out.Value
+		if recipient == "" {
+			recipient = out.ScriptPubKeyAddress
+		}
+	}
+
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, btcDecimals)
+
+	t := types.Transaction{
+		ChainID:          p.chainID,
+		State:            types.TxStateSuccess,
+		Height:           tx.Status.BlockHeight,
+		Hash:             tx.TxID,
+		BlockHash:        tx.Status.BlockHash,
+		GasUsed:          strconv.FormatInt(tx.Fee, 10),
+		GasPrice:         "1",
+		Type:             types.TxTypeUnknown, // native transfer
+		CoinType:         types.CoinTypeNative,
+		TokenDisplayName: nativeSymbol,
+		Decimals:         nativeDecimals,
+		CreatedTime:      tx.Status.BlockTime,
+		ModifiedTime:     tx.Status.BlockTime,
+	}
+
+	var value int64
+	if spent > 0 {
+		t.TranType = types.TransTypeOut
+		t.FromAddress = address
+		t.ToAddress = recipient
+		if recipient == "" {
+			t.ToAddress = address // consolidation back to the same address
+		}
+		value = sentOut
+	} else {
+		t.TranType = types.TransTypeIn
+		t.ToAddress = address
+		if len(tx.Vin) > 0 && tx.Vin[0].Prevout != nil {
+			t.FromAddress = tx.Vin[0].Prevout.ScriptPubKeyAddress
+		}
+		value = received
+	}
+
+	t.Balance = strconv.FormatInt(value, 10)
+	t.Amount = utils.DivideByDecimals(t.Balance, int(nativeDecimals))
+	return t, true
+}
@@ -0,0 +1,123 @@
+// Package oklink serves chains that neither Ankr nor Blockscout cover through
+// the OKLink explorer API (v5). Requests are authenticated with the
+// Ok-Access-Key header and paginated by page number up to the reported
+// totalPage.
+package oklink
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*OKLinkProvider)(nil)
+
+const (
+	defaultURL = "https://www.oklink.com"
+	// accessKeyHeader carries the OKLink API key.
+	accessKeyHeader = "Ok-Access-Key"
+)
+
+// protocolTypes are the transaction lists requested per address.
+var protocolTypes = []string{
+	types.OKLinkProtocolTransaction,
+	types.OKLinkProtocolInternal,
+	types.OKLinkProtocolToken20,
+	types.OKLinkProtocolToken721,
+}
+
+// OKLinkProvider serves one chain from the OKLink explorer API.
+type OKLinkProvider struct {
+	chainID int64
+	chain   types.OKLinkChainConfig
+	cfg     types.OKLinkConfig
+
+	mu        sync.Mutex
+	precision map[string]int64 // token contract (lower-case) → decimals
+}
+
+// NewOKLinkProvider constructs a provider for one chain served by OKLink.
+func NewOKLinkProvider(chainID int64, chain types.OKLinkChainConfig, cfg types.OKLinkConfig) *OKLinkProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	logger.Log.Info().
+		Str("chain", chain.ChainName).
+		Str("chain_short_name", chain.ChainShortName).
+		Msg("Initializing OKLinkProvider")
+
+	return &OKLinkProvider{
+		chainID:   chainID,
+		chain:     chain,
+		cfg:       cfg,
+		precision: make(map[string]int64),
+	}
+}
+
+// GetTransactions fetches every protocol type concurrently and returns them
+// as a single TransactionResponse.
+func (p *OKLinkProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := params.Address
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.chain.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from OKLink")
+
+	results := make([][]types.Transaction, len(protocolTypes))
+
+	g := new(errgroup.Group)
+	for i, protocol := range protocolTypes {
+		g.Go(func() error {
+			items, err := p.fetchTransactionList(params.RequestID, address, protocol, maxPages)
+			if err != nil {
+				return err
+			}
+			results[i] = p.transformTransactions(items, protocol, address)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Msg("OKLink fetch failed")
+		return nil, err
+	}
+
+	var all []types.Transaction
+	for _, txs := range results {
+		all = append(all, txs...)
+	}
+
+	logger.Log.Info().
+		Str("provider", p.chain.ChainName).
+		Int("normal", len(results[0])).
+		Int("internal", len(results[1])).
+		Int("token", len(results[2])+len(results[3])).
+		Int("total", len(all)).
+		Msg("OKLink provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// sendRequest performs an authenticated GET against the OKLink API. Extra options,
+// such as the request ID, are passed through.
+func (p *OKLinkProvider) sendRequest(label, url string, out interface{}, opts ...utils.RequestOption) error {
+	return utils.DoHttpRequestWithLogging(
+		"GET", label, url, nil,
+		map[string]string{accessKeyHeader: p.cfg.APIKey},
+		out,
+		append([]utils.RequestOption{utils.WithProviderKey(provider.Key("oklink", p.chain.ChainName))}, opts...)...,
+	)
+}
@@ -0,0 +1,101 @@
+package oklink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// precisionLookups bounds the concurrent token-list lookups of one batch.
+const precisionLookups = 4
+
+// tokenPrecisions returns the decimals of every distinct token contract among
+// items, keyed by lower-case contract. Known contracts come from the cache,
+// the rest are looked up once each before any row is converted. A contract
+// whose lookup failed is missing from the result and retried on the next
+// request.
+func (p *OKLinkProvider) tokenPrecisions(items []types.OKLinkTransaction) map[string]int64 {
+	out := make(map[string]int64)
+	var missing []string
+	p.mu.Lock()
+	for _, it := range items {
+		key := strings.ToLower(it.TokenContractAddress)
+		if key == "" {
+			continue
+		}
+		if _, seen := out[key]; seen {
+			continue
+		}
+		decimals, ok := p.precision[key]
+		if !ok {
+			decimals = -1 // looked up below
+			missing = append(missing, key)
+		}
+		out[key] = decimals
+	}
+	p.mu.Unlock()
+
+	var mu sync.Mutex
+	g := new(errgroup.Group)
+	g.SetLimit(precisionLookups)
+	for _, contract := range missing {
+		g.Go(func() error {
+			decimals, err := p.fetchTokenPrecision(contract)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.Log.Warn().
+					Err(err).
+					Str("chain", p.chain.ChainName).
+					Str("token", contract).
+					Msg("OKLink token precision lookup failed, leaving amounts unscaled")
+				delete(out, contract)
+				return nil
+			}
+			out[contract] = decimals
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	p.mu.Lock()
+	for _, contract := range missing {
+		if decimals, ok := out[contract]; ok {
+			p.precision[contract] = decimals
+		}
+	}
+	p.mu.Unlock()
+	return out
+}
+
+// fetchTokenPrecision looks up a token contract in /explorer/token/token-list.
+func (p *OKLinkProvider) fetchTokenPrecision(contract string) (int64, error) {
+	q := url.Values{
+		"chainShortName":       {p.chain.ChainShortName},
+		"tokenContractAddress": {contract},
+	}
+	u := fmt.Sprintf("%s/api/v5/explorer/token/token-list?%s", p.cfg.URL, q.Encode())
+
+	var out types.OKLinkTokenListResponse
+	if err := p.sendRequest("oklink.tokenList", u, &out); err != nil {
+		return 0, err
+	}
+	if out.Code != types.OKLinkSuccessCode {
+		return 0, &types.OKLinkError{Code: out.Code, Msg: out.Msg}
+	}
+	if len(out.Data) == 0 || len(out.Data[0].TokenList) == 0 {
+		return 0, fmt.Errorf("token %s not found", contract)
+	}
+	precision := out.Data[0].TokenList[0].Precision
+	decimals := utils.ParseStringToInt64OrDefault(precision, -1)
+	if decimals < 0 {
+		return 0, fmt.Errorf("token %s has invalid precision %q", contract, precision)
+	}
+	return decimals, nil
+}
@@ -0,0 +1,190 @@
+package oklink
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	// defaultPageSize is OKLink's maximum limit per page.
+	defaultPageSize = 100
+	// defaultMaxPages caps pagination when max_pages is unset.
+	defaultMaxPages = 10
+)
+
+// fetchTransactionList pages through the address transaction list of one
+// protocol type until totalPage or maxPages is reached. A failure on a
+// later page keeps the rows collected so far.
+func (p *OKLinkProvider) fetchTransactionList(requestID, address, protocol string, maxPages int64) ([]types.OKLinkTransaction, error) {
+	var all []types.OKLinkTransaction
+	for page := int64(1); page <= maxPages; page++ {
+		resp, err := p.fetchTransactionListPage(requestID, address, protocol, page)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.chain.ChainName).
+				Str("protocol", protocol).
+				Int64("page", page).
+				Msg("OKLink pagination aborted, keeping earlier pages")
+			break
+		}
+		if len(resp.Data) == 0 {
+			break
+		}
+		all = append(all, resp.Data[0].TransactionLists...)
+
+		totalPage := utils.ParseStringToInt64OrDefault(resp.Data[0].TotalPage, 0)
+		if page >= totalPage {
+			break
+		}
+	}
+
+	logger.Log.Debug().
+		Str("chain", p.chain.ChainName).
+		Str("protocol", protocol).
+		Int("items", len(all)).
+		Msg("OKLink pagination finished")
+	return all, nil
+}
+
+// fetchTransactionListPage requests a single page of one protocol type.
+func (p *OKLinkProvider) fetchTransactionListPage(requestID, address, protocol string, page int64) (*types.OKLinkTransactionListResponse, error) {
+	pageSize := p.cfg.RequestPageSize
+	if pageSize <= 0 || pageSize > defaultPageSize {
+		pageSize = defaultPageSize
+	}
+	q := url.Values{
+		"chainShortName": {p.chain.ChainShortName},
+		"address":        {address},
+		"protocolType":   {protocol},
+		"page":           {strconv.FormatInt(page, 10)},
+		"limit":          {strconv.FormatInt(pageSize, 10)},
+	}
+	u := fmt.Sprintf("%s/api/v5/explorer/address/transaction-list?%s", p.cfg.URL, q.Encode())
+
+	var out types.OKLinkTransactionListResponse
+	if err := p.sendRequest("oklink.transactionList", u, &out, utils.WithRequestID(requestID)); err != nil {
+		return nil, err
+	}
+	if out.Code != types.OKLinkSuccessCode {
+		logger.Log.Error().
+			Str("error_code", out.Code).
+			Str("error_message", out.Msg).
+			Str("chain", p.chain.ChainName).
+			Str("protocol", protocol).
+			Msg("OKLink API returned an error in transaction list response")
+		return nil, &types.OKLinkError{Code: out.Code, Msg: out.Msg}
+	}
+	return &out, nil
+}
+
+// transformTransactions converts OKLink rows of one protocol type into
+// types.Transaction. OKLink reports amounts already scaled, so the raw
+// Balance is rebuilt from the asset's decimals; a token whose decimals are
+// unknown keeps only its scaled Amount. txFee, in the native coin, becomes
+// Fee in its smallest unit; the list carries no gas used / gas price.
+// Pending rows are skipped.
+func (p *OKLinkProvider) transformTransactions(items []types.OKLinkTransaction, protocol, address string) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+
+	var precisions map[string]int64
+	if protocol == types.OKLinkProtocolToken20 {
+		precisions = p.tokenPrecisions(items)
+	}
+
+	var txs []types.Transaction
+	for _, it := range items {
+		if it.State != "success" && it.State != "fail" {
+			continue
+		}
+		state := types.TxStateFail
+		if it.State == "success" {
+			state = types.TxStateSuccess
+		}
+
+		tranType := types.TransTypeOut
+		if strings.EqualFold(it.To, address) {
+			tranType = types.TransTypeIn
+		}
+		unixTime := utils.ParseStringToInt64OrDefault(it.TransactionTime, 0) / 1000
+
+		tx := types.Transaction{
+			ChainID:      p.chainID,
+			State:        state,
+			Height:       utils.ParseStringToInt64OrDefault(it.Height, 0),
+			Hash:         it.TxID,
+			BlockHash:    it.BlockHash,
+			FromAddress:  it.From,
+			ToAddress:    it.To,
+			Amount:       it.Amount,
+			CreatedTime:  unixTime,
+			ModifiedTime: unixTime,
+			TranType:     tranType,
+		}
+		if protocol != types.OKLinkProtocolInternal && it.TxFee != "" {
+			// Internal rows share the fee of their parent transaction
+			if fee, err := utils.MultiplyByDecimals(it.TxFee, int(nativeDecimals)); err == nil {
+				tx.Fee = fee
+			}
+		}
+
+		switch protocol {
+		case types.OKLinkProtocolTransaction, types.OKLinkProtocolInternal:
+			tx.Type = types.TxTypeUnknown // native transfer
+			tx.CoinType = types.CoinTypeNative
+			tx.TokenDisplayName = nativeSymbol
+			tx.Decimals = nativeDecimals
+			if protocol == types.OKLinkProtocolInternal {
+				tx.Type = types.TxTypeInternal
+				tx.CoinType = types.CoinTypeInternal
+			}
+		case types.OKLinkProtocolToken721:
+			tx.Type = types.TxTypeTransfer
+			tx.CoinType = types.CoinTypeERC721
+			tx.TokenAddress = it.TokenContractAddress
+			tx.TokenDisplayName = it.TransactionSymbol
+			if id, err := utils.NormalizeNumericString(it.TokenID); err == nil {
+				tx.TokenIDRaw = id
+				tx.TokenID = utils.TokenIDToInt64(id)
+			}
+			tx.Balance = "1"
+			tx.Amount = "1"
+			txs = append(txs, tx)
+			continue
+		default: // token_20
+			tx.Type = types.TxTypeTransfer
+			tx.CoinType = types.CoinTypeToken
+			tx.TokenAddress = it.TokenContractAddress
+			tx.TokenDisplayName = it.TransactionSymbol
+			decimals, ok := precisions[strings.ToLower(it.TokenContractAddress)]
+			if !ok {
+				txs = append(txs, tx)
+				continue
+			}
+			tx.Decimals = decimals
+		}
+
+		if raw, err := utils.MultiplyByDecimals(it.Amount, int(tx.Decimals)); err == nil {
+			tx.Balance = raw
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
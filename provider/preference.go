@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+
+	"tx-aggregator/config"
+)
+
+// randFloat is replaced in tests.
+var randFloat = rand.Float64
+
+// orderByPreference reorders the providers of one chain according to
+// providers.preferences: by priority, then by a weighted random draw among
+// providers of equal priority, with providers at their in-flight limit moved
+// to the back. Providers without preferences keep their relative
+// chain_providers order at priority 0.
+func (r *routing) orderByPreference(keys []string) []string {
+	prefs := config.Current().Providers.Preferences
+	if len(prefs) == 0 || len(keys) < 2 {
+		return keys
+	}
+
+	type ranked struct {
+		key        string
+		pos        int
+		priority   int
+		draw       float64
+		overloaded bool
+	}
+	rs := make([]ranked, len(keys))
+	for i, key := range keys {
+		p := prefs[key]
+		weight := p.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		rs[i] = ranked{
+			key:        key,
+			pos:        i,
+			priority:   p.Priority,
+			draw:       weightedDraw(weight),
+			overloaded: p.MaxInFlight > 0 && r.inFlightOf(key) >= int64(p.MaxInFlight),
+		}
+	}
+
+	// Only draw between providers that share a priority and have a weight
+	// configured somewhere in the group; otherwise keep the YAML order.
+	weighted := make(map[int]bool)
+	for _, c := range rs {
+		if prefs[c.key].Weight > 0 {
+			weighted[c.priority] = true
+		}
+	}
+
+	sort.SliceStable(rs, func(i, j int) bool {
+		a, b := rs[i], rs[j]
+		if a.overloaded != b.overloaded {
+			return !a.overloaded
+		}
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+		if weighted[a.priority] && a.draw != b.draw {
+			return a.draw > b.draw
+		}
+		return a.pos < b.pos
+	})
+
+	out := make([]string, len(rs))
+	for i, c := range rs {
+		out[i] = c.key
+	}
+	return out
+}
+
+// orderByRanking reorders the providers of chain as the ranking source ranks
+// them, when benchmark.route is set. Providers the ranking does not cover,
+// such as ones added since the last run, follow in their current order.
+func (m *MultiProvider) orderByRanking(chain string, keys []string) []string {
+	if m.ranking == nil || len(keys) < 2 || !config.Current().Benchmark.Route {
+		return keys
+	}
+	ranking := m.ranking.Ranking(chain)
+	if len(ranking) == 0 {
+		return keys
+	}
+	pos := func(key string) int {
+		if i := slices.Index(ranking, key); i >= 0 {
+			return i
+		}
+		return len(ranking)
+	}
+	out := slices.Clone(keys)
+	sort.SliceStable(out, func(i, j int) bool { return pos(out[i]) < pos(out[j]) })
+	return out
+}
+
+// weightedDraw returns a random sort key such that sorting descending picks
+// each provider first with probability weight / total weight
+// (Efraimidis–Spirakis).
+func weightedDraw(weight int) float64 {
+	return math.Pow(randFloat(), 1/float64(weight))
+}
+
+// inFlightOf returns how many calls to key are currently running.
+func (r *routing) inFlightOf(key string) int64 {
+	if c, ok := r.inFlight[key]; ok {
+		return c.Load()
+	}
+	return 0
+}
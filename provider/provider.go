@@ -29,12 +29,18 @@ package provider
 import (
 	"context"
 	"errors"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"tx-aggregator/config"
 	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
 	"tx-aggregator/types"
+	"tx-aggregator/utils"
 )
 
 // Provider is the interface every concrete data source must satisfy.
@@ -42,121 +48,362 @@ type Provider interface {
 	GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error)
 }
 
+// HealthChecker is implemented by providers that can be probed cheaply, e.g.
+// by asking for the latest block. Providers without it are never probed and
+// always considered healthy.
+type HealthChecker interface {
+	HealthCheck() error
+}
+
+// HealthSource reports whether a registered provider may currently be routed
+// to.
+type HealthSource interface {
+	Healthy(key string) bool
+}
+
+// RankingSource orders the providers of a chain best first, e.g. from the
+// latest provider benchmark. It returns nil for a chain it has not ranked.
+type RankingSource interface {
+	Ranking(chain string) []string
+}
+
+// Key returns the registry key of a per-chain provider, e.g.
+// Key("blockscan", "ETH") is "blockscan_eth". Providers use it to find
+// their own settings, such as providers.rate_limits.
+func Key(kind, chainName string) string {
+	return kind + "_" + strings.ToLower(chainName)
+}
+
+// Registry gives access to the current provider registry, which may be
+// replaced when the configuration is hot-reloaded.
+type Registry interface {
+	Providers() map[string]Provider
+}
+
+// StaticRegistry is a Registry that never changes.
+type StaticRegistry map[string]Provider
+
+// Providers returns the registry itself.
+func (s StaticRegistry) Providers() map[string]Provider { return s }
+
 // MultiProvider dispatches a single request to several Providers concurrently
 // and merges their results.
 type MultiProvider struct {
-	providers      map[string]Provider // providerKey -> concrete provider
-	chainProviders map[string]string   // chainName   -> providerKey (from YAML)
+	build   func(types.Config) map[string]Provider // nil = fixed registry
+	health  HealthSource                           // nil = every provider is healthy
+	ranking RankingSource                          // nil = chain_providers order
+
+	mu      sync.Mutex // serialises rebuilds
+	routing atomic.Pointer[routing]
+}
+
+// routing is the provider registry and chain mapping derived from one
+// configuration snapshot. It is replaced as a whole, never modified.
+type routing struct {
+	generation     uint64                   // config.Generation() it was derived from
+	providers      map[string]Provider      // providerKey -> concrete provider
+	chainProviders map[string][]string      // chainName   -> providerKeys by preference (from YAML)
+	inFlight       map[string]*atomic.Int64 // providerKey -> calls currently running
 }
 
-// NewMultiProvider builds a MultiProvider from an already-initialised registry.
+// NewMultiProvider builds a MultiProvider from an already-initialised
+// registry. The chain mapping follows configuration reloads, the registry
+// does not.
 func NewMultiProvider(registry map[string]Provider) *MultiProvider {
-	return &MultiProvider{
-		providers:      registry,
-		chainProviders: config.Current().Providers.ChainProviders, // YAML-driven
+	m := &MultiProvider{}
+	gen := config.Generation()
+	m.routing.Store(newRouting(gen, registry, config.Current().Providers.ChainProviders, nil))
+	return m
+}
+
+// NewReloadingMultiProvider builds the registry with build and builds it
+// again, together with the chain mapping, whenever the configuration
+// snapshot changes, so providers added to the configuration are served
+// without a restart.
+func NewReloadingMultiProvider(build func(types.Config) map[string]Provider) *MultiProvider {
+	m := &MultiProvider{build: build}
+	gen, cfg := config.Generation(), config.Current()
+	m.routing.Store(newRouting(gen, build(cfg), cfg.Providers.ChainProviders, nil))
+	return m
+}
+
+// newRouting assembles a routing, keeping the in-flight counters of
+// providers that were already registered in prev.
+func newRouting(gen uint64, providers map[string]Provider, chainProviders map[string][]string, prev map[string]*atomic.Int64) *routing {
+	inFlight := make(map[string]*atomic.Int64, len(providers))
+	for key := range providers {
+		if c, ok := prev[key]; ok {
+			inFlight[key] = c
+		} else {
+			inFlight[key] = new(atomic.Int64)
+		}
+	}
+	return &routing{
+		generation:     gen,
+		providers:      providers,
+		chainProviders: chainProviders,
+		inFlight:       inFlight,
 	}
 }
 
+// current returns the routing of the latest configuration snapshot,
+// deriving it first when the configuration changed since the last call.
+func (m *MultiProvider) current() *routing {
+	gen := config.Generation()
+	if r := m.routing.Load(); r.generation == gen {
+		return r
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.routing.Load()
+	if r.generation == gen {
+		return r
+	}
+
+	cfg := config.Current()
+	providers := r.providers
+	if m.build != nil {
+		providers = m.build(cfg)
+		logger.Log.Info().
+			Int("providers", len(providers)).
+			Msg("Provider registry rebuilt after configuration change")
+	}
+	next := newRouting(gen, providers, cfg.Providers.ChainProviders, r.inFlight)
+	m.routing.Store(next)
+	return next
+}
+
+// Providers returns the current provider registry, keyed by provider key.
+// Callers must not modify it.
+func (m *MultiProvider) Providers() map[string]Provider {
+	return m.current().providers
+}
+
+// SetHealth makes the MultiProvider skip providers that h reports as
+// unhealthy, as long as a chain has another provider left.
+func (m *MultiProvider) SetHealth(h HealthSource) {
+	m.health = h
+}
+
+// SetRanking makes the MultiProvider try the providers of a chain in the
+// order r ranks them, instead of the chain_providers order, while
+// benchmark.route is set. providers.preferences still apply on top.
+func (m *MultiProvider) SetRanking(r RankingSource) {
+	m.ranking = r
+}
+
+// attempt is the outcome of one provider call.
+type attempt struct {
+	key  string
+	txs  []types.Transaction
+	err  error
+	cost time.Duration
+}
+
 // GetTransactions decides which concrete providers to call, fans out the
 // requests, waits for all of them (or a global timeout), merges the
 // Transaction slices, and returns a single response.
+//
+// Each chain is served by the first of its providers that answers: when one
+// fails, or does not answer within the failover timeout, the chain moves on
+// to the next, while a provider it left on timeout may still answer for it. A provider shared by several chains is called at most once,
+// and its rows are kept only for the chains it ended up serving. Its answer
+// still takes part in settling the state and gas fields of transactions that
+// another provider returned too (see reconcile).
 func (m *MultiProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	log := logger.ForRequest(params.RequestID)
 	// ----- 1. Choose providers ------------------------------------------------
-	needed := make(map[string]Provider) // providerKey -> Provider
-
-	if len(params.ChainNames) == 0 {
-		// Client did not specify chains → use every provider referenced in YAML.
-		for _, key := range m.chainProviders {
-			if p, ok := m.providers[key]; ok {
-				needed[key] = p
-			}
-		}
-	} else {
-		// Filter by requested chain names.
-		for _, chain := range params.ChainNames {
-			chain = strings.ToLower(strings.TrimSpace(chain))
-			if key, ok := m.chainProviders[chain]; ok {
-				if p, ok2 := m.providers[key]; ok2 {
-					needed[key] = p
-				} else {
-					logger.Log.Warn().
-						Str("provider_key", key).
-						Msg("Provider key listed in YAML but not registered")
-				}
-			} else {
-				logger.Log.Warn().
-					Str("chain_name", chain).
-					Msg("No provider mapping for chain")
-			}
-		}
-	}
+	r := m.current()
+	queues := m.providerQueues(r, params.ChainNames) // chain -> providerKeys not tried yet
 
-	if len(needed) == 0 {
+	if len(queues) == 0 {
 		return nil, errors.New("no providers selected for requested chains")
 	}
 
-	// ----- 2. Fan-out calls ---------------------------------------------------
+	// ----- 2. Fan-out calls, failing over per chain ---------------------------
 	ctx, cancel := context.WithTimeout(
 		context.Background(),
 		time.Duration(config.Current().Providers.RequestTimeout)*time.Second,
 	)
 	defer cancel()
+	failoverAfter := failoverTimeout()
 
-	resCh := make(chan []types.Transaction, len(needed))
-	errCh := make(chan error, len(needed))
+	distinct := make(map[string]struct{})
+	for _, keys := range queues {
+		for _, key := range keys {
+			distinct[key] = struct{}{}
+		}
+	}
+	resCh := make(chan attempt, len(distinct)) // every provider is started at most once
 
-	idx := 0
-	for key, p := range needed {
-		go func(i int, prov Provider, name string) {
-			start := time.Now()
-			resp, err := prov.GetTransactions(params)
-			cost := time.Since(start)
+	var (
+		started = make(map[string]bool)      // providerKey -> call launched
+		results = make(map[string]attempt)   // providerKey -> finished call
+		current = make(map[string]string)    // chain -> providerKey awaited
+		since   = make(map[string]time.Time) // chain -> when it started awaiting
+		served  = make(map[string]string)    // chain -> providerKey that answered
+		tried   = make(map[string][]string)  // chain -> providerKeys taken from its queue
+		failed  int
+	)
 
-			if err != nil {
-				logger.Log.Warn().
-					Err(err).
-					Str("provider", name).
-					Dur("cost", cost).
-					Msg("Provider failed")
-				errCh <- err
+	start := func(key string) {
+		started[key] = true
+		go func(prov Provider, name string) {
+			inFlight := metrics.ProviderInFlight.WithLabelValues(name)
+			inFlight.Inc()
+			r.inFlight[name].Add(1)
+			t := time.Now()
+			resp, err := getTransactions(prov, name, params)
+			r.inFlight[name].Add(-1)
+			inFlight.Dec()
+
+			res := attempt{key: name, err: err, cost: time.Since(t)}
+			params.Timings.Add(types.TimingProviderPrefix+name, res.cost)
+			if err == nil {
+				res.txs = resp.Result.Transactions
+			}
+			resCh <- res
+		}(r.providers[key], key)
+	}
+
+	// advance moves chain to its next provider, reusing a call that has
+	// already finished or is still running. With no provider left, the chain
+	// goes back to an earlier one it failed over from on timeout, and only
+	// fails once none of them is still running.
+	var advance func(chain string)
+	advance = func(chain string) {
+		delete(current, chain)
+		if len(queues[chain]) == 0 {
+			for _, key := range tried[chain] {
+				res, done := results[key]
+				switch {
+				case done && res.err == nil:
+					served[chain] = key
+					return
+				case !done && started[key]:
+					log.Debug().Str("chain_name", chain).Str("provider", key).Msg("No provider left for chain, waiting for an earlier one")
+					current[chain] = key
+					since[chain] = time.Now()
+					return
+				}
+			}
+			log.Warn().Str("chain_name", chain).Msg("No provider left for chain")
+			failed++
+			return
+		}
+		key := queues[chain][0]
+		queues[chain] = queues[chain][1:]
+		tried[chain] = append(tried[chain], key)
+
+		if res, ok := results[key]; ok {
+			if res.err == nil {
+				served[chain] = key
 				return
 			}
+			advance(chain)
+			return
+		}
+		current[chain] = key
+		since[chain] = time.Now()
+		if !started[key] {
+			start(key)
+		}
+	}
 
-			logger.Log.Info().
-				Str("provider", name).
-				Dur("cost", cost).
-				Int("tx_count", len(resp.Result.Transactions)).
-				Msg("Provider finished")
-			resCh <- resp.Result.Transactions
-		}(idx, p, key)
-		idx++
+	for chain := range queues {
+		advance(chain)
 	}
 
-	// ----- 3. Collect results -------------------------------------------------
-	var (
-		allTxs       []types.Transaction
-		successCount int
-		failCount    int
-	)
+	for len(current) > 0 {
+		// Wake up for the earliest chain that may fail over on timeout.
+		var wake <-chan time.Time
+		var next time.Time
+		for chain := range current {
+			if len(queues[chain]) == 0 {
+				continue
+			}
+			if at := since[chain].Add(failoverAfter); next.IsZero() || at.Before(next) {
+				next = at
+			}
+		}
+		if !next.IsZero() {
+			wake = time.After(time.Until(next))
+		}
 
-	for done := 0; done < len(needed); done++ {
 		select {
-		case txs := <-resCh:
-			allTxs = append(allTxs, txs...)
-			successCount++
-		case err := <-errCh:
-			logger.Log.Warn().Err(err).Msg("Provider error")
-			failCount++
+		case res := <-resCh:
+			results[res.key] = res
+			if res.err != nil {
+				log.Warn().
+					Err(res.err).
+					Str("provider", res.key).
+					Dur("cost", res.cost).
+					Msg("Provider failed")
+			} else {
+				log.Info().
+					Str("provider", res.key).
+					Dur("cost", res.cost).
+					Int("tx_count", len(res.txs)).
+					Msg("Provider finished")
+			}
+			for chain, key := range current {
+				if res.err == nil && key != res.key && slices.Contains(tried[chain], res.key) {
+					// An earlier provider the chain failed over from answered
+					// after all: it serves the chain.
+					delete(current, chain)
+					served[chain] = res.key
+					continue
+				}
+				if key != res.key {
+					continue
+				}
+				if res.err == nil {
+					delete(current, chain)
+					served[chain] = key
+					continue
+				}
+				if len(queues[chain]) > 0 {
+					metrics.ProviderFailovers.WithLabelValues(chain, "error").Inc()
+					log.Warn().
+						Str("chain_name", chain).
+						Str("provider", key).
+						Str("next", queues[chain][0]).
+						Msg("Provider failed, failing over")
+				}
+				advance(chain)
+			}
+		case <-wake:
+			now := time.Now()
+			for chain, key := range current {
+				if len(queues[chain]) == 0 || now.Before(since[chain].Add(failoverAfter)) {
+					continue
+				}
+				metrics.ProviderFailovers.WithLabelValues(chain, "timeout").Inc()
+				log.Warn().
+					Str("chain_name", chain).
+					Str("provider", key).
+					Str("next", queues[chain][0]).
+					Dur("waited", failoverAfter).
+					Msg("Provider too slow, failing over")
+				advance(chain)
+			}
 		case <-ctx.Done():
 			return nil, ctx.Err() // global timeout
 		}
 	}
 
-	if successCount == 0 && failCount > 0 {
+	if len(served) == 0 && failed > 0 {
 		return nil, errors.New("all selected providers failed")
 	}
 
-	// ----- 4. Merge & return --------------------------------------------------
+	// ----- 3. Merge & return --------------------------------------------------
+	allTxs := utils.DedupTransactions(mergeServed(served, results))
+	reconcile(allTxs, results)
+	allTxs = traceInternals(params, served, allTxs)
+	fillSwaps(params.Address, allTxs)
+	labelBridges(params.Address, allTxs)
+	utils.MarkEnrichment(allTxs)
 	return &types.TransactionResponse{
 		Result: struct {
 			Transactions []types.Transaction `json:"transactions"`
@@ -165,3 +412,141 @@ func (m *MultiProvider) GetTransactions(params *types.TransactionQueryParams) (*
 		},
 	}, nil
 }
+
+// providerQueues returns, for each requested chain (every configured chain
+// when none is requested), its registered provider keys in order of
+// preference. Unhealthy providers are dropped unless none of the chain's
+// providers is healthy, and the rest are ordered by the benchmark ranking
+// (see SetRanking), then by providers.preferences. Chains without any
+// registered provider are left out.
+func (m *MultiProvider) providerQueues(r *routing, chainNames []string) map[string][]string {
+	if len(chainNames) == 0 {
+		// Client did not specify chains → use every chain referenced in YAML.
+		for chain := range r.chainProviders {
+			chainNames = append(chainNames, chain)
+		}
+	}
+
+	queues := make(map[string][]string)
+	for _, chain := range chainNames {
+		chain = strings.ToLower(strings.TrimSpace(chain))
+		keys, ok := r.chainProviders[chain]
+		if !ok {
+			logger.Log.Warn().
+				Str("chain_name", chain).
+				Msg("No provider mapping for chain")
+			continue
+		}
+		for _, key := range keys {
+			if _, ok := r.providers[key]; !ok {
+				logger.Log.Warn().
+					Str("provider_key", key).
+					Msg("Provider key listed in YAML but not registered")
+				continue
+			}
+			queues[chain] = append(queues[chain], key)
+		}
+	}
+	for chain, keys := range queues {
+		if len(keys) == 0 {
+			delete(queues, chain)
+			continue
+		}
+		if healthy := m.healthyKeys(keys); len(healthy) > 0 {
+			keys = healthy
+		} else if m.health != nil {
+			logger.Log.Warn().
+				Str("chain_name", chain).
+				Strs("providers", keys).
+				Msg("All providers of chain unhealthy, trying them anyway")
+		}
+		queues[chain] = r.orderByPreference(m.orderByRanking(chain, keys))
+	}
+	return queues
+}
+
+// healthyKeys returns the keys the health source reports as healthy, in
+// order.
+func (m *MultiProvider) healthyKeys(keys []string) []string {
+	if m.health == nil {
+		return keys
+	}
+	healthy := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if m.health.Healthy(key) {
+			healthy = append(healthy, key)
+		}
+	}
+	return healthy
+}
+
+// getTransactions calls prov and turns a panic in it into an error, so the
+// attempt fails over like any other failure instead of crashing the process.
+func getTransactions(prov Provider, name string, params *types.TransactionQueryParams) (resp *types.TransactionResponse, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			resp, err = nil, utils.LogPanic("provider:"+name, e)
+		}
+	}()
+	return prov.GetTransactions(params)
+}
+
+// mergeServed concatenates the rows of every provider that served a chain.
+// A provider's rows are limited to the chains it served, so that a primary
+// that answered late does not duplicate the rows of its fallback; when one
+// of those chains has no known chain ID, all of its rows are kept.
+func mergeServed(served map[string]string, results map[string]attempt) []types.Transaction {
+	chainIDs := make(map[string]map[int64]struct{}) // providerKey -> served chain IDs, nil = keep all
+	keepAll := make(map[string]bool)
+	for chain, key := range served {
+		if chainIDs[key] == nil {
+			chainIDs[key] = make(map[int64]struct{})
+		}
+		id, err := utils.ChainIDByName(chain)
+		if err != nil {
+			keepAll[key] = true
+			continue
+		}
+		chainIDs[key][id] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(chainIDs))
+	for key := range chainIDs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var all []types.Transaction
+	for _, key := range keys {
+		for _, tx := range results[key].txs {
+			if _, ok := chainIDs[key][tx.ChainID]; ok || keepAll[key] {
+				all = append(all, tx)
+			}
+		}
+	}
+	return all
+}
+
+// failoverTimeout returns how long a chain waits for a provider before
+// moving on to the next one: providers.failover_timeout, else half of
+// providers.request_timeout.
+func failoverTimeout() time.Duration {
+	cfg := config.Current().Providers
+	if cfg.FailoverTimeout > 0 {
+		return time.Duration(cfg.FailoverTimeout) * time.Second
+	}
+	return time.Duration(cfg.RequestTimeout) * time.Second / 2
+}
+
+// MaxPages returns the page cap of one fetch: the per-request override set by
+// deep-pagination callers such as the backfill job, else the provider's
+// configured max_pages, else def.
+func MaxPages(params *types.TransactionQueryParams, configured, def int64) int64 {
+	if params != nil && params.MaxPages > 0 {
+		return params.MaxPages
+	}
+	if configured > 0 {
+		return configured
+	}
+	return def
+}
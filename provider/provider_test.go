@@ -1,9 +1,14 @@
 package provider
 
 import (
+	"encoding/json"
 	"errors"
-	"os"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
 	"sort"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,9 +22,11 @@ type mockProvider struct {
 	transactions []types.Transaction
 	err          error
 	delay        time.Duration
+	calls        atomic.Int32
 }
 
 func (m *mockProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	m.calls.Add(1)
 	if m.delay > 0 {
 		time.Sleep(m.delay)
 	}
@@ -38,7 +45,7 @@ func (m *mockProvider) GetTransactions(params *types.TransactionQueryParams) (*t
 }
 
 // prepareTestMultiProvider sets the current configuration and returns a MultiProvider
-func prepareTestMultiProvider(providers map[string]Provider, chainMap map[string]string, timeout int64) *MultiProvider {
+func prepareTestMultiProvider(providers map[string]Provider, chainMap map[string][]string, timeout int64) *MultiProvider {
 	cfg := types.Config{
 		Providers: types.ProvidersConfig{
 			RequestTimeout: timeout,
@@ -53,10 +60,9 @@ func prepareTestMultiProvider(providers map[string]Provider, chainMap map[string
 
 // configForTest manually injects configuration for tests
 func configForTest(cfg types.Config) {
-	_ = os.Setenv("APP_ENV", "test")      // avoid loading remote config
-	config.Init(&types.BootstrapConfig{}) // Init will override with defaults
-	// overwrite with test config
-	val := config.Current()
+	// Init would refuse the empty test configuration, so start from a fresh
+	// snapshot with its defaults and overwrite it with the test config
+	val := types.Config{Server: types.ServerConfig{Port: 8080}}
 	val.Providers = cfg.Providers
 	// simulate hot-reload behavior
 	cfg = val
@@ -76,9 +82,9 @@ func TestMultiProvider_AllSuccess(t *testing.T) {
 		"p1": p1,
 		"p2": p2,
 	}
-	chainMap := map[string]string{
-		"eth": "p1",
-		"bsc": "p2",
+	chainMap := map[string][]string{
+		"eth": {"p1"},
+		"bsc": {"p2"},
 	}
 
 	mp := prepareTestMultiProvider(providerMap, chainMap, 3)
@@ -105,7 +111,7 @@ func TestMultiProvider_SomeFail(t *testing.T) {
 
 	mp := prepareTestMultiProvider(
 		map[string]Provider{"p1": p1, "p2": p2},
-		map[string]string{"eth": "p1", "bsc": "p2"},
+		map[string][]string{"eth": {"p1"}, "bsc": {"p2"}},
 		3,
 	)
 
@@ -128,7 +134,7 @@ func TestMultiProvider_AllFail(t *testing.T) {
 
 	mp := prepareTestMultiProvider(
 		map[string]Provider{"p1": p1, "p2": p2},
-		map[string]string{"eth": "p1", "bsc": "p2"},
+		map[string][]string{"eth": {"p1"}, "bsc": {"p2"}},
 		3,
 	)
 
@@ -147,7 +153,7 @@ func TestMultiProvider_DelayedButWithinTimeout(t *testing.T) {
 
 	mp := prepareTestMultiProvider(
 		map[string]Provider{"p1": p1},
-		map[string]string{"eth": "p1"},
+		map[string][]string{"eth": {"p1"}},
 		3,
 	)
 
@@ -159,3 +165,439 @@ func TestMultiProvider_DelayedButWithinTimeout(t *testing.T) {
 	assert.Len(t, resp.Result.Transactions, 1)
 	assert.Equal(t, "0xdelayed", resp.Result.Transactions[0].Hash)
 }
+
+func TestMultiProvider_FailoverOnError(t *testing.T) {
+	primary := &mockProvider{err: errors.New("outage")}
+	secondary := &mockProvider{transactions: []types.Transaction{{Hash: "0xbackup"}}}
+
+	mp := prepareTestMultiProvider(
+		map[string]Provider{"ankr": primary, "blockscout_eth": secondary},
+		map[string][]string{"eth": {"ankr", "blockscout_eth"}},
+		3,
+	)
+
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Result.Transactions, 1) {
+		assert.Equal(t, "0xbackup", resp.Result.Transactions[0].Hash)
+	}
+	assert.Equal(t, int32(1), primary.calls.Load())
+	assert.Equal(t, int32(1), secondary.calls.Load())
+}
+
+// panicProvider is a provider whose every call panics.
+type panicProvider struct{}
+
+func (panicProvider) GetTransactions(*types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	panic("boom")
+}
+
+func TestMultiProvider_FailoverOnPanic(t *testing.T) {
+	secondary := &mockProvider{transactions: []types.Transaction{{Hash: "0xbackup"}}}
+
+	mp := prepareTestMultiProvider(
+		map[string]Provider{"ankr": panicProvider{}, "blockscout_eth": secondary},
+		map[string][]string{"eth": {"ankr", "blockscout_eth"}},
+		3,
+	)
+
+	start := time.Now()
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Result.Transactions, 1) {
+		assert.Equal(t, "0xbackup", resp.Result.Transactions[0].Hash)
+	}
+	assert.Less(t, time.Since(start), time.Second, "a panic fails over at once, not on timeout")
+}
+
+// unhealthySet is a HealthSource reporting the listed keys as unhealthy.
+type unhealthySet map[string]bool
+
+func (u unhealthySet) Healthy(key string) bool { return !u[key] }
+
+func TestMultiProvider_SkipsUnhealthy(t *testing.T) {
+	primary := &mockProvider{transactions: []types.Transaction{{Hash: "0xprimary"}}}
+	secondary := &mockProvider{transactions: []types.Transaction{{Hash: "0xbackup"}}}
+	lonely := &mockProvider{transactions: []types.Transaction{{Hash: "0xlonely"}}}
+
+	mp := prepareTestMultiProvider(
+		map[string]Provider{"ankr": primary, "blockscout_eth": secondary, "blockscout_ttx": lonely},
+		map[string][]string{"eth": {"ankr", "blockscout_eth"}, "ttx": {"blockscout_ttx"}},
+		3,
+	)
+	mp.SetHealth(unhealthySet{"ankr": true, "blockscout_ttx": true})
+
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"ETH", "TTX"}})
+	assert.NoError(t, err)
+
+	var hashes []string
+	for _, tx := range resp.Result.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	// TTX has no healthy provider left, so its unhealthy one is still tried
+	assert.ElementsMatch(t, []string{"0xbackup", "0xlonely"}, hashes)
+	assert.Equal(t, int32(0), primary.calls.Load())
+}
+
+func TestMultiProvider_FailoverOnTimeout(t *testing.T) {
+	// slow is primary of both chains but only BSC has nothing else: ETH fails
+	// over to fast, and slow's late ETH rows must not duplicate fast's.
+	slow := &mockProvider{
+		transactions: []types.Transaction{{Hash: "0xslow-eth", ChainID: 1}, {Hash: "0xslow-bsc", ChainID: 56}},
+		delay:        1500 * time.Millisecond,
+	}
+	fast := &mockProvider{transactions: []types.Transaction{{Hash: "0xfast-eth", ChainID: 1}}}
+
+	mp := prepareTestMultiProvider(
+		map[string]Provider{"slow": slow, "fast": fast},
+		map[string][]string{"eth": {"slow", "fast"}, "bsc": {"slow"}},
+		3,
+	)
+	cfg := config.Current()
+	cfg.Providers.FailoverTimeout = 1
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	config.SetCurrentConfig(cfg)
+
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"eth", "bsc"}})
+	assert.NoError(t, err)
+
+	var hashes []string
+	for _, tx := range resp.Result.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.ElementsMatch(t, []string{"0xfast-eth", "0xslow-bsc"}, hashes)
+	assert.Equal(t, int32(1), slow.calls.Load())
+}
+
+func TestMultiProvider_WaitsForPrimaryAfterFallbackFails(t *testing.T) {
+	// slow times out, fallback fails: the chain is still served by slow
+	// once it answers, rather than failed while it is in flight.
+	slow := &mockProvider{transactions: []types.Transaction{{Hash: "0xslow", ChainID: 1}}, delay: 1500 * time.Millisecond}
+	fallback := &mockProvider{err: errors.New("boom")}
+
+	mp := prepareTestMultiProvider(
+		map[string]Provider{"slow": slow, "fallback": fallback},
+		map[string][]string{"eth": {"slow", "fallback"}},
+		3,
+	)
+	cfg := config.Current()
+	cfg.Providers.FailoverTimeout = 1
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	config.SetCurrentConfig(cfg)
+
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"eth"}})
+	if assert.NoError(t, err) && assert.Len(t, resp.Result.Transactions, 1) {
+		assert.Equal(t, "0xslow", resp.Result.Transactions[0].Hash)
+	}
+	assert.Equal(t, int32(1), fallback.calls.Load())
+}
+
+func TestMultiProvider_PreferenceOrdering(t *testing.T) {
+	registry := map[string]Provider{
+		"ankr":           &mockProvider{},
+		"blockscout_eth": &mockProvider{},
+		"alchemy_eth":    &mockProvider{},
+	}
+	mp := prepareTestMultiProvider(registry,
+		map[string][]string{"eth": {"ankr", "blockscout_eth", "alchemy_eth"}}, 3)
+	cfg := config.Current()
+
+	// No preferences: YAML order
+	assert.Equal(t, []string{"ankr", "blockscout_eth", "alchemy_eth"}, mp.providerQueues(mp.current(), []string{"eth"})["eth"])
+
+	// Priority wins over YAML order; equal priorities without weights keep it
+	cfg.Providers.Preferences = map[string]types.ProviderPreference{
+		"ankr": {Priority: 1},
+	}
+	config.SetCurrentConfig(cfg)
+	assert.Equal(t, []string{"blockscout_eth", "alchemy_eth", "ankr"}, mp.providerQueues(mp.current(), []string{"eth"})["eth"])
+
+	// Weighted draw among equal priorities
+	cfg.Providers.Preferences = map[string]types.ProviderPreference{
+		"ankr":           {Priority: 1},
+		"blockscout_eth": {Weight: 3},
+		"alchemy_eth":    {Weight: 1},
+	}
+	config.SetCurrentConfig(cfg)
+	draws := []float64{0.5, 0.9, 0.1} // ankr's draw is irrelevant, it is alone at priority 1
+	randFloat = func() float64 { d := draws[0]; draws = draws[1:]; return d }
+	defer func() { randFloat = rand.Float64 }()
+	queue := mp.providerQueues(mp.current(), []string{"eth"})["eth"]
+	// blockscout: 0.9^(1/3) ≈ 0.97 beats alchemy: 0.1
+	assert.Equal(t, []string{"blockscout_eth", "alchemy_eth", "ankr"}, queue)
+
+	// A provider at its in-flight limit spills over to the others
+	randFloat = func() float64 { return 0.5 }
+	cfg.Providers.Preferences = map[string]types.ProviderPreference{
+		"ankr": {MaxInFlight: 2},
+	}
+	config.SetCurrentConfig(cfg)
+	mp.current().inFlight["ankr"].Store(2)
+	assert.Equal(t, []string{"blockscout_eth", "alchemy_eth", "ankr"}, mp.providerQueues(mp.current(), []string{"eth"})["eth"])
+	mp.current().inFlight["ankr"].Store(1)
+	assert.Equal(t, []string{"ankr", "blockscout_eth", "alchemy_eth"}, mp.providerQueues(mp.current(), []string{"eth"})["eth"])
+}
+
+// fixedRanking is a RankingSource with a static ranking per chain.
+type fixedRanking map[string][]string
+
+func (f fixedRanking) Ranking(chain string) []string { return f[chain] }
+
+func TestMultiProvider_RankingOrdering(t *testing.T) {
+	registry := map[string]Provider{
+		"ankr":           &mockProvider{},
+		"blockscout_eth": &mockProvider{},
+		"alchemy_eth":    &mockProvider{},
+	}
+	mp := prepareTestMultiProvider(registry,
+		map[string][]string{"eth": {"ankr", "blockscout_eth", "alchemy_eth"}}, 3)
+	mp.SetRanking(fixedRanking{"eth": {"blockscout_eth", "ankr"}})
+	queue := func() []string { return mp.providerQueues(mp.current(), []string{"eth"})["eth"] }
+
+	assert.Equal(t, []string{"ankr", "blockscout_eth", "alchemy_eth"}, queue(), "benchmark.route unset")
+
+	cfg := config.Current()
+	cfg.Benchmark.Route = true
+	config.SetCurrentConfig(cfg)
+	assert.Equal(t, []string{"blockscout_eth", "ankr", "alchemy_eth"}, queue(), "unranked providers come last")
+
+	// Preferences still apply on top of the ranking
+	cfg.Providers.Preferences = map[string]types.ProviderPreference{"blockscout_eth": {Priority: 1}}
+	config.SetCurrentConfig(cfg)
+	assert.Equal(t, []string{"ankr", "alchemy_eth", "blockscout_eth"}, queue())
+}
+
+func TestMultiProvider_RebuildsOnConfigChange(t *testing.T) {
+	prepareTestMultiProvider(nil, map[string][]string{"eth": {"blockscout_eth"}}, 3)
+
+	builds := 0
+	mp := NewReloadingMultiProvider(func(cfg types.Config) map[string]Provider {
+		builds++
+		registry := map[string]Provider{}
+		for _, bs := range cfg.Blockscout {
+			registry[Key("blockscout", bs.ChainName)] = &mockProvider{transactions: []types.Transaction{{Hash: "0x" + bs.ChainName}}}
+		}
+		return registry
+	})
+	assert.Equal(t, 1, builds)
+	assert.Empty(t, mp.Providers())
+
+	_, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"ETH"}})
+	assert.Error(t, err, "blockscout_eth is not registered yet")
+
+	// A Blockscout instance is added to the configuration
+	cfg := config.Current()
+	cfg.Blockscout = []types.BlockscoutConfig{{ChainName: "ETH"}}
+	config.SetCurrentConfig(cfg)
+
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Result.Transactions, 1) {
+		assert.Equal(t, "0xETH", resp.Result.Transactions[0].Hash)
+	}
+	assert.Equal(t, 2, builds)
+	assert.Contains(t, mp.Providers(), "blockscout_eth")
+
+	// No rebuild while the configuration is unchanged
+	mp.Providers()
+	assert.Equal(t, 2, builds)
+}
+
+func TestMultiProvider_ReconcilesSharedTransactions(t *testing.T) {
+	// ankr serves BSC and also returns the ETH transaction that blockscout_eth
+	// served, with a different status and gas.
+	explorer := &mockProvider{transactions: []types.Transaction{
+		{Hash: "0xe1", ChainID: 1, State: types.TxStateSuccess, GasUsed: "21000"},
+	}}
+	indexer := &mockProvider{transactions: []types.Transaction{
+		{Hash: "0xE1", ChainID: 1, State: types.TxStateFail, GasUsed: "21001", Height: 100},
+		{Hash: "0xb1", ChainID: 56, State: types.TxStateSuccess},
+	}}
+
+	mp := prepareTestMultiProvider(
+		map[string]Provider{"blockscout_eth": explorer, "ankr": indexer},
+		map[string][]string{"eth": {"blockscout_eth", "ankr"}, "bsc": {"ankr"}},
+		3,
+	)
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	config.SetCurrentConfig(cfg)
+
+	find := func(resp *types.TransactionResponse, hash string) types.Transaction {
+		for _, tx := range resp.Result.Transactions {
+			if tx.Hash == hash {
+				return tx
+			}
+		}
+		t.Fatalf("%s not returned", hash)
+		return types.Transaction{}
+	}
+
+	// Explorer beats indexer; fields only the indexer knows are filled in.
+	resp, err := mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"eth", "bsc"}})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Result.Transactions, 2)
+	eth := find(resp, "0xe1")
+	assert.Equal(t, types.TxStateSuccess, eth.State)
+	assert.Equal(t, "21000", eth.GasUsed)
+	assert.Equal(t, int64(100), eth.Height)
+
+	// Reclassifying ankr as receipts makes its values win.
+	cfg.Providers.Reconcile.Sources = map[string]string{"ankr": SourceReceipts}
+	config.SetCurrentConfig(cfg)
+	resp, err = mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"eth", "bsc"}})
+	assert.NoError(t, err)
+	eth = find(resp, "0xe1")
+	assert.Equal(t, types.TxStateFail, eth.State)
+	assert.Equal(t, "21001", eth.GasUsed)
+
+	// A per-field precedence overrides the classes for that field only.
+	cfg.Providers.Reconcile.Precedence = map[string][]string{"state": {SourceExplorer, SourceReceipts}}
+	config.SetCurrentConfig(cfg)
+	resp, err = mp.GetTransactions(&types.TransactionQueryParams{ChainNames: []string{"eth", "bsc"}})
+	assert.NoError(t, err)
+	eth = find(resp, "0xe1")
+	assert.Equal(t, types.TxStateSuccess, eth.State)
+	assert.Equal(t, "21001", eth.GasUsed)
+}
+
+func TestMultiProvider_TracesInternalTransfers(t *testing.T) {
+	const (
+		user     = "0x00000000000000000000000000000000000000aa"
+		contract = "0x00000000000000000000000000000000000000cc"
+	)
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.RpcRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		methods = append(methods, req.Method)
+		var result string
+		switch req.Method {
+		case "debug_traceTransaction":
+			result = `{"type":"CALL","from":"` + user + `","to":"` + contract + `","calls":[
+				{"type":"CALL","from":"` + contract + `","to":"` + user + `","value":"0xde0b6b3a7640000"},
+				{"type":"DELEGATECALL","from":"` + contract + `","to":"` + user + `","value":"0x1"},
+				{"type":"CALL","from":"` + contract + `","to":"0x01","error":"execution reverted","calls":[
+					{"type":"CALL","from":"0x01","to":"` + user + `","value":"0x2"}]}]}`
+		case "trace_block":
+			result = `[
+				{"type":"call","action":{"callType":"call","from":"` + user + `","to":"` + contract + `","value":"0x0"},"traceAddress":[],"transactionHash":"0xa9","blockNumber":100},
+				{"type":"call","action":{"callType":"call","from":"` + contract + `","to":"` + user + `","value":"0x5"},"traceAddress":[0],"transactionHash":"0xa9","blockNumber":100},
+				{"type":"call","action":{"callType":"call","from":"` + contract + `","to":"0x01","value":"0x0"},"error":"Reverted","traceAddress":[1],"transactionHash":"0xa9","blockNumber":100},
+				{"type":"call","action":{"callType":"call","from":"0x01","to":"` + user + `","value":"0x6"},"traceAddress":[1,0],"transactionHash":"0xa9","blockNumber":100},
+				{"type":"reward","action":{"value":"0x7"},"traceAddress":[],"blockNumber":100}]`
+		}
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":`+result+`}`)
+	}))
+	defer srv.Close()
+
+	p := &mockProvider{transactions: []types.Transaction{
+		{Hash: "0xA1", ChainID: 1, Height: 100, CreatedTime: 1700000000, FromAddress: user, ToAddress: contract},
+	}}
+	mp := prepareTestMultiProvider(map[string]Provider{"ankr": p}, map[string][]string{"eth": {"ankr"}}, 3)
+	cfg := config.Current()
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.RPCURLs = map[string]string{"eth": srv.URL}
+	cfg.InternalTraces = []types.InternalTraceConfig{{ChainName: "ETH"}}
+	config.SetCurrentConfig(cfg)
+
+	params := &types.TransactionQueryParams{Address: user, ChainNames: []string{"eth"}}
+	resp, err := mp.GetTransactions(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"debug_traceTransaction"}, methods)
+	if assert.Len(t, resp.Result.Transactions, 2) {
+		internal := resp.Result.Transactions[1]
+		assert.Equal(t, "0xa1", internal.Hash)
+		assert.Equal(t, contract, internal.FromAddress)
+		assert.Equal(t, "1000000000000000000", internal.Balance)
+		assert.Equal(t, "1", internal.Amount)
+		assert.Equal(t, types.CoinTypeInternal, internal.CoinType)
+		assert.Equal(t, types.TransTypeIn, internal.TranType)
+		assert.Equal(t, int64(100), internal.Height)
+		assert.Equal(t, int64(1700000000), internal.CreatedTime)
+	}
+
+	// trace_block also finds the transfers of transactions the provider missed.
+	methods = nil
+	cfg.InternalTraces[0].Method = types.TraceMethodBlock
+	config.SetCurrentConfig(cfg)
+	resp, err = mp.GetTransactions(params)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trace_block"}, methods)
+	if assert.Len(t, resp.Result.Transactions, 2) {
+		internal := resp.Result.Transactions[1]
+		assert.Equal(t, "0xa9", internal.Hash)
+		assert.Equal(t, "5", internal.Balance)
+		assert.Equal(t, int64(1700000000), internal.CreatedTime)
+	}
+
+	// Chains whose provider reports internal transfers are not traced.
+	methods = nil
+	p.transactions = append(p.transactions, types.Transaction{Hash: "0xa1", ChainID: 1, Type: types.TxTypeInternal})
+	resp, err = mp.GetTransactions(params)
+	assert.NoError(t, err)
+	assert.Empty(t, methods)
+	assert.Len(t, resp.Result.Transactions, 2)
+}
+
+func TestFillSwaps(t *testing.T) {
+	const (
+		user   = "0x00000000000000000000000000000000000000aa"
+		router = "0x00000000000000000000000000000000000000bb"
+		pool   = "0x00000000000000000000000000000000000000cc"
+		usdc   = "0x00000000000000000000000000000000000000dd"
+	)
+	rows := []types.Transaction{
+		{ChainID: 1, Hash: "0xa1", FromAddress: user, ToAddress: router, Balance: "500000000000000000", Amount: "0.5",
+			CoinType: types.CoinTypeNative, Type: types.TxTypeSwap, Swap: &types.SwapInfo{Protocol: types.SwapProtocolUniswapV3}},
+		{ChainID: 1, Hash: "0xA1", FromAddress: pool, ToAddress: user, TokenAddress: usdc, Balance: "1500000000", Amount: "1500",
+			CoinType: types.CoinTypeToken},
+		{ChainID: 1, Hash: "0xa1", FromAddress: user, ToAddress: router, Balance: "0", CoinType: types.CoinTypeToken, Type: types.TxTypeApprove},
+		// Selling a token for the native coin: the proceeds are internal
+		{ChainID: 1, Hash: "0xa2", FromAddress: user, ToAddress: router, Balance: "0", Amount: "0",
+			CoinType: types.CoinTypeNative, Type: types.TxTypeSwap, Swap: &types.SwapInfo{Protocol: types.SwapProtocolUniswapV2}},
+		{ChainID: 1, Hash: "0xa2", FromAddress: user, ToAddress: pool, TokenAddress: usdc, Balance: "1000000", Amount: "1",
+			CoinType: types.CoinTypeToken},
+		{ChainID: 1, Hash: "0xa2", FromAddress: router, ToAddress: user, Balance: "300000000000000", Amount: "0.0003",
+			CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal},
+		// Same hash on another chain
+		{ChainID: 56, Hash: "0xa2", FromAddress: pool, ToAddress: user, TokenAddress: pool, Balance: "1", Amount: "1",
+			CoinType: types.CoinTypeToken},
+	}
+
+	fillSwaps(user, rows)
+	assert.Equal(t, &types.SwapInfo{Protocol: types.SwapProtocolUniswapV3,
+		TokenIn: types.NativeTokenName, AmountIn: "0.5", TokenOut: usdc, AmountOut: "1500"}, rows[0].Swap)
+	assert.Equal(t, &types.SwapInfo{Protocol: types.SwapProtocolUniswapV2,
+		TokenIn: usdc, AmountIn: "1", TokenOut: types.NativeTokenName, AmountOut: "0.0003"}, rows[3].Swap)
+	assert.Nil(t, rows[1].Swap)
+}
+
+func TestLabelBridges(t *testing.T) {
+	orig := config.Current()
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+	const (
+		user     = "0x00000000000000000000000000000000000000aa"
+		l1Bridge = "0x99c9fc46f92e8a1c0dec1b1747d010903e884be1"
+	)
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"eth": 1, "op": 10}
+	cfg.Bridges = []types.BridgeConfig{{Name: "optimism", ChainName: "ETH", CounterpartChain: "op",
+		Contracts: []string{"0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1"}}}
+	config.SetCurrentConfig(cfg)
+
+	rows := []types.Transaction{
+		{ChainID: 1, FromAddress: user, ToAddress: l1Bridge, Balance: "1", CoinType: types.CoinTypeNative},
+		{ChainID: 1, FromAddress: l1Bridge, ToAddress: user, Balance: "1", CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal},
+		{ChainID: 1, FromAddress: user, ToAddress: l1Bridge, CoinType: types.CoinTypeToken, Type: types.TxTypeApprove},
+		{ChainID: 10, FromAddress: user, ToAddress: l1Bridge, Balance: "1", CoinType: types.CoinTypeNative},
+	}
+	labelBridges(user, rows)
+
+	assert.Equal(t, types.TxTypeBridge, rows[0].Type)
+	assert.Equal(t, &types.BridgeInfo{Name: "optimism", FromChainID: 1, ToChainID: 10}, rows[0].Bridge)
+	assert.Equal(t, types.TxTypeBridge, rows[1].Type)
+	assert.Equal(t, &types.BridgeInfo{Name: "optimism", FromChainID: 10, ToChainID: 1}, rows[1].Bridge, "a payout arrives from the counterpart")
+	assert.Equal(t, types.TxTypeApprove, rows[2].Type, "approvals keep their type")
+	assert.Nil(t, rows[3].Bridge, "the contract is only a bridge on its own chain")
+}
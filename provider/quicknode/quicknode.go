@@ -55,7 +55,7 @@ func (q *QuickNodeProvider) GetTransactions(params *types.TransactionQueryParams
 
 	// 1️⃣  native transactions
 	g.Go(func() error {
-		resp, err := q.getTxByAddress(address, q.page, q.pageSize)
+		resp, err := q.getTxByAddress(params.RequestID, address, q.page, q.pageSize)
 		if err != nil {
 			return err
 		}
@@ -65,7 +65,7 @@ func (q *QuickNodeProvider) GetTransactions(params *types.TransactionQueryParams
 
 	// 2️⃣  token transfers (all contracts)
 	g.Go(func() error {
-		resp, err := q.getWalletTokenTransfers(address, "", q.page, q.pageSize)
+		resp, err := q.getWalletTokenTransfers(params.RequestID, address, "", q.page, q.pageSize)
 		if err != nil {
 			return err
 		}
@@ -88,10 +88,11 @@ func (q *QuickNodeProvider) GetTransactions(params *types.TransactionQueryParams
 
 // ---- helpers -------------------------------------------------------------
 
-func (q *QuickNodeProvider) sendRequest(req interface{}, out interface{}) error {
+func (q *QuickNodeProvider) sendRequest(req interface{}, out interface{}, opts ...utils.RequestOption) error {
 	return utils.DoHttpRequestWithLogging(
 		"POST", "quicknode", q.url, req,
 		map[string]string{"Content-Type": "application/json"},
 		out,
+		opts...,
 	)
 }
@@ -8,7 +8,7 @@ import (
 
 // ---------------------------- fetch & transform ---------------------------
 
-func (q *QuickNodeProvider) getTxByAddress(addr string, page, perPage int) (*types.QuickNodeTxResponse, error) {
+func (q *QuickNodeProvider) getTxByAddress(requestID, addr string, page, perPage int) (*types.QuickNodeTxResponse, error) {
 	req := types.QuickNodeTxRequest{
 		JSONRPC: "2.0",
 		Method:  "qn_getTransactionsByAddress",
@@ -23,7 +23,7 @@ func (q *QuickNodeProvider) getTxByAddress(addr string, page, perPage int) (*typ
 	}
 
 	var resp types.QuickNodeTxResponse
-	if err := q.sendRequest(req, &resp); err != nil {
+	if err := q.sendRequest(req, &resp, utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -34,6 +34,7 @@ func (q *QuickNodeProvider) transformQuickNodeNative(resp *types.QuickNodeTxResp
 		return nil
 	}
 
+	nativeDecimals := utils.NativeDecimalsByChainID(q.chainID, types.NativeDefaultDecimals)
 	var out []types.Transaction
 	for _, tx := range resp.Result.Transactions {
 		height := utils.ParseStringToInt64OrDefault(tx.BlockNumber, 0)
@@ -41,7 +42,7 @@ func (q *QuickNodeProvider) transformQuickNodeNative(resp *types.QuickNodeTxResp
 		index := utils.ParseStringToInt64OrDefault(tx.TransactionIndex, 0)
 
 		rawValue, _ := utils.NormalizeNumericString(tx.Value)
-		amount := utils.DivideByDecimals(rawValue, types.NativeDefaultDecimals)
+		amount := utils.DivideByDecimals(rawValue, int(nativeDecimals))
 
 		tranType := types.TransTypeOut
 		if strings.EqualFold(tx.ToAddress, addr) {
@@ -73,7 +74,7 @@ func (q *QuickNodeProvider) transformQuickNodeNative(resp *types.QuickNodeTxResp
 			Type:             types.TxTypeTransfer,
 			CoinType:         types.CoinTypeNative,
 			TokenDisplayName: "",
-			Decimals:         types.NativeDefaultDecimals,
+			Decimals:         nativeDecimals,
 			CreatedTime:      timestamp,
 			ModifiedTime:     timestamp,
 			TranType:         tranType,
@@ -9,7 +9,7 @@ import (
 
 // -------------------------- fetch & transform ----------------------------
 
-func (q *QuickNodeProvider) getWalletTokenTransfers(addr, contract string, page, perPage int) (*types.QuickNodeTokenResp, error) {
+func (q *QuickNodeProvider) getWalletTokenTransfers(requestID, addr, contract string, page, perPage int) (*types.QuickNodeTokenResp, error) {
 	param := map[string]interface{}{
 		"address": addr,
 		"page":    page,
@@ -27,7 +27,7 @@ func (q *QuickNodeProvider) getWalletTokenTransfers(addr, contract string, page,
 	}
 
 	var resp types.QuickNodeTokenResp
-	if err := q.sendRequest(req, &resp); err != nil {
+	if err := q.sendRequest(req, &resp, utils.WithRequestID(requestID)); err != nil {
 		return nil, err
 	}
 	return &resp, nil
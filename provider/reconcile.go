@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+)
+
+// Source classes of providers, from most to least trusted by default.
+const (
+	SourceReceipts = "receipts"
+	SourceExplorer = "explorer"
+	SourceIndexer  = "indexer"
+)
+
+var defaultPrecedence = []string{SourceReceipts, SourceExplorer, SourceIndexer}
+
+// indexerKinds are the provider kinds classed as indexers by default.
+var indexerKinds = map[string]bool{
+	"ankr":      true,
+	"alchemy":   true,
+	"quicknode": true,
+	"thegraph":  true,
+	"aptos":     true,
+}
+
+// txField is one transaction-level field settled by reconcile. get returns ""
+// when the row does not know the value.
+type txField struct {
+	name string
+	get  func(tx *types.Transaction) string
+	set  func(tx *types.Transaction, v string)
+}
+
+var txFields = []txField{
+	{
+		name: "state",
+		get:  func(tx *types.Transaction) string { return strconv.Itoa(tx.State) },
+		set:  func(tx *types.Transaction, v string) { tx.State, _ = strconv.Atoi(v) },
+	},
+	{
+		name: "height",
+		get:  func(tx *types.Transaction) string { return positive(tx.Height) },
+		set:  func(tx *types.Transaction, v string) { tx.Height, _ = strconv.ParseInt(v, 10, 64) },
+	},
+	{
+		name: "block_hash",
+		get:  func(tx *types.Transaction) string { return strings.ToLower(tx.BlockHash) },
+		set:  func(tx *types.Transaction, v string) { tx.BlockHash = v },
+	},
+	{
+		name: "tx_index",
+		get:  func(tx *types.Transaction) string { return positive(tx.TxIndex) },
+		set:  func(tx *types.Transaction, v string) { tx.TxIndex, _ = strconv.ParseInt(v, 10, 64) },
+	},
+	{
+		name: "gas_used",
+		get:  func(tx *types.Transaction) string { return tx.GasUsed },
+		set:  func(tx *types.Transaction, v string) { tx.GasUsed = v },
+	},
+	{
+		name: "gas_limit",
+		get:  func(tx *types.Transaction) string { return tx.GasLimit },
+		set:  func(tx *types.Transaction, v string) { tx.GasLimit = v },
+	},
+	{
+		name: "gas_price",
+		get:  func(tx *types.Transaction) string { return tx.GasPrice },
+		set:  func(tx *types.Transaction, v string) { tx.GasPrice = v },
+	},
+	{
+		name: "nonce",
+		get:  func(tx *types.Transaction) string { return tx.Nonce },
+		set:  func(tx *types.Transaction, v string) { tx.Nonce = v },
+	},
+}
+
+// positive formats n, or returns "" when it is not set.
+func positive(n int64) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// reconcile settles the transaction-level fields of the merged rows when
+// several providers answered for the same transaction, typically a provider
+// shared by several chains that also returned rows for a chain another
+// provider served. For every field the value of the most trusted provider
+// that knows it is written to all rows of the transaction, following
+// providers.reconcile; disagreements are logged and counted. Internal rows
+// are reconciled separately from the transaction they belong to, as their
+// state and gas describe the internal call.
+func reconcile(rows []types.Transaction, results map[string]attempt) {
+	var answered []string
+	for key, res := range results {
+		if res.err == nil {
+			answered = append(answered, key)
+		}
+	}
+	if len(rows) == 0 || len(answered) < 2 {
+		return
+	}
+	sort.Strings(answered)
+
+	wanted := make(map[string]struct{}, len(rows))
+	for i := range rows {
+		wanted[reconcileKey(&rows[i])] = struct{}{}
+	}
+
+	// txKey -> providerKey -> first known value of every field
+	seen := make(map[string]map[string][]string)
+	for _, key := range answered {
+		for i := range results[key].txs {
+			tx := &results[key].txs[i]
+			k := reconcileKey(tx)
+			if _, ok := wanted[k]; !ok {
+				continue
+			}
+			if seen[k] == nil {
+				seen[k] = make(map[string][]string)
+			}
+			vals := seen[k][key]
+			if vals == nil {
+				vals = make([]string, len(txFields))
+				seen[k][key] = vals
+			}
+			for j, f := range txFields {
+				if vals[j] == "" {
+					vals[j] = f.get(tx)
+				}
+			}
+		}
+	}
+
+	cfg := config.Current().Providers.Reconcile
+	chosen := make(map[string][]string)
+	for k, byProvider := range seen {
+		if len(byProvider) < 2 {
+			continue
+		}
+		vals := make([]string, len(txFields))
+		for j, f := range txFields {
+			vals[j] = pickValue(k, f.name, j, byProvider, cfg)
+		}
+		chosen[k] = vals
+	}
+
+	for i := range rows {
+		vals, ok := chosen[reconcileKey(&rows[i])]
+		if !ok {
+			continue
+		}
+		for j, f := range txFields {
+			if vals[j] != "" {
+				f.set(&rows[i], vals[j])
+			}
+		}
+	}
+}
+
+// pickValue returns the value of field j given by the most trusted provider
+// that knows it, logging the providers that disagree with it.
+func pickValue(txKey, name string, j int, byProvider map[string][]string, cfg types.ReconcileConfig) string {
+	precedence := defaultPrecedence
+	if p, ok := cfg.Precedence[name]; ok && len(p) > 0 {
+		precedence = p
+	}
+	rank := func(key string) int {
+		class := sourceClass(key, cfg)
+		for i, c := range precedence {
+			if strings.EqualFold(c, class) {
+				return i
+			}
+		}
+		return len(precedence)
+	}
+
+	keys := make([]string, 0, len(byProvider))
+	for key := range byProvider {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(a, b int) bool {
+		if ra, rb := rank(keys[a]), rank(keys[b]); ra != rb {
+			return ra < rb
+		}
+		return keys[a] < keys[b]
+	})
+
+	var kept, keptBy string
+	conflict := false
+	for _, key := range keys {
+		v := byProvider[key][j]
+		switch {
+		case v == "":
+		case kept == "":
+			kept, keptBy = v, key
+		case v != kept:
+			conflict = true
+		}
+	}
+	if conflict {
+		values := make(map[string]string, len(keys))
+		for _, key := range keys {
+			if v := byProvider[key][j]; v != "" {
+				values[key] = v
+			}
+		}
+		metrics.ProviderDiscrepancies.WithLabelValues(name).Inc()
+		logger.Log.Warn().
+			Str("tx", txKey).
+			Str("field", name).
+			Str("kept", keptBy).
+			Interface("values", values).
+			Msg("Providers disagree on transaction field")
+	}
+	return kept
+}
+
+// sourceClass returns the source class of a provider key: the configured
+// one, else receipts for rpcscan, indexer for indexer kinds and explorer for
+// the rest.
+func sourceClass(key string, cfg types.ReconcileConfig) string {
+	if class, ok := cfg.Sources[strings.ToLower(key)]; ok {
+		return strings.ToLower(class)
+	}
+	kind, _, _ := strings.Cut(key, "_")
+	switch {
+	case kind == "rpcscan":
+		return SourceReceipts
+	case indexerKinds[kind]:
+		return SourceIndexer
+	}
+	return SourceExplorer
+}
+
+// reconcileKey identifies the transaction a row belongs to. Internal rows
+// get their own key.
+func reconcileKey(tx *types.Transaction) string {
+	kind := "tx"
+	if tx.Type == types.TxTypeInternal || tx.CoinType == types.CoinTypeInternal {
+		kind = "internal"
+	}
+	return strconv.FormatInt(tx.ChainID, 10) + "|" + strings.ToLower(tx.Hash) + "|" + kind
+}
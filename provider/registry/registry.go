@@ -0,0 +1,207 @@
+// Package registry builds the provider registry, keyed by provider key,
+// from a configuration snapshot. MultiProvider calls Build again whenever the
+// configuration is hot-reloaded, so providers added in Consul are picked up
+// without a restart.
+package registry
+
+import (
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/provider/alchemy"
+	"tx-aggregator/provider/ankr"
+	"tx-aggregator/provider/aptos"
+	"tx-aggregator/provider/blockscan"
+	"tx-aggregator/provider/blockscout"
+	"tx-aggregator/provider/esplora"
+	"tx-aggregator/provider/oklink"
+	"tx-aggregator/provider/rest"
+	"tx-aggregator/provider/routescan"
+	"tx-aggregator/provider/rpcscan"
+	"tx-aggregator/provider/starknet"
+	"tx-aggregator/provider/thegraph"
+	"tx-aggregator/provider/ton"
+	"tx-aggregator/provider/tron"
+	"tx-aggregator/provider/zksync"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Build constructs every provider configured in cfg. Entries whose chain
+// name is unknown are skipped with a warning.
+func Build(cfg types.Config) map[string]provider.Provider {
+	registry := make(map[string]provider.Provider)
+	registry["ankr"] = ankr.NewAnkrProvider(cfg.Ankr.APIKey, cfg.Ankr.URL)
+	logger.Log.Info().Msg("Ankr provider registered")
+
+	// Register blockscout providers
+	for _, bs := range cfg.Blockscout {
+		chainID, err := utils.ChainIDByName(bs.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", bs.ChainName).Msg("Invalid chain name, skipping Blockscout")
+			continue
+		}
+		key := provider.Key("blockscout", bs.ChainName)
+		registry[key] = blockscout.NewBlockscoutProvider(chainID, bs)
+		logger.Log.Info().Str("provider", key).Str("url", bs.URL).Msg("Blockscout provider registered")
+	}
+
+	// Register blockscan providers
+	for _, bs := range cfg.Blockscan {
+		chainID, err := utils.ChainIDByName(bs.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", bs.ChainName).Msg("Invalid chain name, skipping Blockscan")
+			continue
+		}
+		key := provider.Key("blockscan", bs.ChainName)
+		registry[key] = blockscan.NewBlockscanProvider(chainID, bs)
+		logger.Log.Info().Str("provider", key).Str("url", bs.URL).Msg("Blockscan provider registered")
+	}
+
+	// Register alchemy providers
+	for _, al := range cfg.Alchemy {
+		chainID, err := utils.ChainIDByName(al.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", al.ChainName).Msg("Invalid chain name, skipping Alchemy")
+			continue
+		}
+		key := provider.Key("alchemy", al.ChainName)
+		registry[key] = alchemy.NewAlchemyProvider(chainID, al)
+		logger.Log.Info().Str("provider", key).Msg("Alchemy provider registered")
+	}
+
+	// Register routescan providers
+	rs := cfg.Routescan
+	for _, chain := range rs.Chains {
+		chainID, err := utils.ChainIDByName(chain.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", chain.ChainName).Msg("Invalid chain name, skipping Routescan")
+			continue
+		}
+		key := provider.Key("routescan", chain.ChainName)
+		registry[key] = routescan.NewRoutescanProvider(chainID, chain, rs)
+		logger.Log.Info().Str("provider", key).Msg("Routescan provider registered")
+	}
+
+	// Register oklink providers
+	ok := cfg.OKLink
+	for _, chain := range ok.Chains {
+		chainID, err := utils.ChainIDByName(chain.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", chain.ChainName).Msg("Invalid chain name, skipping OKLink")
+			continue
+		}
+		key := provider.Key("oklink", chain.ChainName)
+		registry[key] = oklink.NewOKLinkProvider(chainID, chain, ok)
+		logger.Log.Info().Str("provider", key).Msg("OKLink provider registered")
+	}
+
+	// Register esplora (Bitcoin-style UTXO) providers
+	for _, es := range cfg.Esplora {
+		chainID, err := utils.ChainIDByName(es.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", es.ChainName).Msg("Invalid chain name, skipping Esplora")
+			continue
+		}
+		key := provider.Key("esplora", es.ChainName)
+		registry[key] = esplora.NewEsploraProvider(chainID, es)
+		logger.Log.Info().Str("provider", key).Msg("Esplora provider registered")
+	}
+
+	// Register tron (TronGrid) providers
+	for _, tr := range cfg.Tron {
+		chainID, err := utils.ChainIDByName(tr.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", tr.ChainName).Msg("Invalid chain name, skipping Tron")
+			continue
+		}
+		key := provider.Key("tron", tr.ChainName)
+		registry[key] = tron.NewTronProvider(chainID, tr)
+		logger.Log.Info().Str("provider", key).Msg("Tron provider registered")
+	}
+
+	// Register ton (toncenter) providers
+	for _, tc := range cfg.Ton {
+		chainID, err := utils.ChainIDByName(tc.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", tc.ChainName).Msg("Invalid chain name, skipping TON")
+			continue
+		}
+		key := provider.Key("ton", tc.ChainName)
+		registry[key] = ton.NewTonProvider(chainID, tc)
+		logger.Log.Info().Str("provider", key).Msg("TON provider registered")
+	}
+
+	// Register rpcscan (raw JSON-RPC node) providers
+	for _, rc := range cfg.RPCScan {
+		chainID, err := utils.ChainIDByName(rc.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", rc.ChainName).Msg("Invalid chain name, skipping RPC scan")
+			continue
+		}
+		key := provider.Key("rpcscan", rc.ChainName)
+		registry[key] = rpcscan.NewRPCScanProvider(chainID, rc)
+		logger.Log.Info().Str("provider", key).Str("url", rc.URL).Msg("RPC scan provider registered")
+	}
+
+	// Register subgraph providers
+	for _, gc := range cfg.TheGraph {
+		chainID, err := utils.ChainIDByName(gc.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", gc.ChainName).Msg("Invalid chain name, skipping TheGraph")
+			continue
+		}
+		key := provider.Key("thegraph", gc.ChainName)
+		registry[key] = thegraph.NewTheGraphProvider(chainID, gc)
+		logger.Log.Info().Str("provider", key).Str("url", gc.URL).Msg("TheGraph provider registered")
+	}
+
+	// Register zkSync Era explorer API providers
+	for _, zc := range cfg.ZkSync {
+		chainID, err := utils.ChainIDByName(zc.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", zc.ChainName).Msg("Invalid chain name, skipping zkSync")
+			continue
+		}
+		key := provider.Key("zksync", zc.ChainName)
+		registry[key] = zksync.NewZkSyncProvider(chainID, zc)
+		logger.Log.Info().Str("provider", key).Str("url", zc.URL).Msg("zkSync provider registered")
+	}
+
+	// Register Starknet Voyager providers
+	for _, sc := range cfg.Starknet {
+		chainID, err := utils.ChainIDByName(sc.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", sc.ChainName).Msg("Invalid chain name, skipping Starknet")
+			continue
+		}
+		key := provider.Key("starknet", sc.ChainName)
+		registry[key] = starknet.NewStarknetProvider(chainID, sc)
+		logger.Log.Info().Str("provider", key).Str("url", sc.URL).Msg("Starknet provider registered")
+	}
+
+	// Register Aptos indexer providers
+	for _, ac := range cfg.Aptos {
+		chainID, err := utils.ChainIDByName(ac.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", ac.ChainName).Msg("Invalid chain name, skipping Aptos")
+			continue
+		}
+		key := provider.Key("aptos", ac.ChainName)
+		registry[key] = aptos.NewAptosProvider(chainID, ac)
+		logger.Log.Info().Str("provider", key).Str("url", ac.URL).Msg("Aptos provider registered")
+	}
+
+	// Register config-driven REST providers
+	for _, rc := range cfg.REST {
+		chainID, err := utils.ChainIDByName(rc.ChainName)
+		if err != nil {
+			logger.Log.Warn().Str("chain", rc.ChainName).Msg("Invalid chain name, skipping REST")
+			continue
+		}
+		key := provider.Key("rest", rc.ChainName)
+		registry[key] = rest.NewRESTProvider(chainID, rc)
+		logger.Log.Info().Str("provider", key).Str("url", rc.URL).Msg("REST provider registered")
+	}
+
+	return registry
+}
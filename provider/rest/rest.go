@@ -0,0 +1,213 @@
+// Package rest serves simple Etherscan-like explorers from config alone: the
+// request paths, the location of the item list and the mapping of item
+// fields onto transactions are declared per endpoint, so onboarding such an
+// explorer needs no new Go package.
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*RESTProvider)(nil)
+
+const (
+	// defaultPageSize is used when request_page_size is unset.
+	defaultPageSize = 100
+	// defaultMaxPages caps pagination when max_pages is unset.
+	defaultMaxPages = 10
+	// defaultFirstPage is the first {page} of Etherscan-style APIs.
+	defaultFirstPage = 1
+)
+
+// Endpoint kinds.
+const (
+	kindNative   = "native"
+	kindInternal = "internal"
+	kindToken    = "token"
+)
+
+// RESTProvider serves one chain from one config-described explorer.
+type RESTProvider struct {
+	chainID int64
+	cfg     types.RESTConfig
+}
+
+// NewRESTProvider constructs a provider for one chain / one base URL.
+func NewRESTProvider(chainID int64, cfg types.RESTConfig) *RESTProvider {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.RequestPageSize <= 0 {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	for i := range cfg.Endpoints {
+		cfg.Endpoints[i].Kind = strings.ToLower(cfg.Endpoints[i].Kind)
+		if cfg.Endpoints[i].Kind == "" {
+			cfg.Endpoints[i].Kind = kindNative
+		}
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Int("endpoints", len(cfg.Endpoints)).
+		Msg("Initializing RESTProvider")
+
+	return &RESTProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions reads every configured endpoint concurrently, maps the
+// items and patches token rows with the gas data of their parent
+// transactions.
+func (p *RESTProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := strings.ToLower(params.Address)
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from REST explorer")
+
+	rows := make([][]types.Transaction, len(p.cfg.Endpoints))
+	var g errgroup.Group
+	for i, ep := range p.cfg.Endpoints {
+		g.Go(func() error {
+			items, err := p.fetchEndpoint(params.RequestID, ep, address, maxPages)
+			if err != nil {
+				return err
+			}
+			rows[i] = p.transformItems(ep, items, address)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Str("chain", p.cfg.ChainName).Msg("REST explorer fetch failed")
+		return nil, err
+	}
+
+	var normalTxs, otherTxs, tokenTxs []types.Transaction
+	for i, ep := range p.cfg.Endpoints {
+		switch ep.Kind {
+		case kindToken:
+			tokenTxs = append(tokenTxs, rows[i]...)
+		case kindNative:
+			normalTxs = append(normalTxs, rows[i]...)
+		default:
+			otherTxs = append(otherTxs, rows[i]...)
+		}
+	}
+	tokenTxs = utils.PatchTokenTransactionsWithNormalTxInfo(tokenTxs, normalTxs)
+
+	all := append(append(normalTxs, otherTxs...), tokenTxs...)
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("normal", len(normalTxs)).
+		Int("token", len(tokenTxs)).
+		Int("total", len(all)).
+		Msg("REST provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchEndpoint reads consecutive pages of one endpoint until a short page or
+// maxPages is reached. A failure on a later page keeps the items collected
+// so far.
+func (p *RESTProvider) fetchEndpoint(requestID string, ep types.RESTEndpointConfig, address string, maxPages int64) ([]map[string]interface{}, error) {
+	firstPage := ep.FirstPage
+	if firstPage <= 0 {
+		firstPage = defaultFirstPage
+	}
+
+	var all []map[string]interface{}
+	for page := int64(0); page < maxPages; page++ {
+		items, err := p.fetchPage(requestID, ep, address, firstPage+page, page*p.cfg.RequestPageSize)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", ep.Name).
+				Int64("page", page+1).
+				Msg("REST pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if int64(len(items)) < p.cfg.RequestPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// fetchPage requests one page and returns the objects of the list found at
+// the endpoint's items path. Numbers are kept as json.Number so that uint256
+// amounts survive decoding.
+func (p *RESTProvider) fetchPage(requestID string, ep types.RESTEndpointConfig, address string, page, offset int64) ([]map[string]interface{}, error) {
+	u := p.cfg.URL + p.expand(ep.Path, address, page, offset, url.QueryEscape)
+
+	var headers map[string]string
+	if len(p.cfg.Headers) > 0 {
+		headers = make(map[string]string, len(p.cfg.Headers))
+		for k, v := range p.cfg.Headers {
+			headers[k] = p.expand(v, address, page, offset, nil)
+		}
+	}
+
+	var raw json.RawMessage
+	if err := utils.DoHttpRequestWithLogging("GET", "rest."+ep.Name, u, nil, headers, &raw,
+		utils.WithProviderKey(provider.Key("rest", p.cfg.ChainName)), utils.WithRequestID(requestID)); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var body interface{}
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode %s response: %w", ep.Name, err)
+	}
+
+	list, ok := lookup(body, ep.Items).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s response has no list at %q", ep.Name, ep.Items)
+	}
+	items := make([]map[string]interface{}, 0, len(list))
+	for _, it := range list {
+		if m, ok := it.(map[string]interface{}); ok {
+			items = append(items, m)
+		}
+	}
+	return items, nil
+}
+
+// expand substitutes the placeholders of a path or header template. escape,
+// when set, is applied to every substituted value.
+func (p *RESTProvider) expand(tmpl, address string, page, offset int64, escape func(string) string) string {
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+	return strings.NewReplacer(
+		"{address}", escape(address),
+		"{page}", strconv.FormatInt(page, 10),
+		"{offset}", strconv.FormatInt(offset, 10),
+		"{page_size}", strconv.FormatInt(p.cfg.RequestPageSize, 10),
+		"{api_key}", escape(p.cfg.APIKey),
+	).Replace(tmpl)
+}
@@ -0,0 +1,167 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// transformItems maps the items of one endpoint onto transactions through
+// the configured field paths. Items without a hash or with a non-numeric
+// value are skipped. The endpoint kind decides the row type; a token address
+// on a native endpoint also turns the row into a token transfer.
+func (p *RESTProvider) transformItems(ep types.RESTEndpointConfig, items []map[string]interface{}, address string) []types.Transaction {
+	f := ep.Fields
+
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+
+	txs := make([]types.Transaction, 0, len(items))
+	for _, it := range items {
+		hash := field(it, f.Hash)
+		if hash == "" {
+			softjson.SkipItem(it, errors.New("missing hash"))
+			continue
+		}
+		balance, err := utils.NormalizeNumericString(field(it, f.Value))
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+
+		from := strings.ToLower(field(it, f.From))
+		to := strings.ToLower(field(it, f.To))
+		tranType := types.TransTypeIn
+		if from == address {
+			tranType = types.TransTypeOut
+		}
+		ts := timeField(it, f.Timestamp)
+		gasUsed, _ := utils.NormalizeNumericString(field(it, f.GasUsed))
+		gasPrice, _ := utils.NormalizeNumericString(field(it, f.GasPrice))
+		gasLimit, _ := utils.NormalizeNumericString(field(it, f.GasLimit))
+		nonce, _ := utils.NormalizeNumericString(field(it, f.Nonce))
+
+		tx := types.Transaction{
+			ChainID:          p.chainID,
+			State:            stateOf(ep, it),
+			Height:           intField(it, f.Height),
+			BlockHash:        field(it, f.BlockHash),
+			Hash:             hash,
+			TxIndex:          intField(it, f.TxIndex),
+			FromAddress:      from,
+			ToAddress:        to,
+			Balance:          balance,
+			GasUsed:          gasUsed,
+			GasLimit:         gasLimit,
+			GasPrice:         gasPrice,
+			Nonce:            nonce,
+			Type:             types.TxTypeUnknown, // native transfer
+			CoinType:         types.CoinTypeNative,
+			TokenDisplayName: nativeSymbol,
+			Decimals:         nativeDecimals,
+			CreatedTime:      ts,
+			ModifiedTime:     ts,
+			TranType:         tranType,
+		}
+		if ep.Kind == kindInternal {
+			tx.Type = types.TxTypeInternal
+			tx.CoinType = types.CoinTypeInternal
+		}
+		if token := strings.ToLower(field(it, f.TokenAddress)); token != "" || ep.Kind == kindToken {
+			tx.Type = types.TxTypeTransfer
+			tx.CoinType = types.CoinTypeToken
+			tx.TokenAddress = token
+			tx.TokenDisplayName = field(it, f.TokenSymbol)
+			if d, err := strconv.ParseInt(field(it, f.TokenDecimals), 10, 64); err == nil {
+				tx.Decimals = d
+			}
+		}
+		tx.Amount = utils.DivideByDecimals(balance, int(tx.Decimals))
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// stateOf resolves the state of an item through the endpoint's states table.
+// Items are successful when no state field is configured or its value is
+// not listed.
+func stateOf(ep types.RESTEndpointConfig, item map[string]interface{}) int {
+	if ep.Fields.State == "" {
+		return types.TxStateSuccess
+	}
+	if strings.EqualFold(ep.States[strings.ToLower(field(item, ep.Fields.State))], "failed") {
+		return types.TxStateFail
+	}
+	return types.TxStateSuccess
+}
+
+// field returns the value at a dotted path of an item as a string, or ""
+// when the path is unset or missing.
+func field(item map[string]interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+	switch v := lookup(item, path).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// intField returns the integer at a dotted path of an item, or 0. Decimal
+// and 0x-prefixed hex values are accepted.
+func intField(item map[string]interface{}, path string) int64 {
+	s := field(item, path)
+	if s == "" {
+		return 0
+	}
+	return utils.ParseStringToInt64OrDefault(s, 0)
+}
+
+// timeField returns the Unix timestamp at a dotted path of an item. Values
+// are either Unix seconds (decimal or hex) or RFC 3339 strings.
+func timeField(item map[string]interface{}, path string) int64 {
+	s := field(item, path)
+	if s == "" {
+		return 0
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix()
+	}
+	return utils.ParseStringToInt64OrDefault(s, 0)
+}
+
+// lookup walks a dotted path through nested JSON objects. An empty path
+// returns v itself.
+func lookup(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+// Package routescan serves chains through Routescan's Etherscan-compatible
+// multichain API (Avalanche and many L2s). Routescan speaks the same
+// module/action protocol as Etherscan, so requests are handled by the
+// Blockscan provider; only the base URL differs, because Routescan routes
+// by network and chain ID in the path:
+//
+//	https://api.routescan.io/v2/network/<mainnet|testnet>/evm/<chainId>/etherscan/api
+package routescan
+
+import (
+	"fmt"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/provider/blockscan"
+	"tx-aggregator/types"
+)
+
+const (
+	defaultURL     = "https://api.routescan.io"
+	defaultNetwork = "mainnet"
+	// latestBlock stands in for an open-ended endblock.
+	latestBlock = 9999999999
+)
+
+// NewRoutescanProvider returns an Etherscan-compatible provider for one chain
+// served by Routescan.
+func NewRoutescanProvider(chainID int64, chain types.RoutescanChainConfig, cfg types.RoutescanConfig) *blockscan.BlockscanProvider {
+	url := BaseURL(cfg, chain, chainID)
+	logger.Log.Info().
+		Str("chain", chain.ChainName).
+		Str("url", url).
+		Msg("Initializing Routescan provider")
+
+	p := blockscan.NewBlockscanProvider(chainID, types.BlockscanConfig{
+		URL:             url,
+		APIKey:          cfg.APIKey,
+		ChainName:       chain.ChainName,
+		RequestPageSize: cfg.RequestPageSize,
+		Sort:            cfg.Sort,
+		Page:            1,
+		Startblock:      0,
+		Endblock:        latestBlock,
+		MaxPages:        cfg.MaxPages,
+	})
+	p.SetProviderKey(provider.Key("routescan", chain.ChainName))
+	return p
+}
+
+// BaseURL builds the Etherscan-compatible endpoint of one chain.
+func BaseURL(cfg types.RoutescanConfig, chain types.RoutescanChainConfig, chainID int64) string {
+	base := cfg.URL
+	if base == "" {
+		base = defaultURL
+	}
+	network := chain.Network
+	if network == "" {
+		network = cfg.Network
+	}
+	if network == "" {
+		network = defaultNetwork
+	}
+	return fmt.Sprintf("%s/v2/network/%s/evm/%d/etherscan/api",
+		strings.TrimRight(base, "/"), strings.ToLower(network), chainID)
+}
@@ -0,0 +1,219 @@
+// Package rpcscan serves EVM chains that have no explorer API by scanning the
+// most recent blocks of a plain JSON-RPC node: native transactions come from
+// eth_getBlockByNumber, ERC-20 and ERC-1155 transfers from eth_getLogs,
+// status and gas from eth_getTransactionReceipt and token metadata from
+// eth_call. Only the configured lookback window is visible.
+package rpcscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*RPCScanProvider)(nil)
+
+const (
+	defaultLookbackBlocks = 1000
+	defaultBatchSize      = 50
+	defaultLogRange       = 1000
+)
+
+// tokenInfo is the cached metadata of an ERC-20 contract.
+type tokenInfo struct {
+	symbol   string
+	decimals int64
+	fallback bool // decimals assumed after a failed lookup
+}
+
+// RPCScanProvider serves one chain from a JSON-RPC node.
+type RPCScanProvider struct {
+	chainID int64
+	cfg     types.RPCScanConfig
+
+	mu     sync.Mutex
+	tokens map[string]tokenInfo // lowercase contract address → metadata
+}
+
+// NewRPCScanProvider constructs a provider for one chain.
+func NewRPCScanProvider(chainID int64, cfg types.RPCScanConfig) *RPCScanProvider {
+	if cfg.LookbackBlocks <= 0 {
+		cfg.LookbackBlocks = defaultLookbackBlocks
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.LogRange <= 0 {
+		cfg.LogRange = defaultLogRange
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Int64("lookback_blocks", cfg.LookbackBlocks).
+		Msg("Initializing RPCScanProvider")
+
+	return &RPCScanProvider{
+		chainID: chainID,
+		cfg:     cfg,
+		tokens:  make(map[string]tokenInfo),
+	}
+}
+
+// GetTransactions scans the lookback window for native transactions sent or
+// received by the address and ERC-20 and ERC-1155 transfers involving it,
+// then attaches receipt data (status, gas) to all of them.
+func (p *RPCScanProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := strings.ToLower(params.Address)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from JSON-RPC node")
+
+	requestID := utils.WithRequestID(params.RequestID)
+	var head string
+	if err := p.call("rpcscan.blockNumber", "eth_blockNumber", nil, &head, requestID); err != nil {
+		return nil, err
+	}
+	latest := utils.ParseStringToInt64OrDefault(head, 0)
+	from := latest - p.cfg.LookbackBlocks + 1
+	if from < 0 {
+		from = 0
+	}
+
+	// 1. Native transactions, newest blocks first
+	scan, err := p.scanBlocks(map[string]bool{address: true}, from, latest, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. ERC-20 and ERC-1155 transfers within the blocks actually scanned
+	logs, err := p.fetchTransferLogs(address, scan.lowest, latest, requestID)
+	if err != nil {
+		return nil, err
+	}
+	multiLogs, err := p.fetchERC1155Logs(address, scan.lowest, latest, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Receipts of every transaction involved
+	hashes := make([]string, 0, len(scan.txs)+len(logs)+len(multiLogs))
+	for _, tx := range scan.txs {
+		hashes = append(hashes, tx.Hash)
+	}
+	for _, l := range logs {
+		hashes = append(hashes, l.TransactionHash)
+	}
+	for _, l := range multiLogs {
+		hashes = append(hashes, l.TransactionHash)
+	}
+	receipts, err := p.fetchReceipts(hashes, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	normalTxs := p.transformTransactions(scan, receipts, address)
+	tokenTxs := p.transformTransferLogs(logs, scan.timestamps, receipts, address)
+	tokenTxs = append(tokenTxs, p.transformERC1155Logs(multiLogs, scan.timestamps, receipts, address)...)
+	all := append(normalTxs, tokenTxs...)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int64("from_block", scan.lowest).
+		Int64("to_block", latest).
+		Int("normal", len(normalTxs)).
+		Int("token", len(tokenTxs)).
+		Int("total", len(all)).
+		Msg("RPC scan provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// call performs a single JSON-RPC call and decodes its result into out.
+// opts are passed on to the HTTP request, e.g. the request ID.
+func (p *RPCScanProvider) call(label, method string, params []interface{}, out interface{}, opts ...utils.RequestOption) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	req := types.RpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params}
+
+	opts = append([]utils.RequestOption{utils.WithProviderKey(provider.Key("rpcscan", p.cfg.ChainName))}, opts...)
+	var resp types.RpcResponse
+	if err := utils.DoHttpRequestWithLogging("POST", label, p.cfg.URL, req,
+		map[string]string{"Content-Type": "application/json"}, &resp, opts...); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return json.Unmarshal(resp.Result, out)
+}
+
+// batchCall sends one request per params entry in JSON-RPC batches of
+// batch_size and returns the raw results in the same order. Items the node
+// answers with a JSON-RPC error are logged and left nil; the call only fails
+// when the HTTP request does, or when every item errors. opts are passed on
+// to every HTTP request.
+func (p *RPCScanProvider) batchCall(label, method string, params [][]interface{}, opts ...utils.RequestOption) ([]json.RawMessage, error) {
+	opts = append([]utils.RequestOption{utils.WithProviderKey(provider.Key("rpcscan", p.cfg.ChainName))}, opts...)
+	results := make([]json.RawMessage, len(params))
+	var (
+		firstErr error
+		failed   int
+	)
+	for start := 0; start < len(params); start += p.cfg.BatchSize {
+		end := min(start+p.cfg.BatchSize, len(params))
+
+		reqs := make([]types.RpcRequest, 0, end-start)
+		for i := start; i < end; i++ {
+			reqs = append(reqs, types.RpcRequest{JSONRPC: "2.0", ID: i, Method: method, Params: params[i]})
+		}
+
+		var resps []types.RpcResponse
+		if err := utils.DoHttpRequestWithLogging("POST", fmt.Sprintf("%s.batch.%d", label, len(reqs)), p.cfg.URL, reqs,
+			map[string]string{"Content-Type": "application/json"}, &resps, opts...); err != nil {
+			return nil, err
+		}
+		for _, r := range resps {
+			if r.ID < start || r.ID >= end {
+				return nil, fmt.Errorf("%s: unexpected response id %d", label, r.ID)
+			}
+			if r.Error != nil {
+				logger.Log.Warn().
+					Err(r.Error).
+					Str("chain", p.cfg.ChainName).
+					Str("label", label).
+					Int("item", r.ID).
+					Msg("Skipping failed JSON-RPC batch item")
+				if firstErr == nil {
+					firstErr = r.Error
+				}
+				failed++
+				continue
+			}
+			results[r.ID] = r.Result
+		}
+	}
+	if len(params) > 0 && failed == len(params) {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// hexBlock formats a block number as a JSON-RPC quantity.
+func hexBlock(n int64) string {
+	return "0x" + strconv.FormatInt(n, 16)
+}
@@ -0,0 +1,204 @@
+package rpcscan
+
+import (
+	"encoding/json"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// blockScan is the outcome of scanning the lookback window.
+type blockScan struct {
+	txs        []types.RpcTransaction // transactions sent or received by a matched address
+	timestamps map[int64]int64        // block number → Unix seconds, for every scanned block
+	lowest     int64                  // lowest block scanned
+}
+
+// scanBlocks reads blocks [from, to] with full transactions, newest batch
+// first, and keeps the transactions whose sender or recipient is one of
+// addresses (lowercase). A failure after the first batch keeps the newer
+// blocks already scanned.
+func (p *RPCScanProvider) scanBlocks(addresses map[string]bool, from, to int64, opts ...utils.RequestOption) (*blockScan, error) {
+	scan := &blockScan{timestamps: make(map[int64]int64), lowest: to + 1}
+	batch := int64(p.cfg.BatchSize)
+
+	for end := to; end >= from; end -= batch {
+		start := max(from, end-batch+1)
+		params := make([][]interface{}, 0, end-start+1)
+		for n := end; n >= start; n-- {
+			params = append(params, []interface{}{hexBlock(n), true})
+		}
+
+		raw, err := p.batchCall("rpcscan.blocks", "eth_getBlockByNumber", params, opts...)
+		if err != nil {
+			if end == to {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Int64("scanned_from", scan.lowest).
+				Msg("Block scan aborted, keeping newer blocks")
+			break
+		}
+
+		for _, r := range raw {
+			var block types.RpcBlock
+			if err := json.Unmarshal(r, &block); err != nil || block.Number == "" {
+				continue // pruned or not yet available
+			}
+			number := utils.ParseStringToInt64OrDefault(block.Number, 0)
+			scan.timestamps[number] = utils.ParseStringToInt64OrDefault(block.Timestamp, 0)
+			for _, tx := range block.Transactions {
+				if addresses[strings.ToLower(tx.From)] || addresses[strings.ToLower(tx.To)] {
+					scan.txs = append(scan.txs, tx)
+				}
+			}
+		}
+		scan.lowest = start
+	}
+	return scan, nil
+}
+
+// fetchReceipts returns the receipts of the given transactions keyed by hash.
+// Duplicate hashes are requested once.
+func (p *RPCScanProvider) fetchReceipts(hashes []string, opts ...utils.RequestOption) (map[string]types.RpcReceipt, error) {
+	seen := make(map[string]bool, len(hashes))
+	params := make([][]interface{}, 0, len(hashes))
+	for _, h := range hashes {
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		params = append(params, []interface{}{h})
+	}
+
+	raw, err := p.batchCall("rpcscan.receipts", "eth_getTransactionReceipt", params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make(map[string]types.RpcReceipt, len(raw))
+	for _, r := range raw {
+		var receipt types.RpcReceipt
+		if err := json.Unmarshal(r, &receipt); err == nil && receipt.TransactionHash != "" {
+			receipts[receipt.TransactionHash] = receipt
+		}
+	}
+	return receipts, nil
+}
+
+// transformTransactions converts the matched block transactions into native
+// rows. State and gas used come from the receipt; without one the row is
+// reported as failed with unknown gas. On rollups the receipt also gives
+// the L1 data fee. A receipt holding a DEX Swap event
+// makes the row a swap, else one holding a Deposit or Withdrawal of the
+// wrapped-native contract makes it a wrap or unwrap. A transaction without
+// recipient whose receipt holds a contract address is a contract creation,
+// the events of a batch call become its sub-actions, and a Safe execution
+// event records the outcome of the Safe transaction executed.
+func (p *RPCScanProvider) transformTransactions(scan *blockScan, receipts map[string]types.RpcReceipt, address string) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+
+	txs := make([]types.Transaction, 0, len(scan.txs))
+	for _, tx := range scan.txs {
+		height := utils.ParseStringToInt64OrDefault(tx.BlockNumber, 0)
+		balance, err := utils.NormalizeNumericString(tx.Value)
+		if err != nil {
+			balance = "0"
+		}
+		gasLimit, _ := utils.NormalizeNumericString(tx.Gas)
+		gasPrice, _ := utils.NormalizeNumericString(tx.GasPrice)
+		nonce, _ := utils.NormalizeNumericString(tx.Nonce)
+
+		state := types.TxStateFail
+		txType := types.TxTypeUnknown // native transfer
+		var gasUsed, tokenAddr, created, l1Fee, fee string
+		var swap *types.SwapInfo
+		var actions []types.SubAction
+		var safe *types.SafeExecution
+		if receipt, ok := receipts[tx.Hash]; ok {
+			if receipt.Status == "0x1" {
+				state = types.TxStateSuccess
+			}
+			gasUsed, _ = utils.NormalizeNumericString(receipt.GasUsed)
+			if receipt.EffectiveGasPrice != "" {
+				gasPrice, _ = utils.NormalizeNumericString(receipt.EffectiveGasPrice)
+			}
+			l1Fee, fee, _ = utils.FeeBreakdown(gasUsed, gasPrice, receipt.L1Fee, receipt.GasUsedForL1)
+			if tx.To == "" && receipt.ContractAddress != "" {
+				// A deployment keeps its type whatever its constructor emitted
+				txType, created = types.TxTypeContractCreation, strings.ToLower(receipt.ContractAddress)
+			} else {
+				for _, l := range receipt.Logs {
+					if protocol, ok := utils.DetectSwapEvent(l.Topics); ok {
+						// A swap may wrap or unwrap along the way: it wins
+						txType, tokenAddr, swap = types.TxTypeSwap, "", &types.SwapInfo{Protocol: protocol}
+						break
+					}
+					if wrapType, ok := utils.DetectWrapEvent(p.chainID, l.Address, l.Topics); ok {
+						txType, tokenAddr = wrapType, strings.ToLower(l.Address)
+					}
+				}
+			}
+			if utils.IsBatchCall(tx.Input) {
+				for _, l := range receipt.Logs {
+					actions = append(actions, utils.LogSubActions(p.chainID, l.Address, l.Topics, l.Data)...)
+				}
+			}
+			for _, l := range receipt.Logs {
+				// The last one is that of the outermost Safe
+				if exec, ok := utils.DetectSafeExecution(l.Address, l.Topics, l.Data); ok {
+					safe = exec
+				}
+			}
+		}
+
+		tranType := types.TransTypeOut
+		if strings.EqualFold(tx.To, address) {
+			tranType = types.TransTypeIn
+		}
+		unixTime := scan.timestamps[height]
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            state,
+			Height:           height,
+			Hash:             tx.Hash,
+			TxIndex:          utils.ParseStringToInt64OrDefault(tx.TransactionIndex, 0),
+			BlockHash:        tx.BlockHash,
+			FromAddress:      strings.ToLower(tx.From),
+			ToAddress:        strings.ToLower(tx.To),
+			TokenAddress:     tokenAddr,
+			Balance:          balance,
+			Amount:           utils.DivideByDecimals(balance, int(nativeDecimals)),
+			GasUsed:          gasUsed,
+			GasLimit:         gasLimit,
+			GasPrice:         gasPrice,
+			Nonce:            nonce,
+			Type:             txType,
+			CoinType:         types.CoinTypeNative,
+			TokenDisplayName: nativeSymbol,
+			Decimals:         nativeDecimals,
+			CreatedTime:      unixTime,
+			ModifiedTime:     unixTime,
+			TranType:         tranType,
+			Swap:             swap,
+			ContractAddress:  created,
+			Actions:          actions,
+			Safe:             safe,
+			L1Fee:            l1Fee,
+			Fee:              fee,
+		})
+	}
+	return txs
+}
@@ -0,0 +1,11 @@
+package rpcscan
+
+import "tx-aggregator/provider"
+
+var _ provider.HealthChecker = (*RPCScanProvider)(nil)
+
+// HealthCheck asks the node for its latest block number.
+func (p *RPCScanProvider) HealthCheck() error {
+	var head string
+	return p.call("rpcscan.healthCheck", "eth_blockNumber", nil, &head)
+}
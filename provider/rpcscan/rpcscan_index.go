@@ -0,0 +1,76 @@
+package rpcscan
+
+import (
+	"fmt"
+	"strings"
+
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// LatestBlock returns the current head of the node.
+func (p *RPCScanProvider) LatestBlock() (int64, error) {
+	var head string
+	if err := p.call("rpcscan.blockNumber", "eth_blockNumber", nil, &head); err != nil {
+		return 0, err
+	}
+	return utils.ParseStringToInt64OrDefault(head, 0), nil
+}
+
+// IndexRange reads blocks [from, to] once for all addresses and returns the
+// native transactions and ERC-20 transfers of each address that has any,
+// keyed by lowercase address. Unlike GetTransactions it never returns a
+// partial range: the indexer would otherwise skip the blocks left out.
+func (p *RPCScanProvider) IndexRange(addresses []string, from, to int64) (map[string][]types.Transaction, error) {
+	if len(addresses) == 0 || from > to {
+		return nil, nil
+	}
+	watched := make(map[string]bool, len(addresses))
+	for _, a := range addresses {
+		watched[strings.ToLower(a)] = true
+	}
+
+	scan, err := p.scanBlocks(watched, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if scan.lowest > from {
+		return nil, fmt.Errorf("rpcscan: block scan stopped at %d, before %d", scan.lowest, from)
+	}
+
+	logs := make(map[string][]types.RpcReceiptLog, len(watched))
+	hashes := make([]string, 0, len(scan.txs))
+	for _, tx := range scan.txs {
+		hashes = append(hashes, tx.Hash)
+	}
+	for address := range watched {
+		l, err := p.fetchTransferLogs(address, from, to)
+		if err != nil {
+			return nil, err
+		}
+		logs[address] = l
+		for _, entry := range l {
+			hashes = append(hashes, entry.TransactionHash)
+		}
+	}
+	receipts, err := p.fetchReceipts(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]types.Transaction)
+	for address := range watched {
+		own := &blockScan{timestamps: scan.timestamps, lowest: scan.lowest}
+		for _, tx := range scan.txs {
+			if strings.EqualFold(tx.From, address) || strings.EqualFold(tx.To, address) {
+				own.txs = append(own.txs, tx)
+			}
+		}
+		txs := append(p.transformTransactions(own, receipts, address),
+			p.transformTransferLogs(logs[address], scan.timestamps, receipts, address)...)
+		if len(txs) > 0 {
+			out[address] = txs
+		}
+	}
+	return out, nil
+}
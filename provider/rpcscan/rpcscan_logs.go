@@ -0,0 +1,339 @@
+package rpcscan
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"slices"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	// transferTopic is keccak256("Transfer(address,address,uint256)").
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// Selectors of the ERC-20 metadata getters.
+	decimalsSelector = "0x313ce567"
+	symbolSelector   = "0x95d89b41"
+)
+
+// fetchTransferLogs returns the ERC-20 and ERC-721 Transfer logs sent or
+// received by address in blocks [from, to], in chunks of log_range blocks.
+// ERC-721 transfers have a fourth topic, the token ID.
+func (p *RPCScanProvider) fetchTransferLogs(address string, from, to int64, opts ...utils.RequestOption) ([]types.RpcReceiptLog, error) {
+	addrTopic := "0x000000000000000000000000" + strings.TrimPrefix(address, "0x")
+	logs, err := p.fetchLogs("rpcscan.logs", [][]interface{}{
+		{transferTopic, addrTopic},      // sent
+		{transferTopic, nil, addrTopic}, // received
+	}, from, to, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(logs, func(l types.RpcReceiptLog) bool { return len(l.Topics) != 3 && len(l.Topics) != 4 }), nil
+}
+
+// fetchERC1155Logs returns the ERC-1155 TransferSingle and TransferBatch
+// logs sent or received by address in blocks [from, to].
+func (p *RPCScanProvider) fetchERC1155Logs(address string, from, to int64, opts ...utils.RequestOption) ([]types.RpcReceiptLog, error) {
+	addrTopic := "0x000000000000000000000000" + strings.TrimPrefix(address, "0x")
+	events := []interface{}{utils.ERC1155TransferSingleTopic, utils.ERC1155TransferBatchTopic}
+	logs, err := p.fetchLogs("rpcscan.erc1155Logs", [][]interface{}{
+		{events, nil, addrTopic},      // sent
+		{events, nil, nil, addrTopic}, // received
+	}, from, to, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(logs, func(l types.RpcReceiptLog) bool { return !utils.IsERC1155Event(l.Topics) }), nil
+}
+
+// fetchLogs runs eth_getLogs for every topic filter in blocks [from, to], in
+// chunks of log_range blocks, and returns the logs not removed by a reorg.
+func (p *RPCScanProvider) fetchLogs(label string, filters [][]interface{}, from, to int64, opts ...utils.RequestOption) ([]types.RpcReceiptLog, error) {
+	if from > to {
+		return nil, nil
+	}
+
+	var params [][]interface{}
+	for start := from; start <= to; start += p.cfg.LogRange {
+		end := min(start+p.cfg.LogRange-1, to)
+		for _, topics := range filters {
+			params = append(params, []interface{}{types.RpcLogFilter{
+				FromBlock: hexBlock(start),
+				ToBlock:   hexBlock(end),
+				Topics:    topics,
+			}})
+		}
+	}
+
+	raw, err := p.batchCall(label, "eth_getLogs", params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []types.RpcReceiptLog
+	for _, r := range raw {
+		if r == nil {
+			continue // failed chunk, logged by batchCall
+		}
+		var chunk []types.RpcReceiptLog
+		if err := json.Unmarshal(r, &chunk); err != nil {
+			return nil, err
+		}
+		for _, l := range chunk {
+			if !l.Removed {
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// transformTransferLogs converts Transfer logs into token rows: ERC-20 rows
+// carry the amount, ERC-721 rows the token ID. A transfer to self matches
+// both log queries and is kept once. Token metadata is only looked up for
+// ERC-20 tokens, as ERC-721 contracts have no decimals().
+func (p *RPCScanProvider) transformTransferLogs(
+	logs []types.RpcReceiptLog,
+	timestamps map[int64]int64,
+	receipts map[string]types.RpcReceipt,
+	address string,
+) []types.Transaction {
+	fungible := slices.DeleteFunc(slices.Clone(logs), func(l types.RpcReceiptLog) bool { return len(l.Topics) != 3 })
+	p.resolveTokens(fungible)
+
+	seen := make(map[string]bool, len(logs))
+	txs := make([]types.Transaction, 0, len(logs))
+	for _, l := range logs {
+		key := l.TransactionHash + ":" + l.LogIndex
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		ev, ok := utils.DetectTokenEvent(l.Address, l.Topics, l.Data)
+		if !ok {
+			continue
+		}
+		var raw, amount string
+		var info tokenInfo
+		if ev.CoinType == types.CoinTypeERC721 {
+			// An NFT: one token, identified by the fourth topic
+			raw, amount = "1", "1"
+		} else {
+			var err error
+			if raw, err = utils.NormalizeNumericString(l.Data); err != nil {
+				raw = "0"
+			}
+			p.mu.Lock()
+			info = p.tokens[ev.TokenAddress]
+			p.mu.Unlock()
+			amount = utils.DivideByDecimals(raw, int(info.decimals))
+		}
+
+		from := topicAddress(l.Topics[1])
+		to := topicAddress(l.Topics[2])
+		height := utils.ParseStringToInt64OrDefault(l.BlockNumber, 0)
+
+		state := types.TxStateFail
+		var gasUsed, gasPrice string
+		if receipt, ok := receipts[l.TransactionHash]; ok {
+			if receipt.Status == "0x1" {
+				state = types.TxStateSuccess
+			}
+			gasUsed, _ = utils.NormalizeNumericString(receipt.GasUsed)
+			gasPrice, _ = utils.NormalizeNumericString(receipt.EffectiveGasPrice)
+		}
+
+		tranType := types.TransTypeOut
+		if to == address {
+			tranType = types.TransTypeIn
+		}
+		unixTime := timestamps[height]
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            state,
+			Height:           height,
+			Hash:             l.TransactionHash,
+			TxIndex:          utils.ParseStringToInt64OrDefault(l.TransactionIndex, 0),
+			BlockHash:        l.BlockHash,
+			LogIndex:         utils.ParseStringToInt64OrDefault(l.LogIndex, 0),
+			FromAddress:      from,
+			ToAddress:        to,
+			TokenAddress:     ev.TokenAddress,
+			TokenID:          utils.TokenIDToInt64(ev.TokenID),
+			TokenIDRaw:       ev.TokenID,
+			Balance:          raw,
+			Amount:           amount,
+			GasUsed:          gasUsed,
+			GasPrice:         gasPrice,
+			Type:             types.TxTypeTransfer,
+			CoinType:         ev.CoinType,
+			TokenDisplayName: info.symbol,
+			Decimals:         info.decimals,
+			CreatedTime:      unixTime,
+			ModifiedTime:     unixTime,
+			TranType:         tranType,
+		})
+	}
+	return txs
+}
+
+// transformERC1155Logs converts ERC-1155 transfer logs into one row per
+// token ID moved. A transfer to self matches both log queries and is kept
+// once; logs that fail to decode are skipped.
+func (p *RPCScanProvider) transformERC1155Logs(
+	logs []types.RpcReceiptLog,
+	timestamps map[int64]int64,
+	receipts map[string]types.RpcReceipt,
+	address string,
+) []types.Transaction {
+	seen := make(map[string]bool, len(logs))
+	var txs []types.Transaction
+	for _, l := range logs {
+		key := l.TransactionHash + ":" + l.LogIndex
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		transfers, err := utils.DecodeERC1155Event(l.Topics, l.Data)
+		if err != nil {
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("hash", l.TransactionHash).
+				Msg("Skipping undecodable ERC-1155 log")
+			continue
+		}
+
+		height := utils.ParseStringToInt64OrDefault(l.BlockNumber, 0)
+		state := types.TxStateFail
+		var gasUsed, gasPrice string
+		if receipt, ok := receipts[l.TransactionHash]; ok {
+			if receipt.Status == "0x1" {
+				state = types.TxStateSuccess
+			}
+			gasUsed, _ = utils.NormalizeNumericString(receipt.GasUsed)
+			gasPrice, _ = utils.NormalizeNumericString(receipt.EffectiveGasPrice)
+		}
+		unixTime := timestamps[height]
+
+		for _, t := range transfers {
+			tranType := types.TransTypeOut
+			if t.To == address {
+				tranType = types.TransTypeIn
+			}
+			txs = append(txs, types.Transaction{
+				ChainID:      p.chainID,
+				TokenID:      utils.TokenIDToInt64(t.TokenID),
+				TokenIDRaw:   t.TokenID,
+				State:        state,
+				Height:       height,
+				Hash:         l.TransactionHash,
+				TxIndex:      utils.ParseStringToInt64OrDefault(l.TransactionIndex, 0),
+				BlockHash:    l.BlockHash,
+				LogIndex:     utils.ParseStringToInt64OrDefault(l.LogIndex, 0),
+				FromAddress:  t.From,
+				ToAddress:    t.To,
+				TokenAddress: strings.ToLower(l.Address),
+				Balance:      t.Value,
+				Amount:       t.Value,
+				GasUsed:      gasUsed,
+				GasPrice:     gasPrice,
+				Type:         types.TxTypeTransfer,
+				CoinType:     types.CoinTypeERC1155,
+				CreatedTime:  unixTime,
+				ModifiedTime: unixTime,
+				TranType:     tranType,
+			})
+		}
+	}
+	return txs
+}
+
+// resolveTokens loads symbol and decimals of every token not cached yet with
+// eth_call. Tokens whose lookup fails are cached with 18 decimals, marked as
+// a fallback, and retried on the next request.
+func (p *RPCScanProvider) resolveTokens(logs []types.RpcReceiptLog) {
+	var missing []string
+	p.mu.Lock()
+	for _, l := range logs {
+		token := strings.ToLower(l.Address)
+		if info, ok := p.tokens[token]; (!ok || info.fallback) && !slices.Contains(missing, token) {
+			missing = append(missing, token)
+		}
+	}
+	p.mu.Unlock()
+	if len(missing) == 0 {
+		return
+	}
+
+	params := make([][]interface{}, 0, 2*len(missing))
+	for _, token := range missing {
+		params = append(params,
+			[]interface{}{map[string]string{"to": token, "data": decimalsSelector}, "latest"},
+			[]interface{}{map[string]string{"to": token, "data": symbolSelector}, "latest"},
+		)
+	}
+	raw, err := p.batchCall("rpcscan.tokenMetadata", "eth_call", params)
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("chain", p.cfg.ChainName).
+			Int("tokens", len(missing)).
+			Msg("Token metadata lookup failed, assuming 18 decimals")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, token := range missing {
+		info := tokenInfo{decimals: types.NativeDefaultDecimals, fallback: true}
+		if raw != nil {
+			var decimalsHex, symbolHex string
+			_ = json.Unmarshal(raw[2*i+1], &symbolHex)
+			info.symbol = decodeABIString(symbolHex)
+			if json.Unmarshal(raw[2*i], &decimalsHex) == nil && decimalsHex != "" && decimalsHex != "0x" {
+				info.decimals = utils.ParseStringToInt64OrDefault(decimalsHex, types.NativeDefaultDecimals)
+				info.fallback = false
+			}
+		}
+		p.tokens[token] = info
+	}
+}
+
+// topicAddress extracts the lowercase address from a 32-byte indexed topic.
+func topicAddress(topic string) string {
+	topic = strings.ToLower(strings.TrimPrefix(topic, "0x"))
+	if len(topic) < 40 {
+		return ""
+	}
+	return "0x" + topic[len(topic)-40:]
+}
+
+// decodeABIString decodes an ABI-encoded string return value, falling back
+// to a NUL-padded bytes32 as used by some older tokens.
+func decodeABIString(s string) string {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) == 0 {
+		return ""
+	}
+	if len(b) >= 64 {
+		offset := new(big.Int).SetBytes(b[:32])
+		if offset.IsInt64() && offset.Int64()+32 <= int64(len(b)) {
+			start := offset.Int64()
+			length := new(big.Int).SetBytes(b[start : start+32])
+			if length.IsInt64() && start+32+length.Int64() <= int64(len(b)) {
+				return string(b[start+32 : start+32+length.Int64()])
+			}
+		}
+	}
+	if len(b) == 32 {
+		return strings.TrimRight(string(b), "\x00")
+	}
+	return ""
+}
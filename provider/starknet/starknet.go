@@ -0,0 +1,159 @@
+// Package starknet serves Starknet through the Voyager explorer API.
+// Starknet has no native coin transfer: ETH and STRK are ERC-20 (SNIP-2)
+// contracts, so value movements are token rows from the Transfer events and
+// transactions only carry the fee. Addresses are felts, normalized to
+// lowercase and zero-padded to 64 hex digits.
+package starknet
+
+import (
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*StarknetProvider)(nil)
+
+const (
+	defaultURL = "https://api.voyager.online/beta"
+	// apiKeyHeader carries the Voyager API key.
+	apiKeyHeader = "x-api-key"
+	// defaultPageSize is used when request_page_size is unset; it is also
+	// the API maximum.
+	defaultPageSize = 100
+	// defaultMaxPages caps pagination when max_pages is unset.
+	defaultMaxPages = 10
+)
+
+// StarknetProvider serves one Starknet network.
+type StarknetProvider struct {
+	chainID int64
+	cfg     types.StarknetConfig
+}
+
+// NewStarknetProvider constructs a provider for one Starknet network.
+func NewStarknetProvider(chainID int64, cfg types.StarknetConfig) *StarknetProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.RequestPageSize <= 0 || cfg.RequestPageSize > defaultPageSize {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Msg("Initializing StarknetProvider")
+
+	return &StarknetProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions fetches transactions and ERC-20 transfers concurrently,
+// patches fee and state into the transfer rows and returns them together.
+func (p *StarknetProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := canonical(params.Address)
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from Voyager")
+
+	var normalTxs, tokenTxs []types.Transaction
+
+	g := new(errgroup.Group)
+
+	// 1. Transactions sent by the account (fee carriers)
+	g.Go(func() error {
+		items, err := p.fetchTransactions(params.RequestID, address, maxPages)
+		if err != nil {
+			return err
+		}
+		normalTxs = p.transformTransactions(items)
+		return nil
+	})
+
+	// 2. ERC-20 transfers in and out
+	g.Go(func() error {
+		items, err := p.fetchTransfers(params.RequestID, address, maxPages)
+		if err != nil {
+			return err
+		}
+		tokenTxs = p.transformTransfers(items, address)
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Msg("Voyager fetch failed")
+		return nil, err
+	}
+
+	tokenTxs = utils.PatchTokenTransactionsWithNormalTxInfo(tokenTxs, normalTxs)
+	all := append(normalTxs, tokenTxs...)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("normal", len(normalTxs)).
+		Int("token", len(tokenTxs)).
+		Int("total", len(all)).
+		Msg("Starknet provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchAllPages reads 1-based pages until lastPage, a short page or maxPages
+// is reached. A failure on a later page keeps the items collected so far.
+func fetchAllPages[T any](p *StarknetProvider, maxPages int64, label string, fetchPage func(page int64) ([]T, int64, error)) ([]T, error) {
+	var all []T
+	for page := int64(1); page <= maxPages; page++ {
+		items, lastPage, err := fetchPage(page)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", label).
+				Int64("page", page).
+				Msg("Voyager pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if page >= lastPage || int64(len(items)) < p.cfg.RequestPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// sendRequest performs a GET against Voyager with the API key header. Extra options,
+// such as the request ID, are passed through.
+func (p *StarknetProvider) sendRequest(label, url string, out interface{}, opts ...utils.RequestOption) error {
+	headers := map[string]string{}
+	if p.cfg.APIKey != "" {
+		headers[apiKeyHeader] = p.cfg.APIKey
+	}
+	opts = append([]utils.RequestOption{utils.WithProviderKey(provider.Key("starknet", p.cfg.ChainName))}, opts...)
+	return utils.DoHttpRequestWithLogging("GET", label, url, nil, headers, out, opts...)
+}
+
+// canonical returns the padded lowercase form of a felt address, or the
+// input unchanged when it is not one.
+func canonical(addr string) string {
+	if c, ok := utils.StarknetToCanonical(addr); ok {
+		return c
+	}
+	return addr
+}
@@ -0,0 +1,80 @@
+package starknet
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTransactions reads all pages of transactions sent by address.
+func (p *StarknetProvider) fetchTransactions(requestID, address string, maxPages int64) ([]types.VoyagerTxn, error) {
+	return fetchAllPages(p, maxPages, "txns", func(page int64) ([]types.VoyagerTxn, int64, error) {
+		q := url.Values{
+			"to": {address},
+			"ps": {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+			"p":  {strconv.FormatInt(page, 10)},
+		}
+		u := fmt.Sprintf("%s/txns?%s", p.cfg.URL, q.Encode())
+
+		var out types.VoyagerTxnsResponse
+		if err := p.sendRequest("starknet.txns", u, &out, utils.WithRequestID(requestID)); err != nil {
+			return nil, 0, err
+		}
+		return out.Items, out.LastPage, nil
+	})
+}
+
+// transformTransactions converts transactions into zero-value native rows
+// that carry the fee, reported as gasUsed with gasPrice 1. Rows of
+// transactions that also moved tokens are later dropped as native shadows.
+func (p *StarknetProvider) transformTransactions(items []types.VoyagerTxn) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+
+	txs := make([]types.Transaction, 0, len(items))
+	for _, it := range items {
+		fee, err := utils.NormalizeNumericString(it.ActualFee)
+		if err != nil {
+			fee = "0"
+		}
+		state := types.TxStateSuccess
+		if strings.EqualFold(it.ExecutionStatus, "reverted") {
+			state = types.TxStateFail
+		}
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            state,
+			Height:           it.BlockNumber,
+			Hash:             it.Hash,
+			TxIndex:          it.Index,
+			BlockHash:        it.BlockHash,
+			FromAddress:      canonical(it.SenderAddress),
+			ToAddress:        canonical(it.ContractAddress),
+			Balance:          "0",
+			Amount:           "0",
+			GasUsed:          fee,
+			GasPrice:         "1",
+			Type:             types.TxTypeUnknown, // native transfer
+			CoinType:         types.CoinTypeNative,
+			TokenDisplayName: nativeSymbol,
+			Decimals:         nativeDecimals,
+			CreatedTime:      it.Timestamp,
+			ModifiedTime:     it.Timestamp,
+			TranType:         types.TransTypeOut,
+		})
+	}
+	return txs
+}
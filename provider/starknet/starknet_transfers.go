@@ -0,0 +1,72 @@
+package starknet
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTransfers reads all pages of ERC-20 transfers involving address.
+func (p *StarknetProvider) fetchTransfers(requestID, address string, maxPages int64) ([]types.VoyagerTransfer, error) {
+	return fetchAllPages(p, maxPages, "transfers", func(page int64) ([]types.VoyagerTransfer, int64, error) {
+		q := url.Values{
+			"ps": {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+			"p":  {strconv.FormatInt(page, 10)},
+		}
+		u := fmt.Sprintf("%s/contracts/%s/transfers?%s", p.cfg.URL, address, q.Encode())
+
+		var out types.VoyagerTransfersResponse
+		if err := p.sendRequest("starknet.transfers", u, &out, utils.WithRequestID(requestID)); err != nil {
+			return nil, 0, err
+		}
+		return out.Items, out.LastPage, nil
+	})
+}
+
+// transformTransfers converts ERC-20 (SNIP-2) Transfer events into token
+// rows, ETH and STRK included. Addresses are padded to 64 digits so that they
+// compare equal to the queried account.
+func (p *StarknetProvider) transformTransfers(items []types.VoyagerTransfer, address string) []types.Transaction {
+	txs := make([]types.Transaction, 0, len(items))
+	for _, it := range items {
+		raw, err := utils.NormalizeNumericString(it.TransferAmount)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+		decimals, err := it.Decimals.Int64()
+		if err != nil {
+			decimals = types.NativeDefaultDecimals
+		}
+
+		from, to := canonical(it.TransferFrom), canonical(it.TransferTo)
+		tranType := types.TransTypeIn
+		if from == address {
+			tranType = types.TransTypeOut
+		}
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            types.TxStateSuccess,
+			Height:           it.BlockNumber,
+			Hash:             it.TxHash,
+			FromAddress:      from,
+			ToAddress:        to,
+			TokenAddress:     canonical(it.TokenAddress),
+			Balance:          raw,
+			Amount:           utils.DivideByDecimals(raw, int(decimals)),
+			Type:             types.TxTypeTransfer,
+			CoinType:         types.CoinTypeToken,
+			TokenDisplayName: it.TokenSymbol,
+			Decimals:         decimals,
+			CreatedTime:      it.Timestamp,
+			ModifiedTime:     it.Timestamp,
+			TranType:         tranType,
+		})
+	}
+	return txs
+}
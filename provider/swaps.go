@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"strconv"
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// fillSwaps completes the swap rows flagged by the providers' log scans with
+// what address gave and got, read from the other rows of the same
+// transaction: the first token, native or internal leg sent by address is
+// the token in, the first one received the token out. The swap row's own
+// native value counts as sent. Legs no provider reported stay empty.
+func fillSwaps(address string, rows []types.Transaction) {
+	byTx := make(map[string][]int)
+	for i, tx := range rows {
+		key := strconv.FormatInt(tx.ChainID, 10) + ":" + strings.ToLower(tx.Hash)
+		byTx[key] = append(byTx[key], i)
+	}
+
+	for i := range rows {
+		if rows[i].Type != types.TxTypeSwap || rows[i].Swap == nil {
+			continue
+		}
+		swap := *rows[i].Swap
+		key := strconv.FormatInt(rows[i].ChainID, 10) + ":" + strings.ToLower(rows[i].Hash)
+		for _, j := range byTx[key] {
+			leg := rows[j]
+			if leg.Type == types.TxTypeApprove || leg.Balance == "" || leg.Balance == "0" {
+				continue
+			}
+			sent := strings.EqualFold(leg.FromAddress, address)
+			received := strings.EqualFold(leg.ToAddress, address)
+			if sent == received {
+				continue // a transfer to self, or between others
+			}
+			if sent && swap.TokenIn == "" {
+				swap.TokenIn, swap.AmountIn = swapToken(leg), leg.Amount
+			} else if received && j != i && swap.TokenOut == "" {
+				swap.TokenOut, swap.AmountOut = swapToken(leg), leg.Amount
+			}
+		}
+		rows[i].Swap = &swap
+	}
+}
+
+// swapToken names the asset of a swap leg: its contract, or "native".
+func swapToken(tx types.Transaction) string {
+	if types.IsTokenCoinType(tx.CoinType) {
+		return strings.ToLower(tx.TokenAddress)
+	}
+	return types.NativeTokenName
+}
@@ -0,0 +1,156 @@
+// Package thegraph serves protocol-specific histories from a subgraph. The
+// GraphQL query and the mapping of entity fields onto transactions both come
+// from config, so a new deployment needs no Go code: every entity returned
+// by the query becomes one transaction row.
+package thegraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*TheGraphProvider)(nil)
+
+const (
+	// defaultPageSize is used when request_page_size is unset.
+	defaultPageSize = 100
+	// defaultMaxPages caps skip pagination when max_pages is unset.
+	defaultMaxPages = 10
+)
+
+// TheGraphProvider serves one chain from one subgraph deployment.
+type TheGraphProvider struct {
+	chainID int64
+	cfg     types.TheGraphConfig
+}
+
+// NewTheGraphProvider constructs a provider for one subgraph deployment.
+func NewTheGraphProvider(chainID int64, cfg types.TheGraphConfig) *TheGraphProvider {
+	if cfg.RequestPageSize <= 0 {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Str("entity", cfg.Entity).
+		Msg("Initializing TheGraphProvider")
+
+	return &TheGraphProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions runs the configured query page by page and maps every
+// returned entity into a transaction.
+func (p *TheGraphProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := strings.ToLower(params.Address)
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from subgraph")
+
+	entities, err := p.fetchEntities(params.RequestID, address, maxPages)
+	if err != nil {
+		logger.Log.Error().Err(err).Msg("Subgraph fetch failed")
+		return nil, err
+	}
+	all := p.transformEntities(entities, address)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("entities", len(entities)).
+		Int("total", len(all)).
+		Msg("TheGraph provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchEntities reads consecutive $skip pages until a short page or maxPages
+// is reached. A failure on a later page keeps the entities collected so far.
+func (p *TheGraphProvider) fetchEntities(requestID, address string, maxPages int64) ([]map[string]interface{}, error) {
+	var all []map[string]interface{}
+	for page := int64(0); page < maxPages; page++ {
+		items, err := p.query(requestID, address, page*p.cfg.RequestPageSize)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Int64("page", page+1).
+				Msg("Subgraph pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if int64(len(items)) < p.cfg.RequestPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// query runs one page of the configured query and returns the entities found
+// at the configured path. Numbers are kept as json.Number so that uint256
+// amounts survive decoding.
+func (p *TheGraphProvider) query(requestID, address string, skip int64) ([]map[string]interface{}, error) {
+	req := types.GraphQLRequest{
+		Query: p.cfg.Query,
+		Variables: map[string]interface{}{
+			"address": address,
+			"first":   p.cfg.RequestPageSize,
+			"skip":    skip,
+		},
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	if p.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.cfg.APIKey
+	}
+
+	var resp types.GraphQLResponse
+	if err := utils.DoHttpRequestWithLogging("POST", "thegraph.query", p.cfg.URL, req, headers, &resp,
+		utils.WithProviderKey(provider.Key("thegraph", p.cfg.ChainName)), utils.WithRequestID(requestID)); err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, resp.Errors
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("subgraph returned no data")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(resp.Data))
+	dec.UseNumber()
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode subgraph data: %w", err)
+	}
+
+	list, ok := lookup(data, p.cfg.Entity).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("subgraph data has no list at %q", p.cfg.Entity)
+	}
+	items := make([]map[string]interface{}, 0, len(list))
+	for _, it := range list {
+		if m, ok := it.(map[string]interface{}); ok {
+			items = append(items, m)
+		}
+	}
+	return items, nil
+}
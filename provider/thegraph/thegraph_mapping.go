@@ -0,0 +1,122 @@
+package thegraph
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// transformEntities maps subgraph entities onto transactions through the
+// configured field paths. Entities without a hash or with a non-numeric
+// value are skipped. Subgraphs only index executed transactions, so every
+// row is successful; gas is not available.
+func (p *TheGraphProvider) transformEntities(entities []map[string]interface{}, address string) []types.Transaction {
+	f := p.cfg.Fields
+
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+
+	txs := make([]types.Transaction, 0, len(entities))
+	for _, e := range entities {
+		hash := field(e, f.Hash)
+		if hash == "" {
+			softjson.SkipItem(e, errors.New("missing hash"))
+			continue
+		}
+		balance, err := utils.NormalizeNumericString(field(e, f.Value))
+		if err != nil {
+			softjson.SkipItem(e, err)
+			continue
+		}
+
+		from := strings.ToLower(field(e, f.From))
+		to := strings.ToLower(field(e, f.To))
+		tranType := types.TransTypeIn
+		if from == address {
+			tranType = types.TransTypeOut
+		}
+		ts := intField(e, f.Timestamp)
+
+		tx := types.Transaction{
+			ChainID:          p.chainID,
+			State:            types.TxStateSuccess,
+			Height:           intField(e, f.Height),
+			Hash:             hash,
+			FromAddress:      from,
+			ToAddress:        to,
+			Balance:          balance,
+			Type:             types.TxTypeUnknown, // native transfer
+			CoinType:         types.CoinTypeNative,
+			TokenDisplayName: nativeSymbol,
+			Decimals:         nativeDecimals,
+			CreatedTime:      ts,
+			ModifiedTime:     ts,
+			TranType:         tranType,
+		}
+		if token := strings.ToLower(field(e, f.TokenAddress)); token != "" {
+			tx.Type = types.TxTypeTransfer
+			tx.CoinType = types.CoinTypeToken
+			tx.TokenAddress = token
+			tx.TokenDisplayName = field(e, f.TokenSymbol)
+			if d, err := strconv.ParseInt(field(e, f.TokenDecimals), 10, 64); err == nil {
+				tx.Decimals = d
+			}
+		}
+		tx.Amount = utils.DivideByDecimals(balance, int(tx.Decimals))
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// field returns the value at a dotted path of an entity as a string, or ""
+// when the path is unset or missing.
+func field(e map[string]interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+	switch v := lookup(e, path).(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// intField returns the integer at a dotted path of an entity, or 0. Subgraphs
+// encode BigInt as decimal strings, so both strings and numbers are accepted.
+func intField(e map[string]interface{}, path string) int64 {
+	s := field(e, path)
+	if s == "" {
+		return 0
+	}
+	return utils.ParseStringToInt64OrDefault(s, 0)
+}
+
+// lookup walks a dotted path through nested JSON objects.
+func lookup(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
@@ -0,0 +1,170 @@
+// Package ton serves TON networks through toncenter API v3: TON transfers
+// from /transactions and jetton transfers from /jetton/transfers. Addresses
+// are normalized to the raw form ("0:<hex>") so that bounceable and
+// non-bounceable spellings of one account compare equal; TON has 9 decimals.
+package ton
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*TonProvider)(nil)
+
+const (
+	defaultURL = "https://toncenter.com/api/v3"
+	// apiKeyHeader carries the toncenter API key.
+	apiKeyHeader = "X-API-Key"
+	// defaultPageSize is used when request_page_size is unset.
+	defaultPageSize = 100
+	// defaultMaxPages caps offset pagination when max_pages is unset.
+	defaultMaxPages = 10
+	// tonDecimals is the number of decimals of TON (1 TON = 1e9 nanoton).
+	tonDecimals = 9
+)
+
+// jettonInfo is the cached metadata of a jetton master.
+type jettonInfo struct {
+	symbol   string
+	decimals int64
+}
+
+// TonProvider serves one TON network.
+type TonProvider struct {
+	chainID int64
+	cfg     types.TonConfig
+
+	mu      sync.Mutex
+	jettons map[string]jettonInfo // raw jetton master address → metadata
+}
+
+// NewTonProvider constructs a provider for one TON network.
+func NewTonProvider(chainID int64, cfg types.TonConfig) *TonProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.RequestPageSize <= 0 {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Msg("Initializing TonProvider")
+
+	return &TonProvider{
+		chainID: chainID,
+		cfg:     cfg,
+		jettons: make(map[string]jettonInfo),
+	}
+}
+
+// GetTransactions fetches TON transactions and jetton transfers concurrently
+// and returns them together.
+func (p *TonProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := toRaw(params.Address)
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from toncenter")
+
+	var normalTxs, tokenTxs []types.Transaction
+
+	g := new(errgroup.Group)
+
+	// 1. TON transfers (inbound and outbound messages)
+	g.Go(func() error {
+		items, err := p.fetchTransactions(params.RequestID, address, maxPages)
+		if err != nil {
+			return err
+		}
+		normalTxs = p.transformTransactions(items, address)
+		return nil
+	})
+
+	// 2. Jetton transfers
+	g.Go(func() error {
+		items, err := p.fetchJettonTransfers(params.RequestID, address, maxPages)
+		if err != nil {
+			return err
+		}
+		tokenTxs = p.transformJettonTransfers(items, address)
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Msg("toncenter fetch failed")
+		return nil, err
+	}
+
+	all := append(normalTxs, tokenTxs...)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("normal", len(normalTxs)).
+		Int("token", len(tokenTxs)).
+		Int("total", len(all)).
+		Msg("TON provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchAllPages reads consecutive offset pages until a short page or
+// maxPages is reached. A failure on a later page keeps the items collected
+// so far.
+func fetchAllPages[T any](p *TonProvider, maxPages int64, label string, fetchPage func(offset int64) ([]T, error)) ([]T, error) {
+	var all []T
+	for page := int64(0); page < maxPages; page++ {
+		items, err := fetchPage(page * p.cfg.RequestPageSize)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", label).
+				Int64("page", page+1).
+				Msg("toncenter pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if int64(len(items)) < p.cfg.RequestPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// sendRequest performs a GET against toncenter with the API key header. Extra options,
+// such as the request ID, are passed through.
+func (p *TonProvider) sendRequest(label, url string, out interface{}, opts ...utils.RequestOption) error {
+	headers := map[string]string{}
+	if p.cfg.APIKey != "" {
+		headers[apiKeyHeader] = p.cfg.APIKey
+	}
+	opts = append([]utils.RequestOption{utils.WithProviderKey(provider.Key("ton", p.cfg.ChainName))}, opts...)
+	return utils.DoHttpRequestWithLogging("GET", label, url, nil, headers, out, opts...)
+}
+
+// toRaw returns the raw form of a TON address, or the input unchanged when
+// it is not a valid TON address.
+func toRaw(addr string) string {
+	if raw, ok := utils.TonToRaw(addr); ok {
+		return raw
+	}
+	return addr
+}
@@ -0,0 +1,129 @@
+package ton
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchJettonTransfers reads all pages of jetton transfers sent or received
+// by address, newest first.
+func (p *TonProvider) fetchJettonTransfers(requestID, address string, maxPages int64) ([]types.TonJettonTransfer, error) {
+	return fetchAllPages(p, maxPages, "jettonTransfers", func(offset int64) ([]types.TonJettonTransfer, error) {
+		q := url.Values{
+			"owner_address": {address},
+			"limit":         {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+			"offset":        {strconv.FormatInt(offset, 10)},
+			"sort":          {"desc"},
+		}
+		u := fmt.Sprintf("%s/jetton/transfers?%s", p.cfg.URL, q.Encode())
+
+		var out types.TonJettonTransfersResponse
+		if err := p.sendRequest("ton.jettonTransfers", u, &out, utils.WithRequestID(requestID)); err != nil {
+			return nil, err
+		}
+		return out.JettonTransfers, nil
+	})
+}
+
+// transformJettonTransfers converts jetton transfers into CoinTypeToken rows
+// keyed by the raw jetton master address.
+func (p *TonProvider) transformJettonTransfers(items []types.TonJettonTransfer, address string) []types.Transaction {
+	var txs []types.Transaction
+	for _, it := range items {
+		raw, err := utils.NormalizeNumericString(it.Amount)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+		lt, err := strconv.ParseInt(it.TransactionLt, 10, 64)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+
+		master := toRaw(it.JettonMaster)
+		info := p.jettonInfo(master)
+		from, to := toRaw(it.Source), toRaw(it.Destination)
+
+		state := types.TxStateSuccess
+		if it.TransactionAborted {
+			state = types.TxStateFail
+		}
+		tranType := types.TransTypeOut
+		if to == address {
+			tranType = types.TransTypeIn
+		}
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            state,
+			Height:           lt,
+			Hash:             it.TransactionHash,
+			FromAddress:      from,
+			ToAddress:        to,
+			TokenAddress:     master,
+			Balance:          raw,
+			Amount:           utils.DivideByDecimals(raw, int(info.decimals)),
+			Type:             types.TxTypeTransfer,
+			CoinType:         types.CoinTypeToken,
+			TokenDisplayName: info.symbol,
+			Decimals:         info.decimals,
+			CreatedTime:      it.TransactionNow,
+			ModifiedTime:     it.TransactionNow,
+			TranType:         tranType,
+		})
+	}
+	return txs
+}
+
+// jettonInfo returns the symbol and decimals of a jetton master. Results are
+// cached for the lifetime of the provider; a failed lookup falls back to 9
+// decimals and is retried on the next request.
+func (p *TonProvider) jettonInfo(master string) jettonInfo {
+	p.mu.Lock()
+	info, ok := p.jettons[master]
+	p.mu.Unlock()
+	if ok {
+		return info
+	}
+
+	info, err := p.fetchJettonInfo(master)
+	if err != nil {
+		logger.Log.Warn().
+			Err(err).
+			Str("chain", p.cfg.ChainName).
+			Str("jetton", master).
+			Msg("Jetton metadata lookup failed, assuming 9 decimals")
+		return jettonInfo{decimals: types.TonDefaultJettonDecimals}
+	}
+
+	p.mu.Lock()
+	p.jettons[master] = info
+	p.mu.Unlock()
+	return info
+}
+
+// fetchJettonInfo looks up a jetton master in /jetton/masters.
+func (p *TonProvider) fetchJettonInfo(master string) (jettonInfo, error) {
+	q := url.Values{"address": {master}, "limit": {"1"}}
+	u := fmt.Sprintf("%s/jetton/masters?%s", p.cfg.URL, q.Encode())
+
+	var out types.TonJettonMastersResponse
+	if err := p.sendRequest("ton.jettonMasters", u, &out); err != nil {
+		return jettonInfo{}, err
+	}
+	if len(out.JettonMasters) == 0 {
+		return jettonInfo{}, fmt.Errorf("jetton master %s not found", master)
+	}
+	content := out.JettonMasters[0].JettonContent
+	return jettonInfo{
+		symbol:   content.Symbol,
+		decimals: utils.ParseStringToInt64OrDefault(content.Decimals, types.TonDefaultJettonDecimals),
+	}, nil
+}
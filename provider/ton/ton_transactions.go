@@ -0,0 +1,106 @@
+package ton
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTransactions reads all pages of transactions of address, newest first.
+func (p *TonProvider) fetchTransactions(requestID, address string, maxPages int64) ([]types.TonTransaction, error) {
+	return fetchAllPages(p, maxPages, "transactions", func(offset int64) ([]types.TonTransaction, error) {
+		q := url.Values{
+			"account": {address},
+			"limit":   {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+			"offset":  {strconv.FormatInt(offset, 10)},
+			"sort":    {"desc"},
+		}
+		u := fmt.Sprintf("%s/transactions?%s", p.cfg.URL, q.Encode())
+
+		var out types.TonTransactionsResponse
+		if err := p.sendRequest("ton.transactions", u, &out, utils.WithRequestID(requestID)); err != nil {
+			return nil, err
+		}
+		return out.Transactions, nil
+	})
+}
+
+// transformTransactions turns every value-carrying message of a transaction
+// into a native row: the inbound internal message (received TON) and each
+// outbound message (sent TON). External inbound messages only carry the
+// wallet signature and are skipped. Height is the logical time, the fee in
+// nanotons is reported as gasUsed with gasPrice 1.
+func (p *TonProvider) transformTransactions(items []types.TonTransaction, address string) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, tonDecimals)
+
+	var txs []types.Transaction
+	for _, it := range items {
+		lt, err := strconv.ParseInt(it.Lt, 10, 64)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+		state := types.TxStateSuccess
+		if it.Description.Aborted {
+			state = types.TxStateFail
+		}
+		fee := it.TotalFees
+		if fee == "" {
+			fee = "0"
+		}
+
+		row := func(msg types.TonMessage) {
+			balance, err := utils.NormalizeNumericString(msg.Value)
+			if err != nil {
+				balance = "0"
+			}
+			from, to := toRaw(msg.Source), toRaw(msg.Destination)
+			tranType := types.TransTypeOut
+			if to == address {
+				tranType = types.TransTypeIn
+			}
+			txs = append(txs, types.Transaction{
+				ChainID:          p.chainID,
+				State:            state,
+				Height:           lt,
+				Hash:             it.Hash,
+				FromAddress:      from,
+				ToAddress:        to,
+				Balance:          balance,
+				Amount:           utils.DivideByDecimals(balance, int(nativeDecimals)),
+				GasUsed:          fee,
+				GasPrice:         "1",
+				Type:             types.TxTypeUnknown, // native transfer
+				CoinType:         types.CoinTypeNative,
+				TokenDisplayName: nativeSymbol,
+				Decimals:         nativeDecimals,
+				CreatedTime:      it.Now,
+				ModifiedTime:     it.Now,
+				TranType:         tranType,
+			})
+		}
+
+		if it.InMsg != nil && it.InMsg.Source != "" {
+			row(*it.InMsg)
+		}
+		for _, out := range it.OutMsgs {
+			if out.Destination != "" {
+				row(out)
+			}
+		}
+	}
+	return txs
+}
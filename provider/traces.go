@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const defaultTraceMaxCalls = 20
+
+// traceTransfer is one internal native transfer found in a trace.
+type traceTransfer struct {
+	height                int64
+	hash, from, to, value string
+}
+
+// traceInternals appends the internal native transfers of the queried
+// address that node traces reveal on the served chains listed in
+// internal_traces. A chain whose rows already hold internal transfers is
+// left alone, as its provider has an internal-transaction endpoint. Chains
+// without an RPC URL and failed traces are skipped with a warning: tracing
+// only ever adds rows.
+func traceInternals(params *types.TransactionQueryParams, served map[string]string, rows []types.Transaction) []types.Transaction {
+	log := logger.ForRequest(params.RequestID)
+	for _, tc := range config.Current().InternalTraces {
+		chain := strings.ToLower(tc.ChainName)
+		if _, ok := served[chain]; !ok {
+			continue
+		}
+		chainID, err := utils.ChainIDByName(chain)
+		if err != nil {
+			continue
+		}
+		url, ok := utils.ChainRPCURL(chain)
+		if !ok {
+			log.Warn().Str("chain_name", chain).Msg("No RPC URL for internal traces, skipping")
+			continue
+		}
+
+		var chainRows []types.Transaction
+		hasInternal := false
+		for _, tx := range rows {
+			if tx.ChainID != chainID {
+				continue
+			}
+			if tx.Type == types.TxTypeInternal || tx.CoinType == types.CoinTypeInternal {
+				hasInternal = true
+				break
+			}
+			chainRows = append(chainRows, tx)
+		}
+		if hasInternal || len(chainRows) == 0 {
+			continue
+		}
+
+		maxCalls := tc.MaxCalls
+		if maxCalls <= 0 {
+			maxCalls = defaultTraceMaxCalls
+		}
+		var transfers []traceTransfer
+		if tc.Method == types.TraceMethodBlock {
+			transfers = traceBlocks(url, chainRows, maxCalls, log)
+		} else {
+			transfers = traceTransactions(url, chainRows, maxCalls, log)
+		}
+		traced := internalRows(chainID, params.Address, transfers, chainRows)
+		log.Info().
+			Str("chain_name", chain).
+			Int("internal", len(traced)).
+			Msg("Internal transfers derived from traces")
+		rows = append(rows, traced...)
+	}
+	return rows
+}
+
+// traceTransactions traces the newest maxCalls distinct transactions of rows
+// with debug_traceTransaction.
+func traceTransactions(url string, rows []types.Transaction, maxCalls int, log *zerolog.Logger) []traceTransfer {
+	byHeight := append([]types.Transaction(nil), rows...)
+	sort.SliceStable(byHeight, func(i, j int) bool { return byHeight[i].Height > byHeight[j].Height })
+
+	var transfers []traceTransfer
+	seen := make(map[string]bool)
+	for _, tx := range byHeight {
+		hash := strings.ToLower(tx.Hash)
+		if seen[hash] || hash == "" {
+			continue
+		}
+		if len(seen) == maxCalls {
+			break
+		}
+		seen[hash] = true
+
+		frame, err := utils.RPCTraceTransaction("trace.debug", url, hash)
+		if err != nil {
+			log.Warn().Err(err).Str("hash", hash).Msg("Failed to trace transaction")
+			continue
+		}
+		if frame.Error != "" {
+			continue // reverted: nothing was transferred
+		}
+		transfers = appendCallFrames(transfers, tx.Height, hash, frame.Calls)
+	}
+	return transfers
+}
+
+// appendCallFrames appends the value transfers of frames and of the calls
+// they made, skipping reverted subtrees and calls that cannot move value.
+func appendCallFrames(transfers []traceTransfer, height int64, hash string, frames []types.RpcCallFrame) []traceTransfer {
+	for _, f := range frames {
+		if f.Error != "" {
+			continue
+		}
+		switch strings.ToUpper(f.Type) {
+		case "DELEGATECALL", "STATICCALL":
+		default:
+			transfers = append(transfers, traceTransfer{height, hash, f.From, f.To, f.Value})
+		}
+		transfers = appendCallFrames(transfers, height, hash, f.Calls)
+	}
+	return transfers
+}
+
+// traceBlocks traces the newest maxCalls distinct blocks of rows with
+// trace_block, which also finds transfers to the address made by
+// transactions the providers did not return.
+func traceBlocks(url string, rows []types.Transaction, maxCalls int, log *zerolog.Logger) []traceTransfer {
+	var heights []int64
+	seen := make(map[int64]bool)
+	for _, tx := range rows {
+		if tx.Height > 0 && !seen[tx.Height] {
+			seen[tx.Height] = true
+			heights = append(heights, tx.Height)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	if len(heights) > maxCalls {
+		heights = heights[:maxCalls]
+	}
+
+	var transfers []traceTransfer
+	for _, height := range heights {
+		traces, err := utils.RPCTraceBlock("trace.block", url, height)
+		if err != nil {
+			log.Warn().Err(err).Int64("height", height).Msg("Failed to trace block")
+			continue
+		}
+		transfers = append(transfers, blockTransfers(traces)...)
+	}
+	return transfers
+}
+
+// blockTransfers returns the internal value transfers of a trace_block
+// result. Traces come parent first, so a reverted call is known before the
+// calls it made, which are reverted with it.
+func blockTransfers(traces []types.RpcTrace) []traceTransfer {
+	var transfers []traceTransfer
+	reverted := make(map[string][]string) // tx hash -> trace addresses of failed calls
+	for _, tr := range traces {
+		if tr.TransactionHash == "" {
+			continue // block and uncle rewards
+		}
+		hash := strings.ToLower(tr.TransactionHash)
+		addr := traceAddress(tr.TraceAddress)
+		if tr.Error != "" {
+			reverted[hash] = append(reverted[hash], addr)
+			continue
+		}
+		if len(tr.TraceAddress) == 0 || revertedWith(reverted[hash], addr) {
+			continue
+		}
+
+		a := tr.Action
+		switch tr.Type {
+		case "call":
+			if a.CallType == "delegatecall" || a.CallType == "staticcall" {
+				continue
+			}
+			transfers = append(transfers, traceTransfer{tr.BlockNumber, hash, a.From, a.To, a.Value})
+		case "create":
+			if tr.Result != nil {
+				transfers = append(transfers, traceTransfer{tr.BlockNumber, hash, a.From, tr.Result.Address, a.Value})
+			}
+		case "suicide":
+			transfers = append(transfers, traceTransfer{tr.BlockNumber, hash, a.Address, a.RefundAddress, a.Balance})
+		}
+	}
+	return transfers
+}
+
+// traceAddress formats a trace address as "0.1.", so that the addresses of
+// the calls made by a call all start with its own.
+func traceAddress(path []int) string {
+	var b strings.Builder
+	for _, i := range path {
+		b.WriteString(strconv.Itoa(i))
+		b.WriteByte('.')
+	}
+	return b.String()
+}
+
+// revertedWith reports whether addr is one of the failed calls or below one.
+func revertedWith(failed []string, addr string) bool {
+	for _, f := range failed {
+		if strings.HasPrefix(addr, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// internalRows converts the transfers with a value that involve address
+// into internal transactions, dated from the rows of the same transaction
+// or, for trace_block, of the same block.
+func internalRows(chainID int64, address string, transfers []traceTransfer, rows []types.Transaction) []types.Transaction {
+	byHash := make(map[string]types.Transaction, len(rows))
+	byHeight := make(map[int64]types.Transaction, len(rows))
+	for _, tx := range rows {
+		byHash[strings.ToLower(tx.Hash)] = tx
+		byHeight[tx.Height] = tx
+	}
+	decimals := utils.NativeDecimalsByChainID(chainID, types.NativeDefaultDecimals)
+
+	var out []types.Transaction
+	for _, t := range transfers {
+		from, to := strings.ToLower(t.from), strings.ToLower(t.to)
+		if !strings.EqualFold(from, address) && !strings.EqualFold(to, address) {
+			continue
+		}
+		valueRaw, err := utils.NormalizeNumericString(t.value)
+		if err != nil || valueRaw == "0" {
+			continue
+		}
+		outer, ok := byHash[t.hash]
+		if !ok {
+			outer, ok = byHeight[t.height]
+		}
+		if !ok {
+			continue
+		}
+
+		tranType := types.TransTypeOut
+		if strings.EqualFold(to, address) {
+			tranType = types.TransTypeIn
+		}
+		out = append(out, types.Transaction{
+			ChainID:      chainID,
+			State:        types.TxStateSuccess,
+			Height:       outer.Height,
+			BlockHash:    outer.BlockHash,
+			Hash:         t.hash,
+			FromAddress:  from,
+			ToAddress:    to,
+			Balance:      valueRaw,
+			Amount:       utils.DivideByDecimals(valueRaw, int(decimals)),
+			Type:         types.TxTypeInternal,
+			CoinType:     types.CoinTypeInternal,
+			Decimals:     decimals,
+			CreatedTime:  outer.CreatedTime,
+			ModifiedTime: outer.CreatedTime,
+			TranType:     tranType,
+		})
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+// Package tron serves Tron networks through TronGrid: TRX transfers and
+// contract calls from /v1/accounts/{address}/transactions and TRC-20
+// transfers / approvals from /v1/accounts/{address}/transactions/trc20.
+// Addresses are returned in base58 ("T…") form; TRX has 6 decimals.
+package tron
+
+import (
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*TronProvider)(nil)
+
+const (
+	defaultURL = "https://api.trongrid.io"
+	// apiKeyHeader carries the TronGrid API key.
+	apiKeyHeader = "TRON-PRO-API-KEY"
+	// defaultPageSize is TronGrid's maximum limit per page.
+	defaultPageSize = 200
+	// defaultMaxPages caps fingerprint pagination when max_pages is unset.
+	defaultMaxPages = 10
+	// trxDecimals is the number of decimals of TRX (1 TRX = 1e6 sun).
+	trxDecimals = 6
+)
+
+// TronProvider serves one Tron network.
+type TronProvider struct {
+	chainID int64
+	cfg     types.TronConfig
+}
+
+// NewTronProvider constructs a provider for one Tron network.
+func NewTronProvider(chainID int64, cfg types.TronConfig) *TronProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.RequestPageSize <= 0 || cfg.RequestPageSize > defaultPageSize {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Msg("Initializing TronProvider")
+
+	return &TronProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions fetches TRX transactions and TRC-20 events concurrently,
+// patches fee and state into the TRC-20 rows and returns them together.
+func (p *TronProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := params.Address
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from TronGrid")
+
+	var normalTxs, tokenTxs []types.Transaction
+
+	g := new(errgroup.Group)
+
+	// 1. TRX transfers and contract calls
+	g.Go(func() error {
+		items, err := p.fetchTransactions(params.RequestID, address, maxPages)
+		if err != nil {
+			return err
+		}
+		normalTxs = p.transformTransactions(items, address)
+		return nil
+	})
+
+	// 2. TRC-20 transfers and approvals
+	g.Go(func() error {
+		items, err := p.fetchTRC20(params.RequestID, address, maxPages)
+		if err != nil {
+			return err
+		}
+		tokenTxs = p.transformTRC20(items, address)
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Msg("TronGrid fetch failed")
+		return nil, err
+	}
+
+	tokenTxs = utils.PatchTokenTransactionsWithNormalTxInfo(tokenTxs, normalTxs)
+	all := append(normalTxs, tokenTxs...)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("normal", len(normalTxs)).
+		Int("token", len(tokenTxs)).
+		Int("total", len(all)).
+		Msg("Tron provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchAllPages follows TronGrid's fingerprint cursor until it is empty or
+// maxPages is reached. A failure on a later page keeps the items collected
+// so far.
+func fetchAllPages[T any](p *TronProvider, maxPages int64, label string, fetchPage func(fingerprint string) ([]T, string, error)) ([]T, error) {
+	var (
+		all         []T
+		fingerprint string
+	)
+	for page := int64(0); page < maxPages; page++ {
+		items, next, err := fetchPage(fingerprint)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", label).
+				Int64("page", page+1).
+				Msg("TronGrid pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if next == "" {
+			break
+		}
+		fingerprint = next
+	}
+	return all, nil
+}
+
+// sendRequest performs a GET against TronGrid with the API key header. Extra options,
+// such as the request ID, are passed through.
+func (p *TronProvider) sendRequest(label, url string, out interface{}, opts ...utils.RequestOption) error {
+	headers := map[string]string{}
+	if p.cfg.APIKey != "" {
+		headers[apiKeyHeader] = p.cfg.APIKey
+	}
+	opts = append([]utils.RequestOption{utils.WithProviderKey(provider.Key("tron", p.cfg.ChainName))}, opts...)
+	return utils.DoHttpRequestWithLogging("GET", label, url, nil, headers, out, opts...)
+}
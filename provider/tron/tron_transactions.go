@@ -0,0 +1,118 @@
+package tron
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTransactions reads all pages of confirmed transactions of address.
+func (p *TronProvider) fetchTransactions(requestID, address string, maxPages int64) ([]types.TronTransaction, error) {
+	return fetchAllPages(p, maxPages, "transactions", func(fingerprint string) ([]types.TronTransaction, string, error) {
+		q := url.Values{
+			"only_confirmed": {"true"},
+			"limit":          {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+		}
+		if fingerprint != "" {
+			q.Set("fingerprint", fingerprint)
+		}
+		u := fmt.Sprintf("%s/v1/accounts/%s/transactions?%s", p.cfg.URL, address, q.Encode())
+
+		var out types.TronTransactionsResponse
+		if err := p.sendRequest("tron.transactions", u, &out, utils.WithRequestID(requestID)); err != nil {
+			return nil, "", err
+		}
+		if !out.Success {
+			logger.Log.Error().
+				Str("error_message", out.Error).
+				Str("chain", p.cfg.ChainName).
+				Msg("TronGrid returned an error in transactions response")
+			return nil, "", errors.New("trongrid error: " + out.Error)
+		}
+		return out.Data, out.Meta.Fingerprint, nil
+	})
+}
+
+// transformTransactions converts TRX transfers and contract calls into
+// types.Transaction. Other contract types (staking, voting, TRC-10) are
+// skipped. The fee in sun is reported as gasUsed with gasPrice 1.
+func (p *TronProvider) transformTransactions(items []types.TronTransaction, address string) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, trxDecimals)
+
+	var txs []types.Transaction
+	for _, it := range items {
+		if len(it.RawData.Contract) == 0 {
+			continue
+		}
+		contract := it.RawData.Contract[0]
+		if contract.Type != types.TronContractTransfer && contract.Type != types.TronContractTrigger {
+			continue
+		}
+		var v types.TronContractValue
+		if err := json.Unmarshal(contract.Parameter.Value, &v); err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+
+		from := utils.TronHexToBase58(v.OwnerAddress)
+		to := utils.TronHexToBase58(v.ToAddress)
+		value := v.Amount
+		if contract.Type == types.TronContractTrigger {
+			to = utils.TronHexToBase58(v.ContractAddress)
+			value = v.CallValue
+		}
+
+		state := types.TxStateFail
+		var fee int64
+		if len(it.Ret) > 0 {
+			if it.Ret[0].ContractRet == types.TronContractRetSuccess {
+				state = types.TxStateSuccess
+			}
+			fee = it.Ret[0].Fee
+		}
+
+		tranType := types.TransTypeOut
+		if strings.EqualFold(to, address) {
+			tranType = types.TransTypeIn
+		}
+		unixTime := it.BlockTimestamp / 1000
+		balance := strconv.FormatInt(value, 10)
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            state,
+			Height:           it.BlockNumber,
+			Hash:             it.TxID,
+			FromAddress:      from,
+			ToAddress:        to,
+			Balance:          balance,
+			Amount:           utils.DivideByDecimals(balance, int(nativeDecimals)),
+			GasUsed:          strconv.FormatInt(fee, 10),
+			GasPrice:         "1",
+			Type:             types.TxTypeUnknown, // native transfer
+			CoinType:         types.CoinTypeNative,
+			TokenDisplayName: nativeSymbol,
+			Decimals:         nativeDecimals,
+			CreatedTime:      unixTime,
+			ModifiedTime:     unixTime,
+			TranType:         tranType,
+		})
+	}
+	return txs
+}
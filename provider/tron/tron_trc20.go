@@ -0,0 +1,85 @@
+package tron
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTRC20 reads all pages of confirmed TRC-20 events of address.
+func (p *TronProvider) fetchTRC20(requestID, address string, maxPages int64) ([]types.TronTRC20Tx, error) {
+	return fetchAllPages(p, maxPages, "trc20", func(fingerprint string) ([]types.TronTRC20Tx, string, error) {
+		q := url.Values{
+			"only_confirmed": {"true"},
+			"limit":          {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+		}
+		if fingerprint != "" {
+			q.Set("fingerprint", fingerprint)
+		}
+		u := fmt.Sprintf("%s/v1/accounts/%s/transactions/trc20?%s", p.cfg.URL, address, q.Encode())
+
+		var out types.TronTRC20Response
+		if err := p.sendRequest("tron.trc20", u, &out, utils.WithRequestID(requestID)); err != nil {
+			return nil, "", err
+		}
+		if !out.Success {
+			logger.Log.Error().
+				Str("error_message", out.Error).
+				Str("chain", p.cfg.ChainName).
+				Msg("TronGrid returned an error in trc20 response")
+			return nil, "", errors.New("trongrid error: " + out.Error)
+		}
+		return out.Data, out.Meta.Fingerprint, nil
+	})
+}
+
+// transformTRC20 converts TRC-20 events into types.Transaction. Approvals
+// become TxTypeApprove rows carrying the allowance in ApproveShow. State and
+// fee are patched in later from the parent transaction when it is known.
+func (p *TronProvider) transformTRC20(items []types.TronTRC20Tx, address string) []types.Transaction {
+	var txs []types.Transaction
+	for _, it := range items {
+		raw, err := utils.NormalizeNumericString(it.Value)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+
+		tranType := types.TransTypeOut
+		if strings.EqualFold(it.To, address) {
+			tranType = types.TransTypeIn
+		}
+		unixTime := it.BlockTimestamp / 1000
+
+		tx := types.Transaction{
+			ChainID:          p.chainID,
+			State:            types.TxStateSuccess,
+			Hash:             it.TransactionID,
+			FromAddress:      it.From,
+			ToAddress:        it.To,
+			TokenAddress:     it.TokenInfo.Address,
+			Balance:          raw,
+			Amount:           utils.DivideByDecimals(raw, int(it.TokenInfo.Decimals)),
+			Type:             types.TxTypeTransfer,
+			CoinType:         types.CoinTypeToken,
+			TokenDisplayName: it.TokenInfo.Symbol,
+			Decimals:         it.TokenInfo.Decimals,
+			CreatedTime:      unixTime,
+			ModifiedTime:     unixTime,
+			TranType:         tranType,
+		}
+		if it.Type == "Approval" {
+			tx.Type = types.TxTypeApprove
+			tx.ApproveShow = tx.Amount
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
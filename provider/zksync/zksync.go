@@ -0,0 +1,141 @@
+// Package zksync serves zkSync Era through its block explorer API, which
+// pages differently from Blockscout / Etherscan and reports the fee actually
+// paid (L2 execution plus L1 pubdata, after refunds) instead of a gas price
+// that multiplies out. Native rows come from /transactions, token rows and
+// internal ETH movements from /address/{address}/transfers.
+package zksync
+
+import (
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// Make sure we satisfy the common Provider interface.
+var _ provider.Provider = (*ZkSyncProvider)(nil)
+
+const (
+	defaultURL = "https://block-explorer-api.mainnet.zksync.io"
+	// defaultPageSize is used when request_page_size is unset; it is also
+	// the API maximum.
+	defaultPageSize = 100
+	// defaultMaxPages caps pagination when max_pages is unset.
+	defaultMaxPages = 10
+	// nativeTokenAddress is the L2 system contract that represents ETH in
+	// transfers.
+	nativeTokenAddress = "0x000000000000000000000000000000000000800a"
+)
+
+// ZkSyncProvider serves one zkSync Era network.
+type ZkSyncProvider struct {
+	chainID int64
+	cfg     types.ZkSyncConfig
+}
+
+// NewZkSyncProvider constructs a provider for one zkSync Era network.
+func NewZkSyncProvider(chainID int64, cfg types.ZkSyncConfig) *ZkSyncProvider {
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.RequestPageSize <= 0 || cfg.RequestPageSize > defaultPageSize {
+		cfg.RequestPageSize = defaultPageSize
+	}
+	logger.Log.Info().
+		Str("url", cfg.URL).
+		Str("chain", cfg.ChainName).
+		Msg("Initializing ZkSyncProvider")
+
+	return &ZkSyncProvider{
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// GetTransactions fetches transactions and transfers concurrently, patches
+// fee and state into the token rows and returns them together.
+func (p *ZkSyncProvider) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	address := strings.ToLower(params.Address)
+	maxPages := provider.MaxPages(params, p.cfg.MaxPages, defaultMaxPages)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Str("address", address).
+		Msg("Fetching transactions from zkSync explorer API")
+
+	var (
+		txItems       []types.ZkSyncTransaction
+		transferItems []types.ZkSyncTransfer
+	)
+
+	g := new(errgroup.Group)
+
+	// 1. Transactions sent or received by the address
+	g.Go(func() error {
+		items, err := p.fetchTransactions(params.RequestID, address, maxPages)
+		txItems = items
+		return err
+	})
+
+	// 2. ETH and token transfers involving the address
+	g.Go(func() error {
+		items, err := p.fetchTransfers(params.RequestID, address, maxPages)
+		transferItems = items
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Log.Error().Err(err).Msg("zkSync fetch failed")
+		return nil, err
+	}
+
+	normalTxs := p.transformTransactions(txItems, address)
+	tokenTxs, internalTxs := p.transformTransfers(transferItems, txItems, address)
+	tokenTxs = utils.PatchTokenTransactionsWithNormalTxInfo(tokenTxs, normalTxs)
+	all := append(append(normalTxs, tokenTxs...), internalTxs...)
+
+	logger.Log.Info().
+		Str("provider", p.cfg.ChainName).
+		Int("normal", len(normalTxs)).
+		Int("token", len(tokenTxs)).
+		Int("internal", len(internalTxs)).
+		Int("total", len(all)).
+		Msg("zkSync provider finished")
+
+	return &types.TransactionResponse{
+		Result: struct {
+			Transactions []types.Transaction `json:"transactions"`
+		}{Transactions: all},
+	}, nil
+}
+
+// fetchAllPages reads 1-based pages until the last page reported by the
+// API, a short page or maxPages is reached. A failure on a later page keeps
+// the items collected so far.
+func fetchAllPages[T any](p *ZkSyncProvider, maxPages int64, label string, fetchPage func(page int64) ([]T, types.ZkSyncMeta, error)) ([]T, error) {
+	var all []T
+	for page := int64(1); page <= maxPages; page++ {
+		items, meta, err := fetchPage(page)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			logger.Log.Warn().
+				Err(err).
+				Str("chain", p.cfg.ChainName).
+				Str("endpoint", label).
+				Int64("page", page).
+				Msg("zkSync pagination aborted, keeping earlier pages")
+			break
+		}
+		all = append(all, items...)
+		if page >= meta.TotalPages || int64(len(items)) < p.cfg.RequestPageSize {
+			break
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,101 @@
+package zksync
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/provider"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTransactions reads all pages of transactions of address, newest first.
+func (p *ZkSyncProvider) fetchTransactions(requestID, address string, maxPages int64) ([]types.ZkSyncTransaction, error) {
+	return fetchAllPages(p, maxPages, "transactions", func(page int64) ([]types.ZkSyncTransaction, types.ZkSyncMeta, error) {
+		q := url.Values{
+			"address": {address},
+			"page":    {strconv.FormatInt(page, 10)},
+			"limit":   {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+		}
+		u := fmt.Sprintf("%s/transactions?%s", p.cfg.URL, q.Encode())
+
+		var out types.ZkSyncTransactionsResponse
+		if err := utils.DoHttpRequestWithLogging("GET", "zksync.transactions", u, nil, nil, &out,
+			utils.WithProviderKey(provider.Key("zksync", p.cfg.ChainName)), utils.WithRequestID(requestID)); err != nil {
+			return nil, types.ZkSyncMeta{}, err
+		}
+		return out.Items, out.Meta, nil
+	})
+}
+
+// transformTransactions converts transactions into native rows. The fee
+// actually paid is reported as gasUsed with gasPrice 1, as for other chains
+// whose fee is not gasUsed × gasPrice.
+func (p *ZkSyncProvider) transformTransactions(items []types.ZkSyncTransaction, address string) []types.Transaction {
+	nativeSymbol, err := utils.NativeTokenByChainID(p.chainID)
+	if err != nil {
+		logger.Log.Error().
+			Err(err).
+			Int64("chain_id", p.chainID).
+			Msg("Failed to get native token name")
+	}
+
+	nativeDecimals := utils.NativeDecimalsByChainID(p.chainID, types.NativeDefaultDecimals)
+
+	txs := make([]types.Transaction, 0, len(items))
+	for _, it := range items {
+		value, err := utils.NormalizeNumericString(it.Value)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+		fee, err := utils.NormalizeNumericString(it.Fee)
+		if err != nil {
+			fee = "0"
+		}
+		gasLimit, err := utils.NormalizeNumericString(it.GasLimit)
+		if err != nil {
+			gasLimit = ""
+		}
+
+		from, to := strings.ToLower(it.From), strings.ToLower(it.To)
+		tranType := types.TransTypeIn
+		if from == address {
+			tranType = types.TransTypeOut
+		}
+		state := types.TxStateSuccess
+		if it.Status == "failed" {
+			state = types.TxStateFail
+		}
+		unixTime := utils.ParseBlockscoutTimestampToUnix(it.ReceivedAt)
+
+		txs = append(txs, types.Transaction{
+			ChainID:          p.chainID,
+			State:            state,
+			Height:           it.BlockNumber,
+			Hash:             it.Hash,
+			TxIndex:          it.TransactionIndex,
+			BlockHash:        it.BlockHash,
+			FromAddress:      from,
+			ToAddress:        to,
+			Balance:          value,
+			Amount:           utils.DivideByDecimals(value, int(nativeDecimals)),
+			GasUsed:          fee,
+			GasLimit:         gasLimit,
+			GasPrice:         "1",
+			Nonce:            strconv.FormatInt(it.Nonce, 10),
+			Type:             types.TxTypeUnknown, // native transfer
+			CoinType:         types.CoinTypeNative,
+			TokenDisplayName: nativeSymbol,
+			Decimals:         nativeDecimals,
+			CreatedTime:      unixTime,
+			ModifiedTime:     unixTime,
+			TranType:         tranType,
+		})
+	}
+	return txs
+}
@@ -0,0 +1,100 @@
+package zksync
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/provider"
+	"tx-aggregator/softjson"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// fetchTransfers reads all pages of transfers involving address, newest first.
+func (p *ZkSyncProvider) fetchTransfers(requestID, address string, maxPages int64) ([]types.ZkSyncTransfer, error) {
+	return fetchAllPages(p, maxPages, "transfers", func(page int64) ([]types.ZkSyncTransfer, types.ZkSyncMeta, error) {
+		q := url.Values{
+			"page":  {strconv.FormatInt(page, 10)},
+			"limit": {strconv.FormatInt(p.cfg.RequestPageSize, 10)},
+		}
+		u := fmt.Sprintf("%s/address/%s/transfers?%s", p.cfg.URL, address, q.Encode())
+
+		var out types.ZkSyncTransfersResponse
+		if err := utils.DoHttpRequestWithLogging("GET", "zksync.transfers", u, nil, nil, &out,
+			utils.WithProviderKey(provider.Key("zksync", p.cfg.ChainName)), utils.WithRequestID(requestID)); err != nil {
+			return nil, types.ZkSyncMeta{}, err
+		}
+		return out.Items, out.Meta, nil
+	})
+}
+
+// transformTransfers converts transfers into token rows (ERC-20) and
+// internal rows (ETH movements other than the top-level value of a
+// transaction already returned by /transactions). Fee and refund legs,
+// already counted in the transaction fee, and NFTs are skipped.
+func (p *ZkSyncProvider) transformTransfers(items []types.ZkSyncTransfer, parents []types.ZkSyncTransaction, address string) (tokenTxs, internalTxs []types.Transaction) {
+	topLevel := make(map[string]bool, len(parents))
+	for _, tx := range parents {
+		topLevel[transferKey(tx.Hash, tx.From, tx.To, tx.Value)] = true
+	}
+
+	for _, it := range items {
+		if it.Type == "fee" || it.Type == "refund" || it.TokenType == "ERC721" {
+			continue
+		}
+		amount, err := utils.NormalizeNumericString(it.Amount)
+		if err != nil {
+			softjson.SkipItem(it, err)
+			continue
+		}
+
+		from, to := strings.ToLower(it.From), strings.ToLower(it.To)
+		tranType := types.TransTypeIn
+		if from == address {
+			tranType = types.TransTypeOut
+		}
+		unixTime := utils.ParseBlockscoutTimestampToUnix(it.Timestamp)
+
+		tx := types.Transaction{
+			ChainID:          p.chainID,
+			State:            types.TxStateSuccess,
+			Height:           it.BlockNumber,
+			Hash:             it.TransactionHash,
+			FromAddress:      from,
+			ToAddress:        to,
+			Balance:          amount,
+			Amount:           utils.DivideByDecimals(amount, int(it.Token.Decimals)),
+			TokenDisplayName: it.Token.Symbol,
+			Decimals:         it.Token.Decimals,
+			CreatedTime:      unixTime,
+			ModifiedTime:     unixTime,
+			TranType:         tranType,
+			IconURL:          it.Token.IconURL,
+		}
+
+		if strings.EqualFold(it.TokenAddress, nativeTokenAddress) {
+			if topLevel[transferKey(it.TransactionHash, it.From, it.To, amount)] {
+				continue // same movement as the transaction row
+			}
+			tx.Type = types.TxTypeInternal
+			tx.CoinType = types.CoinTypeNative
+			internalTxs = append(internalTxs, tx)
+			continue
+		}
+		tx.Type = types.TxTypeTransfer
+		tx.CoinType = types.CoinTypeToken
+		tx.TokenAddress = strings.ToLower(it.TokenAddress)
+		tokenTxs = append(tokenTxs, tx)
+	}
+	return tokenTxs, internalTxs
+}
+
+// transferKey identifies an ETH movement within a transaction.
+func transferKey(hash, from, to, value string) string {
+	if v, err := utils.NormalizeNumericString(value); err == nil {
+		value = v
+	}
+	return strings.ToLower(hash + "|" + from + "|" + to + "|" + value)
+}
@@ -0,0 +1,71 @@
+package providertest
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+)
+
+// rpcRequest is the part of an Ankr JSON-RPC request the server looks at.
+type rpcRequest struct {
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+	ID     int                    `json:"id"`
+}
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewAnkrServer emulates the Ankr Advanced API. Use its URL as ankr.url; any
+// path (such as the API key) is accepted. A request for method is answered
+// with ankr/<method>.json as the result, or ankr/<method>.<pageToken>.json
+// for later pages. Methods without a fixture get a JSON-RPC error.
+func NewAnkrServer(fixtures fs.FS) *Server {
+	return newServer(fixtures, "ankr", func(s *Server, w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"jsonrpc": "2.0",
+				"error":   rpcError{Code: -32700, Message: "parse error"},
+			})
+			return
+		}
+		s.record(req.Method)
+
+		name := req.Method + ".json"
+		if token, _ := req.Params["pageToken"].(string); token != "" {
+			name = req.Method + "." + token + ".json"
+		}
+		result, ok := s.fixture(name, ankrAddress(req.Params["address"]))
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      req.ID,
+				"error":   rpcError{Code: -32601, Message: "no fixture " + name},
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  json.RawMessage(result),
+		})
+	})
+}
+
+// ankrAddress returns the queried address, given as a string or a list.
+func ankrAddress(v interface{}) string {
+	switch a := v.(type) {
+	case string:
+		return a
+	case []interface{}:
+		if len(a) > 0 {
+			s, _ := a[0].(string)
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package providertest
+
+import (
+	"io/fs"
+	"net/http"
+	"strconv"
+)
+
+// NewBlockscanServer emulates an Etherscan-compatible API. Use its URL as
+// the url of a blockscan entry. A request is answered with
+// blockscan/<module>.<action>.json, or blockscan/<module>.<action>.<page>.json
+// for pages after the first. Account lists without a fixture get the
+// "No transactions found" answer that ends pagination.
+func NewBlockscanServer(fixtures fs.FS) *Server {
+	return newServer(fixtures, "blockscan", func(s *Server, w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		module, action := q.Get("module"), q.Get("action")
+
+		name := module + "." + action + ".json"
+		if page, _ := strconv.Atoi(q.Get("page")); page > 1 {
+			name = module + "." + action + "." + strconv.Itoa(page) + ".json"
+		}
+		s.record(name)
+
+		if body, ok := s.fixture(name, q.Get("address")); ok {
+			writeJSON(w, http.StatusOK, body)
+			return
+		}
+		if module == "account" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"status":  "0",
+				"message": "No transactions found",
+				"result":  []interface{}{},
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"status":  "0",
+			"message": "NOTOK",
+			"result":  "Error! Missing Or invalid Action name",
+		})
+	})
+}
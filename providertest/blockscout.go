@@ -0,0 +1,36 @@
+package providertest
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// NewBlockscoutServer emulates the Blockscout v2 REST API. Use its URL as
+// the url of a blockscout entry. GET .../addresses/<address>/<list> is
+// answered with blockscout/<list>.json (transactions, token-transfers,
+// internal-transactions, logs) and GET .../main-page/blocks with
+// blockscout/main-page-blocks.json. Other paths, and lists without a
+// fixture, get a 404.
+func NewBlockscoutServer(fixtures fs.FS) *Server {
+	return newServer(fixtures, "blockscout", func(s *Server, w http.ResponseWriter, r *http.Request) {
+		var name, address string
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/main-page/blocks"):
+			name = "main-page-blocks.json"
+		case strings.Contains(r.URL.Path, "/addresses/"):
+			_, rest, _ := strings.Cut(r.URL.Path, "/addresses/")
+			var list string
+			address, list, _ = strings.Cut(rest, "/")
+			name = list + ".json"
+		}
+		s.record(name)
+
+		body, ok := s.fixture(name, address)
+		if name == "" || !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, body)
+	})
+}
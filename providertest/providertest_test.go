@@ -0,0 +1,90 @@
+package providertest
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/provider"
+	"tx-aggregator/provider/ankr"
+	"tx-aggregator/provider/blockscan"
+	"tx-aggregator/provider/blockscout"
+	"tx-aggregator/types"
+)
+
+const testAddress = "0x00000000000000000000000000000000000000aa"
+
+func withTestConfig(t *testing.T) {
+	orig := config.Current()
+	config.SetCurrentConfig(types.Config{
+		ChainNames:   map[string]int64{"ETH": 1},
+		NativeTokens: map[string]string{"ETH": "ETH"},
+		Ankr:         types.AnkrConfig{RequestPageSize: 100, ChainIDs: map[string]int64{"eth": 1}},
+	})
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+// summary reduces rows to hash / coin type / direction for comparison.
+func summary(t *testing.T, p provider.Provider) []string {
+	resp, err := p.GetTransactions(&types.TransactionQueryParams{Address: testAddress, ChainNames: []string{"ETH"}})
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	var out []string
+	for _, tx := range resp.Result.Transactions {
+		assert.Equal(t, int64(1), tx.ChainID)
+		out = append(out, fmt.Sprintf("%s/%d/%d", tx.Hash[:4], tx.CoinType, tx.TranType))
+	}
+	return out
+}
+
+func TestAnkrServer(t *testing.T) {
+	withTestConfig(t)
+	srv := NewAnkrServer(nil)
+	defer srv.Close()
+
+	rows := summary(t, ankr.NewAnkrProvider("test-key", srv.URL))
+	assert.ElementsMatch(t, []string{"0xaa/1/1", "0xbb/1/1", "0xbb/2/1"}, rows)
+	assert.Contains(t, srv.Requests(), "ankr_getTransactionsByAddress")
+	assert.Contains(t, srv.Requests(), "ankr_getTokenTransfers")
+}
+
+func TestBlockscoutServer(t *testing.T) {
+	withTestConfig(t)
+	srv := NewBlockscoutServer(nil)
+	defer srv.Close()
+
+	p := blockscout.NewBlockscoutProvider(1, types.BlockscoutConfig{URL: srv.URL + "/api/v2", ChainName: "ETH", RequestPageSize: 50})
+	rows := summary(t, p)
+	assert.ElementsMatch(t, []string{"0xaa/1/1", "0xbb/1/1", "0xbb/2/1", "0xcc/1/0"}, rows)
+	assert.NoError(t, p.HealthCheck())
+}
+
+func TestBlockscanServer(t *testing.T) {
+	withTestConfig(t)
+	srv := NewBlockscanServer(nil)
+	defer srv.Close()
+
+	p := blockscan.NewBlockscanProvider(1, types.BlockscanConfig{URL: srv.URL + "/api", ChainName: "ETH", RequestPageSize: 2, Sort: "desc"})
+	rows := summary(t, p)
+	assert.ElementsMatch(t, []string{"0xaa/1/1", "0xbb/1/1", "0xbb/2/1"}, rows)
+	assert.Contains(t, srv.Requests(), "account.txlist.2.json", "a full page asks for the next one")
+	assert.NoError(t, p.HealthCheck())
+}
+
+func TestMissingFixture(t *testing.T) {
+	withTestConfig(t)
+	srv := NewBlockscoutServer(emptyFS{})
+	defer srv.Close()
+
+	p := blockscout.NewBlockscoutProvider(1, types.BlockscoutConfig{URL: srv.URL, ChainName: "ETH"})
+	_, err := p.GetTransactions(&types.TransactionQueryParams{Address: testAddress})
+	assert.Error(t, err)
+}
+
+// emptyFS has no fixtures at all.
+type emptyFS struct{}
+
+func (emptyFS) Open(name string) (fs.File, error) { return nil, fs.ErrNotExist }
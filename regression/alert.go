@@ -0,0 +1,63 @@
+package regression
+
+import (
+	"fmt"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const defaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyEvent is a PagerDuty Events API v2 trigger.
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                  `json:"summary"`
+	Source        string                  `json:"source"`
+	Severity      string                  `json:"severity"`
+	CustomDetails types.RegressionFinding `json:"custom_details"`
+}
+
+// sendAlerts delivers f to every configured sink. It runs in the background
+// (see Monitor.Report); failures are logged and counted.
+func sendAlerts(cfg types.AlertConfig, f types.RegressionFinding) {
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	if cfg.WebhookURL != "" {
+		if err := utils.DoHttpRequestWithLogging("POST", "regression.webhook", cfg.WebhookURL, f, headers, nil); err != nil {
+			metrics.RegressionAlertsFailed.WithLabelValues("webhook").Inc()
+			logger.Log.Error().Err(err).Msg("Failed to send regression webhook")
+		}
+	}
+
+	if cfg.PagerDutyRoutingKey != "" {
+		url := cfg.PagerDutyURL
+		if url == "" {
+			url = defaultPagerDutyURL
+		}
+		event := pagerDutyEvent{
+			RoutingKey:  cfg.PagerDutyRoutingKey,
+			EventAction: "trigger",
+			DedupKey:    fmt.Sprintf("tx-aggregator/regression/%s/%s", f.ChainName, f.Address),
+			Payload: pagerDutyPayload{
+				Summary: fmt.Sprintf("tx-aggregator: %s on %s dropped from %d to %d transactions (%s)",
+					f.Address, f.ChainName, f.Expected, f.Actual, f.Source),
+				Source:        "tx-aggregator",
+				Severity:      "error",
+				CustomDetails: f,
+			},
+		}
+		if err := utils.DoHttpRequestWithLogging("POST", "regression.pagerduty", url, event, headers, nil); err != nil {
+			metrics.RegressionAlertsFailed.WithLabelValues("pagerduty").Inc()
+			logger.Log.Error().Err(err).Msg("Failed to send regression PagerDuty event")
+		}
+	}
+}
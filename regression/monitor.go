@@ -0,0 +1,166 @@
+// Package regression enforces the expected-count invariant on the server:
+// an address should never return fewer transactions on a chain than it did
+// before. Drops found by the scheduled monitor, a canary or the integration
+// tool are exported as metrics and sent to the configured alert sinks.
+package regression
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/provider"
+	"tx-aggregator/types"
+)
+
+const defaultIntervalMinutes = 15
+
+// Monitor tracks the highest transaction count seen per address and chain.
+type Monitor struct {
+	provider provider.Provider
+	alerts   sync.WaitGroup // alert deliveries in flight
+
+	mu       sync.Mutex
+	expected map[string]int // chain|address → highest count seen
+	active   map[string]int // chain|address → count last alerted, while regressed
+}
+
+// NewMonitor creates a Monitor that queries p (normally the MultiProvider,
+// bypassing the cache) for the configured targets.
+func NewMonitor(p provider.Provider) *Monitor {
+	return &Monitor{
+		provider: p,
+		expected: make(map[string]int),
+		active:   make(map[string]int),
+	}
+}
+
+// Start checks every target immediately and then every interval_minutes in
+// the background. It does nothing unless regression.enabled is set.
+func (m *Monitor) Start() {
+	cfg := config.Current().Regression
+	if !cfg.Enabled {
+		return
+	}
+	interval := cfg.IntervalMinutes
+	if interval <= 0 {
+		interval = defaultIntervalMinutes
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			m.Check()
+			<-ticker.C
+		}
+	}()
+	logger.Log.Info().Int("interval_minutes", interval).Int("targets", len(cfg.Targets)).Msg("Regression monitor scheduled")
+}
+
+// Check queries every configured target once and returns the regressions found.
+func (m *Monitor) Check() []types.RegressionFinding {
+	var findings []types.RegressionFinding
+	for _, target := range config.Current().Regression.Targets {
+		resp, err := m.provider.GetTransactions(&types.TransactionQueryParams{
+			Address:    strings.ToLower(target.Address),
+			ChainNames: []string{target.ChainName},
+		})
+		if err != nil {
+			// A failing provider is not a count drop; it is covered by provider metrics.
+			logger.Log.Warn().Err(err).Str("address", target.Address).Str("chain", target.ChainName).Msg("Regression check failed")
+			continue
+		}
+		if f := m.Observe(types.RegressionSourceMonitor, target.Address, target.ChainName, len(resp.Result.Transactions)); f != nil {
+			findings = append(findings, *f)
+		}
+	}
+	return findings
+}
+
+// Observe applies the expected-count invariant to a fresh count. It returns
+// a finding, already reported, when count is below the highest count seen
+// and the pair was not regressed yet (see Report); recovering pairs leave
+// the alert gauge.
+func (m *Monitor) Observe(source, address, chain string, count int) *types.RegressionFinding {
+	address, chain = strings.ToLower(address), strings.ToUpper(chain)
+	key := chain + "|" + address
+
+	m.mu.Lock()
+	expected, seen := m.expected[key]
+	if !seen || count >= expected {
+		m.expected[key] = count
+		_, wasActive := m.active[key]
+		delete(m.active, key)
+		m.mu.Unlock()
+		if wasActive {
+			metrics.RegressionActive.WithLabelValues(chain).Dec()
+			logger.Log.Info().Str("address", address).Str("chain", chain).Int("count", count).Msg("Regression recovered")
+		}
+		return nil
+	}
+	m.mu.Unlock()
+
+	f := types.RegressionFinding{
+		Source:       source,
+		Address:      address,
+		ChainName:    chain,
+		Expected:     expected,
+		Actual:       count,
+		DetectedTime: time.Now().Unix(),
+	}
+	if !m.Report(f) {
+		return nil // already alerted
+	}
+	return &f
+}
+
+// Report exports a regression found by any source and sends it to the
+// configured alert sinks in the background. A pair already regressed is
+// reported again only when its count dropped further; the monitor clears
+// it once the count recovers. It returns whether f was reported.
+func (m *Monitor) Report(f types.RegressionFinding) bool {
+	f.Address, f.ChainName = strings.ToLower(f.Address), strings.ToUpper(f.ChainName)
+	if f.DetectedTime == 0 {
+		f.DetectedTime = time.Now().Unix()
+	}
+	key := f.ChainName + "|" + f.Address
+
+	m.mu.Lock()
+	alerted, wasActive := m.active[key]
+	if wasActive && f.Actual >= alerted {
+		m.mu.Unlock()
+		return false
+	}
+	m.active[key] = f.Actual
+	if f.Expected > m.expected[key] {
+		// So that the monitor sees the pair recover once it is back there
+		m.expected[key] = f.Expected
+	}
+	m.mu.Unlock()
+
+	metrics.RegressionsDetected.WithLabelValues(f.Source, f.ChainName).Inc()
+	if !wasActive {
+		metrics.RegressionActive.WithLabelValues(f.ChainName).Inc()
+	}
+	logger.Log.Error().
+		Str("source", f.Source).
+		Str("address", f.Address).
+		Str("chain", f.ChainName).
+		Str("token_address", f.TokenAddress).
+		Int("expected", f.Expected).
+		Int("actual", f.Actual).
+		Msg("Transaction count regression detected")
+
+	alerts := config.Current().Regression.Alerts
+	m.alerts.Add(1)
+	go func() {
+		defer m.alerts.Done()
+		sendAlerts(alerts, f)
+	}()
+	return true
+}
@@ -0,0 +1,135 @@
+package regression
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+)
+
+// countProvider returns count empty transactions.
+type countProvider struct {
+	count int
+}
+
+func (p *countProvider) GetTransactions(*types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = make([]types.Transaction, p.count)
+	return resp, nil
+}
+
+func TestMonitor_DetectsDropOnceAndRecovers(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		webhooks []types.RegressionFinding
+		pdEvents []pagerDutyEvent
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f types.RegressionFinding
+		_ = json.NewDecoder(r.Body).Decode(&f)
+		mu.Lock()
+		webhooks = append(webhooks, f)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+	pagerDuty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e pagerDutyEvent
+		_ = json.NewDecoder(r.Body).Decode(&e)
+		mu.Lock()
+		pdEvents = append(pdEvents, e)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer pagerDuty.Close()
+
+	config.SetCurrentConfig(types.Config{Regression: types.RegressionConfig{
+		Targets: []types.RegressionTarget{{Address: "0xABC", ChainName: "eth"}},
+		Alerts: types.AlertConfig{
+			WebhookURL:          webhook.URL,
+			PagerDutyRoutingKey: "routing-key",
+			PagerDutyURL:        pagerDuty.URL,
+		},
+	}})
+	defer config.SetCurrentConfig(types.Config{})
+
+	p := &countProvider{count: 10}
+	m := NewMonitor(p)
+	active := metrics.RegressionActive.WithLabelValues("ETH")
+
+	assert.Empty(t, m.Check(), "first observation sets the baseline")
+
+	p.count = 7
+	findings := m.Check()
+	assert.Len(t, findings, 1)
+	assert.Equal(t, 10, findings[0].Expected)
+	assert.Equal(t, 7, findings[0].Actual)
+	assert.Equal(t, types.RegressionSourceMonitor, findings[0].Source)
+	assert.Equal(t, float64(1), testutil.ToFloat64(active))
+
+	assert.Empty(t, m.Check(), "an active regression is alerted only once")
+
+	p.count = 12
+	assert.Empty(t, m.Check())
+	assert.Equal(t, float64(0), testutil.ToFloat64(active))
+
+	m.alerts.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, webhooks, 1)
+	assert.Equal(t, "0xabc", webhooks[0].Address)
+	assert.Len(t, pdEvents, 1)
+	assert.Equal(t, "routing-key", pdEvents[0].RoutingKey)
+	assert.Equal(t, "tx-aggregator/regression/ETH/0xabc", pdEvents[0].DedupKey)
+}
+
+func TestMonitor_ReportCountsBySource(t *testing.T) {
+	config.SetCurrentConfig(types.Config{})
+	counter := metrics.RegressionsDetected.WithLabelValues(types.RegressionSourceIntegration, "BSC")
+	before := testutil.ToFloat64(counter)
+
+	NewMonitor(&countProvider{}).Report(types.RegressionFinding{
+		Source:    types.RegressionSourceIntegration,
+		Address:   "0xDEF",
+		ChainName: "bsc",
+		Expected:  5,
+		Actual:    1,
+	})
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestMonitor_ReportDedups(t *testing.T) {
+	var hooks atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hooks.Add(1) }))
+	defer webhook.Close()
+	config.SetCurrentConfig(types.Config{Regression: types.RegressionConfig{
+		Alerts: types.AlertConfig{WebhookURL: webhook.URL},
+	}})
+	defer config.SetCurrentConfig(types.Config{})
+
+	m := NewMonitor(&countProvider{})
+	active := metrics.RegressionActive.WithLabelValues("POLYGON")
+	drop := types.RegressionFinding{Source: types.RegressionSourceIntegration, Address: "0xABC", ChainName: "polygon", Expected: 5, Actual: 3}
+
+	assert.True(t, m.Report(drop))
+	assert.False(t, m.Report(drop), "the same drop is alerted once")
+	drop.Actual = 2
+	assert.True(t, m.Report(drop), "a further drop is alerted again")
+	assert.Equal(t, float64(1), testutil.ToFloat64(active), "one address regressed")
+
+	// The monitor clears the pair once its count is back to the reported one.
+	assert.Nil(t, m.Observe(types.RegressionSourceMonitor, "0xabc", "POLYGON", 5))
+	assert.Equal(t, float64(0), testutil.ToFloat64(active))
+	drop.Actual = 3
+	assert.True(t, m.Report(drop))
+
+	m.alerts.Wait()
+	assert.Equal(t, int32(3), hooks.Load())
+}
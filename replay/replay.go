@@ -0,0 +1,124 @@
+// Package replay re-normalizes stored history: it re-runs the current
+// normalization rules over the cached transactions of every address on a
+// chain and writes the result back, so that changes to them apply
+// retroactively without querying the providers again. Provider transforms,
+// which classify swaps, wraps and the like from raw payloads, are not re-run.
+package replay
+
+import (
+	"strings"
+	"time"
+
+	"tx-aggregator/interfaces"
+	"tx-aggregator/jobs"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+)
+
+const maxJobs = 50 // finished jobs kept in memory before eviction
+
+// Replayer runs replay jobs, at most one per chain at a time.
+type Replayer struct {
+	replayer interfaces.TransactionReplayerInterface
+	jobs     *jobs.Registry[types.ReplayJob] // keyed by chain name while running
+}
+
+// NewReplayer creates a Replayer over r (normally the usecase Service).
+func NewReplayer(r interfaces.TransactionReplayerInterface) *Replayer {
+	return &Replayer{
+		replayer: r,
+		jobs: jobs.NewRegistry(maxJobs, func(job *types.ReplayJob, now int64) {
+			job.UpdatedTime = now
+		}),
+	}
+}
+
+// Submit starts a replay of chainName in the background. If one is already
+// running for the chain, that job is returned instead of starting another.
+func (p *Replayer) Submit(chainName string) types.ReplayJob {
+	chainName = strings.ToUpper(chainName)
+
+	now := time.Now().Unix()
+	job, added := p.jobs.Add(chainName, func(id string) *types.ReplayJob {
+		return &types.ReplayJob{
+			ID:          id,
+			ChainName:   chainName,
+			Status:      types.ExportStatusPending,
+			CreatedTime: now,
+			UpdatedTime: now,
+		}
+	})
+	if !added {
+		return job
+	}
+
+	logger.Log.Info().
+		Str("job_id", job.ID).
+		Str("chain", chainName).
+		Msg("Replay submitted")
+
+	p.jobs.Go(job.ID, func() { p.run(job.ID, chainName) }, func(job *types.ReplayJob, err error) {
+		job.Status = types.ExportStatusFailed
+		job.Error = err.Error()
+	})
+	return job
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (p *Replayer) Get(id string) (types.ReplayJob, bool) {
+	return p.jobs.Get(id)
+}
+
+// run replays every cached address of chainName. A failing address is
+// counted and skipped; the job fails when the addresses cannot be listed or
+// every address failed.
+func (p *Replayer) run(id, chainName string) {
+	p.jobs.Update(id, func(job *types.ReplayJob) { job.Status = types.ExportStatusRunning })
+	start := time.Now()
+
+	addresses, err := p.replayer.CachedAddresses(chainName)
+	if err != nil {
+		logger.Log.Error().Err(err).Str("job_id", id).Str("chain", chainName).Msg("Replay failed to list cached addresses")
+		p.jobs.Release(chainName)
+		p.jobs.Update(id, func(job *types.ReplayJob) {
+			job.Status = types.ExportStatusFailed
+			job.Error = err.Error()
+		})
+		return
+	}
+	p.jobs.Update(id, func(job *types.ReplayJob) { job.Addresses = len(addresses) })
+
+	for _, address := range addresses {
+		kept, dropped, err := p.replayer.ReplayTransactions(&types.TransactionQueryParams{
+			Address:    address,
+			ChainNames: []string{chainName},
+		})
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("job_id", id).Str("address", address).Str("chain", chainName).Msg("Replay address failed")
+			p.jobs.Update(id, func(job *types.ReplayJob) { job.Failed++ })
+			continue
+		}
+		p.jobs.Update(id, func(job *types.ReplayJob) {
+			job.Done++
+			job.Kept += kept
+			job.Dropped += dropped
+		})
+	}
+
+	p.jobs.Release(chainName)
+	p.jobs.Update(id, func(job *types.ReplayJob) {
+		job.Status = types.ExportStatusDone
+		if job.Failed > 0 && job.Done == 0 {
+			job.Status = types.ExportStatusFailed
+		}
+		logger.Log.Info().
+			Str("job_id", id).
+			Str("chain", chainName).
+			Int("done", job.Done).
+			Int("failed", job.Failed).
+			Int("kept", job.Kept).
+			Int("dropped", job.Dropped).
+			Dur("cost", time.Since(start)).
+			Msg("Replay finished")
+	})
+}
@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+// stubReplayer serves a fixed address list and fails for addresses in fail.
+type stubReplayer struct {
+	mu        sync.Mutex
+	addresses []string
+	listErr   error
+	params    []types.TransactionQueryParams
+	fail      map[string]bool
+}
+
+func (s *stubReplayer) CachedAddresses(chainName string) ([]string, error) {
+	return s.addresses, s.listErr
+}
+
+func (s *stubReplayer) ReplayTransactions(params *types.TransactionQueryParams) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = append(s.params, *params)
+	if s.fail[params.Address] {
+		return 0, 0, errors.New("boom")
+	}
+	return 4, 1, nil
+}
+
+func waitForJob(t *testing.T, p *Replayer, id string) types.ReplayJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := p.Get(id)
+		assert.True(t, ok)
+		if job.Status == types.ExportStatusDone || job.Status == types.ExportStatusFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return types.ReplayJob{}
+}
+
+func TestReplayer_ReplaysCachedAddresses(t *testing.T) {
+	stub := &stubReplayer{
+		addresses: []string{"0xaa", "0xbb", "0xbad"},
+		fail:      map[string]bool{"0xbad": true},
+	}
+	p := NewReplayer(stub)
+	job := p.Submit("eth")
+	assert.Equal(t, "ETH", job.ChainName)
+
+	done := waitForJob(t, p, job.ID)
+	assert.Equal(t, types.ExportStatusDone, done.Status)
+	assert.Equal(t, 3, done.Addresses)
+	assert.Equal(t, 2, done.Done)
+	assert.Equal(t, 1, done.Failed)
+	assert.Equal(t, 8, done.Kept)
+	assert.Equal(t, 2, done.Dropped)
+	assert.Equal(t, []string{"ETH"}, stub.params[0].ChainNames)
+
+	again := p.Submit("ETH")
+	assert.NotEqual(t, job.ID, again.ID)
+	waitForJob(t, p, again.ID)
+}
+
+func TestReplayer_ListFailure(t *testing.T) {
+	p := NewReplayer(&stubReplayer{listErr: errors.New("redis down")})
+	job := p.Submit("ETH")
+
+	done := waitForJob(t, p, job.ID)
+	assert.Equal(t, types.ExportStatusFailed, done.Status)
+	assert.Equal(t, "redis down", done.Error)
+}
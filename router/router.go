@@ -3,18 +3,82 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 	"tx-aggregator/api"
+	"tx-aggregator/metrics"
+	"tx-aggregator/middleware"
+	"tx-aggregator/shadow"
+	"tx-aggregator/types"
 )
 
 // SetupRoutes configures all HTTP routes and associates them with their respective handlers.
+// Every request is first tagged with an X-Request-ID, access-logged and guarded against
+// panics. Every route except /health and /metrics goes through API key authentication;
+// each route group then requires a role:
+//   - read:   transaction data and read-only admin introspection
+//   - export: asynchronous export jobs
+//   - invalidate: dropping the cached transactions of an address
+//   - watch:  registering addresses to watch
+//   - admin:  mutating admin endpoints (admin implies every other role)
+//
 // Parameters:
 //   - app: Fiber application instance
 //   - txHandler: TransactionHandler to process transaction-related endpoints
-func SetupRoutes(app *fiber.App, txHandler *api.TransactionHandler) {
-	// Health check endpoint (useful for Docker, Kubernetes, load balancers, etc.)
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.SendString("ok")
-	})
+//   - portfolioHandler: PortfolioHandler to summarize addresses across chains
+//   - tokensHandler: TokensHandler to list the tokens seen for an address
+//   - allowanceHandler: AllowanceHandler to read live ERC-20 allowances
+//   - receiptHandler: ReceiptHandler to proxy transaction receipts
+//   - exportHandler: ExportHandler to process asynchronous export jobs
+//   - adminHandler: AdminHandler to process operational endpoints
+//   - cacheHandler: CacheHandler to process cache invalidation requests
+//   - watchHandler: WatchHandler to process address watch requests
+//   - healthHandler: HealthHandler to answer health checks
+//   - mirror: request shadowing to staging, nil when disabled
+func SetupRoutes(app *fiber.App, txHandler *api.TransactionHandler, portfolioHandler *api.PortfolioHandler, tokensHandler *api.TokensHandler, allowanceHandler *api.AllowanceHandler, receiptHandler *api.ReceiptHandler, exportHandler *api.ExportHandler, adminHandler *api.AdminHandler, cacheHandler *api.CacheHandler, watchHandler *api.WatchHandler, healthHandler *api.HealthHandler, mirror *shadow.Mirror) {
+	// Every request gets an X-Request-ID, tagged on its logs and provider requests,
+	// and one access log line once handled; panics become HTTP 500
+	app.Use(middleware.RequestID(), middleware.AccessLog(), middleware.Recover())
+
+	// Health check endpoint (useful for Docker, Kubernetes, load balancers, etc.),
+	// optionally checking Redis, Consul and critical providers
+	app.Get("/health", healthHandler.Health)
+
+	// Prometheus scrape endpoint (saturation gauges, Go runtime metrics)
+	app.Get("/metrics", metrics.Handler())
+
+	auth := middleware.APIKeyAuth()
 
 	// Transaction APIs
-	app.Get("/transactions", txHandler.GetTransactions)
+	app.Get("/transactions", auth, middleware.RequireRole(types.RoleRead), mirror.Middleware(), txHandler.GetTransactions)
+	app.Get("/portfolio", auth, middleware.RequireRole(types.RoleRead), portfolioHandler.GetPortfolio)
+	app.Get("/tokens", auth, middleware.RequireRole(types.RoleRead), tokensHandler.GetTokens)
+	app.Get("/allowance", auth, middleware.RequireRole(types.RoleRead), allowanceHandler.GetAllowance)
+	app.Get("/receipt/:chain/:hash", auth, middleware.RequireRole(types.RoleRead), receiptHandler.GetReceipt)
+
+	// Provider health as seen by the background prober
+	app.Get("/providers/status", auth, middleware.RequireRole(types.RoleRead), adminHandler.ProviderStatus)
+
+	// Cache invalidation for integrators (e.g. after broadcasting a transaction)
+	app.Post("/cache/invalidate", auth, middleware.RequireRole(types.RoleInvalidate), cacheHandler.InvalidateCache)
+
+	// Address watches, owned by the calling API key
+	watches := app.Group("/watches", auth, middleware.RequireRole(types.RoleWatch))
+	watches.Post("", watchHandler.CreateWatch)
+	watches.Get("", watchHandler.ListWatches)
+	watches.Delete("/:id", watchHandler.DeleteWatch)
+
+	// Export APIs (asynchronous jobs)
+	exports := app.Group("/exports", auth, middleware.RequireRole(types.RoleExport))
+	exports.Post("/taxlots", exportHandler.CreateTaxLotExport)
+	exports.Get("/taxlots/:id", exportHandler.GetTaxLotExport)
+
+	// Admin APIs: GET introspection needs read, anything mutating needs admin
+	admin := app.Group("/admin", auth, middleware.RequireRole(types.RoleRead))
+	admin.Get("/whoami", adminHandler.WhoAmI)
+	admin.Get("/config/effective", adminHandler.EffectiveConfig)
+	admin.Get("/provider-benchmark", adminHandler.ProviderBenchmark)
+	admin.Post("/regressions", middleware.RequireRole(types.RoleAdmin), adminHandler.ReportRegression)
+	admin.Get("/backfill/:id", adminHandler.GetBackfill)
+	admin.Post("/backfill", middleware.RequireRole(types.RoleAdmin), adminHandler.StartBackfill)
+	admin.Get("/replay/:id", adminHandler.GetReplay)
+	admin.Post("/replay", middleware.RequireRole(types.RoleAdmin), adminHandler.StartReplay)
+	admin.Get("/shadow/diffs", adminHandler.ShadowDiffs)
 }
@@ -0,0 +1,92 @@
+// Package server starts the HTTP server on the configured address, over
+// HTTPS when server.tls is enabled.
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"tx-aggregator/types"
+)
+
+const defaultAutocertCacheDir = "./autocert"
+
+// Listen serves app on cfg.Host and port until the server stops, over TLS
+// when cfg.TLS is enabled. port overrides cfg.Port, as the bootstrap file may.
+func Listen(app *fiber.App, cfg types.ServerConfig, port int) error {
+	ln, err := listener(cfg, port)
+	if err != nil {
+		return err
+	}
+	return app.Listener(ln)
+}
+
+// listener opens the listening socket for cfg.
+func listener(cfg types.ServerConfig, port int) (net.Listener, error) {
+	tc, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+	if tc == nil {
+		return net.Listen("tcp", addr)
+	}
+	return tls.Listen("tcp", addr, tc)
+}
+
+// newTLSConfig builds the server TLS settings from cfg. It returns nil when
+// TLS is disabled.
+//
+// fasthttp only speaks HTTP/1.1, so that is the only application protocol
+// offered over ALPN: advertising h2 would make clients pick a protocol the
+// server cannot serve.
+func newTLSConfig(cfg types.ServerTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	minVersion := uint16(tls.VersionTLS12)
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported server.tls.min_version %q", cfg.MinVersion)
+	}
+
+	var tc *tls.Config
+	switch {
+	case cfg.CertFile != "" || cfg.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load server certificate: %w", err)
+		}
+		tc = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			NextProtos:   []string{"http/1.1"},
+		}
+	case len(cfg.Autocert.Domains) > 0:
+		cacheDir := cfg.Autocert.CacheDir
+		if cacheDir == "" {
+			cacheDir = defaultAutocertCacheDir
+		}
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Autocert.Domains...),
+			Cache:      autocert.DirCache(cacheDir),
+			Email:      cfg.Autocert.Email,
+		}
+		tc = m.TLSConfig()
+		tc.NextProtos = []string{"http/1.1", acme.ALPNProto}
+	default:
+		return nil, errors.New("server.tls is enabled without cert_file / key_file or autocert.domains")
+	}
+	tc.MinVersion = minVersion
+	return tc, nil
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/types"
+)
+
+// writeSelfSigned writes a self-signed certificate for 127.0.0.1 and its key
+// to dir and returns their paths.
+func writeSelfSigned(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tx-aggregator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	tc, err := newTLSConfig(types.ServerTLSConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, tc, "disabled TLS yields no config")
+
+	_, err = newTLSConfig(types.ServerTLSConfig{Enabled: true})
+	assert.Error(t, err, "a certificate source is required")
+
+	certFile, keyFile := writeSelfSigned(t, t.TempDir())
+	tc, err = newTLSConfig(types.ServerTLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, tc) {
+		assert.Len(t, tc.Certificates, 1)
+		assert.Equal(t, uint16(tls.VersionTLS13), tc.MinVersion)
+		assert.Equal(t, []string{"http/1.1"}, tc.NextProtos)
+	}
+
+	_, err = newTLSConfig(types.ServerTLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"})
+	assert.Error(t, err)
+
+	_, err = newTLSConfig(types.ServerTLSConfig{Enabled: true, CertFile: filepath.Join(t.TempDir(), "missing.pem"), KeyFile: keyFile})
+	assert.Error(t, err)
+
+	tc, err = newTLSConfig(types.ServerTLSConfig{Enabled: true, Autocert: types.AutocertConfig{Domains: []string{"api.example.com"}, CacheDir: t.TempDir()}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, tc) {
+		assert.NotNil(t, tc.GetCertificate)
+		assert.Equal(t, uint16(tls.VersionTLS12), tc.MinVersion)
+		assert.NotContains(t, tc.NextProtos, "h2", "fasthttp cannot serve HTTP/2")
+	}
+}
+
+func TestListenerTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t, t.TempDir())
+	ln, err := listener(types.ServerConfig{
+		Host: "127.0.0.1",
+		TLS:  types.ServerTLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile},
+	}, 0)
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/health", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + ln.Addr().String() + "/health")
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, "HTTP/1.1", resp.Proto)
+	}
+}
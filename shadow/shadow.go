@@ -0,0 +1,288 @@
+// Package shadow mirrors a sample of production GET requests to a staging
+// deployment in the background and compares the two responses, so that a
+// release can be checked against real traffic before it is promoted.
+// Client credentials and forwarding headers are never sent to staging.
+// Transactions are compared by hash (see utils.HashTransactions); requests
+// whose answers differ are kept in memory for GET /admin/shadow/diffs.
+package shadow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultQueueSize = 100
+	workers          = 2
+	maxDiffs         = 100 // most recent diffs kept
+	maxHashesPerDiff = 20  // missing / extra hashes listed per diff
+)
+
+// scrubbedHeaders are never forwarded to staging. The configured API key
+// header is scrubbed as well.
+var scrubbedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Real-Ip",
+}
+
+// mirrored is one production exchange waiting to be replayed on staging.
+type mirrored struct {
+	path     string
+	headers  map[string]string
+	prodBody []byte
+	at       time.Time
+}
+
+// Mirror forwards sampled requests to staging and records response diffs.
+type Mirror struct {
+	cfg    types.ShadowConfig
+	client *http.Client
+	queue  chan mirrored
+	sample func() float64 // returns [0, 100)
+
+	mu    sync.Mutex
+	diffs []types.ShadowDiff // oldest first
+}
+
+// NewMirror builds a Mirror from cfg, filling in defaults. It returns nil
+// when shadowing is disabled or has no staging URL.
+func NewMirror(cfg types.ShadowConfig) *Mirror {
+	if !cfg.Enabled || cfg.BaseURL == "" {
+		return nil
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	logger.Log.Info().
+		Str("base_url", cfg.BaseURL).
+		Float64("percent", cfg.Percent).
+		Msg("Initializing request shadowing")
+
+	return &Mirror{
+		cfg:    cfg,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan mirrored, cfg.QueueSize),
+		sample: func() float64 { return rand.Float64() * 100 },
+	}
+}
+
+// Start runs the background workers that query staging.
+func (m *Mirror) Start() {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for req := range m.queue {
+				m.compare(req)
+			}
+		}()
+	}
+}
+
+// Middleware serves the request as usual and then, for a sampled share of
+// successful GET requests, queues the exchange for replay on staging. It
+// never delays or alters the production response. A nil Mirror passes every
+// request through.
+func (m *Mirror) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil || m == nil {
+			return err
+		}
+		if c.Method() != fiber.MethodGet || c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+		if m.sample() >= m.cfg.Percent {
+			return nil
+		}
+
+		// Fiber reuses its buffers once the handler returns: copy everything.
+		req := mirrored{
+			path:     string(c.Request().RequestURI()),
+			headers:  m.forwardedHeaders(c),
+			prodBody: append([]byte(nil), c.Response().Body()...),
+			at:       time.Now(),
+		}
+		select {
+		case m.queue <- req:
+		default:
+			metrics.ShadowRequests.WithLabelValues("dropped").Inc()
+		}
+		return nil
+	}
+}
+
+// Diffs returns the most recent recorded diffs, newest first.
+func (m *Mirror) Diffs() []types.ShadowDiff {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]types.ShadowDiff, len(m.diffs))
+	for i, d := range m.diffs {
+		out[len(m.diffs)-1-i] = d
+	}
+	return out
+}
+
+// forwardedHeaders copies the request headers minus credentials and
+// forwarding headers, and adds the staging API key when one is configured.
+func (m *Mirror) forwardedHeaders(c *fiber.Ctx) map[string]string {
+	apiKeyHeader := config.Current().Auth.Header
+	if apiKeyHeader == "" {
+		apiKeyHeader = types.DefaultAPIKeyHeader
+	}
+
+	headers := make(map[string]string)
+	for k, v := range c.GetReqHeaders() {
+		if len(v) == 0 || isScrubbed(k, apiKeyHeader) {
+			continue
+		}
+		headers[k] = v[0]
+	}
+	if m.cfg.APIKey != "" {
+		headers[apiKeyHeader] = m.cfg.APIKey
+	}
+	return headers
+}
+
+func isScrubbed(header, apiKeyHeader string) bool {
+	if strings.EqualFold(header, apiKeyHeader) {
+		return true
+	}
+	for _, h := range scrubbedHeaders {
+		if strings.EqualFold(header, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare replays req on staging and records a diff when the answers differ.
+func (m *Mirror) compare(req mirrored) {
+	diff := types.ShadowDiff{Time: req.at.Unix(), Path: req.path}
+
+	var prod types.TransactionResponse
+	if err := json.Unmarshal(req.prodBody, &prod); err != nil {
+		return // not a transaction list, nothing to compare
+	}
+	diff.ProdCode = prod.Code
+	diff.ProdCount = len(prod.Result.Transactions)
+
+	staging, err := m.fetch(req)
+	if err != nil {
+		metrics.ShadowRequests.WithLabelValues("error").Inc()
+		logger.Log.Warn().Err(err).Str("path", req.path).Msg("Shadow request to staging failed")
+		diff.Error = err.Error()
+		m.record(diff)
+		return
+	}
+	diff.StagingCode = staging.Code
+	diff.StagingCount = len(staging.Result.Transactions)
+
+	if prod.Code == staging.Code &&
+		utils.HashTransactions(prod.Result.Transactions) == utils.HashTransactions(staging.Result.Transactions) {
+		metrics.ShadowRequests.WithLabelValues("match").Inc()
+		return
+	}
+
+	diff.Missing, diff.Extra = hashDiff(prod.Result.Transactions, staging.Result.Transactions)
+	metrics.ShadowRequests.WithLabelValues("diff").Inc()
+	logger.Log.Warn().
+		Str("path", req.path).
+		Int("prod_code", diff.ProdCode).
+		Int("staging_code", diff.StagingCode).
+		Int("prod_count", diff.ProdCount).
+		Int("staging_count", diff.StagingCount).
+		Msg("Shadow response differs from production")
+	m.record(diff)
+}
+
+// fetch sends req to staging and decodes its transaction response.
+func (m *Mirror) fetch(req mirrored) (*types.TransactionResponse, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, m.cfg.BaseURL+req.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("staging returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out types.TransactionResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("decode staging response: %w", err)
+	}
+	return &out, nil
+}
+
+// record appends diff, evicting the oldest beyond maxDiffs.
+func (m *Mirror) record(diff types.ShadowDiff) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diffs = append(m.diffs, diff)
+	if len(m.diffs) > maxDiffs {
+		m.diffs = m.diffs[len(m.diffs)-maxDiffs:]
+	}
+}
+
+// hashDiff returns up to maxHashesPerDiff hashes only in prod and only in
+// staging, in the order they appear.
+func hashDiff(prod, staging []types.Transaction) (missing, extra []string) {
+	inProd := make(map[string]struct{}, len(prod))
+	for _, tx := range prod {
+		inProd[tx.Hash] = struct{}{}
+	}
+	inStaging := make(map[string]struct{}, len(staging))
+	for _, tx := range staging {
+		inStaging[tx.Hash] = struct{}{}
+	}
+	for _, tx := range prod {
+		if _, ok := inStaging[tx.Hash]; !ok && len(missing) < maxHashesPerDiff {
+			missing = append(missing, tx.Hash)
+			inStaging[tx.Hash] = struct{}{} // list each hash once
+		}
+	}
+	for _, tx := range staging {
+		if _, ok := inProd[tx.Hash]; !ok && len(extra) < maxHashesPerDiff {
+			extra = append(extra, tx.Hash)
+			inProd[tx.Hash] = struct{}{}
+		}
+	}
+	return missing, extra
+}
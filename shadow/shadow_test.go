@@ -0,0 +1,95 @@
+package shadow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+func txResponse(hashes ...string) *types.TransactionResponse {
+	resp := &types.TransactionResponse{Code: types.CodeSuccess}
+	for _, h := range hashes {
+		resp.Result.Transactions = append(resp.Result.Transactions, types.Transaction{Hash: h, ChainID: 1})
+	}
+	return resp
+}
+
+func TestMirror(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		headers []http.Header
+	)
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+		if r.URL.Query().Get("address") == "same" {
+			_ = json.NewEncoder(w).Encode(txResponse("0xa", "0xb"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(txResponse("0xa", "0xc"))
+	}))
+	defer staging.Close()
+
+	m := NewMirror(types.ShadowConfig{Enabled: true, BaseURL: staging.URL + "/", Percent: 50, APIKey: "staging-key"})
+	samples := []float64{10, 60, 10} // mirrored, skipped, mirrored
+	m.sample = func() float64 {
+		v := samples[0]
+		samples = samples[1:]
+		return v
+	}
+	m.Start()
+
+	app := fiber.New()
+	app.Get("/transactions", m.Middleware(), func(c *fiber.Ctx) error {
+		return c.JSON(txResponse("0xb", "0xa"))
+	})
+
+	for _, addr := range []string{"same", "skipped", "other"} {
+		req := httptest.NewRequest(http.MethodGet, "/transactions?address="+addr, nil)
+		req.Header.Set("X-API-Key", "prod-key")
+		req.Header.Set("Cookie", "session=1")
+		req.Header.Set("Accept-Language", "en")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	assert.Eventually(t, func() bool { return len(m.Diffs()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	diff := m.Diffs()[0]
+	assert.Equal(t, "/transactions?address=other", diff.Path)
+	assert.Equal(t, []string{"0xb"}, diff.Missing)
+	assert.Equal(t, []string{"0xc"}, diff.Extra)
+	assert.Equal(t, 2, diff.ProdCount)
+	assert.Equal(t, 2, diff.StagingCount)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, headers, 2) {
+		for _, h := range headers {
+			assert.Equal(t, "staging-key", h.Get("X-API-Key"))
+			assert.Empty(t, h.Get("Cookie"))
+			assert.Equal(t, "en", h.Get("Accept-Language"))
+		}
+	}
+}
+
+func TestNewMirror_Disabled(t *testing.T) {
+	assert.Nil(t, NewMirror(types.ShadowConfig{BaseURL: "http://staging"}))
+	assert.Nil(t, NewMirror(types.ShadowConfig{Enabled: true}))
+
+	var m *Mirror
+	app := fiber.New()
+	app.Get("/", m.Middleware(), func(c *fiber.Ctx) error { return c.SendString("ok") })
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, m.Diffs())
+}
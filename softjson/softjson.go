@@ -0,0 +1,82 @@
+// Package softjson decodes JSON arrays item by item, so that one malformed
+// element returned by a provider is skipped and counted instead of failing
+// the whole response.
+package softjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+)
+
+// Stages at which an item can be skipped.
+const (
+	StageDecode    = "decode"
+	StageNormalize = "normalize"
+)
+
+// maxPayloadLog bounds the part of an offending payload written to the log.
+const maxPayloadLog = 512
+
+// sampler limits skip logs to a burst per minute; every skip is still counted.
+var sampler = &zerolog.BurstSampler{Burst: 5, Period: time.Minute}
+
+// Slice is a []T whose JSON decoding tolerates invalid elements. A JSON null
+// decodes to a nil slice; a value that is not an array is still an error.
+type Slice[T any] []T
+
+// UnmarshalJSON decodes each element separately and drops the ones that fail.
+func (s *Slice[T]) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	if raws == nil {
+		*s = nil
+		return nil
+	}
+
+	out := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			Skip(StageDecode, kindOf(item), raw, err)
+			continue
+		}
+		out = append(out, item)
+	}
+	*s = out
+	return nil
+}
+
+// Skip records an item dropped at stage. kind names the item type, e.g.
+// "types.BlockscoutTransaction", and payload is the offending raw JSON.
+func Skip(stage, kind string, payload []byte, err error) {
+	metrics.SkippedItems.WithLabelValues(kind, stage).Inc()
+
+	if len(payload) > maxPayloadLog {
+		payload = payload[:maxPayloadLog]
+	}
+	l := logger.Log.Sample(sampler)
+	l.Warn().
+		Err(err).
+		Str("stage", stage).
+		Str("kind", kind).
+		Bytes("payload", payload).
+		Msg("Skipping malformed provider item")
+}
+
+// SkipItem is Skip for an already decoded item that failed normalization.
+func SkipItem(item any, err error) {
+	payload, _ := json.Marshal(item)
+	Skip(StageNormalize, kindOf(item), payload, err)
+}
+
+// kindOf returns the Go type name of v, used as the metric label.
+func kindOf(v any) string {
+	return fmt.Sprintf("%T", v)
+}
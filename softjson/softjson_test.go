@@ -0,0 +1,47 @@
+package softjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/metrics"
+)
+
+type item struct {
+	Hash  string `json:"hash"`
+	Block int64  `json:"block"`
+}
+
+func TestSlice_SkipsMalformedItems(t *testing.T) {
+	skipped := metrics.SkippedItems.WithLabelValues("softjson.item", StageDecode)
+	before := testutil.ToFloat64(skipped)
+
+	var resp struct {
+		Items Slice[item] `json:"items"`
+	}
+	body := `{"items":[{"hash":"0x1","block":1},{"hash":"0x2","block":"oops"},{"hash":"0x3","block":3}]}`
+	assert.NoError(t, json.Unmarshal([]byte(body), &resp))
+
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, "0x1", resp.Items[0].Hash)
+	assert.Equal(t, "0x3", resp.Items[1].Hash)
+	assert.Equal(t, before+1, testutil.ToFloat64(skipped))
+}
+
+func TestSlice_NullAndNonArray(t *testing.T) {
+	var s Slice[item]
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &s))
+	assert.Nil(t, s)
+
+	assert.Error(t, json.Unmarshal([]byte(`"Max rate limit reached"`), &s))
+}
+
+func TestSkipItem_CountsNormalizeFailures(t *testing.T) {
+	skipped := metrics.SkippedItems.WithLabelValues("softjson.item", StageNormalize)
+	before := testutil.ToFloat64(skipped)
+
+	SkipItem(item{Hash: "0x4"}, assert.AnError)
+	assert.Equal(t, before+1, testutil.ToFloat64(skipped))
+}
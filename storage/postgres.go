@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultMaxOpenConns = 10
+	defaultMaxRows      = 10000
+)
+
+// schema creates the transactions table. row_key is utils.TransactionKey,
+// which tells apart the transfers of one transaction.
+const schema = `
+CREATE TABLE IF NOT EXISTS transactions (
+	address       TEXT        NOT NULL,
+	chain_id      BIGINT      NOT NULL,
+	row_key       TEXT        NOT NULL,
+	hash          TEXT        NOT NULL,
+	height        BIGINT      NOT NULL,
+	coin_type     INT         NOT NULL,
+	token_address TEXT        NOT NULL,
+	data          JSONB       NOT NULL,
+	updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (address, chain_id, row_key)
+);
+CREATE INDEX IF NOT EXISTS transactions_address_height_idx
+	ON transactions (address, chain_id, height DESC);`
+
+const upsertSQL = `
+INSERT INTO transactions (address, chain_id, row_key, hash, height, coin_type, token_address, data)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (address, chain_id, row_key) DO UPDATE SET
+	hash = EXCLUDED.hash,
+	height = EXCLUDED.height,
+	coin_type = EXCLUDED.coin_type,
+	token_address = EXCLUDED.token_address,
+	data = EXCLUDED.data,
+	updated_at = now()`
+
+const deleteSQL = `DELETE FROM transactions WHERE address = $1 AND chain_id = $2 AND row_key = $3`
+
+// PostgresStore is a Store backed by PostgreSQL.
+type PostgresStore struct {
+	db      *sql.DB
+	maxRows int
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// NewPostgresStore connects to cfg.DSN and creates the schema if needed.
+func NewPostgresStore(cfg types.StorageConfig) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	maxOpen := cfg.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenConns
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxOpen)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s := newPostgresStore(db, cfg.MaxRows)
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// newPostgresStore wraps an open database.
+func newPostgresStore(db *sql.DB, maxRows int) *PostgresStore {
+	if maxRows <= 0 {
+		maxRows = defaultMaxRows
+	}
+	return &PostgresStore{db: db, maxRows: maxRows}
+}
+
+// migrate creates the schema.
+func (s *PostgresStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("create postgres schema: %w", err)
+	}
+	return nil
+}
+
+// SaveTransactions upserts txs for address in one database transaction.
+func (s *PostgresStore) SaveTransactions(ctx context.Context, address string, txs []types.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	dbtx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbtx.Rollback()
+
+	stmt, err := dbtx.PrepareContext(ctx, upsertSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	address = strings.ToLower(address)
+	for _, tx := range txs {
+		data, err := json.Marshal(tx)
+		if err != nil {
+			return fmt.Errorf("marshal transaction %s: %w", tx.Hash, err)
+		}
+		if _, err := stmt.ExecContext(ctx,
+			address,
+			tx.ChainID,
+			utils.TransactionKey(tx),
+			strings.ToLower(tx.Hash),
+			tx.Height,
+			tx.CoinType,
+			strings.ToLower(tx.TokenAddress),
+			data,
+		); err != nil {
+			return fmt.Errorf("upsert transaction %s: %w", tx.Hash, err)
+		}
+	}
+	return dbtx.Commit()
+}
+
+// DeleteTransactions removes the rows of txs for address in one database
+// transaction.
+func (s *PostgresStore) DeleteTransactions(ctx context.Context, address string, txs []types.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	dbtx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbtx.Rollback()
+
+	stmt, err := dbtx.PrepareContext(ctx, deleteSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	address = strings.ToLower(address)
+	for _, tx := range txs {
+		if _, err := stmt.ExecContext(ctx, address, tx.ChainID, utils.TransactionKey(tx)); err != nil {
+			return fmt.Errorf("delete transaction %s: %w", tx.Hash, err)
+		}
+	}
+	return dbtx.Commit()
+}
+
+// QueryTransactions returns at most storage.max_rows stored rows matching
+// params, newest first.
+func (s *PostgresStore) QueryTransactions(ctx context.Context, params *types.TransactionQueryParams) ([]types.Transaction, error) {
+	chainIDs := make([]int64, 0, len(params.ChainNames))
+	for _, name := range params.ChainNames {
+		if id, err := utils.ChainIDByName(name); err == nil {
+			chainIDs = append(chainIDs, id)
+		}
+	}
+	if len(chainIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args := buildQuery(params, chainIDs, s.maxRows)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []types.Transaction
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var tx types.Transaction
+		if err := json.Unmarshal(data, &tx); err != nil {
+			return nil, fmt.Errorf("decode stored transaction: %w", err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, rows.Err()
+}
+
+// buildQuery returns the SELECT for params over chainIDs and its arguments.
+func buildQuery(params *types.TransactionQueryParams, chainIDs []int64, limit int) (string, []interface{}) {
+	var b strings.Builder
+	args := []interface{}{strings.ToLower(params.Address), pq.Array(chainIDs)}
+	b.WriteString("SELECT data FROM transactions WHERE address = $1 AND chain_id = ANY($2)")
+
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	switch {
+	case params.TokenAddress == types.NativeTokenName:
+		b.WriteString(" AND coin_type = " + arg(types.CoinTypeNative))
+	case params.TokenAddress != "":
+		b.WriteString(" AND token_address = " + arg(strings.ToLower(params.TokenAddress)))
+	}
+	if params.StartBlock > 0 {
+		b.WriteString(" AND height >= " + arg(params.StartBlock))
+	}
+	if params.EndBlock > 0 {
+		b.WriteString(" AND height <= " + arg(params.EndBlock))
+	}
+	b.WriteString(" ORDER BY height DESC LIMIT " + arg(limit))
+	return b.String(), args
+}
+
+// Close closes the database.
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
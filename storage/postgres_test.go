@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+func withChains(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestSaveTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	s := newPostgresStore(db, 0)
+
+	txs := []types.Transaction{
+		{ChainID: 1, Hash: "0xAAA", Height: 10, CoinType: types.CoinTypeNative},
+		{ChainID: 1, Hash: "0xAAA", Height: 10, CoinType: types.CoinTypeToken, TokenAddress: "0xTOKEN"},
+	}
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT INTO transactions"))
+	prep.ExpectExec().
+		WithArgs("0xuser", int64(1), sqlmock.AnyArg(), "0xaaa", int64(10), types.CoinTypeNative, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().
+		WithArgs("0xuser", int64(1), sqlmock.AnyArg(), "0xaaa", int64(10), types.CoinTypeToken, "0xtoken", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, s.SaveTransactions(context.Background(), "0xUSER", txs))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	s := newPostgresStore(db, 0)
+
+	tx := types.Transaction{ChainID: 1, Hash: "0xAAA", CoinType: types.CoinTypeNative}
+	mock.ExpectBegin()
+	mock.ExpectPrepare(regexp.QuoteMeta("DELETE FROM transactions")).ExpectExec().
+		WithArgs("0xuser", int64(1), utils.TransactionKey(tx)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, s.DeleteTransactions(context.Background(), "0xUSER", []types.Transaction{tx}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryTransactions(t *testing.T) {
+	withChains(t)
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	s := newPostgresStore(db, 500)
+
+	row, _ := json.Marshal(types.Transaction{ChainID: 56, Hash: "0xbbb", Height: 20})
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT data FROM transactions WHERE address = $1 AND chain_id = ANY($2) AND height >= $3 ORDER BY height DESC LIMIT $4")).
+		WithArgs("0xuser", pq.Array([]int64{56}), int64(15), 500).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(row))
+
+	txs, err := s.QueryTransactions(context.Background(), &types.TransactionQueryParams{
+		Address: "0xUser", ChainNames: []string{"BSC", "NOPE"}, StartBlock: 15,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, txs, 1) {
+		assert.Equal(t, "0xbbb", txs[0].Hash)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildQuery(t *testing.T) {
+	query, args := buildQuery(&types.TransactionQueryParams{Address: "0xA", TokenAddress: types.NativeTokenName, EndBlock: 9}, []int64{1}, 10)
+	assert.Equal(t, "SELECT data FROM transactions WHERE address = $1 AND chain_id = ANY($2) AND coin_type = $3 AND height <= $4 ORDER BY height DESC LIMIT $5", query)
+	assert.Equal(t, []interface{}{"0xa", pq.Array([]int64{1}), types.CoinTypeNative, int64(9), 10}, args)
+
+	query, args = buildQuery(&types.TransactionQueryParams{Address: "0xa", TokenAddress: "0xTOKEN"}, []int64{1, 56}, 10)
+	assert.Equal(t, "SELECT data FROM transactions WHERE address = $1 AND chain_id = ANY($2) AND token_address = $3 ORDER BY height DESC LIMIT $4", query)
+	assert.Equal(t, "0xtoken", args[2])
+}
@@ -0,0 +1,26 @@
+// Package storage persists normalized transactions in PostgreSQL, one row
+// per address and transaction row. It is the durable second level behind
+// the Redis cache: history survives Redis flushes, grows beyond what one
+// page-limited provider fetch returns, and is served when every provider
+// fails.
+package storage
+
+import (
+	"context"
+
+	"tx-aggregator/types"
+)
+
+// Store persists transactions per address.
+type Store interface {
+	// SaveTransactions upserts txs for address; a row replaces the stored
+	// row with the same utils.TransactionKey.
+	SaveTransactions(ctx context.Context, address string, txs []types.Transaction) error
+	// DeleteTransactions removes the stored rows of address with the same
+	// utils.TransactionKey as txs.
+	DeleteTransactions(ctx context.Context, address string, txs []types.Transaction) error
+	// QueryTransactions returns the stored rows of params.Address on
+	// params.ChainNames, narrowed by token and block range, newest first.
+	QueryTransactions(ctx context.Context, params *types.TransactionQueryParams) ([]types.Transaction, error)
+	Close() error
+}
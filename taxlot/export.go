@@ -0,0 +1,128 @@
+package taxlot
+
+import (
+	"time"
+
+	"tx-aggregator/interfaces"
+	"tx-aggregator/jobs"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+)
+
+const (
+	defaultWorkers = 2   // concurrent export jobs
+	defaultMaxJobs = 200 // finished jobs kept in memory before eviction
+
+	poolName = "taxlot_export" // label used for worker pool metrics
+)
+
+// Exporter runs tax lot exports asynchronously and keeps their results in
+// memory until they are evicted by newer jobs. Pending and running exports
+// are never evicted.
+type Exporter struct {
+	history interfaces.TransactionHistoryInterface
+	sem     chan struct{} // bounds the number of running jobs
+	jobs    *jobs.Registry[types.ExportJob]
+}
+
+// NewExporter creates an Exporter. Non-positive workers / maxJobs fall back
+// to sensible defaults.
+func NewExporter(history interfaces.TransactionHistoryInterface, workers, maxJobs int) *Exporter {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if maxJobs <= 0 {
+		maxJobs = defaultMaxJobs
+	}
+	metrics.WorkerPoolSize.WithLabelValues(poolName).Set(float64(workers))
+	return &Exporter{
+		history: history,
+		sem:     make(chan struct{}, workers),
+		jobs: jobs.NewRegistry(maxJobs, func(job *types.ExportJob, now int64) {
+			job.UpdatedTime = now
+		}),
+	}
+}
+
+// Submit registers a new export job and starts it in the background.
+// The returned job is a snapshot; poll Get for progress.
+func (e *Exporter) Submit(params *types.TaxLotExportParams) types.ExportJob {
+	now := time.Now().Unix()
+	job, _ := e.jobs.Add("", func(id string) *types.ExportJob {
+		return &types.ExportJob{
+			ID:          id,
+			Status:      types.ExportStatusPending,
+			CreatedTime: now,
+			UpdatedTime: now,
+		}
+	})
+
+	logger.Log.Info().
+		Str("job_id", job.ID).
+		Str("address", params.Address).
+		Str("method", params.Method).
+		Msg("Tax lot export submitted")
+
+	e.jobs.Go(job.ID, func() { e.run(job.ID, params) }, func(job *types.ExportJob, err error) {
+		job.Status = types.ExportStatusFailed
+		job.Error = err.Error()
+	})
+	return job
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (e *Exporter) Get(id string) (types.ExportJob, bool) {
+	return e.jobs.Get(id)
+}
+
+// run executes one export once a worker slot is free.
+func (e *Exporter) run(id string, params *types.TaxLotExportParams) {
+	defer e.jobs.Finish(id)
+	waitStart := time.Now()
+	e.sem <- struct{}{}
+	metrics.SemaphoreWait.WithLabelValues(poolName).Observe(time.Since(waitStart).Seconds())
+	busy := metrics.WorkerPoolBusy.WithLabelValues(poolName)
+	busy.Inc()
+	defer func() {
+		busy.Dec()
+		<-e.sem
+	}()
+
+	e.jobs.Update(id, func(job *types.ExportJob) { job.Status = types.ExportStatusRunning })
+	start := time.Now()
+
+	txs, complete, err := e.history.GetTransactionHistory(&types.TransactionQueryParams{
+		Address:      params.Address,
+		TokenAddress: params.TokenAddress,
+		ChainNames:   params.ChainNames,
+	})
+	var report *types.TaxLotReport
+	if err == nil {
+		report, err = BuildReport(params.Address, params.Method, txs)
+	}
+	if err == nil && !complete {
+		report.Truncated = true
+		logger.Log.Warn().Str("job_id", id).Int("tx_count", len(txs)).Msg("Tax lot export built from truncated history")
+	}
+
+	if err != nil {
+		logger.Log.Error().Err(err).Str("job_id", id).Msg("Tax lot export failed")
+		e.jobs.Update(id, func(job *types.ExportJob) {
+			job.Status = types.ExportStatusFailed
+			job.Error = err.Error()
+		})
+		return
+	}
+
+	logger.Log.Info().
+		Str("job_id", id).
+		Int("tx_count", len(txs)).
+		Int("asset_count", len(report.Assets)).
+		Dur("cost", time.Since(start)).
+		Msg("Tax lot export finished")
+	e.jobs.Update(id, func(job *types.ExportJob) {
+		job.Status = types.ExportStatusDone
+		job.Report = report
+	})
+}
@@ -0,0 +1,216 @@
+// Package taxlot derives cost-basis lots and disposals from an address's
+// transaction history. Every incoming transfer opens a lot, every outgoing
+// transfer is matched against open lots using the selected accounting method.
+// The fee of every transaction sent by the address is disposed of the native
+// coin too, whether the transaction succeeded or not.
+//
+// Fiat valuation is intentionally left out: the aggregator has no price data,
+// so each lot carries its acquisition hash and timestamp, which is all a tax
+// partner needs to attach prices on its side.
+package taxlot
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// openLot is the mutable working state behind a types.TaxLot.
+type openLot struct {
+	lot       types.TaxLot
+	quantity  *big.Int
+	remaining *big.Int
+}
+
+// event is a transaction row of one asset, or the fee paid by its sender.
+type event struct {
+	tx  types.Transaction
+	fee *big.Int // set for a fee, disposed instead of tx.Balance
+}
+
+// assetKey groups transactions by chain and token contract, and by token ID
+// for ERC-1155 tokens.
+type assetKey struct {
+	chainID int64
+	token   string
+	tokenID string
+}
+
+// BuildReport groups the transactions of address by asset and runs lot
+// matching for each one. Failed transactions, approvals and zero-value rows
+// move nothing, but the fee of each transaction sent by address is disposed
+// of the native coin once. Assets are returned ordered by chain ID and token
+// address.
+func BuildReport(address, method string, txs []types.Transaction) (*types.TaxLotReport, error) {
+	method = strings.ToLower(method)
+	if method != types.TaxLotMethodFIFO && method != types.TaxLotMethodLIFO {
+		return nil, fmt.Errorf("unsupported accounting method: %s", method)
+	}
+
+	grouped := make(map[assetKey][]event)
+	feePaid := make(map[string]bool)
+	for _, tx := range txs {
+		if fee, ok := utils.TxFee(tx, address); ok && fee.Sign() > 0 {
+			id := fmt.Sprintf("%d:%s", tx.ChainID, strings.ToLower(tx.Hash))
+			if !feePaid[id] {
+				feePaid[id] = true
+				key := assetKey{chainID: tx.ChainID, token: types.NativeTokenName}
+				grouped[key] = append(grouped[key], event{tx: tx, fee: fee})
+			}
+		}
+		if tx.State != types.TxStateSuccess || tx.Type == types.TxTypeApprove {
+			continue
+		}
+		key := assetKey{chainID: tx.ChainID, token: types.NativeTokenName}
+		if types.IsTokenCoinType(tx.CoinType) {
+			key.token = strings.ToLower(tx.TokenAddress)
+		}
+		if tx.CoinType == types.CoinTypeERC1155 {
+			key.tokenID = tx.TokenIDRaw
+		}
+		grouped[key] = append(grouped[key], event{tx: tx})
+	}
+
+	keys := make([]assetKey, 0, len(grouped))
+	for k := range grouped {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].chainID != keys[j].chainID {
+			return keys[i].chainID < keys[j].chainID
+		}
+		if keys[i].token != keys[j].token {
+			return keys[i].token < keys[j].token
+		}
+		return keys[i].tokenID < keys[j].tokenID
+	})
+
+	report := &types.TaxLotReport{
+		Address: strings.ToLower(address),
+		Method:  method,
+		Assets:  make([]types.TaxLotAsset, 0, len(keys)),
+	}
+	for _, k := range keys {
+		asset := matchLots(address, method, grouped[k])
+		asset.ChainID = k.chainID
+		asset.TokenAddress = k.token
+		asset.TokenIDRaw = k.tokenID
+		report.Assets = append(report.Assets, asset)
+	}
+	return report, nil
+}
+
+// matchLots walks the events of a single asset in chronological order.
+func matchLots(address, method string, events []event) types.TaxLotAsset {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].tx.Height != events[j].tx.Height {
+			return events[i].tx.Height < events[j].tx.Height
+		}
+		return events[i].tx.TxIndex < events[j].tx.TxIndex
+	})
+
+	var asset types.TaxLotAsset
+	var lots []*openLot
+
+	for _, ev := range events {
+		tx := ev.tx
+		if asset.TokenDisplayName == "" {
+			asset.TokenDisplayName = tx.TokenDisplayName
+			asset.Decimals = tx.Decimals
+		}
+		if ev.fee != nil {
+			asset.Disposals = append(asset.Disposals, dispose(method, lots, tx, ev.fee, asset.Decimals, true)...)
+			continue
+		}
+
+		qty, ok := new(big.Int).SetString(tx.Balance, 10)
+		if !ok || qty.Sign() <= 0 {
+			continue
+		}
+
+		incoming := strings.EqualFold(tx.ToAddress, address)
+		outgoing := strings.EqualFold(tx.FromAddress, address)
+		switch {
+		case incoming && outgoing:
+			// Self transfer: holdings do not change.
+			continue
+		case incoming:
+			lots = append(lots, &openLot{
+				lot: types.TaxLot{
+					Hash:         tx.Hash,
+					AcquiredTime: tx.CreatedTime,
+					Height:       tx.Height,
+				},
+				quantity:  new(big.Int).Set(qty),
+				remaining: new(big.Int).Set(qty),
+			})
+		case outgoing:
+			asset.Disposals = append(asset.Disposals, dispose(method, lots, tx, qty, asset.Decimals, false)...)
+		}
+	}
+
+	asset.Lots = make([]types.TaxLot, 0, len(lots))
+	for _, l := range lots {
+		l.lot.QuantityRaw = l.quantity.String()
+		l.lot.Quantity = utils.DivideByDecimals(l.lot.QuantityRaw, int(asset.Decimals))
+		l.lot.RemainingRaw = l.remaining.String()
+		l.lot.Remaining = utils.DivideByDecimals(l.lot.RemainingRaw, int(asset.Decimals))
+		asset.Lots = append(asset.Lots, l.lot)
+	}
+	return asset
+}
+
+// dispose consumes qty from the open lots and returns one disposal per lot
+// touched. Any quantity left once all lots are empty is reported as unmatched.
+// fee marks the disposals as the fee paid by tx rather than a transfer.
+func dispose(method string, lots []*openLot, tx types.Transaction, qty *big.Int, decimals int64, fee bool) []types.TaxDisposal {
+	var out []types.TaxDisposal
+	left := new(big.Int).Set(qty)
+
+	record := func(lot *openLot, amount *big.Int) {
+		d := types.TaxDisposal{
+			Hash:         tx.Hash,
+			DisposedTime: tx.CreatedTime,
+			Height:       tx.Height,
+			QuantityRaw:  amount.String(),
+			Quantity:     utils.DivideByDecimals(amount.String(), int(decimals)),
+			Unmatched:    lot == nil,
+			Fee:          fee,
+		}
+		if lot != nil {
+			d.LotHash = lot.lot.Hash
+			d.AcquiredTime = lot.lot.AcquiredTime
+		}
+		out = append(out, d)
+	}
+
+	for i := range lots {
+		if left.Sign() == 0 {
+			break
+		}
+		lot := lots[i]
+		if method == types.TaxLotMethodLIFO {
+			lot = lots[len(lots)-1-i]
+		}
+		if lot.remaining.Sign() == 0 {
+			continue
+		}
+
+		take := new(big.Int).Set(left)
+		if take.Cmp(lot.remaining) > 0 {
+			take.Set(lot.remaining)
+		}
+		lot.remaining.Sub(lot.remaining, take)
+		left.Sub(left, take)
+		record(lot, take)
+	}
+
+	if left.Sign() > 0 {
+		record(nil, left)
+	}
+	return out
+}
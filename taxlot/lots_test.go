@@ -0,0 +1,179 @@
+package taxlot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+const me = "0xme"
+
+// history returns two acquisitions (10 then 5) followed by a disposal of 12.
+func history() []types.Transaction {
+	return []types.Transaction{
+		{Hash: "0xin1", Height: 1, ChainID: 1, ToAddress: me, FromAddress: "0xa", Balance: "10", CoinType: types.CoinTypeNative, State: types.TxStateSuccess},
+		{Hash: "0xin2", Height: 2, ChainID: 1, ToAddress: me, FromAddress: "0xb", Balance: "5", CoinType: types.CoinTypeNative, State: types.TxStateSuccess},
+		{Hash: "0xout", Height: 3, ChainID: 1, ToAddress: "0xc", FromAddress: me, Balance: "12", CoinType: types.CoinTypeNative, State: types.TxStateSuccess},
+	}
+}
+
+func TestBuildReport_FIFO(t *testing.T) {
+	report, err := BuildReport(me, types.TaxLotMethodFIFO, history())
+	assert.NoError(t, err)
+	assert.Len(t, report.Assets, 1)
+
+	asset := report.Assets[0]
+	assert.Equal(t, types.NativeTokenName, asset.TokenAddress)
+	assert.Len(t, asset.Disposals, 2)
+	assert.Equal(t, "0xin1", asset.Disposals[0].LotHash)
+	assert.Equal(t, "10", asset.Disposals[0].QuantityRaw)
+	assert.Equal(t, "0xin2", asset.Disposals[1].LotHash)
+	assert.Equal(t, "2", asset.Disposals[1].QuantityRaw)
+	assert.Equal(t, "0", asset.Lots[0].RemainingRaw)
+	assert.Equal(t, "3", asset.Lots[1].RemainingRaw)
+}
+
+func TestBuildReport_LIFO(t *testing.T) {
+	report, err := BuildReport(me, types.TaxLotMethodLIFO, history())
+	assert.NoError(t, err)
+
+	asset := report.Assets[0]
+	assert.Len(t, asset.Disposals, 2)
+	assert.Equal(t, "0xin2", asset.Disposals[0].LotHash)
+	assert.Equal(t, "5", asset.Disposals[0].QuantityRaw)
+	assert.Equal(t, "0xin1", asset.Disposals[1].LotHash)
+	assert.Equal(t, "7", asset.Disposals[1].QuantityRaw)
+	assert.Equal(t, "3", asset.Lots[0].RemainingRaw)
+	assert.Equal(t, "0", asset.Lots[1].RemainingRaw)
+}
+
+func TestBuildReport_UnmatchedAndIgnored(t *testing.T) {
+	txs := []types.Transaction{
+		{Hash: "0xout", Height: 1, ChainID: 1, FromAddress: me, Balance: "4", CoinType: types.CoinTypeToken, TokenAddress: "0xTOKEN", State: types.TxStateSuccess},
+		{Hash: "0xfail", Height: 2, ChainID: 1, ToAddress: me, Balance: "9", CoinType: types.CoinTypeToken, TokenAddress: "0xtoken", State: types.TxStateFail},
+		{Hash: "0xappr", Height: 3, ChainID: 1, ToAddress: me, Balance: "9", Type: types.TxTypeApprove, State: types.TxStateSuccess},
+	}
+
+	report, err := BuildReport(me, types.TaxLotMethodFIFO, txs)
+	assert.NoError(t, err)
+	assert.Len(t, report.Assets, 1)
+	assert.Equal(t, "0xtoken", report.Assets[0].TokenAddress)
+	assert.Empty(t, report.Assets[0].Lots)
+	assert.Len(t, report.Assets[0].Disposals, 1)
+	assert.True(t, report.Assets[0].Disposals[0].Unmatched)
+}
+
+func TestBuildReport_Fees(t *testing.T) {
+	txs := []types.Transaction{
+		{Hash: "0xin", Height: 1, ChainID: 1, ToAddress: me, FromAddress: "0xa", Balance: "1000", CoinType: types.CoinTypeNative, State: types.TxStateSuccess},
+		// A token send pays its fee on its native row.
+		{Hash: "0xsend", Height: 2, ChainID: 1, ToAddress: "0xc", FromAddress: me, Balance: "4", CoinType: types.CoinTypeToken,
+			TokenAddress: "0xtoken", State: types.TxStateSuccess},
+		{Hash: "0xsend", Height: 2, ChainID: 1, ToAddress: "0xtoken", FromAddress: me, Balance: "0", CoinType: types.CoinTypeNative,
+			GasUsed: "10", GasPrice: "3", State: types.TxStateSuccess},
+		// A failed transaction still pays its fee.
+		{Hash: "0xfail", Height: 3, ChainID: 1, ToAddress: "0xc", FromAddress: me, Balance: "500", CoinType: types.CoinTypeNative,
+			Fee: "70", State: types.TxStateFail},
+		// An internal row sent by the address does not carry the fee.
+		{Hash: "0xfail", Height: 3, ChainID: 1, ToAddress: "0xd", FromAddress: me, Balance: "1", CoinType: types.CoinTypeNative,
+			Type: types.TxTypeInternal, GasUsed: "10", GasPrice: "3", State: types.TxStateFail},
+	}
+	report, err := BuildReport(me, types.TaxLotMethodFIFO, txs)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Assets, 2) {
+		native := report.Assets[1]
+		assert.Equal(t, types.NativeTokenName, native.TokenAddress)
+		if assert.Len(t, native.Disposals, 2) {
+			assert.Equal(t, types.TaxDisposal{Hash: "0xsend", Height: 2, LotHash: "0xin", QuantityRaw: "30", Quantity: "30", Fee: true}, native.Disposals[0])
+			assert.Equal(t, "0xfail", native.Disposals[1].Hash)
+			assert.Equal(t, "70", native.Disposals[1].QuantityRaw)
+			assert.True(t, native.Disposals[1].Fee)
+		}
+		assert.Equal(t, "900", native.Lots[0].RemainingRaw)
+		assert.False(t, report.Assets[0].Disposals[0].Fee)
+	}
+}
+
+func TestBuildReport_ERC1155IDs(t *testing.T) {
+	txs := []types.Transaction{
+		{Hash: "0x1", Height: 1, ChainID: 1, ToAddress: me, FromAddress: "0xa", Balance: "4", CoinType: types.CoinTypeERC1155,
+			TokenAddress: "0xItems", TokenIDRaw: "7", State: types.TxStateSuccess},
+		{Hash: "0x1", Height: 1, ChainID: 1, ToAddress: me, FromAddress: "0xa", Balance: "2", CoinType: types.CoinTypeERC1155,
+			TokenAddress: "0xItems", TokenIDRaw: "8", State: types.TxStateSuccess},
+	}
+	report, err := BuildReport(me, types.TaxLotMethodFIFO, txs)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Assets, 2, "one asset per token ID") {
+		assert.Equal(t, "0xitems", report.Assets[0].TokenAddress)
+		assert.Equal(t, "7", report.Assets[0].TokenIDRaw)
+		assert.Equal(t, "8", report.Assets[1].TokenIDRaw)
+		assert.Equal(t, "2", report.Assets[1].Lots[0].QuantityRaw)
+	}
+}
+
+func TestBuildReport_InvalidMethod(t *testing.T) {
+	_, err := BuildReport(me, "hifo", history())
+	assert.Error(t, err)
+}
+
+// stubHistory is a fixed TransactionHistoryInterface for exporter tests.
+type stubHistory struct {
+	txs       []types.Transaction
+	truncated bool
+	err       error
+}
+
+func (s *stubHistory) GetTransactionHistory(*types.TransactionQueryParams) ([]types.Transaction, bool, error) {
+	return s.txs, !s.truncated, s.err
+}
+
+func waitForJob(t *testing.T, e *Exporter, id string) types.ExportJob {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := e.Get(id)
+		assert.True(t, ok)
+		if job.Status == types.ExportStatusDone || job.Status == types.ExportStatusFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return types.ExportJob{}
+}
+
+func TestExporter_Lifecycle(t *testing.T) {
+	e := NewExporter(&stubHistory{txs: history()}, 1, 10)
+	job := e.Submit(&types.TaxLotExportParams{Address: me, Method: types.TaxLotMethodFIFO})
+	assert.Equal(t, types.ExportStatusPending, job.Status)
+
+	done := waitForJob(t, e, job.ID)
+	assert.Equal(t, types.ExportStatusDone, done.Status)
+	assert.NotNil(t, done.Report)
+	assert.False(t, done.Report.Truncated)
+}
+
+func TestExporter_FlagsTruncatedHistory(t *testing.T) {
+	e := NewExporter(&stubHistory{txs: history(), truncated: true}, 1, 10)
+	job := e.Submit(&types.TaxLotExportParams{Address: me, Method: types.TaxLotMethodFIFO})
+	done := waitForJob(t, e, job.ID)
+	assert.Equal(t, types.ExportStatusDone, done.Status)
+	assert.True(t, done.Report.Truncated)
+}
+
+func TestExporter_FailureAndEviction(t *testing.T) {
+	e := NewExporter(&stubHistory{err: errors.New("boom")}, 1, 1)
+	first := e.Submit(&types.TaxLotExportParams{Address: me, Method: types.TaxLotMethodFIFO})
+	failed := waitForJob(t, e, first.ID)
+	assert.Equal(t, types.ExportStatusFailed, failed.Status)
+	assert.Equal(t, "boom", failed.Error)
+
+	e.Submit(&types.TaxLotExportParams{Address: me, Method: types.TaxLotMethodFIFO})
+	assert.Eventually(t, func() bool {
+		_, ok := e.Get(first.ID)
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond, "oldest job should be evicted once finished")
+}
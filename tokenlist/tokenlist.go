@@ -0,0 +1,150 @@
+// Package tokenlist fills in token icons from token lists in the Uniswap
+// token-list format (https://tokenlists.org), loaded from the URLs and files
+// of the token_lists config and refreshed periodically. Providers such as
+// Blockscan return no icon for token transfers; the lists provide one for
+// every token they know.
+package tokenlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultRefreshMinutes = 60
+	// ipfsGateway serves the ipfs:// logo URIs common in token lists.
+	ipfsGateway = "https://ipfs.io/ipfs/"
+)
+
+// tokenList is the part of a token list used here.
+type tokenList struct {
+	Tokens []struct {
+		ChainID int64  `json:"chainId"`
+		Address string `json:"address"`
+		LogoURI string `json:"logoURI"`
+	} `json:"tokens"`
+}
+
+var (
+	mu      sync.RWMutex
+	sources = make(map[string]map[string]string) // source → "chainID:address" → icon URL
+	icons   = make(map[string]string)            // merged over sources, first source wins
+)
+
+// Start loads the configured lists in the background and reloads them every
+// refresh_minutes. It does nothing when no list is configured.
+func Start() {
+	cfg := config.Current().TokenLists
+	if len(cfg.Sources) == 0 {
+		return
+	}
+	minutes := cfg.RefreshMinutes
+	if minutes <= 0 {
+		minutes = defaultRefreshMinutes
+	}
+	interval := time.Duration(minutes) * time.Minute
+
+	go func() {
+		Refresh()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			Refresh()
+		}
+	}()
+	logger.Log.Info().Int("lists", len(cfg.Sources)).Dur("interval", interval).Msg("Token list refresh scheduled")
+}
+
+// Refresh reloads every configured list. A list that fails to load keeps
+// the tokens of its last successful load. It returns the number of tokens
+// with an icon.
+func Refresh() int {
+	loaded := make(map[string]map[string]string)
+	for _, src := range config.Current().TokenLists.Sources {
+		list, err := load(src)
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("source", src).Msg("Failed to load token list, keeping the previous one")
+			mu.RLock()
+			list = sources[src]
+			mu.RUnlock()
+		}
+		loaded[src] = list
+	}
+
+	merged := make(map[string]string)
+	for _, src := range config.Current().TokenLists.Sources {
+		for key, icon := range loaded[src] {
+			if _, ok := merged[key]; !ok {
+				merged[key] = icon
+			}
+		}
+	}
+
+	mu.Lock()
+	sources, icons = loaded, merged
+	mu.Unlock()
+	logger.Log.Debug().Int("tokens", len(merged)).Msg("Token lists refreshed")
+	return len(merged)
+}
+
+// IconURL returns the icon of a token contract on a chain, or "" when no
+// list has one.
+func IconURL(chainID int64, address string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return icons[key(chainID, address)]
+}
+
+// FillIcons sets the icon of the token rows that have none.
+func FillIcons(txs []types.Transaction) {
+	for i, tx := range txs {
+		if tx.IconURL == "" && types.IsTokenCoinType(tx.CoinType) {
+			txs[i].IconURL = IconURL(tx.ChainID, tx.TokenAddress)
+		}
+	}
+}
+
+// load reads one list from a URL or a file and indexes its icons.
+func load(src string) (map[string]string, error) {
+	var list tokenList
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		if err := utils.DoHttpRequestWithLogging("GET", "tokenlist", src, nil, nil, &list); err != nil {
+			return nil, err
+		}
+	} else {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid token list: %w", err)
+		}
+	}
+
+	out := make(map[string]string, len(list.Tokens))
+	for _, t := range list.Tokens {
+		if t.LogoURI == "" || t.Address == "" {
+			continue
+		}
+		icon := t.LogoURI
+		if cid, ok := strings.CutPrefix(icon, "ipfs://"); ok {
+			icon = ipfsGateway + cid
+		}
+		out[key(t.ChainID, t.Address)] = icon
+	}
+	return out, nil
+}
+
+func key(chainID int64, address string) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(address))
+}
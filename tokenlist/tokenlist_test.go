@@ -0,0 +1,65 @@
+package tokenlist
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestRefreshAndFillIcons(t *testing.T) {
+	orig := config.Current()
+	t.Cleanup(func() {
+		config.SetCurrentConfig(orig)
+		sources, icons = make(map[string]map[string]string), make(map[string]string)
+	})
+
+	up := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, `{"name":"remote","tokens":[
+			{"chainId":1,"address":"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48","logoURI":"https://example.com/usdc.png"},
+			{"chainId":1,"address":"0xdac17f958d2ee523a2206206994597c13d831ec7","logoURI":"ipfs://QmUSDT"},
+			{"chainId":1,"address":"0x6b175474e89094c44da98b954eedeac495271d0f"}]}`)
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "local.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"tokens":[
+		{"chainId":1,"address":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","logoURI":"https://local/usdc.png"},
+		{"chainId":56,"address":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48","logoURI":"https://local/bsc-usdc.png"}]}`), 0o600))
+
+	cfg := orig
+	cfg.TokenLists = types.TokenListConfig{Sources: []string{srv.URL, file}}
+	config.SetCurrentConfig(cfg)
+
+	assert.Equal(t, 3, Refresh())
+	assert.Equal(t, "https://example.com/usdc.png", IconURL(1, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"), "the first list wins")
+	assert.Equal(t, "https://ipfs.io/ipfs/QmUSDT", IconURL(1, "0xDAC17F958D2EE523A2206206994597C13D831EC7"))
+	assert.Equal(t, "https://local/bsc-usdc.png", IconURL(56, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"))
+	assert.Empty(t, IconURL(1, "0x6b175474e89094c44da98b954eedeac495271d0f"), "tokens without logo")
+
+	txs := []types.Transaction{
+		{ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7"},
+		{ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", IconURL: "https://provider/usdc.png"},
+		{ChainID: 1, CoinType: types.CoinTypeNative},
+	}
+	FillIcons(txs)
+	assert.Equal(t, "https://ipfs.io/ipfs/QmUSDT", txs[0].IconURL)
+	assert.Equal(t, "https://provider/usdc.png", txs[1].IconURL, "provider icons are kept")
+	assert.Empty(t, txs[2].IconURL)
+
+	// A list that fails to load keeps its previous tokens.
+	up = false
+	assert.Equal(t, 3, Refresh())
+	assert.Equal(t, "https://ipfs.io/ipfs/QmUSDT", IconURL(1, "0xdac17f958d2ee523a2206206994597c13d831ec7"))
+}
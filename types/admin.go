@@ -0,0 +1,23 @@
+package types
+
+// APIResponse is the common code/message envelope used by admin endpoints
+// and middleware rejections.
+type APIResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// WhoAmIResult describes the API key used for the current request.
+type WhoAmIResult struct {
+	AuthEnabled bool     `json:"authEnabled"`
+	Name        string   `json:"name,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+}
+
+// EffectiveConfig is the merged runtime configuration with secrets redacted,
+// together with the layers it was built from (lowest precedence first).
+type EffectiveConfig struct {
+	Layers   []string               `json:"layers"`
+	Settings map[string]interface{} `json:"settings"`
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"fmt"
+
+	"tx-aggregator/softjson"
+)
+
+// Alchemy asset transfer categories.
+const (
+	AlchemyCategoryExternal = "external"
+	AlchemyCategoryInternal = "internal"
+	AlchemyCategoryERC20    = "erc20"
+	AlchemyCategoryERC721   = "erc721"
+)
+
+// AlchemyRequest is a JSON-RPC request to an Alchemy endpoint.
+type AlchemyRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+// AlchemyAssetTransfersParams is the single parameter object of
+// alchemy_getAssetTransfers. Exactly one of FromAddress / ToAddress is set.
+type AlchemyAssetTransfersParams struct {
+	FromBlock    string   `json:"fromBlock"`
+	ToBlock      string   `json:"toBlock"`
+	FromAddress  string   `json:"fromAddress,omitempty"`
+	ToAddress    string   `json:"toAddress,omitempty"`
+	Category     []string `json:"category"`
+	Order        string   `json:"order"`
+	WithMetadata bool     `json:"withMetadata"`
+	MaxCount     string   `json:"maxCount,omitempty"` // Hex page size, at most 0x3e8
+	PageKey      string   `json:"pageKey,omitempty"`
+}
+
+// AlchemyAssetTransfersResponse is the response of alchemy_getAssetTransfers.
+type AlchemyAssetTransfersResponse struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Result  struct {
+		Transfers softjson.Slice[AlchemyTransfer] `json:"transfers"`
+		PageKey   string                          `json:"pageKey"` // Empty on the last page
+	} `json:"result"`
+	// Error is populated when the request fails.
+	Error *AlchemyError `json:"error,omitempty"`
+}
+
+// AlchemyTransfer is one asset movement returned by alchemy_getAssetTransfers.
+type AlchemyTransfer struct {
+	BlockNum      string `json:"blockNum"` // Hex block number
+	UniqueID      string `json:"uniqueId"` // Unique per transfer, e.g. "<hash>:log:<index>"
+	Hash          string `json:"hash"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Asset         string `json:"asset"` // Token symbol (or native symbol)
+	Category      string `json:"category"`
+	ERC721TokenID string `json:"erc721TokenId"` // Hex token ID for erc721 transfers
+	RawContract   struct {
+		Value   string `json:"value"`   // Hex raw amount
+		Address string `json:"address"` // Token contract, empty for native / internal
+		Decimal string `json:"decimal"` // Hex decimals
+	} `json:"rawContract"`
+	Metadata struct {
+		BlockTimestamp string `json:"blockTimestamp"` // RFC 3339
+	} `json:"metadata"`
+}
+
+// AlchemyError represents the JSON-RPC error object returned by Alchemy.
+type AlchemyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *AlchemyError) Error() string {
+	return fmt.Sprintf("Alchemy API error %d: %s", e.Code, e.Message)
+}
@@ -0,0 +1,23 @@
+package types
+
+// AllowanceParams describes a live ERC-20 allowance lookup (GET /allowance).
+type AllowanceParams struct {
+	ChainName string
+	Token     string
+	Owner     string
+	Spender   string
+}
+
+// Allowance is the current ERC-20 allowance of a spender, read from the chain
+// rather than from historical Approval events.
+type Allowance struct {
+	ChainName    string `json:"chainName"`
+	ChainID      int64  `json:"chainId"`
+	TokenAddress string `json:"tokenAddress"`
+	Owner        string `json:"owner"`
+	Spender      string `json:"spender"`
+	Allowance    string `json:"allowance"` // Raw integer, in the token's smallest unit
+	// Unlimited is set for allowances of at least 2^255, the range wallets
+	// use for "infinite" approvals.
+	Unlimited bool `json:"unlimited"`
+}
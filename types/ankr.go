@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"tx-aggregator/softjson"
 )
 
 // AnkrTransactionRequest represents the request structure for Ankr API transaction queries
@@ -18,7 +19,8 @@ type AnkrTransactionResponse struct {
 	JSONRPC string `json:"jsonrpc"` // JSON-RPC version
 	ID      int    `json:"id"`      // Request identifier
 	Result  struct {
-		Transactions []AnkrTransaction `json:"transactions"` // List of transactions
+		NextPageToken string                          `json:"nextPageToken"` // Token for pagination
+		Transactions  softjson.Slice[AnkrTransaction] `json:"transactions"`  // List of transactions
 	} `json:"result"`
 	// Error is populated when the request fails.
 	Error *AnkrError `json:"error,omitempty"`
@@ -29,13 +31,30 @@ type AnkrTokenTransferResponse struct {
 	JSONRPC string `json:"jsonrpc"` // JSON-RPC version
 	ID      int    `json:"id"`      // Request identifier
 	Result  struct {
-		NextPageToken string          `json:"nextPageToken"` // Token for pagination
-		Transfers     []TokenTransfer `json:"transfers"`     // List of token transfers
+		NextPageToken string                        `json:"nextPageToken"` // Token for pagination
+		Transfers     softjson.Slice[TokenTransfer] `json:"transfers"`     // List of token transfers
 	} `json:"result"`
 	// Error is populated when the request fails.
 	Error *AnkrError `json:"error,omitempty"`
 }
 
+// AnkrBlockchainStatsResponse represents the response of ankr_getBlockchainStats
+type AnkrBlockchainStatsResponse struct {
+	JSONRPC string `json:"jsonrpc"` // JSON-RPC version
+	ID      int    `json:"id"`      // Request identifier
+	Result  struct {
+		Stats []AnkrBlockchainStats `json:"stats"` // One entry per requested blockchain
+	} `json:"result"`
+	// Error is populated when the request fails.
+	Error *AnkrError `json:"error,omitempty"`
+}
+
+// AnkrBlockchainStats holds the indexing state of one blockchain
+type AnkrBlockchainStats struct {
+	Blockchain        string `json:"blockchain"`        // Ankr chain name, e.g. "eth"
+	LatestBlockNumber int64  `json:"latestBlockNumber"` // Newest block indexed by Ankr
+}
+
 // AnkrError represents the error object returned by the Ankr API.
 type AnkrError struct {
 	Code    int             `json:"code"`
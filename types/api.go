@@ -5,4 +5,32 @@ type TransactionQueryParams struct {
 	Address      string
 	TokenAddress string
 	ChainNames   []string
+	// MaxPages overrides the providers' max_pages when > 0 (backfill deep pagination).
+	MaxPages int64
+	// StartBlock and EndBlock limit the result to heights within
+	// [StartBlock, EndBlock]; zero leaves that end open.
+	StartBlock int64
+	EndBlock   int64
+	// GroupByChain applies the response limit to each chain separately, for
+	// responses grouped by chain (groupBy=chain).
+	GroupByChain bool
+	// MinValue drops the value movements smaller than this decimal amount,
+	// in units of each row's coin (minValue); empty keeps every row.
+	MinValue string
+	// HideZeroValue drops the native transfers of 0, plain contract calls
+	// that move no value themselves (hideZeroValue=true).
+	HideZeroValue bool
+	// RequestID correlates the logs and provider requests made for one API
+	// request; empty for background jobs.
+	RequestID string
+	// Timings receives the time spent in each phase of the request, for
+	// the slow-request log; nil for background jobs.
+	Timings *RequestTimings
+}
+
+// CacheInvalidation is the result of invalidating the cache of an address.
+type CacheInvalidation struct {
+	Address     string   `json:"address"`
+	ChainNames  []string `json:"chainNames"`
+	KeysRemoved int64    `json:"keysRemoved"`
 }
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/json"
+
+	"tx-aggregator/softjson"
+)
+
+// AptosActivitiesData is the data of an Aptos indexer query over
+// fungible_asset_activities.
+type AptosActivitiesData struct {
+	Activities softjson.Slice[AptosActivity] `json:"fungible_asset_activities"`
+}
+
+// AptosActivity is one balance change of one account: a withdrawal, a
+// deposit or the gas fee. Coins (v1) and fungible assets (v2) are both
+// reported here. A transfer is a withdrawal followed by a deposit of the same
+// asset in the same transaction, in event order.
+type AptosActivity struct {
+	TransactionVersion   json.Number         `json:"transaction_version"`
+	EventIndex           json.Number         `json:"event_index"` // -1 for the gas fee
+	BlockHeight          json.Number         `json:"block_height"`
+	OwnerAddress         string              `json:"owner_address"`
+	Amount               json.Number         `json:"amount"` // Raw integer amount
+	AssetType            string              `json:"asset_type"`
+	Type                 string              `json:"type"` // e.g. 0x1::coin::WithdrawEvent, 0x1::fungible_asset::Deposit
+	IsGasFee             bool                `json:"is_gas_fee"`
+	IsTransactionSuccess bool                `json:"is_transaction_success"`
+	TransactionTimestamp string              `json:"transaction_timestamp"` // UTC, no zone, e.g. 2024-05-01T12:34:56.123456
+	Metadata             *AptosAssetMetadata `json:"metadata"`
+}
+
+// AptosAssetMetadata describes a coin or fungible asset.
+type AptosAssetMetadata struct {
+	Symbol   string `json:"symbol"`
+	Decimals int64  `json:"decimals"`
+}
@@ -0,0 +1,14 @@
+package types
+
+import "encoding/json"
+
+// ArchivedPayload is one raw provider response as stored in the archive
+// (gzip-compressed JSON). Credentials in the URL are redacted.
+type ArchivedPayload struct {
+	Label       string          `json:"label"` // Request label, e.g. "ankr.getTransactionsByAddress"
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	RequestBody json.RawMessage `json:"requestBody,omitempty"`
+	Response    json.RawMessage `json:"response"`
+	FetchedAt   int64           `json:"fetchedAt"` // Unix seconds
+}
@@ -0,0 +1,47 @@
+package types
+
+// Roles that can be attached to an API key.
+const (
+	// RoleRead grants access to data endpoints and read-only admin introspection.
+	RoleRead = "read"
+	// RoleAdmin grants every permission, including mutating admin endpoints.
+	RoleAdmin = "admin"
+	// RoleExport grants access to asynchronous export jobs.
+	RoleExport = "export"
+	// RoleInvalidate lets integrators drop the cached transactions of an
+	// address, e.g. right after broadcasting a transaction.
+	RoleInvalidate = "invalidate"
+	// RoleWatch lets integrators register addresses to watch.
+	RoleWatch = "watch"
+)
+
+// DefaultAPIKeyHeader is the request header carrying the API key.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// RequestIDHeader carries the ID of an API request, both on the request and
+// response of the API and on the provider requests made to serve it.
+const RequestIDHeader = "X-Request-ID"
+
+// AuthConfig holds API key authentication settings.
+type AuthConfig struct {
+	Enabled bool           `mapstructure:"enabled"` // When false every request is allowed
+	Header  string         `mapstructure:"header"`  // Header carrying the key (default X-API-Key)
+	Keys    []APIKeyConfig `mapstructure:"keys"`
+}
+
+// APIKeyConfig describes one API key and the roles granted to it.
+type APIKeyConfig struct {
+	Name  string   `mapstructure:"name"`  // Human-readable owner, used in logs
+	Key   string   `mapstructure:"key"`   // Secret value sent by the client
+	Roles []string `mapstructure:"roles"` // Any of read, admin, export, invalidate, watch
+}
+
+// HasRole reports whether the key grants role. Admin implies every role.
+func (k APIKeyConfig) HasRole(role string) bool {
+	for _, r := range k.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package types
+
+// BackfillJob describes a backfill of one newly added chain over the
+// configured watch list. Status uses the ExportStatus* job states.
+type BackfillJob struct {
+	ID           string `json:"id"`
+	ChainName    string `json:"chainName"`
+	Status       string `json:"status"`
+	Addresses    int    `json:"addresses"`    // Watched addresses to warm
+	Done         int    `json:"done"`         // Addresses cached successfully
+	Failed       int    `json:"failed"`       // Addresses whose fetch failed
+	Transactions int    `json:"transactions"` // Transactions cached so far
+	CreatedTime  int64  `json:"createdTime"`
+	UpdatedTime  int64  `json:"updatedTime"`
+}
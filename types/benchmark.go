@@ -0,0 +1,27 @@
+package types
+
+// BenchmarkReport ranks the providers able to serve each chain by how
+// complete their results are and how fast they answer.
+type BenchmarkReport struct {
+	GeneratedTime int64            `json:"generatedTime"` // Unix timestamp of the run
+	Chains        []ChainBenchmark `json:"chains"`
+}
+
+// ChainBenchmark holds the ranked providers of one chain, best first.
+type ChainBenchmark struct {
+	ChainName string              `json:"chainName"`
+	Addresses int                 `json:"addresses"` // Sample addresses queried
+	Providers []ProviderBenchmark `json:"providers"`
+}
+
+// ProviderBenchmark aggregates one provider's results over the sample.
+// Completeness is the share of the union of all providers' transactions that
+// this provider returned, averaged over the sample (failed calls count as 0).
+type ProviderBenchmark struct {
+	Provider     string  `json:"provider"`
+	Rank         int     `json:"rank"` // 1 = best
+	Completeness float64 `json:"completeness"`
+	AvgLatencyMs int64   `json:"avgLatencyMs"`
+	MaxLatencyMs int64   `json:"maxLatencyMs"`
+	Errors       int     `json:"errors"`
+}
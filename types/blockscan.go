@@ -1,5 +1,7 @@
 package types
 
+import "tx-aggregator/softjson"
+
 // -----------------------------------------------------------------------------
 // JSON response structs (minimal fields only)
 // -----------------------------------------------------------------------------
@@ -10,21 +12,21 @@ const (
 )
 
 type BlockscanNormalTxResp struct {
-	Status  string            `json:"status"` // 0 err, 1 ok
-	Message string            `json:"message"`
-	Result  []BlockscanTxItem `json:"result"`
+	Status  string                          `json:"status"` // 0 err, 1 ok
+	Message string                          `json:"message"`
+	Result  softjson.Slice[BlockscanTxItem] `json:"result"`
 }
 
 type BlockscanInternalTxResp struct {
-	Status  string                  `json:"status"`
-	Message string                  `json:"message"`
-	Result  []BlockscanInternalItem `json:"result"`
+	Status  string                                `json:"status"`
+	Message string                                `json:"message"`
+	Result  softjson.Slice[BlockscanInternalItem] `json:"result"`
 }
 
 type BlockscanTokenTxResp struct {
-	Status  string                 `json:"status"`
-	Message string                 `json:"message"`
-	Result  []BlockscanTokenTxItem `json:"result"`
+	Status  string                               `json:"status"`
+	Message string                               `json:"message"`
+	Result  softjson.Slice[BlockscanTokenTxItem] `json:"result"`
 }
 
 type BlockscanTxItem struct {
@@ -69,6 +71,7 @@ type BlockscanTokenTxItem struct {
 	TokenSymbol      string `json:"tokenSymbol"`
 	TokenDecimal     string `json:"tokenDecimal"`
 	TransactionIndex string `json:"transactionIndex"`
+	LogIndex         string `json:"logIndex"` // Not returned by every Etherscan-compatible API
 	Gas              string `json:"gas"`
 	GasPrice         string `json:"gasPrice"`
 	GasUsed          string `json:"gasUsed"`
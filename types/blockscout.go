@@ -1,29 +1,40 @@
 package types
 
+import "tx-aggregator/softjson"
+
 // ===== NORMAL TRANSACTIONS =====
 
 // BlockscoutTransactionResponse represents the response from
 // /addresses/{address}/transactions endpoint, listing normal transactions.
 type BlockscoutTransactionResponse struct {
-	Items []BlockscoutTransaction `json:"items"`
+	Items softjson.Slice[BlockscoutTransaction] `json:"items"`
 }
 
 // BlockscoutTransaction represents a single normal transaction in the Tantin response.
 type BlockscoutTransaction struct {
-	Hash             string                     `json:"hash"`              // Transaction hash
-	BlockHash        string                     `json:"block_hash"`        // Block hash
-	BlockNumber      int64                      `json:"block_number"`      // Block number as integer
-	Value            string                     `json:"value"`             // Value transferred in Wei
-	GasUsed          string                     `json:"gas_used"`          // Gas used for the transaction
-	GasLimit         string                     `json:"gas_limit"`         // Gas limit set by the sender
-	GasPrice         string                     `json:"gas_price"`         // Gas price used
-	Timestamp        string                     `json:"timestamp"`         // ISO timestamp, e.g. "2025-04-16T06:45:02.000000Z"
-	Nonce            int64                      `json:"nonce"`             // Nonce of the transaction
-	Status           string                     `json:"status"`            // "ok" for success, others for failure
-	Method           string                     `json:"method"`            // Method name if known (contract calls)
-	From             BlockscoutAddressContainer `json:"from"`              // Sender address container
-	To               BlockscoutAddressContainer `json:"to"`                // Recipient address container
-	TransactionTypes []string                   `json:"transaction_types"` // Types of transaction, e.g. ["contract_call", "token_transfer"]
+	Hash             string                      `json:"hash"`              // Transaction hash
+	BlockHash        string                      `json:"block_hash"`        // Block hash
+	BlockNumber      int64                       `json:"block_number"`      // Block number as integer
+	Position         int64                       `json:"position"`          // Index of the transaction in its block
+	Value            string                      `json:"value"`             // Value transferred in Wei
+	GasUsed          string                      `json:"gas_used"`          // Gas used for the transaction
+	GasLimit         string                      `json:"gas_limit"`         // Gas limit set by the sender
+	GasPrice         string                      `json:"gas_price"`         // Gas price used
+	Timestamp        string                      `json:"timestamp"`         // ISO timestamp, e.g. "2025-04-16T06:45:02.000000Z"
+	Nonce            int64                       `json:"nonce"`             // Nonce of the transaction
+	Status           string                      `json:"status"`            // "ok" for success, others for failure
+	Method           string                      `json:"method"`            // Method name if known (contract calls)
+	From             BlockscoutAddressContainer  `json:"from"`              // Sender address container
+	To               BlockscoutAddressContainer  `json:"to"`                // Recipient address container; empty for a contract creation
+	CreatedContract  *BlockscoutAddressContainer `json:"created_contract"`  // Contract deployed, if any
+	L1Fee            string                      `json:"l1_fee"`            // OP Stack chains: L1 data fee in wei
+	Arbitrum         *BlockscoutArbitrumFields   `json:"arbitrum"`          // Arbitrum chains only
+	TransactionTypes []string                    `json:"transaction_types"` // Types of transaction, e.g. ["contract_call", "token_transfer"]
+}
+
+// BlockscoutArbitrumFields holds the Arbitrum-specific fields of a transaction.
+type BlockscoutArbitrumFields struct {
+	GasUsedForL1 string `json:"gas_used_for_l1"` // Part of gas_used paying for L1 data
 }
 
 // BlockscoutAddressContainer represents a simple address object with only hash (used in normal txs).
@@ -31,18 +42,27 @@ type BlockscoutAddressContainer struct {
 	Hash string `json:"hash"` // Address hash
 }
 
+// ===== BLOCKS =====
+
+// BlockscoutBlock is one entry of the /main-page/blocks endpoint, which lists
+// the newest indexed blocks first.
+type BlockscoutBlock struct {
+	Height int64 `json:"height"` // Block number
+}
+
 // ===== TOKEN TRANSFERS =====
 
 // BlockscoutTokenTransferResponse represents the response from
 // /addresses/{address}/token-transfers endpoint.
 type BlockscoutTokenTransferResponse struct {
-	Items []BlockscoutTokenTransfer `json:"items"`
+	Items softjson.Slice[BlockscoutTokenTransfer] `json:"items"`
 }
 
 // BlockscoutTokenTransfer represents a single token transfer event.
 type BlockscoutTokenTransfer struct {
 	BlockHash       string                     `json:"block_hash"`       // Block hash
 	BlockNumber     int64                      `json:"block_number"`     // Block number
+	LogIndex        int64                      `json:"log_index"`        // Index of the Transfer event in its block
 	From            BlockscoutAddressContainer `json:"from"`             // Sender address
 	To              BlockscoutAddressContainer `json:"to"`               // Recipient address
 	Timestamp       string                     `json:"timestamp"`        // ISO timestamp
@@ -72,7 +92,7 @@ type BlockscoutTokenAmount struct {
 // BlockscoutInternalTxResponse represents the response from
 // /addresses/{address}/internal-transactions endpoint.
 type BlockscoutInternalTxResponse struct {
-	Items []BlockscoutInternalTx `json:"items"`
+	Items softjson.Slice[BlockscoutInternalTx] `json:"items"`
 }
 
 // BlockscoutInternalTx represents a single internal transaction.
@@ -107,7 +127,7 @@ type BlockscoutAddressDetails struct {
 
 // BlockscoutLogResponse represents the response from /addresses/{address}/logs endpoint.
 type BlockscoutLogResponse struct {
-	Items []BlockscoutLog `json:"items"`
+	Items softjson.Slice[BlockscoutLog] `json:"items"`
 }
 
 // BlockscoutLog represents an individual log/event emitted by a smart contract.
@@ -161,6 +181,8 @@ type RpcReceipt struct {
 	GasUsed           string          `json:"gasUsed"`           // Gas used by this transaction
 	Logs              []RpcReceiptLog `json:"logs"`              // Event logs emitted
 	LogsBloom         string          `json:"logsBloom"`         // Bloom filter for quick lookup
+	L1Fee             string          `json:"l1Fee"`             // OP Stack: L1 data fee, on top of gasUsed × price
+	GasUsedForL1      string          `json:"gasUsedForL1"`      // Arbitrum: part of gasUsed paying for L1 data
 	Status            string          `json:"status"`            // "0x1" for success, "0x0" for failure
 	To                string          `json:"to"`                // Recipient address
 	TransactionHash   string          `json:"transactionHash"`   // Transaction hash
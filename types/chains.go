@@ -0,0 +1,13 @@
+package types
+
+// ChainRegistry holds chain metadata that is owned separately from the service
+// configuration and loaded from its own Consul KV path. When present, its
+// fields replace chain_names, native_tokens, native_decimals and ankr.chain_ids
+// of Config.
+type ChainRegistry struct {
+	ChainNames     map[string]int64  `mapstructure:"chain_names"`     // Chain name → chain ID
+	NativeTokens   map[string]string `mapstructure:"native_tokens"`   // Chain ID (string) → native token symbol
+	NativeDecimals map[string]int64  `mapstructure:"native_decimals"` // Chain ID (string) → native token decimals when not 18 (e.g. 6 for Tron)
+	AnkrChainIDs   map[string]int64  `mapstructure:"ankr_chain_ids"`  // Ankr blockchain name → chain ID
+	ExplorerURLs   map[string]string `mapstructure:"explorer_urls"`   // Chain name → block explorer base URL
+}
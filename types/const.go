@@ -8,15 +8,27 @@ const (
 const (
 	// CoinTypeNative represents native cryptocurrency (e.g., ETH, BNB)
 	CoinTypeNative = 1
-	// CoinTypeToken represents ERC20/ERC721 tokens
+	// CoinTypeToken represents ERC20 tokens
 	CoinTypeToken = 2
 	// CoinTypeInternal represents internal transactions (e.g., contract interactions)
 	CoinTypeInternal = 3
+	// CoinTypeERC1155 represents ERC-1155 multi-token transfers, whose token
+	// ID is in TokenIDRaw and whose amount has no decimals
+	CoinTypeERC1155 = 4
+	// CoinTypeERC721 represents ERC-721 NFT transfers, which move the single
+	// token whose ID is in TokenIDRaw
+	CoinTypeERC721 = 5
 
 	// NativeTokenName is the name for native tokens
 	NativeTokenName = "native"
 )
 
+// IsTokenCoinType reports whether coinType moves a token contract's asset
+// rather than the chain's coin.
+func IsTokenCoinType(coinType int) bool {
+	return coinType == CoinTypeToken || coinType == CoinTypeERC721 || coinType == CoinTypeERC1155
+}
+
 // TxType represents the type of transaction
 const (
 	TxTypeUnknown = 0 // native token transfer also as transfer
@@ -26,6 +38,52 @@ const (
 	TxTypeApprove = 1
 	// TxTypeInternal represents an internal transaction (e.g., contract interaction)
 	TxTypeInternal = 2
+	// TxTypeWrap represents a deposit of native coin into its wrapped-native
+	// token contract (e.g. ETH into WETH)
+	TxTypeWrap = 3
+	// TxTypeUnwrap represents a withdrawal of native coin from its
+	// wrapped-native token contract
+	TxTypeUnwrap = 4
+	// TxTypeSwap represents a DEX swap, detailed in Transaction.Swap
+	TxTypeSwap = 5
+	// TxTypeBridge represents a transfer into or out of a cross-chain bridge,
+	// detailed in Transaction.Bridge
+	TxTypeBridge = 6
+	// TxTypeContractCreation represents the deployment of a contract, whose
+	// address is in Transaction.ContractAddress
+	TxTypeContractCreation = 7
+)
+
+// Swap protocols recognized without configuration (see swap_events for
+// others).
+const (
+	// SwapProtocolUniswapV2 is the Swap event of Uniswap V2 pairs and forks
+	SwapProtocolUniswapV2 = "uniswap_v2"
+	// SwapProtocolUniswapV3 is the Swap event of Uniswap V3 pools and forks
+	SwapProtocolUniswapV3 = "uniswap_v3"
+)
+
+// Sub-actions of a batch call, see SubAction.
+const (
+	// SubActionTransfer is a token transfer (ERC-20, ERC-721 or ERC-1155)
+	SubActionTransfer = "transfer"
+	// SubActionApprove is a token approval
+	SubActionApprove = "approve"
+	// SubActionSwap is a DEX swap
+	SubActionSwap = "swap"
+	// SubActionWrap is a deposit into the wrapped-native contract
+	SubActionWrap = "wrap"
+	// SubActionUnwrap is a withdrawal from the wrapped-native contract
+	SubActionUnwrap = "unwrap"
+)
+
+// Duplicate precedence decides which representation survives when the same
+// native value movement is reported both as a normal and an internal transfer.
+const (
+	// PrecedenceNormal keeps the normal (top-level) transaction row
+	PrecedenceNormal = "normal"
+	// PrecedenceInternal keeps the internal transaction row
+	PrecedenceInternal = "internal"
 )
 
 // TransType represents the direction of transaction
@@ -46,3 +104,53 @@ const (
 	// TxStateFail represents a failed transaction
 	TxStateFail = 0
 )
+
+// Enrichment flags reported in Transaction.Enrichment. A set bit means the
+// enrichment ran and succeeded for that row, so a missing field can be told
+// apart from a real zero and refreshed selectively.
+const (
+	// EnrichmentGasPatched means gas used / gas price are known, either from
+	// the transaction itself or patched in from its parent transaction.
+	EnrichmentGasPatched = 1 << iota
+	// EnrichmentLogsScanned means the transaction's event logs were scanned
+	// for approvals and other ERC-20 events.
+	EnrichmentLogsScanned
+	// EnrichmentPriceAttached means a fiat price was attached. Reserved: no
+	// price source is wired in yet.
+	EnrichmentPriceAttached
+	// EnrichmentMetadataResolved means the token (or native coin) name was resolved.
+	EnrichmentMetadataResolved
+)
+
+// Address families: the address formats accepted by /transactions, each with
+// its own validator in the utils address registry. Every chain speaks exactly
+// one.
+const (
+	AddressFamilyEVM  = "evm"  // "0x" + 40 hex digits
+	AddressFamilyUTXO = "utxo" // bech32 or base58 Bitcoin-style, served by Esplora
+	AddressFamilyTron = "tron" // base58 "T…", served by TronGrid
+	AddressFamilyTON  = "ton"  // raw "0:<hex>" or user-friendly, served by toncenter
+	// AddressFamilyStarknet is a felt ("0x" + up to 64 hex digits), served by Voyager.
+	AddressFamilyStarknet = "starknet"
+	// AddressFamilyAptos is "0x" + up to 64 hex digits, served by the Aptos indexer.
+	AddressFamilyAptos = "aptos"
+)
+
+// IsAddressFamily reports whether family is one of the AddressFamily constants.
+func IsAddressFamily(family string) bool {
+	switch family {
+	case AddressFamilyEVM, AddressFamilyUTXO, AddressFamilyTron, AddressFamilyTON, AddressFamilyStarknet, AddressFamilyAptos:
+		return true
+	}
+	return false
+}
+
+// Internal trace methods (internal_traces.method).
+const (
+	// TraceMethodDebug calls debug_traceTransaction with the callTracer for
+	// every transaction (Geth and most clients).
+	TraceMethodDebug = "debug_trace"
+	// TraceMethodBlock calls trace_block for every block (Erigon, Nethermind,
+	// Reth), which also finds transfers of transactions the providers missed.
+	TraceMethodBlock = "trace_block"
+)
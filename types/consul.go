@@ -8,6 +8,18 @@ type ConsulBootstrap struct {
 	Scheme     string `yaml:"scheme"`     // "http" or "https"
 	Datacenter string `yaml:"datacenter"` // Consul datacenter
 	Token      string `yaml:"token"`      // ACL token (optional)
+	// ChainRegistryKey is the KV path of the chain registry blob
+	// (default "config/chains/<env>").
+	ChainRegistryKey string `yaml:"chain_registry_key" mapstructure:"chain_registry_key"`
+}
+
+// EtcdBootstrap makes the runtime configuration and chain registry come
+// from etcd (v3 API) instead of Consul KV, under the same keys.
+type EtcdBootstrap struct {
+	Endpoints []string `yaml:"endpoints"` // e.g. "http://10.234.99.5:2379"; empty uses Consul KV
+	// ChainRegistryKey is the key of the chain registry blob
+	// (default "config/chains/<env>").
+	ChainRegistryKey string `yaml:"chain_registry_key" mapstructure:"chain_registry_key"`
 }
 
 // ServiceBootstrap holds metadata about the current service.
@@ -15,11 +27,14 @@ type ServiceBootstrap struct {
 	Name string `yaml:"name"` // e.g., "tx-aggregator"
 	IP   string `yaml:"ip"`   // Service IP; if empty, detect dynamically
 	Port int    `yaml:"port"` // Service port; 0 means use runtime port
+	// Region selects the optional region config overlay; APP_REGION overrides it.
+	Region string `yaml:"region"`
 }
 
 // BootstrapConfig is the root structure for the bootstrap configuration file.
 type BootstrapConfig struct {
 	Consul  ConsulBootstrap  `yaml:"consul"`
+	Etcd    EtcdBootstrap    `yaml:"etcd"`
 	Service ServiceBootstrap `yaml:"service"`
 }
 
@@ -32,6 +47,7 @@ type Options struct {
 	Tags       []string          // Optional: Consul Tags
 	Meta       map[string]string // Optional: Metadata
 	HealthPath string            // Health check HTTP path, e.g., "/health"
+	TLS        bool              // The service is served over HTTPS
 	Interval   time.Duration     // Check interval
 	Timeout    time.Duration     // Timeout
 	Deregister time.Duration     // Automatically deregister after continuous failures
@@ -11,6 +11,10 @@ const (
 	CodeInternalError  = 1002 // Internal server error
 	CodeProviderFailed = 1003 // Failed to get data from external provider
 	CodeTimeout        = 1004 // Request timed out
+	CodeNotFound       = 1005 // Requested resource does not exist
+	CodeUnauthorized   = 1006 // Missing or unknown API key
+	CodeForbidden      = 1007 // API key lacks the required role
+	CodeRateLimited    = 1008 // Too many requests; retry later
 )
 
 // CodeMessageMap maps error codes to their corresponding error messages
@@ -19,6 +23,10 @@ var CodeMessageMap = map[int]string{
 	CodeInvalidParam:   "invalid parameters",
 	CodeInternalError:  "internal server error",
 	CodeProviderFailed: "failed to get transactions from provider",
+	CodeNotFound:       "resource not found",
+	CodeUnauthorized:   "missing or invalid api key",
+	CodeForbidden:      "api key is not allowed to access this resource",
+	CodeRateLimited:    "too many requests, retry later",
 }
 
 // GetMessageByCode returns the error message for a given error code.
@@ -0,0 +1,34 @@
+package types
+
+// EsploraTx is a transaction as returned by Esplora-compatible APIs
+// (Blockstream Esplora, mempool.space) under /address/:address/txs.
+type EsploraTx struct {
+	TxID   string          `json:"txid"`
+	Vin    []EsploraVin    `json:"vin"`
+	Vout   []EsploraOutput `json:"vout"`
+	Weight int64           `json:"weight"`
+	Fee    int64           `json:"fee"` // Satoshis
+	Status EsploraStatus   `json:"status"`
+}
+
+// EsploraVin is a transaction input with the output it spends.
+type EsploraVin struct {
+	TxID       string         `json:"txid"`
+	Vout       int64          `json:"vout"`
+	Prevout    *EsploraOutput `json:"prevout"` // nil for coinbase inputs
+	IsCoinbase bool           `json:"is_coinbase"`
+}
+
+// EsploraOutput is a transaction output.
+type EsploraOutput struct {
+	ScriptPubKeyAddress string `json:"scriptpubkey_address"` // Empty for non-standard scripts (e.g. OP_RETURN)
+	Value               int64  `json:"value"`                // Satoshis
+}
+
+// EsploraStatus is the confirmation status of a transaction.
+type EsploraStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   int64  `json:"block_time"`
+}
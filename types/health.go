@@ -0,0 +1,41 @@
+package types
+
+// ProviderStatus is the health of one registered provider as seen by the
+// background prober.
+type ProviderStatus struct {
+	Provider            string `json:"provider"`
+	Healthy             bool   `json:"healthy"`
+	Probed              bool   `json:"probed"` // False when the provider has no health check
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	LastCheckTime       int64  `json:"lastCheckTime,omitempty"` // Unix timestamp of the last probe
+	LastLatencyMs       int64  `json:"lastLatencyMs,omitempty"`
+	LastError           string `json:"lastError,omitempty"`
+	UnhealthySince      int64  `json:"unhealthySince,omitempty"` // Unix timestamp
+}
+
+// Component states reported by the deep health check.
+const (
+	ComponentOK       = "ok"
+	ComponentFailed   = "fail"
+	ComponentSkipped  = "skipped"  // Provider without a health check
+	ComponentDegraded = "degraded" // Report status: some components failed, requests can still be served
+)
+
+// HealthReport is the response of GET /health when deep checks are enabled.
+// Status is ComponentFailed when Redis failed or when every provider of a
+// chain did (listed in DownChains), ComponentDegraded when any other
+// component failed.
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Components []ComponentHealth `json:"components"`
+	DownChains []string          `json:"downChains,omitempty"`
+	CheckedAt  int64             `json:"checkedAt"` // Unix timestamp of the check, which is cached
+}
+
+// ComponentHealth is the outcome of checking one dependency.
+type ComponentHealth struct {
+	Name      string `json:"name"` // "redis", "consul" or "provider:<key>"
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+
+	"tx-aggregator/softjson"
+)
+
+// OKLink protocol types accepted by /explorer/address/transaction-list.
+const (
+	OKLinkProtocolTransaction = "transaction"
+	OKLinkProtocolInternal    = "internal"
+	OKLinkProtocolToken20     = "token_20"
+	OKLinkProtocolToken721    = "token_721"
+)
+
+// OKLinkSuccessCode is the "code" of a successful OKLink response.
+const OKLinkSuccessCode = "0"
+
+// OKLinkTransactionListResponse is the response of
+// /api/v5/explorer/address/transaction-list.
+type OKLinkTransactionListResponse struct {
+	Code string                  `json:"code"` // "0" on success
+	Msg  string                  `json:"msg"`
+	Data []OKLinkTransactionPage `json:"data"`
+}
+
+// OKLinkTransactionPage is one page of an address's transactions.
+// OKLink paginates by page number and reports the total page count.
+type OKLinkTransactionPage struct {
+	Page             string                            `json:"page"`
+	Limit            string                            `json:"limit"`
+	TotalPage        string                            `json:"totalPage"`
+	ChainShortName   string                            `json:"chainShortName"`
+	TransactionLists softjson.Slice[OKLinkTransaction] `json:"transactionLists"`
+}
+
+// OKLinkTransaction is one row of an address transaction list. Amounts are
+// already divided by the asset's decimals.
+type OKLinkTransaction struct {
+	TxID                 string `json:"txId"`
+	MethodID             string `json:"methodId"`
+	BlockHash            string `json:"blockHash"`
+	Height               string `json:"height"`
+	TransactionTime      string `json:"transactionTime"` // Unix milliseconds
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+	Amount               string `json:"amount"`
+	TransactionSymbol    string `json:"transactionSymbol"`
+	TxFee                string `json:"txFee"`
+	State                string `json:"state"` // success, fail or pending
+	TokenID              string `json:"tokenId"`
+	TokenContractAddress string `json:"tokenContractAddress"`
+}
+
+// OKLinkTokenListResponse is the response of /api/v5/explorer/token/token-list.
+type OKLinkTokenListResponse struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		TokenList []OKLinkToken `json:"tokenList"`
+	} `json:"data"`
+}
+
+// OKLinkToken describes one token contract.
+type OKLinkToken struct {
+	Token                string `json:"token"`
+	TokenContractAddress string `json:"tokenContractAddress"`
+	Precision            string `json:"precision"`
+}
+
+// OKLinkError is returned when OKLink answers with a non-zero code.
+type OKLinkError struct {
+	Code string
+	Msg  string
+}
+
+func (e *OKLinkError) Error() string {
+	return fmt.Sprintf("OKLink API error %s: %s", e.Code, e.Msg)
+}
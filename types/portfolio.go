@@ -0,0 +1,32 @@
+package types
+
+// Portfolio summarizes an address on several chains in one response
+// (GET /portfolio): what it holds and what it did recently. Truncated is set
+// when older transactions may be missing from the history it was built
+// from, so that balances are partial.
+type Portfolio struct {
+	Address   string                    `json:"address"`
+	Chains    map[string]PortfolioChain `json:"chains"` // Chain name → summary
+	Truncated bool                      `json:"truncated"`
+}
+
+// PortfolioChain is the part of a portfolio on one chain.
+type PortfolioChain struct {
+	ChainID int64            `json:"chainId"`
+	Tokens  []PortfolioToken `json:"tokens"` // Native coin first, then by token address
+	Recent  []Transaction    `json:"recent"` // Newest first
+}
+
+// PortfolioToken is one asset of a portfolio. The balance is the net of the
+// transfers (and, for the native coin, the fees) known to the service, so it
+// is only exact when the whole history of the address is cached. Balances
+// are kept both as raw integers and as decimal strings.
+type PortfolioToken struct {
+	TokenAddress     string `json:"tokenAddress"`         // "native" for the chain's coin
+	TokenIDRaw       string `json:"tokenIdRaw,omitempty"` // ERC-1155 token ID; each ID is an asset of its own
+	TokenDisplayName string `json:"tokenDisplayName"`
+	Decimals         int64  `json:"decimals"`
+	BalanceRaw       string `json:"balanceRaw"`
+	Balance          string `json:"balance"`
+	TxCount          int    `json:"txCount"` // Successful transactions moving the asset
+}
@@ -1,5 +1,7 @@
 package types
 
+import "tx-aggregator/softjson"
+
 // ---------------------------- JSON-RPC payload/response ------------------
 
 // quickNodeTxRequest models qn_getTransactionsByAddress
@@ -15,9 +17,9 @@ type QuickNodeTxResponse struct {
 	JSONRPC string `json:"jsonrpc"`
 	ID      int    `json:"id"`
 	Result  struct {
-		Address      string                 `json:"address"`
-		EnsName      string                 `json:"ensName"`
-		Transactions []QuickNodeTransaction `json:"transactions"`
+		Address      string                               `json:"address"`
+		EnsName      string                               `json:"ensName"`
+		Transactions softjson.Slice[QuickNodeTransaction] `json:"transactions"`
 	} `json:"result"`
 }
 
@@ -57,8 +59,8 @@ type QuickNodeTokenResp struct {
 			ContractAddress string `json:"contractAddress"`
 		} `json:"token"`
 
-		Transfers  []QuickNodeTransfer `json:"transfers"`
-		PageNumber int                 `json:"pageNumber"`
+		Transfers  softjson.Slice[QuickNodeTransfer] `json:"transfers"`
+		PageNumber int                               `json:"pageNumber"`
 	} `json:"result"`
 }
 
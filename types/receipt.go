@@ -0,0 +1,32 @@
+package types
+
+// Receipt is a normalized eth_getTransactionReceipt result (GET
+// /receipt/:chain/:hash). Quantities are decimal strings and heights and
+// indexes integers, as in Transaction.
+type Receipt struct {
+	ServerChainName   string       `json:"serverChainName"`
+	ChainID           int64        `json:"chainId"`
+	Hash              string       `json:"hash"`
+	State             int          `json:"state"` // TxStateSuccess or TxStateFail
+	Height            int64        `json:"height"`
+	BlockHash         string       `json:"blockHash"`
+	TxIndex           int64        `json:"txIndex"`
+	FromAddress       string       `json:"fromAddress"`
+	ToAddress         string       `json:"toAddress"`       // Empty for contract creation
+	ContractAddress   string       `json:"contractAddress"` // Created contract, if any
+	GasUsed           string       `json:"gasUsed"`
+	GasPrice          string       `json:"gasPrice"` // Effective gas price
+	CumulativeGasUsed string       `json:"cumulativeGasUsed"`
+	Type              int64        `json:"type"`            // EIP-2718 transaction type
+	L1Fee             string       `json:"l1Fee,omitempty"` // Rollup L1 data fee, see Transaction.L1Fee
+	Fee               string       `json:"fee,omitempty"`   // Total fee paid by a rollup transaction
+	Logs              []ReceiptLog `json:"logs"`
+}
+
+// ReceiptLog is one event log of a Receipt.
+type ReceiptLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex int64    `json:"logIndex"`
+}
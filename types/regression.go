@@ -0,0 +1,20 @@
+package types
+
+// Regression sources.
+const (
+	RegressionSourceMonitor     = "monitor"     // server-side expected-count invariant
+	RegressionSourceCanary      = "canary"      // synthetic canary requests
+	RegressionSourceIntegration = "integration" // tx-aggregator-integration tool
+)
+
+// RegressionFinding reports that an address returned fewer transactions on a
+// chain than it did before (the expected-count invariant).
+type RegressionFinding struct {
+	Source       string `json:"source"`
+	Address      string `json:"address"`
+	ChainName    string `json:"chainName"`
+	TokenAddress string `json:"tokenAddress,omitempty"`
+	Expected     int    `json:"expected"`
+	Actual       int    `json:"actual"`
+	DetectedTime int64  `json:"detectedTime"`
+}
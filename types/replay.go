@@ -0,0 +1,17 @@
+package types
+
+// ReplayJob describes a re-normalization of every cached address of one
+// chain. Status uses the ExportStatus* job states.
+type ReplayJob struct {
+	ID          string `json:"id"`
+	ChainName   string `json:"chainName"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"` // Set when the cached addresses could not be listed
+	Addresses   int    `json:"addresses"`       // Cached addresses found
+	Done        int    `json:"done"`            // Addresses replayed successfully
+	Failed      int    `json:"failed"`          // Addresses whose replay failed
+	Kept        int    `json:"kept"`            // Rows kept so far
+	Dropped     int    `json:"dropped"`         // Rows removed by the current rules so far
+	CreatedTime int64  `json:"createdTime"`
+	UpdatedTime int64  `json:"updatedTime"`
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RpcRequest is a standard Ethereum JSON-RPC request, sent alone or in a batch.
+type RpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// RpcResponse is a standard JSON-RPC response whose result is decoded later,
+// since its shape depends on the method.
+type RpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RpcError       `json:"error,omitempty"`
+}
+
+// RpcError is the error object of a JSON-RPC response.
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// RpcBlock is the result of eth_getBlockByNumber with full transactions.
+type RpcBlock struct {
+	Number       string           `json:"number"`    // Hex block number
+	Hash         string           `json:"hash"`      // Block hash
+	Timestamp    string           `json:"timestamp"` // Hex Unix seconds
+	Transactions []RpcTransaction `json:"transactions"`
+}
+
+// RpcTransaction is a transaction object as embedded in RpcBlock.
+type RpcTransaction struct {
+	Hash             string `json:"hash"`
+	From             string `json:"from"`
+	To               string `json:"to"` // Empty for contract creation
+	Value            string `json:"value"`
+	Gas              string `json:"gas"`
+	GasPrice         string `json:"gasPrice"`
+	Nonce            string `json:"nonce"`
+	Input            string `json:"input"`
+	TransactionIndex string `json:"transactionIndex"`
+	BlockNumber      string `json:"blockNumber"`
+	BlockHash        string `json:"blockHash"`
+}
+
+// RpcLogFilter is the filter object of eth_getLogs. A nil topic matches anything.
+type RpcLogFilter struct {
+	FromBlock string        `json:"fromBlock"`
+	ToBlock   string        `json:"toBlock"`
+	Topics    []interface{} `json:"topics"`
+}
+
+// RpcCallFrame is one call of a debug_traceTransaction result with the
+// callTracer. The top frame is the transaction itself; Calls are the
+// internal calls it made.
+type RpcCallFrame struct {
+	Type  string         `json:"type"` // CALL, STATICCALL, DELEGATECALL, CREATE, SELFDESTRUCT, …
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Value string         `json:"value"` // Hex wei, absent for calls without value
+	Error string         `json:"error"` // Set when the call reverted
+	Calls []RpcCallFrame `json:"calls"`
+}
+
+// RpcTrace is one Parity-style trace of a trace_block result. TraceAddress
+// is the position of the call in its transaction's call tree; the empty one
+// is the transaction itself.
+type RpcTrace struct {
+	Type   string `json:"type"` // call, create, suicide, reward
+	Action struct {
+		CallType      string `json:"callType"` // call, staticcall, delegatecall, callcode
+		From          string `json:"from"`
+		To            string `json:"to"`
+		Value         string `json:"value"`
+		Address       string `json:"address"`       // suicide: the destroyed contract
+		RefundAddress string `json:"refundAddress"` // suicide: the beneficiary
+		Balance       string `json:"balance"`       // suicide: the amount sent
+	} `json:"action"`
+	Result *struct {
+		Address string `json:"address"` // create: the new contract
+	} `json:"result"`
+	Error           string `json:"error"`
+	TraceAddress    []int  `json:"traceAddress"`
+	TransactionHash string `json:"transactionHash"`
+	BlockNumber     int64  `json:"blockNumber"`
+}
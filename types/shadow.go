@@ -0,0 +1,16 @@
+package types
+
+// ShadowDiff records one mirrored request whose staging response differed
+// from production's. Transactions are compared by hash; volatile fields are
+// ignored (see utils.HashTransactions).
+type ShadowDiff struct {
+	Time         int64    `json:"time"` // Unix seconds
+	Path         string   `json:"path"` // Path and query of the request
+	ProdCode     int      `json:"prodCode"`
+	StagingCode  int      `json:"stagingCode"`
+	ProdCount    int      `json:"prodCount"`
+	StagingCount int      `json:"stagingCount"`
+	Missing      []string `json:"missing,omitempty"` // Hashes only production returned
+	Extra        []string `json:"extra,omitempty"`   // Hashes only staging returned
+	Error        string   `json:"error,omitempty"`   // Set when staging could not be queried or decoded
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/json"
+
+	"tx-aggregator/softjson"
+)
+
+// VoyagerTxnsResponse is the response of Voyager /txns. Pages are 1-based.
+type VoyagerTxnsResponse struct {
+	Items    softjson.Slice[VoyagerTxn] `json:"items"`
+	LastPage int64                      `json:"lastPage"`
+}
+
+// VoyagerTxn is one Starknet transaction. Starknet has no native value
+// transfer: value moves through ERC-20 (SNIP-2) transfers, and the fee is
+// paid in ETH or STRK.
+type VoyagerTxn struct {
+	Hash            string `json:"hash"`
+	BlockNumber     int64  `json:"blockNumber"`
+	BlockHash       string `json:"blockId"`
+	Index           int64  `json:"index"`
+	Type            string `json:"type"` // INVOKE, DEPLOY_ACCOUNT, DECLARE, L1_HANDLER
+	SenderAddress   string `json:"sender_address"`
+	ContractAddress string `json:"contract_address"`
+	Timestamp       int64  `json:"timestamp"`  // Unix seconds
+	ActualFee       string `json:"actual_fee"` // Hex, in the fee token's smallest unit
+	ExecutionStatus string `json:"execution_status"`
+}
+
+// VoyagerTransfersResponse is the response of Voyager
+// /contracts/{address}/transfers.
+type VoyagerTransfersResponse struct {
+	Items    softjson.Slice[VoyagerTransfer] `json:"items"`
+	LastPage int64                           `json:"lastPage"`
+}
+
+// VoyagerTransfer is one ERC-20 Transfer event. TransferAmount is the raw
+// integer amount.
+type VoyagerTransfer struct {
+	BlockNumber    int64       `json:"blockNumber"`
+	Timestamp      int64       `json:"timestamp"`
+	TransferFrom   string      `json:"transferFrom"`
+	TransferTo     string      `json:"transferTo"`
+	TransferAmount string      `json:"transferAmount"`
+	TokenAddress   string      `json:"tokenAddress"`
+	TokenSymbol    string      `json:"tokenSymbol"`
+	Decimals       json.Number `json:"decimals"` // Number or numeric string
+	TxHash         string      `json:"txHash"`
+}
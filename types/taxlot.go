@@ -0,0 +1,87 @@
+package types
+
+// Accounting methods supported by the tax lot export.
+const (
+	// TaxLotMethodFIFO matches disposals against the oldest open lots first.
+	TaxLotMethodFIFO = "fifo"
+	// TaxLotMethodLIFO matches disposals against the newest open lots first.
+	TaxLotMethodLIFO = "lifo"
+)
+
+// Asynchronous job states, shared by exports and backfills.
+const (
+	ExportStatusPending = "pending"
+	ExportStatusRunning = "running"
+	ExportStatusDone    = "done"
+	ExportStatusFailed  = "failed"
+)
+
+// TaxLotExportParams describes a single tax lot export request.
+type TaxLotExportParams struct {
+	Address      string
+	TokenAddress string
+	ChainNames   []string
+	Method       string // fifo or lifo
+}
+
+// TaxLot is a quantity of one asset acquired by a single incoming transaction.
+// Quantities are kept both as raw integers and as decimal strings.
+type TaxLot struct {
+	Hash         string `json:"hash"`         // Acquiring transaction hash
+	AcquiredTime int64  `json:"acquiredTime"` // Unix timestamp of acquisition
+	Height       int64  `json:"height"`       // Block height of acquisition
+	QuantityRaw  string `json:"quantityRaw"`  // Acquired quantity in smallest unit
+	Quantity     string `json:"quantity"`     // Acquired quantity divided by decimals
+	RemainingRaw string `json:"remainingRaw"` // Quantity still open after all disposals
+	Remaining    string `json:"remaining"`    // Remaining quantity divided by decimals
+}
+
+// TaxDisposal is the part of an outgoing transaction matched against one lot.
+// LotHash is empty when the disposal could not be matched (incomplete history).
+type TaxDisposal struct {
+	Hash         string `json:"hash"`         // Disposing transaction hash
+	DisposedTime int64  `json:"disposedTime"` // Unix timestamp of disposal
+	Height       int64  `json:"height"`       // Block height of disposal
+	LotHash      string `json:"lotHash"`      // Hash of the matched acquisition lot
+	AcquiredTime int64  `json:"acquiredTime"` // Acquisition time of the matched lot
+	QuantityRaw  string `json:"quantityRaw"`  // Disposed quantity in smallest unit
+	Quantity     string `json:"quantity"`     // Disposed quantity divided by decimals
+	Unmatched    bool   `json:"unmatched"`    // True when no open lot covered this quantity
+	Fee          bool   `json:"fee"`          // True when the quantity paid the transaction fee
+}
+
+// TaxLotAsset groups lots and disposals of one token on one chain.
+type TaxLotAsset struct {
+	ChainID          int64         `json:"chainId"`
+	TokenAddress     string        `json:"tokenAddress"`         // "native" for the chain's coin
+	TokenIDRaw       string        `json:"tokenIdRaw,omitempty"` // ERC-1155 token ID
+	TokenDisplayName string        `json:"tokenDisplayName"`
+	Decimals         int64         `json:"decimals"`
+	Lots             []TaxLot      `json:"lots"`
+	Disposals        []TaxDisposal `json:"disposals"`
+}
+
+// TaxLotReport is the result of a finished export job.
+type TaxLotReport struct {
+	Address   string        `json:"address"`
+	Method    string        `json:"method"`
+	Truncated bool          `json:"truncated,omitempty"` // Older history was cut at the cache cap; lots may be missing
+	Assets    []TaxLotAsset `json:"assets"`
+}
+
+// ExportJob describes the state of an asynchronous export.
+type ExportJob struct {
+	ID          string        `json:"id"`
+	Status      string        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	CreatedTime int64         `json:"createdTime"`
+	UpdatedTime int64         `json:"updatedTime"`
+	Report      *TaxLotReport `json:"report,omitempty"`
+}
+
+// ExportJobResponse wraps an ExportJob using the common code/message envelope.
+type ExportJobResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Result  *ExportJob `json:"result,omitempty"`
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// GraphQLRequest is the POST body sent to a subgraph endpoint.
+type GraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is a subgraph reply. Data is decoded later because its
+// shape is defined by the configured query.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors,omitempty"`
+}
+
+// GraphQLError is one entry of the errors array of a GraphQL reply.
+type GraphQLError struct {
+	Message string `json:"message"`
+}
+
+// GraphQLErrors is the errors array of a GraphQL reply.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, m := range e {
+		msgs = append(msgs, m.Message)
+	}
+	return "GraphQL error: " + strings.Join(msgs, "; ")
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// Request phases recorded in RequestTimings. Provider calls are recorded as
+// TimingProviderPrefix followed by the provider key.
+const (
+	TimingCacheRead      = "cache_read"
+	TimingFetch          = "fetch"     // Cache miss: providers, normalization and cache write
+	TimingProviders      = "providers" // Fan-out to the providers, until the last answer
+	TimingProviderPrefix = "provider:"
+	TimingCacheWrite     = "cache_write"
+	TimingPostProcess    = "postprocess"
+)
+
+// RequestTimings collects where the time of one API request went, for the
+// slow-request log. It is safe for concurrent use, and a nil
+// *RequestTimings records nothing.
+type RequestTimings struct {
+	mu     sync.Mutex
+	phases map[string]time.Duration
+}
+
+// Add adds d to the time spent in phase.
+func (t *RequestTimings) Add(phase string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.phases == nil {
+		t.phases = make(map[string]time.Duration)
+	}
+	t.phases[phase] += d
+}
+
+// Since adds the time elapsed since start to phase.
+func (t *RequestTimings) Since(phase string, start time.Time) {
+	t.Add(phase, time.Since(start))
+}
+
+// Phases returns a copy of the recorded phases.
+func (t *RequestTimings) Phases() map[string]time.Duration {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]time.Duration, len(t.phases))
+	for k, v := range t.phases {
+		out[k] = v
+	}
+	return out
+}
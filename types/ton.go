@@ -0,0 +1,67 @@
+package types
+
+import "tx-aggregator/softjson"
+
+// TonDefaultJettonDecimals is used when a jetton's metadata has no decimals.
+const TonDefaultJettonDecimals = 9
+
+// TonTransactionsResponse is the response of toncenter v3 /transactions.
+type TonTransactionsResponse struct {
+	Transactions softjson.Slice[TonTransaction] `json:"transactions"`
+}
+
+// TonTransaction is one transaction of the queried account. Addresses are
+// raw ("0:ABC…"), amounts are nanotons, hashes are base64.
+type TonTransaction struct {
+	Account     string `json:"account"`
+	Hash        string `json:"hash"`
+	Lt          string `json:"lt"`  // Logical time, increases along the account chain
+	Now         int64  `json:"now"` // Unix seconds
+	TotalFees   string `json:"total_fees"`
+	Description struct {
+		Aborted   bool `json:"aborted"`
+		ComputePh struct {
+			Success bool `json:"success"`
+		} `json:"compute_ph"`
+	} `json:"description"`
+	InMsg   *TonMessage  `json:"in_msg"`
+	OutMsgs []TonMessage `json:"out_msgs"`
+}
+
+// TonMessage is an inbound or outbound message. Source is empty for
+// external inbound messages (wallet signatures), which carry no value.
+type TonMessage struct {
+	Hash        string `json:"hash"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Value       string `json:"value"`
+}
+
+// TonJettonTransfersResponse is the response of toncenter v3 /jetton/transfers.
+type TonJettonTransfersResponse struct {
+	JettonTransfers softjson.Slice[TonJettonTransfer] `json:"jetton_transfers"`
+}
+
+// TonJettonTransfer is a jetton (TON token) transfer between two owners.
+type TonJettonTransfer struct {
+	Source             string `json:"source"`      // Sender owner address
+	Destination        string `json:"destination"` // Receiver owner address
+	Amount             string `json:"amount"`      // Raw jetton units
+	JettonMaster       string `json:"jetton_master"`
+	TransactionHash    string `json:"transaction_hash"`
+	TransactionLt      string `json:"transaction_lt"`
+	TransactionNow     int64  `json:"transaction_now"`
+	TransactionAborted bool   `json:"transaction_aborted"`
+}
+
+// TonJettonMastersResponse is the response of toncenter v3 /jetton/masters.
+type TonJettonMastersResponse struct {
+	JettonMasters []struct {
+		Address       string `json:"address"`
+		JettonContent struct {
+			Name     string `json:"name"`
+			Symbol   string `json:"symbol"`
+			Decimals string `json:"decimals"`
+		} `json:"jetton_content"`
+	} `json:"jetton_masters"`
+}
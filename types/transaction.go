@@ -37,6 +37,95 @@ type Transaction struct {
 	TranType    int    `json:"tranType"`
 	ApproveShow string `json:"approveShow"`
 	IconURL     string `json:"iconUrl"`
+
+	// Bitmask of Enrichment* flags
+	Enrichment int `json:"enrichment"`
+
+	// TokenIDRaw is the decimal ID of the token moved by an ERC-721 or
+	// ERC-1155 transfer. IDs are uint256: TokenID only holds those that fit
+	// an int64.
+	TokenIDRaw string `json:"tokenIdRaw,omitempty"`
+
+	// Swap details a transaction of type TxTypeSwap
+	Swap *SwapInfo `json:"swap,omitempty"`
+
+	// Bridge details a transaction of type TxTypeBridge
+	Bridge *BridgeInfo `json:"bridge,omitempty"`
+
+	// ContractAddress is the contract deployed by a transaction of type
+	// TxTypeContractCreation, whose ToAddress is empty
+	ContractAddress string `json:"contractAddress,omitempty"`
+
+	// Actions lists, in log order, what a batch call (Multicall3, router
+	// multicall / execute) did, as recorded by the events it emitted
+	Actions []SubAction `json:"actions,omitempty"`
+
+	// Safe is set when the transaction executed a Safe (Gnosis Safe)
+	// transaction
+	Safe *SafeExecution `json:"safe,omitempty"`
+
+	// L1Fee is the L1 data fee, in wei, of a rollup transaction, and Fee the
+	// total it paid: on OP Stack chains the L1 fee comes on top of GasUsed ×
+	// GasPrice, on Arbitrum it is part of it. Providers that only report the
+	// total fee (OKLink) set Fee alone. Both are empty when unknown.
+	L1Fee string `json:"l1Fee,omitempty"`
+	Fee   string `json:"fee,omitempty"`
+
+	// LogIndex is the position in its block of the event a token row was
+	// read from, which orders the rows of one transaction; 0 for other rows
+	LogIndex int64 `json:"logIndex,omitempty"`
+
+	// TransferIndex tells apart, in the order the provider returned them,
+	// the consecutive rows without LogIndex of a transaction that move the
+	// same amount of the same coin between the same addresses; 0 for the
+	// first (or only) one. Rows with a LogIndex are identified by it instead, see
+	// utils.TransactionKey
+	TransferIndex int `json:"transferIndex,omitempty"`
+
+	// Linked marks the rows of a transaction that sent native value along
+	// with token transfers: its native row and its token rows, which share
+	// the hash, are all served
+	Linked bool `json:"linked,omitempty"`
+}
+
+// SafeExecution is the outcome of a Safe transaction. A Safe records a
+// failed inner call with ExecutionFailure while the outer transaction
+// succeeds, so Success can differ from State.
+type SafeExecution struct {
+	Address    string `json:"address"`    // The Safe
+	SafeTxHash string `json:"safeTxHash"` // Hash of the Safe transaction, as signed by the owners
+	Success    bool   `json:"success"`    // Whether the inner call succeeded
+}
+
+// SubAction is one event of a batch call. Value is the raw amount of a
+// fungible transfer, approval or wrap; TokenIDRaw the ID of an NFT.
+type SubAction struct {
+	Action     string `json:"action"`   // One of the SubAction* constants
+	Contract   string `json:"contract"` // Contract that emitted the event
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	Value      string `json:"value,omitempty"`
+	TokenIDRaw string `json:"tokenIdRaw,omitempty"`
+}
+
+// BridgeInfo names the bridge a transfer went through and the chains on
+// either side: funds sent to the bridge leave ChainID for ToChainID, funds
+// paid out by it arrive from FromChainID.
+type BridgeInfo struct {
+	Name        string `json:"name"`        // Bridge name from the bridges config, e.g. "optimism"
+	FromChainID int64  `json:"fromChainId"` // Chain the funds leave
+	ToChainID   int64  `json:"toChainId"`   // Chain the funds arrive on
+}
+
+// SwapInfo is what the queried address gave and got in a DEX swap. Tokens
+// are contract addresses, or "native" for the chain's coin; amounts are
+// decimal-adjusted. Legs the providers did not report are left empty.
+type SwapInfo struct {
+	Protocol  string `json:"protocol"`  // Protocol whose Swap event was seen, e.g. "uniswap_v3"
+	TokenIn   string `json:"tokenIn"`   // Token sold by the address
+	AmountIn  string `json:"amountIn"`  // Amount sold
+	TokenOut  string `json:"tokenOut"`  // Token bought by the address
+	AmountOut string `json:"amountOut"` // Amount bought
 }
 
 type TransactionResponse struct {
@@ -45,5 +134,64 @@ type TransactionResponse struct {
 	Result  struct {
 		Transactions []Transaction `json:"transactions"`
 	} `json:"result"`
-	Id int `json:"id"`
+	Id   int           `json:"id"`
+	Meta *ResponseMeta `json:"meta,omitempty"` // Set for debug requests or when indexer lag is known
+	// CacheHit is set when the transactions were read from the cache rather
+	// than fetched from providers. It is only reported in the access log.
+	CacheHit bool `json:"-"`
+}
+
+// ResponseMeta carries diagnostic data about a response. It is never cached.
+type ResponseMeta struct {
+	// Hash is a canonical hash of the transaction list (see utils.HashTransactions),
+	// used for cheap equality checks across environments. Only with debug=true.
+	Hash string `json:"hash,omitempty"`
+	// IndexerLagBlocks is how many blocks each chain's indexer recently
+	// trailed the chain head, for the chains where it is known. Transactions
+	// in those newest blocks may be missing from the response.
+	IndexerLagBlocks map[string]int64 `json:"indexerLagBlocks,omitempty"`
+}
+
+// TokenMeta is one entry of the tokens dictionary of a compact response.
+// TokenAddress is "native" for the chain's coin.
+type TokenMeta struct {
+	ChainID          int64  `json:"chainId"`
+	TokenAddress     string `json:"tokenAddress"`
+	TokenDisplayName string `json:"tokenDisplayName"`
+	Decimals         int64  `json:"decimals"`
+}
+
+// CompactTransaction is a Transaction whose symbol and decimals are moved to
+// the response's tokens dictionary and referenced by TokenIndex.
+type CompactTransaction struct {
+	Transaction
+	TokenIndex int `json:"tokenIndex"`
+	// Always nil: shadow the embedded fields so they are left out of the JSON.
+	TokenDisplayName *string `json:"tokenDisplayName,omitempty"`
+	Decimals         *int64  `json:"decimals,omitempty"`
+}
+
+// CompactTransactionResponse is the tokenDict=true form of TransactionResponse.
+type CompactTransactionResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Result  struct {
+		Transactions []CompactTransaction `json:"transactions"`
+		Tokens       []TokenMeta          `json:"tokens"`
+	} `json:"result"`
+	Id   int           `json:"id"`
+	Meta *ResponseMeta `json:"meta,omitempty"`
+}
+
+// GroupedTransactionResponse is the groupBy=chain form of TransactionResponse:
+// the transactions of each requested chain, keyed by chain name, each list
+// sorted and limited on its own.
+type GroupedTransactionResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Result  struct {
+		Chains map[string][]Transaction `json:"chains"`
+	} `json:"result"`
+	Id   int           `json:"id"`
+	Meta *ResponseMeta `json:"meta,omitempty"`
 }
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/json"
+
+	"tx-aggregator/softjson"
+)
+
+// Tron contract types handled by the Tron provider.
+const (
+	TronContractTransfer = "TransferContract"     // TRX transfer
+	TronContractTrigger  = "TriggerSmartContract" // contract call, optionally with TRX
+)
+
+// TronContractRetSuccess is the contractRet of a successful transaction.
+const TronContractRetSuccess = "SUCCESS"
+
+// TronMeta carries TronGrid pagination state.
+type TronMeta struct {
+	At          int64  `json:"at"`
+	PageSize    int    `json:"page_size"`
+	Fingerprint string `json:"fingerprint"` // Empty on the last page
+}
+
+// TronTransactionsResponse is the response of /v1/accounts/{address}/transactions.
+type TronTransactionsResponse struct {
+	Success bool                            `json:"success"`
+	Error   string                          `json:"error"`
+	Data    softjson.Slice[TronTransaction] `json:"data"`
+	Meta    TronMeta                        `json:"meta"`
+}
+
+// TronTransaction is a raw Tron transaction. Addresses inside contract
+// parameters are hex ("41…").
+type TronTransaction struct {
+	TxID           string `json:"txID"`
+	BlockNumber    int64  `json:"blockNumber"`
+	BlockTimestamp int64  `json:"block_timestamp"` // Unix milliseconds
+	Ret            []struct {
+		ContractRet string `json:"contractRet"`
+		Fee         int64  `json:"fee"` // Sun
+	} `json:"ret"`
+	RawData struct {
+		Contract []TronContract `json:"contract"`
+	} `json:"raw_data"`
+}
+
+// TronContract is one contract call of a transaction.
+type TronContract struct {
+	Type      string `json:"type"`
+	Parameter struct {
+		Value json.RawMessage `json:"value"` // Shape depends on Type
+	} `json:"parameter"`
+}
+
+// TronContractValue holds the parameter fields used for TransferContract
+// (amount) and TriggerSmartContract (contract_address, call_value).
+type TronContractValue struct {
+	OwnerAddress    string `json:"owner_address"`
+	ToAddress       string `json:"to_address"`
+	ContractAddress string `json:"contract_address"`
+	Amount          int64  `json:"amount"`
+	CallValue       int64  `json:"call_value"`
+}
+
+// TronTRC20Response is the response of /v1/accounts/{address}/transactions/trc20.
+type TronTRC20Response struct {
+	Success bool                        `json:"success"`
+	Error   string                      `json:"error"`
+	Data    softjson.Slice[TronTRC20Tx] `json:"data"`
+	Meta    TronMeta                    `json:"meta"`
+}
+
+// TronTRC20Tx is a TRC-20 Transfer or Approval event. Addresses are base58.
+type TronTRC20Tx struct {
+	TransactionID  string `json:"transaction_id"`
+	BlockTimestamp int64  `json:"block_timestamp"` // Unix milliseconds
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Type           string `json:"type"` // Transfer or Approval
+	Value          string `json:"value"`
+	TokenInfo      struct {
+		Symbol   string `json:"symbol"`
+		Address  string `json:"address"`
+		Decimals int64  `json:"decimals"`
+		Name     string `json:"name"`
+	} `json:"token_info"`
+}
@@ -0,0 +1,366 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ConfigError lists every problem found by Config.Validate.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// providerEndpoint is one per-chain provider entry as seen by Validate.
+type providerEndpoint struct {
+	kind        string
+	chainName   string
+	url         string
+	urlRequired bool // false when the provider falls back to a public default
+}
+
+// Validate checks that the configuration can serve requests: Redis and the
+// response limit are set, every provider entry has a usable URL and a known
+// chain, every chain_providers mapping points at a known chain and at
+// configured providers, every address_families entry names a known chain
+// and family, every rpc_urls entry a known chain and an absolute URL, every
+// wrapped_native entry a known chain and an address, every swap_events entry
+// a protocol and a topic, every bridges entry a name, known chains and
+// contract addresses, and every internal_traces entry a known chain and
+// trace method.
+// It returns a *ConfigError listing all problems, or nil. Chain names are
+// compared case-insensitively, as keys are lowercased when the configuration
+// is loaded.
+func (c Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		addf("server.port %d is not a valid port", c.Server.Port)
+	}
+	if len(c.Redis.Addrs) == 0 {
+		addf("redis.addrs must list at least one address")
+	}
+	if c.Redis.TTLSeconds <= 0 {
+		addf("redis.ttl must be greater than 0")
+	}
+	if c.Response.Max <= 0 {
+		addf("response.max must be greater than 0")
+	}
+
+	chains := make(map[string]bool, len(c.ChainNames))
+	for name, id := range c.ChainNames {
+		chains[strings.ToLower(name)] = true
+		if id <= 0 {
+			addf("chain_names.%s has invalid chain ID %d", name, id)
+		}
+	}
+	if len(chains) == 0 {
+		addf("chain_names must not be empty")
+	}
+
+	families := make([]string, 0, len(c.AddressFamilies))
+	for chain := range c.AddressFamilies {
+		families = append(families, chain)
+	}
+	sort.Strings(families)
+	for _, chain := range families {
+		if !chains[strings.ToLower(chain)] {
+			addf("address_families.%s: chain is not in chain_names", chain)
+		}
+		if family := c.AddressFamilies[chain]; !IsAddressFamily(family) {
+			addf("address_families.%s: unknown address family %q", chain, family)
+		}
+	}
+
+	rpcChains := make([]string, 0, len(c.RPCURLs))
+	for chain := range c.RPCURLs {
+		rpcChains = append(rpcChains, chain)
+	}
+	sort.Strings(rpcChains)
+	for _, chain := range rpcChains {
+		if !chains[strings.ToLower(chain)] {
+			addf("rpc_urls.%s: chain is not in chain_names", chain)
+		}
+		if !isAbsoluteURL(c.RPCURLs[chain]) {
+			addf("rpc_urls.%s: url %q is not an absolute URL", chain, c.RPCURLs[chain])
+		}
+	}
+
+	wrapChains := make([]string, 0, len(c.WrappedNative))
+	for chain := range c.WrappedNative {
+		wrapChains = append(wrapChains, chain)
+	}
+	sort.Strings(wrapChains)
+	for _, chain := range wrapChains {
+		if !chains[strings.ToLower(chain)] {
+			addf("wrapped_native.%s: chain is not in chain_names", chain)
+		}
+		if !isEVMAddress(c.WrappedNative[chain]) {
+			addf("wrapped_native.%s: %q is not a contract address", chain, c.WrappedNative[chain])
+		}
+	}
+
+	for i, s := range c.SwapEvents {
+		if s.Protocol == "" {
+			addf("swap_events[%d]: protocol is required", i)
+		}
+		if b, err := hex.DecodeString(strings.TrimPrefix(s.Topic, "0x")); err != nil || len(b) != 32 || !strings.HasPrefix(s.Topic, "0x") {
+			addf("swap_events[%d]: topic %q is not an event signature hash", i, s.Topic)
+		}
+	}
+
+	for _, b := range c.Bridges {
+		if b.Name == "" {
+			addf("bridges: entry for chain %q has no name", b.ChainName)
+		}
+		for _, chain := range []string{b.ChainName, b.CounterpartChain} {
+			if !chains[strings.ToLower(chain)] {
+				addf("bridges.%s: chain %q is not in chain_names", b.Name, chain)
+			}
+		}
+		if len(b.Contracts) == 0 {
+			addf("bridges.%s: contracts must not be empty", b.Name)
+		}
+		for _, contract := range b.Contracts {
+			if !isEVMAddress(contract) {
+				addf("bridges.%s: %q is not a contract address", b.Name, contract)
+			}
+		}
+	}
+
+	for _, src := range c.TokenLists.Sources {
+		if strings.Contains(src, "://") && !isAbsoluteURL(src) {
+			addf("token_lists.sources: %q is not an absolute URL", src)
+		} else if src == "" {
+			addf("token_lists.sources: empty source")
+		}
+	}
+
+	allowed := make(map[string]bool, len(c.TokenFilter.Allow))
+	for _, contract := range c.TokenFilter.Allow {
+		if !isEVMAddress(contract) {
+			addf("token_filter.allow: %q is not a contract address", contract)
+		}
+		allowed[strings.ToLower(contract)] = true
+	}
+	for _, contract := range c.TokenFilter.Deny {
+		if !isEVMAddress(contract) {
+			addf("token_filter.deny: %q is not a contract address", contract)
+		} else if allowed[strings.ToLower(contract)] {
+			addf("token_filter: %s is both allowed and denied", contract)
+		}
+	}
+
+	for _, d := range c.DustThresholds {
+		if !chains[strings.ToLower(d.ChainName)] {
+			addf("dust_thresholds: chain %q is not in chain_names", d.ChainName)
+		}
+		if d.Token != "" && d.Token != NativeTokenName {
+			if family := c.ChainAddressFamily(d.ChainName); !isTokenAddress(family, d.Token) {
+				addf("dust_thresholds.%s: %q is not a %s token address", d.ChainName, d.Token, family)
+			}
+		}
+		if min, ok := new(big.Rat).SetString(d.Min); !ok || min.Sign() < 0 || strings.ContainsAny(d.Min, "/eE") {
+			addf("dust_thresholds.%s: min %q is not a decimal amount", d.ChainName, d.Min)
+		}
+	}
+
+	for _, t := range c.InternalTraces {
+		if !chains[strings.ToLower(t.ChainName)] {
+			addf("internal_traces: chain %q is not in chain_names", t.ChainName)
+		}
+		if t.Method != "" && t.Method != TraceMethodDebug && t.Method != TraceMethodBlock {
+			addf("internal_traces.%s: unknown method %q", t.ChainName, t.Method)
+		}
+	}
+
+	configured := map[string]bool{"ankr": true}
+	for _, p := range c.providerEndpoints() {
+		key := p.kind + "_" + strings.ToLower(p.chainName)
+		switch {
+		case p.chainName == "":
+			addf("%s entry has no chain_name", p.kind)
+			continue
+		case !chains[strings.ToLower(p.chainName)]:
+			addf("%s: chain %s is not in chain_names", key, p.chainName)
+		}
+		configured[key] = true
+		if p.url == "" {
+			if p.urlRequired {
+				addf("%s: url is required", key)
+			}
+		} else if !isAbsoluteURL(p.url) {
+			addf("%s: url %q is not an absolute URL", key, p.url)
+		}
+	}
+
+	chainNames := make([]string, 0, len(c.Providers.ChainProviders))
+	for chain := range c.Providers.ChainProviders {
+		chainNames = append(chainNames, chain)
+	}
+	sort.Strings(chainNames)
+	usesAnkr := false
+	for _, chain := range chainNames {
+		keys := c.Providers.ChainProviders[chain]
+		if !chains[strings.ToLower(chain)] {
+			addf("providers.chain_providers.%s: chain is not in chain_names", chain)
+		}
+		if len(keys) == 0 {
+			addf("providers.chain_providers.%s lists no provider", chain)
+		}
+		for _, key := range keys {
+			if !configured[key] {
+				addf("providers.chain_providers.%s: provider %s is not configured", chain, key)
+			}
+			usesAnkr = usesAnkr || key == "ankr"
+		}
+	}
+	if usesAnkr && !isAbsoluteURL(c.Ankr.URL) {
+		addf("ankr.url %q is not an absolute URL, but chain_providers uses ankr", c.Ankr.URL)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ConfigError{Problems: problems}
+}
+
+// providerEndpoints flattens the per-chain provider sections.
+func (c Config) providerEndpoints() []providerEndpoint {
+	var out []providerEndpoint
+	for _, p := range c.Blockscout {
+		out = append(out, providerEndpoint{"blockscout", p.ChainName, p.URL, true})
+	}
+	for _, p := range c.Blockscan {
+		out = append(out, providerEndpoint{"blockscan", p.ChainName, p.URL, true})
+	}
+	for _, p := range c.Alchemy {
+		out = append(out, providerEndpoint{"alchemy", p.ChainName, p.URL, true})
+	}
+	for _, p := range c.Routescan.Chains {
+		out = append(out, providerEndpoint{"routescan", p.ChainName, c.Routescan.URL, false})
+	}
+	for _, p := range c.OKLink.Chains {
+		out = append(out, providerEndpoint{"oklink", p.ChainName, c.OKLink.URL, false})
+	}
+	for _, p := range c.Esplora {
+		out = append(out, providerEndpoint{"esplora", p.ChainName, p.URL, true})
+	}
+	for _, p := range c.Tron {
+		out = append(out, providerEndpoint{"tron", p.ChainName, p.URL, false})
+	}
+	for _, p := range c.Ton {
+		out = append(out, providerEndpoint{"ton", p.ChainName, p.URL, false})
+	}
+	for _, p := range c.RPCScan {
+		out = append(out, providerEndpoint{"rpcscan", p.ChainName, p.URL, true})
+	}
+	for _, p := range c.TheGraph {
+		out = append(out, providerEndpoint{"thegraph", p.ChainName, p.URL, true})
+	}
+	for _, p := range c.ZkSync {
+		out = append(out, providerEndpoint{"zksync", p.ChainName, p.URL, false})
+	}
+	for _, p := range c.Starknet {
+		out = append(out, providerEndpoint{"starknet", p.ChainName, p.URL, false})
+	}
+	for _, p := range c.Aptos {
+		out = append(out, providerEndpoint{"aptos", p.ChainName, p.URL, false})
+	}
+	for _, p := range c.REST {
+		out = append(out, providerEndpoint{"rest", p.ChainName, p.URL, true})
+	}
+	return out
+}
+
+func isAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// ChainAddressFamily returns the address family of a chain: the one set in
+// address_families if any, otherwise utxo for chains listed in the Esplora
+// config, tron / ton / starknet / aptos for chains listed in the Tron / TON /
+// Starknet / Aptos configs and evm for every other chain.
+func (c Config) ChainAddressFamily(chainName string) string {
+	for name, family := range c.AddressFamilies {
+		if strings.EqualFold(name, chainName) {
+			return family
+		}
+	}
+	for _, e := range c.Esplora {
+		if strings.EqualFold(e.ChainName, chainName) {
+			return AddressFamilyUTXO
+		}
+	}
+	for _, t := range c.Tron {
+		if strings.EqualFold(t.ChainName, chainName) {
+			return AddressFamilyTron
+		}
+	}
+	for _, t := range c.Ton {
+		if strings.EqualFold(t.ChainName, chainName) {
+			return AddressFamilyTON
+		}
+	}
+	for _, s := range c.Starknet {
+		if strings.EqualFold(s.ChainName, chainName) {
+			return AddressFamilyStarknet
+		}
+	}
+	for _, a := range c.Aptos {
+		if strings.EqualFold(a.ChainName, chainName) {
+			return AddressFamilyAptos
+		}
+	}
+	return AddressFamilyEVM
+}
+
+// isTokenAddress reports whether s has the shape of a token address of the
+// family: a contract on EVM chains, a TRC-20 contract on Tron, a jetton
+// master on TON, a felt on Starknet and a fungible asset address on Aptos.
+// Checksums are left to the providers. UTXO chains have no tokens.
+func isTokenAddress(family, s string) bool {
+	switch family {
+	case AddressFamilyEVM:
+		return isEVMAddress(s)
+	case AddressFamilyStarknet, AddressFamilyAptos:
+		digits, ok := strings.CutPrefix(s, "0x")
+		return ok && len(digits) > 0 && len(digits) <= 64 && isHex(digits)
+	case AddressFamilyTron:
+		return len(s) == 34 && strings.HasPrefix(s, "T") && isBase58(s)
+	case AddressFamilyTON:
+		if wc, hash, found := strings.Cut(s, ":"); found {
+			return (wc == "0" || wc == "-1") && len(hash) == 64 && isHex(hash)
+		}
+		return len(s) == 48 && strings.Trim(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_+/") == ""
+	}
+	return false
+}
+
+func isHex(s string) bool {
+	return strings.Trim(strings.ToLower(s), "0123456789abcdef") == ""
+}
+
+func isBase58(s string) bool {
+	return strings.Trim(s, "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz") == ""
+}
+
+// isEVMAddress reports whether s is a 0x-prefixed 20-byte hex address.
+func isEVMAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
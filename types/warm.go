@@ -0,0 +1,9 @@
+package types
+
+// HotAddress is one address / chain pair ranked by how often it was queried
+// recently.
+type HotAddress struct {
+	Address   string  `json:"address"`
+	ChainName string  `json:"chainName"`
+	Score     float64 `json:"score"` // Decayed query count
+}
@@ -0,0 +1,22 @@
+package types
+
+// Watch is an address registered by an API key for scheduled refreshes,
+// longer cache retention and webhook notifications of new activity.
+type Watch struct {
+	ID          string           `json:"id"`
+	Owner       string           `json:"owner"` // Name of the API key that created it
+	Address     string           `json:"address"`
+	ChainNames  []string         `json:"chainNames"`
+	WebhookURL  string           `json:"webhookUrl,omitempty"`
+	LastHeights map[string]int64 `json:"lastHeights,omitempty"` // Chain name → highest block seen, absent before its first refresh
+	CreatedTime int64            `json:"createdTime"`
+	ExpiresTime int64            `json:"expiresTime"`
+}
+
+// WatchNotification is POSTed to the webhook of a watch when a refresh finds
+// transactions above the last seen height of their chain.
+type WatchNotification struct {
+	WatchID      string        `json:"watchId"`
+	Address      string        `json:"address"`
+	Transactions []Transaction `json:"transactions"`
+}
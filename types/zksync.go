@@ -0,0 +1,70 @@
+package types
+
+import "tx-aggregator/softjson"
+
+// ZkSyncMeta is the paging block of zkSync explorer API list responses.
+// Pages are 1-based.
+type ZkSyncMeta struct {
+	TotalItems   int64 `json:"totalItems"`
+	ItemCount    int64 `json:"itemCount"`
+	ItemsPerPage int64 `json:"itemsPerPage"`
+	TotalPages   int64 `json:"totalPages"`
+	CurrentPage  int64 `json:"currentPage"`
+}
+
+// ZkSyncTransactionsResponse is the response of /transactions?address=.
+type ZkSyncTransactionsResponse struct {
+	Items softjson.Slice[ZkSyncTransaction] `json:"items"`
+	Meta  ZkSyncMeta                        `json:"meta"`
+}
+
+// ZkSyncTransaction is one transaction of the zkSync explorer API. Fee is the
+// amount actually paid in wei (hex), after refunds and including the L1
+// pubdata cost, so it does not equal gasUsed × gasPrice.
+type ZkSyncTransaction struct {
+	Hash             string `json:"hash"`
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Value            string `json:"value"`
+	Fee              string `json:"fee"`
+	Nonce            int64  `json:"nonce"`
+	GasLimit         string `json:"gasLimit"`
+	GasPrice         string `json:"gasPrice"`
+	GasPerPubdata    string `json:"gasPerPubdata"`
+	BlockNumber      int64  `json:"blockNumber"`
+	BlockHash        string `json:"blockHash"`
+	TransactionIndex int64  `json:"transactionIndex"`
+	ReceivedAt       string `json:"receivedAt"` // RFC 3339
+	IsL1Originated   bool   `json:"isL1Originated"`
+	Status           string `json:"status"` // included, committed, proved, verified or failed
+}
+
+// ZkSyncTransfersResponse is the response of /address/{address}/transfers.
+type ZkSyncTransfersResponse struct {
+	Items softjson.Slice[ZkSyncTransfer] `json:"items"`
+	Meta  ZkSyncMeta                     `json:"meta"`
+}
+
+// ZkSyncTransfer is one value movement: ETH, ERC-20 or NFT, including
+// deposits, withdrawals and the fee / refund legs of every transaction.
+type ZkSyncTransfer struct {
+	From            string      `json:"from"`
+	To              string      `json:"to"`
+	BlockNumber     int64       `json:"blockNumber"`
+	TransactionHash string      `json:"transactionHash"`
+	Timestamp       string      `json:"timestamp"` // RFC 3339
+	Amount          string      `json:"amount"`
+	TokenAddress    string      `json:"tokenAddress"`
+	Type            string      `json:"type"`      // transfer, deposit, withdrawal, fee, mint or refund
+	TokenType       string      `json:"tokenType"` // ETH, ERC20 or ERC721
+	IsInternal      bool        `json:"isInternal"`
+	Token           ZkSyncToken `json:"token"`
+}
+
+// ZkSyncToken is the token metadata embedded in a transfer.
+type ZkSyncToken struct {
+	L2Address string `json:"l2Address"`
+	Symbol    string `json:"symbol"`
+	Decimals  int64  `json:"decimals"`
+	IconURL   string `json:"iconURL"`
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"fmt"
+	"math/big"
+
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// unlimitedAllowance is the threshold above which an allowance is reported
+// as unlimited: 2^255, half of the uint256 range.
+var unlimitedAllowance = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// GetAllowance reads the current allowance of params.Spender on
+// params.Token with eth_call against the chain's RPC endpoint (see
+// utils.ChainRPCURL).
+func (s *Service) GetAllowance(params *types.AllowanceParams) (*types.Allowance, error) {
+	url, ok := utils.ChainRPCURL(params.ChainName)
+	if !ok {
+		return nil, fmt.Errorf("no RPC URL configured for chain %s", params.ChainName)
+	}
+	chainID, err := utils.ChainIDByName(params.ChainName)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := utils.RPCAllowance("rpc.allowance", url, params.Token, params.Owner, params.Spender)
+	if err != nil {
+		return nil, err
+	}
+	return &types.Allowance{
+		ChainName:    params.ChainName,
+		ChainID:      chainID,
+		TokenAddress: params.Token,
+		Owner:        params.Owner,
+		Spender:      params.Spender,
+		Allowance:    value.String(),
+		Unlimited:    value.Cmp(unlimitedAllowance) >= 0,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestGetAllowance(t *testing.T) {
+	result := "0x00000000000000000000000000000000000000000000000000000000000003e8"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":"`+result+`"}`)
+	}))
+	defer srv.Close()
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.RPCURLs = map[string]string{"eth": srv.URL}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	svc := NewService(nil, nil)
+	params := &types.AllowanceParams{ChainName: "ETH", Token: "0xtoken", Owner: "0xowner", Spender: "0xspender"}
+	got, err := svc.GetAllowance(params)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Allowance{
+		ChainName: "ETH", ChainID: 1, TokenAddress: "0xtoken", Owner: "0xowner", Spender: "0xspender",
+		Allowance: "1000",
+	}, got)
+
+	result = "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+	got, err = svc.GetAllowance(params)
+	assert.NoError(t, err)
+	assert.True(t, got.Unlimited)
+
+	_, err = svc.GetAllowance(&types.AllowanceParams{ChainName: "BSC"})
+	assert.Error(t, err, "no RPC URL")
+}
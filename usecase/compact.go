@@ -0,0 +1,42 @@
+package usecase
+
+import "tx-aggregator/types"
+
+// CompactTransactions moves the symbol and decimals of every row into a
+// tokens dictionary, keyed by chain and token address, and references it by
+// index from each row. Dictionary order follows first appearance.
+func CompactTransactions(resp *types.TransactionResponse) *types.CompactTransactionResponse {
+	out := &types.CompactTransactionResponse{
+		Code:    resp.Code,
+		Message: resp.Message,
+		Id:      resp.Id,
+		Meta:    resp.Meta,
+	}
+	out.Result.Transactions = make([]types.CompactTransaction, 0, len(resp.Result.Transactions))
+	out.Result.Tokens = []types.TokenMeta{}
+
+	index := make(map[types.TokenMeta]int)
+	for _, tx := range resp.Result.Transactions {
+		token := types.NativeTokenName
+		if types.IsTokenCoinType(tx.CoinType) {
+			token = tx.TokenAddress
+		}
+		meta := types.TokenMeta{
+			ChainID:          tx.ChainID,
+			TokenAddress:     token,
+			TokenDisplayName: tx.TokenDisplayName,
+			Decimals:         tx.Decimals,
+		}
+		i, ok := index[meta]
+		if !ok {
+			i = len(out.Result.Tokens)
+			index[meta] = i
+			out.Result.Tokens = append(out.Result.Tokens, meta)
+		}
+		out.Result.Transactions = append(out.Result.Transactions, types.CompactTransaction{
+			Transaction: tx,
+			TokenIndex:  i,
+		})
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+
+	. "tx-aggregator/usecase"
+)
+
+func TestCompactTransactions(t *testing.T) {
+	resp := buildResponse([]types.Transaction{
+		{Hash: "0x1", ChainID: 1, CoinType: types.CoinTypeNative, TokenDisplayName: "ETH", Decimals: 18},
+		{Hash: "0x2", ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: "0xusdt", TokenDisplayName: "USDT", Decimals: 6},
+		{Hash: "0x3", ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: "0xusdt", TokenDisplayName: "USDT", Decimals: 6},
+		{Hash: "0x4", ChainID: 56, CoinType: types.CoinTypeNative, TokenDisplayName: "BNB", Decimals: 18},
+	})
+	resp.Code = types.CodeSuccess
+
+	out := CompactTransactions(resp)
+	assert.Equal(t, types.CodeSuccess, out.Code)
+	assert.Equal(t, []types.TokenMeta{
+		{ChainID: 1, TokenAddress: types.NativeTokenName, TokenDisplayName: "ETH", Decimals: 18},
+		{ChainID: 1, TokenAddress: "0xusdt", TokenDisplayName: "USDT", Decimals: 6},
+		{ChainID: 56, TokenAddress: types.NativeTokenName, TokenDisplayName: "BNB", Decimals: 18},
+	}, out.Result.Tokens)
+
+	var indexes []int
+	for _, tx := range out.Result.Transactions {
+		indexes = append(indexes, tx.TokenIndex)
+	}
+	assert.Equal(t, []int{0, 1, 1, 2}, indexes)
+
+	// Symbol and decimals are only in the dictionary.
+	b, err := json.Marshal(out.Result.Transactions[1])
+	assert.NoError(t, err)
+	var row map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &row))
+	assert.NotContains(t, row, "tokenDisplayName")
+	assert.NotContains(t, row, "decimals")
+	assert.Equal(t, float64(1), row["tokenIndex"])
+	assert.Equal(t, "0x2", row["hash"])
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
@@ -31,7 +32,7 @@ func FilterTransactionsByTokenAddress(resp *types.TransactionResponse, params *t
 	tokenAddrLower := strings.ToLower(params.TokenAddress)
 
 	for _, tx := range resp.Result.Transactions {
-		if strings.ToLower(tx.TokenAddress) == tokenAddrLower && tx.CoinType == types.CoinTypeToken {
+		if strings.ToLower(tx.TokenAddress) == tokenAddrLower && types.IsTokenCoinType(tx.CoinType) {
 			filtered = append(filtered, tx)
 		}
 	}
@@ -54,6 +55,179 @@ func FilterTransactionsByCoinType(resp *types.TransactionResponse, coinType int)
 	return resp
 }
 
+// FilterTransactionsByTokenLists drops the token rows whose contract is in
+// deny or, when allow is not empty, missing from allow. Native and internal
+// rows are always kept.
+func FilterTransactionsByTokenLists(resp *types.TransactionResponse, allow, deny []string) *types.TransactionResponse {
+	if len(allow) == 0 && len(deny) == 0 {
+		return resp
+	}
+	toSet := func(contracts []string) map[string]struct{} {
+		set := make(map[string]struct{}, len(contracts))
+		for _, c := range contracts {
+			set[strings.ToLower(c)] = struct{}{}
+		}
+		return set
+	}
+	allowSet, denySet := toSet(allow), toSet(deny)
+
+	filtered := make([]types.Transaction, 0, len(resp.Result.Transactions))
+	for _, tx := range resp.Result.Transactions {
+		if types.IsTokenCoinType(tx.CoinType) {
+			contract := strings.ToLower(tx.TokenAddress)
+			if _, ok := denySet[contract]; ok {
+				continue
+			}
+			if _, ok := allowSet[contract]; len(allowSet) > 0 && !ok {
+				continue
+			}
+		}
+		filtered = append(filtered, tx)
+	}
+
+	resp.Result.Transactions = filtered
+	return resp
+}
+
+// FilterTransactionsByMinValue drops the value movements worth less than
+// minValue, a decimal amount compared exactly against each row's balance in
+// units of its own coin. Approvals, ERC-721 transfers and rows without a
+// numeric balance are kept, and so are rows moving nothing, such as plain
+// contract calls.
+func FilterTransactionsByMinValue(resp *types.TransactionResponse, minValue string) *types.TransactionResponse {
+	min, ok := new(big.Rat).SetString(minValue)
+	if !ok || min.Sign() <= 0 {
+		return resp
+	}
+
+	filtered := make([]types.Transaction, 0, len(resp.Result.Transactions))
+	for _, tx := range resp.Result.Transactions {
+		if !worthLess(tx, min) {
+			filtered = append(filtered, tx)
+		}
+	}
+
+	resp.Result.Transactions = filtered
+	return resp
+}
+
+// FilterDustTransactions drops the transfers worth less than the dust
+// threshold of their chain and token. A token without its own threshold
+// uses the chain's token-wide one (empty token), if any; native transfers
+// only use a "native" threshold. Rows are compared as in
+// FilterTransactionsByMinValue.
+func FilterDustTransactions(resp *types.TransactionResponse, thresholds []types.DustThresholdConfig) *types.TransactionResponse {
+	if len(thresholds) == 0 {
+		return resp
+	}
+	// chainID → token ("native", canonical contract or "") → threshold
+	mins := make(map[int64]map[string]*big.Rat)
+	families := make(map[int64]string)
+	for _, d := range thresholds {
+		chainID, err := utils.ChainIDByName(d.ChainName)
+		if err != nil {
+			continue
+		}
+		families[chainID] = utils.ChainAddressFamily(d.ChainName)
+		min, ok := new(big.Rat).SetString(d.Min)
+		if !ok {
+			continue
+		}
+		if mins[chainID] == nil {
+			mins[chainID] = make(map[string]*big.Rat)
+		}
+		token := d.Token
+		if token != types.NativeTokenName {
+			token = dustTokenKey(families[chainID], token)
+		}
+		mins[chainID][token] = min
+	}
+
+	filtered := make([]types.Transaction, 0, len(resp.Result.Transactions))
+	for _, tx := range resp.Result.Transactions {
+		byToken := mins[tx.ChainID]
+		var min *big.Rat
+		if types.IsTokenCoinType(tx.CoinType) {
+			var ok bool
+			if min, ok = byToken[dustTokenKey(families[tx.ChainID], tx.TokenAddress)]; !ok {
+				min = byToken[""]
+			}
+		} else {
+			min = byToken[types.NativeTokenName]
+		}
+		if min == nil || !worthLess(tx, min) {
+			filtered = append(filtered, tx)
+		}
+	}
+
+	resp.Result.Transactions = filtered
+	return resp
+}
+
+// dustTokenKey is the spelling of a token address under which dust
+// thresholds are matched: canonical for the chain's address family, so
+// case-sensitive base58 Tron contracts and unpadded felts match, and
+// lowercase for addresses the family does not read.
+func dustTokenKey(family, token string) string {
+	if token == "" {
+		return ""
+	}
+	if canonical, ok := utils.NormalizeAddress(family, token); ok {
+		return canonical
+	}
+	return strings.ToLower(token)
+}
+
+// worthLess reports whether tx moves a nonzero amount below min, in units of
+// its own coin. Approvals, ERC-721 transfers and rows without a numeric
+// balance are never worth less.
+func worthLess(tx types.Transaction, min *big.Rat) bool {
+	if tx.Type == types.TxTypeApprove || tx.CoinType == types.CoinTypeERC721 {
+		return false
+	}
+	raw, ok := new(big.Int).SetString(tx.Balance, 10)
+	if !ok || raw.Sign() == 0 {
+		return false
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(tx.Decimals), nil)
+	return new(big.Rat).SetFrac(raw.Abs(raw), scale).Cmp(min) < 0
+}
+
+// FilterZeroValueTransactions drops the native transfers of 0: contract
+// calls whose value movements, if any, are reported by their token and
+// internal rows. Swaps, bridge transfers, wraps, contract creations and
+// approvals are kept whatever their value.
+func FilterZeroValueTransactions(resp *types.TransactionResponse) *types.TransactionResponse {
+	filtered := make([]types.Transaction, 0, len(resp.Result.Transactions))
+	for _, tx := range resp.Result.Transactions {
+		if tx.CoinType == types.CoinTypeNative && tx.Type == types.TxTypeTransfer {
+			if v, ok := new(big.Int).SetString(tx.Balance, 10); ok && v.Sign() == 0 {
+				continue
+			}
+		}
+		filtered = append(filtered, tx)
+	}
+
+	resp.Result.Transactions = filtered
+	return resp
+}
+
+// FilterTransactionsByBlockRange filters transactions to only include those with a height within
+// [start, end]. A zero bound leaves that end of the range open.
+func FilterTransactionsByBlockRange(resp *types.TransactionResponse, start, end int64) *types.TransactionResponse {
+	filtered := make([]types.Transaction, 0, len(resp.Result.Transactions))
+
+	for _, tx := range resp.Result.Transactions {
+		if (start > 0 && tx.Height < start) || (end > 0 && tx.Height > end) {
+			continue
+		}
+		filtered = append(filtered, tx)
+	}
+
+	resp.Result.Transactions = filtered
+	return resp
+}
+
 // FilterTransactionsByChainNames filters transactions to only include those with the specified chain IDs.
 func FilterTransactionsByChainNames(resp *types.TransactionResponse, chainNames []string) *types.TransactionResponse {
 	if len(chainNames) == 0 {
@@ -93,6 +267,9 @@ func SortTransactionResponseByHeightAndIndex(resp *types.TransactionResponse, as
 		if txI.TxIndex != txJ.TxIndex {
 			return ascending == (txI.TxIndex < txJ.TxIndex)
 		}
+		if txI.LogIndex != txJ.LogIndex {
+			return ascending == (txI.LogIndex < txJ.LogIndex)
+		}
 		if txI.FromAddress == txJ.FromAddress {
 			if nI, errI := strconv.ParseUint(txI.Nonce, 10, 64); errI == nil {
 				if nJ, errJ := strconv.ParseUint(txJ.Nonce, 10, 64); errJ == nil && nI != nJ {
@@ -117,6 +294,21 @@ func LimitTransactions(resp *types.TransactionResponse, max int64) *types.Transa
 	return resp
 }
 
+// LimitTransactionsPerChain keeps at most max transactions of each chain,
+// preserving the order of the rows kept.
+func LimitTransactionsPerChain(resp *types.TransactionResponse, max int64) *types.TransactionResponse {
+	counts := make(map[int64]int64)
+	kept := resp.Result.Transactions[:0]
+	for _, tx := range resp.Result.Transactions {
+		if counts[tx.ChainID] < max {
+			counts[tx.ChainID]++
+			kept = append(kept, tx)
+		}
+	}
+	resp.Result.Transactions = kept
+	return resp
+}
+
 // SetServerChainNames sets the ServerChainName field for each transaction
 // based on the chain ID using the configured chain name mappings.
 func SetServerChainNames(resp *types.TransactionResponse) *types.TransactionResponse {
@@ -127,19 +319,32 @@ func SetServerChainNames(resp *types.TransactionResponse) *types.TransactionResp
 	return resp
 }
 
-// FilterNativeShadowTx removes the redundant native (coinType == 1) “shadow”
-// / transaction that accompanies an ERC-20 transfer (coinType == 2) with the
-// same hash. The function rewrites resp.Result.Transactions in place.
+// FilterNativeShadowTx removes the zero-value native "shadow" row that
+// accompanies the token transfers (ERC-20, ERC-721, ERC-1155) of the same
+// transaction. A native row carrying value is kept, and it and the token
+// rows of its transaction are marked Linked. A zero-value native row that
+// describes the transaction (see describesTx) is kept as well, unlinked.
+// The function rewrites resp.Result.Transactions in place.
 func FilterNativeShadowTx(resp *types.TransactionResponse) {
 	if resp == nil || len(resp.Result.Transactions) == 0 {
 		return // nothing to filter
 	}
 
-	// Pass 1: collect the hashes of every ERC-20 transfer.
-	tokenTxHashes := make(map[string]struct{}, len(resp.Result.Transactions))
+	// Pass 1: collect the transactions with token transfers, and those of
+	// them that also sent native value.
+	key := func(tx types.Transaction) string {
+		return strconv.FormatInt(tx.ChainID, 10) + "|" + strings.ToLower(tx.Hash)
+	}
+	tokenTxs := make(map[string]struct{}, len(resp.Result.Transactions))
 	for _, tx := range resp.Result.Transactions {
-		if tx.CoinType == 2 {
-			tokenTxHashes[tx.Hash] = struct{}{}
+		if types.IsTokenCoinType(tx.CoinType) {
+			tokenTxs[key(tx)] = struct{}{}
+		}
+	}
+	linked := make(map[string]struct{})
+	for _, tx := range resp.Result.Transactions {
+		if _, paired := tokenTxs[key(tx)]; paired && tx.CoinType == types.CoinTypeNative && !isZeroValue(tx) {
+			linked[key(tx)] = struct{}{}
 		}
 	}
 
@@ -148,14 +353,110 @@ func FilterNativeShadowTx(resp *types.TransactionResponse) {
 	//   • any native transfer that is *not* a zero-value shadow of a token transfer
 	keep := resp.Result.Transactions[:0] // reuse underlying memory
 	for _, tx := range resp.Result.Transactions {
-		if tx.CoinType == 1 {
-			if _, paired := tokenTxHashes[tx.Hash]; paired {
+		k := key(tx)
+		if tx.CoinType == types.CoinTypeNative {
+			if _, paired := tokenTxs[k]; paired && isZeroValue(tx) && !describesTx(tx) {
 				// Skip the shadow native transaction.
 				continue
 			}
 		}
+		if _, ok := linked[k]; ok && (tx.CoinType == types.CoinTypeNative || types.IsTokenCoinType(tx.CoinType)) {
+			tx.Linked = true
+		}
 		keep = append(keep, tx)
 	}
 
 	resp.Result.Transactions = keep
 }
+
+// describesTx reports whether a native row carries what only it says about
+// its transaction, such as a swap, wrap, contract creation, the actions of
+// a batch call or a Safe execution, which would be lost with it.
+func describesTx(tx types.Transaction) bool {
+	return tx.Type != types.TxTypeTransfer || tx.Swap != nil || tx.Bridge != nil || tx.ContractAddress != "" ||
+		len(tx.Actions) > 0 || tx.Safe != nil
+}
+
+// isZeroValue reports whether tx moves no value; a missing or malformed
+// balance counts as zero.
+func isZeroValue(tx types.Transaction) bool {
+	v, ok := new(big.Int).SetString(tx.Balance, 10)
+	return !ok || v.Sign() == 0
+}
+
+// ReconcileNativeInternal drops doubled native value movements. Some chains
+// (or providers) report the same transfer both as a normal transaction and as
+// an internal one under the same hash. A normal row and an internal row
+// describing the same movement (chain, hash, from, to, amount) are paired and
+// only one of them kept; precedence picks it (types.PrecedenceNormal or
+// types.PrecedenceInternal, normal by default). Rows of the same
+// representation are never merged: a contract may legitimately send the same
+// amount to the same address several times in one transaction. The function
+// rewrites resp.Result.Transactions in place and keeps the original order.
+func ReconcileNativeInternal(resp *types.TransactionResponse, precedence string) {
+	if resp == nil || len(resp.Result.Transactions) == 0 {
+		return
+	}
+	preferInternal := strings.EqualFold(precedence, types.PrecedenceInternal)
+
+	type counts struct{ normal, internal int }
+
+	// Pass 1: count the rows of either representation for every value movement.
+	seen := make(map[string]*counts, len(resp.Result.Transactions))
+	for _, tx := range resp.Result.Transactions {
+		key, ok := valueMovementKey(tx)
+		if !ok {
+			continue
+		}
+		c := seen[key]
+		if c == nil {
+			c = &counts{}
+			seen[key] = c
+		}
+		if isInternalTx(tx) {
+			c.internal++
+		} else {
+			c.normal++
+		}
+	}
+
+	// Pass 2: drop the first rows of the other representation, one per pair.
+	keep := resp.Result.Transactions[:0]
+	for _, tx := range resp.Result.Transactions {
+		key, ok := valueMovementKey(tx)
+		if ok && isInternalTx(tx) != preferInternal {
+			if c := seen[key]; min(c.normal, c.internal) > 0 {
+				c.normal--
+				c.internal--
+				continue
+			}
+		}
+		keep = append(keep, tx)
+	}
+
+	resp.Result.Transactions = keep
+}
+
+// isInternalTx reports whether tx is an internal transfer, whichever way the
+// provider flags it.
+func isInternalTx(tx types.Transaction) bool {
+	return tx.Type == types.TxTypeInternal || tx.CoinType == types.CoinTypeInternal
+}
+
+// valueMovementKey identifies a native value movement. Token rows and
+// approvals are not value movements of the native coin and report false.
+func valueMovementKey(tx types.Transaction) (string, bool) {
+	if tx.CoinType != types.CoinTypeNative && tx.CoinType != types.CoinTypeInternal {
+		return "", false
+	}
+	if tx.Type == types.TxTypeApprove {
+		return "", false
+	}
+	return strings.Join([]string{
+		strconv.FormatInt(tx.ChainID, 10),
+		strings.ToLower(tx.Hash),
+		strings.ToLower(tx.FromAddress),
+		strings.ToLower(tx.ToAddress),
+		tx.Balance,
+	}, "|"), true
+}
@@ -1,6 +1,7 @@
 package usecase_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,6 +42,54 @@ func TestFilterNativeShadowTx(t *testing.T) {
 		})
 	})
 
+	t.Run("keeps and links native value sent with a token transfer", func(t *testing.T) {
+		resp := buildResponse([]types.Transaction{
+			{Hash: "0x1", ChainID: 1, CoinType: types.CoinTypeNative, Balance: "1000"},
+			{Hash: "0x1", ChainID: 1, CoinType: types.CoinTypeToken, Balance: "5"},
+			{Hash: "0x1", ChainID: 1, CoinType: types.CoinTypeInternal, Balance: "7"},
+			{Hash: "0x2", ChainID: 1, CoinType: types.CoinTypeNative, Balance: "0"},
+			{Hash: "0x2", ChainID: 1, CoinType: types.CoinTypeERC721, Balance: "1"},
+			{Hash: "0x2", ChainID: 56, CoinType: types.CoinTypeNative, Balance: "0"}, // other chain
+		})
+		FilterNativeShadowTx(resp)
+		type row struct {
+			hash     string
+			coinType int
+			linked   bool
+		}
+		var got []row
+		for _, tx := range resp.Result.Transactions {
+			got = append(got, row{tx.Hash, tx.CoinType, tx.Linked})
+		}
+		assert.Equal(t, []row{
+			{"0x1", types.CoinTypeNative, true},
+			{"0x1", types.CoinTypeToken, true},
+			{"0x1", types.CoinTypeInternal, false},
+			{"0x2", types.CoinTypeERC721, false},
+			{"0x2", types.CoinTypeNative, false},
+		}, got)
+	})
+
+	t.Run("keeps zero-value native rows that describe the transaction", func(t *testing.T) {
+		resp := buildResponse([]types.Transaction{
+			{Hash: "0x1", CoinType: types.CoinTypeNative, Type: types.TxTypeSwap, Swap: &types.SwapInfo{}},
+			{Hash: "0x1", CoinType: types.CoinTypeToken},
+			{Hash: "0x2", CoinType: types.CoinTypeNative, Type: types.TxTypeContractCreation, ContractAddress: "0xc"},
+			{Hash: "0x2", CoinType: types.CoinTypeToken},
+			{Hash: "0x3", CoinType: types.CoinTypeNative, Type: types.TxTypeUnwrap},
+			{Hash: "0x3", CoinType: types.CoinTypeToken},
+			{Hash: "0x4", CoinType: types.CoinTypeNative, Actions: []types.SubAction{{Action: types.SubActionTransfer}}},
+			{Hash: "0x4", CoinType: types.CoinTypeToken},
+			{Hash: "0x5", CoinType: types.CoinTypeNative, Safe: &types.SafeExecution{Address: "0xsafe"}},
+			{Hash: "0x5", CoinType: types.CoinTypeToken},
+		})
+		FilterNativeShadowTx(resp)
+		assert.Len(t, resp.Result.Transactions, 10)
+		for _, tx := range resp.Result.Transactions {
+			assert.False(t, tx.Linked)
+		}
+	})
+
 	t.Run("does not remove unrelated native tx", func(t *testing.T) {
 		resp := buildResponse([]types.Transaction{
 			{Hash: "0x1", CoinType: types.CoinTypeNative},
@@ -51,6 +100,60 @@ func TestFilterNativeShadowTx(t *testing.T) {
 	})
 }
 
+func TestReconcileNativeInternal(t *testing.T) {
+	doubled := func() *types.TransactionResponse {
+		return buildResponse([]types.Transaction{
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xA", ToAddress: "0xb", Balance: "5", CoinType: types.CoinTypeNative},
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xa", ToAddress: "0xB", Balance: "5", CoinType: types.CoinTypeNative, Type: types.TxTypeInternal},
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xa", ToAddress: "0xb", Balance: "5", CoinType: types.CoinTypeToken},
+			{ChainID: 1, Hash: "0x2", FromAddress: "0xa", ToAddress: "0xb", Balance: "5", CoinType: types.CoinTypeNative},
+		})
+	}
+
+	t.Run("normal precedence keeps the top-level row", func(t *testing.T) {
+		resp := doubled()
+		ReconcileNativeInternal(resp, "")
+		assert.Len(t, resp.Result.Transactions, 3)
+		assert.Equal(t, types.TxTypeTransfer, resp.Result.Transactions[0].Type)
+		assert.Equal(t, types.CoinTypeToken, resp.Result.Transactions[1].CoinType)
+		assert.Equal(t, "0x2", resp.Result.Transactions[2].Hash)
+	})
+
+	t.Run("internal precedence keeps the internal row", func(t *testing.T) {
+		resp := doubled()
+		ReconcileNativeInternal(resp, types.PrecedenceInternal)
+		assert.Len(t, resp.Result.Transactions, 3)
+		assert.Equal(t, types.TxTypeInternal, resp.Result.Transactions[0].Type)
+	})
+
+	t.Run("keeps repeated internal transfers", func(t *testing.T) {
+		resp := buildResponse([]types.Transaction{
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xc", ToAddress: "0xa", Balance: "1", CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal},
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xc", ToAddress: "0xa", Balance: "1", CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal, TransferIndex: 1},
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xc", ToAddress: "0xa", Balance: "2", CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal},
+		})
+		ReconcileNativeInternal(resp, types.PrecedenceNormal)
+		assert.Len(t, resp.Result.Transactions, 3)
+	})
+
+	t.Run("pairs one internal row per normal row", func(t *testing.T) {
+		resp := buildResponse([]types.Transaction{
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xa", ToAddress: "0xb", Balance: "5", CoinType: types.CoinTypeNative},
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xa", ToAddress: "0xb", Balance: "5", CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal},
+			{ChainID: 1, Hash: "0x1", FromAddress: "0xa", ToAddress: "0xb", Balance: "5", CoinType: types.CoinTypeInternal, Type: types.TxTypeInternal, TransferIndex: 1},
+		})
+		ReconcileNativeInternal(resp, types.PrecedenceNormal)
+		assert.Len(t, resp.Result.Transactions, 2)
+		assert.Equal(t, types.CoinTypeNative, resp.Result.Transactions[0].CoinType)
+		assert.Equal(t, 1, resp.Result.Transactions[1].TransferIndex)
+
+		resp = buildResponse(resp.Result.Transactions)
+		ReconcileNativeInternal(resp, types.PrecedenceInternal)
+		assert.Len(t, resp.Result.Transactions, 1)
+		assert.Equal(t, types.CoinTypeInternal, resp.Result.Transactions[0].CoinType)
+	})
+}
+
 func TestFilterTransactionsByInvolvedAddress(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -124,6 +227,106 @@ func TestFilterTransactionsByTokenAddress(t *testing.T) {
 	assert.Equal(t, "0x1", got.Result.Transactions[0].Hash)
 }
 
+func TestFilterTransactionsByTokenLists(t *testing.T) {
+	rows := func() *types.TransactionResponse {
+		return buildResponse([]types.Transaction{
+			{Hash: "0x1", TokenAddress: "0xUSDC", CoinType: types.CoinTypeToken},
+			{Hash: "0x2", TokenAddress: "0xspam", CoinType: types.CoinTypeToken},
+			{Hash: "0x3", TokenAddress: "0xnft", CoinType: types.CoinTypeERC721},
+			{Hash: "0x4", CoinType: types.CoinTypeNative},
+		})
+	}
+	hashes := func(resp *types.TransactionResponse) []string {
+		var out []string
+		for _, tx := range resp.Result.Transactions {
+			out = append(out, tx.Hash)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"0x1", "0x2", "0x3", "0x4"}, hashes(FilterTransactionsByTokenLists(rows(), nil, nil)))
+	assert.Equal(t, []string{"0x1", "0x3", "0x4"}, hashes(FilterTransactionsByTokenLists(rows(), nil, []string{"0xSPAM"})))
+	assert.Equal(t, []string{"0x1", "0x4"}, hashes(FilterTransactionsByTokenLists(rows(), []string{"0xusdc"}, nil)))
+	assert.Equal(t, []string{"0x4"}, hashes(FilterTransactionsByTokenLists(rows(), []string{"0xusdc"}, []string{"0xusdc"})), "deny wins")
+}
+
+func TestFilterTransactionsByMinValue(t *testing.T) {
+	resp := buildResponse([]types.Transaction{
+		{Hash: "0x1", CoinType: types.CoinTypeNative, Balance: "1000000000000000", Decimals: 18}, // 0.001
+		{Hash: "0x2", CoinType: types.CoinTypeNative, Balance: "999999999999999", Decimals: 18},  // just below
+		{Hash: "0x3", CoinType: types.CoinTypeToken, Balance: "999", Decimals: 6},                // 0.000999
+		{Hash: "0x4", CoinType: types.CoinTypeToken, Balance: "5", Decimals: 0},                  // 5
+		{Hash: "0x5", CoinType: types.CoinTypeToken, Balance: "1", Decimals: 6, Type: types.TxTypeApprove},
+		{Hash: "0x6", CoinType: types.CoinTypeERC721, Balance: "1"},
+		{Hash: "0x7", CoinType: types.CoinTypeNative, Balance: "0", Decimals: 18},
+		{Hash: "0x8", CoinType: types.CoinTypeNative, Balance: "", Decimals: 18},
+	})
+	got := FilterTransactionsByMinValue(resp, "0.001")
+	var hashes []string
+	for _, tx := range got.Result.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.Equal(t, []string{"0x1", "0x4", "0x5", "0x6", "0x7", "0x8"}, hashes)
+}
+
+func TestFilterDustTransactions(t *testing.T) {
+	initTestConfig()
+	const usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	resp := buildResponse([]types.Transaction{
+		{Hash: "0x1", ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: usdc, Balance: "9999", Decimals: 6},
+		{Hash: "0x2", ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: usdc, Balance: "10000", Decimals: 6},
+		{Hash: "0x3", ChainID: 1, CoinType: types.CoinTypeToken, TokenAddress: "0xairdrop", Balance: "1", Decimals: 18},
+		{Hash: "0x4", ChainID: 1, CoinType: types.CoinTypeNative, Balance: "1", Decimals: 18},
+		{Hash: "0x5", ChainID: 56, CoinType: types.CoinTypeNative, Balance: "1", Decimals: 18},
+		{Hash: "0x6", ChainID: 56, CoinType: types.CoinTypeToken, TokenAddress: "0xairdrop", Balance: "1", Decimals: 18},
+	})
+	got := FilterDustTransactions(resp, []types.DustThresholdConfig{
+		{ChainName: "eth", Token: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Min: "0.01"},
+		{ChainName: "eth", Min: "0.000001"},
+		{ChainName: "bsc", Token: types.NativeTokenName, Min: "0.000001"},
+	})
+	var hashes []string
+	for _, tx := range got.Result.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.Equal(t, []string{"0x2", "0x4", "0x6"}, hashes)
+}
+
+func TestFilterDustTransactions_TronToken(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"TRON": 728126428}
+	cfg.AddressFamilies = map[string]string{"tron": types.AddressFamilyTron}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	const usdt = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	resp := buildResponse([]types.Transaction{
+		{Hash: "a", ChainID: 728126428, CoinType: types.CoinTypeToken, TokenAddress: usdt, Balance: "9999", Decimals: 6},
+		{Hash: "b", ChainID: 728126428, CoinType: types.CoinTypeToken, TokenAddress: usdt, Balance: "10000", Decimals: 6},
+	})
+	got := FilterDustTransactions(resp, []types.DustThresholdConfig{{ChainName: "tron", Token: usdt, Min: "0.01"}})
+	assert.Len(t, got.Result.Transactions, 1)
+	assert.Equal(t, "b", got.Result.Transactions[0].Hash)
+}
+
+func TestFilterZeroValueTransactions(t *testing.T) {
+	resp := buildResponse([]types.Transaction{
+		{Hash: "0x1", CoinType: types.CoinTypeNative, Balance: "0"},
+		{Hash: "0x2", CoinType: types.CoinTypeNative, Balance: "1"},
+		{Hash: "0x3", CoinType: types.CoinTypeToken, Balance: "0"},
+		{Hash: "0x4", CoinType: types.CoinTypeNative, Balance: "0", Type: types.TxTypeSwap},
+		{Hash: "0x5", CoinType: types.CoinTypeNative, Balance: "0", Type: types.TxTypeApprove},
+		{Hash: "0x6", CoinType: types.CoinTypeInternal, Balance: "0", Type: types.TxTypeInternal},
+	})
+	got := FilterZeroValueTransactions(resp)
+	var hashes []string
+	for _, tx := range got.Result.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.Equal(t, []string{"0x2", "0x3", "0x4", "0x5", "0x6"}, hashes)
+}
+
 func TestFilterTransactionsByCoinType(t *testing.T) {
 	resp := buildResponse([]types.Transaction{
 		{Hash: "0x1", CoinType: types.CoinTypeToken},
@@ -134,6 +337,21 @@ func TestFilterTransactionsByCoinType(t *testing.T) {
 	assert.Equal(t, "0x1", got.Result.Transactions[0].Hash)
 }
 
+func TestFilterTransactionsByBlockRange(t *testing.T) {
+	txs := []types.Transaction{{Hash: "0x1", Height: 10}, {Hash: "0x2", Height: 20}, {Hash: "0x3", Height: 30}}
+
+	got := FilterTransactionsByBlockRange(buildResponse(slices.Clone(txs)), 20, 0)
+	assert.Len(t, got.Result.Transactions, 2, "open end")
+
+	got = FilterTransactionsByBlockRange(buildResponse(slices.Clone(txs)), 0, 20)
+	assert.Len(t, got.Result.Transactions, 2, "open start")
+
+	got = FilterTransactionsByBlockRange(buildResponse(slices.Clone(txs)), 15, 25)
+	if assert.Len(t, got.Result.Transactions, 1) {
+		assert.Equal(t, "0x2", got.Result.Transactions[0].Hash)
+	}
+}
+
 func TestFilterTransactionsByChainNames(t *testing.T) {
 	initTestConfig()
 
@@ -177,6 +395,23 @@ func TestLimitTransactions(t *testing.T) {
 	})
 }
 
+func TestLimitTransactionsPerChain(t *testing.T) {
+	in := buildResponse([]types.Transaction{
+		{ChainID: 1, Hash: "0x1"},
+		{ChainID: 56, Hash: "0x2"},
+		{ChainID: 1, Hash: "0x3"},
+		{ChainID: 1, Hash: "0x4"},
+		{ChainID: 56, Hash: "0x5"},
+	})
+	out := LimitTransactionsPerChain(in, 2)
+
+	var hashes []string
+	for _, tx := range out.Result.Transactions {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.Equal(t, []string{"0x1", "0x2", "0x3", "0x5"}, hashes)
+}
+
 func TestSortTransactionResponseByHeightAndIndex(t *testing.T) {
 	makeResp := func() *types.TransactionResponse {
 		return buildResponse([]types.Transaction{
@@ -206,6 +441,20 @@ func TestSortTransactionResponseByHeightAndIndex(t *testing.T) {
 			assert.Equal(t, h, resp.Result.Transactions[i].Hash)
 		}
 	})
+
+	t.Run("rows of one transaction by log index", func(t *testing.T) {
+		resp := buildResponse([]types.Transaction{
+			{Hash: "0xA", Height: 10, TxIndex: 2, LogIndex: 9, FromAddress: "0x1"},
+			{Hash: "0xA", Height: 10, TxIndex: 2, LogIndex: 4, FromAddress: "0x2"},
+			{Hash: "0xA", Height: 10, TxIndex: 2, FromAddress: "0x3"},
+		})
+		SortTransactionResponseByHeightAndIndex(resp, true)
+		var got []int64
+		for _, tx := range resp.Result.Transactions {
+			got = append(got, tx.LogIndex)
+		}
+		assert.Equal(t, []int64{0, 4, 9}, got)
+	})
 }
 
 func TestSetServerChainNames(t *testing.T) {
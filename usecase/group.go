@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// GroupTransactionsByChain splits the transactions of resp by server chain
+// name, keeping their order. Every chain of chainNames has an entry, empty
+// when it has no transactions, so clients can render one section per chain.
+func GroupTransactionsByChain(resp *types.TransactionResponse, chainNames []string) *types.GroupedTransactionResponse {
+	out := &types.GroupedTransactionResponse{
+		Code:    resp.Code,
+		Message: resp.Message,
+		Id:      resp.Id,
+		Meta:    resp.Meta,
+	}
+	out.Result.Chains = make(map[string][]types.Transaction, len(chainNames))
+	for _, name := range chainNames {
+		out.Result.Chains[strings.ToUpper(name)] = []types.Transaction{}
+	}
+	for _, tx := range resp.Result.Transactions {
+		out.Result.Chains[tx.ServerChainName] = append(out.Result.Chains[tx.ServerChainName], tx)
+	}
+	return out
+}
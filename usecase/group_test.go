@@ -0,0 +1,27 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+
+	. "tx-aggregator/usecase"
+)
+
+func TestGroupTransactionsByChain(t *testing.T) {
+	resp := buildResponse([]types.Transaction{
+		{Hash: "0x3", ServerChainName: "BSC"},
+		{Hash: "0x2", ServerChainName: "ETH"},
+		{Hash: "0x1", ServerChainName: "ETH"},
+	})
+	resp.Code = types.CodeSuccess
+
+	out := GroupTransactionsByChain(resp, []string{"eth", "bsc", "POL"})
+	assert.Equal(t, types.CodeSuccess, out.Code)
+	assert.Equal(t, map[string][]types.Transaction{
+		"ETH": {{Hash: "0x2", ServerChainName: "ETH"}, {Hash: "0x1", ServerChainName: "ETH"}},
+		"BSC": {{Hash: "0x3", ServerChainName: "BSC"}},
+		"POL": {},
+	}, out.Result.Chains)
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// IngestTransactions takes rows found by the indexer for address, normalizes
+// them like a provider fetch, persists them and merges them into the cached
+// entries of the address. Chains with nothing cached are left alone: an
+// entry holding only the indexed rows would hide the older history from the
+// next query, which fetches it instead. It returns the number of rows kept.
+func (s *Service) IngestTransactions(address string, txs []types.Transaction) (int, error) {
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = txs
+	resp = normalize(resp, &types.TransactionQueryParams{Address: address})
+	if len(resp.Result.Transactions) == 0 {
+		return 0, nil
+	}
+	utils.MarkEnrichment(resp.Result.Transactions)
+
+	if s.store != nil {
+		s.persist(address, resp.Result.Transactions)
+	}
+
+	cached := make(map[int64]bool)
+	merge := &types.TransactionResponse{}
+	for _, tx := range resp.Result.Transactions {
+		hit, checked := cached[tx.ChainID]
+		if !checked {
+			chainName, err := utils.ChainNameByID(tx.ChainID)
+			if err != nil {
+				logger.Log.Warn().Err(err).Int64("chain_id", tx.ChainID).Msg("Skipping indexed transaction of unknown chain")
+				continue
+			}
+			hit, err = s.cache.HasEntry(&types.TransactionQueryParams{Address: address, ChainNames: []string{chainName}})
+			if err != nil {
+				return 0, err
+			}
+			cached[tx.ChainID] = hit
+		}
+		if hit {
+			merge.Result.Transactions = append(merge.Result.Transactions, tx)
+		}
+	}
+	if len(merge.Result.Transactions) > 0 {
+		if err := s.cache.ParseTxAndSaveToCache(merge, address); err != nil {
+			return 0, err
+		}
+	}
+	return len(resp.Result.Transactions), nil
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/cache"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestIngestTransactions(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	cfg.Redis.TTLSeconds = 100
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	svc := NewService(rc, nil)
+	store := &stubStore{}
+	svc.SetStore(store)
+
+	// ETH is cached, BSC is not.
+	old := &types.TransactionResponse{}
+	old.Result.Transactions = []types.Transaction{{ChainID: 1, Hash: "0x1", Height: 1, FromAddress: "0xabc", CoinType: types.CoinTypeNative}}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(old, "0xabc"))
+
+	n, err := svc.IngestTransactions("0xabc", []types.Transaction{
+		{ChainID: 1, Hash: "0x2", Height: 2, FromAddress: "0xabc", CoinType: types.CoinTypeNative},
+		{ChainID: 56, Hash: "0x3", Height: 3, ToAddress: "0xabc", CoinType: types.CoinTypeNative},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, store.saved["0xabc"], 2, "every row is persisted")
+
+	eth, err := rc.QueryTxFromCache(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	assert.Len(t, eth.Result.Transactions, 2, "merged into the cached entry")
+
+	hit, err := rc.HasEntry(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"BSC"}})
+	assert.NoError(t, err)
+	assert.False(t, hit, "no partial entry is created")
+}
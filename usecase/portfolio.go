@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// GetPortfolio summarizes the address on the requested chains: every token
+// of its cached token sets and of its history with a balance, and up to
+// recent of its latest transactions per chain. The portfolio is flagged
+// truncated when the history it was built from is not complete.
+func (s *Service) GetPortfolio(params *types.TransactionQueryParams, recent int) (*types.Portfolio, error) {
+	txs, complete, err := s.GetTransactionHistory(params)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSets := make(map[string][]string, len(params.ChainNames))
+	for _, chain := range params.ChainNames {
+		set, err := s.cache.TokenSet(params.Address, chain)
+		if err != nil {
+			// The history still lists every token with transfers.
+			logger.ForRequest(params.RequestID).Warn().Err(err).Str("chain", chain).Msg("Failed to read cached token set")
+			continue
+		}
+		tokenSets[strings.ToUpper(chain)] = set
+	}
+	p := BuildPortfolio(params.Address, params.ChainNames, txs, tokenSets, recent)
+	p.Truncated = !complete
+	return p, nil
+}
+
+// holding accumulates one asset of a portfolio.
+type holding struct {
+	token   types.PortfolioToken
+	balance *big.Int
+}
+
+// BuildPortfolio builds the portfolio of address from its history on
+// chainNames (sorted ascending, with server chain names set) and its token
+// sets (chain name → token addresses). Incoming transfers add to a balance
+// and outgoing ones subtract from it; failed transactions and approvals move
+// nothing, but the fee of every transaction sent by the address is charged
+// to the native coin once, from the transaction's native row (see
+// utils.TxFee). Each chain lists at most recent transactions.
+func BuildPortfolio(address string, chainNames []string, txs []types.Transaction, tokenSets map[string][]string, recent int) *types.Portfolio {
+	holdings := make(map[string]map[string]*holding, len(chainNames))
+	get := func(chain, token, tokenID string) *holding {
+		if holdings[chain] == nil {
+			holdings[chain] = make(map[string]*holding)
+		}
+		key := token + "#" + tokenID
+		h := holdings[chain][key]
+		if h == nil {
+			h = &holding{token: types.PortfolioToken{TokenAddress: token, TokenIDRaw: tokenID}, balance: new(big.Int)}
+			holdings[chain][key] = h
+		}
+		return h
+	}
+
+	history := make(map[string][]types.Transaction, len(chainNames))
+	feePaid := make(map[string]bool)
+	for _, tx := range txs {
+		chain := tx.ServerChainName
+		history[chain] = append(history[chain], tx)
+
+		token, tokenID := types.NativeTokenName, ""
+		if types.IsTokenCoinType(tx.CoinType) {
+			token = strings.ToLower(tx.TokenAddress)
+		}
+		if tx.CoinType == types.CoinTypeERC1155 {
+			tokenID = tx.TokenIDRaw
+		}
+		h := get(chain, token, tokenID)
+		if h.token.TokenDisplayName == "" {
+			h.token.TokenDisplayName = tx.TokenDisplayName
+			h.token.Decimals = tx.Decimals
+		}
+
+		if fee, ok := utils.TxFee(tx, address); ok && !feePaid[chain+tx.Hash] {
+			feePaid[chain+tx.Hash] = true
+			native := get(chain, types.NativeTokenName, "")
+			native.balance.Sub(native.balance, fee)
+		}
+
+		if tx.State != types.TxStateSuccess || tx.Type == types.TxTypeApprove {
+			continue
+		}
+		h.token.TxCount++
+		qty, ok := new(big.Int).SetString(tx.Balance, 10)
+		if !ok {
+			continue
+		}
+		incoming := strings.EqualFold(tx.ToAddress, address)
+		outgoing := strings.EqualFold(tx.FromAddress, address)
+		switch {
+		case incoming && outgoing:
+			// Self transfer: holdings do not change.
+		case incoming:
+			h.balance.Add(h.balance, qty)
+		case outgoing:
+			h.balance.Sub(h.balance, qty)
+		}
+	}
+
+	p := &types.Portfolio{
+		Address: address,
+		Chains:  make(map[string]types.PortfolioChain, len(chainNames)),
+	}
+	for _, name := range chainNames {
+		chain := strings.ToUpper(name)
+		held := make(map[string]bool, len(holdings[chain]))
+		for _, h := range holdings[chain] {
+			held[h.token.TokenAddress] = true
+		}
+		for _, token := range tokenSets[chain] {
+			// Tokens of the set without rows in the history are listed at zero
+			if token = strings.ToLower(token); !held[token] {
+				get(chain, token, "")
+			}
+		}
+
+		tokens := make([]types.PortfolioToken, 0, len(holdings[chain]))
+		for _, h := range holdings[chain] {
+			h.token.BalanceRaw = h.balance.String()
+			h.token.Balance = utils.DivideByDecimals(new(big.Int).Abs(h.balance).String(), int(h.token.Decimals))
+			if h.balance.Sign() < 0 {
+				// Transfers missing from the history; keep the sign visible.
+				h.token.Balance = "-" + h.token.Balance
+			}
+			tokens = append(tokens, h.token)
+		}
+		sort.Slice(tokens, func(i, j int) bool {
+			if (tokens[i].TokenAddress == types.NativeTokenName) != (tokens[j].TokenAddress == types.NativeTokenName) {
+				return tokens[i].TokenAddress == types.NativeTokenName
+			}
+			if tokens[i].TokenAddress != tokens[j].TokenAddress {
+				return tokens[i].TokenAddress < tokens[j].TokenAddress
+			}
+			return tokens[i].TokenIDRaw < tokens[j].TokenIDRaw
+		})
+
+		rows := history[chain]
+		if len(rows) > recent {
+			rows = rows[len(rows)-recent:]
+		}
+		latest := make([]types.Transaction, len(rows))
+		for i, tx := range rows {
+			latest[len(rows)-1-i] = tx
+		}
+
+		id, _ := utils.ChainIDByName(chain)
+		p.Chains[chain] = types.PortfolioChain{ChainID: id, Tokens: tokens, Recent: latest}
+	}
+	return p
+}
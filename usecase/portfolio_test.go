@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+
+	. "tx-aggregator/usecase"
+)
+
+func TestBuildPortfolio(t *testing.T) {
+	initTestConfig()
+
+	const me = "0xme"
+	txs := []types.Transaction{
+		{Hash: "0x1", ServerChainName: "ETH", Height: 1, State: types.TxStateSuccess, CoinType: types.CoinTypeNative,
+			FromAddress: "0xother", ToAddress: me, Balance: "1000000000000000000", TokenDisplayName: "ETH", Decimals: 18},
+		{Hash: "0x2", ServerChainName: "ETH", Height: 2, State: types.TxStateSuccess, CoinType: types.CoinTypeToken,
+			TokenAddress: "0xUSDT", FromAddress: "0xother", ToAddress: me, Balance: "5000000", TokenDisplayName: "USDT", Decimals: 6},
+		// Sends 2 USDT and pays 21000 × 10 wei, charged once from the native row.
+		{Hash: "0x3", ServerChainName: "ETH", Height: 3, State: types.TxStateSuccess, CoinType: types.CoinTypeToken,
+			TokenAddress: "0xusdt", FromAddress: me, ToAddress: "0xother", Balance: "2000000", GasUsed: "21000", GasPrice: "10"},
+		{Hash: "0x3", ServerChainName: "ETH", Height: 3, State: types.TxStateSuccess, CoinType: types.CoinTypeNative,
+			FromAddress: me, ToAddress: "0xusdt", Balance: "0", GasUsed: "21000", GasPrice: "10"},
+		// A failed send only costs its fee.
+		{Hash: "0x4", ServerChainName: "ETH", Height: 4, State: types.TxStateFail, CoinType: types.CoinTypeNative,
+			FromAddress: me, ToAddress: "0xother", Balance: "1", GasUsed: "100", GasPrice: "10"},
+		// A relayer sends this transfer of 1 USDT and pays its fee.
+		{Hash: "0x6", ServerChainName: "ETH", Height: 5, State: types.TxStateSuccess, CoinType: types.CoinTypeToken,
+			TokenAddress: "0xusdt", FromAddress: me, ToAddress: "0xother", Balance: "1000000", GasUsed: "50000", GasPrice: "10"},
+	}
+	// ERC-1155 IDs of one contract are separate assets.
+	items := []types.Transaction{
+		{Hash: "0x5", ServerChainName: "ETH", Height: 1, State: types.TxStateSuccess, CoinType: types.CoinTypeERC1155,
+			TokenAddress: "0xitems", TokenIDRaw: "7", FromAddress: "0xother", ToAddress: me, Balance: "3"},
+		{Hash: "0x5", ServerChainName: "ETH", Height: 1, State: types.TxStateSuccess, CoinType: types.CoinTypeERC1155,
+			TokenAddress: "0xitems", TokenIDRaw: "8", FromAddress: "0xother", ToAddress: me, Balance: "1"},
+	}
+
+	p := BuildPortfolio(me, []string{"ETH", "BSC"}, append(items, txs...), map[string][]string{"ETH": {"0xdai", "0xitems"}}, 2)
+
+	eth := p.Chains["ETH"]
+	assert.Equal(t, int64(1), eth.ChainID)
+	assert.Equal(t, []types.PortfolioToken{
+		{TokenAddress: types.NativeTokenName, TokenDisplayName: "ETH", Decimals: 18, BalanceRaw: "999999999999789000", Balance: "0.999999999999789", TxCount: 2},
+		{TokenAddress: "0xdai", BalanceRaw: "0", Balance: "0"},
+		{TokenAddress: "0xitems", TokenIDRaw: "7", BalanceRaw: "3", Balance: "3", TxCount: 1},
+		{TokenAddress: "0xitems", TokenIDRaw: "8", BalanceRaw: "1", Balance: "1", TxCount: 1},
+		{TokenAddress: "0xusdt", TokenDisplayName: "USDT", Decimals: 6, BalanceRaw: "2000000", Balance: "2", TxCount: 3},
+	}, eth.Tokens)
+	if assert.Len(t, eth.Recent, 2) {
+		assert.Equal(t, "0x6", eth.Recent[0].Hash, "newest first")
+		assert.Equal(t, "0x4", eth.Recent[1].Hash)
+	}
+
+	bsc := p.Chains["BSC"]
+	assert.Equal(t, int64(56), bsc.ChainID)
+	assert.Empty(t, bsc.Tokens)
+	assert.Empty(t, bsc.Recent)
+}
+
+func TestBuildPortfolio_RollupFee(t *testing.T) {
+	initTestConfig()
+
+	const me = "0xme"
+	txs := []types.Transaction{
+		{Hash: "0x1", ServerChainName: "ETH", Height: 1, State: types.TxStateSuccess, CoinType: types.CoinTypeNative,
+			FromAddress: "0xother", ToAddress: me, Balance: "1000000"},
+		// Pays 21000 × 10 wei of execution plus a 500 wei L1 data fee.
+		{Hash: "0x2", ServerChainName: "ETH", Height: 2, State: types.TxStateSuccess, CoinType: types.CoinTypeNative,
+			FromAddress: me, ToAddress: "0xother", Balance: "0", GasUsed: "21000", GasPrice: "10", L1Fee: "500", Fee: "210500"},
+	}
+
+	p := BuildPortfolio(me, []string{"ETH"}, txs, nil, 0)
+	if assert.Len(t, p.Chains["ETH"].Tokens, 1) {
+		assert.Equal(t, "789500", p.Chains["ETH"].Tokens[0].BalanceRaw)
+	}
+}
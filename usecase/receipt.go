@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// ErrReceiptNotFound is returned by GetReceipt when the node does not know
+// the transaction or it is still pending.
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+// GetReceipt returns the normalized receipt of hash on chainName, from the
+// cache or from the chain's RPC endpoint (see utils.ChainRPCURL). Receipts
+// of mined transactions are cached for redis.ttl; unknown and pending ones
+// are not cached.
+func (s *Service) GetReceipt(chainName, hash string) (*types.Receipt, error) {
+	if receipt, ok, err := s.cache.CachedReceipt(chainName, hash); err != nil {
+		logger.Log.Warn().Err(err).Str("chain", chainName).Str("hash", hash).Msg("Failed to read cached receipt")
+	} else if ok {
+		return receipt, nil
+	}
+
+	url, ok := utils.ChainRPCURL(chainName)
+	if !ok {
+		return nil, fmt.Errorf("no RPC URL configured for chain %s", chainName)
+	}
+	chainID, err := utils.ChainIDByName(chainName)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := utils.RPCTransactionReceipt("rpc.receipt", url, hash)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil || raw.BlockHash == "" {
+		return nil, ErrReceiptNotFound
+	}
+
+	receipt := normalizeReceipt(strings.ToUpper(chainName), chainID, raw)
+	ttl := time.Duration(config.Current().Redis.TTLSeconds) * time.Second
+	if err := s.cache.CacheReceipt(receipt, ttl); err != nil {
+		logger.Log.Warn().Err(err).Str("chain", chainName).Str("hash", hash).Msg("Failed to cache receipt")
+	}
+	return receipt, nil
+}
+
+// normalizeReceipt converts the hex quantities of a JSON-RPC receipt to the
+// decimal strings and integers used by transactions.
+func normalizeReceipt(chainName string, chainID int64, raw *types.RpcReceipt) *types.Receipt {
+	number := func(hex string) string {
+		v, _ := utils.NormalizeNumericString(hex)
+		return v
+	}
+	state := types.TxStateFail
+	if raw.Status == "0x1" {
+		state = types.TxStateSuccess
+	}
+
+	receipt := &types.Receipt{
+		ServerChainName:   chainName,
+		ChainID:           chainID,
+		Hash:              strings.ToLower(raw.TransactionHash),
+		State:             state,
+		Height:            utils.ParseStringToInt64OrDefault(raw.BlockNumber, 0),
+		BlockHash:         strings.ToLower(raw.BlockHash),
+		TxIndex:           utils.ParseStringToInt64OrDefault(raw.TransactionIndex, 0),
+		FromAddress:       strings.ToLower(raw.From),
+		ToAddress:         strings.ToLower(raw.To),
+		ContractAddress:   strings.ToLower(raw.ContractAddress),
+		GasUsed:           number(raw.GasUsed),
+		GasPrice:          number(raw.EffectiveGasPrice),
+		CumulativeGasUsed: number(raw.CumulativeGasUsed),
+		Type:              utils.ParseStringToInt64OrDefault(raw.Type, 0),
+		Logs:              make([]types.ReceiptLog, 0, len(raw.Logs)),
+	}
+	receipt.L1Fee, receipt.Fee, _ = utils.FeeBreakdown(raw.GasUsed, raw.EffectiveGasPrice, raw.L1Fee, raw.GasUsedForL1)
+	for _, l := range raw.Logs {
+		receipt.Logs = append(receipt.Logs, types.ReceiptLog{
+			Address:  strings.ToLower(l.Address),
+			Topics:   l.Topics,
+			Data:     l.Data,
+			LogIndex: utils.ParseStringToInt64OrDefault(l.LogIndex, 0),
+		})
+	}
+	return receipt
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/cache"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestGetReceipt(t *testing.T) {
+	const hash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), hash) {
+			_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":null}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{
+			"transactionHash":"`+hash+`","blockHash":"0xB1","blockNumber":"0x10","transactionIndex":"0x2",
+			"from":"0xAA","to":"0xBB","status":"0x1","gasUsed":"0x5208","effectiveGasPrice":"0x3b9aca00",
+			"cumulativeGasUsed":"0xa410","type":"0x2",
+			"logs":[{"address":"0xCC","topics":["0xt0"],"data":"0x","logIndex":"0x5"}]}}`)
+	}))
+	defer srv.Close()
+
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.RPCURLs = map[string]string{"eth": srv.URL}
+	cfg.Redis.TTLSeconds = 100
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	svc := NewService(rc, nil)
+	want := &types.Receipt{
+		ServerChainName: "ETH", ChainID: 1, Hash: hash, State: types.TxStateSuccess,
+		Height: 16, BlockHash: "0xb1", TxIndex: 2, FromAddress: "0xaa", ToAddress: "0xbb",
+		GasUsed: "21000", GasPrice: "1000000000", CumulativeGasUsed: "42000", Type: 2,
+		Logs: []types.ReceiptLog{{Address: "0xcc", Topics: []string{"0xt0"}, Data: "0x", LogIndex: 5}},
+	}
+	got, err := svc.GetReceipt("ETH", hash)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = svc.GetReceipt("ETH", hash)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, int32(1), calls.Load(), "the second lookup is served from the cache")
+
+	_, err = svc.GetReceipt("ETH", "0x"+strings.Repeat("0", 64))
+	assert.ErrorIs(t, err, ErrReceiptNotFound)
+}
@@ -1,16 +1,46 @@
 package usecase
 
 import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 	"tx-aggregator/cache"
 	"tx-aggregator/config"
 	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
 	"tx-aggregator/provider"
+	"tx-aggregator/storage"
+	"tx-aggregator/tokenlist"
 	"tx-aggregator/types"
+	"tx-aggregator/utils"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// defaultFanOutTimeout stands in for an unset providers.request_timeout.
+	defaultFanOutTimeout = 60 * time.Second
+	// lockSlack covers the work done under the fetch lock after the
+	// provider fan-out: normalization, storage and the cache write.
+	lockSlack = 10 * time.Second
+
+	defaultDiscoveryPerMinute = 60
 )
 
+// lockPollInterval is how often a waiting instance checks the fetch lock.
+var lockPollInterval = 50 * time.Millisecond
+
+// ErrDiscoveryLimited is returned for a cache miss on an address unknown to
+// the Bloom filters once the discovery budget of the minute is spent.
+var ErrDiscoveryLimited = errors.New("discovery budget for unknown addresses spent")
+
 type Service struct {
 	cache    *cache.RedisCache
 	provider *provider.MultiProvider
+	fetches  singleflight.Group // identical upstream fetches in flight
+	store    storage.Store      // durable history behind the cache; nil when disabled
 }
 
 func NewService(c *cache.RedisCache, p *provider.MultiProvider) *Service {
@@ -20,74 +50,558 @@ func NewService(c *cache.RedisCache, p *provider.MultiProvider) *Service {
 	}
 }
 
+// SetStore adds durable storage behind the cache. Passing nil removes it.
+func (s *Service) SetStore(store storage.Store) {
+	s.store = store
+}
+
 func (s *Service) GetTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
-	logger.Log.Info().
+	log := logger.ForRequest(params.RequestID)
+	log.Debug().
 		Str("address", params.Address).
 		Str("token_address", params.TokenAddress).
 		Interface("chain_names", params.ChainNames).
 		Msg("Starting GetTransactions usecase")
 
+	if config.Current().Warm.Enabled {
+		if err := s.cache.TrackHot(params.Address, params.ChainNames); err != nil {
+			log.Debug().Err(err).Msg("Failed to count query for cache warming")
+		}
+	}
+
+	resp, err := s.loadTransactions(params)
+	if err != nil {
+		return resp, err
+	}
+
+	// Step 5: Post-process the data. Filtering may build a new response.
+	postStart := time.Now()
+	cacheHit := resp.CacheHit
+	resp = s.postProcess(resp, params)
+	resp.CacheHit = cacheHit
+	params.Timings.Since(types.TimingPostProcess, postStart)
+	return resp, nil
+}
+
+// GetTransactionHistory returns the transactions of the address for the
+// requested chains / token, sorted ascending and without the response limit.
+// With durable storage the stored history is included, so it is complete.
+// Without, it is what the cache holds (at most redis.max_cached_txs rows per
+// entry) or a single page-limited fetch, and complete is false once a chain
+// reaches that cap.
+func (s *Service) GetTransactionHistory(params *types.TransactionQueryParams) ([]types.Transaction, bool, error) {
+	resp, err := s.loadTransactions(params)
+	if err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	if s.store != nil {
+		resp.Result.Transactions = withStoredHistory(resp.Result.Transactions, s.storedHistory(params))
+	} else {
+		complete = !reachesCacheCap(resp.Result.Transactions)
+	}
+
+	resp = s.applyFilters(resp, params)
+	SortTransactionResponseByHeightAndIndex(resp, true)
+	resp = SetServerChainNames(resp)
+	return resp.Result.Transactions, complete, nil
+}
+
+// loadTransactions returns the unfiltered transactions of an address, either
+// from cache or from the providers (caching the fresh result).
+func (s *Service) loadTransactions(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	log := logger.ForRequest(params.RequestID)
 	// Step 1: Try reading from cache
+	cacheStart := time.Now()
 	resp, err := s.cache.QueryTxFromCache(params)
 	if err == nil && len(resp.Result.Transactions) > 0 {
-		logger.Log.Debug().
+		params.Timings.Since(types.TimingCacheRead, cacheStart)
+		log.Debug().
 			Int("transaction_count", len(resp.Result.Transactions)).
 			Msg("Transactions loaded from cache")
-		return s.postProcess(resp, params), nil
+		resp.CacheHit = true
+		return resp, nil
+	}
+
+	if err == nil && (params.StartBlock > 0 || params.EndBlock > 0) {
+		// An entry with nothing in the block range is still a hit, so that
+		// clients polling for new blocks do not refetch every time.
+		if ok, exErr := s.cache.HasEntry(params); exErr == nil && ok {
+			params.Timings.Since(types.TimingCacheRead, cacheStart)
+			log.Debug().Msg("Cache hit: no transactions in block range")
+			resp.CacheHit = true
+			return resp, nil
+		}
 	}
 
+	params.Timings.Since(types.TimingCacheRead, cacheStart)
+
 	if err != nil {
-		logger.Log.Warn().Err(err).Msg("Error querying transactions from cache")
+		log.Warn().Err(err).Msg("Error querying transactions from cache")
 	} else {
-		logger.Log.Debug().Msg("Cache miss: no transactions found")
+		log.Debug().Msg("Cache miss: no transactions found")
+	}
+
+	if fetch, err := s.mayHaveHistory(params); !fetch {
+		metrics.BloomShortCircuits.Inc()
+		if err != nil {
+			log.Warn().Str("address", params.Address).Msg("Unknown address over discovery budget, refusing")
+			code := types.CodeRateLimited
+			return &types.TransactionResponse{
+				Code:    code,
+				Message: types.GetMessageByCode(code),
+			}, err
+		}
+		log.Debug().Str("address", params.Address).Msg("Address recently fetched empty, returning empty")
+		return &types.TransactionResponse{}, nil
+	}
+	return s.fetchAndCache(params)
+}
+
+// mayHaveHistory reports whether a cache miss for params should go to the
+// providers. With redis.bloom enabled, an address absent from the Bloom
+// filter of every requested chain is answered empty only if every chain was
+// recently fetched with no transactions; otherwise it is fetched while the
+// discovery budget lasts, and refused with ErrDiscoveryLimited beyond it.
+// Redis errors let the fetch through.
+func (s *Service) mayHaveHistory(params *types.TransactionQueryParams) (bool, error) {
+	cfg := config.Current().Redis.Bloom
+	if !cfg.Enabled {
+		return true, nil
+	}
+	for _, chain := range params.ChainNames {
+		ok, err := s.cache.BloomMayContain(chain, params.Address)
+		if err != nil || ok {
+			return true, nil
+		}
+	}
+	if s.knownEmpty(params) {
+		return false, nil
+	}
+	perMinute := cfg.DiscoveryPerMinute
+	if perMinute <= 0 {
+		perMinute = defaultDiscoveryPerMinute
+	}
+	ok, err := s.cache.TakeDiscoveryToken(perMinute)
+	if err != nil || ok {
+		return true, nil
+	}
+	return false, ErrDiscoveryLimited
+}
+
+// knownEmpty reports whether every requested chain was recently fetched
+// for params.Address with no transactions.
+func (s *Service) knownEmpty(params *types.TransactionQueryParams) bool {
+	if len(params.ChainNames) == 0 {
+		return false
+	}
+	for _, chain := range params.ChainNames {
+		ok, err := s.cache.IsKnownEmpty(chain, params.Address)
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// markEmpty records the requested chains on which a fetch found no
+// transactions, so that repeated lookups of the address are answered
+// without a fetch; see mayHaveHistory.
+func (s *Service) markEmpty(params *types.TransactionQueryParams, txs []types.Transaction) {
+	if !config.Current().Redis.Bloom.Enabled {
+		return
+	}
+	seen := make(map[int64]bool)
+	for _, tx := range txs {
+		seen[tx.ChainID] = true
+	}
+	for _, chain := range params.ChainNames {
+		id, err := utils.ChainIDByName(chain)
+		if err != nil || seen[id] {
+			continue
+		}
+		if err := s.cache.MarkEmpty(chain, params.Address); err != nil {
+			logger.ForRequest(params.RequestID).Debug().Err(err).Str("chain", chain).Msg("Failed to mark empty address")
+		}
+	}
+}
+
+// WarmTransactions fetches the transactions of an address from the providers,
+// bypassing the cache read, and stores them in the cache. It returns the
+// number of transactions cached; used by the backfill job.
+func (s *Service) WarmTransactions(params *types.TransactionQueryParams) (int, error) {
+	resp, err := s.fetchAndCache(params)
+	if err != nil {
+		return 0, err
+	}
+	return len(resp.Result.Transactions), nil
+}
+
+// RefreshTransactions is WarmTransactions returning the fetched rows; used
+// by the watch refresh job to spot new activity.
+func (s *Service) RefreshTransactions(params *types.TransactionQueryParams) ([]types.Transaction, error) {
+	resp, err := s.fetchAndCache(params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Result.Transactions, nil
+}
+
+// fetchAndCache queries the providers, drops rows not involving the address
+// and writes the result to the cache. Identical concurrent calls, such as
+// many clients polling the same hot address on a cache miss, share a single
+// upstream fetch; each caller gets its own copy of the result.
+func (s *Service) fetchAndCache(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	defer params.Timings.Since(types.TimingFetch, time.Now())
+	v, err, shared := s.fetches.Do(fetchKey(params), func() (interface{}, error) {
+		return s.fetchAndCacheOnce(params)
+	})
+	resp, _ := v.(*types.TransactionResponse)
+	if shared {
+		logger.ForRequest(params.RequestID).Debug().Str("address", params.Address).Msg("Shared an in-flight provider fetch")
+		resp = cloneResponse(resp)
+	}
+	return resp, err
+}
+
+// fetchKey identifies the upstream fetch params leads to: address, chains
+// (in any order) and token, case-insensitively, plus the page cap.
+func fetchKey(params *types.TransactionQueryParams) string {
+	chains := make([]string, len(params.ChainNames))
+	for i, c := range params.ChainNames {
+		chains[i] = strings.ToLower(strings.TrimSpace(c))
+	}
+	slices.Sort(chains)
+	return strings.Join([]string{
+		strings.ToLower(params.Address),
+		strings.Join(chains, ","),
+		strings.ToLower(params.TokenAddress),
+		strconv.FormatInt(params.MaxPages, 10),
+	}, "|")
+}
+
+// cloneResponse copies resp deeply enough that callers may filter and sort
+// their copy independently.
+func cloneResponse(resp *types.TransactionResponse) *types.TransactionResponse {
+	if resp == nil {
+		return nil
+	}
+	c := *resp
+	c.Result.Transactions = slices.Clone(resp.Result.Transactions)
+	if resp.Meta != nil {
+		meta := *resp.Meta
+		c.Meta = &meta
+	}
+	return &c
+}
+
+// fetchAndCacheOnce does the work of fetchAndCache. With redis.lock enabled
+// it first takes a distributed lock on the fetch; when another instance
+// holds it, it serves the entry already cached or stored, if any, or else
+// waits for that instance to finish and serves what it cached.
+func (s *Service) fetchAndCacheOnce(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	log := logger.ForRequest(params.RequestID)
+	if lock := config.Current().Redis.Lock; lock.Enabled {
+		name := fetchKey(params)
+		ttl, wait := lockTimings(lock)
+		token, acquired, err := s.cache.TryLock(name, ttl)
+		switch {
+		case err != nil:
+			log.Warn().Err(err).Msg("Failed to take fetch lock, fetching without it")
+		case acquired:
+			defer func() {
+				if err := s.cache.Unlock(name, token); err != nil {
+					log.Warn().Err(err).Msg("Failed to release fetch lock")
+				}
+			}()
+		default:
+			if resp := s.awaitPeerFetch(params, name, wait); resp != nil {
+				return resp, nil
+			}
+		}
+	}
+	return s.fetchAndCacheUnlocked(params)
+}
+
+// awaitPeerFetch serves the rows of params while the holder of the fetch
+// lock name refreshes them. Rows already cached, or else stored, are served
+// at once, stale as they may be; otherwise it waits up to wait for the holder
+// to finish and returns the rows it cached. It returns nil when the holder
+// takes too long or cached nothing, and the caller should fetch itself.
+func (s *Service) awaitPeerFetch(params *types.TransactionQueryParams, name string, wait time.Duration) *types.TransactionResponse {
+	log := logger.ForRequest(params.RequestID)
+	if resp, err := s.cache.QueryTxFromCache(params); err == nil && len(resp.Result.Transactions) > 0 {
+		log.Debug().Str("address", params.Address).Msg("Served cached transactions while another instance refreshes them")
+		return resp
+	}
+	if stored := s.storedHistory(params); len(stored) > 0 {
+		log.Debug().Str("address", params.Address).Msg("Served stored transactions while another instance fetches them")
+		resp := &types.TransactionResponse{}
+		resp.Result.Transactions = stored
+		return resp
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		locked, err := s.cache.Locked(name)
+		if err != nil {
+			return nil
+		}
+		if !locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			log.Debug().Str("address", params.Address).Msg("Fetch lock holder too slow, fetching")
+			return nil
+		}
+		time.Sleep(lockPollInterval)
 	}
 
+	resp, err := s.cache.QueryTxFromCache(params)
+	if err != nil || len(resp.Result.Transactions) == 0 {
+		return nil
+	}
+	log.Debug().Str("address", params.Address).Msg("Served transactions fetched by another instance")
+	return resp
+}
+
+// lockTimings returns the lifetime of the fetch lock and how long others
+// wait for its holder. Both default to the provider fan-out timeout
+// (providers.request_timeout) plus lockSlack, so that a holder still
+// fetching keeps the lock and its peers wait for it rather than fan out too.
+func lockTimings(lock types.CacheLockConfig) (ttl, wait time.Duration) {
+	fanOut := time.Duration(config.Current().Providers.RequestTimeout) * time.Second
+	if fanOut <= 0 {
+		fanOut = defaultFanOutTimeout
+	}
+	ttl = durationMs(lock.TTLMs, fanOut+lockSlack)
+	return ttl, durationMs(lock.WaitMs, ttl)
+}
+
+// durationMs converts a millisecond setting, using def when it is unset.
+func durationMs(ms int64, def time.Duration) time.Duration {
+	if ms <= 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// fetchAndCacheUnlocked fetches from the providers and caches the result.
+func (s *Service) fetchAndCacheUnlocked(params *types.TransactionQueryParams) (*types.TransactionResponse, error) {
+	log := logger.ForRequest(params.RequestID)
 	// Step 2: Fetch from provider
-	logger.Log.Info().Msg("Querying transactions from provider")
-	resp, err = s.provider.GetTransactions(params)
+	log.Info().Msg("Querying transactions from provider")
+	providersStart := time.Now()
+	resp, err := s.provider.GetTransactions(params)
+	params.Timings.Since(types.TimingProviders, providersStart)
 	if err != nil {
-		logger.Log.Error().Err(err).Msg("Provider query failed")
+		log.Error().Err(err).Msg("Provider query failed")
+		if stored := s.storedHistory(params); len(stored) > 0 {
+			log.Warn().Int("stored_transaction_count", len(stored)).Msg("Serving stored history instead")
+			out := &types.TransactionResponse{}
+			out.Result.Transactions = stored
+			return out, nil
+		}
 		code := types.CodeProviderFailed
 		return &types.TransactionResponse{
 			Code:    code,
 			Message: types.GetMessageByCode(code),
 		}, err
 	}
-	logger.Log.Debug().
+	log.Debug().
 		Int("fetched_transaction_count", len(resp.Result.Transactions)).
 		Msg("Transactions fetched from provider")
 
-	// Step 3: Filter by involved address
+	// Step 3: Normalize and filter by involved address
+	resp = normalize(resp, params)
+	s.markEmpty(params, resp.Result.Transactions)
+
+	// Step 3b: Persist, and extend the page-limited fetch with stored history
+	if s.store != nil {
+		s.persist(params.Address, resp.Result.Transactions)
+		resp.Result.Transactions = withStoredHistory(resp.Result.Transactions, s.storedHistory(params))
+	}
+
+	// Step 4: Save to cache
+	writeStart := time.Now()
+	if err := s.cache.ParseTxAndSaveToCache(resp, params.Address); err != nil {
+		log.Warn().Err(err).Msg("Failed to save fetched transactions to cache")
+	} else {
+		log.Debug().Int("cached_transaction_count", len(resp.Result.Transactions)).Msg("Cached transactions successfully")
+	}
+	params.Timings.Since(types.TimingCacheWrite, writeStart)
+
+	return resp, nil
+}
+
+// ReplayTransactions re-runs the current normalization (shadow filtering,
+// native/internal reconciliation, enrichment flags) over the cached rows of
+// an address on params.ChainNames and writes the result back. It works on
+// rows already transformed: classifications made by the provider
+// transforms, such as swap or wrap detection, are not re-run, as the raw
+// payloads are not replayed. Nothing is fetched from the providers. With
+// storage, the kept rows are updated in
+// the store and the dropped ones deleted from it, so that stored history
+// does not bring them back. It returns the number of rows kept and dropped;
+// an address with nothing cached is a no-op.
+func (s *Service) ReplayTransactions(params *types.TransactionQueryParams) (kept, dropped int, err error) {
+	resp, err := s.cache.QueryTxFromCache(params)
+	if err != nil {
+		return 0, 0, err
+	}
+	before := slices.Clone(resp.Result.Transactions) // normalize filters in place
+	if len(before) == 0 {
+		return 0, 0, nil
+	}
+
+	resp = normalize(resp, params)
+	utils.MarkEnrichment(resp.Result.Transactions)
+	if err := s.cache.ReplaceTxInCache(resp, params.Address); err != nil {
+		return 0, 0, err
+	}
+	if s.store != nil {
+		s.persist(params.Address, resp.Result.Transactions)
+		s.forget(params.Address, droppedRows(before, resp.Result.Transactions))
+	}
+	kept = len(resp.Result.Transactions)
+	return kept, len(before) - kept, nil
+}
+
+// droppedRows returns the rows of before whose key is not in after.
+func droppedRows(before, after []types.Transaction) []types.Transaction {
+	keep := make(map[string]bool, len(after))
+	for _, tx := range after {
+		keep[utils.TransactionKey(tx)] = true
+	}
+	var dropped []types.Transaction
+	for _, tx := range before {
+		if !keep[utils.TransactionKey(tx)] {
+			dropped = append(dropped, tx)
+		}
+	}
+	return dropped
+}
+
+// InvalidateTransactions drops the cached transactions of params.Address on
+// params.ChainNames, whatever the token, so the next query refetches them.
+func (s *Service) InvalidateTransactions(params *types.TransactionQueryParams) (*types.CacheInvalidation, error) {
+	removed, err := s.cache.InvalidateAddress(params.Address, params.ChainNames)
+	if err != nil {
+		return nil, err
+	}
+	logger.ForRequest(params.RequestID).Info().
+		Str("address", params.Address).
+		Strs("chain_names", params.ChainNames).
+		Int64("keys_removed", removed).
+		Msg("Cache invalidated")
+	return &types.CacheInvalidation{
+		Address:     params.Address,
+		ChainNames:  params.ChainNames,
+		KeysRemoved: removed,
+	}, nil
+}
+
+// CachedAddresses returns the addresses with cached transactions on chainName.
+func (s *Service) CachedAddresses(chainName string) ([]string, error) {
+	return s.cache.ScanChainAddresses(chainName)
+}
+
+// normalize applies the transform rules shared by fresh provider results and
+// replays of cached rows: shadow and duplicate removal, then dropping rows
+// that do not involve the address.
+func normalize(resp *types.TransactionResponse, params *types.TransactionQueryParams) *types.TransactionResponse {
+	log := logger.ForRequest(params.RequestID)
 	before := len(resp.Result.Transactions)
 
 	FilterNativeShadowTx(resp)
-	logger.Log.Debug().
+	log.Debug().
 		Int("filtered_native_shadow", len(resp.Result.Transactions)).
 		Int("before_filter", before).
 		Msg("Filtered native shadow transactions")
 
+	ReconcileNativeInternal(resp, config.Current().Response.DuplicatePrecedence)
+	log.Debug().
+		Int("reconciled_native_internal", len(resp.Result.Transactions)).
+		Int("before_filter", before).
+		Msg("Reconciled native and internal transfer duplicates")
+
 	resp = FilterTransactionsByInvolvedAddress(resp, params)
-	logger.Log.Debug().
+	log.Debug().
 		Int("filtered_by_address", len(resp.Result.Transactions)).
 		Int("before_filter", before).
 		Msg("Filtered transactions by involved address")
+	return resp
+}
 
-	// Step 4: Save to cache
-	if err := s.cache.ParseTxAndSaveToCache(resp, params.Address); err != nil {
-		logger.Log.Warn().Err(err).Msg("Failed to save fetched transactions to cache")
+func (s *Service) postProcess(resp *types.TransactionResponse, params *types.TransactionQueryParams) *types.TransactionResponse {
+	resp = s.applyFilters(resp, params)
+	if dust := config.Current().DustThresholds; len(dust) > 0 {
+		before := len(resp.Result.Transactions)
+		resp = FilterDustTransactions(resp, dust)
+		logger.ForRequest(params.RequestID).Debug().
+			Int("filtered_dust", len(resp.Result.Transactions)).
+			Int("before_filter", before).
+			Msg("Filtered dust transactions")
+	}
+	if params.MinValue != "" {
+		before := len(resp.Result.Transactions)
+		resp = FilterTransactionsByMinValue(resp, params.MinValue)
+		logger.ForRequest(params.RequestID).Debug().
+			Int("filtered_by_min_value", len(resp.Result.Transactions)).
+			Int("before_filter", before).
+			Msg("Filtered transactions by minimum value")
+	}
+	if params.HideZeroValue {
+		before := len(resp.Result.Transactions)
+		resp = FilterZeroValueTransactions(resp)
+		logger.ForRequest(params.RequestID).Debug().
+			Int("filtered_zero_value", len(resp.Result.Transactions)).
+			Int("before_filter", before).
+			Msg("Filtered zero-value transactions")
+	}
+
+	// Sort and limit
+	SortTransactionResponseByHeightAndIndex(resp, config.Current().Response.Ascending)
+	if params.GroupByChain {
+		resp = LimitTransactionsPerChain(resp, config.Current().Response.Max)
 	} else {
-		logger.Log.Debug().Int("cached_transaction_count", len(resp.Result.Transactions)).Msg("Cached transactions successfully")
+		resp = LimitTransactions(resp, config.Current().Response.Max)
 	}
+	logger.ForRequest(params.RequestID).Debug().
+		Int("final_transaction_count", len(resp.Result.Transactions)).
+		Msg("Final sorted and limited transaction count")
+
+	// Add chain names and token-list icons to response
+	resp = SetServerChainNames(resp)
+	tokenlist.FillIcons(resp.Result.Transactions)
 
-	// Step 5: Post-process the data
-	return s.postProcess(resp, params), nil
+	// Final response setup
+	resp.Code = types.CodeSuccess
+	resp.Message = types.GetMessageByCode(types.CodeSuccess)
+	return resp
 }
 
-func (s *Service) postProcess(resp *types.TransactionResponse, params *types.TransactionQueryParams) *types.TransactionResponse {
+// applyFilters narrows the response down to the supported tokens and the
+// requested chains, token and block range.
+func (s *Service) applyFilters(resp *types.TransactionResponse, params *types.TransactionQueryParams) *types.TransactionResponse {
+	log := logger.ForRequest(params.RequestID)
+	if tf := config.Current().TokenFilter; len(tf.Allow) > 0 || len(tf.Deny) > 0 {
+		before := len(resp.Result.Transactions)
+		resp = FilterTransactionsByTokenLists(resp, tf.Allow, tf.Deny)
+		log.Debug().
+			Int("filtered_by_token_lists", len(resp.Result.Transactions)).
+			Int("before_filter", before).
+			Msg("Filtered transactions by token allow/deny lists")
+	}
+	if params.StartBlock > 0 || params.EndBlock > 0 {
+		resp = FilterTransactionsByBlockRange(resp, params.StartBlock, params.EndBlock)
+	}
+
 	// Filter by chain
 	before := len(resp.Result.Transactions)
 	resp = FilterTransactionsByChainNames(resp, params.ChainNames)
-	logger.Log.Debug().
+	log.Debug().
 		Int("filtered_by_chain", len(resp.Result.Transactions)).
 		Int("before_filter", before).
 		Msg("Filtered transactions by chain")
@@ -97,31 +611,17 @@ func (s *Service) postProcess(resp *types.TransactionResponse, params *types.Tra
 		before = len(resp.Result.Transactions)
 		if params.TokenAddress == types.NativeTokenName {
 			resp = FilterTransactionsByCoinType(resp, types.CoinTypeNative)
-			logger.Log.Debug().
+			log.Debug().
 				Int("filtered_native", len(resp.Result.Transactions)).
 				Int("before_filter", before).
 				Msg("Filtered by native token")
 		} else {
 			resp = FilterTransactionsByTokenAddress(resp, params)
-			logger.Log.Debug().
+			log.Debug().
 				Int("filtered_token", len(resp.Result.Transactions)).
 				Int("before_filter", before).
 				Msg("Filtered by token address")
 		}
 	}
-
-	// Sort and limit
-	SortTransactionResponseByHeightAndIndex(resp, config.Current().Response.Ascending)
-	resp = LimitTransactions(resp, config.Current().Response.Max)
-	logger.Log.Debug().
-		Int("final_transaction_count", len(resp.Result.Transactions)).
-		Msg("Final sorted and limited transaction count")
-
-	// Add chain names to response
-	resp = SetServerChainNames(resp)
-
-	// Final response setup
-	resp.Code = types.CodeSuccess
-	resp.Message = types.GetMessageByCode(types.CodeSuccess)
 	return resp
 }
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/cache"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestFetchKey(t *testing.T) {
+	a := fetchKey(&types.TransactionQueryParams{
+		Address:      "0xAbC",
+		ChainNames:   []string{"ETH", " bsc"},
+		TokenAddress: "0xDEF",
+	})
+	b := fetchKey(&types.TransactionQueryParams{
+		Address:      "0xabc",
+		ChainNames:   []string{"bsc", "eth"},
+		TokenAddress: "0xdef",
+	})
+	assert.Equal(t, a, b, "case and chain order do not matter")
+
+	assert.NotEqual(t, a, fetchKey(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"eth"}, TokenAddress: "0xdef"}))
+	assert.NotEqual(t, a, fetchKey(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"bsc", "eth"}}))
+	assert.NotEqual(t, a, fetchKey(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"bsc", "eth"}, TokenAddress: "0xdef", MaxPages: 50}))
+}
+
+func TestCloneResponse(t *testing.T) {
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = []types.Transaction{{Hash: "0x1"}, {Hash: "0x2"}}
+
+	c := cloneResponse(resp)
+	c.Result.Transactions[0].Hash = "0x9"
+	c.Result.Transactions = c.Result.Transactions[:1]
+
+	assert.Equal(t, "0x1", resp.Result.Transactions[0].Hash)
+	assert.Len(t, resp.Result.Transactions, 2)
+	assert.Nil(t, cloneResponse(nil))
+}
+
+func TestAwaitPeerFetch(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.Redis.TTLSeconds = 100
+	config.SetCurrentConfig(cfg)
+	origPoll := lockPollInterval
+	lockPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		config.SetCurrentConfig(orig)
+		lockPollInterval = origPoll
+	})
+
+	svc := NewService(rc, nil)
+	params := &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}}
+	name := fetchKey(params)
+
+	// Another instance holds the lock, caches its result and releases it.
+	token, ok, err := rc.TryLock(name, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		peer := &types.TransactionResponse{}
+		peer.Result.Transactions = []types.Transaction{{ChainID: 1, Hash: "0x1", CoinType: types.CoinTypeNative}}
+		_ = rc.ParseTxAndSaveToCache(peer, "0xabc")
+		_ = rc.Unlock(name, token)
+	}()
+	resp := svc.awaitPeerFetch(params, name, time.Second)
+	if assert.NotNil(t, resp) {
+		assert.Len(t, resp.Result.Transactions, 1)
+	}
+
+	// While the holder refreshes an address, its cached rows are served at once.
+	_, ok, _ = rc.TryLock(name, time.Minute)
+	assert.True(t, ok)
+	start := time.Now()
+	resp = svc.awaitPeerFetch(params, name, time.Minute)
+	if assert.NotNil(t, resp) {
+		assert.Len(t, resp.Result.Transactions, 1)
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	// A holder that does not finish in time leaves the caller to fetch.
+	other := &types.TransactionQueryParams{Address: "0xdef", ChainNames: []string{"ETH"}}
+	_, ok, _ = rc.TryLock(fetchKey(other), time.Second)
+	assert.True(t, ok)
+	assert.Nil(t, svc.awaitPeerFetch(other, fetchKey(other), 10*time.Millisecond))
+}
+
+func TestLockTimings(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.RequestTimeout = 60
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	ttl, wait := lockTimings(types.CacheLockConfig{})
+	assert.Equal(t, 70*time.Second, ttl, "outlives the fan-out")
+	assert.Equal(t, ttl, wait)
+
+	ttl, wait = lockTimings(types.CacheLockConfig{TTLMs: 90000})
+	assert.Equal(t, 90*time.Second, ttl)
+	assert.Equal(t, 90*time.Second, wait)
+
+	_, wait = lockTimings(types.CacheLockConfig{WaitMs: 500})
+	assert.Equal(t, 500*time.Millisecond, wait)
+}
+
+func TestMayHaveHistory(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+	svc := NewService(rc, nil)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	params := &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH", "BSC"}}
+	fetch, err := svc.mayHaveHistory(params)
+	assert.True(t, fetch, "disabled")
+	assert.NoError(t, err)
+
+	cfg.Redis.Bloom = types.BloomConfig{Enabled: true, DiscoveryPerMinute: 1}
+	config.SetCurrentConfig(cfg)
+	fetch, err = svc.mayHaveHistory(params)
+	assert.True(t, fetch, "first unknown address uses the budget")
+	assert.NoError(t, err)
+	fetch, err = svc.mayHaveHistory(params)
+	assert.False(t, fetch)
+	assert.ErrorIs(t, err, ErrDiscoveryLimited, "budget spent: refused, not answered empty")
+
+	svc.markEmpty(params, []types.Transaction{{ChainID: 56}})
+	fetch, err = svc.mayHaveHistory(params)
+	assert.False(t, fetch)
+	assert.ErrorIs(t, err, ErrDiscoveryLimited, "only ETH is known empty")
+
+	svc.markEmpty(params, nil)
+	fetch, err = svc.mayHaveHistory(params)
+	assert.False(t, fetch)
+	assert.NoError(t, err, "known empty on every chain: answered empty")
+
+	assert.NoError(t, rc.BloomAdd("BSC", "0xabc"))
+	fetch, err = svc.mayHaveHistory(params)
+	assert.True(t, fetch, "known on one of the chains")
+	assert.NoError(t, err)
+}
+
+func TestGetTransactionsCacheHit(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+	svc := NewService(rc, nil)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.Redis.TTLSeconds = 100
+	cfg.Response.Max = 10
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	cached := &types.TransactionResponse{}
+	cached.Result.Transactions = []types.Transaction{{ChainID: 1, Hash: "0x1", FromAddress: "0xabc", CoinType: types.CoinTypeNative}}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(cached, "0xabc"))
+
+	timings := &types.RequestTimings{}
+	resp, err := svc.GetTransactions(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}, Timings: timings})
+	assert.NoError(t, err)
+	assert.True(t, resp.CacheHit)
+	assert.Len(t, resp.Result.Transactions, 1)
+
+	phases := timings.Phases()
+	assert.Contains(t, phases, types.TimingCacheRead)
+	assert.Contains(t, phases, types.TimingPostProcess)
+	assert.NotContains(t, phases, types.TimingFetch, "a cache hit does not fetch")
+}
+
+func TestNormalize_KeepsSwapRow(t *testing.T) {
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = []types.Transaction{
+		{ChainID: 1, Hash: "0x1", FromAddress: "0xabc", ToAddress: "0xrouter", Balance: "0",
+			CoinType: types.CoinTypeNative, Type: types.TxTypeSwap, Swap: &types.SwapInfo{Protocol: types.SwapProtocolUniswapV2}},
+		{ChainID: 1, Hash: "0x1", FromAddress: "0xabc", ToAddress: "0xpair", TokenAddress: "0xusdc", Balance: "5",
+			CoinType: types.CoinTypeToken},
+		{ChainID: 1, Hash: "0x1", FromAddress: "0xpair", ToAddress: "0xabc", TokenAddress: "0xweth", Balance: "1",
+			CoinType: types.CoinTypeToken},
+	}
+	out := normalize(resp, &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}})
+
+	var swaps int
+	for _, tx := range out.Result.Transactions {
+		if tx.Type == types.TxTypeSwap && tx.Swap != nil {
+			swaps++
+		}
+	}
+	assert.Len(t, out.Result.Transactions, 3)
+	assert.Equal(t, 1, swaps, "the swap label survives normalization")
+}
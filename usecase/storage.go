@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"tx-aggregator/cache"
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const defaultStorageTimeout = 2 * time.Second
+
+// storageContext bounds one storage operation by storage.timeout_ms.
+func storageContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), durationMs(config.Current().Storage.TimeoutMs, defaultStorageTimeout))
+}
+
+// persist writes freshly fetched rows to the store. Failures are logged:
+// the cache and the response do not depend on them.
+func (s *Service) persist(address string, txs []types.Transaction) {
+	ctx, cancel := storageContext()
+	defer cancel()
+	if err := s.store.SaveTransactions(ctx, address, txs); err != nil {
+		logger.Log.Warn().Err(err).Str("address", address).Msg("Failed to persist transactions")
+	}
+}
+
+// forget removes rows from the store, such as those a replay no longer
+// serves. Failures are logged.
+func (s *Service) forget(address string, txs []types.Transaction) {
+	ctx, cancel := storageContext()
+	defer cancel()
+	if err := s.store.DeleteTransactions(ctx, address, txs); err != nil {
+		logger.Log.Warn().Err(err).Str("address", address).Msg("Failed to delete stored transactions")
+	}
+}
+
+// storedHistory returns every stored row of the address on the requested
+// chains, whatever the token or block range, since the result is cached as
+// the chain-level entry. It returns nil without a store or on error.
+func (s *Service) storedHistory(params *types.TransactionQueryParams) []types.Transaction {
+	if s.store == nil {
+		return nil
+	}
+	ctx, cancel := storageContext()
+	defer cancel()
+	txs, err := s.store.QueryTransactions(ctx, &types.TransactionQueryParams{
+		Address:    params.Address,
+		ChainNames: params.ChainNames,
+	})
+	if err != nil {
+		logger.ForRequest(params.RequestID).Warn().Err(err).Str("address", params.Address).Msg("Failed to read stored transactions")
+		return nil
+	}
+	return txs
+}
+
+// withStoredHistory adds the stored rows missing from txs, so the history
+// reaches back beyond the cache and the provider page limits. Rows in txs
+// win, as they are at least as fresh.
+func withStoredHistory(txs, stored []types.Transaction) []types.Transaction {
+	seen := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		seen[utils.TransactionKey(tx)] = true
+	}
+	for _, tx := range stored {
+		if k := utils.TransactionKey(tx); !seen[k] {
+			seen[k] = true
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
+
+// reachesCacheCap reports whether any chain holds as many rows as a cache
+// entry keeps, in which case older rows may have been trimmed.
+func reachesCacheCap(txs []types.Transaction) bool {
+	limit := cache.MaxCachedTxs()
+	perChain := make(map[int64]int)
+	for _, tx := range txs {
+		perChain[tx.ChainID]++
+		if perChain[tx.ChainID] >= limit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/cache"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// stubStore keeps saved rows in memory, upserted by utils.TransactionKey,
+// and can be made to fail.
+type stubStore struct {
+	saved   map[string][]types.Transaction
+	queried []types.TransactionQueryParams
+	err     error
+}
+
+func (s *stubStore) SaveTransactions(_ context.Context, address string, txs []types.Transaction) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.saved == nil {
+		s.saved = make(map[string][]types.Transaction)
+	}
+	for _, tx := range txs {
+		i := slices.IndexFunc(s.saved[address], func(old types.Transaction) bool {
+			return utils.TransactionKey(old) == utils.TransactionKey(tx)
+		})
+		if i < 0 {
+			s.saved[address] = append(s.saved[address], tx)
+		} else {
+			s.saved[address][i] = tx
+		}
+	}
+	return nil
+}
+
+func (s *stubStore) DeleteTransactions(_ context.Context, address string, txs []types.Transaction) error {
+	if s.err != nil {
+		return s.err
+	}
+	drop := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		drop[utils.TransactionKey(tx)] = true
+	}
+	s.saved[address] = slices.DeleteFunc(s.saved[address], func(tx types.Transaction) bool {
+		return drop[utils.TransactionKey(tx)]
+	})
+	return nil
+}
+
+func (s *stubStore) QueryTransactions(_ context.Context, params *types.TransactionQueryParams) ([]types.Transaction, error) {
+	s.queried = append(s.queried, *params)
+	return s.saved[params.Address], s.err
+}
+
+func (s *stubStore) Close() error { return nil }
+
+func TestStoredHistory(t *testing.T) {
+	svc := NewService(nil, nil)
+	assert.Nil(t, svc.storedHistory(&types.TransactionQueryParams{Address: "0xabc"}), "no store")
+
+	store := &stubStore{}
+	svc.SetStore(store)
+	svc.persist("0xabc", []types.Transaction{{Hash: "0x1"}, {Hash: "0x2"}})
+
+	got := svc.storedHistory(&types.TransactionQueryParams{
+		Address: "0xabc", ChainNames: []string{"ETH"}, TokenAddress: "0xtoken", StartBlock: 5,
+	})
+	assert.Len(t, got, 2)
+	assert.Equal(t, []types.TransactionQueryParams{{Address: "0xabc", ChainNames: []string{"ETH"}}}, store.queried,
+		"the whole chain-level history is read, as it is cached")
+
+	store.err = errors.New("db down")
+	svc.persist("0xabc", []types.Transaction{{Hash: "0x3"}})
+	assert.Nil(t, svc.storedHistory(&types.TransactionQueryParams{Address: "0xabc"}))
+}
+
+func TestGetTransactionHistory_Completeness(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+	svc := NewService(rc, nil)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.Redis.TTLSeconds = 100
+	cfg.Redis.MaxCachedTxs = 2
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	row := func(hash string, height int64) types.Transaction {
+		return types.Transaction{ChainID: 1, Hash: hash, Height: height, FromAddress: "0xabc", CoinType: types.CoinTypeNative}
+	}
+	cached := &types.TransactionResponse{}
+	cached.Result.Transactions = []types.Transaction{row("0x1", 1), row("0x2", 2), row("0x3", 3)}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(cached, "0xabc"))
+	params := &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}}
+
+	txs, complete, err := svc.GetTransactionHistory(params)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2, "the cache kept the newest rows")
+	assert.False(t, complete, "without storage the cap may have cut the history")
+	portfolio, err := svc.GetPortfolio(params, 1)
+	assert.NoError(t, err)
+	assert.True(t, portfolio.Truncated, "balances built from a cut history are partial")
+
+	svc.SetStore(&stubStore{saved: map[string][]types.Transaction{"0xabc": cached.Result.Transactions}})
+	txs, complete, err = svc.GetTransactionHistory(params)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash)
+	}
+	assert.Equal(t, []string{"0x1", "0x2", "0x3"}, hashes, "stored rows fill in what the cache trimmed")
+	portfolio, err = svc.GetPortfolio(params, 1)
+	assert.NoError(t, err)
+	assert.False(t, portfolio.Truncated)
+}
+
+func TestReplayTransactions_UpdatesStore(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+	svc := NewService(rc, nil)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1}
+	cfg.Redis.TTLSeconds = 100
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	rows := []types.Transaction{
+		{ChainID: 1, Hash: "0x1", FromAddress: "0xabc", ToAddress: "0xtoken", Balance: "0", CoinType: types.CoinTypeNative},
+		{ChainID: 1, Hash: "0x1", FromAddress: "0xabc", ToAddress: "0xdef", TokenAddress: "0xtoken", Balance: "5", CoinType: types.CoinTypeToken},
+	}
+	cached := &types.TransactionResponse{}
+	cached.Result.Transactions = rows
+	assert.NoError(t, rc.ParseTxAndSaveToCache(cached, "0xabc"))
+	store := &stubStore{saved: map[string][]types.Transaction{"0xabc": slices.Clone(rows)}}
+	svc.SetStore(store)
+
+	kept, dropped, err := svc.ReplayTransactions(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, kept)
+	assert.Equal(t, 1, dropped)
+	assert.Len(t, store.saved["0xabc"], 1, "the shadow row is deleted from the store")
+	assert.Equal(t, types.CoinTypeToken, store.saved["0xabc"][0].CoinType)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// GetTokens returns the token contracts the address has transfers of on the
+// requested chains, read from the token sets recorded when its transactions
+// were cached, with the symbol and decimals of their cached transfers. An
+// address with nothing cached is fetched first, which records its token
+// sets. Tokens are ordered by chain ID and token address.
+func (s *Service) GetTokens(params *types.TransactionQueryParams) ([]types.TokenMeta, error) {
+	log := logger.ForRequest(params.RequestID)
+	if ok, err := s.cache.HasEntry(params); err != nil || !ok {
+		if _, err := s.loadTransactions(params); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens := []types.TokenMeta{}
+	for _, chain := range params.ChainNames {
+		chainID, err := utils.ChainIDByName(chain)
+		if err != nil {
+			continue
+		}
+		set, err := s.cache.TokenSet(params.Address, chain)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(set))
+		for _, token := range set {
+			meta := types.TokenMeta{ChainID: chainID, TokenAddress: strings.ToLower(token)}
+			if seen[meta.TokenAddress] {
+				continue // the set keeps the case providers reported
+			}
+			seen[meta.TokenAddress] = true
+			rows, err := s.cache.QueryTxFromCache(&types.TransactionQueryParams{
+				Address:      params.Address,
+				ChainNames:   []string{chain},
+				TokenAddress: meta.TokenAddress,
+			})
+			if err != nil {
+				log.Debug().Err(err).Str("chain", chain).Str("token", token).Msg("Failed to read cached token transfers")
+			}
+			for _, tx := range rows.Result.Transactions {
+				if tx.TokenDisplayName != "" {
+					meta.TokenDisplayName = tx.TokenDisplayName
+					meta.Decimals = tx.Decimals
+					break
+				}
+			}
+			tokens = append(tokens, meta)
+		}
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].ChainID != tokens[j].ChainID {
+			return tokens[i].ChainID < tokens[j].ChainID
+		}
+		return tokens[i].TokenAddress < tokens[j].TokenAddress
+	})
+	return tokens, nil
+}
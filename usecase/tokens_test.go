@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/cache"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestGetTokens(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer s.Close()
+	rc, err := cache.NewRedisCache(types.RedisConfig{Addrs: []string{s.Addr()}})
+	assert.NoError(t, err)
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	cfg.Redis.TTLSeconds = 100
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	resp := &types.TransactionResponse{}
+	resp.Result.Transactions = []types.Transaction{
+		{ChainID: 1, Hash: "0x1", CoinType: types.CoinTypeNative, TokenDisplayName: "ETH", Decimals: 18},
+		{ChainID: 1, Hash: "0x2", CoinType: types.CoinTypeToken, TokenAddress: "0xUSDT", TokenDisplayName: "USDT", Decimals: 6},
+		{ChainID: 56, Hash: "0x3", CoinType: types.CoinTypeToken, TokenAddress: "0xcake", TokenDisplayName: "Cake", Decimals: 18},
+		{ChainID: 1, Hash: "0x4", CoinType: types.CoinTypeToken, TokenAddress: "0xdai", TokenDisplayName: "DAI", Decimals: 18},
+	}
+	assert.NoError(t, rc.ParseTxAndSaveToCache(resp, "0xabc"))
+
+	svc := NewService(rc, nil)
+	tokens, err := svc.GetTokens(&types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"BSC", "ETH"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.TokenMeta{
+		{ChainID: 1, TokenAddress: "0xdai", TokenDisplayName: "DAI", Decimals: 18},
+		{ChainID: 1, TokenAddress: "0xusdt", TokenDisplayName: "USDT", Decimals: 6},
+		{ChainID: 56, TokenAddress: "0xcake", TokenDisplayName: "Cake", Decimals: 18},
+	}, tokens)
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// addressFormat validates the addresses of one address family and returns
+// their canonical spelling, under which they are cached and queried.
+type addressFormat struct {
+	family    string
+	normalize func(addr string) (string, bool)
+}
+
+// addressFormats is the address validation registry, in detection order:
+// an address readable by several of the requested families (every EVM
+// address is also a felt and an Aptos address) goes to the first of them.
+var addressFormats = []addressFormat{
+	{types.AddressFamilyEVM, normalizeEVMAddress},
+	{types.AddressFamilyStarknet, StarknetToCanonical}, // lowercase, padded to 64 digits
+	{types.AddressFamilyAptos, AptosToCanonical},       // lowercase, padded to 64 digits
+	{types.AddressFamilyTON, TonToRaw},                 // bounceable / non-bounceable / raw → raw
+	{types.AddressFamilyTron, normalizeTronAddress},
+	{types.AddressFamilyUTXO, normalizeBitcoinAddress},
+}
+
+// NormalizeAddress validates addr against the format of family and returns
+// its canonical spelling. It returns false for an unknown family.
+func NormalizeAddress(family, addr string) (string, bool) {
+	for _, f := range addressFormats {
+		if f.family == family {
+			return f.normalize(addr)
+		}
+	}
+	return "", false
+}
+
+// DetectAddressFamily returns the first family of the registry, among
+// families, whose format accepts addr, together with the canonical address.
+func DetectAddressFamily(addr string, families map[string]bool) (family, normalized string, ok bool) {
+	for _, f := range addressFormats {
+		if !families[f.family] {
+			continue
+		}
+		if normalized, ok := f.normalize(addr); ok {
+			return f.family, normalized, true
+		}
+	}
+	return "", "", false
+}
+
+// ChainAddressFamily returns the address family of a chain: the one set in
+// address_families if any, otherwise utxo for chains listed in the Esplora
+// config, tron / ton / starknet / aptos for chains listed in the Tron / TON /
+// Starknet / Aptos configs and evm for every other chain.
+func ChainAddressFamily(chainName string) string {
+	return config.Current().ChainAddressFamily(chainName)
+}
+
+// ChainAddressFamilies returns the set of address families of chainNames.
+func ChainAddressFamilies(chainNames []string) map[string]bool {
+	families := make(map[string]bool, len(chainNames))
+	for _, name := range chainNames {
+		families[ChainAddressFamily(name)] = true
+	}
+	return families
+}
+
+func normalizeEVMAddress(addr string) (string, bool) {
+	return strings.ToLower(addr), IsValidEthereumAddress(addr)
+}
+
+// normalizeTronAddress keeps base58, which is case-sensitive, as is.
+func normalizeTronAddress(addr string) (string, bool) {
+	return addr, IsValidTronAddress(addr)
+}
+
+// normalizeBitcoinAddress lowercases bech32 addresses and keeps base58 ones,
+// which are case-sensitive, as is.
+func normalizeBitcoinAddress(addr string) (string, bool) {
+	if IsBech32Address(addr) {
+		return strings.ToLower(addr), true
+	}
+	return addr, isValidBase58Address(addr)
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestDetectAddressFamily(t *testing.T) {
+	const evm = "0x0123456789ABCDEF0123456789abcdef01234567"
+	all := map[string]bool{
+		types.AddressFamilyEVM: true, types.AddressFamilyStarknet: true, types.AddressFamilyUTXO: true,
+		types.AddressFamilyTron: true, types.AddressFamilyTON: true, types.AddressFamilyAptos: true,
+	}
+
+	family, normalized, ok := DetectAddressFamily(evm, all)
+	assert.True(t, ok)
+	assert.Equal(t, types.AddressFamilyEVM, family, "EVM wins over felt and Aptos")
+	assert.Equal(t, "0x0123456789abcdef0123456789abcdef01234567", normalized)
+
+	family, normalized, ok = DetectAddressFamily(evm, map[string]bool{types.AddressFamilyStarknet: true})
+	assert.True(t, ok)
+	assert.Equal(t, types.AddressFamilyStarknet, family)
+	assert.Equal(t, "0x0000000000000000000000000123456789abcdef0123456789abcdef01234567", normalized)
+
+	family, normalized, ok = DetectAddressFamily("BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", all)
+	assert.True(t, ok)
+	assert.Equal(t, types.AddressFamilyUTXO, family)
+	assert.Equal(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", normalized)
+
+	family, _, ok = DetectAddressFamily("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", all)
+	assert.True(t, ok)
+	assert.Equal(t, types.AddressFamilyTron, family)
+
+	_, _, ok = DetectAddressFamily("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", map[string]bool{types.AddressFamilyEVM: true})
+	assert.False(t, ok, "only the given families are tried")
+	_, _, ok = DetectAddressFamily("invalid", all)
+	assert.False(t, ok)
+
+	_, ok = NormalizeAddress("cosmos", evm)
+	assert.False(t, ok, "unknown family")
+}
+
+func TestChainAddressFamily(t *testing.T) {
+	orig := config.Current()
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	cfg := orig
+	cfg.Esplora = []types.EsploraConfig{{ChainName: "BTC"}}
+	cfg.Tron = []types.TronConfig{{ChainName: "TRON"}}
+	cfg.AddressFamilies = map[string]string{"sui": types.AddressFamilyAptos}
+	config.SetCurrentConfig(cfg)
+
+	assert.Equal(t, types.AddressFamilyUTXO, ChainAddressFamily("btc"))
+	assert.Equal(t, types.AddressFamilyTron, ChainAddressFamily("TRON"))
+	assert.Equal(t, types.AddressFamilyAptos, ChainAddressFamily("SUI"), "address_families wins")
+	assert.Equal(t, types.AddressFamilyEVM, ChainAddressFamily("ETH"))
+	assert.Equal(t, map[string]bool{types.AddressFamilyEVM: true, types.AddressFamilyUTXO: true},
+		ChainAddressFamilies([]string{"ETH", "BSC", "BTC"}))
+}
@@ -0,0 +1,44 @@
+package utils
+
+import "strings"
+
+// AptosToCanonical validates an Aptos account address ("0x" followed by up
+// to 64 hex digits; special addresses such as 0x1 are usually written short)
+// and returns its long form: lowercase and zero-padded to 64 digits.
+func AptosToCanonical(addr string) (string, bool) {
+	if len(addr) < 3 || len(addr) > 66 || (addr[:2] != "0x" && addr[:2] != "0X") {
+		return "", false
+	}
+	digits := strings.ToLower(addr[2:])
+	for _, c := range digits {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", false
+		}
+	}
+	return "0x" + strings.Repeat("0", 64-len(digits)) + digits, true
+}
+
+// IsValidAptosAddress checks if addr is an Aptos account address. Note that
+// every EVM address is also a valid Aptos address; callers test EVM first.
+func IsValidAptosAddress(addr string) bool {
+	_, ok := AptosToCanonical(addr)
+	return ok
+}
+
+// AptosAssetToCanonical canonicalizes an Aptos asset identifier: either a
+// fungible asset metadata address ("0xa") or a coin type
+// ("0x1::aptos_coin::AptosCoin"), whose leading address is put in long form.
+func AptosAssetToCanonical(asset string) (string, bool) {
+	addr, rest, isType := strings.Cut(asset, "::")
+	canonical, ok := AptosToCanonical(addr)
+	if !ok {
+		return "", false
+	}
+	if !isType {
+		return canonical, true
+	}
+	if rest == "" {
+		return "", false
+	}
+	return canonical + "::" + rest, true
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAptosToCanonical(t *testing.T) {
+	const one = "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+	got, ok := AptosToCanonical("0x1")
+	assert.True(t, ok)
+	assert.Equal(t, one, got)
+
+	got, ok = AptosToCanonical("0xF22BEDE237A07E121B56D91A491EB7BCDFD1F5907926A9E58338F964A01B17FA")
+	assert.True(t, ok)
+	assert.Equal(t, "0xf22bede237a07e121b56d91a491eb7bcdfd1f5907926a9e58338f964a01b17fa", got)
+
+	_, ok = AptosToCanonical("0x")
+	assert.False(t, ok)
+	_, ok = AptosToCanonical("0x" + "1" + one[2:]) // 65 digits
+	assert.False(t, ok)
+	assert.False(t, IsValidAptosAddress("1"))
+}
+
+func TestAptosAssetToCanonical(t *testing.T) {
+	got, ok := AptosAssetToCanonical("0x1::aptos_coin::AptosCoin")
+	assert.True(t, ok)
+	assert.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000001::aptos_coin::AptosCoin", got)
+
+	got, ok = AptosAssetToCanonical("0xA")
+	assert.True(t, ok)
+	assert.Equal(t, "0x000000000000000000000000000000000000000000000000000000000000000a", got)
+
+	_, ok = AptosAssetToCanonical("0x1::")
+	assert.False(t, ok)
+	_, ok = AptosAssetToCanonical("aptos_coin::AptosCoin")
+	assert.False(t, ok)
+}
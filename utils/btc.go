@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// bech32Charset maps 5-bit values to bech32 characters.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants of BIP-173 (bech32, witness v0) and BIP-350 (bech32m, v1+).
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// base58Versions are the accepted version bytes of base58check addresses:
+// P2PKH and P2SH on mainnet and testnet.
+var base58Versions = map[byte]bool{0x00: true, 0x05: true, 0x6f: true, 0xc4: true}
+
+// bech32HRPs are the accepted segwit human-readable parts.
+var bech32HRPs = map[string]bool{"bc": true, "tb": true, "bcrt": true}
+
+// IsValidBitcoinAddress checks if addr is a valid legacy (base58check P2PKH /
+// P2SH) or segwit (bech32 / bech32m) Bitcoin address on mainnet, testnet or
+// regtest.
+func IsValidBitcoinAddress(addr string) bool {
+	if IsBech32Address(addr) {
+		return true
+	}
+	return isValidBase58Address(addr)
+}
+
+// IsBech32Address reports whether addr is a valid segwit address. Bech32
+// addresses are case-insensitive and may be lowercased; base58 ones may not.
+func IsBech32Address(addr string) bool {
+	if len(addr) < 14 || len(addr) > 90 {
+		return false
+	}
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return false // mixed case is not allowed
+	}
+	addr = strings.ToLower(addr)
+
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || sep+7 > len(addr) {
+		return false
+	}
+	hrp := addr[:sep]
+	if !bech32HRPs[hrp] {
+		return false
+	}
+
+	data := make([]byte, 0, len(addr)-sep-1)
+	for _, c := range addr[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return false
+		}
+		data = append(data, byte(v))
+	}
+
+	checksum := bech32Polymod(append(bech32HRPExpand(hrp), data...))
+	payload := data[:len(data)-6]
+	if len(payload) == 0 {
+		return false
+	}
+	version := payload[0]
+	switch {
+	case version == 0 && checksum != bech32Const:
+		return false
+	case version > 0 && checksum != bech32mConst:
+		return false
+	case version > 16:
+		return false
+	}
+
+	program, ok := convertBits(payload[1:], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 {
+		return false
+	}
+	// Witness v0 programs are P2WPKH (20 bytes) or P2WSH (32 bytes).
+	return version != 0 || len(program) == 20 || len(program) == 32
+}
+
+// isValidBase58Address decodes a base58check address and verifies its
+// version byte, length and checksum.
+func isValidBase58Address(addr string) bool {
+	if len(addr) < 26 || len(addr) > 35 {
+		return false
+	}
+	payload, ok := base58CheckDecode(addr)
+	return ok && len(payload) == 21 && base58Versions[payload[0]]
+}
+
+// base58CheckDecode decodes s and verifies its 4-byte double-SHA256
+// checksum, returning the payload (version byte included).
+func base58CheckDecode(s string) ([]byte, bool) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range s {
+		v := strings.IndexRune(base58Alphabet, c)
+		if v < 0 {
+			return nil, false
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+	decoded := n.Bytes()
+	// Each leading '1' encodes a leading zero byte.
+	for i := 0; i < len(s) && s[i] == '1'; i++ {
+		decoded = append([]byte{0}, decoded...)
+	}
+	if len(decoded) < 5 {
+		return nil, false
+	}
+
+	payload, sum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	check := base58Checksum(payload)
+	for i := range sum {
+		if sum[i] != check[i] {
+			return nil, false
+		}
+	}
+	return payload, true
+}
+
+// base58CheckEncode appends the checksum to payload and encodes it.
+func base58CheckEncode(payload []byte) string {
+	check := base58Checksum(payload)
+	full := append(append([]byte{}, payload...), check[:4]...)
+
+	n := new(big.Int).SetBytes(full)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range full {
+		if b != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// base58Checksum returns the first 4 bytes of sha256(sha256(payload)).
+func base58Checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// bech32Polymod computes the BIP-173 checksum polynomial.
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand expands the human-readable part for checksum computation.
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range hrp {
+		out = append(out, byte(c>>5))
+	}
+	out = append(out, 0)
+	for _, c := range hrp {
+		out = append(out, byte(c&31))
+	}
+	return out
+}
+
+// convertBits regroups bits from fromBits-wide to toBits-wide groups without
+// padding, as required when decoding a witness program.
+func convertBits(data []byte, fromBits, toBits uint) ([]byte, bool) {
+	var (
+		acc  uint32
+		bits uint
+		out  []byte
+	)
+	maxv := uint32(1)<<toBits - 1
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits >= fromBits || (acc<<(toBits-bits))&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
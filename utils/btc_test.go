@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidBitcoinAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		valid   bool
+	}{
+		{name: "P2PKH", address: "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", valid: true},
+		{name: "P2SH", address: "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", valid: true},
+		{name: "genesis P2PKH", address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", valid: true},
+		{name: "P2WPKH", address: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", valid: true},
+		{name: "P2WPKH uppercase", address: "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", valid: true},
+		{name: "P2TR (bech32m)", address: "bc1p5d7rjq7g6rdk2yhzks9smlaqtedr4dekq08ge8ztwac72sfr9rusxg3297", valid: true},
+		{name: "testnet P2WPKH", address: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", valid: true},
+		{name: "invalid: bad base58 checksum", address: "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", valid: false},
+		{name: "invalid: base58 forbidden char", address: "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN0", valid: false},
+		{name: "invalid: bech32 mixed case", address: "bc1QW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", valid: false},
+		{name: "invalid: bech32 bad checksum", address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", valid: false},
+		{name: "invalid: v1 with bech32 checksum", address: "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7k7grplx", valid: false},
+		{name: "invalid: unknown hrp", address: "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", valid: false},
+		{name: "invalid: ethereum address", address: "0x0123456789abcdef0123456789abcdef01234567", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, IsValidBitcoinAddress(tt.address))
+		})
+	}
+}
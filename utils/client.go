@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+const (
+	// defaultTimeout and defaultResponseHeaderTimeout keep a stalled
+	// upstream from holding a connection forever; they are above the
+	// slowest configured RPC calls (rpc_request_timeout, 90s).
+	defaultTimeout               = 120 * time.Second
+	defaultResponseHeaderTimeout = 90 * time.Second
+	defaultDialTimeout           = 10 * time.Second
+	defaultKeepAlive             = 30 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConns          = 100
+	defaultMaxIdleConnsPerHost   = 16
+)
+
+var (
+	clientMu  sync.Mutex
+	client    *http.Client
+	clientCfg types.HTTPClientConfig
+)
+
+// ProviderClient returns the HTTP client shared by all provider requests,
+// tuned by providers.http_client and wrapped in the VCR. Requests that do
+// not go through DoHttpRequestWithLogging use it directly. A configuration
+// change replaces the client; the idle connections of the old one are
+// closed.
+func ProviderClient() *http.Client {
+	cfg := config.Current().Providers.HTTPClient
+
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client != nil && cfg == clientCfg {
+		return client
+	}
+	if client != nil {
+		client.CloseIdleConnections()
+	}
+	client, clientCfg = newProviderClient(cfg), cfg
+	return client
+}
+
+// newProviderClient builds a client from cfg, applying defaults.
+func newProviderClient(cfg types.HTTPClientConfig) *http.Client {
+	seconds := func(v int64, def time.Duration) time.Duration {
+		if v > 0 {
+			return time.Duration(v) * time.Second
+		}
+		return def
+	}
+	orDefault := func(v, def int) int {
+		if v > 0 {
+			return v
+		}
+		return def
+	}
+
+	// Negative timeouts disable the limit
+	limit := func(v int64, def time.Duration) time.Duration {
+		if v < 0 {
+			return 0
+		}
+		return seconds(v, def)
+	}
+
+	keepAlive := seconds(cfg.KeepAlive, defaultKeepAlive)
+	if cfg.KeepAlive < 0 {
+		keepAlive = -1 // disables TCP keep-alive probes
+	}
+	dialer := &net.Dialer{
+		Timeout:   seconds(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: keepAlive,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = seconds(cfg.TLSHandshakeTimeout, defaultTLSHandshakeTimeout)
+	transport.ResponseHeaderTimeout = limit(cfg.ResponseHeaderTimeout, defaultResponseHeaderTimeout)
+	transport.IdleConnTimeout = seconds(cfg.IdleConnTimeout, defaultIdleConnTimeout)
+	transport.MaxIdleConns = orDefault(cfg.MaxIdleConns, defaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = orDefault(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost)
+	transport.MaxConnsPerHost = max(cfg.MaxConnsPerHost, 0)
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+
+	return &http.Client{
+		Transport: &vcrTransport{base: transport},
+		Timeout:   limit(cfg.Timeout, defaultTimeout),
+	}
+}
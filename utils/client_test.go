@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// withHTTPClientConfig sets providers.http_client for the duration of a test.
+func withHTTPClientConfig(t *testing.T, c types.HTTPClientConfig) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.HTTPClient = c
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func transportOf(c *http.Client) *http.Transport {
+	return c.Transport.(*vcrTransport).base.(*http.Transport)
+}
+
+func TestProviderClient_Defaults(t *testing.T) {
+	withHTTPClientConfig(t, types.HTTPClientConfig{})
+
+	c := ProviderClient()
+	tr := transportOf(c)
+	assert.Equal(t, defaultTimeout, c.Timeout)
+	assert.Equal(t, defaultResponseHeaderTimeout, tr.ResponseHeaderTimeout)
+	assert.Equal(t, defaultMaxIdleConns, tr.MaxIdleConns)
+	assert.Equal(t, defaultMaxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, defaultIdleConnTimeout, tr.IdleConnTimeout)
+	assert.Equal(t, defaultTLSHandshakeTimeout, tr.TLSHandshakeTimeout)
+	assert.False(t, tr.DisableKeepAlives)
+	assert.Same(t, c, ProviderClient(), "reused while the configuration is unchanged")
+}
+
+func TestProviderClient_RebuiltOnConfigChange(t *testing.T) {
+	withHTTPClientConfig(t, types.HTTPClientConfig{})
+	before := ProviderClient()
+
+	withHTTPClientConfig(t, types.HTTPClientConfig{
+		Timeout:               20,
+		ResponseHeaderTimeout: 5,
+		MaxIdleConnsPerHost:   64,
+		MaxConnsPerHost:       128,
+		DisableKeepAlives:     true,
+	})
+	after := ProviderClient()
+	assert.NotSame(t, before, after)
+
+	tr := transportOf(after)
+	assert.Equal(t, 20*time.Second, after.Timeout)
+	assert.Equal(t, 5*time.Second, tr.ResponseHeaderTimeout)
+	assert.Equal(t, 64, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 128, tr.MaxConnsPerHost)
+	assert.True(t, tr.DisableKeepAlives)
+
+	withHTTPClientConfig(t, types.HTTPClientConfig{Timeout: -1, ResponseHeaderTimeout: -1})
+	unlimited := ProviderClient()
+	assert.Equal(t, time.Duration(0), unlimited.Timeout)
+	assert.Equal(t, time.Duration(0), transportOf(unlimited).ResponseHeaderTimeout)
+}
@@ -0,0 +1,10 @@
+package utils
+
+// IntOr returns v, or def when v is not positive: the default of a numeric
+// setting left unset.
+func IntOr[T int | int64](v, def T) T {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
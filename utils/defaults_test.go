@@ -0,0 +1,14 @@
+package utils_test
+
+import (
+	"testing"
+	"tx-aggregator/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntOr(t *testing.T) {
+	assert.Equal(t, 5, utils.IntOr(5, 10))
+	assert.Equal(t, 10, utils.IntOr(0, 10))
+	assert.Equal(t, int64(10), utils.IntOr(int64(-1), 10))
+}
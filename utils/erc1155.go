@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ERC1155TransferSingleTopic is
+	// keccak256("TransferSingle(address,address,address,uint256,uint256)").
+	ERC1155TransferSingleTopic = "0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62"
+	// ERC1155TransferBatchTopic is
+	// keccak256("TransferBatch(address,address,address,uint256[],uint256[])").
+	ERC1155TransferBatchTopic = "0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"
+)
+
+// ERC1155Transfer is one id/value pair moved by an ERC-1155 TransferSingle
+// or TransferBatch event. Addresses are lowercase, TokenID and Value decimal.
+type ERC1155Transfer struct {
+	Operator string
+	From     string
+	To       string
+	TokenID  string
+	Value    string
+}
+
+// IsERC1155Event reports whether topics are those of an ERC-1155
+// TransferSingle or TransferBatch event: the signature and the indexed
+// operator, from and to.
+func IsERC1155Event(topics []string) bool {
+	if len(topics) != 4 {
+		return false
+	}
+	topic0 := strings.ToLower(topics[0])
+	return topic0 == ERC1155TransferSingleTopic || topic0 == ERC1155TransferBatchTopic
+}
+
+// DecodeERC1155Event decodes an ERC-1155 transfer event into its id/value
+// pairs: one for TransferSingle, one per id for TransferBatch.
+func DecodeERC1155Event(topics []string, data string) ([]ERC1155Transfer, error) {
+	if !IsERC1155Event(topics) {
+		return nil, fmt.Errorf("not an ERC-1155 transfer event")
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid event data: %w", err)
+	}
+	base := ERC1155Transfer{
+		Operator: topicToAddress(topics[1]),
+		From:     topicToAddress(topics[2]),
+		To:       topicToAddress(topics[3]),
+	}
+
+	if strings.ToLower(topics[0]) == ERC1155TransferSingleTopic {
+		if len(b) < 64 {
+			return nil, fmt.Errorf("TransferSingle data is %d bytes, want 64", len(b))
+		}
+		base.TokenID = abiWord(b, 0).String()
+		base.Value = abiWord(b, 1).String()
+		return []ERC1155Transfer{base}, nil
+	}
+
+	ids, err := abiUintArray(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("TransferBatch ids: %w", err)
+	}
+	values, err := abiUintArray(b, 1)
+	if err != nil {
+		return nil, fmt.Errorf("TransferBatch values: %w", err)
+	}
+	if len(ids) != len(values) {
+		return nil, fmt.Errorf("TransferBatch has %d ids but %d values", len(ids), len(values))
+	}
+	transfers := make([]ERC1155Transfer, len(ids))
+	for i := range ids {
+		t := base
+		t.TokenID = ids[i].String()
+		t.Value = values[i].String()
+		transfers[i] = t
+	}
+	return transfers, nil
+}
+
+// TokenIDToInt64 returns the decimal token ID id as an int64 for
+// Transaction.TokenID, or 0 when it does not fit.
+func TokenIDToInt64(id string) int64 {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// topicToAddress extracts the lowercase address from a 32-byte indexed topic.
+func topicToAddress(topic string) string {
+	topic = strings.ToLower(strings.TrimPrefix(topic, "0x"))
+	if len(topic) < 40 {
+		return ""
+	}
+	return "0x" + topic[len(topic)-40:]
+}
+
+// abiWord returns the i-th 32-byte word of b as an unsigned integer. The
+// caller checks that b is long enough.
+func abiWord(b []byte, i int) *big.Int {
+	return new(big.Int).SetBytes(b[32*i : 32*(i+1)])
+}
+
+// abiUintArray decodes the dynamic uint256[] whose offset is the i-th head
+// word of b.
+func abiUintArray(b []byte, i int) ([]*big.Int, error) {
+	if len(b) < 32*(i+1) {
+		return nil, fmt.Errorf("data too short")
+	}
+	offset := abiWord(b, i)
+	if !offset.IsInt64() || offset.Int64()%32 != 0 || offset.Int64()+32 > int64(len(b)) {
+		return nil, fmt.Errorf("invalid offset %s", offset)
+	}
+	start := int(offset.Int64() / 32)
+	length := abiWord(b, start)
+	if !length.IsInt64() || int64(start+1)+length.Int64() > int64(len(b)/32) {
+		return nil, fmt.Errorf("invalid length %s", length)
+	}
+	out := make([]*big.Int, length.Int64())
+	for j := range out {
+		out[j] = abiWord(b, start+1+j)
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeERC1155Event(t *testing.T) {
+	word := func(hex string) string { return strings.Repeat("0", 64-len(hex)) + hex }
+	topics := func(sig string) []string {
+		return []string{sig, "0x" + word("0a"), "0x" + word("0b"), "0x" + word("0c")}
+	}
+
+	single, err := DecodeERC1155Event(topics(ERC1155TransferSingleTopic), "0x"+word("7")+word("3"))
+	assert.NoError(t, err)
+	assert.Equal(t, []ERC1155Transfer{{
+		Operator: "0x000000000000000000000000000000000000000a",
+		From:     "0x000000000000000000000000000000000000000b",
+		To:       "0x000000000000000000000000000000000000000c",
+		TokenID:  "7",
+		Value:    "3",
+	}}, single)
+
+	// ids at offset 0x40, values at offset 0xa0
+	batchData := "0x" + word("40") + word("a0") +
+		word("2") + word("1") + word("ffffffffffffffffffffffffffffffff") +
+		word("2") + word("5") + word("6")
+	batch, err := DecodeERC1155Event(topics(ERC1155TransferBatchTopic), batchData)
+	assert.NoError(t, err)
+	if assert.Len(t, batch, 2) {
+		assert.Equal(t, "1", batch[0].TokenID)
+		assert.Equal(t, "5", batch[0].Value)
+		assert.Equal(t, "340282366920938463463374607431768211455", batch[1].TokenID)
+		assert.Equal(t, "6", batch[1].Value)
+		assert.Equal(t, "0x000000000000000000000000000000000000000c", batch[1].To)
+	}
+
+	_, err = DecodeERC1155Event(topics(ERC1155TransferBatchTopic), "0x"+word("40")+word("a0")+word("2")+word("1"))
+	assert.Error(t, err, "truncated ids")
+	_, err = DecodeERC1155Event(topics(ERC1155TransferSingleTopic), "0x"+word("7"))
+	assert.Error(t, err, "truncated value")
+	_, err = DecodeERC1155Event(topics(ERC1155TransferSingleTopic)[:3], "0x")
+	assert.Error(t, err, "missing topic")
+}
+
+func TestTokenIDToInt64(t *testing.T) {
+	assert.Equal(t, int64(7), TokenIDToInt64("7"))
+	assert.Equal(t, int64(0), TokenIDToInt64("340282366920938463463374607431768211455"), "too large")
+}
@@ -14,3 +14,13 @@ func IsValidEthereumAddress(addr string) bool {
 	_, err := hex.DecodeString(addr[2:])
 	return err == nil
 }
+
+// IsValidTxHash checks if hash is an EVM transaction hash: "0x" followed by
+// 64 hex digits.
+func IsValidTxHash(hash string) bool {
+	if len(hash) != 66 || !strings.HasPrefix(hash, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(hash[2:])
+	return err == nil
+}
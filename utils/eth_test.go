@@ -51,3 +51,11 @@ func TestIsValidEthereumAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidTxHash(t *testing.T) {
+	const hash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	assert.True(t, IsValidTxHash(hash))
+	assert.False(t, IsValidTxHash(hash[:65]), "too short")
+	assert.False(t, IsValidTxHash("0x"+hash[3:]+"zz"[:1]), "not hex")
+	assert.False(t, IsValidTxHash("00"+hash[2:]), "missing 0x")
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// HashTransactions returns a hex SHA-256 over a canonical serialization of
+// txs, so two responses can be compared for equality without diffing them.
+//
+// The hash ignores ordering (transactions are sorted by a total key first) and
+// fields that legitimately differ between environments or over time:
+// ServerChainName (deployment-specific chain naming), IconURL and ModifiedTime.
+// Address and hash fields are compared case-insensitively.
+func HashTransactions(txs []types.Transaction) string {
+	lines := make([]string, len(txs))
+	for i, tx := range txs {
+		lines[i] = canonicalTransaction(tx)
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// TransactionKey identifies one row of a transaction: the transaction
+// itself, or one of its token or internal transfers. Rows with the same key
+// are versions of each other, e.g. before and after it was mined. It is the
+// identity used by every merge: provider results (DedupTransactions), cache
+// entries and stored history. A row read from a log is keyed by its log
+// index, which does not depend on what else a fetch returned; rows without
+// one fall back on their TransferIndex.
+func TransactionKey(tx types.Transaction) string {
+	key := movementKey(tx)
+	switch {
+	case tx.LogIndex > 0:
+		key += "|@" + strconv.FormatInt(tx.LogIndex, 10)
+	case tx.TransferIndex > 0:
+		// Repeats of the same movement within the transaction
+		key += "|#" + strconv.Itoa(tx.TransferIndex)
+	}
+	return key
+}
+
+// movementKey is TransactionKey without the log or transfer index.
+func movementKey(tx types.Transaction) string {
+	fields := []string{
+		strconv.FormatInt(tx.ChainID, 10),
+		strings.ToLower(tx.Hash),
+		strconv.Itoa(tx.Type),
+		strconv.Itoa(tx.CoinType),
+		strings.ToLower(tx.TokenAddress),
+		strings.ToLower(tx.FromAddress),
+		strings.ToLower(tx.ToAddress),
+		tx.Amount,
+	}
+	if tx.TokenIDRaw != "" {
+		// The rows of an ERC-1155 batch transfer may only differ by token ID
+		fields = append(fields, tx.TokenIDRaw)
+	}
+	return strings.Join(fields, "|")
+}
+
+// DedupTransactions drops the rows whose TransactionKey repeats, such as a
+// transfer returned on two overlapping pages. Rows read from a log are told
+// apart by their log index. Rows without one (LogIndex 0) that repeat the
+// same movement are numbered by TransferIndex within each run of
+// consecutive rows of their transaction, as a provider returns them
+// together: a transaction seen again further down, on an overlapping page,
+// is numbered from 0 again and its rows dropped. The order of the rows kept
+// is preserved.
+func DedupTransactions(txs []types.Transaction) []types.Transaction {
+	repeats := make(map[string]int) // movement → rows without log index so far in the run
+	seen := make(map[string]struct{}, len(txs))
+	kept := txs[:0]
+	var run string
+	for _, tx := range txs {
+		if r := strconv.FormatInt(tx.ChainID, 10) + "|" + strings.ToLower(tx.Hash); r != run {
+			run = r
+			clear(repeats)
+		}
+		tx.TransferIndex = 0
+		if tx.LogIndex == 0 {
+			k := movementKey(tx)
+			tx.TransferIndex = repeats[k]
+			repeats[k]++
+		}
+		k := TransactionKey(tx)
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+		kept = append(kept, tx)
+	}
+	return kept
+}
+
+// canonicalTransaction serializes the stable fields of tx in a fixed order,
+// separated by the ASCII unit separator.
+func canonicalTransaction(tx types.Transaction) string {
+	fields := []string{
+		strconv.FormatInt(tx.ChainID, 10),
+		strconv.FormatInt(tx.Height, 10),
+		strings.ToLower(tx.Hash),
+		strconv.FormatInt(tx.TxIndex, 10),
+		strings.ToLower(tx.BlockHash),
+		strconv.Itoa(tx.CoinType),
+		strconv.Itoa(tx.Type),
+		strconv.Itoa(tx.State),
+		strings.ToLower(tx.FromAddress),
+		strings.ToLower(tx.ToAddress),
+		strings.ToLower(tx.TokenAddress),
+		strconv.FormatInt(tx.TokenID, 10),
+		tx.Balance,
+		tx.Amount,
+		strconv.FormatInt(tx.Decimals, 10),
+		tx.TokenDisplayName,
+		tx.GasUsed,
+		tx.GasLimit,
+		tx.GasPrice,
+		tx.Nonce,
+		strconv.FormatInt(tx.CreatedTime, 10),
+		strconv.Itoa(tx.TranType),
+		tx.ApproveShow,
+	}
+	if tx.TokenIDRaw != "" {
+		fields = append(fields, tx.TokenIDRaw)
+	}
+	return strings.Join(fields, "\x1f")
+}
@@ -0,0 +1,91 @@
+package utils_test
+
+import (
+	"testing"
+
+	"tx-aggregator/utils"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+func TestHashTransactions(t *testing.T) {
+	a := types.Transaction{ChainID: 1, Height: 10, Hash: "0xAA", Balance: "5", ServerChainName: "ETH"}
+	b := types.Transaction{ChainID: 56, Height: 3, Hash: "0xbb", Balance: "7", IconURL: "https://x/icon.png"}
+
+	base := utils.HashTransactions([]types.Transaction{a, b})
+	assert.Len(t, base, 64)
+
+	// Order does not matter.
+	assert.Equal(t, base, utils.HashTransactions([]types.Transaction{b, a}))
+
+	// Volatile fields and hash casing are ignored.
+	a2, b2 := a, b
+	a2.ServerChainName = "Ethereum"
+	a2.Hash = "0xaa"
+	a2.ModifiedTime = 12345
+	b2.IconURL = ""
+	assert.Equal(t, base, utils.HashTransactions([]types.Transaction{a2, b2}))
+
+	// Meaningful changes alter the hash.
+	a3 := a
+	a3.Balance = "6"
+	assert.NotEqual(t, base, utils.HashTransactions([]types.Transaction{a3, b}))
+	assert.NotEqual(t, base, utils.HashTransactions([]types.Transaction{a}))
+}
+
+func TestTransactionKey(t *testing.T) {
+	tx := types.Transaction{ChainID: 1, Hash: "0xABC", CoinType: types.CoinTypeNative, FromAddress: "0xA", ToAddress: "0xB", Amount: "1"}
+
+	mined := tx
+	mined.State = 1
+	mined.Height = 100
+	mined.Hash = "0xabc"
+	assert.Equal(t, utils.TransactionKey(tx), utils.TransactionKey(mined), "state, height and case do not matter")
+
+	transfer := tx
+	transfer.CoinType = types.CoinTypeToken
+	transfer.TokenAddress = "0xtoken"
+	assert.NotEqual(t, utils.TransactionKey(tx), utils.TransactionKey(transfer), "token transfer of the same tx")
+
+	id1 := transfer
+	id1.CoinType = types.CoinTypeERC1155
+	id1.TokenIDRaw = "1"
+	id2 := id1
+	id2.TokenIDRaw = "2"
+	assert.NotEqual(t, utils.TransactionKey(id1), utils.TransactionKey(id2), "ERC-1155 batch rows")
+
+	repeat := transfer
+	repeat.TransferIndex = 1
+	assert.NotEqual(t, utils.TransactionKey(transfer), utils.TransactionKey(repeat), "repeated transfer of the same tx")
+}
+
+func TestDedupTransactions(t *testing.T) {
+	transfer := types.Transaction{ChainID: 1, Hash: "0xabc", CoinType: types.CoinTypeToken, TokenAddress: "0xtoken",
+		FromAddress: "0xa", ToAddress: "0xb", Amount: "1"}
+	at := func(tx types.Transaction, logIndex int64) types.Transaction {
+		tx.LogIndex = logIndex
+		return tx
+	}
+	other := transfer
+	other.Hash = "0xdef"
+
+	got := utils.DedupTransactions([]types.Transaction{
+		at(transfer, 12), at(transfer, 7), other, other, at(transfer, 12), other, other,
+	})
+	type row struct {
+		hash          string
+		logIndex      int64
+		transferIndex int
+	}
+	var rows []row
+	for _, tx := range got {
+		rows = append(rows, row{tx.Hash, tx.LogIndex, tx.TransferIndex})
+	}
+	assert.Equal(t, []row{{"0xabc", 12, 0}, {"0xabc", 7, 0}, {"0xdef", 0, 0}, {"0xdef", 0, 1}}, rows,
+		"logged duplicates are dropped, consecutive repeats without log index numbered, later pages dropped")
+
+	// The key of a logged row does not depend on the other rows fetched.
+	alone := utils.DedupTransactions([]types.Transaction{at(transfer, 12)})
+	assert.Equal(t, utils.TransactionKey(got[0]), utils.TransactionKey(alone[0]))
+}
@@ -2,15 +2,22 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
 )
 
 // GetInsensitiveQuery retrieves the query parameter by ignoring case sensitivity.
@@ -23,82 +30,242 @@ func GetInsensitiveQuery(ctx *fiber.Ctx, key string) string {
 	return ""
 }
 
+// RequestOption customises a single DoHttpRequestWithLogging call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	auth        AuthStrategy
+	providerKey string
+	requestID   string
+	publicOnly  bool
+}
+
+// WithAuth signs the request with the given strategy. A nil strategy is ignored.
+func WithAuth(a AuthStrategy) RequestOption {
+	return func(o *requestOptions) { o.auth = a }
+}
+
+// WithProviderKey attributes the request to a provider registry key (e.g.
+// "blockscan_eth"), so that the key's entries under providers.rate_limits and
+// providers.timeouts apply to it.
+func WithProviderKey(key string) RequestOption {
+	return func(o *requestOptions) { o.providerKey = key }
+}
+
+// WithPublicDestinationOnly refuses to connect to loopback, private and
+// link-local addresses, for URLs supplied by API clients such as watch
+// webhooks.
+func WithPublicDestinationOnly() RequestOption {
+	return func(o *requestOptions) { o.publicOnly = true }
+}
+
+// WithRequestID forwards the ID of the API request being served to the
+// provider in the X-Request-ID header and tags the request logs with it. An
+// empty ID is ignored.
+func WithRequestID(id string) RequestOption {
+	return func(o *requestOptions) { o.requestID = id }
+}
+
+// PayloadRecorder receives the raw body of every successful response read by
+// DoHttpRequestWithLogging, with the request that produced it. It runs on the
+// request path and must not block.
+type PayloadRecorder func(label, method, url string, reqBody, respBody []byte)
+
+var payloadRecorder atomic.Pointer[PayloadRecorder]
+
+// SetPayloadRecorder installs r as the process-wide payload recorder. A nil r
+// removes it.
+func SetPayloadRecorder(r PayloadRecorder) {
+	if r == nil {
+		payloadRecorder.Store(nil)
+		return
+	}
+	payloadRecorder.Store(&r)
+}
+
 // DoHttpRequestWithLogging performs an HTTP request with optional JSON body and optional JSON decoding of the response.
 // It logs request method, URL, duration, response size, status, and error if any.
+// Responses with status 429 or 5xx and timed-out requests are retried with
+// exponential backoff according to providers.retry; each attempt is bounded
+// by the matching providers.timeouts entry.
 //
 // method:     "GET", "POST", etc.
 // url:        full request URL
 // body:       optional request body (e.g., struct for POST JSON), pass nil for GET
 // headers:    optional headers (e.g., Content-Type, API keys)
 // result:     optional pointer to decode JSON response into (pass nil if not needed)
-func DoHttpRequestWithLogging(method, label, url string, body interface{}, headers map[string]string, result interface{}) error {
-	logger.Log.Debug().
+// opts:       optional behaviour such as WithAuth or WithProviderKey
+func DoHttpRequestWithLogging(method, label, url string, body interface{}, headers map[string]string, result interface{}, opts ...RequestOption) error {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	log := requestLogger(label, o)
+
+	log.Debug().
 		Str("label", label).
 		Str("url", url).
 		Str("method", method).
 		Msg("Preparing HTTP request")
 
-	var reqBody io.Reader
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
-			logger.Log.Error().Str("label", label).Err(err).Msg("Failed to marshal request body")
+			log.Error().Str("label", label).Err(err).Msg("Failed to marshal request body")
 			return fmt.Errorf("marshal request failed: %w", err)
 		}
+	}
+
+	policy := currentRetryPolicy()
+	var respBody []byte
+	for attempt := 0; ; attempt++ {
+		out, err := doHttpAttempt(method, label, url, jsonData, headers, o)
+		if err == nil {
+			respBody = out.body
+			break
+		}
+		if out.retryReason == "" || attempt >= policy.maxRetries {
+			return err
+		}
+
+		wait := policy.delay(attempt, out.retryAfter)
+		metrics.HTTPRetries.WithLabelValues(label, out.retryReason).Inc()
+		log.Warn().
+			Str("label", label).
+			Str("url", url).
+			Str("method", method).
+			Str("reason", out.retryReason).
+			Int("attempt", attempt+1).
+			Dur("backoff", wait).
+			Msg("Retrying HTTP request")
+		time.Sleep(wait)
+	}
+
+	if r := payloadRecorder.Load(); r != nil {
+		(*r)(label, method, url, jsonData, respBody)
+	}
+
+	// Optional: unmarshal into result
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			metrics.ProviderErrors.WithLabelValues(metricProvider(label, o.providerKey), label, "decode").Inc()
+			log.Error().
+				Str("label", label).
+				Str("url", url).
+				Err(err).
+				Msg("Failed to unmarshal response body")
+			return fmt.Errorf("unmarshal response failed for %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// attemptResult is the outcome of a single HTTP round trip. On failure,
+// retryReason is set when the attempt may be retried and retryAfter carries
+// the server's Retry-After hint.
+type attemptResult struct {
+	body        []byte
+	retryReason string
+	retryAfter  time.Duration
+}
+
+// doHttpAttempt builds, signs and sends one request. The request is rebuilt
+// on every attempt so that auth signatures and body readers are fresh.
+func doHttpAttempt(method, label, url string, jsonData []byte, headers map[string]string, o requestOptions) (attemptResult, error) {
+	log := requestLogger(label, o)
+	var reqBody io.Reader
+	if jsonData != nil {
 		reqBody = bytes.NewReader(jsonData)
 	}
 
+	// Wait for a rate limit token before the attempt's timeout starts
+	waitRateLimit(o.providerKey)
+
+	// Bound this attempt, including reading the body
+	ctx := context.Background()
+	if o.providerKey != "" {
+		ctx = withVCRProvider(ctx)
+	}
+	if timeout := requestTimeout(label, o.providerKey); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Construct request
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		logger.Log.Error().Str("label", label).Err(err).Msg("Failed to create HTTP request")
-		return fmt.Errorf("create request failed: %w", err)
+		log.Error().Str("label", label).Err(err).Msg("Failed to create HTTP request")
+		return attemptResult{}, fmt.Errorf("create request failed: %w", err)
 	}
 
 	// Set headers if provided
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	if o.requestID != "" {
+		req.Header.Set(types.RequestIDHeader, o.requestID)
+	}
+
+	// Sign last, so the signature covers the final request
+	if o.auth != nil {
+		if err := o.auth.Sign(req, jsonData); err != nil {
+			log.Error().Str("label", label).Err(err).Msg("Failed to sign HTTP request")
+			return attemptResult{}, fmt.Errorf("sign request failed for %s: %w", label, err)
+		}
+	}
 
 	start := time.Now()
-	resp, err := http.DefaultClient.Do(req)
+	client := ProviderClient()
+	if o.publicOnly {
+		client = publicClient
+	}
+	resp, err := client.Do(req)
 	duration := time.Since(start)
 
 	if err != nil {
-		logger.Log.Error().
+		observeAttempt(label, o.providerKey, start, errorClass(0, err))
+		log.Error().
 			Str("label", label).
 			Str("url", url).
 			Str("method", method).
 			Dur("duration", duration).
 			Err(err).
 			Msg("Failed to send HTTP request")
-		return fmt.Errorf("send %s failed: %w", label, err)
+		return attemptResult{retryReason: retryReason(0, err)}, fmt.Errorf("send %s failed: %w", label, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.Log.Error().
+		observeAttempt(label, o.providerKey, start, errorClass(0, err))
+		log.Error().
 			Str("label", label).
 			Str("url", url).
 			Dur("duration", duration).
 			Err(err).
 			Msg("Failed to read response body")
-		return fmt.Errorf("read response failed for %s: %w", label, err)
+		return attemptResult{retryReason: retryReason(0, err)}, fmt.Errorf("read response failed for %s: %w", label, err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		logger.Log.Error().
+		observeAttempt(label, o.providerKey, start, errorClass(resp.StatusCode, nil))
+		log.Error().
 			Str("label", label).
 			Str("url", url).
 			Str("method", method).
 			Int("status_code", resp.StatusCode).
 			Dur("duration", duration).
 			Msg("Non-200 HTTP status")
-		return fmt.Errorf("non-200 response for %s: %d", label, resp.StatusCode)
+		return attemptResult{
+			retryReason: retryReason(resp.StatusCode, nil),
+			retryAfter:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}, fmt.Errorf("non-200 response for %s: %d", label, resp.StatusCode)
 	}
 
-	logger.Log.Info().
+	log.Info().
 		Str("label", label).
 		Str("url", url).
 		Str("method", method).
@@ -107,17 +274,57 @@ func DoHttpRequestWithLogging(method, label, url string, body interface{}, heade
 		Dur("duration", duration).
 		Msg("HTTP request completed")
 
-	// Optional: unmarshal into result
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			logger.Log.Error().
-				Str("label", label).
-				Str("url", url).
-				Dur("duration", duration).
-				Err(err).
-				Msg("Failed to unmarshal response body")
-			return fmt.Errorf("unmarshal response failed for %s: %w", label, err)
+	observeAttempt(label, o.providerKey, start, "")
+	return attemptResult{body: respBody}, nil
+}
+
+// requestLogger returns the logger of one outbound request, tagged with the
+// API request ID and the provider, so that failures can be told apart per
+// provider in logs and error reports.
+func requestLogger(label string, o requestOptions) *zerolog.Logger {
+	l := logger.ForRequest(o.requestID).With().Str("provider", metricProvider(label, o.providerKey)).Logger()
+	return &l
+}
+
+// observeAttempt records the duration of an attempt started at start and,
+// when class is set, counts it as failed.
+func observeAttempt(label, providerKey string, start time.Time, class string) {
+	provider := metricProvider(label, providerKey)
+	metrics.ProviderRequestDuration.WithLabelValues(provider, label).Observe(time.Since(start).Seconds())
+	if class != "" {
+		metrics.ProviderErrors.WithLabelValues(provider, label, class).Inc()
+	}
+}
+
+// metricProvider names the provider of a request in metrics: its registry
+// key, or the first segment of its label (e.g. "quicknode" or "blockscout"
+// for "blockscout.rpcReceipts.shard.4") when it has none.
+func metricProvider(label, providerKey string) string {
+	if providerKey != "" {
+		return providerKey
+	}
+	provider, _, _ := strings.Cut(label, ".")
+	return provider
+}
+
+// errorClass classifies a failed attempt for metrics: "timeout" or
+// "network" for transport errors, else "429", "5xx", "4xx" or "status" by
+// status code.
+func errorClass(statusCode int, err error) string {
+	if err != nil {
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return "timeout"
 		}
+		return "network"
 	}
-	return nil
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return "429"
+	case statusCode >= 500 && statusCode <= 599:
+		return "5xx"
+	case statusCode >= 400 && statusCode <= 499:
+		return "4xx"
+	}
+	return "status"
 }
@@ -1,13 +1,18 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"tx-aggregator/metrics"
 )
 
 // ------------------------
@@ -110,3 +115,137 @@ func TestDoHttpRequestWithLogging_BadMarshal(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "marshal request failed")
 }
+
+// ------------------------
+// Test payload recorder hook
+// ------------------------
+func TestDoHttpRequestWithLogging_PayloadRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	var gotLabel, gotReq, gotResp string
+	SetPayloadRecorder(func(label, method, url string, reqBody, respBody []byte) {
+		gotLabel, gotReq, gotResp = label, string(reqBody), string(respBody)
+	})
+	defer SetPayloadRecorder(nil)
+
+	err := DoHttpRequestWithLogging("POST", "recorded", server.URL, map[string]int{"a": 1}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "recorded", gotLabel)
+	assert.Equal(t, `{"a":1}`, gotReq)
+	assert.Equal(t, `{"ok":true}`, gotResp)
+}
+
+// ------------------------
+// Test request ID forwarding
+// ------------------------
+func TestDoHttpRequestWithLogging_RequestID(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Request-ID"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, DoHttpRequestWithLogging("GET", "with-id", server.URL, nil, nil, nil, WithRequestID("abc-123")))
+	assert.NoError(t, DoHttpRequestWithLogging("GET", "without-id", server.URL, nil, nil, nil))
+	assert.Equal(t, []string{"abc-123", ""}, got)
+}
+
+// ------------------------
+// Test retries on transient failures
+// ------------------------
+func TestDoHttpRequestWithLogging_RetriesTransient(t *testing.T) {
+	withRetryConfig(t, 2, 1, 5)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	var result map[string]string
+	err := DoHttpRequestWithLogging("GET", "retry_ok", server.URL, nil, nil, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", result["status"])
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDoHttpRequestWithLogging_RetriesExhausted(t *testing.T) {
+	withRetryConfig(t, 1, 1, 5)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := DoHttpRequestWithLogging("GET", "retry_fail", server.URL, nil, nil, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "non-200 response")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDoHttpRequestWithLogging_NoRetryOnClientError(t *testing.T) {
+	withRetryConfig(t, 3, 1, 5)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := DoHttpRequestWithLogging("GET", "retry_400", server.URL, nil, nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// ------------------------
+// Test latency and error class metrics
+// ------------------------
+func TestDoHttpRequestWithLogging_Metrics(t *testing.T) {
+	withRetryConfig(t, 1, 1, 5)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`not json`))
+	}))
+	defer server.Close()
+
+	series := testutil.CollectAndCount(metrics.ProviderRequestDuration)
+	var result map[string]string
+	err := DoHttpRequestWithLogging("GET", "metrics.test", server.URL, nil, nil, &result, WithProviderKey("metrics_eth"))
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProviderErrors.WithLabelValues("metrics_eth", "metrics.test", "429")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ProviderErrors.WithLabelValues("metrics_eth", "metrics.test", "decode")))
+	assert.Equal(t, series+1, testutil.CollectAndCount(metrics.ProviderRequestDuration), "both attempts observed in one series")
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "timeout", errorClass(0, context.DeadlineExceeded))
+	assert.Equal(t, "network", errorClass(0, errors.New("connection refused")))
+	assert.Equal(t, "429", errorClass(http.StatusTooManyRequests, nil))
+	assert.Equal(t, "5xx", errorClass(http.StatusBadGateway, nil))
+	assert.Equal(t, "4xx", errorClass(http.StatusNotFound, nil))
+	assert.Equal(t, "status", errorClass(http.StatusFound, nil))
+
+	assert.Equal(t, "blockscout", metricProvider("blockscout.rpcReceipts.shard.4", ""))
+	assert.Equal(t, "quicknode", metricProvider("quicknode", ""))
+	assert.Equal(t, "alchemy_eth", metricProvider("alchemy.assetTransfers", "alchemy_eth"))
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"math/big"
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// FeeBreakdown returns the L1 data fee and the total fee paid, in wei, by a
+// rollup transaction. OP Stack chains (Optimism, Base) report the L1 fee as
+// l1Fee, charged on top of gasUsed × gasPrice; Arbitrum reports
+// gasUsedForL1, the part of gasUsed that paid for L1 data. Values may be
+// decimal or hex. ok is false when neither is known, as on L1 chains.
+func FeeBreakdown(gasUsed, gasPrice, l1Fee, gasUsedForL1 string) (l1, total string, ok bool) {
+	parse := func(s string) (*big.Int, bool) {
+		n, err := NormalizeNumericString(s)
+		if err != nil {
+			return nil, false
+		}
+		return new(big.Int).SetString(n, 10)
+	}
+	used, okUsed := parse(gasUsed)
+	price, okPrice := parse(gasPrice)
+	if !okUsed || !okPrice {
+		return "", "", false
+	}
+	execution := new(big.Int).Mul(used, price)
+
+	if fee, ok := parse(l1Fee); ok {
+		return fee.String(), execution.Add(execution, fee).String(), true
+	}
+	if l1Gas, ok := parse(gasUsedForL1); ok {
+		return l1Gas.Mul(l1Gas, price).String(), execution.String(), true
+	}
+	return "", "", false
+}
+
+// TxFee returns the fee, in the chain's smallest unit, that address paid for
+// tx. Only the normal native row of a transaction carries the fee, charged
+// to its sender: token, internal and approval rows report false, as does a
+// row whose fee is unknown. A rollup transaction's Fee includes the L1 data
+// fee; other rows pay gasUsed × gasPrice. Callers charge a fee once per hash.
+func TxFee(tx types.Transaction, address string) (*big.Int, bool) {
+	if tx.CoinType != types.CoinTypeNative || tx.Type == types.TxTypeInternal || tx.Type == types.TxTypeApprove {
+		return nil, false
+	}
+	if !strings.EqualFold(tx.FromAddress, address) {
+		return nil, false
+	}
+	if fee, ok := new(big.Int).SetString(tx.Fee, 10); ok {
+		return fee, true
+	}
+	gasUsed, okUsed := new(big.Int).SetString(tx.GasUsed, 10)
+	gasPrice, okPrice := new(big.Int).SetString(tx.GasPrice, 10)
+	if !okUsed || !okPrice {
+		return nil, false
+	}
+	return gasUsed.Mul(gasUsed, gasPrice), true
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/types"
+)
+
+func TestFeeBreakdown(t *testing.T) {
+	tests := []struct {
+		name                                   string
+		gasUsed, gasPrice, l1Fee, gasUsedForL1 string
+		l1, total                              string
+		ok                                     bool
+	}{
+		{"op stack adds the l1 fee", "21000", "1000", "0x3e8", "", "1000", "21001000", true},
+		{"arbitrum l1 gas is part of gas used", "0x7530", "0x64", "", "0x2710", "1000000", "3000000", true},
+		{"l1 chain", "21000", "1000", "", "", "", "", false},
+		{"unknown gas", "", "1000", "1000", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l1, total, ok := FeeBreakdown(tt.gasUsed, tt.gasPrice, tt.l1Fee, tt.gasUsedForL1)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.l1, l1)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestTxFee(t *testing.T) {
+	const me = "0xMe"
+	native := types.Transaction{CoinType: types.CoinTypeNative, FromAddress: "0xme", GasUsed: "21000", GasPrice: "10"}
+
+	fee, ok := TxFee(native, me)
+	assert.True(t, ok)
+	assert.Equal(t, "210000", fee.String())
+
+	rollup := native
+	rollup.Fee = "210500"
+	fee, ok = TxFee(rollup, me)
+	assert.True(t, ok)
+	assert.Equal(t, "210500", fee.String(), "the total fee wins over gas")
+
+	token := native
+	token.CoinType = types.CoinTypeToken
+	internal := native
+	internal.Type = types.TxTypeInternal
+	unknown := native
+	unknown.GasPrice = ""
+	for name, tx := range map[string]types.Transaction{"token": token, "internal": internal, "unknown": unknown} {
+		_, ok = TxFee(tx, me)
+		assert.False(t, ok, name)
+	}
+	_, ok = TxFee(native, "0xother")
+	assert.False(t, ok, "received rows pay nothing")
+}
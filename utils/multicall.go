@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// batchSelectors maps the selectors of the batch calls whose events are
+// decoded into sub-actions to their method names.
+var batchSelectors = map[string]string{
+	"0x252dba42": "aggregate",            // Multicall3 aggregate((address,bytes)[])
+	"0xbce38bd7": "tryAggregate",         // Multicall3 tryAggregate(bool,(address,bytes)[])
+	"0xc3077fa9": "blockAndAggregate",    // Multicall3 blockAndAggregate((address,bytes)[])
+	"0x399542e9": "tryBlockAndAggregate", // Multicall3 tryBlockAndAggregate(bool,(address,bytes)[])
+	"0x82ad56cb": "aggregate3",           // Multicall3 aggregate3((address,bool,bytes)[])
+	"0x174dea71": "aggregate3Value",      // Multicall3 aggregate3Value((address,bool,uint256,bytes)[])
+	"0xac9650d8": "multicall",            // Uniswap V3 router multicall(bytes[])
+	"0x5ae401dc": "multicall",            // Uniswap router multicall(uint256 deadline,bytes[])
+	"0x1f0464d1": "multicall",            // Uniswap router multicall(bytes32 previousBlockhash,bytes[])
+	"0x3593564c": "execute",              // Universal Router execute(bytes,bytes[],uint256)
+	"0x24856bc3": "execute",              // Universal Router execute(bytes,bytes[])
+}
+
+// IsBatchCall reports whether method is a Multicall3 or router batch call.
+// method is either the transaction's input data, which starts with the
+// selector, or a method name as decoded by explorers.
+func IsBatchCall(method string) bool {
+	method = strings.ToLower(strings.TrimSpace(method))
+	if strings.HasPrefix(method, "0x") {
+		if len(method) < 10 {
+			return false
+		}
+		_, ok := batchSelectors[method[:10]]
+		return ok
+	}
+	for _, name := range batchSelectors {
+		if strings.EqualFold(name, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogSubActions decodes one event emitted during a batch call into the
+// sub-actions it records: token transfers and approvals (one per id/value
+// pair for ERC-1155), swaps, wraps and unwraps. Other events yield none.
+func LogSubActions(chainID int64, contract string, topics []string, data string) []types.SubAction {
+	contract = strings.ToLower(contract)
+	if _, ok := DetectSwapEvent(topics); ok {
+		return []types.SubAction{{Action: types.SubActionSwap, Contract: contract}}
+	}
+	if wrapType, ok := DetectWrapEvent(chainID, contract, topics); ok && len(topics) >= 2 {
+		a := types.SubAction{Action: types.SubActionWrap, Contract: contract, To: topicToAddress(topics[1])}
+		if wrapType == types.TxTypeUnwrap {
+			a = types.SubAction{Action: types.SubActionUnwrap, Contract: contract, From: topicToAddress(topics[1])}
+		}
+		a.Value, _ = NormalizeNumericString(data)
+		return []types.SubAction{a}
+	}
+	if IsERC1155Event(topics) {
+		transfers, err := DecodeERC1155Event(topics, data)
+		if err != nil {
+			return nil
+		}
+		actions := make([]types.SubAction, len(transfers))
+		for i, t := range transfers {
+			actions[i] = types.SubAction{
+				Action: types.SubActionTransfer, Contract: contract,
+				From: t.From, To: t.To, Value: t.Value, TokenIDRaw: t.TokenID,
+			}
+		}
+		return actions
+	}
+
+	ev, ok := DetectTokenEvent(contract, topics, data)
+	if !ok || len(topics) < 3 {
+		return nil
+	}
+	a := types.SubAction{
+		Action:   types.SubActionTransfer,
+		Contract: contract,
+		From:     topicToAddress(topics[1]),
+		To:       topicToAddress(topics[2]),
+	}
+	if ev.TxType == types.TxTypeApprove {
+		a.Action = types.SubActionApprove
+	}
+	if ev.CoinType == types.CoinTypeERC721 {
+		a.TokenIDRaw = ev.TokenID
+	} else {
+		a.Value, _ = NormalizeNumericString(data)
+	}
+	return []types.SubAction{a}
+}
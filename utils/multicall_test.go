@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestIsBatchCall(t *testing.T) {
+	assert.True(t, IsBatchCall("0x82ad56cb0000000000000000000000000000000000000000000000000000000000000020"))
+	assert.True(t, IsBatchCall("0x5AE401DC"))
+	assert.True(t, IsBatchCall("aggregate3"))
+	assert.True(t, IsBatchCall("Multicall"))
+	assert.False(t, IsBatchCall("0xa9059cbb"), "transfer")
+	assert.False(t, IsBatchCall("transfer"))
+	assert.False(t, IsBatchCall("0x"))
+	assert.False(t, IsBatchCall(""))
+}
+
+func TestLogSubActions(t *testing.T) {
+	orig := config.Current()
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+	const weth = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"eth": 1}
+	cfg.WrappedNative = map[string]string{"eth": weth}
+	config.SetCurrentConfig(cfg)
+
+	const (
+		alice = "0x1111111111111111111111111111111111111111"
+		bob   = "0x2222222222222222222222222222222222222222"
+		token = "0x3333333333333333333333333333333333333333"
+	)
+	topic := func(addr string) string { return "0x000000000000000000000000" + addr[2:] }
+	word := func(hex string) string {
+		return "0x" + "00000000000000000000000000000000000000000000000000000000000000"[:64-len(hex)] + hex
+	}
+
+	tests := []struct {
+		name     string
+		contract string
+		topics   []string
+		data     string
+		want     []types.SubAction
+	}{
+		{"erc20 transfer", token, []string{transferSig, topic(alice), topic(bob)}, word("64"),
+			[]types.SubAction{{Action: types.SubActionTransfer, Contract: token, From: alice, To: bob, Value: "100"}}},
+		{"erc721 approval", token, []string{approveSig, topic(alice), topic(bob), word("07")}, "0x",
+			[]types.SubAction{{Action: types.SubActionApprove, Contract: token, From: alice, To: bob, TokenIDRaw: "7"}}},
+		{"wrap", weth, []string{WrappedNativeDepositTopic, topic(alice)}, word("0a"),
+			[]types.SubAction{{Action: types.SubActionWrap, Contract: weth, To: alice, Value: "10"}}},
+		{"unwrap", weth, []string{WrappedNativeWithdrawalTopic, topic(alice)}, word("0a"),
+			[]types.SubAction{{Action: types.SubActionUnwrap, Contract: weth, From: alice, Value: "10"}}},
+		{"swap", token, []string{UniswapV2SwapTopic, topic(alice), topic(bob)}, "0x",
+			[]types.SubAction{{Action: types.SubActionSwap, Contract: token}}},
+		{"erc1155 single", token, []string{ERC1155TransferSingleTopic, topic(alice), topic(alice), topic(bob)}, word("05") + word("02")[2:],
+			[]types.SubAction{{Action: types.SubActionTransfer, Contract: token, From: alice, To: bob, Value: "2", TokenIDRaw: "5"}}},
+		{"unknown event", token, []string{WrappedNativeDepositTopic, topic(alice)}, word("0a"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LogSubActions(1, tt.contract, tt.topics, tt.data))
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net"
+	"net/http"
+	"syscall"
+	"time"
+	"tx-aggregator/config"
 )
 
 // GetLocalIPv4 returns the first non-loopback IPv4 address of the host.
@@ -26,3 +32,77 @@ func GetLocalIPv4() (string, error) {
 
 	return "", errors.New("no non-loopback IPv4 address found")
 }
+
+// RedactURL masks the credentials in a URL (see config.RedactURL). An
+// unparsable URL yields "".
+func RedactURL(raw string) string {
+	redacted, err := config.RedactURL(raw)
+	if err != nil {
+		return ""
+	}
+	return redacted
+}
+
+// ErrNonPublicAddress is returned for destinations that resolve to a
+// loopback, private, link-local or otherwise non-public address.
+var ErrNonPublicAddress = errors.New("destination is not a public address")
+
+// cgnat is the shared address space of RFC 6598, not covered by IsPrivate.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is a globally routable unicast address. It
+// excludes loopback, private, link-local (including the 169.254.169.254
+// cloud metadata address), unspecified, multicast and CGNAT addresses.
+func IsPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() && !cgnat.Contains(ip)
+}
+
+// CheckPublicHost resolves host and fails with ErrNonPublicAddress unless
+// every address it resolves to is public.
+func CheckPublicHost(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDialTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, a := range addrs {
+		if !IsPublicIP(a.IP) {
+			return fmt.Errorf("%s resolves to %s: %w", host, a.IP, ErrNonPublicAddress)
+		}
+	}
+	return nil
+}
+
+// refuseNonPublic is a net.Dialer Control function refusing connections to
+// non-public addresses. It runs on the resolved address, so a host name that
+// resolves differently after CheckPublicHost (DNS rebinding) is still caught.
+func refuseNonPublic(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("dial %s: %w", address, ErrNonPublicAddress)
+	}
+	return nil
+}
+
+// publicClient sends requests made with WithPublicDestinationOnly. It dials
+// public addresses only and ignores proxy settings, which could reach
+// internal hosts on its behalf.
+var publicClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: defaultKeepAlive,
+			Control:   refuseNonPublic,
+		}).DialContext,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		MaxIdleConns:          defaultMaxIdleConns,
+	},
+	Timeout: time.Minute,
+}
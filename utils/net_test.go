@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"errors"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -26,3 +29,46 @@ func TestGetLocalIPv4(t *testing.T) {
 
 	t.Logf("Local IP address: %s", ip)
 }
+
+func TestRedactURL(t *testing.T) {
+	got := RedactURL("https://api.example.com/api?module=account&apikey=s3cret&address=0xabc")
+	if got != "https://api.example.com/api?address=0xabc&apikey=%2A%2A%2A%2A%2A%2A%2A%2A&module=account" {
+		t.Fatalf("unexpected redaction: %s", got)
+	}
+	if got := RedactURL("://bad"); got != "" {
+		t.Fatalf("expected empty string for an unparsable URL, got: %s", got)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+	} {
+		if got := IsPublicIP(net.ParseIP(ip)); got != want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestWithPublicDestinationOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a loopback destination was reached")
+	}))
+	defer server.Close()
+
+	err := DoHttpRequestWithLogging("POST", "test.public", server.URL, map[string]string{"a": "b"}, nil, nil, WithPublicDestinationOnly())
+	if !errors.Is(err, ErrNonPublicAddress) {
+		t.Fatalf("expected ErrNonPublicAddress, got: %v", err)
+	}
+}
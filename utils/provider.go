@@ -12,45 +12,79 @@ import (
 	"unicode"
 )
 
+// Full 32-byte event signatures shared by ERC-20 and ERC-721, which only
+// differ by how many parameters are indexed.
+const (
+	transferSig = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	approveSig  = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+)
+
+// TokenEvent is a token event recognized by DetectTokenEvent.
+type TokenEvent struct {
+	TxType       int    // types.TxTypeTransfer or types.TxTypeApprove
+	CoinType     int    // types.CoinTypeToken (ERC-20), CoinTypeERC721 or CoinTypeERC1155
+	TokenAddress string // Emitting contract, lowercase
+	ApproveValue string // Hex amount of an ERC-20 Approval
+	TokenID      string // Decimal token ID of an ERC-721 event
+}
+
+// DetectTokenEvent recognizes the ERC-20 and ERC-721 Transfer and Approval
+// events and the ERC-1155 transfer events. ERC-20 and ERC-721 events share
+// their signatures: ERC-721 ones have four topics, the last being the
+// indexed token ID, while ERC-20 ones have three and the amount in data.
+func DetectTokenEvent(contractAddress string, topics []string, data string) (TokenEvent, bool) {
+	if len(topics) == 0 {
+		return TokenEvent{}, false
+	}
+	ev := TokenEvent{TokenAddress: strings.ToLower(contractAddress)}
+
+	switch strings.ToLower(topics[0]) {
+	case transferSig, approveSig:
+		ev.TxType = types.TxTypeTransfer
+		if strings.ToLower(topics[0]) == approveSig {
+			ev.TxType = types.TxTypeApprove
+		}
+		if len(topics) == 4 {
+			id, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(topics[3]), "0x"), 16)
+			if !ok {
+				return TokenEvent{}, false
+			}
+			ev.CoinType = types.CoinTypeERC721
+			ev.TokenID = id.String()
+			return ev, true
+		}
+		ev.CoinType = types.CoinTypeToken
+		if ev.TxType == types.TxTypeApprove {
+			ev.ApproveValue = data
+		}
+		return ev, true
+
+	case ERC1155TransferSingleTopic, ERC1155TransferBatchTopic:
+		ev.TxType = types.TxTypeTransfer
+		ev.CoinType = types.CoinTypeERC1155
+		return ev, true
+	}
+	return TokenEvent{}, false
+}
+
 // DetectERC20Event checks if the (address, topics, data) indicate
-// an ERC-20 Transfer or Approval event.
+// a token Transfer or Approval event (see DetectTokenEvent).
 //
 // Returns:
 //   - txType: model.TxTypeTransfer (0), model.TxTypeApprove (1), or -1 if unrecognized
-//   - tokenAddress: the address of the ERC-20 token (lowercased)
-//   - approveValue: hex-encoded amount (only non-empty if it's an Approval event)
+//   - tokenAddress: the address of the token (lowercased)
+//   - approveValue: hex-encoded amount (only non-empty if it's an ERC-20 Approval event)
 func DetectERC20Event(
 	contractAddress string,
 	topics []string,
 	data string,
 ) (txType int, tokenAddress string, approveValue string) {
-
-	// Full 32-byte event signatures:
-	const transferSig = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
-	const approveSig = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
-
-	if len(topics) == 0 {
-		return types.TxTypeUnknown, "", ""
-	}
-
-	// Convert to lower for matching
-	topic0 := strings.ToLower(topics[0])
-	addrLower := strings.ToLower(contractAddress)
-
-	switch topic0 {
-	case transferSig:
-		// This is an ERC-20 Transfer event
-		return types.TxTypeTransfer, addrLower, ""
-
-	case approveSig:
-		// This is an ERC-20 Approval event
-		// The amount is typically in the log's data field
-		return types.TxTypeApprove, addrLower, data
-
-	default:
+	ev, ok := DetectTokenEvent(contractAddress, topics, data)
+	if !ok {
 		// Not recognized
 		return types.TxTypeUnknown, "", ""
 	}
+	return ev.TxType, ev.TokenAddress, ev.ApproveValue
 }
 
 // Within wherever you loop over logs in a transaction:
@@ -66,6 +100,33 @@ func DetectERC20TypeForAnkr(logs []types.AnkrLogEntry) (typ int, tokenAddress, a
 	return types.TxTypeUnknown, "", ""
 }
 
+// TransferLogIndex returns the log index of the ERC-20 Transfer event in logs
+// that the token row tx was read from: same contract, sender, recipient and
+// raw amount. Logs in used are skipped and the match is added to it, so that
+// repeats of one transfer get one log each. It returns 0 when no log matches.
+func TransferLogIndex(tx types.Transaction, logs []types.RpcReceiptLog, used map[string]bool) int64 {
+	want, ok := new(big.Int).SetString(tx.Balance, 10)
+	if !ok {
+		return 0
+	}
+	for _, l := range logs {
+		key := strings.ToLower(l.TransactionHash) + ":" + l.LogIndex
+		if used[key] || len(l.Topics) != 3 || strings.ToLower(l.Topics[0]) != transferSig ||
+			!strings.EqualFold(l.Address, tx.TokenAddress) ||
+			!strings.EqualFold(topicToAddress(l.Topics[1]), tx.FromAddress) ||
+			!strings.EqualFold(topicToAddress(l.Topics[2]), tx.ToAddress) {
+			continue
+		}
+		value, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(l.Data), "0x"), 16)
+		if !ok || value.Cmp(want) != 0 {
+			continue
+		}
+		used[key] = true
+		return ParseStringToInt64OrDefault(l.LogIndex, 0)
+	}
+	return 0
+}
+
 // ParseStringToInt64OrDefault converts a string to int64, supporting hex with "0x" prefix
 // Returns the default value if parsing fails
 func ParseStringToInt64OrDefault(s string, def int64) int64 {
@@ -162,14 +223,57 @@ func PatchTokenTransactionsWithNormalTxInfo(
 			tokenTxs[i].GasLimit = normal.GasLimit
 			tokenTxs[i].GasUsed = normal.GasUsed
 			tokenTxs[i].GasPrice = normal.GasPrice
+			tokenTxs[i].L1Fee = normal.L1Fee
+			tokenTxs[i].Fee = normal.Fee
 			tokenTxs[i].Nonce = normal.Nonce
 			tokenTxs[i].State = normal.State
 			tokenTxs[i].BlockHash = normal.BlockHash
+			if tokenTxs[i].TxIndex == 0 {
+				tokenTxs[i].TxIndex = normal.TxIndex
+			}
 		}
 	}
 	return tokenTxs
 }
 
+// PatchTxPositionFromNormalTxs sets the transaction index, and the block hash
+// when missing, of rows derived from a normal transaction (internal
+// transfers) from that transaction. Unlike
+// PatchTokenTransactionsWithNormalTxInfo it leaves gas and state alone:
+// an internal call has its own.
+func PatchTxPositionFromNormalTxs(rows, normalTxs []types.Transaction) {
+	txMap := make(map[string]types.Transaction, len(normalTxs))
+	for _, tx := range normalTxs {
+		txMap[tx.Hash] = tx
+	}
+	for i, row := range rows {
+		normal, ok := txMap[row.Hash]
+		if !ok {
+			continue
+		}
+		if row.TxIndex == 0 {
+			rows[i].TxIndex = normal.TxIndex
+		}
+		if row.BlockHash == "" {
+			rows[i].BlockHash = normal.BlockHash
+		}
+	}
+}
+
+// MarkEnrichment sets the enrichment flags that can be derived from the fields
+// of each transaction (gas and metadata). Flags already set by providers,
+// such as EnrichmentLogsScanned, are kept.
+func MarkEnrichment(txs []types.Transaction) {
+	for i := range txs {
+		if txs[i].GasUsed != "" && txs[i].GasPrice != "" {
+			txs[i].Enrichment |= types.EnrichmentGasPatched
+		}
+		if txs[i].TokenDisplayName != "" {
+			txs[i].Enrichment |= types.EnrichmentMetadataResolved
+		}
+	}
+}
+
 // DivideByDecimals converts an integer string to a decimal string by shifting the dot
 // `value`   – integer in base‑10 (no sign, no “0x” prefix)
 // `decimals`– how many decimals the original integer assumed
@@ -67,12 +67,42 @@ func TestDetectERC20Event(t *testing.T) {
 	assert.Equal(t, "0xdef", addr)
 	assert.Equal(t, "0x01", val)
 
+	txType, addr, _ = utils.DetectERC20Event("0xFED", []string{utils.ERC1155TransferBatchTopic, "0x1", "0x2", "0x3"}, "0x")
+	assert.Equal(t, types.TxTypeTransfer, txType)
+	assert.Equal(t, "0xfed", addr)
+
 	txType, addr, val = utils.DetectERC20Event("0xGHI", unknownTopic, "")
 	assert.Equal(t, types.TxTypeUnknown, txType)
 	assert.Equal(t, "", addr)
 	assert.Equal(t, "", val)
 }
 
+func TestDetectTokenEvent(t *testing.T) {
+	const transferSig = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	const approveSig = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+	from := "0x000000000000000000000000000000000000000000000000000000000000000a"
+	to := "0x000000000000000000000000000000000000000000000000000000000000000b"
+	id := "0x00000000000000000000000000000000000000000000000000000000000004d2"
+
+	ev, ok := utils.DetectTokenEvent("0xERC20", []string{transferSig, from, to}, "0x64")
+	assert.True(t, ok)
+	assert.Equal(t, utils.TokenEvent{TxType: types.TxTypeTransfer, CoinType: types.CoinTypeToken, TokenAddress: "0xerc20"}, ev)
+
+	ev, ok = utils.DetectTokenEvent("0xNFT", []string{transferSig, from, to, id}, "0x")
+	assert.True(t, ok)
+	assert.Equal(t, utils.TokenEvent{TxType: types.TxTypeTransfer, CoinType: types.CoinTypeERC721, TokenAddress: "0xnft", TokenID: "1234"}, ev)
+
+	ev, ok = utils.DetectTokenEvent("0xNFT", []string{approveSig, from, to, id}, "0x")
+	assert.True(t, ok)
+	assert.Equal(t, types.TxTypeApprove, ev.TxType)
+	assert.Equal(t, types.CoinTypeERC721, ev.CoinType)
+	assert.Equal(t, "", ev.ApproveValue, "ERC-721 approvals carry no amount")
+	assert.Equal(t, "1234", ev.TokenID)
+
+	_, ok = utils.DetectTokenEvent("0xNFT", []string{"0xdeadbeef"}, "")
+	assert.False(t, ok)
+}
+
 func TestNormalizeNumericString(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -103,6 +133,31 @@ func TestNormalizeNumericString(t *testing.T) {
 	}
 }
 
+func TestTransferLogIndex(t *testing.T) {
+	const transfer = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	pad := "0x000000000000000000000000"
+	log := func(index, value string) types.RpcReceiptLog {
+		return types.RpcReceiptLog{
+			Address:         "0xToken",
+			Topics:          []string{transfer, pad + "00000000000000000000000000000000000000aa", pad + "00000000000000000000000000000000000000bb"},
+			Data:            value,
+			TransactionHash: "0x1",
+			LogIndex:        index,
+		}
+	}
+	logs := []types.RpcReceiptLog{log("0x3", "0x5"), log("0x4", "0xa"), log("0x7", "0xa")}
+	row := types.Transaction{Hash: "0x1", TokenAddress: "0xtoken", Balance: "10",
+		FromAddress: "0x00000000000000000000000000000000000000aa", ToAddress: "0x00000000000000000000000000000000000000bb"}
+
+	used := make(map[string]bool)
+	assert.Equal(t, int64(4), utils.TransferLogIndex(row, logs, used))
+	assert.Equal(t, int64(7), utils.TransferLogIndex(row, logs, used), "a repeat takes the next log")
+	assert.Equal(t, int64(0), utils.TransferLogIndex(row, logs, used), "no log left")
+
+	row.Balance = "6"
+	assert.Equal(t, int64(0), utils.TransferLogIndex(row, logs, make(map[string]bool)), "amount differs")
+}
+
 func TestParseStringToInt64OrDefault(t *testing.T) {
 	assert.Equal(t, int64(21000), utils.ParseStringToInt64OrDefault("21000", 0))
 	assert.Equal(t, int64(21000), utils.ParseStringToInt64OrDefault("0x5208", 0))
@@ -164,3 +219,33 @@ func TestPatchTokenTransactionsWithNormalTxInfo(t *testing.T) {
 	assert.Equal(t, 1, result[0].State)
 	assert.Equal(t, "0xblock", result[0].BlockHash)
 }
+
+func TestPatchTxPositionFromNormalTxs(t *testing.T) {
+	normal := []types.Transaction{{Hash: "0xabc", TxIndex: 7, BlockHash: "0xblock", GasLimit: "21000", State: 1}}
+	rows := []types.Transaction{
+		{Hash: "0xabc", GasLimit: "2300"},
+		{Hash: "0xabc", TxIndex: 3, BlockHash: "0xother"},
+		{Hash: "0xdef"},
+	}
+	utils.PatchTxPositionFromNormalTxs(rows, normal)
+	assert.Equal(t, int64(7), rows[0].TxIndex)
+	assert.Equal(t, "0xblock", rows[0].BlockHash)
+	assert.Equal(t, "2300", rows[0].GasLimit, "gas is the internal call's own")
+	assert.Equal(t, 0, rows[0].State)
+	assert.Equal(t, int64(3), rows[1].TxIndex)
+	assert.Equal(t, "0xother", rows[1].BlockHash)
+	assert.Equal(t, int64(0), rows[2].TxIndex)
+}
+
+func TestMarkEnrichment(t *testing.T) {
+	txs := []types.Transaction{
+		{Hash: "0x1", GasUsed: "21000", GasPrice: "1", TokenDisplayName: "ETH"},
+		{Hash: "0x2", TokenDisplayName: "USDT", Enrichment: types.EnrichmentLogsScanned},
+		{Hash: "0x3"},
+	}
+	utils.MarkEnrichment(txs)
+
+	assert.Equal(t, types.EnrichmentGasPatched|types.EnrichmentMetadataResolved, txs[0].Enrichment)
+	assert.Equal(t, types.EnrichmentLogsScanned|types.EnrichmentMetadataResolved, txs[1].Enrichment)
+	assert.Equal(t, 0, txs[2].Enrichment)
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+	"tx-aggregator/config"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+
+	"golang.org/x/time/rate"
+)
+
+// keyedLimiter remembers the settings a limiter was built from, so a config
+// change replaces the bucket instead of being ignored.
+type keyedLimiter struct {
+	cfg     types.RateLimitConfig
+	limiter *rate.Limiter
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*keyedLimiter)
+)
+
+// rateLimiter returns the limiter for key, or nil when key is unlimited.
+func rateLimiter(key string) *rate.Limiter {
+	if key == "" {
+		return nil
+	}
+	cfg, ok := config.Current().Providers.RateLimits[key]
+	if !ok || cfg.RPS <= 0 {
+		return nil
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = max(1, int(cfg.RPS))
+	}
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	if kl, ok := limiters[key]; ok && kl.cfg == cfg {
+		return kl.limiter
+	}
+	kl := &keyedLimiter{cfg: cfg, limiter: rate.NewLimiter(rate.Limit(cfg.RPS), cfg.Burst)}
+	limiters[key] = kl
+	return kl.limiter
+}
+
+// waitRateLimit blocks until the bucket of key admits one request and
+// records how long that took.
+func waitRateLimit(key string) {
+	l := rateLimiter(key)
+	if l == nil {
+		return
+	}
+	start := time.Now()
+	// Background never cancels and a burst of at least 1 always fits, so
+	// Wait cannot fail here.
+	_ = l.Wait(context.Background())
+	metrics.RateLimitWait.WithLabelValues(key).Observe(time.Since(start).Seconds())
+}
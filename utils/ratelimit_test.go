@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withRateLimits installs per-provider rate limits for the duration of a test.
+func withRateLimits(t *testing.T, limits map[string]types.RateLimitConfig) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.RateLimits = limits
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestRateLimiter_Unconfigured(t *testing.T) {
+	withRateLimits(t, map[string]types.RateLimitConfig{
+		"disabled": {RPS: 0, Burst: 5},
+	})
+
+	assert.Nil(t, rateLimiter(""))
+	assert.Nil(t, rateLimiter("unknown"))
+	assert.Nil(t, rateLimiter("disabled"))
+}
+
+func TestRateLimiter_ReusedUntilConfigChanges(t *testing.T) {
+	withRateLimits(t, map[string]types.RateLimitConfig{
+		"blockscan_eth": {RPS: 5},
+	})
+
+	l := rateLimiter("blockscan_eth")
+	assert.NotNil(t, l)
+	assert.Equal(t, 5, l.Burst(), "burst defaults to rps")
+	assert.Same(t, l, rateLimiter("blockscan_eth"))
+
+	withRateLimits(t, map[string]types.RateLimitConfig{
+		"blockscan_eth": {RPS: 2, Burst: 1},
+	})
+	l2 := rateLimiter("blockscan_eth")
+	assert.NotSame(t, l, l2)
+	assert.Equal(t, 1, l2.Burst())
+}
+
+func TestDoHttpRequestWithLogging_RateLimited(t *testing.T) {
+	withRateLimits(t, map[string]types.RateLimitConfig{
+		"throttled": {RPS: 20, Burst: 1},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		err := DoHttpRequestWithLogging("GET", "rate_limited", server.URL, nil, nil, nil, WithProviderKey("throttled"))
+		assert.NoError(t, err)
+	}
+	// One token is available up front, the other two arrive 50ms apart
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+)
+
+// LogPanic logs a recovered panic at error level with its stack, counts it
+// under component and returns it as an error for callers that report it.
+func LogPanic(component string, v any) error {
+	metrics.PanicsRecovered.WithLabelValues(component).Inc()
+	logger.Log.Error().
+		Str("component", component).
+		Str("panic", fmt.Sprint(v)).
+		Str("stack", string(debug.Stack())).
+		Msg("💥 Panic in background goroutine")
+	return fmt.Errorf("%s panicked: %v", component, v)
+}
+
+// Safely runs fn and recovers a panic in it, so a background loop keeps
+// running and the process survives. Handlers are covered by
+// middleware.Recover instead.
+func Safely(component string, fn func()) {
+	defer func() {
+		if e := recover(); e != nil {
+			LogPanic(component, e)
+		}
+	}()
+	fn()
+}
+
+// Go runs fn in a new goroutine through Safely.
+func Go(component string, fn func()) {
+	go Safely(component, fn)
+}
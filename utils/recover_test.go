@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/metrics"
+)
+
+func TestSafely(t *testing.T) {
+	before := testutil.ToFloat64(metrics.PanicsRecovered.WithLabelValues("test"))
+
+	ran := false
+	assert.NotPanics(t, func() {
+		Safely("test", func() {
+			ran = true
+			panic("boom")
+		})
+	})
+	assert.True(t, ran)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.PanicsRecovered.WithLabelValues("test")))
+
+	Safely("test", func() {})
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.PanicsRecovered.WithLabelValues("test")), "no panic, no count")
+
+	done := make(chan struct{})
+	Go("test", func() {
+		defer close(done)
+		panic("boom")
+	})
+	<-done
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.PanicsRecovered.WithLabelValues("test")) == before+2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLogPanic(t *testing.T) {
+	err := LogPanic("test", "boom")
+	assert.EqualError(t, err, "test panicked: boom")
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"tx-aggregator/config"
 )
@@ -129,3 +130,36 @@ func NativeTokenByChainID(id int64) (string, error) {
 	}
 	return token, nil
 }
+
+// NativeDecimalsByChainID returns the number of decimals of the native token
+// of a chain, from native_decimals, or def when the chain has no entry (18
+// for EVM chains, the protocol's own precision for the others).
+func NativeDecimalsByChainID(id int64, def int64) int64 {
+	if d, ok := config.Current().NativeDecimals[strconv.FormatInt(id, 10)]; ok {
+		return d
+	}
+	return def
+}
+
+// ChainRPCURL returns the JSON-RPC endpoint used for live reads on a chain:
+// its rpc_urls entry, else the rpc_url of its Blockscout entry, else the url
+// of its rpc_scan entry. It returns false when the chain has none.
+func ChainRPCURL(chainName string) (string, bool) {
+	cfg := config.Current()
+	for name, url := range cfg.RPCURLs {
+		if strings.EqualFold(name, chainName) {
+			return url, true
+		}
+	}
+	for _, b := range cfg.Blockscout {
+		if strings.EqualFold(b.ChainName, chainName) && b.RPCURL != "" {
+			return b.RPCURL, true
+		}
+	}
+	for _, r := range cfg.RPCScan {
+		if strings.EqualFold(r.ChainName, chainName) && r.URL != "" {
+			return r.URL, true
+		}
+	}
+	return "", false
+}
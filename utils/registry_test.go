@@ -183,3 +183,38 @@ func TestResolveAnkrBlockchains(t *testing.T) {
 		})
 	}
 }
+
+func TestNativeDecimalsByChainID(t *testing.T) {
+	cfg := config.Current()
+	cfg.NativeDecimals = map[string]int64{"728126428": 6, "295": 8}
+	config.SetCurrentConfig(cfg)
+
+	assert.Equal(t, int64(6), utils.NativeDecimalsByChainID(728126428, types.NativeDefaultDecimals))
+	assert.Equal(t, int64(8), utils.NativeDecimalsByChainID(295, types.NativeDefaultDecimals))
+	assert.Equal(t, int64(types.NativeDefaultDecimals), utils.NativeDecimalsByChainID(1, types.NativeDefaultDecimals), "EVM chains default to 18")
+	assert.Equal(t, int64(9), utils.NativeDecimalsByChainID(607, 9), "other chains default to their protocol precision")
+}
+
+func TestChainRPCURL(t *testing.T) {
+	cfg := config.Current()
+	cfg.RPCURLs = map[string]string{"eth": "https://eth.rpc.example"}
+	cfg.Blockscout = []types.BlockscoutConfig{
+		{ChainName: "ETH", RPCURL: "https://eth.blockscout.rpc"},
+		{ChainName: "GNO", RPCURL: "https://gno.blockscout.rpc"},
+		{ChainName: "BASE"},
+	}
+	cfg.RPCScan = []types.RPCScanConfig{{ChainName: "PRIV", URL: "https://priv.rpc"}}
+	config.SetCurrentConfig(cfg)
+
+	for chain, want := range map[string]string{
+		"ETH":  "https://eth.rpc.example",
+		"gno":  "https://gno.blockscout.rpc",
+		"PRIV": "https://priv.rpc",
+	} {
+		url, ok := utils.ChainRPCURL(chain)
+		assert.True(t, ok, chain)
+		assert.Equal(t, want, url, chain)
+	}
+	_, ok := utils.ChainRPCURL("BASE")
+	assert.False(t, ok, "a Blockscout entry without rpc_url")
+}
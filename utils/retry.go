@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+	"tx-aggregator/config"
+)
+
+const (
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// retryPolicy is the resolved form of types.HTTPRetryConfig.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// currentRetryPolicy reads the retry settings from the live configuration,
+// applying defaults for unset delays.
+func currentRetryPolicy() retryPolicy {
+	cfg := config.Current().Providers.Retry
+	p := retryPolicy{
+		maxRetries: cfg.MaxRetries,
+		baseDelay:  time.Duration(cfg.BaseDelayMs) * time.Millisecond,
+		maxDelay:   time.Duration(cfg.MaxDelayMs) * time.Millisecond,
+	}
+	if p.maxRetries < 0 {
+		p.maxRetries = 0
+	}
+	if p.baseDelay <= 0 {
+		p.baseDelay = defaultRetryBaseDelay
+	}
+	if p.maxDelay <= 0 {
+		p.maxDelay = defaultRetryMaxDelay
+	}
+	if p.maxDelay < p.baseDelay {
+		p.maxDelay = p.baseDelay
+	}
+	return p
+}
+
+// delay returns how long to wait before retry number attempt (0-based). A
+// positive retryAfter from the server wins over the computed backoff; both
+// are capped at maxDelay. The backoff uses "equal jitter": a random value
+// between half and all of the exponential step.
+func (p retryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.maxDelay)
+	}
+	d := p.maxDelay
+	if attempt < 30 {
+		d = min(p.baseDelay<<attempt, p.maxDelay)
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// retryReason classifies a failed attempt: "429", "5xx" or "timeout" when it
+// is worth retrying, "" otherwise. err is the transport error, if any.
+func retryReason(statusCode int, err error) string {
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "timeout"
+		}
+		return ""
+	}
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return "429"
+	case statusCode >= 500 && statusCode <= 599:
+		return "5xx"
+	}
+	return ""
+}
+
+// parseRetryAfter interprets a Retry-After header, given either as seconds
+// or as an HTTP date. It returns 0 when the header is absent or unusable.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
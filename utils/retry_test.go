@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+	"tx-aggregator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withRetryConfig installs retry settings for the duration of a test.
+func withRetryConfig(t *testing.T, maxRetries int, baseMs, maxMs int64) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.Retry.MaxRetries = maxRetries
+	cfg.Providers.Retry.BaseDelayMs = baseMs
+	cfg.Providers.Retry.MaxDelayMs = maxMs
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestCurrentRetryPolicy_Defaults(t *testing.T) {
+	withRetryConfig(t, -1, 0, 0)
+
+	p := currentRetryPolicy()
+	assert.Equal(t, 0, p.maxRetries)
+	assert.Equal(t, defaultRetryBaseDelay, p.baseDelay)
+	assert.Equal(t, defaultRetryMaxDelay, p.maxDelay)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := retryPolicy{maxRetries: 5, baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	for attempt, step := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		step *= time.Millisecond
+		d := p.delay(attempt, 0)
+		assert.GreaterOrEqual(t, d, step/2, "attempt %d", attempt)
+		assert.LessOrEqual(t, d, step, "attempt %d", attempt)
+	}
+
+	// Retry-After wins, but never beyond the cap
+	assert.Equal(t, 300*time.Millisecond, p.delay(0, 300*time.Millisecond))
+	assert.Equal(t, time.Second, p.delay(0, time.Minute))
+
+	// Very large attempt numbers must not overflow the shift
+	assert.LessOrEqual(t, p.delay(100, 0), time.Second)
+}
+
+func TestRetryReason(t *testing.T) {
+	assert.Equal(t, "429", retryReason(http.StatusTooManyRequests, nil))
+	assert.Equal(t, "5xx", retryReason(http.StatusBadGateway, nil))
+	assert.Equal(t, "", retryReason(http.StatusNotFound, nil))
+	assert.Equal(t, "timeout", retryReason(0, os.ErrDeadlineExceeded))
+	assert.Equal(t, "", retryReason(0, errors.New("connection refused")))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-1", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+}
@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+// RPCBlockNumber returns the head block of a JSON-RPC node (eth_blockNumber).
+func RPCBlockNumber(label, url string) (int64, error) {
+	req := types.RpcRequest{JSONRPC: "2.0", ID: 1, Method: "eth_blockNumber", Params: []interface{}{}}
+
+	var resp types.RpcResponse
+	if err := DoHttpRequestWithLogging("POST", label, url, req,
+		map[string]string{"Content-Type": "application/json"}, &resp); err != nil {
+		return 0, err
+	}
+	if resp.Error != nil {
+		return 0, resp.Error
+	}
+	var head string
+	if err := json.Unmarshal(resp.Result, &head); err != nil {
+		return 0, fmt.Errorf("decode eth_blockNumber: %w", err)
+	}
+	n := ParseStringToInt64OrDefault(head, -1)
+	if n < 0 {
+		return 0, fmt.Errorf("invalid block number %q", head)
+	}
+	return n, nil
+}
+
+// RPCTransactionReceipt returns the receipt of the transaction hash
+// (eth_getTransactionReceipt), or nil when the node does not know it or it
+// is still pending.
+func RPCTransactionReceipt(label, url, hash string) (*types.RpcReceipt, error) {
+	req := types.RpcRequest{JSONRPC: "2.0", ID: 1, Method: "eth_getTransactionReceipt", Params: []interface{}{hash}}
+
+	var resp types.RpcResponse
+	if err := DoHttpRequestWithLogging("POST", label, url, req,
+		map[string]string{"Content-Type": "application/json"}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var receipt *types.RpcReceipt
+	if err := json.Unmarshal(resp.Result, &receipt); err != nil {
+		return nil, fmt.Errorf("decode eth_getTransactionReceipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// rpcBatchSize caps the requests of one JSON-RPC batch.
+const rpcBatchSize = 50
+
+// rpcBatchByHash calls method for every hash, in JSON-RPC batches of
+// rpcBatchSize, and returns the raw results by hash. Hashes the node answers
+// with an error or null are left out. When a batch fails, the results of
+// the earlier ones are returned with the error.
+func rpcBatchByHash(label, url, method string, hashes []string, opts ...RequestOption) (map[string]json.RawMessage, error) {
+	out := make(map[string]json.RawMessage, len(hashes))
+	for start := 0; start < len(hashes); start += rpcBatchSize {
+		shard := hashes[start:min(start+rpcBatchSize, len(hashes))]
+		reqs := make([]types.RpcRequest, len(shard))
+		for i, hash := range shard {
+			reqs[i] = types.RpcRequest{JSONRPC: "2.0", ID: i + 1, Method: method, Params: []interface{}{hash}}
+		}
+
+		var resps []types.RpcResponse
+		if err := DoHttpRequestWithLogging("POST", label, url, reqs,
+			map[string]string{"Content-Type": "application/json"}, &resps, opts...); err != nil {
+			return out, err
+		}
+		for _, resp := range resps {
+			if resp.Error != nil || resp.ID < 1 || resp.ID > len(shard) || string(resp.Result) == "null" || len(resp.Result) == 0 {
+				continue
+			}
+			out[shard[resp.ID-1]] = resp.Result
+		}
+	}
+	return out, nil
+}
+
+// RPCTransactionsByHash returns the transactions of hashes by hash
+// (eth_getTransactionByHash, batched). Unknown hashes are left out; see
+// rpcBatchByHash for errors.
+func RPCTransactionsByHash(label, url string, hashes []string, opts ...RequestOption) (map[string]*types.RpcTransaction, error) {
+	raw, err := rpcBatchByHash(label, url, "eth_getTransactionByHash", hashes, opts...)
+	txs := make(map[string]*types.RpcTransaction, len(raw))
+	for hash, result := range raw {
+		var tx types.RpcTransaction
+		if json.Unmarshal(result, &tx) == nil {
+			txs[hash] = &tx
+		}
+	}
+	return txs, err
+}
+
+// RPCTransactionReceipts returns the receipts of hashes by hash
+// (eth_getTransactionReceipt, batched). Unknown and pending transactions are
+// left out; see rpcBatchByHash for errors.
+func RPCTransactionReceipts(label, url string, hashes []string, opts ...RequestOption) (map[string]*types.RpcReceipt, error) {
+	raw, err := rpcBatchByHash(label, url, "eth_getTransactionReceipt", hashes, opts...)
+	receipts := make(map[string]*types.RpcReceipt, len(raw))
+	for hash, result := range raw {
+		var receipt types.RpcReceipt
+		if json.Unmarshal(result, &receipt) == nil {
+			receipts[hash] = &receipt
+		}
+	}
+	return receipts, err
+}
+
+// RPCTraceTransaction returns the call tree of the transaction hash
+// (debug_traceTransaction with the callTracer).
+func RPCTraceTransaction(label, url, hash string) (*types.RpcCallFrame, error) {
+	tracer := map[string]string{"tracer": "callTracer"}
+	req := types.RpcRequest{JSONRPC: "2.0", ID: 1, Method: "debug_traceTransaction", Params: []interface{}{hash, tracer}}
+
+	var resp types.RpcResponse
+	if err := DoHttpRequestWithLogging("POST", label, url, req,
+		map[string]string{"Content-Type": "application/json"}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var frame types.RpcCallFrame
+	if err := json.Unmarshal(resp.Result, &frame); err != nil {
+		return nil, fmt.Errorf("decode debug_traceTransaction: %w", err)
+	}
+	return &frame, nil
+}
+
+// RPCTraceBlock returns the Parity-style traces of every transaction of the
+// block at height (trace_block).
+func RPCTraceBlock(label, url string, height int64) ([]types.RpcTrace, error) {
+	block := "0x" + strconv.FormatInt(height, 16)
+	req := types.RpcRequest{JSONRPC: "2.0", ID: 1, Method: "trace_block", Params: []interface{}{block}}
+
+	var resp types.RpcResponse
+	if err := DoHttpRequestWithLogging("POST", label, url, req,
+		map[string]string{"Content-Type": "application/json"}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	var traces []types.RpcTrace
+	if err := json.Unmarshal(resp.Result, &traces); err != nil {
+		return nil, fmt.Errorf("decode trace_block: %w", err)
+	}
+	return traces, nil
+}
+
+// allowanceSelector is the selector of ERC-20 allowance(address,address).
+const allowanceSelector = "0xdd62ed3e"
+
+// RPCCall runs eth_call of data against the contract to at the latest block
+// and returns the hex result.
+func RPCCall(label, url, to, data string) (string, error) {
+	call := map[string]string{"to": to, "data": data}
+	req := types.RpcRequest{JSONRPC: "2.0", ID: 1, Method: "eth_call", Params: []interface{}{call, "latest"}}
+
+	var resp types.RpcResponse
+	if err := DoHttpRequestWithLogging("POST", label, url, req,
+		map[string]string{"Content-Type": "application/json"}, &resp); err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+	var out string
+	if err := json.Unmarshal(resp.Result, &out); err != nil {
+		return "", fmt.Errorf("decode eth_call: %w", err)
+	}
+	return out, nil
+}
+
+// RPCAllowance returns the ERC-20 allowance granted by owner to spender on
+// token, read live with eth_call. Addresses are 0x-prefixed hex.
+func RPCAllowance(label, url, token, owner, spender string) (*big.Int, error) {
+	data := allowanceSelector + abiAddress(owner) + abiAddress(spender)
+	out, err := RPCCall(label, url, token, data)
+	if err != nil {
+		return nil, err
+	}
+	hexValue := strings.TrimPrefix(out, "0x")
+	if hexValue == "" {
+		// Calls to an address without code return no data.
+		return nil, fmt.Errorf("empty allowance result from %s, not an ERC-20 contract", token)
+	}
+	v, ok := new(big.Int).SetString(hexValue, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid allowance result %q", out)
+	}
+	return v, nil
+}
+
+// abiAddress ABI-encodes an address as a 32-byte word.
+func abiAddress(addr string) string {
+	return strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(addr, "0x"))
+}
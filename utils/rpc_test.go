@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPCBlockNumber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "eth_blockNumber", req["method"])
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+	}))
+	defer srv.Close()
+
+	head, err := RPCBlockNumber("test.blockNumber", srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), head)
+}
+
+func TestRPCAllowance(t *testing.T) {
+	result := `"0x00000000000000000000000000000000000000000000000000000000000f4240"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                   `json:"method"`
+			Params []map[string]interface{} `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "eth_call", req.Method)
+		assert.Equal(t, "0xtoken", req.Params[0]["to"])
+		assert.Equal(t, "0xdd62ed3e"+
+			"000000000000000000000000aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"+
+			"000000000000000000000000bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", req.Params[0]["data"])
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":`+result+`}`)
+	}))
+	defer srv.Close()
+
+	v, err := RPCAllowance("test.allowance", srv.URL, "0xtoken",
+		"0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+	assert.NoError(t, err)
+	assert.Equal(t, "1000000", v.String())
+
+	result = `"0x"`
+	_, err = RPCAllowance("test.allowance", srv.URL, "0xtoken",
+		"0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+	assert.Error(t, err, "no contract code")
+}
+
+func TestRPCTransactionReceipt(t *testing.T) {
+	result := `{"transactionHash":"0xabc","blockHash":"0xb1","blockNumber":"0x10","status":"0x1"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "eth_getTransactionReceipt", req["method"])
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":`+result+`}`)
+	}))
+	defer srv.Close()
+
+	receipt, err := RPCTransactionReceipt("test.receipt", srv.URL, "0xabc")
+	assert.NoError(t, err)
+	if assert.NotNil(t, receipt) {
+		assert.Equal(t, "0x10", receipt.BlockNumber)
+	}
+
+	result = "null"
+	receipt, err = RPCTransactionReceipt("test.receipt", srv.URL, "0xabc")
+	assert.NoError(t, err)
+	assert.Nil(t, receipt, "unknown or pending")
+}
+
+func TestRPCTransactionsByHash(t *testing.T) {
+	hashes := make([]string, rpcBatchSize+2)
+	for i := range hashes {
+		hashes[i] = fmt.Sprintf("0x%064x", i)
+	}
+	batches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []struct {
+			ID     int      `json:"id"`
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&reqs)
+		batches++
+		var resps []string
+		for _, req := range reqs {
+			assert.Equal(t, "eth_getTransactionByHash", req.Method)
+			switch req.Params[0] {
+			case hashes[1]:
+				resps = append(resps, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":null}`, req.ID))
+			case hashes[2]:
+				resps = append(resps, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32000,"message":"boom"}}`, req.ID))
+			default:
+				resps = append(resps, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"hash":%q,"nonce":"0x7","transactionIndex":"0x3","blockHash":"0xblock"}}`, req.ID, req.Params[0]))
+			}
+		}
+		_, _ = io.WriteString(w, "["+strings.Join(resps, ",")+"]")
+	}))
+	defer srv.Close()
+
+	txs, err := RPCTransactionsByHash("test.txByHash", srv.URL, hashes)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, batches)
+	assert.Len(t, txs, len(hashes)-2, "unknown and failed hashes are left out")
+	last := txs[hashes[len(hashes)-1]]
+	if assert.NotNil(t, last) {
+		assert.Equal(t, hashes[len(hashes)-1], last.Hash)
+		assert.Equal(t, "0x3", last.TransactionIndex)
+		assert.Equal(t, "0x7", last.Nonce)
+	}
+}
+
+func TestRPCTransactionReceipts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&reqs)
+		assert.Len(t, reqs, 2)
+		assert.Equal(t, "eth_getTransactionReceipt", reqs[0]["method"])
+		_, _ = io.WriteString(w, `[{"jsonrpc":"2.0","id":2,"result":null},{"jsonrpc":"2.0","id":1,"result":{"transactionHash":"0xa","status":"0x0"}}]`)
+	}))
+	defer srv.Close()
+
+	receipts, err := RPCTransactionReceipts("test.receipts", srv.URL, []string{"0xa", "0xpending"})
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+	if assert.Contains(t, receipts, "0xa") {
+		assert.Equal(t, "0x0", receipts["0xa"].Status)
+	}
+}
+
+func TestRPCTraceTransaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "debug_traceTransaction", req["method"])
+		assert.Equal(t, []interface{}{"0xabc", map[string]interface{}{"tracer": "callTracer"}}, req["params"])
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{"type":"CALL","calls":[{"type":"CALL","value":"0x1"}]}}`)
+	}))
+	defer srv.Close()
+
+	frame, err := RPCTraceTransaction("test.trace", srv.URL, "0xabc")
+	assert.NoError(t, err)
+	if assert.Len(t, frame.Calls, 1) {
+		assert.Equal(t, "0x1", frame.Calls[0].Value)
+	}
+}
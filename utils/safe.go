@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"tx-aggregator/types"
+)
+
+const (
+	// SafeExecutionSuccessTopic is keccak256("ExecutionSuccess(bytes32,uint256)").
+	SafeExecutionSuccessTopic = "0x442e715f626346e8c54381002da614f62bee8d27386535b2521ec8540898556e"
+	// SafeExecutionFailureTopic is keccak256("ExecutionFailure(bytes32,uint256)").
+	SafeExecutionFailureTopic = "0x23428b18acfb3ea64b08dc0c1d296ea9c09702c09083ca5272e64d115b687d23"
+)
+
+// DetectSafeExecution reports whether a log emitted by contractAddress is
+// the ExecutionSuccess or ExecutionFailure event of a Safe and returns the
+// execution it records. The Safe transaction hash is the first data word,
+// or the first indexed topic in Safe versions that index it.
+func DetectSafeExecution(contractAddress string, topics []string, data string) (*types.SafeExecution, bool) {
+	if len(topics) == 0 {
+		return nil, false
+	}
+	exec := &types.SafeExecution{Address: strings.ToLower(contractAddress)}
+	switch strings.ToLower(topics[0]) {
+	case SafeExecutionSuccessTopic:
+		exec.Success = true
+	case SafeExecutionFailureTopic:
+	default:
+		return nil, false
+	}
+
+	if len(topics) > 1 {
+		exec.SafeTxHash = strings.ToLower(topics[1])
+	} else if b, err := hex.DecodeString(strings.TrimPrefix(data, "0x")); err == nil && len(b) >= 32 {
+		exec.SafeTxHash = "0x" + hex.EncodeToString(b[:32])
+	}
+	return exec, true
+}
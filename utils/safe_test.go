@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/types"
+)
+
+func TestDetectSafeExecution(t *testing.T) {
+	const (
+		safe       = "0x5afe5afe5afe5afe5afe5afe5afe5afe5afe5afe"
+		safeTxHash = "0xabababababababababababababababababababababababababababababababab"
+		payment    = "0000000000000000000000000000000000000000000000000000000000000000"
+	)
+
+	exec, ok := DetectSafeExecution("0x5AFE5AFE5AFE5AFE5AFE5AFE5AFE5AFE5AFE5AFE", []string{SafeExecutionSuccessTopic}, safeTxHash+payment)
+	assert.True(t, ok)
+	assert.Equal(t, &types.SafeExecution{Address: safe, SafeTxHash: safeTxHash, Success: true}, exec)
+
+	exec, ok = DetectSafeExecution(safe, []string{SafeExecutionFailureTopic}, safeTxHash+payment)
+	assert.True(t, ok)
+	assert.False(t, exec.Success)
+
+	exec, ok = DetectSafeExecution(safe, []string{SafeExecutionSuccessTopic, safeTxHash}, "0x"+payment)
+	assert.True(t, ok, "Safe versions indexing the hash")
+	assert.Equal(t, safeTxHash, exec.SafeTxHash)
+
+	_, ok = DetectSafeExecution(safe, []string{transferSig}, "0x")
+	assert.False(t, ok)
+	_, ok = DetectSafeExecution(safe, nil, "0x")
+	assert.False(t, ok)
+}
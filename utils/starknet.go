@@ -0,0 +1,32 @@
+package utils
+
+import "strings"
+
+// StarknetToCanonical validates a Starknet address (a felt written as "0x"
+// followed by up to 64 hex digits) and returns its canonical form: lowercase
+// and zero-padded to 64 digits, so that the padded and unpadded spellings
+// compare equal. Contract addresses are below 2^251.
+func StarknetToCanonical(addr string) (string, bool) {
+	if len(addr) < 3 || len(addr) > 66 || (addr[:2] != "0x" && addr[:2] != "0X") {
+		return "", false
+	}
+	digits := strings.ToLower(addr[2:])
+	for _, c := range digits {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", false
+		}
+	}
+	digits = strings.Repeat("0", 64-len(digits)) + digits
+	// 2^251 = 0x08 followed by 62 zero digits.
+	if digits[0] != '0' || digits[1] > '7' {
+		return "", false
+	}
+	return "0x" + digits, true
+}
+
+// IsValidStarknetAddress checks if addr is a Starknet felt address. Note that
+// every EVM address is also a valid felt; callers test EVM first.
+func IsValidStarknetAddress(addr string) bool {
+	_, ok := StarknetToCanonical(addr)
+	return ok
+}
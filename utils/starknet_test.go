@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStarknetToCanonical(t *testing.T) {
+	const eth = "0x049d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"
+
+	got, ok := StarknetToCanonical("0x49D36570D4E46F48E99674BD3FCC84644DDD6B96F7C741B1562B82F9E004DC7")
+	assert.True(t, ok)
+	assert.Equal(t, eth, got)
+
+	got, ok = StarknetToCanonical(eth)
+	assert.True(t, ok)
+	assert.Equal(t, eth, got)
+
+	got, ok = StarknetToCanonical("0x1")
+	assert.True(t, ok)
+	assert.Equal(t, "0x"+"0000000000000000000000000000000000000000000000000000000000000001", got)
+
+	_, ok = StarknetToCanonical("0x0800000000000000000000000000000000000000000000000000000000000000") // 2^251
+	assert.False(t, ok)
+	_, ok = StarknetToCanonical("0x" + "1" + eth[2:]) // 65 digits
+	assert.False(t, ok)
+	assert.False(t, IsValidStarknetAddress("0xzz"))
+	assert.False(t, IsValidStarknetAddress("49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"))
+}
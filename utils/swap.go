@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+const (
+	// UniswapV2SwapTopic is
+	// keccak256("Swap(address,uint256,uint256,uint256,uint256,address)").
+	UniswapV2SwapTopic = "0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"
+	// UniswapV3SwapTopic is
+	// keccak256("Swap(address,address,int256,int256,uint160,uint128,int24)").
+	UniswapV3SwapTopic = "0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67"
+)
+
+// DetectSwapEvent reports whether topics are those of a DEX Swap event and
+// returns its protocol: Uniswap V2 and V3, shared by their many forks, then
+// the swap_events entries.
+func DetectSwapEvent(topics []string) (protocol string, ok bool) {
+	if len(topics) == 0 {
+		return "", false
+	}
+	topic0 := strings.ToLower(topics[0])
+	switch topic0 {
+	case UniswapV2SwapTopic:
+		return types.SwapProtocolUniswapV2, true
+	case UniswapV3SwapTopic:
+		return types.SwapProtocolUniswapV3, true
+	}
+	for _, s := range config.Current().SwapEvents {
+		if strings.EqualFold(s.Topic, topic0) {
+			return s.Protocol, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestDetectSwapEvent(t *testing.T) {
+	orig := config.Current()
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+	const curveTopic = "0x8b3e96f2b889fa771c53c981b40daf005f63f637f1869f707052d15a3dd97140"
+	cfg := orig
+	cfg.SwapEvents = []types.SwapEventConfig{{Protocol: "curve", Topic: curveTopic}}
+	config.SetCurrentConfig(cfg)
+
+	tests := []struct {
+		name     string
+		topics   []string
+		protocol string
+		ok       bool
+	}{
+		{"uniswap v2", []string{UniswapV2SwapTopic}, types.SwapProtocolUniswapV2, true},
+		{"uniswap v3, uppercase", []string{"0xC42079F94A6350D7E6235F29174924F928CC2AC818EB64FED8004E115FBCCA67"}, types.SwapProtocolUniswapV3, true},
+		{"configured", []string{curveTopic}, "curve", true},
+		{"transfer", []string{transferSig}, "", false},
+		{"no topics", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol, ok := DetectSwapEvent(tt.topics)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.protocol, protocol)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"time"
+	"tx-aggregator/config"
+)
+
+// requestTimeout resolves the timeout of one attempt of a request with the
+// given label and provider key from providers.timeouts. Zero means no
+// per-request timeout.
+func requestTimeout(label, providerKey string) time.Duration {
+	cfg := config.Current().Providers.Timeouts
+
+	best, secs := -1, int64(0)
+	for _, lt := range cfg.Labels {
+		if lt.Seconds <= 0 || !labelHasPrefix(label, lt.Prefix) {
+			continue
+		}
+		if len(lt.Prefix) > best {
+			best, secs = len(lt.Prefix), lt.Seconds
+		}
+	}
+	if best < 0 && providerKey != "" {
+		secs = cfg.Providers[strings.ToLower(providerKey)]
+	}
+	if secs <= 0 {
+		secs = cfg.Default
+	}
+	if secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// labelHasPrefix reports whether label is prefix or one of its dotted
+// children, comparing case-insensitively.
+func labelHasPrefix(label, prefix string) bool {
+	if prefix == "" || len(label) < len(prefix) || !strings.EqualFold(label[:len(prefix)], prefix) {
+		return false
+	}
+	return len(label) == len(prefix) || label[len(prefix)] == '.'
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withTimeouts installs request timeouts for the duration of a test.
+func withTimeouts(t *testing.T, timeouts types.HTTPTimeoutConfig) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.Timeouts = timeouts
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestRequestTimeout_Precedence(t *testing.T) {
+	withTimeouts(t, types.HTTPTimeoutConfig{
+		Default:   30,
+		Providers: map[string]int64{"blockscout_ttx": 20},
+		Labels: []types.LabelTimeoutConfig{
+			{Prefix: "blockscout", Seconds: 15},
+			{Prefix: "blockscout.rpcReceipts", Seconds: 5},
+			{Prefix: "ankr.disabled", Seconds: 0},
+		},
+	})
+
+	assert.Equal(t, 5*time.Second, requestTimeout("blockscout.rpcReceipts.shard.3", "blockscout_ttx"))
+	assert.Equal(t, 15*time.Second, requestTimeout("blockscout.normalTx", "blockscout_ttx"))
+	assert.Equal(t, 20*time.Second, requestTimeout("blockscoutish", "blockscout_ttx"))
+	assert.Equal(t, 20*time.Second, requestTimeout("x", "BLOCKSCOUT_TTX"))
+	assert.Equal(t, 30*time.Second, requestTimeout("ankr.disabled", "ankr"))
+	assert.Equal(t, 30*time.Second, requestTimeout("vault.readSecret", ""))
+}
+
+func TestRequestTimeout_Unset(t *testing.T) {
+	withTimeouts(t, types.HTTPTimeoutConfig{})
+	assert.Zero(t, requestTimeout("blockscout.normalTx", "blockscout_ttx"))
+}
+
+func TestDoHttpRequestWithLogging_Timeout(t *testing.T) {
+	withTimeouts(t, types.HTTPTimeoutConfig{
+		Labels: []types.LabelTimeoutConfig{{Prefix: "slow", Seconds: 1}},
+	})
+	withRetryConfig(t, 1, 1, 5)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	err := DoHttpRequestWithLogging("GET", "slow.call", server.URL, nil, nil, nil)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int32(2), calls.Load(), "a timed-out attempt is retried")
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IsValidTonAddress checks if addr is a TON address, either raw
+// ("<workchain>:<64 hex>") or user-friendly (48 base64 / base64url chars).
+func IsValidTonAddress(addr string) bool {
+	_, ok := TonToRaw(addr)
+	return ok
+}
+
+// TonToRaw converts a TON address to its canonical raw form
+// "<workchain>:<lowercase hex>". User-friendly addresses (bounceable "EQ…" or
+// non-bounceable "UQ…") are checked against their CRC16; both flavours of
+// the same account map to the same raw address.
+func TonToRaw(addr string) (string, bool) {
+	if wc, hash, found := strings.Cut(addr, ":"); found {
+		n, err := strconv.ParseInt(wc, 10, 8)
+		if err != nil || len(hash) != 64 {
+			return "", false
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("%d:%s", n, strings.ToLower(hash)), true
+	}
+
+	if len(addr) != 48 {
+		return "", false
+	}
+	enc := base64.URLEncoding
+	if strings.ContainsAny(addr, "+/") {
+		enc = base64.StdEncoding
+	}
+	b, err := enc.DecodeString(addr)
+	if err != nil || len(b) != 36 {
+		return "", false
+	}
+	if crc16XModem(b[:34]) != binary.BigEndian.Uint16(b[34:]) {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%s", int8(b[1]), hex.EncodeToString(b[2:34])), true
+}
+
+// crc16XModem is the CRC16 (poly 0x1021, init 0) used by TON addresses.
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, c := range data {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
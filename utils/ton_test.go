@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTonToRaw(t *testing.T) {
+	const raw = "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e"
+
+	got, ok := TonToRaw("EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF") // bounceable
+	assert.True(t, ok)
+	assert.Equal(t, raw, got)
+
+	got, ok = TonToRaw("UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPuwA") // non-bounceable
+	assert.True(t, ok)
+	assert.Equal(t, raw, got)
+
+	got, ok = TonToRaw("0:CA6E321C7CCE9ECEDF0A8CA2492EC8592494AA5FB5CE0387DFF96EF6AF982A3E")
+	assert.True(t, ok)
+	assert.Equal(t, raw, got)
+
+	_, ok = TonToRaw("EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHG") // bad CRC
+	assert.False(t, ok)
+	assert.False(t, IsValidTonAddress("0x0123456789abcdef0123456789abcdef01234567"))
+	assert.False(t, IsValidTonAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"))
+}
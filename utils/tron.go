@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// tronAddressVersion is the version byte of Tron addresses (base58 "T...").
+const tronAddressVersion = 0x41
+
+// IsValidTronAddress checks if addr is a base58check Tron address.
+func IsValidTronAddress(addr string) bool {
+	if len(addr) != 34 || !strings.HasPrefix(addr, "T") {
+		return false
+	}
+	payload, ok := base58CheckDecode(addr)
+	return ok && len(payload) == 21 && payload[0] == tronAddressVersion
+}
+
+// TronHexToBase58 converts a hex Tron address ("41…", 21 bytes, as returned
+// inside raw transaction data) to its base58 form. Other inputs are returned
+// unchanged.
+func TronHexToBase58(addr string) string {
+	raw, err := hex.DecodeString(strings.TrimPrefix(addr, "0x"))
+	if err != nil || len(raw) != 21 || raw[0] != tronAddressVersion {
+		return addr
+	}
+	return base58CheckEncode(raw)
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidTronAddress(t *testing.T) {
+	assert.True(t, IsValidTronAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"))  // USDT contract
+	assert.False(t, IsValidTronAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u")) // bad checksum
+	assert.False(t, IsValidTronAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")) // bitcoin
+	assert.False(t, IsValidTronAddress("0x0123456789abcdef0123456789abcdef01234567"))
+}
+
+func TestTronHexToBase58(t *testing.T) {
+	assert.Equal(t, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", TronHexToBase58("41a614f803b6fd780986a42c78ec9c7f77e6ded13c"))
+	assert.Equal(t, "not-hex", TronHexToBase58("not-hex"))
+}
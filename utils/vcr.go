@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+)
+
+// VCR modes of providers.vcr.mode.
+const (
+	VCRModeOff    = "off"
+	VCRModeRecord = "record"
+	VCRModeReplay = "replay"
+)
+
+const defaultVCRDir = "testdata/vcr"
+
+// vcrRecording is one recorded exchange, stored as
+// <dir>/<host>/<request hash>.json. The body is kept as JSON when it is JSON,
+// so recordings can be read and edited by hand.
+type vcrRecording struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"` // Credentials masked, see config.RedactURL
+	Request  json.RawMessage `json:"request,omitempty"`
+	Status   int             `json:"status"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"bodyText,omitempty"` // Used when the body is not JSON
+}
+
+// vcrProviderCtxKey marks the context of a request made on behalf of a
+// provider (see WithProviderKey). Only those requests are recorded or
+// replayed: Vault reads, alert webhooks and token lists always go to the
+// network, so secrets are never written to disk and alerts are never
+// swallowed.
+type vcrProviderCtxKey struct{}
+
+// withVCRProvider marks ctx as belonging to a provider request.
+func withVCRProvider(ctx context.Context) context.Context {
+	return context.WithValue(ctx, vcrProviderCtxKey{}, true)
+}
+
+// vcrTransport records or replays provider requests according to
+// providers.vcr.mode, read on every request. Only 2xx responses are
+// recorded; replaying a request that was never recorded fails without
+// touching the network.
+type vcrTransport struct {
+	base http.RoundTripper
+}
+
+func (t *vcrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := config.Current().Providers.VCR
+	mode := strings.ToLower(cfg.Mode)
+	if mode == "" || mode == VCRModeOff || req.Context().Value(vcrProviderCtxKey{}) == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	reqBody, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultVCRDir
+	}
+	file := filepath.Join(dir, vcrHost(req.URL), vcrKey(req.Method, req.URL, reqBody)+".json")
+
+	switch mode {
+	case VCRModeReplay:
+		return replayRecording(req, file)
+	case VCRModeRecord:
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return resp, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err := writeRecording(file, req, reqBody, resp.StatusCode, body); err != nil {
+			logger.Log.Warn().Err(err).Str("file", file).Msg("Failed to record HTTP exchange")
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("unknown providers.vcr.mode %q", cfg.Mode)
+}
+
+// requestBody returns a copy of the request body, leaving the request
+// readable.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// replayRecording answers req from file.
+func replayRecording(req *http.Request, file string) (*http.Response, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("vcr: no recording of %s %s: %w", req.Method, vcrURL(req.URL), err)
+	}
+	var rec vcrRecording
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("vcr: corrupt recording %s: %w", file, err)
+	}
+	body := []byte(rec.Body)
+	if rec.Body == nil {
+		body = []byte(rec.BodyText)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// writeRecording stores one exchange, replacing any earlier recording of the
+// same request.
+func writeRecording(file string, req *http.Request, reqBody []byte, status int, body []byte) error {
+	rec := vcrRecording{
+		Method: req.Method,
+		URL:    vcrURL(req.URL),
+		Status: status,
+	}
+	if len(reqBody) > 0 && json.Valid(reqBody) {
+		rec.Request = reqBody
+	}
+	if json.Valid(body) {
+		rec.Body = body
+	} else {
+		rec.BodyText = string(body)
+	}
+	raw, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// vcrKey hashes what identifies a request: its method, its URL with
+// credentials masked (so recordings made with one API key replay with
+// another) and its body.
+func vcrKey(method string, u *url.URL, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + vcrURL(u) + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// vcrURL returns u with the credentials in its userinfo, query and path
+// masked (see config.RedactURL). Query parameters are sorted.
+func vcrURL(u *url.URL) string {
+	redacted, err := config.RedactURL(u.String())
+	if err != nil {
+		return u.Scheme + "://" + u.Host
+	}
+	return redacted
+}
+
+// vcrHost returns the directory of a host's recordings.
+func vcrHost(u *url.URL) string {
+	return strings.NewReplacer(":", "_", "/", "_").Replace(u.Host)
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+)
+
+// withVCR sets providers.vcr for the duration of a test.
+func withVCR(t *testing.T, mode, dir string) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.VCR.Mode = mode
+	cfg.Providers.VCR.Dir = dir
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestVCR_RecordThenReplay(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`{"result":[{"hash":"0x1"}]}`))
+	}))
+	dir := t.TempDir()
+
+	withVCR(t, VCRModeRecord, dir)
+	var got map[string]interface{}
+	err := DoHttpRequestWithLogging("POST", "test.vcr", server.URL+"/api?module=account&apikey=real", map[string]string{"a": "b"}, nil, &got, WithProviderKey("test"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if !assert.Len(t, files, 1) {
+		return
+	}
+	raw, _ := os.ReadFile(files[0])
+	assert.NotContains(t, string(raw), "real", "credentials are masked")
+	assert.Contains(t, string(raw), `"hash": "0x1"`)
+
+	// Replay with the server gone and a different API key
+	server.Close()
+	withVCR(t, VCRModeReplay, dir)
+	var replayed map[string]interface{}
+	err = DoHttpRequestWithLogging("POST", "test.vcr", server.URL+"/api?apikey=other&module=account", map[string]string{"a": "b"}, nil, &replayed, WithProviderKey("test"))
+	assert.NoError(t, err)
+	assert.Equal(t, got, replayed)
+
+	// A different body was never recorded
+	err = DoHttpRequestWithLogging("POST", "test.vcr", server.URL+"/api?module=account", map[string]string{"a": "c"}, nil, nil, WithProviderKey("test"))
+	assert.ErrorContains(t, err, "no recording")
+}
+
+func TestVCR_RecordSkipsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	withVCR(t, VCRModeRecord, dir)
+
+	err := DoHttpRequestWithLogging("GET", "test.vcr", server.URL, nil, nil, nil, WithProviderKey("test"))
+	assert.Error(t, err)
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	assert.Empty(t, files)
+}
+
+func TestVCR_OnlyProviderRequests(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`{"data":{"value":"s3cret"}}`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	withVCR(t, VCRModeRecord, dir)
+	assert.NoError(t, DoHttpRequestWithLogging("GET", "vault.read", server.URL+"/v1/secret/data/app", nil, nil, nil))
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	assert.Empty(t, files, "requests without a provider key are not recorded")
+
+	withVCR(t, VCRModeReplay, dir)
+	assert.NoError(t, DoHttpRequestWithLogging("POST", "alert.webhook", server.URL+"/hook", map[string]string{"a": "b"}, nil, nil))
+	assert.Equal(t, int32(2), hits.Load(), "nor replayed")
+}
+
+func TestVCR_MasksPathKeys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"0x1"}`))
+	}))
+	dir := t.TempDir()
+
+	withVCR(t, VCRModeRecord, dir)
+	assert.NoError(t, DoHttpRequestWithLogging("POST", "test.vcr", server.URL+"/v2/Ab3dEfGh1jKlMn0pQrStUvWx", nil, nil, nil, WithProviderKey("test")))
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if !assert.Len(t, files, 1) {
+		return
+	}
+	raw, _ := os.ReadFile(files[0])
+	assert.NotContains(t, string(raw), "Ab3dEfGh1jKlMn0pQrStUvWx")
+
+	server.Close()
+	withVCR(t, VCRModeReplay, dir)
+	assert.NoError(t, DoHttpRequestWithLogging("POST", "test.vcr", server.URL+"/v2/Zz9yXwVu8tSrQp7oNmLkJiHg", nil, nil, nil, WithProviderKey("test")),
+		"a recording made with one path key replays with another")
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"strings"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+const (
+	// WrappedNativeDepositTopic is keccak256("Deposit(address,uint256)"),
+	// emitted by WETH9-style contracts when native coin is wrapped.
+	WrappedNativeDepositTopic = "0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c"
+	// WrappedNativeWithdrawalTopic is keccak256("Withdrawal(address,uint256)"),
+	// emitted when wrapped native coin is unwrapped.
+	WrappedNativeWithdrawalTopic = "0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65"
+)
+
+// WrappedNativeByChainID returns the lowercase wrapped-native token contract
+// of a chain from wrapped_native, or false when the chain has none.
+func WrappedNativeByChainID(id int64) (string, bool) {
+	for name, contract := range config.Current().WrappedNative {
+		if chainID, err := ChainIDByName(name); err == nil && chainID == id {
+			return strings.ToLower(contract), true
+		}
+	}
+	return "", false
+}
+
+// DetectWrapEvent reports whether a log emitted by contractAddress on chain
+// chainID is a Deposit (types.TxTypeWrap) or a Withdrawal
+// (types.TxTypeUnwrap) of the chain's wrapped-native contract. Tokens that
+// merely share the event signatures are ignored.
+func DetectWrapEvent(chainID int64, contractAddress string, topics []string) (txType int, ok bool) {
+	if len(topics) == 0 {
+		return types.TxTypeUnknown, false
+	}
+	wrapped, found := WrappedNativeByChainID(chainID)
+	if !found || !strings.EqualFold(contractAddress, wrapped) {
+		return types.TxTypeUnknown, false
+	}
+	switch strings.ToLower(topics[0]) {
+	case WrappedNativeDepositTopic:
+		return types.TxTypeWrap, true
+	case WrappedNativeWithdrawalTopic:
+		return types.TxTypeUnwrap, true
+	}
+	return types.TxTypeUnknown, false
+}
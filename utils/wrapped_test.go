@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestDetectWrapEvent(t *testing.T) {
+	orig := config.Current()
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"eth": 1, "bsc": 56}
+	cfg.WrappedNative = map[string]string{"eth": "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"}
+	config.SetCurrentConfig(cfg)
+
+	const weth = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	user := "0x000000000000000000000000" + "1111111111111111111111111111111111111111"
+
+	txType, ok := DetectWrapEvent(1, weth, []string{WrappedNativeDepositTopic, user})
+	assert.True(t, ok)
+	assert.Equal(t, types.TxTypeWrap, txType)
+
+	txType, ok = DetectWrapEvent(1, "0xC02AAA39B223FE8D0A0E5C4F27EAD9083C756CC2", []string{WrappedNativeWithdrawalTopic, user})
+	assert.True(t, ok)
+	assert.Equal(t, types.TxTypeUnwrap, txType)
+
+	_, ok = DetectWrapEvent(1, "0x2222222222222222222222222222222222222222", []string{WrappedNativeDepositTopic, user})
+	assert.False(t, ok, "other contracts emitting Deposit are not wraps")
+	_, ok = DetectWrapEvent(56, weth, []string{WrappedNativeDepositTopic, user})
+	assert.False(t, ok, "chains without a wrapped_native entry")
+	_, ok = DetectWrapEvent(1, weth, []string{transferSig, user, user})
+	assert.False(t, ok, "the contract's other events")
+	_, ok = DetectWrapEvent(1, weth, nil)
+	assert.False(t, ok)
+
+	contract, ok := WrappedNativeByChainID(1)
+	assert.True(t, ok)
+	assert.Equal(t, weth, contract)
+}
@@ -0,0 +1,52 @@
+// Package vault reads secrets from a HashiCorp Vault KV version 2 engine
+// over its HTTP API.
+package vault
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// kvV2Response is the subset of a KV v2 read response we care about.
+type kvV2Response struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+// ReadSecret returns the fields of the KV v2 secret at cfg.Path.
+// The token falls back to the VAULT_TOKEN environment variable and the
+// mount defaults to "secret".
+func ReadSecret(cfg types.VaultConfig) (map[string]string, error) {
+	if cfg.Address == "" || cfg.Path == "" {
+		return nil, fmt.Errorf("vault address and path are required")
+	}
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	mount := cfg.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s",
+		strings.TrimRight(cfg.Address, "/"),
+		strings.Trim(mount, "/"),
+		strings.TrimLeft(cfg.Path, "/"))
+
+	var resp kvV2Response
+	if err := utils.DoHttpRequestWithLogging("GET", "vault.readSecret", url, nil, map[string]string{
+		"X-Vault-Token": token,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data.Data) == 0 {
+		return nil, fmt.Errorf("vault secret %s is empty", cfg.Path)
+	}
+	return resp.Data.Data, nil
+}
@@ -0,0 +1,109 @@
+// Package warm keeps the cache of popular addresses fresh. Every query is
+// counted in a Redis ranking; a background job refreshes the entries of the
+// top-ranked address / chain pairs shortly before they expire, so popular
+// wallets almost never take the slow provider path.
+package warm
+
+import (
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultIntervalSeconds      = 10
+	defaultTopN                 = 100
+	defaultRefreshBeforeSeconds = 15
+
+	// decayFactor scales the ranking after every round, so an address that
+	// stops being queried drops out within a few minutes.
+	decayFactor = 0.5
+	// lockName makes a single instance run each round.
+	lockName = "job:warm"
+)
+
+// Warmer refreshes the cache entries of hot addresses.
+type Warmer struct {
+	store  interfaces.HotAddressStoreInterface
+	warmer interfaces.TransactionWarmerInterface
+}
+
+// NewWarmer creates a Warmer that ranks addresses in store and refreshes
+// them through warmer (normally the usecase Service).
+func NewWarmer(store interfaces.HotAddressStoreInterface, warmer interfaces.TransactionWarmerInterface) *Warmer {
+	return &Warmer{store: store, warmer: warmer}
+}
+
+// Start runs a round every interval_seconds in the background. It does
+// nothing unless warm.enabled is set.
+func (w *Warmer) Start() {
+	cfg := config.Current().Warm
+	if !cfg.Enabled {
+		return
+	}
+	interval := time.Duration(utils.IntOr(cfg.IntervalSeconds, defaultIntervalSeconds)) * time.Second
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			utils.Safely("warm", func() { w.RunOnce() })
+		}
+	}()
+	logger.Log.Info().Dur("interval", interval).Msg("Cache warming scheduled")
+}
+
+// RunOnce refreshes the top-ranked pairs whose entry is missing or expires
+// within refresh_before_seconds, then decays the ranking. Only one instance
+// runs a given round; the others skip it. It returns the number of pairs
+// refreshed.
+func (w *Warmer) RunOnce() int {
+	cfg := config.Current().Warm
+	interval := time.Duration(utils.IntOr(cfg.IntervalSeconds, defaultIntervalSeconds)) * time.Second
+	before := time.Duration(utils.IntOr(cfg.RefreshBeforeSeconds, defaultRefreshBeforeSeconds)) * time.Second
+
+	// The lock is left to expire, which spaces rounds by one interval across
+	// all instances.
+	if _, ok, err := w.store.TryLock(lockName, interval); err != nil || !ok {
+		if err != nil {
+			logger.Log.Warn().Err(err).Msg("Failed to take cache warming lock")
+		}
+		return 0
+	}
+
+	hot, err := w.store.HotAddresses(utils.IntOr(cfg.TopN, defaultTopN))
+	if err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to read hot addresses")
+		return 0
+	}
+
+	refreshed := 0
+	for _, h := range hot {
+		ttl, err := w.store.EntryTTL(h.Address, h.ChainName)
+		if err != nil || ttl < 0 || ttl > before {
+			continue
+		}
+		params := &types.TransactionQueryParams{Address: h.Address, ChainNames: []string{h.ChainName}}
+		if _, err := w.warmer.WarmTransactions(params); err != nil {
+			metrics.CacheWarmRefreshes.WithLabelValues("error").Inc()
+			logger.Log.Warn().Err(err).Str("address", h.Address).Str("chain", h.ChainName).Msg("Cache warming refresh failed")
+			continue
+		}
+		metrics.CacheWarmRefreshes.WithLabelValues("ok").Inc()
+		refreshed++
+	}
+
+	if err := w.store.DecayHotAddresses(decayFactor); err != nil {
+		logger.Log.Warn().Err(err).Msg("Failed to decay hot addresses")
+	}
+	if refreshed > 0 {
+		logger.Log.Info().Int("refreshed", refreshed).Int("hot", len(hot)).Msg("Cache warming round finished")
+	}
+	return refreshed
+}
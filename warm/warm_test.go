@@ -0,0 +1,87 @@
+package warm
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+// stubStore serves a fixed ranking and TTLs keyed by address.
+type stubStore struct {
+	hot     []types.HotAddress
+	ttls    map[string]time.Duration
+	locked  bool
+	decayed int
+}
+
+func (s *stubStore) HotAddresses(n int) ([]types.HotAddress, error) {
+	return s.hot[:min(n, len(s.hot))], nil
+}
+
+func (s *stubStore) DecayHotAddresses(float64) error {
+	s.decayed++
+	return nil
+}
+
+func (s *stubStore) EntryTTL(address, _ string) (time.Duration, error) {
+	return s.ttls[address], nil
+}
+
+func (s *stubStore) TryLock(string, time.Duration) (string, bool, error) {
+	if s.locked {
+		return "", false, nil
+	}
+	s.locked = true
+	return "token", true, nil
+}
+
+// stubWarmer records the warmed params.
+type stubWarmer struct {
+	mu     sync.Mutex
+	params []types.TransactionQueryParams
+}
+
+func (s *stubWarmer) WarmTransactions(params *types.TransactionQueryParams) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = append(s.params, *params)
+	return 1, nil
+}
+
+func TestRunOnce(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Warm = types.WarmConfig{Enabled: true, TopN: 3, RefreshBeforeSeconds: 10}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	store := &stubStore{
+		hot: []types.HotAddress{
+			{Address: "0xexpiring", ChainName: "ETH", Score: 9},
+			{Address: "0xfresh", ChainName: "ETH", Score: 8},
+			{Address: "0xmissing", ChainName: "BSC", Score: 7},
+			{Address: "0xbeyondtop", ChainName: "ETH", Score: 1},
+		},
+		ttls: map[string]time.Duration{
+			"0xexpiring":  5 * time.Second,
+			"0xfresh":     50 * time.Second,
+			"0xbeyondtop": time.Second,
+		},
+	}
+	warmer := &stubWarmer{}
+	w := NewWarmer(store, warmer)
+
+	assert.Equal(t, 2, w.RunOnce())
+	assert.Equal(t, []types.TransactionQueryParams{
+		{Address: "0xexpiring", ChainNames: []string{"ETH"}},
+		{Address: "0xmissing", ChainNames: []string{"BSC"}},
+	}, warmer.params)
+	assert.Equal(t, 1, store.decayed)
+
+	assert.Equal(t, 0, w.RunOnce(), "round already taken by another instance")
+	assert.Len(t, warmer.params, 2)
+}
@@ -0,0 +1,192 @@
+// Package watch manages address watches. An API key with the watch role
+// registers an address; until the watch expires the address is refetched in
+// the background, its cache entries are kept longer than redis.ttl, and the
+// transactions each refresh finds above the last seen height of their chain
+// are POSTed to the watch's webhook. Refreshes follow a schedule kept in
+// Redis and run on a worker pool in every instance, apart from user requests.
+package watch
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/interfaces"
+	"tx-aggregator/logger"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	defaultTTLSeconds      = 7 * 24 * 3600
+	defaultMaxTTLSeconds   = 30 * 24 * 3600
+	defaultMaxPerKey       = 100
+	defaultRefreshSeconds  = 60
+	defaultCacheTTLSeconds = 3600
+	defaultWorkers         = 4
+
+	// SignatureHeader carries the HMAC-SHA256 of the webhook body, as
+	// "sha256=<hex>", when watch.webhook_secret is set.
+	SignatureHeader = "X-Signature-256"
+)
+
+// ErrLimitReached is returned by Create when the owner already has
+// watch.max_per_key live watches.
+var ErrLimitReached = errors.New("watch limit reached")
+
+// Watcher creates, lists and refreshes watches.
+type Watcher struct {
+	store     interfaces.WatchStoreInterface
+	refresher interfaces.TransactionRefresherInterface
+}
+
+// NewWatcher creates a Watcher that keeps watches in store (normally the
+// Redis cache) and refetches them through refresher (normally the usecase
+// Service).
+func NewWatcher(store interfaces.WatchStoreInterface, refresher interfaces.TransactionRefresherInterface) *Watcher {
+	return &Watcher{store: store, refresher: refresher}
+}
+
+// Create registers a watch of params.Address on params.ChainNames for owner.
+// A ttl of 0 uses watch.ttl_seconds; longer ones are capped at
+// watch.max_ttl_seconds. webhookURL is optional.
+func (w *Watcher) Create(owner string, params *types.TransactionQueryParams, webhookURL string, ttl time.Duration) (types.Watch, error) {
+	cfg := config.Current().Watch
+	if webhookURL != "" {
+		if err := checkWebhookURL(cfg, webhookURL); err != nil {
+			return types.Watch{}, err
+		}
+	}
+	if ttl <= 0 {
+		ttl = time.Duration(utils.IntOr(cfg.TTLSeconds, defaultTTLSeconds)) * time.Second
+	}
+	ttl = min(ttl, time.Duration(utils.IntOr(cfg.MaxTTLSeconds, defaultMaxTTLSeconds))*time.Second)
+
+	existing, err := w.store.Watches(owner)
+	if err != nil {
+		return types.Watch{}, err
+	}
+	if len(existing) >= utils.IntOr(cfg.MaxPerKey, defaultMaxPerKey) {
+		return types.Watch{}, ErrLimitReached
+	}
+
+	now := time.Now()
+	watch := types.Watch{
+		ID:          newWatchID(),
+		Owner:       owner,
+		Address:     params.Address,
+		ChainNames:  params.ChainNames,
+		WebhookURL:  webhookURL,
+		CreatedTime: now.Unix(),
+		ExpiresTime: now.Add(ttl).Unix(),
+	}
+	if err := w.store.SaveWatch(watch); err != nil {
+		return types.Watch{}, err
+	}
+	if err := w.store.ScheduleWatch(watch.ID, now); err != nil {
+		return types.Watch{}, err
+	}
+	logger.Log.Info().
+		Str("owner", owner).
+		Str("watch_id", watch.ID).
+		Str("address", watch.Address).
+		Strs("chain_names", watch.ChainNames).
+		Dur("ttl", ttl).
+		Msg("Watch created")
+	return watch, nil
+}
+
+// List returns the live watches of owner.
+func (w *Watcher) List(owner string) ([]types.Watch, error) {
+	return w.store.Watches(owner)
+}
+
+// Delete removes the watch id of owner. It reports false when there is no
+// such watch, or it belongs to another owner.
+func (w *Watcher) Delete(owner, id string) (bool, error) {
+	watch, ok, err := w.store.GetWatch(id)
+	if err != nil || !ok || watch.Owner != owner {
+		return false, err
+	}
+	if err := w.store.DeleteWatch(watch); err != nil {
+		return false, err
+	}
+	logger.Log.Info().Str("owner", owner).Str("watch_id", id).Msg("Watch deleted")
+	return true, nil
+}
+
+// notify POSTs the new rows of watch to its webhook, signed with
+// watch.webhook_secret when set.
+func notify(watch types.Watch, txs []types.Transaction) error {
+	body, err := json.Marshal(types.WatchNotification{
+		WatchID:      watch.ID,
+		Address:      watch.Address,
+		Transactions: txs,
+	})
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	if secret := config.Current().Watch.WebhookSecret; secret != "" {
+		headers[SignatureHeader] = "sha256=" + sign(secret, body)
+	}
+	var opts []utils.RequestOption
+	if !config.Current().Watch.WebhookAllowPrivate {
+		opts = append(opts, utils.WithPublicDestinationOnly())
+	}
+	return utils.DoHttpRequestWithLogging("POST", "watch.webhook", watch.WebhookURL, json.RawMessage(body), headers, nil, opts...)
+}
+
+// checkWebhookURL rejects webhooks that are not http(s), whose host is not
+// on watch.webhook_allowed_hosts when that is set, or that resolve to a
+// non-public address unless watch.webhook_allow_private is set. Delivery
+// checks the address again when it connects.
+func checkWebhookURL(cfg types.WatchConfig, webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url: %s", webhookURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if len(cfg.WebhookAllowedHosts) > 0 && !hostAllowed(host, cfg.WebhookAllowedHosts) {
+		return fmt.Errorf("webhook host %s is not allowed", host)
+	}
+	if !cfg.WebhookAllowPrivate {
+		if err := utils.CheckPublicHost(host); err != nil {
+			return fmt.Errorf("invalid webhook url: %w", err)
+		}
+	}
+	return nil
+}
+
+// hostAllowed reports whether host is one of allowed or a subdomain of one.
+func hostAllowed(host string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimPrefix(a, "."))
+		if host == a || strings.HasSuffix(host, "."+a) {
+			return true
+		}
+	}
+	return false
+}
+
+// sign returns the hex HMAC-SHA256 of body under secret.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWatchID returns a random 128-bit hex identifier.
+func newWatchID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
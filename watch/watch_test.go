@@ -0,0 +1,149 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+// stubStore keeps watches and their schedule in memory, with a fixed number
+// of rate tokens per name.
+type stubStore struct {
+	watches  map[string]types.Watch
+	schedule map[string]time.Time
+	retained []string
+	tokens   map[string]int
+}
+
+func newStubStore() *stubStore {
+	return &stubStore{
+		watches:  map[string]types.Watch{},
+		schedule: map[string]time.Time{},
+		tokens:   map[string]int{},
+	}
+}
+
+func (s *stubStore) SaveWatch(w types.Watch) error {
+	s.watches[w.ID] = w
+	return nil
+}
+
+func (s *stubStore) GetWatch(id string) (types.Watch, bool, error) {
+	w, ok := s.watches[id]
+	return w, ok, nil
+}
+
+func (s *stubStore) DeleteWatch(w types.Watch) error {
+	delete(s.watches, w.ID)
+	return nil
+}
+
+func (s *stubStore) Watches(owner string) ([]types.Watch, error) {
+	var out []types.Watch
+	for _, w := range s.watches {
+		if owner == "" || w.Owner == owner {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubStore) RetainAddress(address string, _ []string, _ time.Duration) error {
+	s.retained = append(s.retained, address)
+	return nil
+}
+
+func (s *stubStore) ScheduleWatch(id string, at time.Time) error {
+	s.schedule[id] = at
+	return nil
+}
+
+func (s *stubStore) UnscheduleWatch(id string) error {
+	delete(s.schedule, id)
+	return nil
+}
+
+func (s *stubStore) ClaimDueWatches(n int, lease time.Duration) ([]string, error) {
+	var ids []string
+	for id, at := range s.schedule {
+		if len(ids) < n && !at.After(time.Now()) {
+			ids = append(ids, id)
+			s.schedule[id] = time.Now().Add(lease)
+		}
+	}
+	return ids, nil
+}
+
+func (s *stubStore) TakeRateToken(name string, perMinute int) (bool, error) {
+	s.tokens[name]++
+	return s.tokens[name] <= perMinute, nil
+}
+
+// stubRefresher serves a fixed set of rows.
+type stubRefresher struct {
+	txs []types.Transaction
+}
+
+func (s *stubRefresher) RefreshTransactions(*types.TransactionQueryParams) ([]types.Transaction, error) {
+	return s.txs, nil
+}
+
+func setWatchConfig(t *testing.T, cfg types.WatchConfig) {
+	orig := config.Current()
+	c := orig
+	c.Watch = cfg
+	config.SetCurrentConfig(c)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+}
+
+func TestCreate(t *testing.T) {
+	setWatchConfig(t, types.WatchConfig{Enabled: true, MaxPerKey: 1, MaxTTLSeconds: 60})
+	store := newStubStore()
+	w := NewWatcher(store, &stubRefresher{})
+	params := &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}}
+
+	_, err := w.Create("wallet", params, "ftp://hooks.example.com", 0)
+	assert.Error(t, err, "webhooks must be http(s)")
+
+	created, err := w.Create("wallet", params, "", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "wallet", created.Owner)
+	assert.Equal(t, int64(60), created.ExpiresTime-created.CreatedTime, "ttl capped at max_ttl_seconds")
+	assert.Contains(t, store.schedule, created.ID, "due right away")
+
+	_, err = w.Create("wallet", params, "", 0)
+	assert.ErrorIs(t, err, ErrLimitReached)
+	_, err = w.Create("explorer", params, "", 0)
+	assert.NoError(t, err, "the limit is per key")
+
+	ok, err := w.Delete("explorer", created.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok, "owned by another key")
+	ok, err = w.Delete("wallet", created.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCreate_RejectsInternalWebhooks(t *testing.T) {
+	setWatchConfig(t, types.WatchConfig{Enabled: true, WebhookAllowedHosts: []string{"example.com", "127.0.0.1", "::1"}})
+	w := NewWatcher(newStubStore(), &stubRefresher{})
+	params := &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH"}}
+
+	for _, hook := range []string{
+		"http://127.0.0.1:8500/v1/agent/service/register",
+		"http://[::1]/",
+	} {
+		_, err := w.Create("wallet", params, hook, 0)
+		assert.ErrorIs(t, err, utils.ErrNonPublicAddress, hook)
+	}
+	_, err := w.Create("wallet", params, "http://169.254.169.254/latest/meta-data", 0)
+	assert.ErrorContains(t, err, "not allowed")
+	_, err = w.Create("wallet", params, "https://hooks.example.org/tx", 0)
+	assert.ErrorContains(t, err, "not allowed")
+	assert.True(t, hostAllowed("hooks.example.com", []string{"example.com"}))
+	assert.False(t, hostAllowed("evilexample.com", []string{"example.com"}))
+}
@@ -0,0 +1,211 @@
+package watch
+
+import (
+	"strings"
+	"time"
+
+	"tx-aggregator/config"
+	"tx-aggregator/logger"
+	"tx-aggregator/metrics"
+	"tx-aggregator/types"
+	"tx-aggregator/utils"
+)
+
+const (
+	// dispatchInterval is how often an instance claims due watches.
+	dispatchInterval = time.Second
+	// claimLease hides a claimed watch from other instances; a refresh that
+	// has not rescheduled it by then is claimed again.
+	claimLease = 5 * time.Minute
+	// rateLimitedRetry is how soon a watch whose provider budget is spent is
+	// tried again.
+	rateLimitedRetry = 15 * time.Second
+)
+
+// Start runs watch.workers refresh workers in the background, fed with the
+// watches whose refresh is due in the Redis schedule. Every instance runs
+// its own pool; the schedule hands each due watch to one of them. It does
+// nothing unless watch.enabled is set.
+func (w *Watcher) Start() {
+	cfg := config.Current().Watch
+	if !cfg.Enabled {
+		return
+	}
+	workers := utils.IntOr(cfg.Workers, defaultWorkers)
+
+	jobs := make(chan string)
+	for range workers {
+		go func() {
+			for id := range jobs {
+				utils.Safely("watch", func() { w.process(id) })
+			}
+		}()
+	}
+	go func() {
+		ticker := time.NewTicker(dispatchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			utils.Safely("watch", func() { w.dispatch(jobs, workers) })
+		}
+	}()
+	logger.Log.Info().Int("workers", workers).Msg("Watch refresh workers started")
+}
+
+// dispatch claims the due watches and sends them to the workers. It claims
+// no more than the pool can start, until the backlog is drained; a send
+// blocks while every worker is busy.
+func (w *Watcher) dispatch(jobs chan<- string, workers int) {
+	for {
+		ids, err := w.store.ClaimDueWatches(workers, claimLease)
+		if err != nil {
+			logger.Log.Warn().Err(err).Msg("Failed to claim due watches")
+			return
+		}
+		for _, id := range ids {
+			jobs <- id
+		}
+		if len(ids) < workers {
+			return
+		}
+	}
+}
+
+// process refreshes the watch id and schedules its next refresh. A watch
+// that expired leaves the schedule. When the refresh budget of one of its
+// providers is spent for this minute the watch is retried shortly instead.
+func (w *Watcher) process(id string) {
+	watch, ok, err := w.store.GetWatch(id)
+	if err != nil {
+		logger.Log.Warn().Err(err).Str("watch_id", id).Msg("Failed to read watch, retrying after the lease")
+		return
+	}
+	if !ok {
+		if err := w.store.UnscheduleWatch(id); err != nil {
+			logger.Log.Warn().Err(err).Str("watch_id", id).Msg("Failed to unschedule expired watch")
+		}
+		return
+	}
+
+	next := time.Now().Add(time.Duration(utils.IntOr(config.Current().Watch.RefreshSeconds, defaultRefreshSeconds)) * time.Second)
+	switch ok, err := w.takeBudget(watch.ChainNames); {
+	case err != nil || !ok:
+		if err != nil {
+			logger.Log.Warn().Err(err).Str("watch_id", id).Msg("Failed to take watch refresh budget")
+		}
+		metrics.WatchRefreshes.WithLabelValues("rate_limited").Inc()
+		next = time.Now().Add(rateLimitedRetry)
+	default:
+		if err := w.refresh(watch); err != nil {
+			metrics.WatchRefreshes.WithLabelValues("error").Inc()
+			logger.Log.Warn().Err(err).Str("watch_id", id).Str("address", watch.Address).Msg("Watch refresh failed")
+		} else {
+			metrics.WatchRefreshes.WithLabelValues("ok").Inc()
+		}
+	}
+
+	if err := w.store.ScheduleWatch(id, next); err != nil {
+		logger.Log.Warn().Err(err).Str("watch_id", id).Msg("Failed to schedule next watch refresh")
+	}
+}
+
+// takeBudget takes one request from the per-minute refresh budget of the
+// primary provider of each chain, shared by every instance. It reports false
+// as soon as one budget is spent; requests already taken for the other
+// chains are not given back, which errs on the side of fewer requests.
+func (w *Watcher) takeBudget(chainNames []string) (bool, error) {
+	cfg := config.Current().Watch
+	for _, chain := range chainNames {
+		key := primaryProvider(chain)
+		limit := cfg.RequestsPerMinute
+		if v, ok := cfg.ProviderRequestsPerMinute[key]; ok {
+			limit = v
+		}
+		if key == "" || limit <= 0 {
+			continue
+		}
+		ok, err := w.store.TakeRateToken("watch:"+key, limit)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// primaryProvider returns the first provider key of chainName in
+// providers.chain_providers, or "" when it has none.
+func primaryProvider(chainName string) string {
+	keys := config.Current().Providers.ChainProviders[strings.ToLower(chainName)]
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+// refresh refetches one watch, extends its cache retention and notifies the
+// webhook of the rows above the last seen height of their chain. The first
+// refresh of a chain only records its height. Heights are not advanced while
+// the webhook fails, so the rows are sent again next time.
+func (w *Watcher) refresh(watch types.Watch) error {
+	txs, err := w.refresher.RefreshTransactions(&types.TransactionQueryParams{
+		Address:    watch.Address,
+		ChainNames: watch.ChainNames,
+	})
+	if err != nil {
+		return err
+	}
+	ttl := time.Duration(utils.IntOr(config.Current().Watch.CacheTTLSeconds, defaultCacheTTLSeconds)) * time.Second
+	if err := w.store.RetainAddress(watch.Address, watch.ChainNames, ttl); err != nil {
+		logger.Log.Warn().Err(err).Str("watch_id", watch.ID).Msg("Failed to extend cache retention of watched address")
+	}
+
+	heights := make(map[string]int64, len(watch.ChainNames))
+	for _, chain := range watch.ChainNames {
+		heights[strings.ToUpper(chain)] = watch.LastHeights[strings.ToUpper(chain)]
+	}
+	var fresh []types.Transaction
+	for _, tx := range txs {
+		chain, err := utils.ChainNameByID(tx.ChainID)
+		if err != nil {
+			continue
+		}
+		last, seen := watch.LastHeights[chain]
+		if tx.Height <= last {
+			continue
+		}
+		if seen {
+			fresh = append(fresh, tx)
+		}
+		heights[chain] = max(heights[chain], tx.Height)
+	}
+	if sameHeights(watch.LastHeights, heights) {
+		return nil
+	}
+	if len(fresh) > 0 && watch.WebhookURL != "" {
+		if err := notify(watch, fresh); err != nil {
+			metrics.WatchWebhooks.WithLabelValues("error").Inc()
+			return err
+		}
+		metrics.WatchWebhooks.WithLabelValues("ok").Inc()
+	}
+
+	// Do not bring back a watch deleted during the refresh.
+	if _, ok, err := w.store.GetWatch(watch.ID); err != nil || !ok {
+		return err
+	}
+	watch.LastHeights = heights
+	return w.store.SaveWatch(watch)
+}
+
+// sameHeights reports whether a and b hold the same chains and heights.
+func sameHeights(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for chain, h := range a {
+		if v, ok := b[chain]; !ok || v != h {
+			return false
+		}
+	}
+	return true
+}
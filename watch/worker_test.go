@@ -0,0 +1,89 @@
+package watch
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"tx-aggregator/config"
+	"tx-aggregator/types"
+)
+
+func TestProcess(t *testing.T) {
+	var received []types.WatchNotification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "sha256="+sign("s3cret", body), r.Header.Get(SignatureHeader))
+		var n types.WatchNotification
+		_ = json.Unmarshal(body, &n)
+		received = append(received, n)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	orig := config.Current()
+	cfg := orig
+	cfg.ChainNames = map[string]int64{"ETH": 1, "BSC": 56}
+	cfg.Watch = types.WatchConfig{Enabled: true, WebhookSecret: "s3cret", RefreshSeconds: 60, WebhookAllowPrivate: true}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	store := newStubStore()
+	refresher := &stubRefresher{txs: []types.Transaction{{ChainID: 1, Hash: "0x1", Height: 10}}}
+	w := NewWatcher(store, refresher)
+	created, err := w.Create("wallet", &types.TransactionQueryParams{Address: "0xabc", ChainNames: []string{"ETH", "BSC"}}, srv.URL, 0)
+	assert.NoError(t, err)
+
+	w.process(created.ID)
+	assert.Empty(t, received, "the first refresh only records the heights")
+	assert.Equal(t, map[string]int64{"ETH": 10, "BSC": 0}, store.watches[created.ID].LastHeights)
+	assert.Equal(t, []string{"0xabc"}, store.retained)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), store.schedule[created.ID], 2*time.Second)
+
+	// A BSC height far above ETH's does not hide new ETH rows.
+	refresher.txs = append(refresher.txs,
+		types.Transaction{ChainID: 1, Hash: "0x2", Height: 12},
+		types.Transaction{ChainID: 56, Hash: "0x3", Height: 40000000},
+	)
+	w.process(created.ID)
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, created.ID, received[0].WatchID)
+		assert.Len(t, received[0].Transactions, 2)
+	}
+	assert.Equal(t, map[string]int64{"ETH": 12, "BSC": 40000000}, store.watches[created.ID].LastHeights)
+
+	w.process(created.ID)
+	assert.Len(t, received, 1, "nothing new")
+
+	// Expired or deleted watches leave the schedule.
+	delete(store.watches, created.ID)
+	w.process(created.ID)
+	assert.NotContains(t, store.schedule, created.ID)
+}
+
+func TestTakeBudget(t *testing.T) {
+	orig := config.Current()
+	cfg := orig
+	cfg.Providers.ChainProviders = map[string][]string{"eth": {"ankr", "blockscan_eth"}, "bsc": {"blockscan_bsc"}}
+	cfg.Watch = types.WatchConfig{RequestsPerMinute: 2, ProviderRequestsPerMinute: map[string]int{"blockscan_bsc": 0}}
+	config.SetCurrentConfig(cfg)
+	t.Cleanup(func() { config.SetCurrentConfig(orig) })
+
+	store := newStubStore()
+	w := NewWatcher(store, &stubRefresher{})
+
+	for i := 0; i < 2; i++ {
+		ok, err := w.takeBudget([]string{"ETH", "BSC"})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, _ := w.takeBudget([]string{"ETH"})
+	assert.False(t, ok, "primary provider of ETH spent")
+	ok, _ = w.takeBudget([]string{"BSC"})
+	assert.True(t, ok, "0 overrides the default with unlimited")
+	assert.Equal(t, map[string]int{"watch:ankr": 3}, store.tokens)
+}